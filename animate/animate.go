@@ -0,0 +1,415 @@
+// Package animate transitions the styles of a laid-out node tree over time
+// and reports how the layout moves, frame by frame.
+//
+// Register transitions on a node's properties, change its style with
+// SetStyle, then call Tick once per frame:
+//
+//	a := animate.New(root, layout.Loose(80, 24), ctx)
+//	a.Transition(panel, animate.Transition{Property: animate.Width, Duration: 200 * time.Millisecond, Easing: animate.EaseOut})
+//
+//	style := panel.Style
+//	style.Width = layout.Px(40)
+//	a.SetStyle(panel, style)
+//	for a.Running() {
+//	    for _, c := range a.Tick(16 * time.Millisecond) {
+//	        redraw(c.Node, c.From, c.To)
+//	    }
+//	}
+//
+// During a transition the node's Style holds the in-between value, so the
+// tree itself is the interpolated tree of the current frame: render it as
+// usual after each Tick. Properties without a transition change at once.
+//
+// Lengths in the same unit, and absolute lengths in different units, are
+// interpolated; other pairs (auto, percentages against other units,
+// calc()) switch halfway, as in CSS. Transforms are interpolated with
+// layout.InterpolateTransform.
+//
+// See: https://www.w3.org/TR/css-transitions-1/
+package animate
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Property is a group of style properties that transitions together.
+type Property int
+
+const (
+	Width      Property = iota // Style.Width
+	Height                     // Style.Height
+	MinWidth                   // Style.MinWidth
+	MinHeight                  // Style.MinHeight
+	MaxWidth                   // Style.MaxWidth
+	MaxHeight                  // Style.MaxHeight
+	Padding                    // All four sides of Style.Padding
+	Margin                     // All four sides of Style.Margin
+	Border                     // All four sides of Style.Border
+	Inset                      // Style.Top, Right, Bottom and Left
+	FlexGrow                   // Style.FlexGrow
+	FlexShrink                 // Style.FlexShrink
+	Transform                  // Style.Transform
+	All                        // Every property above
+)
+
+// Transition says how a property moves to a new value.
+type Transition struct {
+	Property Property
+	Duration time.Duration
+	Delay    time.Duration // Time before the value starts to move
+	Easing   Easing        // Default: Ease
+}
+
+// Change is a node whose Rect moved or resized during a Tick.
+type Change struct {
+	Node     *layout.Node
+	From, To layout.Rect
+}
+
+// Animator runs the transitions of a node tree. It isn't safe for
+// concurrent use.
+type Animator struct {
+	root        *layout.Node
+	constraints layout.Constraints
+	ctx         *layout.LayoutContext
+
+	transitions map[*layout.Node]map[Property]Transition
+	running     map[*layout.Node]*nodeAnimation
+	rects       map[*layout.Node]layout.Rect
+}
+
+// nodeAnimation is the running transitions of one node.
+type nodeAnimation struct {
+	target layout.Style
+	props  []*propertyAnimation
+}
+
+// propertyAnimation is a running transition of one property.
+type propertyAnimation struct {
+	property   Property
+	transition Transition
+	from       layout.Style
+	elapsed    time.Duration
+}
+
+// New lays out root and returns an animator for it, which lays it out
+// again with constraints and ctx after every Tick.
+func New(root *layout.Node, constraints layout.Constraints, ctx *layout.LayoutContext) *Animator {
+	a := &Animator{
+		root:        root,
+		constraints: constraints,
+		ctx:         ctx,
+		transitions: make(map[*layout.Node]map[Property]Transition),
+		running:     make(map[*layout.Node]*nodeAnimation),
+	}
+	layout.Layout(root, constraints, ctx)
+	a.changes()
+	return a
+}
+
+// Transition registers transitions on node's properties, replacing any
+// registered earlier for the same properties. A transition on All applies
+// to every property not given its own.
+func (a *Animator) Transition(node *layout.Node, transitions ...Transition) {
+	registered := a.transitions[node]
+	if registered == nil {
+		registered = make(map[Property]Transition)
+		a.transitions[node] = registered
+	}
+	for _, t := range transitions {
+		registered[t.Property] = t
+	}
+}
+
+// SetStyle changes node's style to style. Properties with a transition
+// move there from their current value over the following Ticks; a
+// property already moving to the same value carries on. The others change
+// at once. Change the styles of animated nodes with SetStyle rather than
+// directly, as each transition sets its property's final value when it
+// ends.
+func (a *Animator) SetStyle(node *layout.Node, style layout.Style) {
+	old := a.running[node]
+	anim := &nodeAnimation{target: style}
+	for p := range All {
+		t, ok := a.transition(node, p)
+		if !ok || t.Duration+t.Delay <= 0 {
+			continue
+		}
+		spec := properties[p]
+		if spec.get(&node.Style) == spec.get(&style) {
+			continue
+		}
+		if pa := old.find(p); pa != nil && spec.get(&old.target) == spec.get(&style) {
+			anim.props = append(anim.props, pa)
+			continue
+		}
+		anim.props = append(anim.props, &propertyAnimation{property: p, transition: t, from: node.Style})
+	}
+
+	// Moving properties keep their current value until the next Tick
+	current := style
+	for _, pa := range anim.props {
+		properties[pa.property].copy(&current, &node.Style)
+	}
+	node.Style = current
+	if len(anim.props) > 0 {
+		a.running[node] = anim
+	} else {
+		delete(a.running, node)
+	}
+}
+
+// Running reports whether any transition hasn't finished.
+func (a *Animator) Running() bool {
+	return len(a.running) > 0
+}
+
+// Tick advances the transitions by dt, lays the tree out again and returns
+// the nodes whose Rects changed since the last Tick, in tree order.
+func (a *Animator) Tick(dt time.Duration) []Change {
+	for node, anim := range a.running {
+		r := resolver{ctx: a.ctx, fontSize: fontSize(node, a.ctx)}
+		style := node.Style
+		done := true
+		for _, pa := range anim.props {
+			pa.elapsed += dt
+			progress := 1.0
+			if d := pa.transition.Duration; d > 0 {
+				progress = math.Max(0, math.Min(1, float64(pa.elapsed-pa.transition.Delay)/float64(d)))
+			} else if pa.elapsed < pa.transition.Delay {
+				progress = 0
+			}
+			if progress < 1 {
+				done = false
+			}
+			easing := pa.transition.Easing
+			if easing == nil {
+				easing = Ease
+			}
+			properties[pa.property].mix(&style, &pa.from, &anim.target, easing(progress), r)
+		}
+		node.Style = style
+		if done {
+			node.Style = anim.target
+			delete(a.running, node)
+		}
+	}
+	layout.Layout(a.root, a.constraints, a.ctx)
+	return a.changes()
+}
+
+// LerpRect returns the rect t of the way from a to b (0 gives a, 1 gives
+// b).
+//
+// Example:
+//
+//	frame := animate.LerpRect(c.From, c.To, animate.EaseOut(0.5))
+func LerpRect(a, b layout.Rect, t float64) layout.Rect {
+	return layout.Rect{
+		X:      lerp(a.X, b.X, t),
+		Y:      lerp(a.Y, b.Y, t),
+		Width:  lerp(a.Width, b.Width, t),
+		Height: lerp(a.Height, b.Height, t),
+	}
+}
+
+// transition returns the transition registered on node for p.
+func (a *Animator) transition(node *layout.Node, p Property) (Transition, bool) {
+	registered := a.transitions[node]
+	if t, ok := registered[p]; ok {
+		return t, true
+	}
+	t, ok := registered[All]
+	return t, ok
+}
+
+// changes records the Rect of every node and returns the ones that differ
+// from the last time.
+func (a *Animator) changes() []Change {
+	var changes []Change
+	rects := make(map[*layout.Node]layout.Rect, len(a.rects))
+	var walk func(n *layout.Node)
+	walk = func(n *layout.Node) {
+		if old, ok := a.rects[n]; ok && old != n.Rect {
+			changes = append(changes, Change{Node: n, From: old, To: n.Rect})
+		}
+		rects[n] = n.Rect
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(a.root)
+	a.rects = rects
+	return changes
+}
+
+// find returns the running transition of p, or nil.
+func (anim *nodeAnimation) find(p Property) *propertyAnimation {
+	if anim == nil {
+		return nil
+	}
+	for _, pa := range anim.props {
+		if pa.property == p {
+			return pa
+		}
+	}
+	return nil
+}
+
+// property knows how to compare, copy and interpolate one Property.
+type property struct {
+	get  func(s *layout.Style) any
+	copy func(dst, src *layout.Style)
+	mix  func(dst, a, b *layout.Style, t float64, r resolver)
+}
+
+var properties = map[Property]property{
+	Width:     lengthProperty(func(s *layout.Style) *layout.Length { return &s.Width }, sizeLength),
+	Height:    lengthProperty(func(s *layout.Style) *layout.Length { return &s.Height }, sizeLength),
+	MinWidth:  lengthProperty(func(s *layout.Style) *layout.Length { return &s.MinWidth }, optionalLength),
+	MinHeight: lengthProperty(func(s *layout.Style) *layout.Length { return &s.MinHeight }, optionalLength),
+	MaxWidth:  lengthProperty(func(s *layout.Style) *layout.Length { return &s.MaxWidth }, optionalLength),
+	MaxHeight: lengthProperty(func(s *layout.Style) *layout.Length { return &s.MaxHeight }, optionalLength),
+	Padding:   spacingProperty(func(s *layout.Style) *layout.Spacing { return &s.Padding }),
+	Margin:    spacingProperty(func(s *layout.Style) *layout.Spacing { return &s.Margin }),
+	Border:    spacingProperty(func(s *layout.Style) *layout.Spacing { return &s.Border }),
+	Inset: {
+		get: func(s *layout.Style) any { return [4]layout.Length{s.Top, s.Right, s.Bottom, s.Left} },
+		copy: func(dst, src *layout.Style) {
+			dst.Top, dst.Right, dst.Bottom, dst.Left = src.Top, src.Right, src.Bottom, src.Left
+		},
+		mix: func(dst, a, b *layout.Style, t float64, r resolver) {
+			dst.Top = r.length(a.Top, b.Top, t, optionalLength)
+			dst.Right = r.length(a.Right, b.Right, t, optionalLength)
+			dst.Bottom = r.length(a.Bottom, b.Bottom, t, optionalLength)
+			dst.Left = r.length(a.Left, b.Left, t, optionalLength)
+		},
+	},
+	FlexGrow:   floatProperty(func(s *layout.Style) *float64 { return &s.FlexGrow }),
+	FlexShrink: floatProperty(func(s *layout.Style) *float64 { return &s.FlexShrink }),
+	Transform: {
+		get:  func(s *layout.Style) any { return s.Transform },
+		copy: func(dst, src *layout.Style) { dst.Transform = src.Transform },
+		mix: func(dst, a, b *layout.Style, t float64, _ resolver) {
+			dst.Transform = layout.InterpolateTransform(a.Transform, b.Transform, t)
+		},
+	},
+}
+
+func lengthProperty(field func(*layout.Style) *layout.Length, kind lengthKind) property {
+	return property{
+		get:  func(s *layout.Style) any { return *field(s) },
+		copy: func(dst, src *layout.Style) { *field(dst) = *field(src) },
+		mix: func(dst, a, b *layout.Style, t float64, r resolver) {
+			*field(dst) = r.length(*field(a), *field(b), t, kind)
+		},
+	}
+}
+
+func spacingProperty(field func(*layout.Style) *layout.Spacing) property {
+	return property{
+		get:  func(s *layout.Style) any { return *field(s) },
+		copy: func(dst, src *layout.Style) { *field(dst) = *field(src) },
+		mix: func(dst, a, b *layout.Style, t float64, r resolver) {
+			sa, sb := field(a), field(b)
+			*field(dst) = layout.Spacing{
+				Top:    r.length(sa.Top, sb.Top, t, spacingLength),
+				Right:  r.length(sa.Right, sb.Right, t, spacingLength),
+				Bottom: r.length(sa.Bottom, sb.Bottom, t, spacingLength),
+				Left:   r.length(sa.Left, sb.Left, t, spacingLength),
+			}
+		},
+	}
+}
+
+func floatProperty(field func(*layout.Style) *float64) property {
+	return property{
+		get:  func(s *layout.Style) any { return *field(s) },
+		copy: func(dst, src *layout.Style) { *field(dst) = *field(src) },
+		mix: func(dst, a, b *layout.Style, t float64, _ resolver) {
+			*field(dst) = lerp(*field(a), *field(b), t)
+		},
+	}
+}
+
+// lengthKind is what an unset (zero) Length and a negative one mean for a
+// property.
+type lengthKind int
+
+const (
+	sizeLength     lengthKind = iota // Unset is 0px; negative is auto
+	spacingLength                    // Unset is 0px
+	optionalLength                   // Unset is auto or none
+)
+
+// resolver resolves lengths of one node to pixels.
+type resolver struct {
+	ctx      *layout.LayoutContext
+	fontSize float64
+}
+
+// length interpolates between a and b, switching from one to the other
+// halfway if they can't be interpolated.
+func (r resolver) length(a, b layout.Length, t float64, kind lengthKind) layout.Length {
+	if a == b {
+		return a
+	}
+	if kind != optionalLength {
+		// An unset length is 0px here
+		if a.Unit == "" {
+			a = layout.Px(0)
+		}
+		if b.Unit == "" {
+			b = layout.Px(0)
+		}
+	}
+	if interpolable(a, kind) && interpolable(b, kind) {
+		if a.Unit == b.Unit {
+			return layout.Length{Value: lerp(a.Value, b.Value, t), Unit: a.Unit}
+		}
+		if absolute(a) && absolute(b) {
+			return layout.Px(lerp(layout.ResolveLength(a, r.ctx, r.fontSize), layout.ResolveLength(b, r.ctx, r.fontSize), t))
+		}
+	}
+	if t < 0.5 {
+		return a
+	}
+	return b
+}
+
+// interpolable reports whether l is a plain number of some unit.
+func interpolable(l layout.Length, kind lengthKind) bool {
+	switch {
+	case l.Unit == "" || l.Unit == layout.AutoUnit || l.Unit == layout.UnboundedUnit:
+		return false
+	case strings.HasPrefix(string(l.Unit), "calc("):
+		return false
+	case kind == sizeLength && l.Value < 0:
+		return false
+	}
+	return true
+}
+
+// absolute reports whether l resolves to pixels without a reference size.
+func absolute(l layout.Length) bool {
+	return l.Unit != layout.PercentUnit && !l.IsContainerRelative()
+}
+
+// fontSize returns the font size em lengths of node are resolved against.
+func fontSize(node *layout.Node, ctx *layout.LayoutContext) float64 {
+	if ts := node.Style.TextStyle; ts != nil && ts.FontSize > 0 {
+		return ts.FontSize
+	}
+	if ctx != nil && ctx.RootFontSize > 0 {
+		return ctx.RootFontSize
+	}
+	return 16
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
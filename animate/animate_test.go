@@ -0,0 +1,124 @@
+package animate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestTransition(t *testing.T) {
+	panel := &layout.Node{Style: layout.Style{Width: layout.Px(100), Height: layout.Px(10)}}
+	below := &layout.Node{Style: layout.Style{Width: layout.Px(50), Height: layout.Px(10)}}
+	root := &layout.Node{
+		Style:    layout.Style{Width: layout.Px(400), Height: layout.Px(-1)},
+		Children: []*layout.Node{panel, below},
+	}
+	a := New(root, layout.Loose(800, 600), layout.NewLayoutContext(800, 600, 16))
+	a.Transition(panel, Transition{Property: Width, Duration: 100 * time.Millisecond, Easing: Linear})
+
+	// Height has no transition, so it changes at once; width moves
+	style := panel.Style
+	style.Width = layout.Px(200)
+	style.Height = layout.Px(30)
+	a.SetStyle(panel, style)
+	if panel.Style.Width != layout.Px(100) || panel.Style.Height != layout.Px(30) {
+		t.Errorf("Expected width 100 and height 30 before the first tick, got %v and %v", panel.Style.Width, panel.Style.Height)
+	}
+
+	changes := a.Tick(25 * time.Millisecond)
+	if panel.Rect.Width != 125 || panel.Rect.Height != 30 {
+		t.Errorf("Expected the panel to be 125x30 a quarter of the way, got %vx%v", panel.Rect.Width, panel.Rect.Height)
+	}
+	// The panel and the node it pushed down changed
+	if len(changes) != 3 || changes[1].Node != panel || changes[2].Node != below || changes[2].From.Y != 10 || changes[2].To.Y != 30 {
+		t.Errorf("Expected changes to the root, panel and the node below, got %+v", changes)
+	}
+
+	a.Tick(50 * time.Millisecond)
+	if panel.Rect.Width != 175 || !a.Running() {
+		t.Errorf("Expected the panel 175 wide and still running, got %v (%v)", panel.Rect.Width, a.Running())
+	}
+
+	// Retargeting starts from where the width is now
+	style.Width = layout.Px(75)
+	a.SetStyle(panel, style)
+	a.Tick(50 * time.Millisecond)
+	if panel.Rect.Width != 125 {
+		t.Errorf("Expected the panel to head back from 175, got %v", panel.Rect.Width)
+	}
+	a.Tick(time.Second)
+	if panel.Style.Width != style.Width || a.Running() {
+		t.Errorf("Expected the transition to finish at the target width, got %v (%v)", panel.Style.Width, a.Running())
+	}
+	if changes := a.Tick(time.Second); len(changes) != 0 {
+		t.Errorf("Expected no changes once finished, got %+v", changes)
+	}
+}
+
+func TestTransitionDelayAndAll(t *testing.T) {
+	node := &layout.Node{Style: layout.Style{Width: layout.Px(10), Height: layout.Px(10), FlexGrow: 0}}
+	a := New(node, layout.Loose(800, 600), layout.NewLayoutContext(800, 600, 16))
+	a.Transition(node, Transition{Property: All, Duration: 100 * time.Millisecond, Delay: 50 * time.Millisecond, Easing: Linear})
+
+	style := node.Style
+	style.Padding = layout.Uniform(layout.Em(1)) // Unset padding is 0px
+	style.FlexGrow = 2
+	style.Transform = layout.RotateDegrees(90)
+	a.SetStyle(node, style)
+
+	a.Tick(50 * time.Millisecond)
+	if node.Style.FlexGrow != 0 {
+		t.Errorf("Expected nothing to move during the delay, got flex-grow %v", node.Style.FlexGrow)
+	}
+	a.Tick(50 * time.Millisecond)
+	if node.Style.FlexGrow != 1 || node.Style.Padding.Left != layout.Px(8) {
+		t.Errorf("Expected flex-grow 1 and 8px padding halfway, got %v and %v", node.Style.FlexGrow, node.Style.Padding.Left)
+	}
+	if rot := node.Style.Transform.Decompose().Rotate; math.Abs(rot-45) > 1e-9 {
+		t.Errorf("Expected a 45 degree rotation halfway, got %v", rot)
+	}
+}
+
+func TestInterpolateLength(t *testing.T) {
+	r := resolver{ctx: layout.NewLayoutContext(800, 600, 16), fontSize: 10}
+	tests := []struct {
+		a, b layout.Length
+		t    float64
+		kind lengthKind
+		want layout.Length
+	}{
+		{layout.Percent(10), layout.Percent(50), 0.5, sizeLength, layout.Percent(30)},
+		{layout.Px(10), layout.Em(3), 0.5, sizeLength, layout.Px(20)},
+		{layout.Px(-1), layout.Px(100), 0.4, sizeLength, layout.Px(-1)}, // auto switches halfway
+		{layout.Px(-1), layout.Px(100), 0.6, sizeLength, layout.Px(100)},
+		{layout.Px(-10), layout.Px(10), 0.25, spacingLength, layout.Px(-5)},
+		{layout.Length{}, layout.Px(10), 0.4, optionalLength, layout.Length{}},
+		{layout.Percent(50), layout.Px(10), 0.75, sizeLength, layout.Px(10)},
+	}
+	for _, tt := range tests {
+		if got := r.length(tt.a, tt.b, tt.t, tt.kind); got != tt.want {
+			t.Errorf("length(%v, %v, %v): expected %v, got %v", tt.a, tt.b, tt.t, tt.want, got)
+		}
+	}
+}
+
+func TestEasing(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		easing Easing
+		t      float64
+		want   float64
+	}{
+		{"linear", Linear, 0.3, 0.3},
+		{"ease-in-out midpoint", EaseInOut, 0.5, 0.5},
+		{"ease-in starts slowly", EaseIn, 0.5, 0.3153},
+		{"steps", Steps(4), 0.6, 0.5},
+		{"end", Ease, 1, 1},
+	} {
+		if got := tt.easing(tt.t); math.Abs(got-tt.want) > 1e-3 {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
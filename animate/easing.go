@@ -0,0 +1,72 @@
+package animate
+
+import "math"
+
+// Easing maps the fraction of a transition's duration that has passed, from
+// 0 to 1, to how far the value has moved from its start to its end.
+//
+// See: https://www.w3.org/TR/css-easing-1/
+type Easing func(t float64) float64
+
+// The CSS named easing functions.
+var (
+	Linear    Easing = func(t float64) float64 { return t }
+	Ease             = CubicBezier(0.25, 0.1, 0.25, 1)
+	EaseIn           = CubicBezier(0.42, 0, 1, 1)
+	EaseOut          = CubicBezier(0, 0, 0.58, 1)
+	EaseInOut        = CubicBezier(0.42, 0, 0.58, 1)
+)
+
+// CubicBezier returns the easing of the CSS cubic-bezier(x1, y1, x2, y2)
+// function: a curve from (0, 0) to (1, 1) with those control points. x1
+// and x2 are clamped to [0, 1] so the curve is a function of time.
+//
+// Example:
+//
+//	snappy := animate.CubicBezier(0.2, 0, 0, 1)
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	x1 = math.Max(0, math.Min(1, x1))
+	x2 = math.Max(0, math.Min(1, x2))
+	bezier := func(p1, p2, s float64) float64 {
+		// B(s) for a curve from 0 to 1 with control points p1 and p2
+		return 3*p1*s*(1-s)*(1-s) + 3*p2*s*s*(1-s) + s*s*s
+	}
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+		// x(s) increases with s, so bisect for the s where x(s) = t
+		lo, hi := 0.0, 1.0
+		s := t
+		for range 50 {
+			x := bezier(x1, x2, s)
+			if math.Abs(x-t) < 1e-9 {
+				break
+			}
+			if x < t {
+				lo = s
+			} else {
+				hi = s
+			}
+			s = (lo + hi) / 2
+		}
+		return bezier(y1, y2, s)
+	}
+}
+
+// Steps returns the easing of the CSS steps(n, jump-end) function: n
+// equal jumps, each at the end of its interval.
+func Steps(n int) Easing {
+	if n < 1 {
+		n = 1
+	}
+	return func(t float64) float64 {
+		if t >= 1 {
+			return 1
+		}
+		if t <= 0 {
+			return 0
+		}
+		return math.Floor(t*float64(n)) / float64(n)
+	}
+}
@@ -775,16 +775,23 @@ func MaxContentTrack() GridTrack {
 	}
 }
 
-// FitContentTrack creates a grid track that uses fit-content sizing.
-// The track will be max-content clamped to the specified maximum size.
+// FitContentTrack creates a grid track that uses fit-content(limit) sizing.
+// The track hugs the max-content size of its items, clamped to limit, but
+// never shrinks below their min-content size. This matches CSS
+// fit-content(): max(min-content, min(max-content, limit)).
+//
+// Useful for sidebar and label columns that should hug their content
+// without blowing out the layout.
 //
 // Example:
 //
-//	GridTemplateColumns: []GridTrack{FitContentTrack(300), FixedTrack(Px(200))}
-func FitContentTrack(maxSize float64) GridTrack {
+//	GridTemplateColumns: []GridTrack{FitContentTrack(300), FractionTrack(1)}
+//
+// See: https://www.w3.org/TR/css-grid-1/#valdef-grid-template-columns-fit-content
+func FitContentTrack(limit float64) GridTrack {
 	return GridTrack{
 		MinSize:  Px(0),
-		MaxSize:  Px(maxSize),
+		MaxSize:  Px(limit),
 		Fraction: -1, // Special marker for fit-content
 	}
 }
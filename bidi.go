@@ -0,0 +1,151 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/SCKelemen/unicode/v6/uax9"
+)
+
+// Bidirectional text (UAX #9).
+//
+// Lines are broken in logical order, then LayoutText resolves the
+// embedding level of every box and reorders the boxes of each line into
+// visual order, so renderers can draw them left to right. The paragraph
+// level comes from TextStyle.Direction. Boxes keep their text in logical
+// order; InlineBox.VisualText returns it in display order.
+//
+// Reordering is at box (word) granularity: a box takes the lowest level of
+// its characters, so a word mixing scripts without a space is reordered as
+// a whole.
+//
+// See: https://www.unicode.org/reports/tr9/
+
+// VisualText returns the box's text in display order: reversed for a
+// right-to-left box, with any embedded left-to-right runs (such as
+// numbers) kept in order.
+func (b InlineBox) VisualText() string {
+	if b.Level%2 == 0 && !needsBidi(b.Text) {
+		return b.Text
+	}
+	dir := uax9.DirectionLTR
+	if b.Level%2 == 1 {
+		dir = uax9.DirectionRTL
+	}
+	return uax9.Reorder(b.Text, dir)
+}
+
+// reorderLines resolves the embedding levels of each line's boxes and puts
+// the boxes into visual order (UAX #9 rules L1-L2). Lines of purely
+// left-to-right text in a left-to-right paragraph are left alone.
+func reorderLines(lines []TextLine, direction Direction) {
+	paraLevel := 0
+	if direction == DirectionRTL {
+		paraLevel = 1
+	}
+	for i := range lines {
+		line := &lines[i]
+		if paraLevel == 0 && !lineNeedsBidi(line) {
+			continue
+		}
+		levels := boxLevels(line.Boxes, paraLevel)
+		for j := range line.Boxes {
+			line.Boxes[j].Level = levels[j]
+		}
+		reorderBoxes(line.Boxes)
+	}
+}
+
+// boxLevels returns the embedding level of each box, resolved over the
+// line's text with a space between boxes.
+func boxLevels(boxes []InlineBox, paraLevel int) []int {
+	var classes []uax9.BidiClass
+	starts := make([]int, len(boxes)+1)
+	for i, box := range boxes {
+		if i > 0 {
+			classes = append(classes, uax9.ClassWS)
+		}
+		starts[i] = len(classes)
+		for _, r := range box.Text {
+			classes = append(classes, uax9.GetBidiClass(r))
+		}
+	}
+	starts[len(boxes)] = len(classes) + 1
+	runeLevels := uax9.ComputeLevels(classes, paraLevel)
+
+	levels := make([]int, len(boxes))
+	for i := range boxes {
+		level := -1
+		for _, l := range runeLevels[starts[i] : starts[i+1]-1] {
+			// -1 marks removed formatting characters
+			if l >= 0 && (level < 0 || l < level) {
+				level = l
+			}
+		}
+		if level < 0 {
+			level = paraLevel
+		}
+		levels[i] = level
+	}
+	return levels
+}
+
+// reorderBoxes reverses every maximal run of boxes at or above each odd
+// level, from the highest level down (UAX #9 rule L2).
+func reorderBoxes(boxes []InlineBox) {
+	highest, lowestOdd := 0, -1
+	for _, b := range boxes {
+		if b.Level > highest {
+			highest = b.Level
+		}
+		if b.Level%2 == 1 && (lowestOdd < 0 || b.Level < lowestOdd) {
+			lowestOdd = b.Level
+		}
+	}
+	if lowestOdd < 0 {
+		return
+	}
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(boxes); {
+			if boxes[i].Level < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(boxes) && boxes[j].Level >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				boxes[a], boxes[b] = boxes[b], boxes[a]
+			}
+			i = j
+		}
+	}
+}
+
+// lineNeedsBidi reports whether any box of line needs reordering in a
+// left-to-right paragraph.
+func lineNeedsBidi(line *TextLine) bool {
+	for _, box := range line.Boxes {
+		if needsBidi(box.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsBidi reports whether s has right-to-left characters, Arabic
+// numbers or explicit directional formatting.
+func needsBidi(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool {
+		if r < 0x0590 {
+			return false
+		}
+		switch uax9.GetBidiClass(r) {
+		case uax9.ClassR, uax9.ClassAL, uax9.ClassAN,
+			uax9.ClassRLE, uax9.ClassRLO, uax9.ClassLRE, uax9.ClassLRO,
+			uax9.ClassRLI, uax9.ClassLRI, uax9.ClassFSI:
+			return true
+		}
+		return false
+	})
+}
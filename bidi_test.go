@@ -0,0 +1,123 @@
+package layout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// visualWords returns the boxes of each line in visual order.
+func visualWords(node *Node) []string {
+	var lines []string
+	for _, line := range node.TextLayout.Lines {
+		words := make([]string, len(line.Boxes))
+		for i, box := range line.Boxes {
+			words[i] = box.Text
+		}
+		lines = append(lines, strings.Join(words, " "))
+	}
+	return lines
+}
+
+func TestBidiReordersBoxes(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	tests := []struct {
+		name      string
+		text      string
+		direction Direction
+		want      string
+		levels    []int
+	}{
+		{"ltr", "one two", DirectionLTR, "one two", []int{0, 0}},
+		{"rtl run in ltr", "abc אבג דהו def", DirectionLTR, "abc דהו אבג def", []int{0, 1, 1, 0}},
+		{"numbers after rtl", "abc אבג 123 def", DirectionLTR, "abc 123 אבג def", []int{0, 2, 1, 0}},
+		// 123 follows world, so it is left-to-right text too (rule W7)
+		{"rtl paragraph", "שלום world 123 עולם", DirectionRTL, "עולם world 123 שלום", []int{1, 2, 2, 1}},
+		{"ltr run in rtl", "אבג abc def", DirectionRTL, "abc def אבג", []int{2, 2, 1}},
+	}
+	for _, tt := range tests {
+		node := Text(tt.text, Style{TextStyle: &TextStyle{FontSize: 16, Direction: tt.direction}})
+		LayoutText(node, Loose(1000, 100), NewLayoutContext(800, 600, 16))
+
+		if got := visualWords(node); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: expected visual order %q, got %q", tt.name, tt.want, got)
+			continue
+		}
+		for i, box := range node.TextLayout.Lines[0].Boxes {
+			if box.Level != tt.levels[i] {
+				t.Errorf("%s: expected box %q at level %d, got %d", tt.name, box.Text, tt.levels[i], box.Level)
+			}
+		}
+	}
+}
+
+func TestBidiLinesBreakInLogicalOrder(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// Each word is 30px and a space 10px: two words and a trailing space fit
+	// on an 80px line
+	node := Text("אבג דהו זחט", Style{
+		Width:     Px(80),
+		TextStyle: &TextStyle{FontSize: 16, Direction: DirectionRTL},
+	})
+	LayoutText(node, Loose(80, 100), NewLayoutContext(800, 600, 16))
+
+	want := []string{"דהו אבג", "זחט"}
+	if got := visualWords(node); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Expected lines %q, got %q", want, got)
+	}
+	// The short last line starts at the right edge
+	if got := node.TextLayout.Lines[1].OffsetX; got != 50 {
+		t.Errorf("Expected the last line at x 50, got %.2f", got)
+	}
+}
+
+func TestBidiJustifyLastLineStart(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	tests := []struct {
+		last    TextAlignLast
+		offsetX float64
+	}{
+		{TextAlignLastAuto, 50},
+		{TextAlignLastStart, 50},
+		{TextAlignLastEnd, 0},
+		{TextAlignLastLeft, 0},
+	}
+	for _, tt := range tests {
+		node := Text("אבג דהו זחט", Style{
+			Width:     Px(80),
+			TextStyle: &TextStyle{FontSize: 16, Direction: DirectionRTL, TextAlign: TextAlignJustify, TextAlignLast: tt.last},
+		})
+		LayoutText(node, Loose(80, 100), NewLayoutContext(800, 600, 16))
+
+		lines := node.TextLayout.Lines
+		if lines[0].OffsetX != 0 || math.Abs(lines[0].Width-80) > 0.01 {
+			t.Errorf("Expected the first line justified across 80px, got x %.2f width %.2f", lines[0].OffsetX, lines[0].Width)
+		}
+		if got := lines[1].OffsetX; got != tt.offsetX {
+			t.Errorf("Expected text-align-last %d at x %.2f, got %.2f", tt.last, tt.offsetX, got)
+		}
+	}
+}
+
+func TestInlineBoxVisualText(t *testing.T) {
+	tests := []struct {
+		box  InlineBox
+		want string
+	}{
+		{InlineBox{Text: "abc"}, "abc"},
+		{InlineBox{Text: "אבג", Level: 1}, "גבא"},
+		{InlineBox{Text: "123", Level: 2}, "123"},
+		{InlineBox{Text: "אב12", Level: 1}, "12בא"},
+	}
+	for _, tt := range tests {
+		if got := tt.box.VisualText(); got != tt.want {
+			t.Errorf("Expected %q to display as %q, got %q", tt.box.Text, tt.want, got)
+		}
+	}
+}
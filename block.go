@@ -15,6 +15,8 @@ package layout
 func LayoutBlock(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	// Get current font size for em resolution
 	currentFontSize := getCurrentFontSize(node, ctx)
+	restoreInherited := inheritStyle(ctx, node, currentFontSize)
+	defer restoreInherited()
 
 	// §4: Box Model - Setup and determine container dimensions
 	setup := blockDetermineContainerSize(node, constraints, ctx, currentFontSize)
@@ -25,6 +27,17 @@ func LayoutBlock(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	// §5: Intrinsic Size Determination - Apply min/max constraints
 	nodeWidth, nodeHeight = blockApplyConstraints(node, setup, nodeWidth, nodeHeight, aspectRatioCalculatedWidth, aspectRatioCalculatedHeight)
 
+	// Children's percentage sizes are taken of the content box; an auto
+	// height is not definite
+	percentHeight := percentSizeIndefinite
+	if !setup.isAutoHeight {
+		percentHeight = nodeHeight
+	}
+	restore := resolvePercentSizes(node.Children, nodeWidth, percentHeight)
+	defer restore()
+	restoreContainer := enterQueryContainer(node, ctx, nodeWidth, percentHeight)
+	defer restoreContainer()
+
 	// §8.3.1: Collapsing margins - Layout children with margin collapsing
 	currentBlockPos, maxCrossSize := blockLayoutChildren(node, setup, nodeWidth, ctx, currentFontSize)
 
@@ -134,10 +147,17 @@ func max(a, b float64) float64 {
 }
 
 // getCurrentFontSize returns the current font size for Length resolution.
-// Falls back to ctx.RootFontSize if the node's TextStyle is not set.
+// A node without its own TextStyle.FontSize inherits its parent's font size
+// during layout, and ctx.RootFontSize at the root (16 without a context).
 func getCurrentFontSize(node *Node, ctx *LayoutContext) float64 {
 	if node.Style.TextStyle != nil && node.Style.TextStyle.FontSize > 0 {
 		return node.Style.TextStyle.FontSize
 	}
+	if ctx == nil {
+		return 16
+	}
+	if ctx.inheritedFontSize > 0 {
+		return ctx.inheritedFontSize
+	}
 	return ctx.RootFontSize
 }
@@ -19,13 +19,20 @@ package layout
 // 3. Parent and last child end margins collapse if no border/padding/size separates them
 // 4. Empty block margins collapse with themselves
 //
-// For this implementation, we focus on the most common case:
-// - Adjacent sibling margins collapse (rule 1)
+// By default only adjacent sibling margins collapse (rule 1). Containers with
+// MarginCollapseFull apply all four rules; see blockLayoutChildrenCollapsing.
 func blockLayoutChildren(node *Node, setup blockSetup, nodeWidth float64, ctx *LayoutContext, parentFontSize float64) (currentBlockPos, maxCrossSize float64) {
 	children := node.Children
 	writingMode := node.Style.WritingMode
 	isVertical := writingMode.IsVertical()
 
+	if isMultiColumn(&node.Style) && !isVertical {
+		return blockLayoutColumns(node, setup, nodeWidth, ctx, parentFontSize)
+	}
+	if node.Style.MarginCollapse == MarginCollapseFull && !isVertical {
+		return blockLayoutChildrenCollapsing(node, nodeWidth, ctx, parentFontSize)
+	}
+
 	currentBlockPos = 0.0
 	maxCrossSize = 0.0
 
@@ -107,16 +114,9 @@ func blockLayoutChildren(node *Node, setup blockSetup, nodeWidth float64, ctx *L
 		}
 
 		// Layout child
-		var childSize Size
-		if child.Style.Display == DisplayFlex {
-			childSize = LayoutFlexbox(child, childConstraints, ctx)
-		} else if child.Style.Display == DisplayGrid {
-			childSize = LayoutGrid(child, childConstraints, ctx)
-		} else if child.Style.Display == DisplayInlineText {
-			childSize = LayoutText(child, childConstraints, ctx)
-		} else {
-			childSize = LayoutBlock(child, childConstraints, ctx)
-		}
+		childSize := cachedLayout(child, childConstraints, ctx, func() Size {
+			return layoutByDisplay(child, childConstraints, ctx)
+		})
 
 		// Resolve parent's padding and border for positioning
 		parentPaddingLeft := ResolveLength(node.Style.Padding.Left, ctx, parentFontSize)
@@ -0,0 +1,223 @@
+package layout
+
+// collapsedMargin is a set of adjoining margins that collapse into one:
+// the largest positive margin plus the most negative margin.
+//
+// See: https://www.w3.org/TR/CSS2/box.html#collapsing-margins
+type collapsedMargin struct {
+	positive float64
+	negative float64
+}
+
+// with adds a margin to the set.
+func (m collapsedMargin) with(margin float64) collapsedMargin {
+	if margin > 0 {
+		m.positive = max(m.positive, margin)
+	} else {
+		m.negative = min(m.negative, margin)
+	}
+	return m
+}
+
+// join merges two sets of adjoining margins.
+func (m collapsedMargin) join(o collapsedMargin) collapsedMargin {
+	return m.with(o.positive).with(o.negative)
+}
+
+// value returns the size of the collapsed margin.
+func (m collapsedMargin) value() float64 {
+	return m.positive + m.negative
+}
+
+// blockMargins describes how a child's block-start and block-end margins
+// take part in margin collapsing in a MarginCollapseFull parent.
+type blockMargins struct {
+	// start and end are the child's own margins, joined with the margins
+	// that collapse through its top and bottom edges from its descendants.
+	start collapsedMargin
+	end   collapsedMargin
+
+	// leading is how much of start the child placed inside its own box
+	// when it was laid out; the parent shifts the child's children up by
+	// it. shrink is how much the child's auto height grew from margins
+	// that collapsed out of it; the parent removes it.
+	leading float64
+	shrink  float64
+
+	// through reports an empty child whose start and end margins collapse
+	// with each other (and with the siblings on both sides).
+	through bool
+}
+
+// blockCollapsedMargins computes child's collapsing margins, matching how
+// blockLayoutChildren laid child out.
+//
+// A block child's first child's start margin collapses through its top edge
+// unless padding or a border separates them; its last child's end margin
+// collapses through its bottom edge when, in addition, its height is auto
+// and it has no min or max height.
+//
+// Algorithm based on CSS 2.2:
+// - §8.3.1: Collapsing margins
+//
+// See: https://www.w3.org/TR/CSS2/box.html#collapsing-margins
+func blockCollapsedMargins(child *Node, ctx *LayoutContext) blockMargins {
+	fontSize := getCurrentFontSize(child, ctx)
+	s := &child.Style
+	m := blockMargins{
+		start: collapsedMargin{}.with(ResolveLength(s.Margin.Top, ctx, fontSize)),
+		end:   collapsedMargin{}.with(ResolveLength(s.Margin.Bottom, ctx, fontSize)),
+	}
+
+	// Flex, grid and text boxes and vertical writing modes keep their
+	// children's margins inside.
+	if s.Display != DisplayBlock || child.Text != "" || s.WritingMode.IsVertical() {
+		return m
+	}
+
+	height := ResolveLength(s.Height, ctx, fontSize)
+	maxHeight := ResolveLength(s.MaxHeight, ctx, fontSize)
+	autoHeight := height < 0 && s.HeightSizing == IntrinsicSizeNone &&
+		ResolveLength(s.MinHeight, ctx, fontSize) <= 0 && (maxHeight <= 0 || maxHeight >= Unbounded)
+	topOpen := ResolveLength(s.Padding.Top, ctx, fontSize)+ResolveLength(s.Border.Top, ctx, fontSize) == 0
+	bottomOpen := ResolveLength(s.Padding.Bottom, ctx, fontSize)+ResolveLength(s.Border.Bottom, ctx, fontSize) == 0
+
+	var children []blockMargins
+	for _, c := range child.Children {
+		if c.Style.Display == DisplayNone {
+			continue
+		}
+		if s.MarginCollapse == MarginCollapseFull && !s.WritingMode.IsVertical() {
+			children = append(children, blockCollapsedMargins(c, ctx))
+			continue
+		}
+		// Sibling-only collapsing places the first and last child at
+		// their own margins and never collapses through a child
+		cf := getCurrentFontSize(c, ctx)
+		children = append(children, blockMargins{
+			start: collapsedMargin{}.with(ResolveLength(c.Style.Margin.Top, ctx, cf)),
+			end:   collapsedMargin{}.with(ResolveLength(c.Style.Margin.Bottom, ctx, cf)),
+		})
+	}
+
+	// Margins the child's layout placed before its first in-flow content
+	var leading collapsedMargin
+	first := 0
+	for ; first < len(children); first++ {
+		leading = leading.join(children[first].start)
+		if !children[first].through {
+			break
+		}
+		leading = leading.join(children[first].end)
+	}
+
+	if first == len(children) && topOpen && bottomOpen && (autoHeight || height == 0) {
+		// No content: everything collapses through
+		m.through = true
+		m.start = m.start.join(leading).join(m.end)
+		m.end = collapsedMargin{}
+		m.leading = leading.value()
+		m.shrink = leading.value()
+		return m
+	}
+	if first == len(children) {
+		// Only collapsed-through children; their margins end up on one side
+		if autoHeight && bottomOpen {
+			m.end = m.end.join(leading)
+			m.shrink = leading.value()
+		} else if topOpen {
+			m.start = m.start.join(leading)
+			m.leading = leading.value()
+			if autoHeight {
+				m.shrink = leading.value()
+			}
+		}
+		return m
+	}
+
+	if topOpen {
+		m.start = m.start.join(leading)
+		m.leading = leading.value()
+		if autoHeight {
+			m.shrink = leading.value()
+		}
+	}
+	if autoHeight && bottomOpen {
+		var trailing collapsedMargin
+		for last := len(children) - 1; last >= 0; last-- {
+			trailing = trailing.join(children[last].end)
+			if !children[last].through {
+				break
+			}
+			trailing = trailing.join(children[last].start)
+		}
+		m.end = m.end.join(trailing)
+		m.shrink += trailing.value()
+	}
+	return m
+}
+
+// blockLayoutChildrenCollapsing lays out the children of a
+// MarginCollapseFull block container in a horizontal writing mode. Margins
+// that collapse through a child's edges are moved out of the child: its
+// children are shifted up and its height is reduced accordingly.
+//
+// Returns the block size of the content (including the end margin of the
+// last child, unless it collapses out of node too) and the largest inline
+// size including margins.
+func blockLayoutChildrenCollapsing(node *Node, nodeWidth float64, ctx *LayoutContext, parentFontSize float64) (currentBlockPos, maxCrossSize float64) {
+	childConstraints := Constraints{
+		MinWidth:  0,
+		MaxWidth:  nodeWidth,
+		MinHeight: 0,
+		MaxHeight: Unbounded,
+	}
+	contentLeft := ResolveLength(node.Style.Padding.Left, ctx, parentFontSize) + ResolveLength(node.Style.Border.Left, ctx, parentFontSize)
+	contentTop := ResolveLength(node.Style.Padding.Top, ctx, parentFontSize) + ResolveLength(node.Style.Border.Top, ctx, parentFontSize)
+
+	var pending collapsedMargin
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone {
+			continue
+		}
+		childFontSize := getCurrentFontSize(child, ctx)
+		marginLeft := ResolveLength(child.Style.Margin.Left, ctx, childFontSize)
+		marginRight := ResolveLength(child.Style.Margin.Right, ctx, childFontSize)
+
+		margins := blockCollapsedMargins(child, ctx)
+		childSize := cachedLayout(child, childConstraints, ctx, func() Size {
+			return layoutByDisplay(child, childConstraints, ctx)
+		})
+		pending = pending.join(margins.start)
+
+		// Move margins that collapse through the child's edges out of it
+		if margins.leading != 0 {
+			for _, grandchild := range child.Children {
+				grandchild.Rect.Y -= margins.leading
+			}
+		}
+		height := childSize.Height - margins.shrink
+		if margins.through || height < 0 {
+			height = 0
+		}
+
+		child.Rect = Rect{
+			X:      contentLeft + marginLeft,
+			Y:      contentTop + currentBlockPos + pending.value(),
+			Width:  childSize.Width,
+			Height: height,
+		}
+		if cross := childSize.Width + marginLeft + marginRight; cross > maxCrossSize {
+			maxCrossSize = cross
+		}
+
+		if margins.through {
+			pending = pending.join(margins.end)
+			continue
+		}
+		currentBlockPos += pending.value() + height
+		pending = margins.end
+	}
+
+	return currentBlockPos + pending.value(), maxCrossSize
+}
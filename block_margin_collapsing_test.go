@@ -269,3 +269,118 @@ func TestBlockMarginCollapsingNestedFlex(t *testing.T) {
 		t.Errorf("Second child Y: expected %.2f, got %.2f", expectedY, root.Children[1].Rect.Y)
 	}
 }
+
+// collapsingBlock returns an auto-height block with full margin collapsing
+func collapsingBlock(margin Spacing, children ...*Node) *Node {
+	return &Node{
+		Style: Style{
+			Display:        DisplayBlock,
+			Width:          Px(-1),
+			Height:         Px(-1),
+			Margin:         margin,
+			MarginCollapse: MarginCollapseFull,
+		},
+		Children: children,
+	}
+}
+
+// TestBlockMarginCollapseFullParentFirstChild tests that a first child's top
+// margin collapses through its parent's top edge
+func TestBlockMarginCollapseFullParentFirstChild(t *testing.T) {
+	header := Fixed(100, 40)
+	heading := &Node{Style: Style{Display: DisplayBlock, Height: Px(30), Margin: Spacing{Top: Px(20), Bottom: Px(10)}}}
+	section := collapsingBlock(Spacing{Top: Px(12), Bottom: Px(5)}, heading)
+	root := collapsingBlock(Spacing{}, header, section)
+	root.Style.Width = Px(200)
+
+	Layout(root, Loose(200, 400), NewLayoutContext(200, 400, 16))
+
+	// The section's 12px and the heading's 20px margins collapse to 20
+	if section.Rect.Y != 60 {
+		t.Errorf("Section Y: expected 60, got %.2f", section.Rect.Y)
+	}
+	if heading.Rect.Y != 0 {
+		t.Errorf("Heading Y: expected 0 inside the section, got %.2f", heading.Rect.Y)
+	}
+	// The heading's 10px bottom margin collapses with the section's 5px
+	if section.Rect.Height != 30 {
+		t.Errorf("Section height: expected 30, got %.2f", section.Rect.Height)
+	}
+	// The root is a formatting context root, so the last margin stays inside it
+	if root.Rect.Height != 100 {
+		t.Errorf("Root height: expected 100, got %.2f", root.Rect.Height)
+	}
+}
+
+// TestBlockMarginCollapseFullPaddingSeparates tests that padding stops
+// margins from collapsing through a parent's edge
+func TestBlockMarginCollapseFullPaddingSeparates(t *testing.T) {
+	child := &Node{Style: Style{Display: DisplayBlock, Height: Px(30), Margin: Spacing{Top: Px(20), Bottom: Px(20)}}}
+	box := collapsingBlock(Spacing{Top: Px(10)}, child)
+	box.Style.Padding = Spacing{Top: Px(1)}
+	root := collapsingBlock(Spacing{}, box)
+	root.Style.Width = Px(200)
+
+	Layout(root, Loose(200, 400), NewLayoutContext(200, 400, 16))
+
+	if box.Rect.Y != 10 || child.Rect.Y != 21 {
+		t.Errorf("Expected box at 10 and child at 21, got %.2f and %.2f", box.Rect.Y, child.Rect.Y)
+	}
+	// The bottom edge is open, so the child's bottom margin moves out of the box
+	if box.Rect.Height != 51 {
+		t.Errorf("Box height: expected 51, got %.2f", box.Rect.Height)
+	}
+}
+
+// TestBlockMarginCollapseFullEmptyBlock tests that an empty block's margins
+// collapse through it and with both neighbours
+func TestBlockMarginCollapseFullEmptyBlock(t *testing.T) {
+	first := &Node{Style: Style{Display: DisplayBlock, Height: Px(20), Margin: Spacing{Bottom: Px(10)}}}
+	empty := &Node{Style: Style{Display: DisplayBlock, Margin: Spacing{Top: Px(25), Bottom: Px(15)}}}
+	last := &Node{Style: Style{Display: DisplayBlock, Height: Px(20), Margin: Spacing{Top: Px(5)}}}
+	root := collapsingBlock(Spacing{}, first, empty, last)
+	root.Style.Width = Px(200)
+
+	Layout(root, Loose(200, 400), NewLayoutContext(200, 400, 16))
+
+	if last.Rect.Y != 45 {
+		t.Errorf("Last Y: expected 45 (20 + max(10, 25, 15, 5)), got %.2f", last.Rect.Y)
+	}
+	if empty.Rect.Height != 0 {
+		t.Errorf("Empty block height: expected 0, got %.2f", empty.Rect.Height)
+	}
+}
+
+// TestBlockMarginCollapseFullNegativeMargins tests that positive and
+// negative margins combine instead of taking the maximum
+func TestBlockMarginCollapseFullNegativeMargins(t *testing.T) {
+	first := &Node{Style: Style{Display: DisplayBlock, Height: Px(20), Margin: Spacing{Bottom: Px(30)}}}
+	second := &Node{Style: Style{Display: DisplayBlock, Height: Px(20), Margin: Spacing{Top: Px(-10)}}}
+	root := collapsingBlock(Spacing{}, first, second)
+	root.Style.Width = Px(200)
+
+	Layout(root, Loose(200, 400), NewLayoutContext(200, 400, 16))
+
+	if second.Rect.Y != 40 {
+		t.Errorf("Second Y: expected 40 (20 + 30 - 10), got %.2f", second.Rect.Y)
+	}
+}
+
+// TestBlockMarginCollapseFullNested tests margins collapsing through several
+// levels of nesting
+func TestBlockMarginCollapseFullNested(t *testing.T) {
+	leaf := &Node{Style: Style{Display: DisplayBlock, Height: Px(10), Margin: Spacing{Top: Px(30)}}}
+	inner := collapsingBlock(Spacing{Top: Px(10)}, leaf)
+	outer := collapsingBlock(Spacing{Top: Px(20)}, inner)
+	root := collapsingBlock(Spacing{}, Fixed(50, 10), outer)
+	root.Style.Width = Px(200)
+
+	Layout(root, Loose(200, 400), NewLayoutContext(200, 400, 16))
+
+	if outer.Rect.Y != 40 || inner.Rect.Y != 0 || leaf.Rect.Y != 0 {
+		t.Errorf("Expected outer at 40 with inner and leaf at 0, got %.2f, %.2f, %.2f", outer.Rect.Y, inner.Rect.Y, leaf.Rect.Y)
+	}
+	if outer.Rect.Height != 10 || root.Rect.Height != 50 {
+		t.Errorf("Expected outer height 10 and root height 50, got %.2f and %.2f", outer.Rect.Height, root.Rect.Height)
+	}
+}
@@ -84,9 +84,11 @@ func blockDetermineContainerSize(node *Node, constraints Constraints, ctx *Layou
 	setup.specifiedHeight = convertToContentSize(heightValue, node.Style.BoxSizing, setup.horizontalPaddingBorder, setup.verticalPaddingBorder, false)
 
 	// Determine if dimensions are auto
-	// CRITICAL FIX: Treat 0 as auto when aspect ratio is set (Go zero value issue)
-	setup.isAutoWidth = setup.specifiedWidth < 0 || (setup.specifiedWidth == 0 && node.Style.AspectRatio > 0 && setup.specifiedHeight == 0)
-	setup.isAutoHeight = setup.specifiedHeight < 0 || (setup.specifiedHeight == 0 && node.Style.AspectRatio > 0 && setup.specifiedWidth == 0)
+	// CRITICAL FIX: Treat 0 as auto when aspect ratio is set (Go zero value issue).
+	// An unset (zero Length) dimension is always auto with an aspect ratio,
+	// so Width: Px(80) alone derives the height.
+	setup.isAutoWidth = setup.specifiedWidth < 0 || (setup.specifiedWidth == 0 && node.Style.AspectRatio > 0 && (setup.specifiedHeight == 0 || node.Style.Width.Unit == ""))
+	setup.isAutoHeight = setup.specifiedHeight < 0 || (setup.specifiedHeight == 0 && node.Style.AspectRatio > 0 && (setup.specifiedWidth == 0 || node.Style.Height.Unit == ""))
 
 	// Resolve min/max constraints to pixels
 	minWidthValue := ResolveLength(node.Style.MinWidth, ctx, currentFontSize)
@@ -0,0 +1,65 @@
+package layout
+
+// GetTransformedBounds returns the axis-aligned bounding box of node's
+// border box in root's coordinates (those of root.Rect), after the
+// transforms of node and its ancestors and the scroll positions of its
+// scroll containers. Unlike GetFinalRect, which applies only node's own
+// transform, it accounts for every transformed ancestor. It returns false
+// if node isn't in root's tree. Nodes don't know their parents, so the
+// search for node starts at root.
+//
+// Example:
+//
+//	bounds, _ := layout.GetTransformedBounds(root, card)
+//	// Redraw bounds when card changes
+func GetTransformedBounds(root, node *Node) (Rect, bool) {
+	toRoot, ok := nodeToRoot(root, node)
+	if !ok {
+		return Rect{}, false
+	}
+	return toRoot.ApplyToRect(Rect{Width: node.Rect.Width, Height: node.Rect.Height}), true
+}
+
+// PointInNode reports whether the point (x, y), in root's coordinates, is
+// inside node's border box once the transforms of node and its ancestors
+// are applied. It undoes the transforms rather than testing the bounding
+// box, so it's exact for rotated and skewed boxes. It's false if node
+// isn't in root's tree or a transform flattens it.
+//
+// Example:
+//
+//	for _, card := range cards {
+//	    if layout.PointInNode(root, card, mouseX, mouseY) {
+//	        selected = card
+//	    }
+//	}
+func PointInNode(root, node *Node, x, y float64) bool {
+	toRoot, ok := nodeToRoot(root, node)
+	if !ok {
+		return false
+	}
+	toNode, ok := toRoot.Invert()
+	if !ok {
+		return false
+	}
+	p := toNode.Apply(Point{X: x, Y: y})
+	return p.X >= 0 && p.Y >= 0 && p.X < node.Rect.Width && p.Y < node.Rect.Height
+}
+
+// nodeToRoot returns the transform from node's own coordinates, relative
+// to its Rect's origin, to root's, and false if node isn't in root's tree.
+func nodeToRoot(root, node *Node) (Transform, bool) {
+	path := pathTo(root, node)
+	if path == nil {
+		return Transform{}, false
+	}
+	toRoot := IdentityTransform()
+	for i, n := range path {
+		var parent *Node
+		if i > 0 {
+			parent = path[i-1]
+		}
+		toRoot = childToRoot(toRoot, parent, n)
+	}
+	return toRoot, true
+}
@@ -0,0 +1,61 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransformedBounds(t *testing.T) {
+	card := &Node{Style: Style{Width: Px(100), Height: Px(100), Transform: RotateDegrees(45)}}
+	group := &Node{
+		Style:    Style{Width: Px(300), Height: Px(300), Transform: Translate(100, 0)},
+		Children: []*Node{card},
+	}
+	root := &Node{Style: Style{Width: Px(400), Height: Px(400)}, Children: []*Node{group}}
+	Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	// The card turns into a diamond with its top corner at (100, 0)
+	half := 100 / math.Sqrt2
+	bounds, ok := GetTransformedBounds(root, card)
+	want := Rect{X: 100 - half, Y: 0, Width: 2 * half, Height: 2 * half}
+	if !ok || math.Abs(bounds.X-want.X) > 1e-9 || math.Abs(bounds.Y-want.Y) > 1e-9 ||
+		math.Abs(bounds.Width-want.Width) > 1e-9 || math.Abs(bounds.Height-want.Height) > 1e-9 {
+		t.Errorf("Expected bounds %+v, got %+v (%v)", want, bounds, ok)
+	}
+
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{100, 70, true},   // Center
+		{160, 10, false},  // In the bounding box, outside the diamond
+		{40, 130, false},  // Likewise
+		{100, 140, true},  // Near the bottom corner
+		{300, 300, false}, // Outside
+	}
+	for _, tt := range tests {
+		if got := PointInNode(root, card, tt.x, tt.y); got != tt.want {
+			t.Errorf("PointInNode(%v, %v): expected %v, got %v", tt.x, tt.y, tt.want, got)
+		}
+	}
+
+	if _, ok := GetTransformedBounds(root, &Node{}); ok {
+		t.Error("Expected no bounds for a node outside the tree")
+	}
+	card.Style.Transform = Scale(0, 1)
+	if PointInNode(root, card, 100, 50) {
+		t.Error("Expected no hit on a flattened node")
+	}
+}
+
+func TestTransformInvert(t *testing.T) {
+	tr := Translate(10, 20).Multiply(RotateDegrees(30)).Multiply(Scale(2, 3))
+	inv, ok := tr.Invert()
+	if !ok {
+		t.Fatal("Expected the transform to be invertible")
+	}
+	p := inv.Apply(tr.Apply(Point{X: 7, Y: -4}))
+	if math.Abs(p.X-7) > 1e-9 || math.Abs(p.Y+4) > 1e-9 {
+		t.Errorf("Expected the inverse to undo the transform, got %+v", p)
+	}
+}
@@ -0,0 +1,439 @@
+package layout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SCKelemen/units"
+)
+
+// calc() expressions
+//
+// A calc() Length keeps its expression in the unit: Calc(Percent(100),
+// CalcSub, Px(32)) is Length{Value: 1, Unit: "calc(100% - 32px)"}. That way
+// a calc() length is still a plain comparable value that can be hashed,
+// copied and serialized like any other. The expression is parsed once and
+// resolved at layout time, when the percentage base and font size are
+// known. Value scales the result.
+//
+// Percentages in an expression are taken of the same reference size as a
+// bare Percent for that property; where a Percent resolves to 0, so do the
+// percentage terms of an expression.
+//
+// See: https://www.w3.org/TR/css-values-4/#calc-func
+
+// CalcOp is an arithmetic operator in a calc() expression.
+type CalcOp int
+
+const (
+	CalcAdd CalcOp = iota // a + b
+	CalcSub               // a - b
+	CalcMul               // a * b, where b is a number
+	CalcDiv               // a / b, where b is a number
+)
+
+// calcPrefix starts the unit of every calc() Length.
+const calcPrefix = "calc("
+
+// Calc creates a calc() Length that combines a and b with op. Either
+// operand may itself be a calc() Length. For CalcMul and CalcDiv, b is a
+// plain number: only its Value is used.
+//
+// Example:
+//
+//	// width: calc(100% - 32px)
+//	node.Style.Width = layout.Calc(layout.Percent(100), layout.CalcSub, layout.Px(32))
+//
+//	// width: calc((100% - 2em) / 3)
+//	third := layout.Calc(layout.Calc(layout.Percent(100), layout.CalcSub, layout.Em(2)), layout.CalcDiv, layout.Length{Value: 3})
+func Calc(a Length, op CalcOp, b Length) Length {
+	left := calcOperand(a)
+	var right *calcNode
+	if op == CalcMul || op == CalcDiv {
+		right = &calcNode{leaf: Length{Value: b.Value}}
+	} else {
+		right = calcOperand(b)
+	}
+	n := &calcNode{op: "+-*/"[op], a: left, b: right}
+	return Length{Value: 1, Unit: LengthUnit(n.String())}
+}
+
+// ParseCalc parses a CSS calc() expression such as "calc(100% - 32px)".
+// Operands are lengths, percentages and plain numbers; + and - must be
+// surrounded by whitespace, as in CSS. Units the engine doesn't know are
+// kept for the context's UnitResolver.
+//
+// Example:
+//
+//	width, err := layout.ParseCalc("calc(50% + 2rem)")
+func ParseCalc(s string) (Length, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(strings.ToLower(s), calcPrefix) {
+		return Length{}, fmt.Errorf("layout: %q is not a calc() expression", s)
+	}
+	n, err := parseCalcExpression(s)
+	if err != nil {
+		return Length{}, err
+	}
+	return Length{Value: 1, Unit: LengthUnit(n.String())}, nil
+}
+
+// isCalc reports whether l is a calc() Length.
+func isCalc(l Length) bool {
+	return strings.HasPrefix(string(l.Unit), calcPrefix)
+}
+
+// lengthHasPercent reports whether l is a percentage or a calc() Length
+// with a percentage term, so that it needs a reference size to resolve.
+func lengthHasPercent(l Length) bool {
+	if l.Unit == PercentUnit {
+		return true
+	}
+	if !isCalc(l) {
+		return false
+	}
+	n, err := cachedCalcExpression(l.Unit)
+	return err == nil && n.hasPercent()
+}
+
+// resolveCalc resolves a calc() Length, taking percentages of base. A
+// malformed expression resolves like an unknown unit, to l.Value.
+func resolveCalc(l Length, base float64, ctx *LayoutContext, currentFontSize float64) float64 {
+	n, err := cachedCalcExpression(l.Unit)
+	if err != nil {
+		return l.Value
+	}
+	return l.Value * n.eval(base, ctx, currentFontSize)
+}
+
+// calcWithPercentBase replaces the percentage terms of a calc() Length with
+// pixels taken of base. The other terms are still resolved at layout time.
+func calcWithPercentBase(l Length, base float64) Length {
+	n, err := cachedCalcExpression(l.Unit)
+	if err != nil {
+		return l
+	}
+	return Length{Value: l.Value, Unit: LengthUnit(n.withPercentBase(base).String())}
+}
+
+// calcExpressions caches parsed expressions by unit.
+var calcExpressions sync.Map // LengthUnit → calcParsed
+
+type calcParsed struct {
+	node *calcNode
+	err  error
+}
+
+func cachedCalcExpression(unit LengthUnit) (*calcNode, error) {
+	if p, ok := calcExpressions.Load(unit); ok {
+		return p.(calcParsed).node, p.(calcParsed).err
+	}
+	n, err := parseCalcExpression(string(unit))
+	calcExpressions.Store(unit, calcParsed{n, err})
+	return n, err
+}
+
+// calcNode is a node of a parsed calc() expression: a leaf (op 0) holding
+// a length, a percentage or (with the zero unit) a number, or a binary
+// operation.
+type calcNode struct {
+	op   byte
+	leaf Length
+	a, b *calcNode
+}
+
+// calcOperand returns the expression for an operand of Calc.
+func calcOperand(l Length) *calcNode {
+	if !isCalc(l) {
+		return &calcNode{leaf: l}
+	}
+	n, err := cachedCalcExpression(l.Unit)
+	if err != nil {
+		return &calcNode{leaf: Px(0)}
+	}
+	if l.Value != 1 {
+		n = &calcNode{op: '*', a: n, b: &calcNode{leaf: Length{Value: l.Value}}}
+	}
+	return n
+}
+
+// isNumber reports whether n evaluates to a number rather than a length.
+func (n *calcNode) isNumber() bool {
+	switch n.op {
+	case 0:
+		return n.leaf.Unit == ""
+	case '*':
+		return n.a.isNumber() && n.b.isNumber()
+	default:
+		return n.a.isNumber()
+	}
+}
+
+func (n *calcNode) hasPercent() bool {
+	if n.op == 0 {
+		return n.leaf.Unit == PercentUnit
+	}
+	return n.a.hasPercent() || n.b.hasPercent()
+}
+
+func (n *calcNode) eval(base float64, ctx *LayoutContext, fontSize float64) float64 {
+	switch n.op {
+	case 0:
+		switch n.leaf.Unit {
+		case "":
+			return n.leaf.Value
+		case PercentUnit:
+			return base * n.leaf.Value / 100
+		}
+		return ResolveLength(n.leaf, ctx, fontSize)
+	case '+':
+		return n.a.eval(base, ctx, fontSize) + n.b.eval(base, ctx, fontSize)
+	case '-':
+		return n.a.eval(base, ctx, fontSize) - n.b.eval(base, ctx, fontSize)
+	case '*':
+		return n.a.eval(base, ctx, fontSize) * n.b.eval(base, ctx, fontSize)
+	default:
+		divisor := n.b.eval(base, ctx, fontSize)
+		if divisor == 0 {
+			return 0
+		}
+		return n.a.eval(base, ctx, fontSize) / divisor
+	}
+}
+
+func (n *calcNode) withPercentBase(base float64) *calcNode {
+	if n.op == 0 {
+		if n.leaf.Unit == PercentUnit {
+			return &calcNode{leaf: Px(base * n.leaf.Value / 100)}
+		}
+		return n
+	}
+	return &calcNode{op: n.op, a: n.a.withPercentBase(base), b: n.b.withPercentBase(base)}
+}
+
+// String returns the expression in canonical CSS form, e.g.
+// "calc(100% - 32px)".
+func (n *calcNode) String() string {
+	var sb strings.Builder
+	sb.WriteString(calcPrefix)
+	n.write(&sb)
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+func (n *calcNode) write(sb *strings.Builder) {
+	if n.op == 0 {
+		sb.WriteString(strconv.FormatFloat(n.leaf.Value, 'g', -1, 64))
+		sb.WriteString(string(n.leaf.Unit))
+		return
+	}
+	product := n.op == '*' || n.op == '/'
+	n.a.writeOperand(sb, product && n.a.isSum())
+	sb.WriteByte(' ')
+	sb.WriteByte(n.op)
+	sb.WriteByte(' ')
+	// The right operand needs parentheses if it would otherwise bind to
+	// the left: a - (b + c), a * (b + c), a / (b * c)
+	n.b.writeOperand(sb, (product && n.b.isSum()) || (n.op == '-' && n.b.isSum()) || (n.op == '/' && n.b.op != 0))
+}
+
+func (n *calcNode) isSum() bool {
+	return n.op == '+' || n.op == '-'
+}
+
+func (n *calcNode) writeOperand(sb *strings.Builder, parens bool) {
+	if parens {
+		sb.WriteByte('(')
+	}
+	n.write(sb)
+	if parens {
+		sb.WriteByte(')')
+	}
+}
+
+// calcUnits are the built-in units, which calc() expressions accept in
+// any case.
+var calcUnits = func() map[string]LengthUnit {
+	m := make(map[string]LengthUnit)
+	for _, u := range []LengthUnit{
+		units.PX, units.CM, units.MM, units.QQ, units.IN, units.PT, units.PC,
+		units.EM, units.REM, units.EX, units.REX, units.CAP, units.RCAP,
+		units.CH, units.RCH, units.IC, units.RIC, units.LH, units.RLH,
+		units.VW, units.VH, units.VMIN, units.VMAX, units.VB, units.VI,
+		units.SVW, units.SVH, units.SVB, units.SVI,
+		units.LVW, units.LVH, units.LVB, units.LVI,
+		units.DVW, units.DVH, units.DVB, units.DVI,
+		units.CQW, units.CQH, units.CQI, units.CQB, units.CQMIN, units.CQMAX,
+	} {
+		m[strings.ToLower(string(u))] = u
+	}
+	return m
+}()
+
+// calcParser is a recursive-descent parser for calc() expressions:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = value { ("*" | "/") value }
+//	value   = number [unit | "%"] | "(" sum ")" | "calc(" sum ")"
+type calcParser struct {
+	s   string
+	pos int
+}
+
+func parseCalcExpression(s string) (*calcNode, error) {
+	p := &calcParser{s: s}
+	n, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	if n.isNumber() {
+		return nil, p.errorf("expression is a number, not a length")
+	}
+	return n, nil
+}
+
+func (p *calcParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("layout: invalid calc() %q: %s", p.s, fmt.Sprintf(format, args...))
+}
+
+func (p *calcParser) digits() {
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+}
+
+func (p *calcParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *calcParser) sum() (*calcNode, error) {
+	n, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.pos
+		p.skipSpace()
+		if p.pos >= len(p.s) || (p.s[p.pos] != '+' && p.s[p.pos] != '-') {
+			return n, nil
+		}
+		op := p.s[p.pos]
+		if p.pos == start || p.pos+1 >= len(p.s) || p.s[p.pos+1] != ' ' {
+			return nil, p.errorf("%q must be surrounded by whitespace", op)
+		}
+		p.pos++
+		b, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		if n.isNumber() != b.isNumber() {
+			return nil, p.errorf("cannot %s a number and a length", map[byte]string{'+': "add", '-': "subtract"}[op])
+		}
+		n = &calcNode{op: op, a: n, b: b}
+	}
+}
+
+func (p *calcParser) product() (*calcNode, error) {
+	n, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.pos
+		p.skipSpace()
+		if p.pos >= len(p.s) || (p.s[p.pos] != '*' && p.s[p.pos] != '/') {
+			// Leave the whitespace for sum to check
+			p.pos = start
+			return n, nil
+		}
+		op := p.s[p.pos]
+		p.pos++
+		b, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case op == '*' && !n.isNumber() && !b.isNumber():
+			return nil, p.errorf("cannot multiply two lengths")
+		case op == '/' && !b.isNumber():
+			return nil, p.errorf("cannot divide by a length")
+		case op == '/' && b.op == 0 && b.leaf.Value == 0:
+			return nil, p.errorf("division by zero")
+		}
+		if op == '*' && n.isNumber() {
+			// Keep the length on the left: 2 * 10px is 10px * 2
+			n, b = b, n
+		}
+		n = &calcNode{op: op, a: n, b: b}
+	}
+}
+
+func (p *calcParser) value() (*calcNode, error) {
+	p.skipSpace()
+	rest := p.s[p.pos:]
+	if strings.HasPrefix(strings.ToLower(rest), calcPrefix) || strings.HasPrefix(rest, "(") {
+		p.pos += strings.IndexByte(rest, '(') + 1
+		n, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	}
+
+	start := p.pos
+	if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+		p.pos++
+	}
+	p.digits()
+	if p.pos < len(p.s) && p.s[p.pos] == '.' {
+		p.pos++
+		p.digits()
+	}
+	// An exponent, but not the e of em or ex
+	if e := p.pos; e+1 < len(p.s) && (p.s[e] == 'e' || p.s[e] == 'E') {
+		d := e + 1
+		if p.s[d] == '+' || p.s[d] == '-' {
+			d++
+		}
+		if d < len(p.s) && p.s[d] >= '0' && p.s[d] <= '9' {
+			p.pos = d
+			p.digits()
+		}
+	}
+	value, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("expected a number at %q", rest)
+	}
+
+	unitStart := p.pos
+	if p.pos < len(p.s) && p.s[p.pos] == '%' {
+		p.pos++
+		return &calcNode{leaf: Percent(value)}, nil
+	}
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z' || p.s[p.pos] >= 'A' && p.s[p.pos] <= 'Z') {
+		p.pos++
+	}
+	name := p.s[unitStart:p.pos]
+	if name == "" {
+		return &calcNode{leaf: Length{Value: value}}, nil
+	}
+	unit, ok := calcUnits[strings.ToLower(name)]
+	if !ok {
+		// Left to the context's UnitResolver
+		unit = LengthUnit(name)
+	}
+	return &calcNode{leaf: Length{Value: value, Unit: unit}}, nil
+}
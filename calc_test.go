@@ -0,0 +1,112 @@
+package layout
+
+import "testing"
+
+func TestParseCalc(t *testing.T) {
+	tests := []struct {
+		input string
+		want  LengthUnit
+	}{
+		{"calc(100% - 32px)", "calc(100% - 32px)"},
+		{"calc( 50%   +  2rem )", "calc(50% + 2rem)"},
+		{"calc((100% - 2em) / 3)", "calc((100% - 2em) / 3)"},
+		{"calc(2 * 10px)", "calc(10px * 2)"},
+		{"calc(10px - (5px + 1px))", "calc(10px - (5px + 1px))"},
+		{"CALC(1.5EM)", "calc(1.5em)"},
+		{"calc(calc(10px + 5%) * -1)", "calc((10px + 5%) * -1)"},
+	}
+	for _, tt := range tests {
+		l, err := ParseCalc(tt.input)
+		if err != nil {
+			t.Errorf("ParseCalc(%q): unexpected error %v", tt.input, err)
+			continue
+		}
+		if l.Unit != tt.want || l.Value != 1 {
+			t.Errorf("ParseCalc(%q): expected %s, got %v %s", tt.input, tt.want, l.Value, l.Unit)
+		}
+	}
+
+	for _, input := range []string{
+		"100% - 32px",
+		"calc(100%-32px)",
+		"calc(10px * 2px)",
+		"calc(10px / 2px)",
+		"calc(10px / 0)",
+		"calc(10px + 2)",
+		"calc(3 * 2)",
+		"calc(10px",
+		"calc(10px) 5",
+	} {
+		if _, err := ParseCalc(input); err == nil {
+			t.Errorf("ParseCalc(%q): expected an error", input)
+		}
+	}
+}
+
+func TestCalcConstructor(t *testing.T) {
+	inner := Calc(Percent(100), CalcSub, Em(2))
+	if inner.Unit != "calc(100% - 2em)" {
+		t.Errorf("Expected calc(100%% - 2em), got %s", inner.Unit)
+	}
+	third := Calc(inner, CalcDiv, Length{Value: 3})
+	if third.Unit != "calc((100% - 2em) / 3)" {
+		t.Errorf("Expected calc((100%% - 2em) / 3), got %s", third.Unit)
+	}
+	nested := Calc(Px(10), CalcSub, Calc(Px(5), CalcAdd, Px(1)))
+	if nested.Unit != "calc(10px - (5px + 1px))" {
+		t.Errorf("Expected calc(10px - (5px + 1px)), got %s", nested.Unit)
+	}
+}
+
+func TestResolveCalc(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+	tests := []struct {
+		name string
+		l    Length
+		base float64
+		want float64
+	}{
+		{"px and em", Calc(Px(10), CalcAdd, Em(1)), 0, 30},
+		{"percent", Calc(Percent(100), CalcSub, Px(32)), 200, 168},
+		{"viewport", Calc(Vw(50), CalcSub, Px(100)), 0, 300},
+		{"division", Calc(Calc(Percent(100), CalcSub, Px(20)), CalcDiv, Length{Value: 3}), 320, 100},
+		{"scaled", Length{Value: 2, Unit: "calc(10px + 5px)"}, 0, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveLengthAgainst(tt.l, tt.base, ctx, 20); got != tt.want {
+				t.Errorf("Expected %.2f, got %.2f", tt.want, got)
+			}
+		})
+	}
+
+	// Without a reference size, percentage terms are 0
+	if got := ResolveLength(Calc(Percent(100), CalcSub, Px(32)), ctx, 16); got != -32 {
+		t.Errorf("Expected -32, got %.2f", got)
+	}
+}
+
+func TestCalcWidthInBlock(t *testing.T) {
+	child := &Node{Style: Style{Display: DisplayBlock, Width: Calc(Percent(100), CalcSub, Px(32)), Height: Px(20)}}
+	root := &Node{Style: Style{Display: DisplayBlock, Width: Px(300), Height: Px(-1)}, Children: []*Node{child}}
+	Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if child.Rect.Width != 268 {
+		t.Errorf("Expected 100%% - 32px of 300, got %.2f", child.Rect.Width)
+	}
+	if child.Style.Width != Calc(Percent(100), CalcSub, Px(32)) {
+		t.Errorf("Expected the style to be restored, got %v", child.Style.Width)
+	}
+}
+
+func TestCalcSizesInFlex(t *testing.T) {
+	a := &Node{Style: Style{Width: Calc(Percent(50), CalcSub, Px(10)), Height: Calc(Percent(100), CalcSub, Em(1))}}
+	row := HStack(a)
+	row.Style.Width = Px(400)
+	row.Style.Height = Px(100)
+	Layout(row, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if a.Rect.Width != 190 || a.Rect.Height != 84 {
+		t.Errorf("Expected 190x84, got %.2fx%.2f", a.Rect.Width, a.Rect.Height)
+	}
+}
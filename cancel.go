@@ -0,0 +1,72 @@
+package layout
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// LayoutWithContext lays out root like Layout, but gives up when goCtx is
+// done, so a pathological tree can't hang the caller. Use
+// context.WithTimeout to give a layout a time budget.
+//
+// Once goCtx is done, the layout algorithms don't run on the nodes left:
+// their containers place them as empty boxes, and their descendants keep
+// their previous Rects. The size returned with the error is the root's
+// size as far as layout got. A layout that finishes isn't affected by
+// cancellation afterwards.
+//
+// Unlike Layout, it also reports a tree nested deeper than ctx.MaxDepth:
+// the tree is laid out as Layout lays it out, and the error is a
+// *DepthError for its first node past the maximum.
+//
+// A nil ctx stands for the context LayoutSimple uses.
+//
+// Example:
+//
+//	goCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+//	defer cancel()
+//	if _, err := layout.LayoutWithContext(goCtx, root, constraints, ctx); err != nil {
+//	    return err // context.DeadlineExceeded
+//	}
+func LayoutWithContext(goCtx context.Context, root *Node, constraints Constraints, ctx *LayoutContext) (Size, error) {
+	if err := goCtx.Err(); err != nil {
+		return Size{}, err
+	}
+	if ctx == nil {
+		ctx = NewLayoutContext(constraints.MaxWidth, constraints.MaxHeight, 16.0)
+	}
+	state := &cancelState{done: goCtx.Done()}
+	copy := *ctx
+	copy.cancel = state
+	size, err := layoutRoot(root, constraints, &copy)
+	runLayoutCallbacks(root, &copy)
+	if state.stopped.Load() {
+		return size, goCtx.Err()
+	}
+	return size, err
+}
+
+// cancelState tells a layout when to stop, and records that it did.
+type cancelState struct {
+	done    <-chan struct{}
+	stopped atomic.Bool
+}
+
+// canceled reports whether ctx's layout has been canceled, and the nodes
+// left should be skipped.
+func (ctx *LayoutContext) canceled() bool {
+	if ctx == nil || ctx.cancel == nil {
+		return false
+	}
+	s := ctx.cancel
+	if s.stopped.Load() {
+		return true
+	}
+	select {
+	case <-s.done:
+		s.stopped.Store(true)
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLayoutWithContextCompletes(t *testing.T) {
+	root := VStack(Fixed(50, 20), Fixed(30, 20))
+
+	size, err := LayoutWithContext(context.Background(), root, Loose(400, 300), nil)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if size.Height != 40 {
+		t.Errorf("Expected height 40, got %.2f", size.Height)
+	}
+}
+
+func TestLayoutWithContextAlreadyCanceled(t *testing.T) {
+	child := Fixed(50, 20)
+	root := VStack(child)
+	goCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LayoutWithContext(goCtx, root, Loose(400, 300), NewLayoutContext(400, 300, 16))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if child.Rect != (Rect{}) {
+		t.Errorf("Expected no layout, got %+v", child.Rect)
+	}
+}
+
+func TestLayoutWithContextStopsMidLayout(t *testing.T) {
+	first, grandchild := Fixed(50, 20), Fixed(30, 20)
+	grandchild.Rect = Rect{X: 1, Y: 2, Width: 3, Height: 4}
+	second := &Node{Style: Style{Height: Px(-1)}, Children: []*Node{grandchild}}
+	root := &Node{Style: Style{Height: Px(-1)}, Children: []*Node{first, second}}
+	goCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.Cache = NewLayoutCache()
+	ctx.OnNodeEnd = func(tr NodeTrace) {
+		if tr.Node == first {
+			cancel()
+		}
+	}
+	size, err := LayoutWithContext(goCtx, root, Loose(400, 300), ctx)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if first.Rect.Height != 20 {
+		t.Errorf("Expected the first child to be laid out, got %+v", first.Rect)
+	}
+	if second.Rect.Height != 0 {
+		t.Errorf("Expected the second child to be skipped, got %+v", second.Rect)
+	}
+	if grandchild.Rect != (Rect{X: 1, Y: 2, Width: 3, Height: 4}) {
+		t.Errorf("Expected the skipped subtree to keep its rects, got %+v", grandchild.Rect)
+	}
+	if size.Height != 20 {
+		t.Errorf("Expected the partial height 20, got %.2f", size.Height)
+	}
+
+	// The partial result isn't cached
+	ctx.OnNodeEnd = nil
+	size = Layout(root, Loose(400, 300), ctx)
+	if size.Height != 40 || grandchild.Rect.Height != 20 {
+		t.Errorf("Expected a full layout afterwards, got height %.2f", size.Height)
+	}
+}
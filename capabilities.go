@@ -0,0 +1,104 @@
+package layout
+
+import "sort"
+
+// Feature identifies an optional layout capability that downstream tools
+// (CSS importers, renderers, test suites) may want to probe for before
+// relying on it.
+type Feature string
+
+const (
+	// FeatureFlexbox is CSS Flexible Box Layout (display: flex).
+	FeatureFlexbox Feature = "flexbox"
+
+	// FeatureGrid is CSS Grid Layout (display: grid).
+	FeatureGrid Feature = "grid"
+
+	// FeatureGridSubgrid is grid-template-rows/columns: subgrid.
+	FeatureGridSubgrid Feature = "grid-subgrid"
+
+	// FeatureTextLayout is inline text layout (display: inline-text).
+	FeatureTextLayout Feature = "text-layout"
+
+	// FeatureWritingModes is vertical writing mode support.
+	FeatureWritingModes Feature = "writing-modes"
+
+	// FeatureBidi is the Unicode Bidirectional Algorithm for mixed-direction text.
+	FeatureBidi Feature = "bidi"
+
+	// FeaturePositioning is relative/absolute/fixed positioning.
+	FeaturePositioning Feature = "positioning"
+
+	// FeatureContainerQueries is container-relative length units (cq*).
+	FeatureContainerQueries Feature = "container-queries"
+
+	// FeatureFragmentation is breaking content across pages or columns.
+	FeatureFragmentation Feature = "fragmentation"
+
+	// FeatureMultiColumn is multi-column block layout (column-count/column-width).
+	FeatureMultiColumn Feature = "multi-column"
+
+	// FeatureParallelLayout is laying out independent subtrees concurrently.
+	FeatureParallelLayout Feature = "parallel-layout"
+
+	// FeatureLayoutCache is subtree result caching via LayoutCache.
+	FeatureLayoutCache Feature = "layout-cache"
+)
+
+// features records which capabilities are compiled into this build. Files
+// guarded by build tags may flip entries from an init function.
+var features = map[Feature]bool{
+	FeatureFlexbox:          true,
+	FeatureGrid:             true,
+	FeatureGridSubgrid:      false,
+	FeatureTextLayout:       true,
+	FeatureWritingModes:     true,
+	FeatureBidi:             true,
+	FeaturePositioning:      true,
+	FeatureContainerQueries: true,
+	FeatureFragmentation:    true,
+	FeatureMultiColumn:      true,
+	FeatureParallelLayout:   false,
+	FeatureLayoutCache:      true,
+}
+
+// Capabilities reports every known feature and whether it is available in
+// this build. The returned map is a copy; modifying it has no effect.
+//
+// Example:
+//
+//	if !layout.Capabilities()[layout.FeatureBidi] {
+//	    // fall back to LTR-only rendering
+//	}
+func Capabilities() map[Feature]bool {
+	out := make(map[Feature]bool, len(features))
+	for f, ok := range features {
+		out[f] = ok
+	}
+	return out
+}
+
+// Supports reports whether feature f is available. Unknown features report
+// false, so callers probing for capabilities added in newer versions degrade
+// gracefully.
+//
+// Tests can use it to skip unsupported areas:
+//
+//	if !layout.Supports(layout.FeatureGridSubgrid) {
+//	    t.Skip("subgrid not supported")
+//	}
+func Supports(f Feature) bool {
+	return features[f]
+}
+
+// SupportedFeatures returns the available features in sorted order.
+func SupportedFeatures() []Feature {
+	var out []Feature
+	for f, ok := range features {
+		if ok {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
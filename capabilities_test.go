@@ -0,0 +1,43 @@
+package layout
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	caps := Capabilities()
+
+	for _, f := range []Feature{FeatureFlexbox, FeatureGrid, FeatureTextLayout, FeatureLayoutCache} {
+		if !caps[f] {
+			t.Errorf("Expected %q to be supported", f)
+		}
+	}
+	if _, ok := caps[FeatureGridSubgrid]; !ok {
+		t.Errorf("Expected %q to be reported even when unsupported", FeatureGridSubgrid)
+	}
+
+	// Returned map must be a copy
+	caps[FeatureGridSubgrid] = true
+	if Supports(FeatureGridSubgrid) != features[FeatureGridSubgrid] {
+		t.Error("Modifying Capabilities() result should not change Supports")
+	}
+}
+
+func TestSupportsUnknownFeature(t *testing.T) {
+	if Supports(Feature("teleportation")) {
+		t.Error("Unknown features should not be supported")
+	}
+}
+
+func TestSupportedFeatures(t *testing.T) {
+	list := SupportedFeatures()
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i] < list[j] }) {
+		t.Errorf("Expected sorted features, got %v", list)
+	}
+	for _, f := range list {
+		if !Supports(f) {
+			t.Errorf("SupportedFeatures returned unsupported feature %q", f)
+		}
+	}
+}
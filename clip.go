@@ -0,0 +1,101 @@
+package layout
+
+import "math"
+
+// GetClipRect returns the part of node's border box that can be seen, in
+// root's coordinates (those of root.Rect): the box, moved by the
+// transforms of node and its ancestors and by the scroll positions of the
+// scroll containers it's in, intersected with those scroll containers'
+// clips (see OverflowClip). It returns false if node isn't in root's tree
+// or is clipped away entirely. Nodes don't know their parents, so the
+// search for node starts at root.
+//
+// Transformed boxes and clips are replaced by their bounding boxes, so the
+// result may be larger than what's visible under a rotation or skew, but
+// never smaller. Renderers can use it to cull nodes or to set a clip path.
+//
+// Example:
+//
+//	if visible, ok := layout.GetClipRect(root, node, ctx); ok {
+//	    canvas.ClipRect(visible)
+//	    draw(node)
+//	}
+func GetClipRect(root, node *Node, ctx *LayoutContext) (Rect, bool) {
+	path := pathTo(root, node)
+	if path == nil {
+		return Rect{}, false
+	}
+
+	// toRoot maps the current node's own coordinates, relative to its
+	// Rect's origin, to root's
+	toRoot := IdentityTransform()
+	clip, clipped := Rect{}, false
+	for i, n := range path {
+		var parent *Node
+		if i > 0 {
+			parent = path[i-1]
+		}
+		toRoot = childToRoot(toRoot, parent, n)
+		if n == node {
+			break
+		}
+		if local, ok := n.OverflowClip(ctx); ok {
+			r := toRoot.ApplyToRect(local)
+			if clipped {
+				r = intersectRects(clip, r)
+			}
+			clip, clipped = r, true
+		}
+	}
+
+	visible := toRoot.ApplyToRect(Rect{Width: node.Rect.Width, Height: node.Rect.Height})
+	if clipped {
+		visible = intersectRects(visible, clip)
+	}
+	if visible.Width <= 0 || visible.Height <= 0 {
+		return Rect{}, false
+	}
+	return visible, true
+}
+
+// pathTo returns the nodes from root down to node, or nil if node isn't in
+// root's tree.
+func pathTo(root, node *Node) []*Node {
+	if root == node {
+		return []*Node{root}
+	}
+	for _, child := range root.Children {
+		if path := pathTo(child, node); path != nil {
+			return append([]*Node{root}, path...)
+		}
+	}
+	return nil
+}
+
+// childToRoot returns the transform from n's own coordinates, relative to
+// its Rect's origin, to root's, given that of its parent (nil for root).
+// It applies n's position and transform, and the parent's scroll position.
+func childToRoot(parentToRoot Transform, parent, n *Node) Transform {
+	if parent != nil {
+		parentToRoot = parentToRoot.Multiply(Translate(-parent.Scroll.X, -parent.Scroll.Y))
+	}
+	return parentToRoot.Multiply(nodeTransform(n)).Multiply(Translate(n.Rect.X, n.Rect.Y))
+}
+
+// nodeTransform returns n's transform, treating the zero Transform as the
+// identity.
+func nodeTransform(n *Node) Transform {
+	if n.Style.Transform == (Transform{}) {
+		return IdentityTransform()
+	}
+	return n.Style.Transform
+}
+
+// intersectRects returns the overlap of a and b, which is empty (zero
+// width or height) if they don't overlap.
+func intersectRects(a, b Rect) Rect {
+	x0, y0 := math.Max(a.X, b.X), math.Max(a.Y, b.Y)
+	x1 := math.Min(a.X+a.Width, b.X+b.Width)
+	y1 := math.Min(a.Y+a.Height, b.Y+b.Height)
+	return Rect{X: x0, Y: y0, Width: math.Max(0, x1-x0), Height: math.Max(0, y1-y0)}
+}
@@ -0,0 +1,46 @@
+package layout
+
+import "testing"
+
+func TestGetClipRect(t *testing.T) {
+	item := &Node{Style: Style{Width: Px(100), Height: Px(50)}}
+	below := &Node{Style: Style{Width: Px(100), Height: Px(50)}}
+	pane := &Node{
+		Style:    Style{Width: Px(200), Height: Px(100), Overflow: OverflowHidden},
+		Children: []*Node{{Style: Style{Height: Px(80)}}, item, {Style: Style{Height: Px(100)}}, below},
+	}
+	root := &Node{
+		Style:    Style{Width: Px(200), Height: Px(400)},
+		Children: []*Node{{Style: Style{Height: Px(50)}}, pane},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	Layout(root, Loose(800, 600), ctx)
+
+	// The pane shows 50 to 150; the item reaches from 130 to 180
+	if got, ok := GetClipRect(root, item, ctx); !ok || got != (Rect{X: 0, Y: 130, Width: 100, Height: 20}) {
+		t.Errorf("Expected the top 20px of the item, got %+v (%v)", got, ok)
+	}
+	if _, ok := GetClipRect(root, below, ctx); ok {
+		t.Error("Expected the node below the scrollport to be clipped away")
+	}
+
+	// Scrolled by 40, all of it shows
+	pane.ScrollTo(0, 40, ctx)
+	if got, ok := GetClipRect(root, item, ctx); !ok || got != (Rect{X: 0, Y: 90, Width: 100, Height: 50}) {
+		t.Errorf("Expected the whole scrolled item, got %+v (%v)", got, ok)
+	}
+
+	// Transforms move the node and the clip
+	pane.Style.Transform = Translate(5, 0)
+	item.Style.Transform = Scale(3, 1)
+	if got, ok := GetClipRect(root, item, ctx); !ok || got != (Rect{X: 5, Y: 90, Width: 200, Height: 50}) {
+		t.Errorf("Expected the transformed item clipped to the pane, got %+v (%v)", got, ok)
+	}
+
+	if got, ok := GetClipRect(root, pane, ctx); !ok || got != (Rect{X: 5, Y: 50, Width: 200, Height: 100}) {
+		t.Errorf("Expected the pane's own box, got %+v (%v)", got, ok)
+	}
+	if _, ok := GetClipRect(root, &Node{}, ctx); ok {
+		t.Error("Expected no clip rect for a node outside the tree")
+	}
+}
@@ -0,0 +1,77 @@
+// Command layoutsnap precomputes a layout and writes it as an embeddable
+// snapshot.
+//
+// It reads a layout tree in the serialize package's JSON format, lays it
+// out at the given viewport size and writes the result in the snapshot
+// format. With -go it also writes a Go file that embeds the snapshot:
+//
+//	layoutsnap -in dashboard.json -width 1280 -height 720 \
+//	    -out dashboard.snap -go dashboard_snap.go -pkg dashboard -var Dashboard
+//
+// Use it from a go:generate directive to keep the snapshot in sync with the
+// tree definition.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/layout/serialize"
+	"github.com/SCKelemen/layout/snapshot"
+)
+
+func main() {
+	in := flag.String("in", "", "layout tree in serialize JSON format (required)")
+	out := flag.String("out", "", "snapshot file to write (required)")
+	width := flag.Float64("width", 800, "viewport width")
+	height := flag.Float64("height", 600, "viewport height")
+	fontSize := flag.Float64("font-size", 16, "root font size")
+	goFile := flag.String("go", "", "also write a Go file embedding the snapshot")
+	pkg := flag.String("pkg", "main", "package name for -go")
+	varName := flag.String("var", "Layout", "variable name for -go")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, *width, *height, *fontSize, *goFile, *pkg, *varName); err != nil {
+		fmt.Fprintln(os.Stderr, "layoutsnap:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string, width, height, fontSize float64, goFile, pkg, varName string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	root, err := serialize.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", in, err)
+	}
+
+	ctx := layout.NewLayoutContext(width, height, fontSize)
+	layout.Layout(root, layout.Loose(width, height), ctx)
+
+	if err := os.WriteFile(out, snapshot.Encode(root), 0o644); err != nil {
+		return err
+	}
+	if goFile == "" {
+		return nil
+	}
+
+	// go:embed paths are relative to the generated file's directory
+	rel, err := filepath.Rel(filepath.Dir(goFile), out)
+	if err != nil {
+		return err
+	}
+	src, err := snapshot.GenerateGo(pkg, varName, filepath.ToSlash(rel))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(goFile, src, 0o644)
+}
@@ -20,6 +20,10 @@ import (
 //   - An ancestor-aware resolver (ResolveLengthInContext) that walks a
 //     NodeContext to find the nearest qualifying query container, then
 //     delegates pixel resolution to units.Length.Resolve.
+//   - Layout-time resolution: block, flex and grid containers that are
+//     query containers publish their content box on the LayoutContext
+//     while their descendants are laid out (enterQueryContainer), so cq*
+//     units in any style property resolve through plain ResolveLength.
 //
 // The cq* length unit *constants* themselves live in
 // github.com/SCKelemen/units and are re-exported through Phase 1's
@@ -277,13 +281,20 @@ func ResolveLengthInContext(l Length, ctx *LayoutContext, currentFontSize float6
 		return ResolveLength(l, ctx, currentFontSize)
 	}
 
-	uctx := buildUnitsContext(ctx, currentFontSize)
-	resolveUnit := l.Unit
-
 	// Inline-axis container (accepts size OR inline-size).
-	inlineCtr := findQueryContainer(nctx, false)
+	inlineCtr := queryContainerOf(findQueryContainer(nctx, false))
 	// Block-axis container (accepts only size).
-	sizeCtr := findQueryContainer(nctx, true)
+	sizeCtr := queryContainerOf(findQueryContainer(nctx, true))
+
+	return resolveContainerLength(l, ctx, currentFontSize, inlineCtr, sizeCtr)
+}
+
+// resolveContainerLength resolves a container-relative length against
+// the given inline-axis and block-axis query containers. A nil container
+// falls back to the viewport on its axis.
+func resolveContainerLength(l Length, ctx *LayoutContext, currentFontSize float64, inlineCtr, sizeCtr *queryContainer) float64 {
+	uctx := buildUnitsContext(ctx, currentFontSize)
+	resolveUnit := l.Unit
 
 	switch l.Unit {
 	case units.CQW:
@@ -367,15 +378,56 @@ func findQueryContainer(nctx *NodeContext, sizeOnly bool) *Node {
 	return nil
 }
 
+// queryContainer is the content box of a query container, as seen by
+// the cq* units of its descendants.
+type queryContainer struct {
+	width, height float64
+	writingMode   WritingMode
+}
+
+// queryContainerOf returns the query container for a laid-out node, using
+// its Rect. It returns nil for a nil node.
+func queryContainerOf(n *Node) *queryContainer {
+	if n == nil {
+		return nil
+	}
+	return &queryContainer{width: n.Rect.Width, height: n.Rect.Height, writingMode: n.Style.WritingMode}
+}
+
+// enterQueryContainer makes node the query container of its descendants'
+// cq* units while they are laid out, if its ContainerType makes it one,
+// and returns a function that restores the previous containers. width and
+// height are the node's content box; a negative or unbounded dimension is
+// indefinite. An indefinite width establishes no container, and a size
+// container with an indefinite height only answers inline-axis queries
+// (cqw, cqi), leaving block-axis queries to the next container up.
+//
+// Example:
+//
+//	restoreContainer := enterQueryContainer(node, ctx, contentWidth, contentHeight)
+//	defer restoreContainer()
+func enterQueryContainer(node *Node, ctx *LayoutContext, width, height float64) (restore func()) {
+	if ctx == nil || node.Style.ContainerType == ContainerTypeNormal || width < 0 || width >= Unbounded {
+		return func() {}
+	}
+	previousInline, previousSize := ctx.inlineContainer, ctx.sizeContainer
+	c := &queryContainer{width: width, height: height, writingMode: node.Style.WritingMode}
+	ctx.inlineContainer = c
+	if node.Style.ContainerType == ContainerTypeSize && height >= 0 && height < Unbounded {
+		ctx.sizeContainer = c
+	}
+	return func() { ctx.inlineContainer, ctx.sizeContainer = previousInline, previousSize }
+}
+
 // containerWritingMode returns the container's WritingMode, defaulting
 // to WritingModeHorizontalTB (the CSS default and zero value) when no
 // container is supplied. Used to map logical cqi / cqb units to a
 // physical axis.
-func containerWritingMode(c *Node) WritingMode {
+func containerWritingMode(c *queryContainer) WritingMode {
 	if c == nil {
 		return WritingModeHorizontalTB
 	}
-	return c.Style.WritingMode
+	return c.writingMode
 }
 
 // physicalAxis identifies one of the two physical layout axes.
@@ -388,11 +440,11 @@ const (
 
 // inlineAxisFallback returns the physical pixel value to plug into
 // units.Context for the inline-axis container dimension. Uses the
-// container's Rect if available; otherwise falls back to the viewport
+// container's size if available; otherwise falls back to the viewport
 // dimension on the requested physical axis.
-func inlineAxisFallback(c *Node, ctx *LayoutContext, axis physicalAxis) float64 {
+func inlineAxisFallback(c *queryContainer, ctx *LayoutContext, axis physicalAxis) float64 {
 	if c != nil {
-		return rectAxis(c.Rect, axis)
+		return c.axis(axis)
 	}
 	return viewportAxis(ctx, axis)
 }
@@ -401,18 +453,18 @@ func inlineAxisFallback(c *Node, ctx *LayoutContext, axis physicalAxis) float64
 // Kept separate for readability at the call sites; the two helpers have
 // the same body but they document very different intents (one is
 // triggered by inline-axis cq* units, the other by block-axis ones).
-func blockAxisFallback(c *Node, ctx *LayoutContext, axis physicalAxis) float64 {
+func blockAxisFallback(c *queryContainer, ctx *LayoutContext, axis physicalAxis) float64 {
 	if c != nil {
-		return rectAxis(c.Rect, axis)
+		return c.axis(axis)
 	}
 	return viewportAxis(ctx, axis)
 }
 
-func rectAxis(r Rect, axis physicalAxis) float64 {
+func (c *queryContainer) axis(axis physicalAxis) float64 {
 	if axis == axisWidth {
-		return r.Width
+		return c.width
 	}
-	return r.Height
+	return c.height
 }
 
 func viewportAxis(ctx *LayoutContext, axis physicalAxis) float64 {
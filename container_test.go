@@ -395,3 +395,47 @@ func TestResolveLengthInContext_VerticalWritingMode(t *testing.T) {
 		t.Errorf("Cqh(50) in vertical-rl container = %v, want 300 (physical height)", got)
 	}
 }
+
+func TestContainerUnitsDuringLayout(t *testing.T) {
+	// A card is an inline-size container of 300px content width inside a
+	// 1000px page; its children size themselves relative to the card.
+	half := &Node{Style: Style{Display: DisplayBlock, Width: Cqw(50), Height: Cqh(10)}}
+	padded := &Node{Style: Style{Display: DisplayBlock, Width: Px(-1), Height: Px(10), Padding: Spacing{Left: Cqi(10)}}}
+	card := &Node{
+		Style:    Style{Display: DisplayBlock, Width: Px(300), Height: Px(-1), ContainerType: ContainerTypeInlineSize},
+		Children: []*Node{half, padded},
+	}
+	root := &Node{Style: Style{Display: DisplayBlock, Width: Px(1000), Height: Px(-1)}, Children: []*Node{card}}
+	Layout(root, Loose(1000, 800), NewLayoutContext(1000, 800, 16))
+
+	if half.Rect.Width != 150 {
+		t.Errorf("Expected 50cqw of the 300px card, got %.2f", half.Rect.Width)
+	}
+	if half.Rect.Height != 80 {
+		t.Errorf("Expected cqh to fall back to the viewport (10%% of 800), got %.2f", half.Rect.Height)
+	}
+	if got := ResolveLength(padded.Style.Padding.Left, NewLayoutContext(1000, 800, 16), 16); got != 100 {
+		t.Errorf("Expected cq* outside layout to resolve against the viewport, got %.2f", got)
+	}
+}
+
+func TestContainerUnitsNestedDuringLayout(t *testing.T) {
+	// The nearest container wins; a size container with an explicit height
+	// answers block-axis queries
+	inner := Fixed(0, 0)
+	inner.Style.Width = Cqw(25)
+	inner.Style.Height = Cqb(50)
+	box := &Node{
+		Style:    Style{Display: DisplayFlex, Width: Px(200), Height: Px(100), ContainerType: ContainerTypeSize},
+		Children: []*Node{inner},
+	}
+	outer := &Node{
+		Style:    Style{Display: DisplayBlock, Width: Px(600), Height: Px(-1), ContainerType: ContainerTypeSize},
+		Children: []*Node{box},
+	}
+	Layout(outer, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if inner.Rect.Width != 50 || inner.Rect.Height != 50 {
+		t.Errorf("Expected 50x50 from the 200x100 container, got %.2fx%.2f", inner.Rect.Width, inner.Rect.Height)
+	}
+}
@@ -0,0 +1,406 @@
+// Package css parses CSS stylesheets and applies them to layout trees.
+//
+// Nodes are matched by layout selectors (see layout.ParseSelector), and
+// each matching declaration is written into the node's Style:
+//
+//	sheet, err := css.Parse(`
+//	    .row  { display: flex; gap: 8px }
+//	    .card { flex: 1; padding: 12px 16px; border: 1px solid }
+//	`)
+//	if err != nil {
+//	    log.Print(err) // the valid rules are still applied
+//	}
+//	sheet.Apply(root)
+//	layout.Layout(root, layout.Loose(800, layout.Unbounded), ctx)
+//
+// Parsing follows CSS error handling: a rule with an invalid selector, or
+// a declaration with an unknown property or invalid value, is dropped and
+// reported, and the rest of the stylesheet is kept. Rules in @media blocks
+// apply when the query matches the layout context passed to ApplyContext;
+// other at-rules are skipped.
+//
+// See SetProperty for the supported properties.
+package css
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Stylesheet is a parsed CSS stylesheet.
+type Stylesheet struct {
+	Rules []Rule
+}
+
+// Rule is a style rule: a selector list and its declarations.
+type Rule struct {
+	// Selectors is the selector list; the rule applies to nodes matched
+	// by any of them.
+	Selectors    []layout.Selector
+	Declarations []Declaration
+
+	// Media are the queries of the @media rules the rule is nested in;
+	// the rule applies only if all of them match.
+	Media []layout.MediaQuery
+}
+
+// Declaration is a single property declaration.
+type Declaration struct {
+	Property  string // Lowercase property name, e.g. "margin-top"
+	Value     string // Value text without !important, e.g. "8px"
+	Important bool
+}
+
+// Parse parses a CSS stylesheet. It returns every rule it could parse,
+// along with an error describing the rules and declarations it dropped.
+//
+// Example:
+//
+//	sheet, err := css.Parse("main > .card { width: calc(100% - 32px) }")
+func Parse(src string) (*Stylesheet, error) {
+	src, err := stripComments(src)
+	if err != nil {
+		return &Stylesheet{}, err
+	}
+
+	sheet := &Stylesheet{}
+	errs := sheet.parseRules(src, nil)
+	return sheet, errors.Join(errs...)
+}
+
+// parseRules parses the rules in src, which are nested in @media rules
+// with the given queries, and appends them to the stylesheet.
+func (s *Stylesheet) parseRules(src string, media []layout.MediaQuery) []error {
+	var errs []error
+	for i := 0; ; {
+		for i < len(src) && isSpace(src[i]) {
+			i++
+		}
+		if i >= len(src) {
+			break
+		}
+
+		// Other at-rules are skipped, with their block if they have one
+		if src[i] == '@' {
+			end := scanUntil(src, i, ";{")
+			if end >= len(src) || src[end] != '{' {
+				i = end + 1
+				continue
+			}
+			close := matchBrace(src, end)
+			prelude, body := src[i+1:end], src[end+1:min(close, len(src))]
+			i = close + 1
+			name := identPrefix(prelude)
+			if !strings.EqualFold(name, "media") {
+				continue
+			}
+			query := prelude[len(name):]
+			mq, err := layout.ParseMediaQuery(query)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, s.parseRules(body, append(media[:len(media):len(media)], mq))...)
+			continue
+		}
+
+		open := scanUntil(src, i, "{")
+		if open >= len(src) {
+			errs = append(errs, fmt.Errorf("css: expected { after %q", strings.TrimSpace(src[i:])))
+			break
+		}
+		close := matchBrace(src, open)
+		prelude, body := src[i:open], src[open+1:min(close, len(src))]
+		i = close + 1
+
+		rule := Rule{Media: media}
+		var ruleErr error
+		for _, part := range splitTopLevel(prelude, ',') {
+			sel, err := layout.ParseSelector(part)
+			if err != nil {
+				ruleErr = err
+				break
+			}
+			rule.Selectors = append(rule.Selectors, sel)
+		}
+		if ruleErr != nil {
+			errs = append(errs, ruleErr)
+			continue
+		}
+		decls, err := ParseDeclarations(body)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		rule.Declarations = decls
+		s.Rules = append(s.Rules, rule)
+	}
+	return errs
+}
+
+// ParseDeclarations parses a declaration list such as the contents of a
+// rule or an HTML style attribute. It returns the valid declarations,
+// along with an error describing the ones it dropped.
+//
+// Example:
+//
+//	decls, err := css.ParseDeclarations("display: flex; gap: 4px")
+func ParseDeclarations(src string) ([]Declaration, error) {
+	src, err := stripComments(src)
+	if err != nil {
+		return nil, err
+	}
+	var decls []Declaration
+	var errs []error
+	for _, part := range splitTopLevel(src, ';') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		colon := strings.IndexByte(part, ':')
+		if colon < 0 {
+			errs = append(errs, fmt.Errorf("css: expected property: value, got %q", part))
+			continue
+		}
+		d := Declaration{
+			Property: strings.ToLower(strings.TrimSpace(part[:colon])),
+			Value:    strings.TrimSpace(part[colon+1:]),
+		}
+		if i := strings.LastIndexByte(d.Value, '!'); i >= 0 && strings.EqualFold(strings.TrimSpace(d.Value[i+1:]), "important") {
+			d.Value, d.Important = strings.TrimSpace(d.Value[:i]), true
+		}
+		// Validate against a scratch style so errors are reported once
+		var scratch layout.Style
+		if err := SetProperty(&scratch, d.Property, d.Value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		decls = append(decls, d)
+	}
+	return decls, errors.Join(errs...)
+}
+
+// Apply applies the stylesheet to root and its descendants. Declarations
+// are applied in cascade order, so later ones win:
+//
+//  1. Normal stylesheet declarations, by specificity, then source order
+//  2. Normal declarations of the node's inline "style" attribute
+//  3. !important stylesheet declarations, likewise ordered
+//  4. !important inline declarations
+//
+// Apply writes on top of each node's current Style; properties the
+// stylesheet doesn't set are left alone. A node whose TextStyle is created
+// by Apply starts from its parent's computed text style, so the text
+// properties it doesn't set are inherited as in CSS.
+//
+// Rules nested in @media are skipped; see ApplyContext.
+//
+// See: https://www.w3.org/TR/css-cascade-4/#cascade-sort
+func (s *Stylesheet) Apply(root *layout.Node) {
+	s.ApplyContext(root, nil)
+}
+
+// ApplyContext is like Apply, but also applies the rules nested in @media
+// whose queries match ctx, so a single tree definition can be laid out
+// responsively. Call it again with the new context when the viewport
+// changes; Apply only writes the properties it sets, so start from a
+// fresh tree (or reset the styles) when rules may stop matching.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(width, height, 16)
+//	sheet.ApplyContext(root, ctx)
+//	layout.Layout(root, layout.Tight(width, height), ctx)
+func (s *Stylesheet) ApplyContext(root *layout.Node, ctx *layout.LayoutContext) {
+	active := &Stylesheet{}
+	for _, rule := range s.Rules {
+		if mediaMatch(rule.Media, ctx) {
+			active.Rules = append(active.Rules, rule)
+		}
+	}
+	active.apply(root, nil, nil)
+}
+
+// mediaMatch reports whether ctx matches all the queries.
+func mediaMatch(media []layout.MediaQuery, ctx *layout.LayoutContext) bool {
+	for _, mq := range media {
+		if !mq.Match(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchedDeclaration is a declaration that applies to a node, with the
+// keys it is sorted by in the cascade.
+type matchedDeclaration struct {
+	decl        Declaration
+	specificity layout.Specificity
+	inline      bool // From the node's style attribute
+	order       int
+}
+
+func (s *Stylesheet) apply(node *layout.Node, ancestors []*layout.Node, parent *layout.TextStyle) {
+	var matched []matchedDeclaration
+	order := 0
+	for _, rule := range s.Rules {
+		var spec layout.Specificity
+		found := false
+		for _, sel := range rule.Selectors {
+			if sel.Match(node, ancestors) {
+				if sp := sel.Specificity(); !found || spec.Less(sp) {
+					spec = sp
+				}
+				found = true
+			}
+		}
+		for _, d := range rule.Declarations {
+			if found {
+				matched = append(matched, matchedDeclaration{decl: d, specificity: spec, order: order})
+			}
+			order++
+		}
+	}
+	if inline, ok := node.Attributes["style"]; ok {
+		// Invalid inline declarations are dropped like invalid rules
+		decls, _ := ParseDeclarations(inline)
+		for _, d := range decls {
+			matched = append(matched, matchedDeclaration{decl: d, inline: true, order: order})
+			order++
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.decl.Important != b.decl.Important {
+			return b.decl.Important
+		}
+		if a.inline != b.inline {
+			return b.inline
+		}
+		if a.specificity != b.specificity {
+			return a.specificity.Less(b.specificity)
+		}
+		return a.order < b.order
+	})
+
+	parentFontSize := defaultFontSize
+	if parent != nil && parent.FontSize > 0 {
+		parentFontSize = parent.FontSize
+	}
+	// Seed a missing TextStyle with the inherited one, and drop it again
+	// if no declaration changed it
+	var seed layout.TextStyle
+	seeded := node.Style.TextStyle == nil && len(matched) > 0
+	if seeded {
+		if inherited := layout.InheritTextStyle(parent, nil); inherited != nil {
+			seed = *inherited
+		} else {
+			seed = layout.TextStyle{WordSpacing: -1, LetterSpacing: -1, TabSize: -1}
+		}
+		ts := seed
+		node.Style.TextStyle = &ts
+	}
+	for _, m := range matched {
+		// Validated when parsed
+		_ = setProperty(&node.Style, m.decl.Property, m.decl.Value, parentFontSize)
+	}
+	if seeded && *node.Style.TextStyle == seed {
+		node.Style.TextStyle = nil
+	}
+
+	computed := layout.InheritTextStyle(parent, node.Style.TextStyle)
+	ancestors = append(ancestors, node)
+	for _, child := range node.Children {
+		s.apply(child, ancestors, computed)
+	}
+}
+
+// stripComments removes /* */ comments outside strings.
+func stripComments(src string) (string, error) {
+	if !strings.Contains(src, "/*") {
+		return src, nil
+	}
+	var sb strings.Builder
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return "", fmt.Errorf("css: unterminated comment")
+			}
+			i += end + 3
+			sb.WriteByte(' ')
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String(), nil
+}
+
+// scanUntil returns the index of the first byte of stop in src at or after
+// i that is outside strings and parentheses, or len(src).
+func scanUntil(src string, i int, stop string) int {
+	var quote byte
+	depth := 0
+	for ; i < len(src); i++ {
+		ch := src[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth <= 0 && strings.IndexByte(stop, ch) >= 0:
+			return i
+		}
+	}
+	return i
+}
+
+// matchBrace returns the index of the } matching the { at open, or
+// len(src) if the block is unterminated.
+func matchBrace(src string, open int) int {
+	depth := 0
+	for i := open; i < len(src); {
+		i = scanUntil(src, i, "{}")
+		if i >= len(src) {
+			break
+		}
+		if src[i] == '{' {
+			depth++
+		} else if depth--; depth == 0 {
+			return i
+		}
+		i++
+	}
+	return len(src)
+}
+
+// splitTopLevel splits src at sep outside strings and parentheses.
+func splitTopLevel(src string, sep byte) []string {
+	var parts []string
+	for i := 0; ; {
+		j := scanUntil(src, i, string(sep))
+		parts = append(parts, src[i:j])
+		if j >= len(src) {
+			return parts
+		}
+		i = j + 1
+	}
+}
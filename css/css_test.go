@@ -0,0 +1,330 @@
+package css
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestParseStylesheet(t *testing.T) {
+	sheet, err := Parse(`
+		/* layout */
+		@import url("base.css");
+		@media (min-width: 600px) { .card { width: 10px } }
+		.row, #toolbar { display: flex; gap: 8px !important }
+		.card { width: calc(100% - 2 * 16px); padding: 4px 8px; }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(sheet.Rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(sheet.Rules))
+	}
+	if media := sheet.Rules[0].Media; len(media) != 1 || media[0].String() != "(min-width: 600px)" {
+		t.Errorf("Expected the first rule in @media (min-width: 600px), got %v", media)
+	}
+	row := sheet.Rules[1]
+	if len(row.Selectors) != 2 || row.Selectors[1].String() != "#toolbar" {
+		t.Errorf("Expected selectors .row and #toolbar, got %v", row.Selectors)
+	}
+	want := []Declaration{{Property: "display", Value: "flex"}, {Property: "gap", Value: "8px", Important: true}}
+	if len(row.Declarations) != len(want) {
+		t.Fatalf("Expected %d declarations, got %d", len(want), len(row.Declarations))
+	}
+	for i, d := range want {
+		if row.Declarations[i] != d {
+			t.Errorf("Expected declaration %+v, got %+v", d, row.Declarations[i])
+		}
+	}
+}
+
+func TestParseRecoversFromErrors(t *testing.T) {
+	sheet, err := Parse(`
+		.a { width: 10px; colour: red; height: tall; margin: 1px }
+		.b:hover { width: 20px }
+		.c { width: 30px }
+	`)
+	if err == nil {
+		t.Fatal("Expected an error for the invalid declarations and selector")
+	}
+	for _, want := range []string{`unsupported property "colour"`, `invalid height "tall"`, `.b:hover`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got %v", want, err)
+		}
+	}
+	if len(sheet.Rules) != 2 {
+		t.Fatalf("Expected the 2 valid rules to be kept, got %d", len(sheet.Rules))
+	}
+	if n := len(sheet.Rules[0].Declarations); n != 2 {
+		t.Errorf("Expected the 2 valid declarations of .a to be kept, got %d", n)
+	}
+}
+
+func TestApplyCascade(t *testing.T) {
+	sheet, err := Parse(`
+		#main .item { width: 30px }
+		.item { width: 10px; height: 5px !important }
+		div { width: 20px }
+		.item.item { height: 7px }
+		.item { width: 40px }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	inside := &layout.Node{Tag: "div", Classes: []string{"item"}}
+	outside := &layout.Node{Tag: "div", Classes: []string{"item"}}
+	root := &layout.Node{Children: []*layout.Node{{ID: "main", Children: []*layout.Node{inside}}, outside}}
+	sheet.Apply(root)
+
+	// The id selector beats the later class rule
+	if inside.Style.Width != layout.Px(30) {
+		t.Errorf("Expected width 30px, got %v", inside.Style.Width)
+	}
+	// The later of two equally specific rules wins
+	if outside.Style.Width != layout.Px(40) {
+		t.Errorf("Expected width 40px, got %v", outside.Style.Width)
+	}
+	// !important beats a more specific rule
+	if outside.Style.Height != layout.Px(5) {
+		t.Errorf("Expected height 5px, got %v", outside.Style.Height)
+	}
+}
+
+func TestSetProperty(t *testing.T) {
+	tests := []struct {
+		property, value string
+		check           func(s layout.Style) bool
+	}{
+		{"display", "inline-flex", func(s layout.Style) bool { return s.Display == layout.DisplayFlex }},
+		{"width", "auto", func(s layout.Style) bool { return s.Width == layout.Px(-1) }},
+		{"width", "50%", func(s layout.Style) bool { return s.Width == layout.Percent(50) }},
+		{"width", "2.5em", func(s layout.Style) bool { return s.Width == layout.Em(2.5) }},
+		{"height", "3cells", func(s layout.Style) bool { return s.Height == layout.Length{Value: 3, Unit: "cells"} }},
+		{"width", "min-content", func(s layout.Style) bool {
+			return s.WidthSizing == layout.IntrinsicSizeMinContent && s.Width.Value == layout.SizeMinContent
+		}},
+		{"height", "fit-content(200px)", func(s layout.Style) bool {
+			return s.HeightSizing == layout.IntrinsicSizeFitContent && s.FitContentHeight == layout.Px(200)
+		}},
+		{"max-width", "none", func(s layout.Style) bool { return s.MaxWidth == layout.Length{} }},
+		{"aspect-ratio", "16 / 9", func(s layout.Style) bool { return math.Abs(s.AspectRatio-16.0/9) < 1e-9 }},
+		{"margin", "1px auto", func(s layout.Style) bool {
+			return s.Margin.Top == layout.Px(1) && s.Margin.Bottom == layout.Px(1) && s.Margin.Left == layout.Auto() && s.Margin.Right == layout.Auto()
+		}},
+		{"padding", "1px 2px 3px", func(s layout.Style) bool {
+			return s.Padding.Top == layout.Px(1) && s.Padding.Right == layout.Px(2) && s.Padding.Bottom == layout.Px(3) && s.Padding.Left == layout.Px(2)
+		}},
+		{"border", "2px solid red", func(s layout.Style) bool { return s.Border.Left == layout.Px(2) }},
+		{"border-width", "thin thick", func(s layout.Style) bool { return s.Border.Top == layout.Px(1) && s.Border.Right == layout.Px(5) }},
+		{"flex", "2", func(s layout.Style) bool {
+			return s.FlexGrow == 2 && s.FlexShrink == 1 && s.FlexBasis == layout.Basis(layout.Px(0))
+		}},
+		{"flex", "1 0 auto", func(s layout.Style) bool {
+			return s.FlexGrow == 1 && s.FlexShrink == 0 && s.FlexBasis == layout.BasisAuto()
+		}},
+		{"flex", "none", func(s layout.Style) bool { return s.FlexGrow == 0 && s.FlexShrink == 0 }},
+		{"flex-flow", "wrap column", func(s layout.Style) bool {
+			return s.FlexDirection == layout.FlexDirectionColumn && s.FlexWrap == layout.FlexWrapWrap
+		}},
+		{"align-content", "unsafe center", func(s layout.Style) bool {
+			return s.AlignContent == layout.AlignContentCenter && s.AlignContentOverflow == layout.OverflowAlignmentUnsafe
+		}},
+		{"gap", "4px 8px", func(s layout.Style) bool {
+			return s.FlexRowGap == layout.Px(4) && s.GridColumnGap == layout.Px(8) && s.ColumnGap == layout.Px(8)
+		}},
+		{"grid-template-columns", "100px repeat(2, minmax(50px, 1fr)) auto", func(s layout.Style) bool {
+			c := s.GridTemplateColumns
+			return len(c) == 4 && c[0] == layout.FixedTrack(layout.Px(100)) && c[1].MinSize == layout.Px(50) && c[2].Fraction == 1 && c[3] == layout.AutoTrack()
+		}},
+		{"grid-column", "2 / span 3", func(s layout.Style) bool { return s.GridColumnStart == 1 && s.GridColumnEnd == layout.Span(3) }},
+		{"grid-row-end", "-1", func(s layout.Style) bool { return s.GridRowEnd == -1 }},
+		{"grid-area", "header", func(s layout.Style) bool { return s.GridArea == "header" }},
+		{"grid-template-areas", `"head head" "nav main"`, func(s layout.Style) bool {
+			a := s.GridTemplateAreas.Areas
+			return len(a) == 3 && a[0] == layout.GridArea{Name: "head", RowStart: 0, RowEnd: 1, ColumnStart: 0, ColumnEnd: 2}
+		}},
+		{"grid-auto-flow", "column dense", func(s layout.Style) bool { return s.GridAutoFlow == layout.GridAutoFlowColumnDense }},
+		{"transform", "translate(10px, 20px) scale(2)", func(s layout.Style) bool {
+			return s.Transform == layout.Translate(10, 20).Multiply(layout.Scale(2, 2))
+		}},
+		{"writing-mode", "vertical-rl", func(s layout.Style) bool { return s.WritingMode == layout.WritingModeVerticalRL }},
+		{"container", "sidebar / inline-size", func(s layout.Style) bool { return s.ContainerType == layout.ContainerTypeInlineSize }},
+		{"font-size", "1.5em", func(s layout.Style) bool { return s.TextStyle.FontSize == 24 }},
+		{"line-height", "1.5", func(s layout.Style) bool { return s.TextStyle.LineHeight == 1.5 }},
+		{"letter-spacing", "normal", func(s layout.Style) bool { return s.TextStyle.LetterSpacing == -1 }},
+		{"white-space", "pre-wrap", func(s layout.Style) bool { return s.TextStyle.WhiteSpace == layout.WhiteSpacePreWrap }},
+		{"text-decoration", "underline line-through", func(s layout.Style) bool {
+			return s.TextStyle.TextDecoration == layout.TextDecorationUnderline|layout.TextDecorationLineThrough
+		}},
+		{"overflow", "auto", func(s layout.Style) bool { return s.Overflow == layout.OverflowAuto }},
+		{"margin-inline", "4px auto", func(s layout.Style) bool {
+			return s.MarginInlineStart == layout.Px(4) && s.MarginInlineEnd == layout.Auto()
+		}},
+		{"padding-block-end", "2em", func(s layout.Style) bool { return s.PaddingBlockEnd == layout.Em(2) }},
+		{"inset-inline-start", "10%", func(s layout.Style) bool { return s.InsetInlineStart == layout.Percent(10) }},
+		{"scrollbar-gutter", "both-edges  stable", func(s layout.Style) bool { return s.ScrollbarGutter == layout.ScrollbarGutterStableBothEdges }},
+		{"hanging-punctuation", "first allow-end last", func(s layout.Style) bool {
+			return s.TextStyle.HangingPunctuation == layout.HangingPunctuationFirst|layout.HangingPunctuationAllowEnd|layout.HangingPunctuationLast
+		}},
+	}
+	for _, tt := range tests {
+		var s layout.Style
+		if err := SetProperty(&s, tt.property, tt.value); err != nil {
+			t.Errorf("SetProperty(%s: %s): %v", tt.property, tt.value, err)
+			continue
+		}
+		if !tt.check(s) {
+			t.Errorf("Expected %s: %s to be applied, got %+v", tt.property, tt.value, s)
+		}
+	}
+
+	invalid := [][2]string{
+		{"width", "10"},
+		{"width", "-5px"},
+		{"flex-grow", "-1"},
+		{"display", "table"},
+		{"grid-column-start", "0"},
+		{"grid-template-areas", `"a b" "b a"`},
+		{"transform", "translate(10%)"},
+		{"margin", "1px 2px 3px 4px 5px"},
+		{"hanging-punctuation", "force-end allow-end"},
+	}
+	for _, tt := range invalid {
+		var s layout.Style
+		if err := SetProperty(&s, tt[0], tt[1]); err == nil {
+			t.Errorf("Expected %s: %s to be rejected", tt[0], tt[1])
+		}
+	}
+}
+
+func TestApplyFontSizeInherits(t *testing.T) {
+	sheet, err := Parse(`
+		.outer { font-size: 20px }
+		.inner { font-size: 1.5em; letter-spacing: 0.1em }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	inner := &layout.Node{Classes: []string{"inner"}}
+	root := &layout.Node{Classes: []string{"outer"}, Children: []*layout.Node{{Children: []*layout.Node{inner}}}}
+	sheet.Apply(root)
+
+	if inner.Style.TextStyle.FontSize != 30 {
+		t.Errorf("Expected font size 30, got %v", inner.Style.TextStyle.FontSize)
+	}
+	if math.Abs(inner.Style.TextStyle.LetterSpacing-2) > 1e-9 {
+		t.Errorf("Expected letter spacing of the parent's 20px font, 2, got %v", inner.Style.TextStyle.LetterSpacing)
+	}
+}
+
+func TestApplyLayout(t *testing.T) {
+	sheet, err := Parse(`
+		.row { display: flex; width: 300px; height: 50px; padding: 0 10px }
+		.item { flex: 1 }
+		.item.wide { flex: 2 }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	a := &layout.Node{Classes: []string{"item"}}
+	b := &layout.Node{Classes: []string{"item", "wide"}}
+	root := &layout.Node{Classes: []string{"row"}, Children: []*layout.Node{a, b}}
+	sheet.Apply(root)
+	layout.Layout(root, layout.Loose(1000, 1000), layout.NewLayoutContext(1000, 1000, 16))
+
+	// 300px split 1:2 after the 10px left padding
+	if a.Rect.X != 10 || a.Rect.Width != 100 {
+		t.Errorf("Expected first item at x 10 with width 100, got x %.2f width %.2f", a.Rect.X, a.Rect.Width)
+	}
+	if b.Rect.X != 110 || b.Rect.Width != 200 {
+		t.Errorf("Expected second item at x 110 with width 200, got x %.2f width %.2f", b.Rect.X, b.Rect.Width)
+	}
+}
+
+func TestApplyInlineStyle(t *testing.T) {
+	sheet, err := Parse(`
+		#box { width: 10px; height: 10px !important }
+		.box { min-width: 5px !important }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	box := &layout.Node{ID: "box", Classes: []string{"box"}, Attributes: map[string]string{
+		"style": "width: 20px; height: 20px; min-width: 1px !important; colour: red",
+	}}
+	sheet.Apply(box)
+
+	// Inline beats any selector, !important beats inline, and important inline beats everything
+	if box.Style.Width != layout.Px(20) {
+		t.Errorf("Expected inline width 20px, got %v", box.Style.Width)
+	}
+	if box.Style.Height != layout.Px(10) {
+		t.Errorf("Expected important height 10px, got %v", box.Style.Height)
+	}
+	if box.Style.MinWidth != layout.Px(1) {
+		t.Errorf("Expected important inline min-width 1px, got %v", box.Style.MinWidth)
+	}
+}
+
+func TestApplyTextStyleInherits(t *testing.T) {
+	sheet, err := Parse(`
+		.card { text-align: center; font-size: 20px; visibility: hidden }
+		.title { font-weight: bold }
+		.plain { width: 10px }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	title := &layout.Node{Classes: []string{"title"}}
+	plain := &layout.Node{Classes: []string{"plain"}}
+	root := &layout.Node{Classes: []string{"card"}, Children: []*layout.Node{title, plain}}
+	sheet.Apply(root)
+
+	if root.Style.Visibility != layout.VisibilityHidden {
+		t.Errorf("Expected the card to be hidden, got %v", root.Style.Visibility)
+	}
+	// A TextStyle created for bold keeps the inherited properties
+	ts := title.Style.TextStyle
+	if ts == nil || ts.TextAlign != layout.TextAlignCenter || ts.FontSize != 20 || ts.FontWeight != layout.FontWeightBold {
+		t.Errorf("Expected bold centered 20px text, got %+v", ts)
+	}
+	// No text property set, so nothing to create
+	if plain.Style.TextStyle != nil {
+		t.Errorf("Expected no TextStyle, got %+v", plain.Style.TextStyle)
+	}
+}
+
+func TestApplyContextMedia(t *testing.T) {
+	sheet, err := Parse(`
+		.card { width: 100px; height: 10px }
+		@media (min-width: 600px) {
+			.card { width: 300px }
+			@media (prefers-color-scheme: dark) { .card { height: 20px } }
+		}
+		@media (max-width: 599px) { .card { width: 50px } }
+		@media (hover: hover) { .card { width: 1px } }
+	`)
+	if err == nil || !strings.Contains(err.Error(), "hover") {
+		t.Errorf("Expected an error for the unsupported feature, got %v", err)
+	}
+
+	tests := []struct {
+		ctx           *layout.LayoutContext
+		width, height float64
+	}{
+		{nil, 100, 10},
+		{layout.NewLayoutContext(400, 800, 16), 50, 10},
+		{layout.NewLayoutContext(800, 600, 16), 300, 10},
+		{layout.NewLayoutContext(800, 600, 16).WithPrefersDark(true), 300, 20},
+		{layout.NewLayoutContext(400, 800, 16).WithPrefersDark(true), 50, 10},
+	}
+	for _, tt := range tests {
+		card := &layout.Node{Classes: []string{"card"}}
+		sheet.ApplyContext(card, tt.ctx)
+		if card.Style.Width != layout.Px(tt.width) || card.Style.Height != layout.Px(tt.height) {
+			t.Errorf("Expected %vx%v, got %vx%v", tt.width, tt.height, card.Style.Width, card.Style.Height)
+		}
+	}
+}
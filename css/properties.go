@@ -0,0 +1,1239 @@
+package css
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// SetProperty parses a single CSS declaration and writes it into style.
+// Font-relative text properties such as font-size: 1.5em are taken of the
+// 16px initial font size; Stylesheet.Apply uses the parent's font size.
+//
+// Supported properties:
+//
+//   - box: display, position, top, right, bottom, left, z-index, box-sizing,
+//     width, height, min-width, min-height, max-width, max-height,
+//     aspect-ratio, margin, padding, border, border-width and their sides
+//   - flexbox: flex, flex-direction, flex-wrap, flex-flow, flex-grow,
+//     flex-shrink, flex-basis, order, justify-content, align-items,
+//     align-self, align-content
+//   - grid: grid-template-columns, grid-template-rows, grid-template-areas,
+//     grid-auto-columns, grid-auto-rows, grid-auto-flow, grid-row,
+//     grid-column, grid-area and their -start/-end longhands,
+//     justify-items, justify-self
+//   - gaps: gap, row-gap, column-gap
+//   - multi-column and fragmentation: columns, column-count, column-width,
+//     column-rule-width, break-before, break-after, break-inside
+//   - other: transform, writing-mode, container, container-type,
+//     container-name
+//   - text: font-size, font-family, font-weight, font-style, line-height,
+//     letter-spacing, word-spacing, text-indent, text-align,
+//     text-align-last, text-justify, white-space, overflow-wrap,
+//     word-break, text-overflow, text-transform, text-decoration,
+//     hyphens, hanging-punctuation, tab-size, vertical-align, direction
+//
+// Lengths accept calc() and any unit; units the engine doesn't know are
+// resolved by the layout context's UnitResolver.
+//
+// Example:
+//
+//	err := css.SetProperty(&node.Style, "grid-template-columns", "200px 1fr")
+func SetProperty(style *layout.Style, property, value string) error {
+	return setProperty(style, property, value, defaultFontSize)
+}
+
+// setProperty is SetProperty with the parent's font size, for
+// font-relative text properties.
+func setProperty(style *layout.Style, property, value string, parentFontSize float64) error {
+	property = strings.ToLower(strings.TrimSpace(property))
+	value = strings.TrimSpace(value)
+	set, ok := properties[property]
+	if !ok {
+		return fmt.Errorf("css: unsupported property %q", property)
+	}
+	if value == "" {
+		return fmt.Errorf("css: missing value for %s", property)
+	}
+	if err := set(style, value, parentFontSize); err != nil {
+		return fmt.Errorf("css: invalid %s %q: %v", property, value, err)
+	}
+	return nil
+}
+
+// Properties returns the names of the supported properties, sorted.
+func Properties() []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// propertySetter parses value and writes it into style. fontSize is the
+// parent's font size.
+type propertySetter func(style *layout.Style, value string, fontSize float64) error
+
+var properties map[string]propertySetter
+
+func init() {
+	properties = map[string]propertySetter{
+		"display":            setKeyword(displays, func(s *layout.Style) *layout.Display { return &s.Display }),
+		"position":           setKeyword(positions, func(s *layout.Style) *layout.Position { return &s.Position }),
+		"box-sizing":         setKeyword(boxSizings, func(s *layout.Style) *layout.BoxSizing { return &s.BoxSizing }),
+		"top":                setLength(func(s *layout.Style) *layout.Length { return &s.Top }),
+		"right":              setLength(func(s *layout.Style) *layout.Length { return &s.Right }),
+		"bottom":             setLength(func(s *layout.Style) *layout.Length { return &s.Bottom }),
+		"left":               setLength(func(s *layout.Style) *layout.Length { return &s.Left }),
+		"inset-block-start":  setLength(func(s *layout.Style) *layout.Length { return &s.InsetBlockStart }),
+		"inset-block-end":    setLength(func(s *layout.Style) *layout.Length { return &s.InsetBlockEnd }),
+		"inset-inline-start": setLength(func(s *layout.Style) *layout.Length { return &s.InsetInlineStart }),
+		"inset-inline-end":   setLength(func(s *layout.Style) *layout.Length { return &s.InsetInlineEnd }),
+		"inset-block":        setLogicalPair(layout.ParseSpacing, func(s *layout.Style) (*layout.Length, *layout.Length) { return &s.InsetBlockStart, &s.InsetBlockEnd }),
+		"inset-inline":       setLogicalPair(layout.ParseSpacing, func(s *layout.Style) (*layout.Length, *layout.Length) { return &s.InsetInlineStart, &s.InsetInlineEnd }),
+		"z-index":            setZIndex,
+		"width":              setWidth,
+		"height":             setHeight,
+		"min-width":          setMinMax(func(s *layout.Style) *layout.Length { return &s.MinWidth }),
+		"min-height":         setMinMax(func(s *layout.Style) *layout.Length { return &s.MinHeight }),
+		"max-width":          setMinMax(func(s *layout.Style) *layout.Length { return &s.MaxWidth }),
+		"max-height":         setMinMax(func(s *layout.Style) *layout.Length { return &s.MaxHeight }),
+		"aspect-ratio":       setAspectRatio,
+
+		"margin":              setSpacing(layout.ParseSpacing, func(s *layout.Style) *layout.Spacing { return &s.Margin }),
+		"margin-top":          setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.Margin.Top }),
+		"margin-right":        setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.Margin.Right }),
+		"margin-bottom":       setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.Margin.Bottom }),
+		"margin-left":         setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.Margin.Left }),
+		"margin-block-start":  setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.MarginBlockStart }),
+		"margin-block-end":    setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.MarginBlockEnd }),
+		"margin-inline-start": setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.MarginInlineStart }),
+		"margin-inline-end":   setSide(layout.ParseSpacing, func(s *layout.Style) *layout.Length { return &s.MarginInlineEnd }),
+		"margin-block":        setLogicalPair(layout.ParseSpacing, func(s *layout.Style) (*layout.Length, *layout.Length) { return &s.MarginBlockStart, &s.MarginBlockEnd }),
+		"margin-inline": setLogicalPair(layout.ParseSpacing, func(s *layout.Style) (*layout.Length, *layout.Length) {
+			return &s.MarginInlineStart, &s.MarginInlineEnd
+		}),
+		"padding":              setSpacing(layout.ParseBox, func(s *layout.Style) *layout.Spacing { return &s.Padding }),
+		"padding-top":          setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.Padding.Top }),
+		"padding-right":        setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.Padding.Right }),
+		"padding-bottom":       setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.Padding.Bottom }),
+		"padding-left":         setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.Padding.Left }),
+		"padding-block-start":  setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.PaddingBlockStart }),
+		"padding-block-end":    setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.PaddingBlockEnd }),
+		"padding-inline-start": setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.PaddingInlineStart }),
+		"padding-inline-end":   setSide(layout.ParseBox, func(s *layout.Style) *layout.Length { return &s.PaddingInlineEnd }),
+		"padding-block": setLogicalPair(layout.ParseBox, func(s *layout.Style) (*layout.Length, *layout.Length) {
+			return &s.PaddingBlockStart, &s.PaddingBlockEnd
+		}),
+		"padding-inline": setLogicalPair(layout.ParseBox, func(s *layout.Style) (*layout.Length, *layout.Length) {
+			return &s.PaddingInlineStart, &s.PaddingInlineEnd
+		}),
+		"border":              setBorder,
+		"border-width":        setBorderWidth,
+		"border-top":          setBorderSide(func(s *layout.Style) *layout.Length { return &s.Border.Top }),
+		"border-right":        setBorderSide(func(s *layout.Style) *layout.Length { return &s.Border.Right }),
+		"border-bottom":       setBorderSide(func(s *layout.Style) *layout.Length { return &s.Border.Bottom }),
+		"border-left":         setBorderSide(func(s *layout.Style) *layout.Length { return &s.Border.Left }),
+		"border-top-width":    setBorderSideWidth(func(s *layout.Style) *layout.Length { return &s.Border.Top }),
+		"border-right-width":  setBorderSideWidth(func(s *layout.Style) *layout.Length { return &s.Border.Right }),
+		"border-bottom-width": setBorderSideWidth(func(s *layout.Style) *layout.Length { return &s.Border.Bottom }),
+		"border-left-width":   setBorderSideWidth(func(s *layout.Style) *layout.Length { return &s.Border.Left }),
+
+		"flex":            setFlex,
+		"flex-direction":  setKeyword(flexDirections, func(s *layout.Style) *layout.FlexDirection { return &s.FlexDirection }),
+		"flex-wrap":       setKeyword(flexWraps, func(s *layout.Style) *layout.FlexWrap { return &s.FlexWrap }),
+		"flex-flow":       setFlexFlow,
+		"flex-grow":       setFactor(func(s *layout.Style) *float64 { return &s.FlexGrow }),
+		"flex-shrink":     setFactor(func(s *layout.Style) *float64 { return &s.FlexShrink }),
+		"flex-basis":      setFlexBasis,
+		"order":           setOrder,
+		"justify-content": setKeyword(justifyContents, func(s *layout.Style) *layout.JustifyContent { return &s.JustifyContent }),
+		"align-items":     setKeyword(alignItems, func(s *layout.Style) *layout.AlignItems { return &s.AlignItems }),
+		"align-self":      setKeyword(alignSelves, func(s *layout.Style) *layout.AlignItems { return &s.AlignSelf }),
+		"align-content":   setAlignContent,
+
+		"gap":        setGap,
+		"row-gap":    setRowGap,
+		"column-gap": setColumnGap,
+
+		"grid-template-columns": setTracks(func(s *layout.Style) *[]layout.GridTrack { return &s.GridTemplateColumns }),
+		"grid-template-rows":    setTracks(func(s *layout.Style) *[]layout.GridTrack { return &s.GridTemplateRows }),
+		"grid-template-areas":   setTemplateAreas,
+		"grid-auto-columns":     setAutoTrack(func(s *layout.Style) *layout.GridTrack { return &s.GridAutoColumns }),
+		"grid-auto-rows":        setAutoTrack(func(s *layout.Style) *layout.GridTrack { return &s.GridAutoRows }),
+		"grid-auto-flow":        setGridAutoFlow,
+		"grid-row-start":        setGridLine(func(s *layout.Style) *int { return &s.GridRowStart }),
+		"grid-row-end":          setGridLine(func(s *layout.Style) *int { return &s.GridRowEnd }),
+		"grid-column-start":     setGridLine(func(s *layout.Style) *int { return &s.GridColumnStart }),
+		"grid-column-end":       setGridLine(func(s *layout.Style) *int { return &s.GridColumnEnd }),
+		"grid-row":              setGridSpan(func(s *layout.Style) (*int, *int) { return &s.GridRowStart, &s.GridRowEnd }),
+		"grid-column":           setGridSpan(func(s *layout.Style) (*int, *int) { return &s.GridColumnStart, &s.GridColumnEnd }),
+		"grid-area":             setGridArea,
+		"justify-items":         setKeyword(justifyItems, func(s *layout.Style) *layout.JustifyItems { return &s.JustifyItems }),
+		"justify-self":          setKeyword(justifySelves, func(s *layout.Style) *layout.JustifyItems { return &s.JustifySelf }),
+
+		"columns":           setColumns,
+		"column-count":      setColumnCount,
+		"column-width":      setColumnWidth,
+		"column-rule-width": setColumnRuleWidth,
+		"break-before":      setKeyword(breakBetweens, func(s *layout.Style) *layout.BreakBetween { return &s.BreakBefore }),
+		"break-after":       setKeyword(breakBetweens, func(s *layout.Style) *layout.BreakBetween { return &s.BreakAfter }),
+		"break-inside":      setKeyword(breakInsides, func(s *layout.Style) *layout.BreakInside { return &s.BreakInside }),
+
+		"transform":        setTransform,
+		"visibility":       setKeyword(visibilities, func(s *layout.Style) *layout.Visibility { return &s.Visibility }),
+		"overflow":         setKeyword(overflows, func(s *layout.Style) *layout.Overflow { return &s.Overflow }),
+		"scrollbar-gutter": setScrollbarGutter,
+		"writing-mode":     setWritingMode,
+		"container":        setContainer,
+		"container-type":   setContainerType,
+		"container-name":   setContainerName,
+
+		"font-size":           setFontSize,
+		"font-family":         setFontFamily,
+		"font-weight":         setFontWeight,
+		"font-style":          setTextKeyword(fontStyles, func(t *layout.TextStyle) *layout.FontStyle { return &t.FontStyle }),
+		"line-height":         setLineHeight,
+		"letter-spacing":      setSpacingPx(func(t *layout.TextStyle) *float64 { return &t.LetterSpacing }),
+		"word-spacing":        setSpacingPx(func(t *layout.TextStyle) *float64 { return &t.WordSpacing }),
+		"text-indent":         setTextIndent,
+		"text-align":          setTextKeyword(textAligns, func(t *layout.TextStyle) *layout.TextAlign { return &t.TextAlign }),
+		"text-align-last":     setTextKeyword(textAlignLasts, func(t *layout.TextStyle) *layout.TextAlignLast { return &t.TextAlignLast }),
+		"text-justify":        setTextKeyword(textJustifies, func(t *layout.TextStyle) *layout.TextJustify { return &t.TextJustify }),
+		"white-space":         setTextKeyword(whiteSpaces, func(t *layout.TextStyle) *layout.WhiteSpace { return &t.WhiteSpace }),
+		"overflow-wrap":       setTextKeyword(overflowWraps, func(t *layout.TextStyle) *layout.OverflowWrap { return &t.OverflowWrap }),
+		"word-wrap":           setTextKeyword(overflowWraps, func(t *layout.TextStyle) *layout.OverflowWrap { return &t.OverflowWrap }),
+		"word-break":          setTextKeyword(wordBreaks, func(t *layout.TextStyle) *layout.WordBreak { return &t.WordBreak }),
+		"text-overflow":       setTextKeyword(textOverflows, func(t *layout.TextStyle) *layout.TextOverflow { return &t.TextOverflow }),
+		"text-transform":      setTextKeyword(textTransforms, func(t *layout.TextStyle) *layout.TextTransform { return &t.TextTransform }),
+		"text-decoration":     setTextDecoration,
+		"hyphens":             setTextKeyword(hyphens, func(t *layout.TextStyle) *layout.Hyphens { return &t.Hyphens }),
+		"hanging-punctuation": setHangingPunctuation,
+		"tab-size":            setTabSize,
+		"vertical-align":      setTextKeyword(verticalAligns, func(t *layout.TextStyle) *layout.VerticalAlign { return &t.VerticalAlign }),
+		"direction":           setTextKeyword(directions, func(t *layout.TextStyle) *layout.Direction { return &t.Direction }),
+	}
+}
+
+// Keyword tables. inline-* display values map to their block-level
+// counterparts, since layout has no inline formatting context for boxes.
+var (
+	displays = map[string]layout.Display{
+		"block": layout.DisplayBlock, "inline-block": layout.DisplayBlock, "flow-root": layout.DisplayBlock,
+		"flex": layout.DisplayFlex, "inline-flex": layout.DisplayFlex,
+		"grid": layout.DisplayGrid, "inline-grid": layout.DisplayGrid,
+		"inline": layout.DisplayInlineText, "none": layout.DisplayNone,
+	}
+	positions = map[string]layout.Position{
+		"static": layout.PositionStatic, "relative": layout.PositionRelative,
+		"absolute": layout.PositionAbsolute, "fixed": layout.PositionFixed, "sticky": layout.PositionSticky,
+	}
+	overflows = map[string]layout.Overflow{
+		"visible": layout.OverflowVisible, "hidden": layout.OverflowHidden, "clip": layout.OverflowHidden,
+		"scroll": layout.OverflowScroll, "auto": layout.OverflowAuto,
+	}
+	scrollbarGutters = map[string]layout.ScrollbarGutter{
+		"auto": layout.ScrollbarGutterAuto, "stable": layout.ScrollbarGutterStable,
+		"stable both-edges": layout.ScrollbarGutterStableBothEdges, "both-edges stable": layout.ScrollbarGutterStableBothEdges,
+	}
+	visibilities = map[string]layout.Visibility{
+		"visible": layout.VisibilityVisible, "hidden": layout.VisibilityHidden, "collapse": layout.VisibilityHidden,
+	}
+	boxSizings = map[string]layout.BoxSizing{
+		"content-box": layout.BoxSizingContentBox, "border-box": layout.BoxSizingBorderBox,
+	}
+	flexDirections = map[string]layout.FlexDirection{
+		"row": layout.FlexDirectionRow, "row-reverse": layout.FlexDirectionRowReverse,
+		"column": layout.FlexDirectionColumn, "column-reverse": layout.FlexDirectionColumnReverse,
+	}
+	flexWraps = map[string]layout.FlexWrap{
+		"nowrap": layout.FlexWrapNoWrap, "wrap": layout.FlexWrapWrap, "wrap-reverse": layout.FlexWrapWrapReverse,
+	}
+	justifyContents = map[string]layout.JustifyContent{
+		"normal": layout.JustifyContentFlexStart, "flex-start": layout.JustifyContentFlexStart, "start": layout.JustifyContentFlexStart,
+		"flex-end": layout.JustifyContentFlexEnd, "end": layout.JustifyContentFlexEnd, "center": layout.JustifyContentCenter,
+		"space-between": layout.JustifyContentSpaceBetween, "space-around": layout.JustifyContentSpaceAround,
+		"space-evenly": layout.JustifyContentSpaceEvenly,
+	}
+	alignItems = map[string]layout.AlignItems{
+		"normal": layout.AlignItemsStretch, "stretch": layout.AlignItemsStretchExplicit,
+		"flex-start": layout.AlignItemsFlexStart, "start": layout.AlignItemsFlexStart, "self-start": layout.AlignItemsFlexStart,
+		"flex-end": layout.AlignItemsFlexEnd, "end": layout.AlignItemsFlexEnd, "self-end": layout.AlignItemsFlexEnd,
+		"center": layout.AlignItemsCenter, "baseline": layout.AlignItemsBaseline,
+	}
+	// alignSelves maps auto to the zero value, which defers to the parent's
+	// align-items.
+	alignSelves   = withKeyword(alignItems, "auto", layout.AlignItemsStretch)
+	alignContents = map[string]layout.AlignContent{
+		"normal": layout.AlignContentStretch, "stretch": layout.AlignContentStretch,
+		"flex-start": layout.AlignContentFlexStart, "start": layout.AlignContentFlexStart,
+		"flex-end": layout.AlignContentFlexEnd, "end": layout.AlignContentFlexEnd, "center": layout.AlignContentCenter,
+		"space-between": layout.AlignContentSpaceBetween, "space-around": layout.AlignContentSpaceAround,
+		"space-evenly": layout.AlignContentSpaceEvenly,
+	}
+	justifyItems = map[string]layout.JustifyItems{
+		"normal": layout.JustifyItemsStretch, "stretch": layout.JustifyItemsStretchExplicit,
+		"start": layout.JustifyItemsStart, "flex-start": layout.JustifyItemsStart, "self-start": layout.JustifyItemsStart, "left": layout.JustifyItemsStart,
+		"end": layout.JustifyItemsEnd, "flex-end": layout.JustifyItemsEnd, "self-end": layout.JustifyItemsEnd, "right": layout.JustifyItemsEnd,
+		"center": layout.JustifyItemsCenter,
+	}
+	justifySelves = withKeyword(justifyItems, "auto", layout.JustifyItemsStretch)
+	breakBetweens = map[string]layout.BreakBetween{
+		"auto": layout.BreakAuto, "avoid": layout.BreakAvoid, "avoid-page": layout.BreakAvoid,
+		"page": layout.BreakPage, "left": layout.BreakPage, "right": layout.BreakPage,
+		"recto": layout.BreakPage, "verso": layout.BreakPage,
+	}
+	breakInsides = map[string]layout.BreakInside{
+		"auto": layout.BreakInsideAuto, "avoid": layout.BreakInsideAvoid, "avoid-page": layout.BreakInsideAvoid,
+	}
+	writingModes = map[string]layout.WritingMode{
+		"horizontal-tb": layout.WritingModeHorizontalTB,
+		"vertical-rl":   layout.WritingModeVerticalRL, "vertical-lr": layout.WritingModeVerticalLR,
+		"sideways-rl": layout.WritingModeSidewaysRL, "sideways-lr": layout.WritingModeSidewaysLR,
+	}
+
+	fontStyles = map[string]layout.FontStyle{
+		"normal": layout.FontStyleNormal, "italic": layout.FontStyleItalic, "oblique": layout.FontStyleOblique,
+	}
+	textAligns = map[string]layout.TextAlign{
+		"start": layout.TextAlignStart, "end": layout.TextAlignEnd, "left": layout.TextAlignLeft,
+		"right": layout.TextAlignRight, "center": layout.TextAlignCenter, "justify": layout.TextAlignJustify,
+	}
+	textAlignLasts = map[string]layout.TextAlignLast{
+		"auto": layout.TextAlignLastAuto, "left": layout.TextAlignLastLeft, "right": layout.TextAlignLastRight,
+		"center": layout.TextAlignLastCenter, "justify": layout.TextAlignLastJustify,
+		"start": layout.TextAlignLastStart, "end": layout.TextAlignLastEnd,
+	}
+	textJustifies = map[string]layout.TextJustify{
+		"auto": layout.TextJustifyAuto, "inter-word": layout.TextJustifyInterWord,
+		"inter-character": layout.TextJustifyInterCharacter, "distribute": layout.TextJustifyDistribute,
+		"none": layout.TextJustifyNone,
+	}
+	whiteSpaces = map[string]layout.WhiteSpace{
+		"normal": layout.WhiteSpaceNormal, "nowrap": layout.WhiteSpaceNowrap, "pre": layout.WhiteSpacePre,
+		"pre-wrap": layout.WhiteSpacePreWrap, "pre-line": layout.WhiteSpacePreLine,
+	}
+	overflowWraps = map[string]layout.OverflowWrap{
+		"normal": layout.OverflowWrapNormal, "break-word": layout.OverflowWrapBreakWord, "anywhere": layout.OverflowWrapAnywhere,
+	}
+	wordBreaks = map[string]layout.WordBreak{
+		"normal": layout.WordBreakNormal, "break-all": layout.WordBreakBreakAll, "keep-all": layout.WordBreakKeepAll,
+		"break-word": layout.WordBreakNormal,
+	}
+	textOverflows = map[string]layout.TextOverflow{
+		"clip": layout.TextOverflowClip, "ellipsis": layout.TextOverflowEllipsis,
+	}
+	textTransforms = map[string]layout.TextTransform{
+		"none": layout.TextTransformNone, "uppercase": layout.TextTransformUppercase,
+		"lowercase": layout.TextTransformLowercase, "capitalize": layout.TextTransformCapitalize,
+		"full-width": layout.TextTransformFullWidth, "full-size-kana": layout.TextTransformFullSizeKana,
+	}
+	hyphens = map[string]layout.Hyphens{
+		"none": layout.HyphensNone, "manual": layout.HyphensManual, "auto": layout.HyphensAuto,
+	}
+	hangingPunctuations = map[string]layout.HangingPunctuation{
+		"none": layout.HangingPunctuationNone, "first": layout.HangingPunctuationFirst,
+		"last": layout.HangingPunctuationLast, "force-end": layout.HangingPunctuationForceEnd,
+		"allow-end": layout.HangingPunctuationAllowEnd,
+	}
+	verticalAligns = map[string]layout.VerticalAlign{
+		"baseline": layout.VerticalAlignBaseline, "sub": layout.VerticalAlignSub, "super": layout.VerticalAlignSuper,
+		"text-top": layout.VerticalAlignTextTop, "text-bottom": layout.VerticalAlignTextBottom,
+		"middle": layout.VerticalAlignMiddle, "top": layout.VerticalAlignTop, "bottom": layout.VerticalAlignBottom,
+	}
+	directions = map[string]layout.Direction{
+		"ltr": layout.DirectionLTR, "rtl": layout.DirectionRTL,
+	}
+	fontWeights = map[string]layout.FontWeight{
+		"normal": layout.FontWeightNormal, "bold": layout.FontWeightBold,
+	}
+	textDecorations = map[string]layout.TextDecoration{
+		"none": layout.TextDecorationNone, "underline": layout.TextDecorationUnderline,
+		"overline": layout.TextDecorationOverline, "line-through": layout.TextDecorationLineThrough,
+	}
+)
+
+// withKeyword returns a copy of table with one more keyword.
+func withKeyword[T any](table map[string]T, keyword string, v T) map[string]T {
+	out := make(map[string]T, len(table)+1)
+	for k, tv := range table {
+		out[k] = tv
+	}
+	out[keyword] = v
+	return out
+}
+
+// lookupKeyword looks up a case-insensitive keyword.
+func lookupKeyword[T any](table map[string]T, value string) (T, error) {
+	v, ok := table[strings.ToLower(value)]
+	if !ok {
+		return v, fmt.Errorf("unknown keyword")
+	}
+	return v, nil
+}
+
+func setKeyword[T any](table map[string]T, field func(*layout.Style) *T) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		v, err := lookupKeyword(table, value)
+		if err != nil {
+			return err
+		}
+		*field(s) = v
+		return nil
+	}
+}
+
+// textStyle returns style's TextStyle, creating it with normal spacing
+// and tab size if it is nil.
+func textStyle(s *layout.Style) *layout.TextStyle {
+	if s.TextStyle == nil {
+		s.TextStyle = &layout.TextStyle{WordSpacing: -1, LetterSpacing: -1, TabSize: -1}
+	}
+	return s.TextStyle
+}
+
+func setTextKeyword[T any](table map[string]T, field func(*layout.TextStyle) *T) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		v, err := lookupKeyword(table, value)
+		if err != nil {
+			return err
+		}
+		*field(textStyle(s)) = v
+		return nil
+	}
+}
+
+func setLength(field func(*layout.Style) *layout.Length) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		l, err := parseLength(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = l
+		return nil
+	}
+}
+
+func setZIndex(s *layout.Style, value string, _ float64) error {
+	if strings.EqualFold(value, "auto") {
+		s.ZIndex = 0
+		return nil
+	}
+	n, err := parseInteger(value)
+	if err != nil {
+		return err
+	}
+	s.ZIndex = n
+	return nil
+}
+
+// parseSize parses a width or height: auto, a length, min-content,
+// max-content, fit-content or fit-content(<length>). It returns the Width
+// or Height value and the intrinsic sizing mode.
+func parseSize(value string) (layout.Length, layout.IntrinsicSize, *layout.Length, error) {
+	switch strings.ToLower(value) {
+	case "auto":
+		return layout.Px(-1), layout.IntrinsicSizeNone, nil, nil
+	case "min-content":
+		return layout.Px(layout.SizeMinContent), layout.IntrinsicSizeMinContent, nil, nil
+	case "max-content":
+		return layout.Px(layout.SizeMaxContent), layout.IntrinsicSizeMaxContent, nil, nil
+	case "fit-content":
+		return layout.Px(layout.SizeFitContent), layout.IntrinsicSizeFitContent, nil, nil
+	}
+	if name, args, ok := parseFunction(value); ok && name == "fit-content" && len(args) == 1 {
+		l, err := parseLength(args[0])
+		if err != nil {
+			return layout.Length{}, 0, nil, err
+		}
+		return layout.Px(layout.SizeFitContent), layout.IntrinsicSizeFitContent, &l, nil
+	}
+	l, err := parseLength(value)
+	if err != nil {
+		return layout.Length{}, 0, nil, err
+	}
+	if l.Value < 0 && !strings.HasPrefix(string(l.Unit), "calc(") {
+		return layout.Length{}, 0, nil, fmt.Errorf("negative size")
+	}
+	return l, layout.IntrinsicSizeNone, nil, nil
+}
+
+func setWidth(s *layout.Style, value string, _ float64) error {
+	l, sizing, fit, err := parseSize(value)
+	if err != nil {
+		return err
+	}
+	s.Width, s.WidthSizing = l, sizing
+	if fit != nil {
+		s.FitContentWidth = *fit
+	}
+	return nil
+}
+
+func setHeight(s *layout.Style, value string, _ float64) error {
+	l, sizing, fit, err := parseSize(value)
+	if err != nil {
+		return err
+	}
+	s.Height, s.HeightSizing = l, sizing
+	if fit != nil {
+		s.FitContentHeight = *fit
+	}
+	return nil
+}
+
+// setMinMax sets a min-* or max-* size, where none and auto are the zero
+// Length.
+func setMinMax(field func(*layout.Style) *layout.Length) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		if lower := strings.ToLower(value); lower == "none" || lower == "auto" {
+			*field(s) = layout.Length{}
+			return nil
+		}
+		l, err := parseLength(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = l
+		return nil
+	}
+}
+
+// setAspectRatio parses auto, a ratio such as 16 / 9, or a number.
+func setAspectRatio(s *layout.Style, value string, _ float64) error {
+	if strings.EqualFold(value, "auto") {
+		s.AspectRatio = 0
+		return nil
+	}
+	w, h, found := strings.Cut(value, "/")
+	num, err := parseNumber(w)
+	if err != nil {
+		return err
+	}
+	den := 1.0
+	if found {
+		if den, err = parseNumber(h); err != nil {
+			return err
+		}
+	}
+	if num <= 0 || den <= 0 {
+		return fmt.Errorf("ratio must be positive")
+	}
+	s.AspectRatio = num / den
+	return nil
+}
+
+// setSpacing sets a box shorthand with parse, layout.ParseSpacing for
+// margins or layout.ParseBox for padding.
+func setSpacing(parse func(string) (layout.Spacing, error), field func(*layout.Style) *layout.Spacing) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		sp, err := parse(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = sp
+		return nil
+	}
+}
+
+// setSide sets a single side, validated like the shorthand.
+func setSide(parse func(string) (layout.Spacing, error), field func(*layout.Style) *layout.Length) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		if n := len(splitFields(value)); n != 1 {
+			return fmt.Errorf("expected 1 value, got %d", n)
+		}
+		sp, err := parse(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = sp.Top
+		return nil
+	}
+}
+
+// setLogicalPair sets a start and end side from a shorthand such as
+// margin-inline: one value for both, or the start then the end.
+func setLogicalPair(parse func(string) (layout.Spacing, error), fields func(*layout.Style) (*layout.Length, *layout.Length)) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		if n := len(splitFields(value)); n < 1 || n > 2 {
+			return fmt.Errorf("expected 1 or 2 values, got %d", n)
+		}
+		sp, err := parse(value)
+		if err != nil {
+			return err
+		}
+		start, end := fields(s)
+		*start, *end = sp.Top, sp.Right
+		return nil
+	}
+}
+
+func setBorder(s *layout.Style, value string, _ float64) error {
+	w, err := parseBorderShorthand(value)
+	if err != nil {
+		return err
+	}
+	s.Border = layout.Spacing{Top: w, Right: w, Bottom: w, Left: w}
+	return nil
+}
+
+func setBorderWidth(s *layout.Style, value string, _ float64) error {
+	fields := splitFields(value)
+	for i, f := range fields {
+		if px, ok := borderWidthKeywords[strings.ToLower(f)]; ok {
+			fields[i] = fmt.Sprintf("%gpx", px)
+		}
+	}
+	sp, err := layout.ParseBox(strings.Join(fields, " "))
+	if err != nil {
+		return err
+	}
+	s.Border = sp
+	return nil
+}
+
+func setBorderSide(field func(*layout.Style) *layout.Length) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		w, err := parseBorderShorthand(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = w
+		return nil
+	}
+}
+
+func setBorderSideWidth(field func(*layout.Style) *layout.Length) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		w, err := parseBorderWidth(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = w
+		return nil
+	}
+}
+
+// setFlex parses the flex shorthand: none, auto, initial, or one to three
+// of <grow> [<shrink>] [<basis>]. A number-only shorthand has a 0 basis.
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#flex-property
+func setFlex(s *layout.Style, value string, _ float64) error {
+	switch strings.ToLower(value) {
+	case "none":
+		s.FlexGrow, s.FlexShrink, s.FlexBasis = 0, 0, layout.BasisAuto()
+		return nil
+	case "auto":
+		s.FlexGrow, s.FlexShrink, s.FlexBasis = 1, 1, layout.BasisAuto()
+		return nil
+	case "initial":
+		s.FlexGrow, s.FlexShrink, s.FlexBasis = 0, 1, layout.BasisAuto()
+		return nil
+	}
+	grow, shrink, basis := -1.0, -1.0, layout.Basis(layout.Px(0))
+	haveBasis, afterGrow := false, false
+	for _, f := range splitFields(value) {
+		// A number is the grow factor, or the shrink factor right after it;
+		// anything else (including a second 0) is the basis
+		if v, err := parseNumber(f); err == nil && (grow < 0 || afterGrow && shrink < 0) {
+			if v < 0 {
+				return fmt.Errorf("negative flex factor")
+			}
+			if grow < 0 {
+				grow, afterGrow = v, true
+			} else {
+				shrink = v
+			}
+			continue
+		}
+		afterGrow = false
+		if haveBasis {
+			return fmt.Errorf("unexpected %q", f)
+		}
+		b, err := parseFlexBasis(f)
+		if err != nil {
+			return err
+		}
+		basis, haveBasis = b, true
+	}
+	if grow < 0 {
+		grow = 1
+	}
+	if shrink < 0 {
+		shrink = 1
+	}
+	s.FlexGrow, s.FlexShrink, s.FlexBasis = grow, shrink, basis
+	return nil
+}
+
+func parseFlexBasis(value string) (layout.FlexBasis, error) {
+	switch strings.ToLower(value) {
+	case "auto":
+		return layout.BasisAuto(), nil
+	case "content":
+		return layout.BasisContent(), nil
+	}
+	l, err := parseLength(value)
+	if err != nil {
+		return layout.FlexBasis{}, err
+	}
+	return layout.Basis(l), nil
+}
+
+func setFlexBasis(s *layout.Style, value string, _ float64) error {
+	b, err := parseFlexBasis(value)
+	if err != nil {
+		return err
+	}
+	s.FlexBasis = b
+	return nil
+}
+
+// setFlexFlow parses flex-flow: a direction, a wrap, or both in any order.
+func setFlexFlow(s *layout.Style, value string, _ float64) error {
+	fields := strings.Fields(value)
+	if len(fields) > 2 {
+		return fmt.Errorf("expected a direction and a wrap")
+	}
+	dir, wrap := layout.FlexDirectionRow, layout.FlexWrapNoWrap
+	haveDir, haveWrap := false, false
+	for _, f := range fields {
+		if d, err := lookupKeyword(flexDirections, f); err == nil && !haveDir {
+			dir, haveDir = d, true
+		} else if w, err := lookupKeyword(flexWraps, f); err == nil && !haveWrap {
+			wrap, haveWrap = w, true
+		} else {
+			return fmt.Errorf("unexpected %q", f)
+		}
+	}
+	s.FlexDirection, s.FlexWrap = dir, wrap
+	return nil
+}
+
+func setFactor(field func(*layout.Style) *float64) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		v, err := parseNumber(value)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return fmt.Errorf("negative flex factor")
+		}
+		*field(s) = v
+		return nil
+	}
+}
+
+func setOrder(s *layout.Style, value string, _ float64) error {
+	n, err := parseInteger(value)
+	if err != nil {
+		return err
+	}
+	s.Order = n
+	return nil
+}
+
+// setAlignContent parses align-content with an optional safe or unsafe
+// overflow position.
+func setAlignContent(s *layout.Style, value string, _ float64) error {
+	fields := strings.Fields(strings.ToLower(value))
+	overflow := layout.OverflowAlignmentDefault
+	if len(fields) == 2 {
+		switch fields[0] {
+		case "safe":
+			overflow = layout.OverflowAlignmentSafe
+		case "unsafe":
+			overflow = layout.OverflowAlignmentUnsafe
+		default:
+			return fmt.Errorf("expected safe or unsafe, got %q", fields[0])
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return fmt.Errorf("expected one keyword")
+	}
+	v, err := lookupKeyword(alignContents, fields[0])
+	if err != nil {
+		return err
+	}
+	s.AlignContent, s.AlignContentOverflow = v, overflow
+	return nil
+}
+
+// parseGap parses a gap length, where normal is 0.
+func parseGap(value string) (layout.Length, error) {
+	if strings.EqualFold(value, "normal") {
+		return layout.Px(0), nil
+	}
+	return parseLength(value)
+}
+
+// setGap parses the gap shorthand: <row-gap> [<column-gap>]. Gaps apply to
+// flex, grid and multi-column containers alike.
+func setGap(s *layout.Style, value string, _ float64) error {
+	fields := splitFields(value)
+	if len(fields) < 1 || len(fields) > 2 {
+		return fmt.Errorf("expected 1 or 2 values")
+	}
+	if err := setRowGap(s, fields[0], 0); err != nil {
+		return err
+	}
+	return setColumnGap(s, fields[len(fields)-1], 0)
+}
+
+func setRowGap(s *layout.Style, value string, _ float64) error {
+	g, err := parseGap(value)
+	if err != nil {
+		return err
+	}
+	s.FlexRowGap, s.GridRowGap = g, g
+	return nil
+}
+
+func setColumnGap(s *layout.Style, value string, _ float64) error {
+	g, err := parseGap(value)
+	if err != nil {
+		return err
+	}
+	s.FlexColumnGap, s.GridColumnGap = g, g
+	if strings.EqualFold(value, "normal") {
+		s.ColumnGap = layout.Length{}
+	} else {
+		s.ColumnGap = g
+	}
+	return nil
+}
+
+func setTracks(field func(*layout.Style) *[]layout.GridTrack) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		if strings.EqualFold(value, "none") {
+			*field(s) = nil
+			return nil
+		}
+		tracks, err := parseTrackList(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = tracks
+		return nil
+	}
+}
+
+func setAutoTrack(field func(*layout.Style) *layout.GridTrack) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		t, err := parseTrack(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = t
+		return nil
+	}
+}
+
+func setTemplateAreas(s *layout.Style, value string, _ float64) error {
+	if strings.EqualFold(value, "none") {
+		s.GridTemplateAreas = nil
+		return nil
+	}
+	areas, err := parseGridTemplateAreas(value)
+	if err != nil {
+		return err
+	}
+	s.GridTemplateAreas = areas
+	return nil
+}
+
+// setGridAutoFlow parses row, column, dense, or row/column with dense.
+func setGridAutoFlow(s *layout.Style, value string, _ float64) error {
+	column, dense := false, false
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) > 2 {
+		return fmt.Errorf("too many keywords")
+	}
+	for _, f := range fields {
+		switch f {
+		case "row":
+		case "column":
+			column = true
+		case "dense":
+			dense = true
+		default:
+			return fmt.Errorf("unknown keyword %q", f)
+		}
+	}
+	switch {
+	case column && dense:
+		s.GridAutoFlow = layout.GridAutoFlowColumnDense
+	case column:
+		s.GridAutoFlow = layout.GridAutoFlowColumn
+	case dense:
+		s.GridAutoFlow = layout.GridAutoFlowRowDense
+	default:
+		s.GridAutoFlow = layout.GridAutoFlowRow
+	}
+	return nil
+}
+
+func setGridLine(field func(*layout.Style) *int) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		n, err := parseGridLine(value)
+		if err != nil {
+			return err
+		}
+		*field(s) = n
+		return nil
+	}
+}
+
+// setGridSpan parses the grid-row and grid-column shorthands:
+// <start> [/ <end>]. A missing end is auto.
+func setGridSpan(fields func(*layout.Style) (*int, *int)) propertySetter {
+	return func(s *layout.Style, value string, _ float64) error {
+		parts := strings.Split(value, "/")
+		if len(parts) > 2 {
+			return fmt.Errorf("expected <start> / <end>")
+		}
+		start, err := parseGridLine(parts[0])
+		if err != nil {
+			return err
+		}
+		end := layout.GridLineAuto
+		if len(parts) == 2 {
+			if end, err = parseGridLine(parts[1]); err != nil {
+				return err
+			}
+		}
+		startField, endField := fields(s)
+		*startField, *endField = start, end
+		return nil
+	}
+}
+
+// setGridArea parses grid-area: an area name from grid-template-areas, or
+// <row-start> / <column-start> / <row-end> / <column-end> with missing
+// lines auto.
+func setGridArea(s *layout.Style, value string, _ float64) error {
+	parts := strings.Split(value, "/")
+	if len(parts) == 1 {
+		name := strings.TrimSpace(value)
+		if identPrefix(name) == name && !strings.EqualFold(name, "auto") && (name[0] < '0' || name[0] > '9') && name[0] != '-' {
+			s.GridArea = name
+			return nil
+		}
+	}
+	if len(parts) > 4 {
+		return fmt.Errorf("expected at most 4 lines")
+	}
+	lines := [4]int{layout.GridLineAuto, layout.GridLineAuto, layout.GridLineAuto, layout.GridLineAuto}
+	for i, p := range parts {
+		n, err := parseGridLine(p)
+		if err != nil {
+			return err
+		}
+		lines[i] = n
+	}
+	s.GridArea = ""
+	s.GridRowStart, s.GridColumnStart, s.GridRowEnd, s.GridColumnEnd = lines[0], lines[1], lines[2], lines[3]
+	return nil
+}
+
+// setColumns parses the columns shorthand: a column width, a count, or
+// both in any order.
+func setColumns(s *layout.Style, value string, _ float64) error {
+	fields := splitFields(value)
+	if len(fields) > 2 {
+		return fmt.Errorf("expected a width and a count")
+	}
+	count, width := 0, layout.Length{}
+	for _, f := range fields {
+		if strings.EqualFold(f, "auto") {
+			continue
+		}
+		if n, err := parseInteger(f); err == nil {
+			if n < 1 {
+				return fmt.Errorf("column count must be positive")
+			}
+			count = n
+			continue
+		}
+		l, err := parseLength(f)
+		if err != nil {
+			return err
+		}
+		width = l
+	}
+	s.ColumnCount, s.ColumnWidth = count, width
+	return nil
+}
+
+func setColumnCount(s *layout.Style, value string, _ float64) error {
+	if strings.EqualFold(value, "auto") {
+		s.ColumnCount = 0
+		return nil
+	}
+	n, err := parseInteger(value)
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return fmt.Errorf("column count must be positive")
+	}
+	s.ColumnCount = n
+	return nil
+}
+
+func setColumnWidth(s *layout.Style, value string, _ float64) error {
+	if strings.EqualFold(value, "auto") {
+		s.ColumnWidth = layout.Length{}
+		return nil
+	}
+	l, err := parseLength(value)
+	if err != nil {
+		return err
+	}
+	s.ColumnWidth = l
+	return nil
+}
+
+func setColumnRuleWidth(s *layout.Style, value string, _ float64) error {
+	l, err := parseBorderWidth(value)
+	if err != nil {
+		return err
+	}
+	s.ColumnRuleWidth = l
+	return nil
+}
+
+func setTransform(s *layout.Style, value string, _ float64) error {
+	t, err := parseTransform(value)
+	if err != nil {
+		return err
+	}
+	s.Transform = t
+	return nil
+}
+
+// setWritingMode sets the box's writing mode and, if the node has a
+// TextStyle, the text's writing mode, so that both agree.
+func setWritingMode(s *layout.Style, value string, _ float64) error {
+	wm, err := lookupKeyword(writingModes, value)
+	if err != nil {
+		return err
+	}
+	s.WritingMode = wm
+	if s.TextStyle != nil {
+		s.TextStyle.WritingMode = wm
+	}
+	return nil
+}
+
+func setScrollbarGutter(s *layout.Style, value string, _ float64) error {
+	gutter, err := lookupKeyword(scrollbarGutters, strings.Join(strings.Fields(value), " "))
+	if err != nil {
+		return err
+	}
+	s.ScrollbarGutter = gutter
+	return nil
+}
+
+func setContainer(s *layout.Style, value string, _ float64) error {
+	name, typ, err := layout.ParseContainer(value)
+	if err != nil {
+		return err
+	}
+	s.ContainerName, s.ContainerType = name, typ
+	return nil
+}
+
+func setContainerType(s *layout.Style, value string, _ float64) error {
+	typ, err := layout.ParseContainerType(value)
+	if err != nil {
+		return err
+	}
+	s.ContainerType = typ
+	return nil
+}
+
+func setContainerName(s *layout.Style, value string, _ float64) error {
+	name, err := layout.ParseContainerName(value)
+	if err != nil {
+		return err
+	}
+	s.ContainerName = name
+	return nil
+}
+
+// fontSizeKeywords are the absolute font-size keywords, in pixels.
+var fontSizeKeywords = map[string]float64{
+	"xx-small": 9, "x-small": 10, "small": 13, "medium": 16,
+	"large": 18, "x-large": 24, "xx-large": 32, "xxx-large": 48,
+}
+
+// setFontSize resolves font-size to pixels, since TextStyle.FontSize is
+// in pixels. em and % are of the parent's font size; other relative units
+// use the initial font size as the root.
+func setFontSize(s *layout.Style, value string, parentFontSize float64) error {
+	lower := strings.ToLower(value)
+	size, ok := fontSizeKeywords[lower]
+	switch {
+	case ok:
+	case lower == "smaller":
+		size = parentFontSize / 1.2
+	case lower == "larger":
+		size = parentFontSize * 1.2
+	default:
+		l, err := parseLength(value)
+		if err != nil {
+			return err
+		}
+		if l.Unit == layout.PercentUnit {
+			size = l.Value / 100 * parentFontSize
+		} else {
+			size = layout.ResolveLength(l, nil, parentFontSize)
+		}
+	}
+	if size < 0 {
+		return fmt.Errorf("negative font size")
+	}
+	textStyle(s).FontSize = size
+	return nil
+}
+
+func setFontFamily(s *layout.Style, value string, _ float64) error {
+	textStyle(s).FontFamily = value
+	return nil
+}
+
+func setFontWeight(s *layout.Style, value string, _ float64) error {
+	w, err := lookupKeyword(fontWeights, value)
+	if err != nil {
+		n, nerr := parseInteger(value)
+		if nerr != nil || n < 1 || n > 1000 {
+			return fmt.Errorf("expected normal, bold or 1 to 1000")
+		}
+		w = layout.FontWeight(n)
+	}
+	textStyle(s).FontWeight = w
+	return nil
+}
+
+// setLineHeight parses normal, a multiplier, a percentage or a length.
+// TextStyle.LineHeight treats values under 10 as multipliers, so small
+// lengths are converted to a multiplier of the parent's font size.
+func setLineHeight(s *layout.Style, value string, parentFontSize float64) error {
+	ts := textStyle(s)
+	if strings.EqualFold(value, "normal") {
+		ts.LineHeight = 0
+		return nil
+	}
+	if n, err := parseNumber(value); err == nil {
+		if n < 0 {
+			return fmt.Errorf("negative line height")
+		}
+		ts.LineHeight = n
+		return nil
+	}
+	l, err := parseLength(value)
+	if err != nil {
+		return err
+	}
+	fontSize := parentFontSize
+	if ts.FontSize > 0 {
+		fontSize = ts.FontSize
+	}
+	if l.Unit == layout.PercentUnit {
+		ts.LineHeight = l.Value / 100
+		return nil
+	}
+	px := layout.ResolveLength(l, nil, fontSize)
+	switch {
+	case px < 0:
+		return fmt.Errorf("negative line height")
+	case px < 10 && fontSize > 0:
+		ts.LineHeight = px / fontSize
+	default:
+		ts.LineHeight = px
+	}
+	return nil
+}
+
+// setSpacingPx sets letter-spacing or word-spacing in pixels, where normal
+// is -1.
+func setSpacingPx(field func(*layout.TextStyle) *float64) propertySetter {
+	return func(s *layout.Style, value string, parentFontSize float64) error {
+		if strings.EqualFold(value, "normal") {
+			*field(textStyle(s)) = -1
+			return nil
+		}
+		px, err := parsePixels(value, parentFontSize)
+		if err != nil {
+			return err
+		}
+		*field(textStyle(s)) = px
+		return nil
+	}
+}
+
+func setTextIndent(s *layout.Style, value string, parentFontSize float64) error {
+	px, err := parsePixels(value, parentFontSize)
+	if err != nil {
+		return err
+	}
+	textStyle(s).TextIndent = px
+	return nil
+}
+
+// setTextDecoration parses text-decoration or text-decoration-line. Only
+// the lines are kept; style and color keywords are ignored.
+func setTextDecoration(s *layout.Style, value string, _ float64) error {
+	var td layout.TextDecoration
+	matched := false
+	for _, f := range strings.Fields(value) {
+		if d, err := lookupKeyword(textDecorations, f); err == nil {
+			td |= d
+			matched = true
+		}
+	}
+	if !matched {
+		return fmt.Errorf("expected none or decoration lines")
+	}
+	textStyle(s).TextDecoration = td
+	return nil
+}
+
+// setHangingPunctuation parses none or a combination of first, last and
+// force-end or allow-end.
+func setHangingPunctuation(s *layout.Style, value string, _ float64) error {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("expected none or hanging punctuation keywords")
+	}
+	var hp layout.HangingPunctuation
+	for _, f := range fields {
+		h, err := lookupKeyword(hangingPunctuations, f)
+		if err != nil {
+			return err
+		}
+		hp |= h
+	}
+	if hp&layout.HangingPunctuationForceEnd != 0 && hp&layout.HangingPunctuationAllowEnd != 0 {
+		return fmt.Errorf("expected force-end or allow-end, not both")
+	}
+	textStyle(s).HangingPunctuation = hp
+	return nil
+}
+
+func setTabSize(s *layout.Style, value string, _ float64) error {
+	n, err := parseNumber(value)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("negative tab size")
+	}
+	textStyle(s).TabSize = n
+	return nil
+}
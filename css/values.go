@@ -0,0 +1,485 @@
+package css
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// defaultFontSize is the CSS initial font size (medium), used for
+// relative font sizes when there is no parent.
+const defaultFontSize = 16.0
+
+// parseNumber parses a unitless number.
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("expected a number, got %q", s)
+	}
+	return v, nil
+}
+
+// parseInteger parses a unitless integer.
+func parseInteger(s string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("expected an integer, got %q", s)
+	}
+	return v, nil
+}
+
+// splitNumber splits a dimension such as "12.5px" into its number and
+// unit. The unit is "" for a plain number and "%" for a percentage.
+func splitNumber(s string) (float64, string, bool) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+		digits++
+	}
+	if digits == 0 {
+		return 0, "", false
+	}
+	// Exponent, but not the e of em or ex
+	if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') && (s[i+1] >= '0' && s[i+1] <= '9' || (s[i+1] == '-' || s[i+1] == '+') && i+2 < len(s) && s[i+2] >= '0' && s[i+2] <= '9') {
+		i += 2
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, "", false
+	}
+	unit := s[i:]
+	if unit != "" && unit != "%" && identPrefix(unit) != unit {
+		return 0, "", false
+	}
+	return v, unit, true
+}
+
+// parseLength parses a <length-percentage>: a dimension, a percentage,
+// 0, or a calc() expression. Units the engine doesn't know are kept for
+// the layout context's UnitResolver.
+func parseLength(s string) (layout.Length, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), "calc(") {
+		return layout.ParseCalc(s)
+	}
+	v, unit, ok := splitNumber(s)
+	switch {
+	case !ok:
+		return layout.Length{}, fmt.Errorf("expected a length, got %q", s)
+	case unit == "%":
+		return layout.Percent(v), nil
+	case unit == "":
+		if v != 0 {
+			return layout.Length{}, fmt.Errorf("missing unit in %q", s)
+		}
+		return layout.Px(0), nil
+	case strings.EqualFold(unit, "q"):
+		return layout.Q(v), nil
+	}
+	return layout.Length{Value: v, Unit: layout.LengthUnit(strings.ToLower(unit))}, nil
+}
+
+// parsePixels parses a length that must be known before layout, such as a
+// letter spacing, and returns it in pixels. Font-relative units are taken
+// of fontSize.
+func parsePixels(s string, fontSize float64) (float64, error) {
+	l, err := parseLength(s)
+	if err != nil {
+		return 0, err
+	}
+	if l.Unit == layout.PercentUnit {
+		return 0, fmt.Errorf("percentage not allowed in %q", s)
+	}
+	return layout.ResolveLength(l, nil, fontSize), nil
+}
+
+// borderWidthKeywords are the border-width keywords, in pixels.
+var borderWidthKeywords = map[string]float64{"thin": 1, "medium": 3, "thick": 5}
+
+// parseBorderWidth parses a border width: a length or thin/medium/thick.
+func parseBorderWidth(s string) (layout.Length, error) {
+	if px, ok := borderWidthKeywords[strings.ToLower(s)]; ok {
+		return layout.Px(px), nil
+	}
+	return parseLength(s)
+}
+
+// parseBorderShorthand extracts the width from a border shorthand such as
+// "1px solid red". Style and color don't affect layout and are ignored. A
+// border without a width is medium, and "none" is 0.
+func parseBorderShorthand(s string) (layout.Length, error) {
+	width := layout.Px(borderWidthKeywords["medium"])
+	for _, f := range splitFields(s) {
+		lower := strings.ToLower(f)
+		if lower == "none" || lower == "hidden" {
+			return layout.Px(0), nil
+		}
+		if l, err := parseBorderWidth(f); err == nil {
+			width = l
+		}
+	}
+	return width, nil
+}
+
+// splitFields splits s at whitespace outside parentheses, so that
+// "calc(1px + 2px) 3px" is two fields.
+func splitFields(s string) []string {
+	var fields []string
+	depth, start := 0, -1
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case isSpace(ch) && depth <= 0:
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, s[start:])
+	}
+	return fields
+}
+
+// parseFunction splits a function call such as "minmax(10px, 1fr)" into
+// its lowercase name and comma-separated arguments.
+func parseFunction(s string) (name string, args []string, ok bool) {
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", nil, false
+	}
+	for _, a := range splitTopLevel(s[open+1:len(s)-1], ',') {
+		args = append(args, strings.TrimSpace(a))
+	}
+	return strings.ToLower(strings.TrimSpace(s[:open])), args, true
+}
+
+// parseAngle parses an angle in deg, rad, grad or turn and returns it in
+// radians. A unitless 0 is allowed.
+func parseAngle(s string) (float64, error) {
+	v, unit, ok := splitNumber(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("expected an angle, got %q", s)
+	}
+	switch strings.ToLower(unit) {
+	case "deg":
+		return v * math.Pi / 180, nil
+	case "rad":
+		return v, nil
+	case "grad":
+		return v * math.Pi / 200, nil
+	case "turn":
+		return v * 2 * math.Pi, nil
+	case "":
+		if v == 0 {
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("expected an angle, got %q", s)
+}
+
+// parseTrack parses a single grid track size: a length, a percentage, an
+// fr value, auto, min-content, max-content, minmax() or fit-content().
+func parseTrack(s string) (layout.GridTrack, error) {
+	lower := strings.ToLower(s)
+	switch lower {
+	case "auto":
+		return layout.AutoTrack(), nil
+	case "min-content":
+		return layout.MinMaxTrack(layout.Px(layout.SizeMinContent), layout.Px(layout.SizeMinContent)), nil
+	case "max-content":
+		return layout.MinMaxTrack(layout.Px(layout.SizeMaxContent), layout.Px(layout.SizeMaxContent)), nil
+	}
+	if v, unit, ok := splitNumber(s); ok && strings.EqualFold(unit, "fr") {
+		if v < 0 {
+			return layout.GridTrack{}, fmt.Errorf("negative fr in %q", s)
+		}
+		return layout.FractionTrack(v), nil
+	}
+	if name, args, ok := parseFunction(s); ok {
+		switch {
+		case name == "minmax" && len(args) == 2:
+			min, err := parseTrack(args[0])
+			if err != nil {
+				return layout.GridTrack{}, err
+			}
+			max, err := parseTrack(args[1])
+			if err != nil {
+				return layout.GridTrack{}, err
+			}
+			if min.Fraction > 0 {
+				return layout.GridTrack{}, fmt.Errorf("fr not allowed as minmax() minimum in %q", s)
+			}
+			track := layout.MinMaxTrack(min.MinSize, max.MaxSize)
+			track.Fraction = max.Fraction
+			return track, nil
+		case name == "fit-content" && len(args) == 1:
+			l, err := parseLength(args[0])
+			if err != nil {
+				return layout.GridTrack{}, err
+			}
+			return layout.MinMaxTrack(layout.Px(0), l), nil
+		}
+		return layout.GridTrack{}, fmt.Errorf("unsupported track function %q", s)
+	}
+	l, err := parseLength(s)
+	if err != nil {
+		return layout.GridTrack{}, err
+	}
+	return layout.FixedTrack(l), nil
+}
+
+// parseTrackList parses a grid-template-rows/columns track list, expanding
+// repeat() with a fixed count.
+func parseTrackList(s string) ([]layout.GridTrack, error) {
+	var tracks []layout.GridTrack
+	for _, f := range splitFields(s) {
+		if name, args, ok := parseFunction(f); ok && name == "repeat" {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("expected repeat(count, tracks), got %q", f)
+			}
+			count, err := parseInteger(args[0])
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("unsupported repeat() count %q", args[0])
+			}
+			pattern, err := parseTrackList(args[1])
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, layout.RepeatTracks(count, pattern...)...)
+			continue
+		}
+		if strings.HasPrefix(f, "[") {
+			// Line names aren't supported; skip them
+			continue
+		}
+		track, err := parseTrack(f)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("expected a track list, got %q", s)
+	}
+	return tracks, nil
+}
+
+// parseGridLine parses a grid-row-start style line: auto, a 1-based line
+// number (negative counts from the end), or span n. Layout line numbers
+// are 0-based.
+func parseGridLine(s string) (int, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	switch {
+	case len(fields) == 1 && fields[0] == "auto":
+		return layout.GridLineAuto, nil
+	case len(fields) == 1 && fields[0] == "span":
+		return layout.Span(1), nil
+	case len(fields) == 2 && fields[0] == "span":
+		n, err := parseInteger(fields[1])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid span in %q", s)
+		}
+		return layout.Span(n), nil
+	case len(fields) == 1:
+		n, err := parseInteger(fields[0])
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid grid line %q", s)
+		}
+		if n > 0 {
+			return n - 1, nil
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid grid line %q", s)
+}
+
+// parseGridTemplateAreas parses a grid-template-areas value: one quoted
+// string per row, with one name (or . for an empty cell) per column. Each
+// name must form a rectangle.
+func parseGridTemplateAreas(s string) (*layout.GridTemplateAreas, error) {
+	var rows [][]string
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		quote := rest[0]
+		if quote != '"' && quote != '\'' {
+			return nil, fmt.Errorf("expected quoted rows, got %q", s)
+		}
+		end := strings.IndexByte(rest[1:], quote)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string in %q", s)
+		}
+		rows = append(rows, strings.Fields(rest[1:end+1]))
+		rest = strings.TrimSpace(rest[end+2:])
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("expected at least one row, got %q", s)
+	}
+	cols := len(rows[0])
+	type bounds struct{ rowStart, rowEnd, colStart, colEnd, cells int }
+	found := map[string]*bounds{}
+	var names []string
+	for r, row := range rows {
+		if len(row) != cols {
+			return nil, fmt.Errorf("rows have different numbers of columns in %q", s)
+		}
+		for c, name := range row {
+			if strings.Trim(name, ".") == "" {
+				continue
+			}
+			b, ok := found[name]
+			if !ok {
+				b = &bounds{rowStart: r, rowEnd: r + 1, colStart: c, colEnd: c + 1}
+				found[name] = b
+				names = append(names, name)
+			}
+			b.rowEnd = max(b.rowEnd, r+1)
+			b.colStart, b.colEnd = min(b.colStart, c), max(b.colEnd, c+1)
+			b.cells++
+		}
+	}
+	areas := layout.NewGridTemplateAreas(len(rows), cols)
+	for _, name := range names {
+		b := found[name]
+		if b.cells != (b.rowEnd-b.rowStart)*(b.colEnd-b.colStart) {
+			return nil, fmt.Errorf("area %q is not a rectangle", name)
+		}
+		if err := areas.DefineArea(name, b.rowStart, b.rowEnd, b.colStart, b.colEnd); err != nil {
+			return nil, err
+		}
+	}
+	return areas, nil
+}
+
+// parseTransform parses a transform list such as "translate(10px, 0)
+// rotate(45deg)" into a single matrix. Translations must be in px.
+func parseTransform(s string) (layout.Transform, error) {
+	if strings.EqualFold(strings.TrimSpace(s), "none") {
+		return layout.IdentityTransform(), nil
+	}
+	m := layout.IdentityTransform()
+	for _, f := range splitFields(s) {
+		name, args, ok := parseFunction(f)
+		if !ok {
+			return layout.Transform{}, fmt.Errorf("expected a transform function, got %q", f)
+		}
+		t, err := transformFunction(name, args)
+		if err != nil {
+			return layout.Transform{}, fmt.Errorf("%v in %q", err, f)
+		}
+		m = m.Multiply(t)
+	}
+	return m, nil
+}
+
+func transformFunction(name string, args []string) (layout.Transform, error) {
+	nums := func(parse func(string) (float64, error)) ([]float64, error) {
+		out := make([]float64, len(args))
+		for i, a := range args {
+			v, err := parse(a)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+	px := func(s string) (float64, error) {
+		l, err := parseLength(s)
+		if err != nil {
+			return 0, err
+		}
+		if l.Unit != layout.Pixels {
+			return 0, fmt.Errorf("only px translations are supported")
+		}
+		return l.Value, nil
+	}
+
+	switch name {
+	case "translate", "translatex", "translatey":
+		v, err := nums(px)
+		if err != nil || len(v) < 1 || len(v) > 2 || (name != "translate" && len(v) != 1) {
+			return layout.Transform{}, fmt.Errorf("invalid %s()", name)
+		}
+		switch {
+		case name == "translatex":
+			return layout.Translate(v[0], 0), nil
+		case name == "translatey":
+			return layout.Translate(0, v[0]), nil
+		case len(v) == 1:
+			return layout.Translate(v[0], 0), nil
+		}
+		return layout.Translate(v[0], v[1]), nil
+	case "scale", "scalex", "scaley":
+		v, err := nums(parseNumber)
+		if err != nil || len(v) < 1 || len(v) > 2 || (name != "scale" && len(v) != 1) {
+			return layout.Transform{}, fmt.Errorf("invalid %s()", name)
+		}
+		switch {
+		case name == "scalex":
+			return layout.Scale(v[0], 1), nil
+		case name == "scaley":
+			return layout.Scale(1, v[0]), nil
+		case len(v) == 1:
+			return layout.Scale(v[0], v[0]), nil
+		}
+		return layout.Scale(v[0], v[1]), nil
+	case "rotate", "skewx", "skewy":
+		v, err := nums(parseAngle)
+		if err != nil || len(v) != 1 {
+			return layout.Transform{}, fmt.Errorf("invalid %s()", name)
+		}
+		switch name {
+		case "rotate":
+			return layout.Rotate(v[0]), nil
+		case "skewx":
+			return layout.SkewX(v[0]), nil
+		}
+		return layout.SkewY(v[0]), nil
+	case "matrix":
+		v, err := nums(parseNumber)
+		if err != nil || len(v) != 6 {
+			return layout.Transform{}, fmt.Errorf("invalid matrix()")
+		}
+		return layout.Matrix(v[0], v[1], v[2], v[3], v[4], v[5]), nil
+	}
+	return layout.Transform{}, fmt.Errorf("unsupported transform function %s()", name)
+}
+
+// identPrefix returns the CSS identifier at the start of s, approximated
+// as [A-Za-z0-9_-] and non-ASCII characters.
+func identPrefix(s string) string {
+	i := 0
+	for i < len(s) {
+		ch := s[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' || ch >= 0x80 {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i]
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f'
+}
@@ -0,0 +1,306 @@
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Debug overlays.
+//
+// DebugOverlay describes a laid-out tree the way a browser's layout
+// inspector highlights it: each box's margin, border, padding and content
+// areas, the tracks of grid containers, the lines of flex containers and
+// the baselines of text. WriteDebugOverlaySVG draws it as an SVG image that
+// can be viewed alone or laid over a rendering of the tree.
+
+// OverlayKind is the kind of an OverlayShape.
+type OverlayKind int
+
+const (
+	// OverlayMargin, OverlayBorder and OverlayPadding are the areas
+	// between a box's edges: between Rect and Inner.
+	OverlayMargin OverlayKind = iota
+	OverlayBorder
+	OverlayPadding
+
+	// OverlayContent is a box's content area, Rect.
+	OverlayContent
+
+	// OverlayGridColumn and OverlayGridRow are a grid container's tracks,
+	// as wide or as tall as the grid, labeled with their size.
+	OverlayGridColumn
+	OverlayGridRow
+
+	// OverlayFlexLine is a flex container's line, as long as its content
+	// box.
+	OverlayFlexLine
+
+	// OverlayBaseline is a line of text's alphabetic baseline, or a box's
+	// Baseline: Rect is a line of zero height.
+	OverlayBaseline
+)
+
+// OverlayShape is a shape of a debug overlay. Rects are in the coordinates
+// of root.Rect.
+type OverlayShape struct {
+	Kind OverlayKind
+	Node *Node
+
+	// Rect is the shape's area, or its outer edge for the margin, border
+	// and padding areas.
+	Rect Rect
+
+	// Inner is the inner edge of the margin, border and padding areas.
+	Inner Rect
+
+	// Label describes the shape: the size of a grid track or of a content
+	// area, like "120px" or "120 × 40".
+	Label string
+}
+
+// OverlayOptions selects what a debug overlay shows.
+type OverlayOptions struct {
+	// Kinds are the kinds of shapes to include. Nil means all of them.
+	Kinds []OverlayKind
+}
+
+// includes reports whether shapes of kind k are included.
+func (o OverlayOptions) includes(k OverlayKind) bool {
+	if o.Kinds == nil {
+		return true
+	}
+	for _, kind := range o.Kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutTracks are the grid tracks or flex lines of a container's last
+// layout, relative to its Rect's origin.
+type layoutTracks struct {
+	columns, rows []Rect
+
+	// lines span the content box along the main axis, horizontally if
+	// linesHorizontal; their length is taken from the container's final
+	// size, which may be set after its layout
+	lines           []Rect
+	linesHorizontal bool
+
+	// stickyAreas are the grid areas of a grid's sticky items, by child
+	// index (see updateStickyIn)
+	stickyAreas map[int]Rect
+}
+
+// gridTracks returns the tracks of a grid from their offsets and sizes,
+// relative to its content box at (left, top).
+func gridTracks(columnOffsets, columnSizes, rowOffsets, rowSizes []float64, left, top float64) *layoutTracks {
+	columns, rows := len(columnSizes), len(rowSizes)
+	if columns == 0 || rows == 0 || len(columnOffsets) < columns || len(rowOffsets) < rows {
+		return nil
+	}
+	x0, x1 := left+columnOffsets[0], left+columnOffsets[columns-1]+columnSizes[columns-1]
+	y0, y1 := top+rowOffsets[0], top+rowOffsets[rows-1]+rowSizes[rows-1]
+	t := &layoutTracks{columns: make([]Rect, columns), rows: make([]Rect, rows)}
+	for i := range t.columns {
+		t.columns[i] = Rect{X: left + columnOffsets[i], Y: y0, Width: columnSizes[i], Height: y1 - y0}
+	}
+	for i := range t.rows {
+		t.rows[i] = Rect{X: x0, Y: top + rowOffsets[i], Width: x1 - x0, Height: rowSizes[i]}
+	}
+	return t
+}
+
+// flexLineTracks returns the lines of a flex container from their cross
+// offsets and sizes.
+func flexLineTracks(node *Node, setup *flexboxSetup, offsets, sizes []float64, ctx *LayoutContext, fontSize float64) *layoutTracks {
+	lines := len(sizes)
+	if lines == 0 || len(offsets) < lines {
+		return nil
+	}
+	left := ResolveLength(node.Style.Padding.Left, ctx, fontSize) + ResolveLength(node.Style.Border.Left, ctx, fontSize)
+	top := ResolveLength(node.Style.Padding.Top, ctx, fontSize) + ResolveLength(node.Style.Border.Top, ctx, fontSize)
+	t := &layoutTracks{lines: make([]Rect, lines), linesHorizontal: setup.isMainHorizontal}
+	for i := range t.lines {
+		if setup.isMainHorizontal {
+			t.lines[i] = Rect{X: left, Y: top + offsets[i], Height: sizes[i]}
+		} else {
+			t.lines[i] = Rect{X: left + offsets[i], Y: top, Width: sizes[i]}
+		}
+	}
+	return t
+}
+
+// DebugOverlay returns the shapes of root's tree, which must already be
+// laid out, in tree order: for each node, its margin, border, padding and
+// content areas, its grid tracks or flex lines, and its baselines. Nodes
+// with DisplayNone are skipped. Scroll positions are applied, and
+// transformed shapes are replaced by their bounding boxes, like
+// GetClipRect does. Margins are shown as specified, so auto margins are
+// shown as zero.
+//
+// Grid tracks aren't recorded for grids in vertical writing modes, and
+// baselines are only shown for horizontal text.
+//
+// Example:
+//
+//	layout.Layout(root, constraints, ctx)
+//	for _, shape := range layout.DebugOverlay(root, ctx, layout.OverlayOptions{}) {
+//		if shape.Kind == layout.OverlayGridColumn {
+//			canvas.StrokeRect(shape.Rect)
+//			canvas.DrawText(shape.Label, shape.Rect.X, shape.Rect.Y)
+//		}
+//	}
+func DebugOverlay(root *Node, ctx *LayoutContext, opts OverlayOptions) []OverlayShape {
+	if root == nil {
+		return nil
+	}
+	type entry struct {
+		node   *Node
+		toRoot Transform
+	}
+	var shapes []OverlayShape
+	add := func(shape OverlayShape) {
+		if opts.includes(shape.Kind) {
+			shapes = append(shapes, shape)
+		}
+	}
+	stack := []entry{{root, childToRoot(IdentityTransform(), nil, root)}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		if n.Style.Display == DisplayNone {
+			continue
+		}
+		rect := func(r Rect) Rect { return e.toRoot.ApplyToRect(r) }
+
+		// The box's edges, from the margin edge in
+		fontSize := getCurrentFontSize(n, ctx)
+		border := Rect{Width: n.Rect.Width, Height: n.Rect.Height}
+		margin := insetRect(border, n.Style.Margin, ctx, fontSize, -1)
+		padding := insetRect(border, n.Style.Border, ctx, fontSize, 1)
+		content := insetRect(padding, n.Style.Padding, ctx, fontSize, 1)
+		add(OverlayShape{Kind: OverlayMargin, Node: n, Rect: rect(margin), Inner: rect(border)})
+		add(OverlayShape{Kind: OverlayBorder, Node: n, Rect: rect(border), Inner: rect(padding)})
+		add(OverlayShape{Kind: OverlayPadding, Node: n, Rect: rect(padding), Inner: rect(content)})
+		add(OverlayShape{Kind: OverlayContent, Node: n, Rect: rect(content),
+			Label: overlayNumber(content.Width) + " × " + overlayNumber(content.Height)})
+
+		if t := n.tracks; t != nil {
+			for _, r := range t.columns {
+				add(OverlayShape{Kind: OverlayGridColumn, Node: n, Rect: rect(r), Label: overlayNumber(r.Width) + "px"})
+			}
+			for _, r := range t.rows {
+				add(OverlayShape{Kind: OverlayGridRow, Node: n, Rect: rect(r), Label: overlayNumber(r.Height) + "px"})
+			}
+			for _, r := range t.lines {
+				if t.linesHorizontal {
+					r.Width = content.Width
+				} else {
+					r.Height = content.Height
+				}
+				add(OverlayShape{Kind: OverlayFlexLine, Node: n, Rect: rect(r)})
+			}
+		}
+
+		switch tl := n.TextLayout; {
+		case tl != nil && !n.Style.WritingMode.IsVertical():
+			for _, line := range tl.Lines {
+				y := tl.ContentY + line.OffsetY + line.Baseline
+				add(OverlayShape{Kind: OverlayBaseline, Node: n, Rect: rect(Rect{X: tl.ContentX + line.OffsetX, Y: y, Width: line.Width})})
+			}
+		case tl == nil && n.Baseline != 0:
+			add(OverlayShape{Kind: OverlayBaseline, Node: n, Rect: rect(Rect{Y: n.Baseline, Width: n.Rect.Width})})
+		}
+
+		for i := len(n.Children) - 1; i >= 0; i-- {
+			child := n.Children[i]
+			stack = append(stack, entry{child, childToRoot(e.toRoot, n, child)})
+		}
+	}
+	return shapes
+}
+
+// insetRect returns r shrunk by the sides of s, or grown by them when
+// sign is -1. Auto and negative sides count as zero.
+func insetRect(r Rect, s Spacing, ctx *LayoutContext, fontSize float64, sign float64) Rect {
+	side := func(l Length) float64 {
+		return math.Max(0, ResolveLength(l, ctx, fontSize)) * sign
+	}
+	top, right, bottom, left := side(s.Top), side(s.Right), side(s.Bottom), side(s.Left)
+	return Rect{
+		X:      r.X + left,
+		Y:      r.Y + top,
+		Width:  math.Max(0, r.Width-left-right),
+		Height: math.Max(0, r.Height-top-bottom),
+	}
+}
+
+// overlayColors are the fills and strokes of the overlay's shapes, those
+// of browsers' layout inspectors.
+var overlayColors = map[OverlayKind]string{
+	OverlayMargin:     `fill="rgb(246, 178, 107)" fill-opacity="0.66"`,
+	OverlayBorder:     `fill="rgb(255, 229, 153)" fill-opacity="0.66"`,
+	OverlayPadding:    `fill="rgb(147, 196, 125)" fill-opacity="0.55"`,
+	OverlayContent:    `fill="rgb(111, 168, 220)" fill-opacity="0.66"`,
+	OverlayGridColumn: `fill="none" stroke="rgb(160, 80, 200)" stroke-dasharray="4 2"`,
+	OverlayGridRow:    `fill="none" stroke="rgb(160, 80, 200)" stroke-dasharray="4 2"`,
+	OverlayFlexLine:   `fill="none" stroke="rgb(200, 60, 160)" stroke-dasharray="2 2"`,
+	OverlayBaseline:   `stroke="rgb(220, 40, 40)"`,
+}
+
+// WriteDebugOverlaySVG draws the debug overlay of root's tree (see
+// DebugOverlay) to w as an SVG image the size of root's margin box, with
+// browser inspector colors: margins orange, borders yellow, padding green,
+// content blue, grid tracks and flex lines dashed, and baselines red. Grid
+// tracks are labeled with their size.
+func WriteDebugOverlaySVG(w io.Writer, root *Node, ctx *LayoutContext, opts OverlayOptions) error {
+	shapes := DebugOverlay(root, ctx, opts)
+	var bounds Rect
+	if root != nil {
+		bounds = root.Rect
+		fontSize := getCurrentFontSize(root, ctx)
+		bounds = insetRect(bounds, root.Style.Margin, ctx, fontSize, -1)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		overlayNumber(bounds.Width), overlayNumber(bounds.Height),
+		overlayNumber(bounds.X), overlayNumber(bounds.Y), overlayNumber(bounds.Width), overlayNumber(bounds.Height))
+	for _, s := range shapes {
+		r := s.Rect
+		switch s.Kind {
+		case OverlayMargin, OverlayBorder, OverlayPadding:
+			// The area between the edges
+			fmt.Fprintf(bw, `<path d="%s %s" fill-rule="evenodd" %s/>`+"\n", overlayPath(r), overlayPath(s.Inner), overlayColors[s.Kind])
+		case OverlayBaseline:
+			fmt.Fprintf(bw, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s/>`+"\n",
+				overlayNumber(r.X), overlayNumber(r.Y), overlayNumber(r.X+r.Width), overlayNumber(r.Y), overlayColors[s.Kind])
+		default:
+			fmt.Fprintf(bw, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+				overlayNumber(r.X), overlayNumber(r.Y), overlayNumber(r.Width), overlayNumber(r.Height), overlayColors[s.Kind])
+		}
+		if s.Kind == OverlayGridColumn || s.Kind == OverlayGridRow {
+			fmt.Fprintf(bw, `<text x="%s" y="%s" font-size="10" fill="rgb(160, 80, 200)">%s</text>`+"\n",
+				overlayNumber(r.X+2), overlayNumber(r.Y+10), s.Label)
+		}
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// overlayPath returns a closed SVG path around r.
+func overlayPath(r Rect) string {
+	return fmt.Sprintf("M%s %sh%sv%sh%sz", overlayNumber(r.X), overlayNumber(r.Y),
+		overlayNumber(r.Width), overlayNumber(r.Height), overlayNumber(-r.Width))
+}
+
+// overlayNumber formats v rounded to hundredths, without trailing zeros.
+func overlayNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
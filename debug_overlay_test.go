@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+// overlayShapes returns the shapes of kind k for node.
+func overlayShapes(shapes []OverlayShape, node *Node, k OverlayKind) []OverlayShape {
+	var out []OverlayShape
+	for _, s := range shapes {
+		if s.Node == node && s.Kind == k {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestDebugOverlayBoxModel(t *testing.T) {
+	box := &Node{Style: Style{
+		Width:   Px(50),
+		Height:  Px(20),
+		Margin:  Uniform(Px(5)),
+		Border:  Uniform(Px(2)),
+		Padding: Uniform(Px(3)),
+	}}
+	root := VStack(box)
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	shapes := DebugOverlay(root, ctx, OverlayOptions{})
+	margin := overlayShapes(shapes, box, OverlayMargin)
+	if len(margin) != 1 || margin[0].Rect != (Rect{X: 0, Y: 0, Width: 70, Height: 40}) || margin[0].Inner != (Rect{X: 5, Y: 5, Width: 60, Height: 30}) {
+		t.Errorf("Expected the margin area from (0, 0) to the border box, got %+v", margin)
+	}
+	border := overlayShapes(shapes, box, OverlayBorder)
+	if len(border) != 1 || border[0].Inner != (Rect{X: 7, Y: 7, Width: 56, Height: 26}) {
+		t.Errorf("Expected the border's inner edge at (7, 7), got %+v", border)
+	}
+	content := overlayShapes(shapes, box, OverlayContent)
+	if len(content) != 1 || content[0].Rect != (Rect{X: 10, Y: 10, Width: 50, Height: 20}) || content[0].Label != "50 × 20" {
+		t.Errorf("Expected a 50 × 20 content box at (10, 10), got %+v", content)
+	}
+
+	only := DebugOverlay(root, ctx, OverlayOptions{Kinds: []OverlayKind{OverlayContent}})
+	if len(only) != 2 {
+		t.Errorf("Expected the content boxes of the root and the box, got %d shapes", len(only))
+	}
+}
+
+func TestDebugOverlayGridTracks(t *testing.T) {
+	grid := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			Padding:             Uniform(Px(10)),
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(60)), FixedTrack(Px(40))},
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(30))},
+			GridColumnGap:       Px(5),
+		},
+		Children: []*Node{{}, {}},
+	}
+	ctx := NewLayoutContext(400, 400, 16)
+	Layout(grid, Loose(400, 400), ctx)
+
+	shapes := DebugOverlay(grid, ctx, OverlayOptions{})
+	columns := overlayShapes(shapes, grid, OverlayGridColumn)
+	if len(columns) != 2 {
+		t.Fatalf("Expected 2 column tracks, got %d", len(columns))
+	}
+	if columns[1].Rect != (Rect{X: 75, Y: 10, Width: 40, Height: 30}) || columns[1].Label != "40px" {
+		t.Errorf("Expected the second column at x 75, got %+v", columns[1])
+	}
+	rows := overlayShapes(shapes, grid, OverlayGridRow)
+	if len(rows) != 1 || rows[0].Rect != (Rect{X: 10, Y: 10, Width: 105, Height: 30}) {
+		t.Errorf("Expected a row across the grid, got %+v", rows)
+	}
+
+	// Tracks come back from the layout cache, and go once the node isn't
+	// a grid
+	cache := NewLayoutCache()
+	Layout(grid, Loose(400, 400), ctx.WithCache(cache))
+	grid.tracks = nil
+	Layout(grid, Loose(400, 400), ctx.WithCache(cache))
+	if cache.Stats().Hits == 0 {
+		t.Fatalf("Expected a cache hit")
+	}
+	if got := overlayShapes(DebugOverlay(grid, ctx, OverlayOptions{}), grid, OverlayGridColumn); len(got) != 2 {
+		t.Errorf("Expected cached layouts to keep the tracks, got %d", len(got))
+	}
+	grid.Style.Display = DisplayBlock
+	Layout(grid, Loose(400, 400), ctx)
+	if got := overlayShapes(DebugOverlay(grid, ctx, OverlayOptions{}), grid, OverlayGridColumn); len(got) != 0 {
+		t.Errorf("Expected no tracks for a block, got %d", len(got))
+	}
+}
+
+func TestDebugOverlayFlexLinesAndBaselines(t *testing.T) {
+	row := &Node{
+		Style:    Style{Display: DisplayFlex, FlexWrap: FlexWrapWrap, Width: Px(120), Height: Px(40)},
+		Children: []*Node{Fixed(60, 20), Fixed(60, 30), Fixed(60, 10)},
+	}
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(row, Loose(200, Unbounded), ctx)
+
+	shapes := DebugOverlay(row, ctx, OverlayOptions{})
+	lines := overlayShapes(shapes, row, OverlayFlexLine)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 flex lines, got %d", len(lines))
+	}
+	if lines[0].Rect != (Rect{Width: 120, Height: 30}) || lines[1].Rect != (Rect{Y: 30, Width: 120, Height: 10}) {
+		t.Errorf("Expected lines 30 and 10 tall, got %+v and %+v", lines[0].Rect, lines[1].Rect)
+	}
+
+	text := Text("hello", Style{TextStyle: &TextStyle{FontSize: 10, LineHeight: 20}})
+	root := VStack(Fixed(10, 10), text)
+	Layout(root, Loose(200, 200), ctx)
+	baselines := overlayShapes(DebugOverlay(root, ctx, OverlayOptions{}), text, OverlayBaseline)
+	line := text.TextLayout.Lines[0]
+	if len(baselines) != 1 || baselines[0].Rect.Y != text.Rect.Y+line.Baseline || baselines[0].Rect.Height != 0 {
+		t.Errorf("Expected the text's baseline at %v, got %+v", text.Rect.Y+line.Baseline, baselines)
+	}
+}
+
+func TestWriteDebugOverlaySVG(t *testing.T) {
+	box := Fixed(50, 20)
+	box.Style.Margin = Uniform(Px(5))
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(box, Loose(200, 200), ctx)
+
+	var b strings.Builder
+	if err := WriteDebugOverlaySVG(&b, box, ctx, OverlayOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	svg := b.String()
+	for _, want := range []string{
+		`width="60" height="30" viewBox="-5 -5 60 30"`,
+		`<path d="M-5 -5h60v30h-60z M0 0h50v20h-50z" fill-rule="evenodd" fill="rgb(246, 178, 107)"`,
+		`<rect x="0" y="0" width="50" height="20" fill="rgb(111, 168, 220)"`,
+		"</svg>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected %q in:\n%s", want, svg)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package layout
+
+import "fmt"
+
+// DefaultMaxDepth is the deepest nesting Layout lays out when
+// LayoutContext.MaxDepth is 0. Real documents are far shallower; browsers
+// stop nesting elements at a few hundred levels while parsing.
+const DefaultMaxDepth = 4096
+
+// DepthError reports a node nested deeper than the maximum depth (see
+// LayoutContext.MaxDepth).
+type DepthError struct {
+	// Path is the sequence of child indexes from the root to the node;
+	// its length is the node's depth.
+	Path []int
+
+	// MaxDepth is the maximum that was exceeded.
+	MaxDepth int
+}
+
+// Error implements the error interface.
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("layout: node nested %d deep, deeper than the maximum of %d; flatten the tree or raise LayoutContext.MaxDepth", len(e.Path), e.MaxDepth)
+}
+
+// CheckDepth reports the first node of root's tree, in tree order, that is
+// nested deeper than maxDepth (0 for DefaultMaxDepth, negative for no
+// limit), or nil if there's none. Layout doesn't lay out such nodes, and
+// LayoutResilient replaces them with placeholders.
+//
+// Example:
+//
+//	if err := layout.CheckDepth(root, ctx.MaxDepth); err != nil {
+//	    return err
+//	}
+func CheckDepth(root *Node, maxDepth int) error {
+	maxDepth = resolveMaxDepth(maxDepth)
+	var err error
+	walkTree(root, func(n *Node, path []int) walkStep {
+		if maxDepth >= 0 && len(path) > maxDepth {
+			err = &DepthError{Path: append([]int(nil), path...), MaxDepth: maxDepth}
+			return walkStop
+		}
+		return walkChildren
+	})
+	return err
+}
+
+// resolveMaxDepth returns the maximum depth that maxDepth stands for: -1
+// for no limit.
+func resolveMaxDepth(maxDepth int) int {
+	switch {
+	case maxDepth == 0:
+		return DefaultMaxDepth
+	case maxDepth < 0:
+		return -1
+	}
+	return maxDepth
+}
+
+// maxDepth returns ctx's maximum depth, or -1 for no limit.
+func (ctx *LayoutContext) maxDepth() int {
+	if ctx == nil {
+		return DefaultMaxDepth
+	}
+	return resolveMaxDepth(ctx.MaxDepth)
+}
+
+// prepareTree readies root's tree for layout in a single walk: it resolves
+// logical sides (see resolveLogicalTree) and detaches the children of the
+// nodes at maxDepth (-1 for no limit), so layout's recursion stays within
+// it. It returns a function that undoes both, and a DepthError for the
+// first detached child, or nil.
+func prepareTree(root *Node, maxDepth int) (restore func(), err *DepthError) {
+	var logical logicalSides
+	var cut []*Node
+	var children [][]*Node
+	walkTree(root, func(n *Node, path []int) walkStep {
+		logical.resolve(n, len(path))
+		if maxDepth < 0 || len(path) < maxDepth {
+			return walkChildren
+		}
+		if len(n.Children) > 0 {
+			if err == nil {
+				err = &DepthError{Path: append(append([]int{}, path...), 0), MaxDepth: maxDepth}
+			}
+			cut = append(cut, n)
+			children = append(children, n.Children)
+			n.Children = nil
+		}
+		return walkSkip
+	})
+	return func() {
+		for i, n := range cut {
+			n.Children = children[i]
+		}
+		logical.restore()
+	}, err
+}
+
+// walkStep tells walkTree how to go on after visiting a node.
+type walkStep int
+
+const (
+	walkChildren walkStep = iota // Visit the node's children
+	walkSkip                     // Skip the node's children
+	walkStop                     // Stop walking
+)
+
+// walkTree visits n and its descendants in tree order (depth-first,
+// parents before children) with an explicit stack, so arbitrarily deep
+// trees don't overflow the goroutine stack. path holds the child indexes
+// from n to the visited node; it's reused, so visit must copy it to keep
+// it.
+func walkTree(n *Node, visit func(node *Node, path []int) walkStep) {
+	if n == nil {
+		return
+	}
+	switch visit(n, nil) {
+	case walkSkip, walkStop:
+		return
+	}
+	type frame struct {
+		node *Node
+		next int
+	}
+	stack := []frame{{node: n}}
+	var path []int
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == len(top.node.Children) {
+			stack = stack[:len(stack)-1]
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			continue
+		}
+		i := top.next
+		top.next++
+		child := top.node.Children[i]
+		path = append(path, i)
+		switch visit(child, path) {
+		case walkChildren:
+			stack = append(stack, frame{node: child})
+		case walkSkip:
+			path = path[:len(path)-1]
+		case walkStop:
+			return
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package layout
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// deepChain returns a chain of depth+1 auto-height block nodes, and its
+// innermost node, which is 10px tall.
+func deepChain(depth int) (root, leaf *Node) {
+	root = &Node{Style: Style{Display: DisplayBlock, Height: Px(-1)}}
+	leaf = root
+	for i := 0; i < depth; i++ {
+		child := &Node{Style: Style{Display: DisplayBlock, Height: Px(-1)}}
+		leaf.Children = []*Node{child}
+		leaf = child
+	}
+	leaf.Style.Height = Px(10)
+	return root, leaf
+}
+
+func TestTraversalsHandleDeepTrees(t *testing.T) {
+	root, leaf := deepChain(100000)
+	leaf.ID = "leaf"
+
+	if n := len(root.Descendants()); n != 100000 {
+		t.Errorf("Expected 100000 descendants, got %d", n)
+	}
+	if found := root.Find(func(n *Node) bool { return n.ID == "leaf" }); found != leaf {
+		t.Error("Expected Find to reach the innermost node")
+	}
+	if !root.All(func(n *Node) bool { return n.Style.Display == DisplayBlock }) {
+		t.Error("Expected All to hold for every node")
+	}
+	maxDepth := root.FoldWithContext(0, func(acc interface{}, n *Node, depth int) interface{} {
+		if depth > acc.(int) {
+			return depth
+		}
+		return acc
+	}).(int)
+	if maxDepth != 100000 {
+		t.Errorf("Expected depth 100000, got %d", maxDepth)
+	}
+
+	clone := root.CloneDeep()
+	if got := clone.Find(func(n *Node) bool { return n.ID == "leaf" }); got == nil || got == leaf {
+		t.Error("Expected CloneDeep to copy the innermost node")
+	}
+	widened := root.Transform(func(n *Node) bool { return n.ID == "leaf" }, func(n *Node) *Node { return n.WithWidth(20) })
+	if got := widened.Find(func(n *Node) bool { return n.ID == "leaf" }); got == nil || got.Style.Width.Value != 20 {
+		t.Error("Expected Transform to reach the innermost node")
+	}
+	filtered := root.FilterDeep(func(n *Node) bool { return n.ID != "leaf" })
+	if n := len(filtered.Descendants()); n != 99999 {
+		t.Errorf("Expected 99999 descendants after FilterDeep, got %d", n)
+	}
+}
+
+func TestWalkTreeOrderAndPaths(t *testing.T) {
+	a, b := Fixed(10, 10), Fixed(10, 10)
+	inner := VStack(a, b)
+	c := Fixed(10, 10)
+	root := VStack(inner, c)
+
+	var nodes []*Node
+	var paths [][]int
+	walkTree(root, func(n *Node, path []int) walkStep {
+		nodes = append(nodes, n)
+		paths = append(paths, append([]int(nil), path...))
+		return walkChildren
+	})
+	want := []*Node{root, inner, a, b, c}
+	wantPaths := [][]int{nil, {0}, {0, 0}, {0, 1}, {1}}
+	if len(nodes) != len(want) {
+		t.Fatalf("Expected %d nodes, got %d", len(want), len(nodes))
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Errorf("Expected node %d in tree order", i)
+		}
+		if len(paths[i]) != len(wantPaths[i]) {
+			t.Errorf("Expected path %v, got %v", wantPaths[i], paths[i])
+			continue
+		}
+		for j := range paths[i] {
+			if paths[i][j] != wantPaths[i][j] {
+				t.Errorf("Expected path %v, got %v", wantPaths[i], paths[i])
+			}
+		}
+	}
+}
+
+func TestCheckDepth(t *testing.T) {
+	root, _ := deepChain(10)
+
+	if err := CheckDepth(root, 10); err != nil {
+		t.Errorf("Expected no error at the limit, got %v", err)
+	}
+	if err := CheckDepth(root, -1); err != nil {
+		t.Errorf("Expected no error without a limit, got %v", err)
+	}
+	err := CheckDepth(root, 5)
+	var depthErr *DepthError
+	if !errors.As(err, &depthErr) {
+		t.Fatalf("Expected a DepthError, got %v", err)
+	}
+	if len(depthErr.Path) != 6 || depthErr.MaxDepth != 5 {
+		t.Errorf("Expected a path of 6 past a maximum of 5, got %v past %d", depthErr.Path, depthErr.MaxDepth)
+	}
+}
+
+func TestLayoutStopsAtMaxDepth(t *testing.T) {
+	root, leaf := deepChain(10)
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.MaxDepth = 5
+
+	size := Layout(root, Loose(400, 300), ctx)
+
+	if size.Height != 0 {
+		t.Errorf("Expected height 0 without the deep leaf, got %.2f", size.Height)
+	}
+	if leaf.Rect != (Rect{}) {
+		t.Errorf("Expected the deep leaf not to be laid out, got %+v", leaf.Rect)
+	}
+	if n := len(root.Descendants()); n != 10 {
+		t.Errorf("Expected children to be restored, got %d descendants", n)
+	}
+
+	ctx.MaxDepth = 0
+	size = Layout(root, Loose(400, 300), ctx)
+	if size.Height != 10 {
+		t.Errorf("Expected height 10 under the default maximum, got %.2f", size.Height)
+	}
+}
+
+func TestLayoutWithContextReportsDepth(t *testing.T) {
+	root, _ := deepChain(10)
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.MaxDepth = 5
+
+	size, err := LayoutWithContext(context.Background(), root, Loose(400, 300), ctx)
+	var depthErr *DepthError
+	if !errors.As(err, &depthErr) {
+		t.Fatalf("Expected a DepthError, got %v", err)
+	}
+	if len(depthErr.Path) != 6 || depthErr.MaxDepth != 5 {
+		t.Errorf("Expected a path of 6 past a maximum of 5, got %v past %d", depthErr.Path, depthErr.MaxDepth)
+	}
+	if size != Layout(root, Loose(400, 300), ctx) {
+		t.Errorf("Expected the size Layout returns, got %+v", size)
+	}
+
+	ctx.MaxDepth = 10
+	if _, err := LayoutWithContext(context.Background(), root, Loose(400, 300), ctx); err != nil {
+		t.Errorf("Expected no error at the limit, got %v", err)
+	}
+}
+
+func TestLayoutHandlesDeepBlockTrees(t *testing.T) {
+	root, leaf := deepChain(20000)
+	ctx := NewLayoutContext(400, 300, 16)
+
+	Layout(root, Loose(400, 300), ctx)
+	if leaf.Rect.Height != 0 {
+		t.Errorf("Expected the leaf past DefaultMaxDepth not to be laid out, got %+v", leaf.Rect)
+	}
+
+	ctx.MaxDepth = -1
+	size := Layout(root, Loose(400, 300), ctx)
+	if size.Height != 10 {
+		t.Errorf("Expected height 10 without a limit, got %.2f", size.Height)
+	}
+}
+
+func TestLayoutResilientReportsDepth(t *testing.T) {
+	root, _ := deepChain(10)
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.MaxDepth = 3
+
+	result := LayoutResilient(root, Loose(400, 300), ctx,
+		ResilientOptions{PlaceholderWidth: 20, PlaceholderHeight: 10})
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d", len(result.Errors))
+	}
+	err := result.Errors[0]
+	var depthErr *DepthError
+	if !errors.As(err, &depthErr) {
+		t.Fatalf("Expected a DepthError, got %v", err)
+	}
+	if len(err.Path) != 3 || len(depthErr.Path) != 4 {
+		t.Errorf("Expected the node at depth 3 to be replaced, got path %v", err.Path)
+	}
+	if err.Node.Rect.Height != 10 {
+		t.Errorf("Expected a 10px placeholder, got %+v", err.Node.Rect)
+	}
+	if result.Size.Height != 10 {
+		t.Errorf("Expected height 10, got %.2f", result.Size.Height)
+	}
+}
@@ -0,0 +1,157 @@
+package layout
+
+import "strconv"
+
+// RectChange is a node whose box differs between two layouts (see
+// DiffRects). From and To are its border boxes before and after, in the
+// coordinates of the respective roots' Rects, ignoring transforms and
+// scroll positions. A node only in the later tree has a nil Before and a
+// zero From; one only in the earlier tree has a nil After and a zero To.
+type RectChange struct {
+	Before, After *Node
+	From, To      Rect
+}
+
+// Added reports whether the node is only in the later tree.
+func (c RectChange) Added() bool { return c.Before == nil }
+
+// Removed reports whether the node is only in the earlier tree.
+func (c RectChange) Removed() bool { return c.After == nil }
+
+// Invert returns the transform that draws the node's new box where its old
+// one was, in the coordinates Style.Transform applies in: the "invert"
+// step of a FLIP animation. Animating it to the identity, for example with
+// InterpolateTransform, slides the node into its new place. It returns the
+// identity for added and removed nodes, and doesn't scale along an axis
+// the new box has no extent in.
+//
+// Example:
+//
+//	for _, c := range layout.DiffRects(before, root) {
+//	    if !c.Added() && !c.Removed() {
+//	        c.After.Style.Transform = c.Invert()
+//	    }
+//	}
+func (c RectChange) Invert() Transform {
+	if c.Before == nil || c.After == nil {
+		return IdentityTransform()
+	}
+	sx, sy := 1.0, 1.0
+	if c.To.Width != 0 {
+		sx = c.From.Width / c.To.Width
+	}
+	if c.To.Height != 0 {
+		sy = c.From.Height / c.To.Height
+	}
+	// The parent's offset from the root is the same for both boxes in the
+	// node's own coordinates, so move the old box into them
+	dx, dy := c.After.Rect.X-c.To.X, c.After.Rect.Y-c.To.Y
+	return Translate(c.From.X+dx, c.From.Y+dy).
+		Multiply(Scale(sx, sy)).
+		Multiply(Translate(-c.After.Rect.X, -c.After.Rect.Y))
+}
+
+// DiffRects compares the boxes of two laid-out trees, typically a
+// CloneDeep of a tree taken before a change and the tree after it, and
+// returns the nodes whose box moved or resized, appeared or disappeared.
+// Changes to nodes in after come first, in tree order, followed by the
+// removed nodes in before's tree order.
+//
+// Nodes are paired up by identity when the same *Node is in both trees,
+// then by ID when both have the same one, and then, among the nodes
+// without an ID, by their position in the tree (the path of child indexes
+// from the root), so a CloneDeep pairs up with its original.
+//
+// Example:
+//
+//	before := root.CloneDeep()
+//	items.Children = items.Children[1:]
+//	layout.Layout(root, constraints, ctx)
+//	for _, c := range layout.DiffRects(before, root) {
+//	    highlight(c.To)
+//	}
+func DiffRects(before, after *Node) []RectChange {
+	var old, cur []diffEntry
+	collectDiffEntries(before, nil, Point{}, &old)
+	collectDiffEntries(after, nil, Point{}, &cur)
+
+	byNode := make(map[*Node]int, len(old))
+	byID := make(map[string]int)
+	byPath := make(map[string]int, len(old))
+	for i, e := range old {
+		byNode[e.node] = i
+		if e.node.ID != "" {
+			if _, ok := byID[e.node.ID]; !ok {
+				byID[e.node.ID] = i
+			}
+		} else {
+			byPath[e.path] = i
+		}
+	}
+
+	// Pair by identity and ID first, so a node keeps its partner even if
+	// another one took its place in the tree
+	pair := make([]int, len(cur))
+	taken := make([]bool, len(old))
+	for i, e := range cur {
+		pair[i] = -1
+		j, ok := byNode[e.node]
+		if !ok && e.node.ID != "" {
+			j, ok = byID[e.node.ID]
+		}
+		if ok && !taken[j] {
+			pair[i], taken[j] = j, true
+		}
+	}
+	for i, e := range cur {
+		if pair[i] >= 0 || e.node.ID != "" {
+			continue
+		}
+		if j, ok := byPath[e.path]; ok && !taken[j] {
+			pair[i], taken[j] = j, true
+		}
+	}
+
+	var changes []RectChange
+	for i, e := range cur {
+		if pair[i] < 0 {
+			changes = append(changes, RectChange{After: e.node, To: e.rect})
+			continue
+		}
+		o := old[pair[i]]
+		if o.rect != e.rect {
+			changes = append(changes, RectChange{Before: o.node, After: e.node, From: o.rect, To: e.rect})
+		}
+	}
+	for j, o := range old {
+		if !taken[j] {
+			changes = append(changes, RectChange{Before: o.node, From: o.rect})
+		}
+	}
+	return changes
+}
+
+// diffEntry is a node of a tree compared by DiffRects, with its box in
+// the root's coordinates and its path from the root ("/0/2/1").
+type diffEntry struct {
+	node *Node
+	rect Rect
+	path string
+}
+
+// collectDiffEntries appends n and its descendants to entries in tree
+// order, given the position of n's parent in the root's coordinates.
+func collectDiffEntries(n *Node, path []byte, origin Point, entries *[]diffEntry) {
+	if n == nil {
+		return
+	}
+	rect := n.Rect
+	rect.X += origin.X
+	rect.Y += origin.Y
+	*entries = append(*entries, diffEntry{node: n, rect: rect, path: string(path)})
+	for i, child := range n.Children {
+		childPath := append(path[:len(path):len(path)], '/')
+		childPath = strconv.AppendInt(childPath, int64(i), 10)
+		collectDiffEntries(child, childPath, Point{X: rect.X, Y: rect.Y}, entries)
+	}
+}
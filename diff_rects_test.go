@@ -0,0 +1,69 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiffRects(t *testing.T) {
+	item := func(id string, height float64) *Node {
+		return &Node{ID: id, Style: Style{Width: Px(100), Height: Px(height)}}
+	}
+	a, b, c := item("a", 10), item("", 20), item("", 30)
+	root := &Node{Style: Style{Width: Px(200), Height: Px(-1)}, Children: []*Node{a, b, c}}
+	ctx := NewLayoutContext(800, 600, 16)
+	Layout(root, Loose(800, 600), ctx)
+	before := root.CloneDeep()
+
+	// Remove a, so b and c move up and the root shrinks. Nodes without an ID
+	// pair up by position: nothing without one was in a's place, c takes
+	// the clone's b and a new node the clone's c
+	root.Children = []*Node{b, c, item("", 5)}
+	Layout(root, Loose(800, 600), ctx)
+	changes := DiffRects(before, root)
+
+	if len(changes) != 5 {
+		t.Fatalf("Expected 5 changes, got %+v", changes)
+	}
+	if changes[0].After != root || changes[0].From.Height != 60 || changes[0].To.Height != 55 {
+		t.Errorf("Expected the root to shrink from 60 to 55, got %+v", changes[0])
+	}
+	if changes[1].After != b || !changes[1].Added() {
+		t.Errorf("Expected b to look added, got %+v", changes[1])
+	}
+	if changes[2].After != c || changes[2].From.Y != 10 || changes[2].To.Y != 20 {
+		t.Errorf("Expected c to be paired with the clone's b, got %+v", changes[2])
+	}
+	if changes[3].From.Y != 30 || changes[3].To.Y != 50 || changes[3].To.Height != 5 {
+		t.Errorf("Expected the new node to be paired with the clone's c, got %+v", changes[3])
+	}
+	if !changes[4].Removed() || changes[4].Before.ID != "a" {
+		t.Errorf("Expected a to be removed, got %+v", changes[4])
+	}
+
+	// Shared nodes pair by identity, and unchanged ones aren't listed
+	before = root.CloneDeep()
+	before.Children = []*Node{c, b, root.Children[2]}
+	if changes := DiffRects(before, root); len(changes) != 0 {
+		t.Errorf("Expected no changes between nodes paired by identity, got %+v", changes)
+	}
+}
+
+func TestRectChangeInvert(t *testing.T) {
+	node := &Node{Rect: Rect{X: 10, Y: 20, Width: 50, Height: 40}}
+	change := RectChange{
+		Before: &Node{},
+		After:  node,
+		From:   Rect{X: 100, Y: 100, Width: 100, Height: 20},
+		To:     Rect{X: 110, Y: 120, Width: 50, Height: 40}, // Parent at (100, 100)
+	}
+	// The inverted box, in the root's coordinates, is the old one
+	got := Translate(100, 100).Multiply(change.Invert()).ApplyToRect(node.Rect)
+	if math.Abs(got.X-100) > 1e-9 || math.Abs(got.Y-100) > 1e-9 ||
+		math.Abs(got.Width-100) > 1e-9 || math.Abs(got.Height-20) > 1e-9 {
+		t.Errorf("Expected the old box, got %+v", got)
+	}
+	if !(RectChange{After: node}).Invert().IsIdentity() {
+		t.Error("Expected the identity for an added node")
+	}
+}
@@ -0,0 +1,288 @@
+package layout
+
+import (
+	"sort"
+	"strings"
+)
+
+// Display lists.
+//
+// RecordDisplayList turns a laid-out tree into the commands a renderer
+// runs to paint it, so renderers don't each walk the tree, apply scroll
+// positions, clip scroll containers and sort boxes by z-index. Commands
+// are in painting order, and PushClip and PushTransform apply until their
+// matching Pop, like a canvas's save and restore. WalkPaint runs them on a
+// Painter.
+//
+// Stacking follows CSS 2.1 Appendix E, simplified: a box that is
+// positioned (any Position but PositionStatic) or transformed is painted
+// as a layer, with its descendants, after the boxes in normal flow around
+// it, and layers are ordered by ZIndex, negative ones before normal flow.
+// Layers with the same ZIndex are painted in tree order.
+//
+// See: https://www.w3.org/TR/CSS21/zindex.html
+
+// PaintOp is the kind of a PaintCommand.
+type PaintOp int
+
+const (
+	// PaintPushClip clips later commands to Rect, until the matching
+	// PaintPop.
+	PaintPushClip PaintOp = iota
+
+	// PaintPushTransform applies Transform to later commands, after the
+	// transforms already pushed, until the matching PaintPop.
+	PaintPushTransform
+
+	// PaintDrawRect paints a node's box: its background fills Rect, its
+	// border lies between Rect and Inner.
+	PaintDrawRect
+
+	// PaintDrawTextRun paints a word of a node's text.
+	PaintDrawTextRun
+
+	// PaintPop undoes the last PaintPushClip or PaintPushTransform that
+	// hasn't been undone.
+	PaintPop
+
+	// PaintDrawImage paints the image of a node whose Tag is "img", from
+	// its "src" attribute, in Rect, the node's content box.
+	PaintDrawImage
+)
+
+// PaintCommand is a command of a display list. Rects and positions are in
+// the coordinates of the root's parent (those of root.Rect), mapped by the
+// transforms pushed before the command.
+type PaintCommand struct {
+	Op PaintOp
+
+	// Node is the node the command paints, clips or transforms. It's nil
+	// for PaintPop.
+	Node *Node
+
+	// Rect is the node's border box for PaintDrawRect, the clip for
+	// PaintPushClip (the node's padding box, see OverflowClip), the
+	// word's box for PaintDrawTextRun (its advance by the line's height)
+	// and the node's content box for PaintDrawImage.
+	Rect Rect
+
+	// Inner is the inner edge of the border, for PaintDrawRect.
+	Inner Rect
+
+	// Transform is the transform of PaintPushTransform.
+	Transform Transform
+
+	// Text, Baseline and TextStyle describe the word of PaintDrawTextRun:
+	// its text in display order (see InlineBox.VisualText), the y of its
+	// baseline and its line's computed style.
+	Text      string
+	Baseline  float64
+	TextStyle TextStyle
+
+	// Source is the image's "src" attribute, for PaintDrawImage.
+	Source string
+}
+
+// RecordDisplayList returns the commands that paint root's tree, which
+// must already be laid out, in painting order. Nodes with DisplayNone are
+// skipped, and so are the boxes and text of nodes hidden by Visibility,
+// though their visible descendants are painted. The contents of scroll
+// containers are clipped to their padding box and moved by their Scroll.
+// Only horizontal text is recorded. Nodes with Tag "img" and a "src"
+// attribute paint their image after their box.
+//
+// Example:
+//
+//	for _, cmd := range layout.RecordDisplayList(root, ctx) {
+//		switch cmd.Op {
+//		case layout.PaintPushClip:
+//			canvas.Save()
+//			canvas.ClipRect(cmd.Rect)
+//		case layout.PaintPushTransform:
+//			canvas.Save()
+//			canvas.Concat(cmd.Transform)
+//		case layout.PaintDrawRect:
+//			canvas.FillRect(cmd.Rect, background(cmd.Node))
+//		case layout.PaintDrawTextRun:
+//			canvas.DrawText(cmd.Text, cmd.Rect.X, cmd.Baseline, cmd.TextStyle)
+//		case layout.PaintPop:
+//			canvas.Restore()
+//		}
+//	}
+func RecordDisplayList(root *Node, ctx *LayoutContext) []PaintCommand {
+	if root == nil || root.Style.Display == DisplayNone {
+		return nil
+	}
+	r := displayRecorder{ctx: ctx}
+	r.paintLayer(paintLayerItem{node: root, parentVisible: true})
+	return r.commands
+}
+
+// paintLayerItem is a box painted as a layer, with what it inherits from
+// the boxes around it.
+type paintLayerItem struct {
+	node *Node
+
+	// x and y are where the origin of the node's parent's content (its
+	// Rect's origin, moved by its Scroll) is in the enclosing layer's
+	// coordinates
+	x, y float64
+
+	// clips are the PaintPushClip commands of the scroll containers
+	// between the enclosing layer and the node, in the enclosing layer's
+	// coordinates
+	clips []PaintCommand
+
+	parentVisible bool
+}
+
+// displayRecorder records the commands of a display list.
+type displayRecorder struct {
+	ctx      *LayoutContext
+	commands []PaintCommand
+}
+
+// isPaintLayer reports whether n is painted as a layer rather than in
+// normal flow.
+func isPaintLayer(n *Node) bool {
+	return n.Style.Position != PositionStatic || !nodeTransform(n).IsIdentity()
+}
+
+// paintLayer records a layer: the node's box, the layers behind its normal
+// flow, its normal flow, then the other layers.
+func (r *displayRecorder) paintLayer(item paintLayerItem) {
+	n := item.node
+	r.commands = append(r.commands, item.clips...)
+	pushes := len(item.clips)
+	x, y := item.x+n.Rect.X, item.y+n.Rect.Y
+	if t := nodeTransform(n); !t.IsIdentity() {
+		r.commands = append(r.commands, PaintCommand{Op: PaintPushTransform, Node: n, Transform: Translate(item.x, item.y).Multiply(t)})
+		x, y = n.Rect.X, n.Rect.Y
+		pushes++
+	}
+
+	visible := n.Style.Visibility.Visible(item.parentVisible)
+	r.paintBox(n, x, y, visible)
+
+	// Normal flow is recorded first, to find the layers in it, and added
+	// after those behind it
+	var layers []paintLayerItem
+	commands := r.commands
+	r.commands = nil
+	r.paintFlow(n, x, y, visible, nil, &layers)
+	flow := r.commands
+	r.commands = commands
+
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layerZIndex(layers[i].node) < layerZIndex(layers[j].node)
+	})
+	i := 0
+	for ; i < len(layers) && layerZIndex(layers[i].node) < 0; i++ {
+		r.paintLayer(layers[i])
+	}
+	r.commands = append(r.commands, flow...)
+	for ; i < len(layers); i++ {
+		r.paintLayer(layers[i])
+	}
+
+	for range pushes {
+		r.commands = append(r.commands, PaintCommand{Op: PaintPop})
+	}
+}
+
+// layerZIndex returns the z-index n is stacked at: its ZIndex if it's
+// positioned, and 0 otherwise.
+func layerZIndex(n *Node) int {
+	if n.Style.Position == PositionStatic {
+		return 0
+	}
+	return n.Style.ZIndex
+}
+
+// paintFlow records the normal flow descendants of n, which is at (x, y),
+// and adds the layers among them to layers. clips are the scroll
+// containers' clips around n.
+func (r *displayRecorder) paintFlow(n *Node, x, y float64, visible bool, clips []PaintCommand, layers *[]paintLayerItem) {
+	clip, clipped := n.OverflowClip(r.ctx)
+	if clipped {
+		clip.X += x
+		clip.Y += y
+		push := PaintCommand{Op: PaintPushClip, Node: n, Rect: clip}
+		r.commands = append(r.commands, push)
+		clips = append(clips[:len(clips):len(clips)], push)
+	}
+
+	x, y = x-n.Scroll.X, y-n.Scroll.Y
+	for _, child := range n.Children {
+		if child.Style.Display == DisplayNone {
+			continue
+		}
+		if isPaintLayer(child) {
+			*layers = append(*layers, paintLayerItem{node: child, x: x, y: y, clips: clips, parentVisible: visible})
+			continue
+		}
+		childVisible := child.Style.Visibility.Visible(visible)
+		cx, cy := x+child.Rect.X, y+child.Rect.Y
+		r.paintBox(child, cx, cy, childVisible)
+		r.paintFlow(child, cx, cy, childVisible, clips, layers)
+	}
+
+	if clipped {
+		r.commands = append(r.commands, PaintCommand{Op: PaintPop})
+	}
+}
+
+// paintBox records n's box, image and text, with n at (x, y), if it's
+// visible.
+func (r *displayRecorder) paintBox(n *Node, x, y float64, visible bool) {
+	if !visible {
+		return
+	}
+	fontSize := getCurrentFontSize(n, r.ctx)
+	left := ResolveLength(n.Style.Border.Left, r.ctx, fontSize)
+	top := ResolveLength(n.Style.Border.Top, r.ctx, fontSize)
+	right := ResolveLength(n.Style.Border.Right, r.ctx, fontSize)
+	bottom := ResolveLength(n.Style.Border.Bottom, r.ctx, fontSize)
+	r.commands = append(r.commands, PaintCommand{
+		Op:    PaintDrawRect,
+		Node:  n,
+		Rect:  Rect{X: x, Y: y, Width: n.Rect.Width, Height: n.Rect.Height},
+		Inner: Rect{X: x + left, Y: y + top, Width: n.Rect.Width - left - right, Height: n.Rect.Height - top - bottom},
+	})
+
+	if src := n.Attributes["src"]; src != "" && strings.EqualFold(n.Tag, "img") {
+		content := contentBoxOf(n, r.ctx)
+		content.X += x
+		content.Y += y
+		r.commands = append(r.commands, PaintCommand{Op: PaintDrawImage, Node: n, Rect: content, Source: src})
+	}
+
+	tl := n.TextLayout
+	if tl == nil || n.Style.WritingMode.IsVertical() {
+		return
+	}
+	for i, line := range tl.Lines {
+		style := tl.Style
+		if i == 0 && tl.FirstLineStyle != nil {
+			style = *tl.FirstLineStyle
+		}
+		// Boxes are words, separated by the line's spaces
+		space := line.SpaceAdjustment
+		if line.SpaceCount > 0 {
+			space += line.SpaceWidth / float64(line.SpaceCount)
+		}
+		wx := x + tl.ContentX + line.OffsetX
+		top := y + tl.ContentY + line.OffsetY
+		for _, box := range line.Boxes {
+			r.commands = append(r.commands, PaintCommand{
+				Op:        PaintDrawTextRun,
+				Node:      n,
+				Rect:      Rect{X: wx, Y: top, Width: box.Width, Height: line.Height},
+				Text:      box.VisualText(),
+				Baseline:  top + line.Baseline,
+				TextStyle: style,
+			})
+			wx += box.Width + space
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package layout
+
+import (
+	"slices"
+	"testing"
+)
+
+// paintedNodes returns the nodes of a display list's PaintDrawRect
+// commands, in order.
+func paintedNodes(commands []PaintCommand) []*Node {
+	var nodes []*Node
+	for _, cmd := range commands {
+		if cmd.Op == PaintDrawRect {
+			nodes = append(nodes, cmd.Node)
+		}
+	}
+	return nodes
+}
+
+func TestRecordDisplayListOrder(t *testing.T) {
+	a := Fixed(10, 10)
+	behind := Fixed(10, 10)
+	behind.Style.Position = PositionRelative
+	behind.Style.ZIndex = -1
+	front := Fixed(10, 10)
+	front.Style.Position = PositionRelative
+	front.Style.ZIndex = 2
+	positioned := Fixed(10, 10)
+	positioned.Style.Position = PositionRelative
+	b := Fixed(10, 10)
+	root := VStack(front, a, positioned, behind, b)
+	ctx := NewLayoutContext(100, 100, 16)
+	Layout(root, Loose(100, 100), ctx)
+
+	got := paintedNodes(RecordDisplayList(root, ctx))
+	want := []*Node{root, behind, a, b, positioned, front}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected root, negative layer, normal flow, z-index 0 and positive layers, got %v", got)
+	}
+}
+
+func TestRecordDisplayListRects(t *testing.T) {
+	inner := Fixed(20, 10)
+	box := &Node{
+		Style:    Style{Width: Px(50), Height: Px(50), Border: Uniform(Px(2)), Padding: Uniform(Px(3))},
+		Children: []*Node{inner},
+	}
+	root := VStack(Fixed(100, 30), box)
+	root.Style.Padding = Uniform(Px(1))
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	commands := RecordDisplayList(root, ctx)
+	if len(commands) != 4 {
+		t.Fatalf("Expected 4 commands, got %d", len(commands))
+	}
+	if c := commands[2]; c.Node != box || c.Rect != (Rect{X: 1, Y: 31, Width: 60, Height: 60}) || c.Inner != (Rect{X: 3, Y: 33, Width: 56, Height: 56}) {
+		t.Errorf("Expected the box at (1, 31) with a 2px border, got %+v", c)
+	}
+	if c := commands[3]; c.Node != inner || c.Rect.X != 6 || c.Rect.Y != 36 {
+		t.Errorf("Expected the inner box at (6, 36), got %+v", c.Rect)
+	}
+}
+
+func TestRecordDisplayListScrollAndTransform(t *testing.T) {
+	content := Fixed(100, 200)
+	scroller := &Node{
+		Style:    Style{Width: Px(100), Height: Px(50), Overflow: OverflowHidden},
+		Children: []*Node{content},
+	}
+	scroller.Scroll = Point{Y: 30}
+	moved := Fixed(10, 10)
+	moved.Style.Transform = Scale(2, 2)
+	content.Children = []*Node{moved}
+	root := VStack(Fixed(100, 20), scroller)
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	var ops []PaintOp
+	commands := RecordDisplayList(root, ctx)
+	for _, cmd := range commands {
+		ops = append(ops, cmd.Op)
+	}
+	want := []PaintOp{
+		PaintDrawRect,      // root
+		PaintDrawRect,      // header
+		PaintDrawRect,      // scroller
+		PaintPushClip,      // scroller's content
+		PaintDrawRect,      // content
+		PaintPop,           // scroller's content
+		PaintPushClip,      // the layer is still clipped
+		PaintPushTransform, // the layer's transform
+		PaintDrawRect,      // moved
+		PaintPop,
+		PaintPop,
+	}
+	if !slices.Equal(ops, want) {
+		t.Fatalf("Expected %v, got %v", want, ops)
+	}
+	if c := commands[3]; c.Rect != (Rect{X: 0, Y: 20, Width: 100, Height: 50}) {
+		t.Errorf("Expected the clip at the scroller's padding box, got %+v", c.Rect)
+	}
+	if c := commands[4]; c.Rect.Y != -10 {
+		t.Errorf("Expected the content scrolled up by 30, got %+v", c.Rect)
+	}
+	if c := commands[7]; c.Transform != Translate(0, -10).Multiply(Scale(2, 2)) {
+		t.Errorf("Expected the transform in the content's coordinates, got %+v", c.Transform)
+	}
+	if c := commands[8]; c.Rect != (Rect{Width: 10, Height: 10}) {
+		t.Errorf("Expected the transformed box at its own origin, got %+v", c.Rect)
+	}
+}
+
+func TestRecordDisplayListVisibilityAndText(t *testing.T) {
+	text := Text("hello world", Style{Width: Px(200), TextStyle: &TextStyle{FontSize: 10, LineHeight: 20}})
+	shown := Fixed(10, 10)
+	shown.Style.Visibility = VisibilityVisible
+	hidden := VStack(shown)
+	hidden.Style.Visibility = VisibilityHidden
+	gone := Fixed(10, 10)
+	gone.Style.Display = DisplayNone
+	root := VStack(text, hidden, gone)
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	commands := RecordDisplayList(root, ctx)
+	if got := paintedNodes(commands); !slices.Equal(got, []*Node{root, text, shown}) {
+		t.Errorf("Expected hidden and undisplayed boxes left out, got %v", got)
+	}
+	var words []string
+	for _, cmd := range commands {
+		if cmd.Op == PaintDrawTextRun {
+			words = append(words, cmd.Text)
+			if cmd.Baseline <= cmd.Rect.Y || cmd.Baseline >= cmd.Rect.Y+20 || cmd.TextStyle.FontSize != 10 {
+				t.Errorf("Expected the word's baseline inside its line, got %+v", cmd)
+			}
+		}
+	}
+	if !slices.Equal(words, []string{"hello", "world"}) {
+		t.Errorf("Expected a run per word, got %q", words)
+	}
+
+	if RecordDisplayList(nil, ctx) != nil {
+		t.Errorf("Expected no commands for a nil root")
+	}
+}
+
+func TestRecordDisplayListImage(t *testing.T) {
+	img := Fixed(40, 30)
+	img.Tag = "img"
+	img.Attributes = map[string]string{"src": "logo.png"}
+	img.Style.Padding = Uniform(Px(5))
+	noSource := Fixed(10, 10)
+	noSource.Tag = "img"
+	root := VStack(Fixed(100, 20), img, noSource)
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	commands := RecordDisplayList(root, ctx)
+	if len(commands) != 5 {
+		t.Fatalf("Expected 4 boxes and an image, got %d commands", len(commands))
+	}
+	c := commands[3]
+	if c.Op != PaintDrawImage || c.Node != img || c.Source != "logo.png" || c.Rect != (Rect{X: 5, Y: 25, Width: 40, Height: 30}) {
+		t.Errorf("Expected the image in the content box at (5, 25), got %+v", c)
+	}
+}
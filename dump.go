@@ -0,0 +1,392 @@
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DumpOptions controls what DumpTreeWithOptions prints.
+type DumpOptions struct {
+	// Properties are the style properties to print, by Style field name
+	// ("Width", "Padding", "FlexGrow"), plus "GridRow" and "GridColumn"
+	// for the line placements. Nil means all of them; an empty list
+	// prints rects only. Properties at their default are never printed.
+	Properties []string
+
+	// Absolute prints rects in root's coordinates (those of root.Rect)
+	// instead of relative to the parent. Scroll positions and transforms
+	// aren't applied.
+	Absolute bool
+
+	// MaxTextLength is the number of characters of text printed before it
+	// is cut off with "…". Zero means 40; negative means no limit.
+	MaxTextLength int
+}
+
+// DumpTree prints root's tree to w, a node per line, indented under its
+// parent: the node's display type, ID, classes and text, its rect as
+// layout computed it, and its style properties that aren't at their
+// default. See DumpTreeWithOptions to choose the properties.
+//
+// Example output:
+//
+//	flex#toolbar [0, 0, 416×48] Width=400px Padding=8px JustifyContent=space-between
+//	├── block.logo [8, 8, 32×32] Width=32px Height=32px
+//	└── text "Settings" [331.2, 8, 76.8×32]
+func DumpTree(root *Node, w io.Writer) error {
+	return DumpTreeWithOptions(root, w, DumpOptions{})
+}
+
+// DumpTreeWithOptions is DumpTree with options to filter properties and
+// choose coordinates.
+func DumpTreeWithOptions(root *Node, w io.Writer, opts DumpOptions) error {
+	bw := bufio.NewWriter(w)
+	if root == nil {
+		bw.WriteString("<nil>\n")
+		return bw.Flush()
+	}
+	type entry struct {
+		node         *Node
+		x, y         float64 // Parent's origin, for Absolute
+		prefix, lead string  // Indentation of the node's children, and its own connector
+	}
+	stack := []entry{{node: root}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		rect := n.Rect
+		if opts.Absolute {
+			rect.X += e.x
+			rect.Y += e.y
+		}
+		bw.WriteString(e.lead)
+		bw.WriteString(dumpNode(n, rect, &opts))
+		bw.WriteByte('\n')
+
+		for i := len(n.Children) - 1; i >= 0; i-- {
+			lead, prefix := e.prefix+"├── ", e.prefix+"│   "
+			if i == len(n.Children)-1 {
+				lead, prefix = e.prefix+"└── ", e.prefix+"    "
+			}
+			stack = append(stack, entry{node: n.Children[i], x: rect.X, y: rect.Y, prefix: prefix, lead: lead})
+		}
+	}
+	return bw.Flush()
+}
+
+// dumpNode returns the line DumpTree prints for n.
+func dumpNode(n *Node, rect Rect, opts *DumpOptions) string {
+	var b strings.Builder
+	b.WriteString(nodeLabel(n))
+	if n.Text != "" {
+		b.WriteByte(' ')
+		b.WriteString(strconv.Quote(dumpText(n.Text, opts.MaxTextLength)))
+	}
+	fmt.Fprintf(&b, " [%s, %s, %s×%s]", dumpNumber(rect.X), dumpNumber(rect.Y), dumpNumber(rect.Width), dumpNumber(rect.Height))
+
+	for _, p := range dumpProperties {
+		if opts.Properties != nil && !slices.Contains(opts.Properties, p.name) {
+			continue
+		}
+		if value := p.value(&n.Style); value != "" {
+			b.WriteString(" " + p.name + "=" + value)
+		}
+	}
+	return b.String()
+}
+
+// nodeLabel names n by its display type ("text" for text nodes), tag, ID
+// and classes, like "flex<nav>#menu.dark".
+func nodeLabel(n *Node) string {
+	name := dumpDisplays[n.Style.Display]
+	if n.Text != "" && n.Style.Display != DisplayNone {
+		name = "text"
+	}
+	if n.Tag != "" {
+		name += "<" + n.Tag + ">"
+	}
+	if n.ID != "" {
+		name += "#" + n.ID
+	}
+	for _, class := range n.Classes {
+		name += "." + class
+	}
+	return name
+}
+
+// dumpText returns text cut off after max characters (40 if zero, no
+// limit if negative).
+func dumpText(text string, max int) string {
+	if max == 0 {
+		max = 40
+	}
+	if max < 0 || utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	return string([]rune(text)[:max]) + "…"
+}
+
+// dumpProperties are the style properties DumpTree prints, in order. Their
+// values are empty at the default.
+var dumpProperties = []struct {
+	name  string
+	value func(s *Style) string
+}{
+	{"Width", func(s *Style) string { return dumpSize(s.Width) }},
+	{"Height", func(s *Style) string { return dumpSize(s.Height) }},
+	{"MinWidth", func(s *Style) string { return dumpLength(s.MinWidth) }},
+	{"MinHeight", func(s *Style) string { return dumpLength(s.MinHeight) }},
+	{"MaxWidth", func(s *Style) string { return dumpLength(s.MaxWidth) }},
+	{"MaxHeight", func(s *Style) string { return dumpLength(s.MaxHeight) }},
+	{"AspectRatio", func(s *Style) string { return dumpFloat(s.AspectRatio) }},
+	{"BoxSizing", func(s *Style) string { return dumpKeyword(s.BoxSizing, BoxSizingBorderBox, "border-box") }},
+	{"Margin", func(s *Style) string { return dumpSpacing(s.Margin) }},
+	{"Border", func(s *Style) string { return dumpSpacing(s.Border) }},
+	{"Padding", func(s *Style) string { return dumpSpacing(s.Padding) }},
+	{"FlexDirection", func(s *Style) string { return dumpFlexDirections[s.FlexDirection] }},
+	{"FlexWrap", func(s *Style) string { return dumpFlexWraps[s.FlexWrap] }},
+	{"JustifyContent", func(s *Style) string { return dumpJustifyContents[s.JustifyContent] }},
+	{"AlignItems", func(s *Style) string { return dumpAlignItems[s.AlignItems] }},
+	{"AlignContent", func(s *Style) string { return dumpAlignContents[s.AlignContent] }},
+	{"AlignSelf", func(s *Style) string { return dumpAlignItems[s.AlignSelf] }},
+	{"JustifyItems", func(s *Style) string { return dumpJustifyItems[s.JustifyItems] }},
+	{"JustifySelf", func(s *Style) string { return dumpJustifyItems[s.JustifySelf] }},
+	{"FlexGrow", func(s *Style) string { return dumpFloat(s.FlexGrow) }},
+	{"FlexShrink", func(s *Style) string { return dumpFloat(s.FlexShrink) }},
+	{"FlexBasis", func(s *Style) string { return dumpBasis(s.FlexBasis) }},
+	{"FlexGap", func(s *Style) string { return dumpLength(s.FlexGap) }},
+	{"Order", func(s *Style) string { return dumpInt(s.Order) }},
+	{"GridTemplateColumns", func(s *Style) string { return dumpTracks(s.GridTemplateColumns) }},
+	{"GridTemplateRows", func(s *Style) string { return dumpTracks(s.GridTemplateRows) }},
+	{"GridGap", func(s *Style) string { return dumpLength(s.GridGap) }},
+	{"GridRow", func(s *Style) string { return dumpGridLines(s.GridRowStart, s.GridRowEnd) }},
+	{"GridColumn", func(s *Style) string { return dumpGridLines(s.GridColumnStart, s.GridColumnEnd) }},
+	{"GridArea", func(s *Style) string { return s.GridArea }},
+	{"Position", func(s *Style) string { return dumpPositions[s.Position] }},
+	{"Top", func(s *Style) string { return dumpLength(s.Top) }},
+	{"Right", func(s *Style) string { return dumpLength(s.Right) }},
+	{"Bottom", func(s *Style) string { return dumpLength(s.Bottom) }},
+	{"Left", func(s *Style) string { return dumpLength(s.Left) }},
+	{"ZIndex", func(s *Style) string { return dumpInt(s.ZIndex) }},
+	{"Overflow", func(s *Style) string { return dumpOverflows[s.Overflow] }},
+	{"Visibility", func(s *Style) string { return dumpVisibilities[s.Visibility] }},
+	{"Transform", func(s *Style) string {
+		if s.Transform == (Transform{}) {
+			return ""
+		}
+		return s.Transform.ToSVGString()
+	}},
+}
+
+// dumpLength formats a length, or returns "" for the zero Length.
+func dumpLength(l Length) string {
+	switch {
+	case l == (Length{}):
+		return ""
+	case l.Unit == "" || l.Unit == Pixels:
+		if l.Value >= Unbounded {
+			return "none"
+		}
+		return dumpNumber(l.Value) + "px"
+	case l.Unit == AutoUnit:
+		return "auto"
+	case l.Unit == UnboundedUnit:
+		return "none"
+	case strings.HasPrefix(string(l.Unit), "calc("):
+		return string(l.Unit)
+	}
+	return dumpNumber(l.Value) + string(l.Unit)
+}
+
+// dumpSize formats a Width or Height, with the sentinel values by name.
+func dumpSize(l Length) string {
+	if l.Unit == "" || l.Unit == Pixels {
+		switch {
+		case l.Value == SizeMinContent:
+			return "min-content"
+		case l.Value == SizeMaxContent:
+			return "max-content"
+		case l.Value == SizeFitContent:
+			return "fit-content"
+		case l.Value < 0:
+			return "" // Auto
+		}
+	}
+	return dumpLength(l)
+}
+
+// dumpSpacing formats the sides of a Spacing like the CSS shorthand, or
+// returns "" if they're all zero.
+func dumpSpacing(s Spacing) string {
+	sides := [4]string{dumpLength(s.Top), dumpLength(s.Right), dumpLength(s.Bottom), dumpLength(s.Left)}
+	for i, side := range sides {
+		if side == "" || side == "0px" {
+			sides[i] = "0"
+		}
+	}
+	switch {
+	case sides == [4]string{"0", "0", "0", "0"}:
+		return ""
+	case sides[0] == sides[1] && sides[1] == sides[2] && sides[2] == sides[3]:
+		return sides[0]
+	case sides[0] == sides[2] && sides[1] == sides[3]:
+		return sides[0] + "," + sides[1]
+	}
+	return strings.Join(sides[:], ",")
+}
+
+// dumpBasis formats a flex basis, or returns "" for auto.
+func dumpBasis(b FlexBasis) string {
+	switch b.Kind {
+	case FlexBasisContent:
+		return "content"
+	case FlexBasisLength:
+		if l := dumpLength(b.Length); l != "" {
+			return l
+		}
+		return "0px"
+	}
+	return ""
+}
+
+// dumpTracks formats a grid template, or returns "" for none.
+func dumpTracks(tracks []GridTrack) string {
+	parts := make([]string, len(tracks))
+	for i, t := range tracks {
+		switch {
+		case t.Fraction > 0:
+			parts[i] = dumpNumber(t.Fraction) + "fr"
+		case t.MinSize == t.MaxSize:
+			parts[i] = dumpTrackBreadth(t.MaxSize)
+		case t == AutoTrack():
+			parts[i] = "auto"
+		default:
+			parts[i] = "minmax(" + dumpTrackBreadth(t.MinSize) + "," + dumpTrackBreadth(t.MaxSize) + ")"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// dumpTrackBreadth formats a track's minimum or maximum size.
+func dumpTrackBreadth(l Length) string {
+	if l.Unit == "" || l.Unit == Pixels {
+		switch l.Value {
+		case SizeMinContent:
+			return "min-content"
+		case SizeMaxContent:
+			return "max-content"
+		}
+	}
+	if l.Unit == UnboundedUnit || isPixelLength(l) && l.Value >= Unbounded {
+		return "auto"
+	}
+	if l == (Length{}) {
+		return "0px"
+	}
+	return dumpLength(l)
+}
+
+// dumpGridLines formats a line placement with layout's 0-based lines, or
+// returns "" if it's auto.
+func dumpGridLines(start, end int) string {
+	if start == 0 && end == 0 || start == GridLineAuto && end == GridLineAuto {
+		return ""
+	}
+	return dumpGridLine(start) + "/" + dumpGridLine(end)
+}
+
+// dumpGridLine formats one line of a placement.
+func dumpGridLine(line int) string {
+	switch {
+	case line == GridLineAuto:
+		return "auto"
+	case line > gridSpanBase && line <= gridSpanBase+gridSpanMax:
+		return "span" + strconv.Itoa(line-gridSpanBase)
+	}
+	return strconv.Itoa(line)
+}
+
+// dumpKeyword returns name if v is value, and "" otherwise.
+func dumpKeyword[T comparable](v, value T, name string) string {
+	if v == value {
+		return name
+	}
+	return ""
+}
+
+// dumpFloat formats v, or returns "" for zero.
+func dumpFloat(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return dumpNumber(v)
+}
+
+// dumpInt formats v, or returns "" for zero.
+func dumpInt(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// dumpNumber formats v with at most two decimals and no trailing zeros.
+func dumpNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Names of the values DumpTree prints. Defaults are missing, so they're
+// left out.
+var (
+	dumpDisplays = map[Display]string{
+		DisplayBlock: "block", DisplayFlex: "flex", DisplayGrid: "grid",
+		DisplayInlineText: "text", DisplayNone: "none",
+	}
+	dumpFlexDirections = map[FlexDirection]string{
+		FlexDirectionRowReverse: "row-reverse", FlexDirectionColumn: "column",
+		FlexDirectionColumnReverse: "column-reverse",
+	}
+	dumpFlexWraps = map[FlexWrap]string{
+		FlexWrapWrap: "wrap", FlexWrapWrapReverse: "wrap-reverse",
+	}
+	dumpJustifyContents = map[JustifyContent]string{
+		JustifyContentFlexEnd: "flex-end", JustifyContentCenter: "center",
+		JustifyContentSpaceBetween: "space-between", JustifyContentSpaceAround: "space-around",
+		JustifyContentSpaceEvenly: "space-evenly",
+	}
+	dumpAlignItems = map[AlignItems]string{
+		AlignItemsFlexStart: "flex-start", AlignItemsFlexEnd: "flex-end",
+		AlignItemsCenter: "center", AlignItemsBaseline: "baseline",
+		AlignItemsStretchExplicit: "stretch",
+	}
+	dumpAlignContents = map[AlignContent]string{
+		AlignContentFlexStart: "flex-start", AlignContentFlexEnd: "flex-end",
+		AlignContentCenter: "center", AlignContentSpaceBetween: "space-between",
+		AlignContentSpaceAround: "space-around", AlignContentSpaceEvenly: "space-evenly",
+	}
+	dumpJustifyItems = map[JustifyItems]string{
+		JustifyItemsStart: "start", JustifyItemsEnd: "end", JustifyItemsCenter: "center",
+		JustifyItemsStretchExplicit: "stretch",
+	}
+	dumpPositions = map[Position]string{
+		PositionRelative: "relative", PositionAbsolute: "absolute",
+		PositionFixed: "fixed", PositionSticky: "sticky",
+	}
+	dumpOverflows = map[Overflow]string{
+		OverflowHidden: "hidden", OverflowScroll: "scroll", OverflowAuto: "auto",
+	}
+	dumpVisibilities = map[Visibility]string{
+		VisibilityVisible: "visible", VisibilityHidden: "hidden",
+	}
+)
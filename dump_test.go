@@ -0,0 +1,81 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDumpTree(t *testing.T) {
+	logo := Fixed(32, 32)
+	logo.Classes = []string{"logo"}
+	label := Text("Settings", Style{TextStyle: &TextStyle{FontSize: 16}})
+	bar := HStack(logo, label)
+	bar.ID = "toolbar"
+	bar.Style.Width = Px(400)
+	bar.Style.Height = Px(48)
+	bar.Style.Padding = Uniform(Px(8))
+	bar.Style.JustifyContent = JustifyContentSpaceBetween
+	Layout(bar, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	var b strings.Builder
+	if err := DumpTree(bar, &b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := `flex#toolbar [0, 0, 416×64] Width=400px Height=48px Padding=8px JustifyContent=space-between
+├── block.logo [8, 8, 32×32] Width=32px Height=32px
+└── text "Settings" [331.2, 8, 76.8×48]
+`
+	if got := b.String(); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestDumpTreeWithOptions(t *testing.T) {
+	cell := &Node{Style: Style{GridColumnStart: 1, GridColumnEnd: Span(2), Margin: Spacing{Top: Px(1), Right: Px(2), Bottom: Px(1), Left: Px(2)}}}
+	inner := Text("a very long text that goes on and on", Style{})
+	cell.Children = []*Node{inner}
+	grid := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			Padding:             Uniform(Px(10)),
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(50)), FractionTrack(1), AutoTrack(), MinMaxTrack(Px(10), Percent(50))},
+		},
+		Children: []*Node{{Style: Style{Display: DisplayNone}}, cell},
+	}
+	Layout(grid, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	var b strings.Builder
+	DumpTreeWithOptions(grid, &b, DumpOptions{})
+	for _, want := range []string{
+		"grid [0, 0, ",
+		"GridTemplateColumns=50px,1fr,auto,minmax(10px,50%)",
+		"├── none [",
+		"└── block [",
+		"Margin=1px,2px GridColumn=1/span2",
+		`    └── text "a very long text that goes on and on"`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	DumpTreeWithOptions(grid, &b, DumpOptions{Properties: []string{"Padding"}, Absolute: true, MaxTextLength: 6})
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[0], "] Padding=10px") || strings.Contains(lines[2], "=") {
+		t.Errorf("Expected only padding printed, got:\n%s", b.String())
+	}
+	absolute := inner.Rect
+	absolute.X += cell.Rect.X
+	absolute.Y += cell.Rect.Y
+	want := `"a very…" [` + dumpNumber(absolute.X) + ", " + dumpNumber(absolute.Y) + ","
+	if !strings.Contains(lines[3], want) {
+		t.Errorf("Expected %q in %q", want, lines[3])
+	}
+
+	b.Reset()
+	DumpTree(nil, &b)
+	if b.String() != "<nil>\n" {
+		t.Errorf("Expected <nil>, got %q", b.String())
+	}
+}
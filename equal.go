@@ -0,0 +1,190 @@
+package layout
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Normalize rewrites s in its canonical form, replacing values that lay out
+// the same as another with that one, so equivalent styles compare and
+// serialize alike:
+//
+//   - px padding, border and margin lengths become unitless, which are
+//     pixels too (the zero Length is 0px, like Px(0))
+//   - a negative pixel Width or Height, which is auto, becomes Px(-1)
+//   - the identity Transform becomes the zero Transform
+//   - empty grid templates and container names become nil
+//   - a FirstLetter spanning 1 line or fewer spans 0, which means 1
+//
+// Other fields are left as they are: the zero Length isn't the same as
+// Px(0) for sizes, offsets and gaps, where it's auto or unset. A TextStyle,
+// FirstLine or FirstLetter that needs changes is copied rather than changed
+// in place, as styles may share them.
+func (s *Style) Normalize() {
+	for _, sp := range []*Spacing{&s.Padding, &s.Border, &s.Margin} {
+		for _, side := range []*Length{&sp.Top, &sp.Right, &sp.Bottom, &sp.Left} {
+			if side.Unit == Pixels {
+				side.Unit = ""
+			}
+		}
+	}
+	for _, size := range []*Length{&s.Width, &s.Height} {
+		if isPixelLength(*size) && size.Value < 0 {
+			*size = Px(-1)
+		}
+	}
+	if s.Transform.IsIdentity() {
+		s.Transform = Transform{}
+	}
+	if len(s.GridTemplateRows) == 0 {
+		s.GridTemplateRows = nil
+	}
+	if len(s.GridTemplateColumns) == 0 {
+		s.GridTemplateColumns = nil
+	}
+	if len(s.ContainerName) == 0 {
+		s.ContainerName = nil
+	}
+	if s.FirstLetter != nil && s.FirstLetter.Lines != 0 && s.FirstLetter.Lines <= 1 {
+		fl := *s.FirstLetter
+		fl.Lines = 0
+		s.FirstLetter = &fl
+	}
+}
+
+// Equal reports whether s and other are the same once normalized (see
+// Normalize). Text styles, grid areas and drop caps are compared by value,
+// not by pointer.
+//
+// Example:
+//
+//	a := layout.Style{Padding: layout.Uniform(layout.Px(0))}
+//	b := layout.Style{}
+//	a.Equal(b) // true
+func (s Style) Equal(other Style) bool {
+	s.Normalize()
+	other.Normalize()
+	return stylesEqual(&s, &other)
+}
+
+// stylesEqual reports whether two normalized styles are the same.
+func stylesEqual(a, b *Style) bool {
+	if !slices.Equal(a.GridTemplateRows, b.GridTemplateRows) ||
+		!slices.Equal(a.GridTemplateColumns, b.GridTemplateColumns) ||
+		!slices.Equal(a.ContainerName, b.ContainerName) ||
+		!pointeesEqual(a.TextStyle, b.TextStyle) ||
+		!pointeesEqual(a.FirstLine, b.FirstLine) ||
+		!pointeesEqual(a.FirstLetter, b.FirstLetter) {
+		return false
+	}
+	if (a.GridTemplateAreas == nil) != (b.GridTemplateAreas == nil) {
+		return false
+	}
+	if a.GridTemplateAreas != nil {
+		x, y := a.GridTemplateAreas, b.GridTemplateAreas
+		if x.Rows != y.Rows || x.Cols != y.Cols || !slices.Equal(x.Areas, y.Areas) {
+			return false
+		}
+	}
+
+	// The rest of the fields are comparable, and compared by DeepEqual
+	// with the others cleared
+	x, y := *a, *b
+	for _, s := range []*Style{&x, &y} {
+		s.GridTemplateRows, s.GridTemplateColumns, s.ContainerName = nil, nil, nil
+		s.GridTemplateAreas = nil
+		s.TextStyle, s.FirstLine, s.FirstLetter = nil, nil, nil
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// pointeesEqual reports whether a and b are both nil or point to equal
+// values.
+func pointeesEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Normalize normalizes the styles of n and its descendants (see
+// Style.Normalize), sorts their Classes and removes duplicates, and
+// replaces empty Classes and Attributes with nil. Golden tests can
+// normalize a tree before serializing it, so equivalent trees give the
+// same output.
+func (n *Node) Normalize() {
+	if n == nil {
+		return
+	}
+	walkTree(n, func(node *Node, path []int) walkStep {
+		node.Style.Normalize()
+		slices.Sort(node.Classes)
+		node.Classes = slices.Compact(node.Classes)
+		if len(node.Classes) == 0 {
+			node.Classes = nil
+		}
+		if len(node.Attributes) == 0 {
+			node.Attributes = nil
+		}
+		return walkChildren
+	})
+}
+
+// Equal reports whether the trees of n and other are the same: their nodes
+// have equal styles (see Style.Equal), rects, baselines, text, tags, IDs,
+// sets of classes and attributes, and children, in the same order. Runtime
+// state and data that aren't part of the tree's description or its layout
+// aren't compared: TextLayout, which follows from the rest, Scroll,
+// UserData, OnLayout callbacks and state variants. Nil is only equal to
+// nil.
+//
+// Example:
+//
+//	layout.Layout(root, constraints, ctx)
+//	if !root.Equal(golden) {
+//	    t.Error("layout changed")
+//	}
+func (n *Node) Equal(other *Node) bool {
+	type pair struct{ a, b *Node }
+	stack := []pair{{n, other}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if p.a == nil || p.b == nil {
+			if p.a != p.b {
+				return false
+			}
+			continue
+		}
+		if !nodesEqual(p.a, p.b) {
+			return false
+		}
+		for i := range p.a.Children {
+			stack = append(stack, pair{p.a.Children[i], p.b.Children[i]})
+		}
+	}
+	return true
+}
+
+// nodesEqual reports whether a and b are equal apart from their children's
+// contents.
+func nodesEqual(a, b *Node) bool {
+	if a.Rect != b.Rect || a.Baseline != b.Baseline || a.Text != b.Text ||
+		a.Tag != b.Tag || a.ID != b.ID || len(a.Children) != len(b.Children) ||
+		!maps.Equal(a.Attributes, b.Attributes) || !sameClasses(a.Classes, b.Classes) {
+		return false
+	}
+	return a.Style.Equal(b.Style)
+}
+
+// sameClasses reports whether a and b have the same classes, in any order.
+func sameClasses(a, b []string) bool {
+	if slices.Equal(a, b) {
+		return true
+	}
+	x, y := slices.Clone(a), slices.Clone(b)
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(slices.Compact(x), slices.Compact(y))
+}
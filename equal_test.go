@@ -0,0 +1,92 @@
+package layout
+
+import "testing"
+
+func TestStyleEqualTreatsEquivalentValuesAlike(t *testing.T) {
+	a := Style{
+		Padding:     Uniform(Px(8)),
+		Width:       Px(-5),
+		Transform:   IdentityTransform(),
+		TextStyle:   &TextStyle{FontSize: 14},
+		FirstLetter: &FirstLetter{Lines: 1},
+	}
+	b := Style{
+		Padding:             Spacing{Top: Length{Value: 8}, Right: Length{Value: 8}, Bottom: Length{Value: 8}, Left: Length{Value: 8}},
+		Width:               Px(-1),
+		GridTemplateColumns: []GridTrack{},
+		TextStyle:           &TextStyle{FontSize: 14},
+		FirstLetter:         &FirstLetter{},
+	}
+	if !a.Equal(b) {
+		t.Error("Expected equivalent styles to be equal")
+	}
+	if a.Padding.Top.Unit != Pixels || a.FirstLetter.Lines != 1 {
+		t.Error("Expected Equal not to change its arguments")
+	}
+
+	for name, c := range map[string]Style{
+		"text style":     {Padding: Uniform(Px(8)), Width: Px(-1), TextStyle: &TextStyle{FontSize: 16}, FirstLetter: &FirstLetter{}},
+		"zero min width": {Padding: Uniform(Px(8)), Width: Px(-1), TextStyle: &TextStyle{FontSize: 14}, FirstLetter: &FirstLetter{}, MinWidth: Px(0)},
+		"grid columns":   {Padding: Uniform(Px(8)), Width: Px(-1), TextStyle: &TextStyle{FontSize: 14}, FirstLetter: &FirstLetter{}, GridTemplateColumns: []GridTrack{FixedTrack(Px(10))}},
+	} {
+		if a.Equal(c) {
+			t.Errorf("Expected a different %s not to be equal", name)
+		}
+	}
+}
+
+func TestStyleNormalizeCopiesSharedValues(t *testing.T) {
+	letter := &FirstLetter{Lines: 1}
+	s := Style{FirstLetter: letter, Margin: Uniform(Px(4)), Height: Length{Value: -3}}
+	s.Normalize()
+
+	if letter.Lines != 1 || s.FirstLetter.Lines != 0 {
+		t.Errorf("Expected a normalized copy of FirstLetter, got %d and %d", letter.Lines, s.FirstLetter.Lines)
+	}
+	if s.Margin.Left != (Length{Value: 4}) || s.Height != Px(-1) {
+		t.Errorf("Expected canonical lengths, got %+v and %+v", s.Margin.Left, s.Height)
+	}
+}
+
+func TestNodeEqualAndNormalize(t *testing.T) {
+	build := func() *Node {
+		item := Fixed(50, 20)
+		item.Classes = []string{"b", "a", "b"}
+		item.Text = "x"
+		return VStack(item, Fixed(30, 30))
+	}
+	a, b := build(), build()
+	ctx := NewLayoutContext(400, 300, 16)
+	Layout(a, Loose(400, 300), ctx)
+	Layout(b, Loose(400, 300), ctx)
+
+	b.Children[0].Classes = []string{"a", "b"}
+	b.Children[0].Attributes = map[string]string{}
+	b.Children[0].UserData = "ignored"
+	b.Scroll = Point{X: 5}
+	if !a.Equal(b) {
+		t.Error("Expected trees differing only in class order and ignored fields to be equal")
+	}
+
+	b.Children[1].Rect.Height++
+	if a.Equal(b) {
+		t.Error("Expected a different rect not to be equal")
+	}
+	b.Children = b.Children[:1]
+	if a.Equal(b) {
+		t.Error("Expected different children not to be equal")
+	}
+	if a.Equal(nil) || !(*Node)(nil).Equal(nil) {
+		t.Error("Expected nil only to equal nil")
+	}
+
+	item := a.Children[0]
+	item.Style.Margin = Uniform(Px(2))
+	a.Normalize()
+	if len(item.Classes) != 2 || item.Classes[0] != "a" || item.Classes[1] != "b" {
+		t.Errorf("Expected sorted unique classes, got %v", item.Classes)
+	}
+	if item.Style.Margin.Top != (Length{Value: 2}) {
+		t.Errorf("Expected normalized styles, got margin %+v", item.Style.Margin.Top)
+	}
+}
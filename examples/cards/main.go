@@ -135,4 +135,15 @@ func main() {
 		fmt.Printf(` />` + "\n")
 	}
 	fmt.Println("</svg>")
+
+	// Picking: find the card under a point, accounting for rotations
+	fmt.Println()
+	x, y := 250.0, 80.0
+	for i, node := range nodes {
+		if i > 0 && layout.PointInNode(root, node, x, y) {
+			bounds, _ := layout.GetTransformedBounds(root, node)
+			fmt.Printf("Card %d is under (%.0f, %.0f); its bounds are (%.2f, %.2f) %.2f x %.2f\n",
+				i, x, y, bounds.X, bounds.Y, bounds.Width, bounds.Height)
+		}
+	}
 }
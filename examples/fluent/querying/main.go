@@ -85,46 +85,42 @@ func main() {
 	fmt.Println("\n=== Collecting Statistics ===")
 
 	// Count total nodes
-	totalNodes := tree.Fold(0, func(acc interface{}, n *layout.Node) interface{} {
-		return acc.(int) + 1
-	}).(int)
+	totalNodes := layout.FoldNodes(tree, 0, func(acc int, n *layout.Node) int {
+		return acc + 1
+	})
 	fmt.Printf("Total nodes: %d\n", totalNodes)
 
 	// Sum all widths
-	totalWidth := tree.Fold(0.0, func(acc interface{}, n *layout.Node) interface{} {
-		return acc.(float64) + n.Style.Width.Value
-	}).(float64)
+	totalWidth := layout.FoldNodes(tree, 0.0, func(acc float64, n *layout.Node) float64 {
+		return acc + n.Style.Width.Value
+	})
 	fmt.Printf("Sum of all widths: %.0f\n", totalWidth)
 
 	// Find max height
-	maxHeight := tree.Fold(0.0, func(acc interface{}, n *layout.Node) interface{} {
-		current := acc.(float64)
+	maxHeight := layout.FoldNodes(tree, 0.0, func(current float64, n *layout.Node) float64 {
 		if n.Style.Height.Value > current {
 			return n.Style.Height.Value
 		}
 		return current
-	}).(float64)
+	})
 	fmt.Printf("Maximum height: %.0f\n", maxHeight)
 
 	// Collect all text content
-	allText := tree.Fold([]string{}, func(acc interface{}, n *layout.Node) interface{} {
-		list := acc.([]string)
+	allText := layout.FoldNodes(tree, []string{}, func(list []string, n *layout.Node) []string {
 		if n.Text != "" {
 			list = append(list, n.Text)
 		}
 		return list
-	}).([]string)
+	})
 	fmt.Printf("All text: %v\n", allText)
 
 	// Count nodes by depth
-	depthCounts := tree.FoldWithContext(
-		make(map[int]int),
-		func(acc interface{}, n *layout.Node, depth int) interface{} {
-			m := acc.(map[int]int)
+	depthCounts := layout.FoldNodesWithDepth(tree, make(map[int]int),
+		func(m map[int]int, n *layout.Node, depth int) map[int]int {
 			m[depth]++
 			return m
 		},
-	).(map[int]int)
+	)
 	fmt.Printf("Nodes per depth: %v\n", depthCounts)
 
 	fmt.Println("\n=== Transforming Trees ===")
@@ -190,8 +186,8 @@ func main() {
 	})
 	fmt.Printf("Original still has %d text nodes\n", len(originalTextNodes))
 
-	originalSum := tree.Fold(0.0, func(acc interface{}, n *layout.Node) interface{} {
-		return acc.(float64) + n.Style.Width.Value
-	}).(float64)
+	originalSum := layout.FoldNodes(tree, 0.0, func(acc float64, n *layout.Node) float64 {
+		return acc + n.Style.Width.Value
+	})
 	fmt.Printf("Original sum of widths: %.0f (unchanged)\n", originalSum)
 }
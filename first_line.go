@@ -0,0 +1,333 @@
+package layout
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// First-line and first-letter styling.
+//
+// Style.FirstLine styles the first formatted line of a text node like the
+// CSS ::first-line pseudo-element, and Style.FirstLetter turns its first
+// letter into a drop cap, like ::first-letter with initial-letter, that the
+// following lines wrap around. Both are resolved by LayoutText.
+//
+// See: https://www.w3.org/TR/css-pseudo-4/#first-line-pseudo
+// and https://www.w3.org/TR/css-inline-3/#initial-letter-styling
+
+// FirstLetter styles the first letter of a text node as a drop cap: an
+// enlarged letter that sinks Lines lines into the text, which is laid out
+// beside it. The letter is the first typographic letter unit of the text,
+// with any punctuation around it.
+//
+// Drop caps apply in horizontal writing modes. The letter is on the start
+// side: the left, or the right in right-to-left text.
+//
+// Example:
+//
+//	node := layout.Text("Once upon a time...", layout.Style{
+//	    TextStyle:   &layout.TextStyle{FontSize: 16},
+//	    FirstLetter: &layout.FirstLetter{Lines: 3, Margin: 4},
+//	})
+type FirstLetter struct {
+	// Lines is the number of lines the letter spans, from the top of the
+	// first line's text to the baseline of the last. Below 1 means 1.
+	Lines int
+
+	// FontSize is the size of the letter in pixels. 0 sizes the letter to
+	// span Lines lines.
+	FontSize float64
+
+	// FontFamily and FontWeight override the text's for the letter when
+	// set.
+	FontFamily string
+	FontWeight FontWeight
+
+	// Margin is the space between the letter and the text beside it, in
+	// pixels.
+	Margin float64
+}
+
+// DropCap is a laid-out drop cap (see FirstLetter).
+type DropCap struct {
+	Text  string
+	Style TextStyle // The style to draw Text in
+
+	// Rect is the letter's box, from the top of its ascent to the bottom of
+	// its descent, relative to the content box like line offsets.
+	Rect Rect
+
+	// Baseline is the offset of the letter's baseline from the content
+	// box's block start. It's the baseline of the last line it spans.
+	Baseline float64
+}
+
+// firstLineStyle returns style with the set fields of first: the
+// non-zero ones. It returns nil without a first-line style.
+func firstLineStyle(style TextStyle, first *TextStyle) *TextStyle {
+	if first == nil {
+		return nil
+	}
+	out := style
+	if first.FontSize > 0 {
+		out.FontSize = first.FontSize
+	}
+	if first.FontFamily != "" {
+		out.FontFamily = first.FontFamily
+	}
+	if first.FontWeight != 0 {
+		out.FontWeight = first.FontWeight
+	}
+	if first.FontStyle != FontStyleNormal {
+		out.FontStyle = first.FontStyle
+	}
+	if first.LetterSpacing != 0 {
+		out.LetterSpacing = first.LetterSpacing
+	}
+	if first.WordSpacing != 0 {
+		out.WordSpacing = first.WordSpacing
+	}
+	if first.TextTransform != TextTransformNone {
+		out.TextTransform = first.TextTransform
+	}
+	if first.LineHeight > 0 {
+		out.LineHeight = first.LineHeight
+	}
+	if first.TextDecoration != TextDecorationNone {
+		out.TextDecoration = first.TextDecoration
+	}
+	if first.TextDecorationColor != "" {
+		out.TextDecorationColor = first.TextDecorationColor
+	}
+	return &out
+}
+
+// splitFirstLetter splits text into its first letter, with the
+// punctuation before and after it, and the rest. The letter is empty if
+// text doesn't start with one.
+func splitFirstLetter(text string) (letter, rest string) {
+	i := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsPunct(r) {
+			break
+		}
+		i += size
+	}
+	r, size := utf8.DecodeRuneInString(text[i:])
+	if i == len(text) || !(unicode.IsLetter(r) || unicode.IsNumber(r)) {
+		return "", text
+	}
+	i += size
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.In(r, unicode.Mn, unicode.Me) && !unicode.IsPunct(r) {
+			break
+		}
+		i += size
+	}
+	return text[:i], text[i:]
+}
+
+// layoutDropCap styles and sizes the drop cap for letter, in the lines of
+// a paragraph whose first line is in first and the others in style. It
+// returns the drop cap, its block offsets still to be set, and its inset
+// of the lines beside it.
+func layoutDropCap(letter string, fl *FirstLetter, style, first TextStyle, ctx *LayoutContext) (*DropCap, []float64) {
+	lines := fl.Lines
+	if lines < 1 {
+		lines = 1
+	}
+	letterStyle := first
+	letterStyle.TextTransform = TextTransformNone
+	letterStyle.TextDecoration = TextDecorationNone
+	if fl.FontFamily != "" {
+		letterStyle.FontFamily = fl.FontFamily
+	}
+	if fl.FontWeight != 0 {
+		letterStyle.FontWeight = fl.FontWeight
+	}
+
+	// The letter's ascent reaches from the top of the first line's text to
+	// the baseline of the last line it spans
+	top, baseline := strutBaseline(first, ctx)
+	top = baseline - top
+	if lines > 1 {
+		_, b := strutBaseline(style, ctx)
+		baseline = resolveLineHeight(first.LineHeight, first.FontSize) +
+			float64(lines-2)*resolveLineHeight(style.LineHeight, style.FontSize) + b
+	}
+	letterStyle.FontSize = fl.FontSize
+	if letterStyle.FontSize <= 0 {
+		const reference = 100
+		letterStyle.FontSize = reference
+		_, ascent, _ := measureText(letter, letterStyle, ctx)
+		if ascent > 0 {
+			letterStyle.FontSize = reference * (baseline - top) / ascent
+		}
+	}
+
+	width, ascent, descent := measureText(letter, letterStyle, ctx)
+	dc := &DropCap{
+		Text:     letter,
+		Style:    letterStyle,
+		Rect:     Rect{Y: baseline - ascent, Width: width, Height: ascent + descent},
+		Baseline: baseline,
+	}
+	insets := make([]float64, lines)
+	for i := range insets {
+		insets[i] = width + fl.Margin
+	}
+	return dc, insets
+}
+
+// strutBaseline returns the ascent of an empty line in style and the
+// offset of its baseline from the line's block start.
+func strutBaseline(style TextStyle, ctx *LayoutContext) (ascent, baseline float64) {
+	_, ascent, descent := measureText(" ", style, ctx)
+	lineHeight := resolveLineHeight(style.LineHeight, style.FontSize)
+	return ascent, (lineHeight-(ascent+descent))/2 + ascent
+}
+
+// breakLeadingLines breaks text into lines like breakIntoLines, with a
+// first line in first (nil for style) and the first lines inset by the
+// inline sizes in insets.
+func breakLeadingLines(text string, maxInlineSize float64, style TextStyle, first *TextStyle, insets []float64, ctx *LayoutContext) []TextLine {
+	n := len(insets)
+	if first != nil && n < 1 {
+		n = 1
+	}
+	if n == 0 || text == "" {
+		return breakIntoLines(text, maxInlineSize, style, ctx)
+	}
+	if maxInlineSize <= 0 {
+		maxInlineSize = Unbounded
+	}
+
+	var lines []TextLine
+	for i := 0; i < n && text != ""; i++ {
+		lineStyle := style
+		if i == 0 && first != nil {
+			lineStyle = *first
+		}
+		available := maxInlineSize
+		if i < len(insets) {
+			available -= insets[i]
+		}
+		if i == 0 {
+			available -= style.TextIndent
+		} else {
+			lineStyle.TextIndent = 0
+		}
+		if i > 0 || lineStyle.TextTransform == style.TextTransform {
+			// The text is already transformed for the paragraph
+			lineStyle.TextTransform = TextTransformNone
+		}
+		var line TextLine
+		line, text = splitFirstLine(text, available, lineStyle, ctx)
+		lines = append(lines, line)
+	}
+	if text != "" {
+		// Only the first line is indented
+		rest := style
+		rest.TextIndent = 0
+		lines = append(lines, breakIntoLines(text, maxInlineSize, rest, ctx)...)
+	}
+	return lines
+}
+
+// splitFirstLine breaks the first line off text: the longest run up to a
+// break opportunity that fits maxInlineSize, or up to the first one if none
+// fits. It returns the line and the text after it.
+func splitFirstLine(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) (TextLine, string) {
+	// Preserved newlines are forced breaks
+	segment, rest := text, ""
+	if style.WhiteSpace != WhiteSpaceNormal && style.WhiteSpace != WhiteSpaceNowrap {
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			segment, rest = text[:i], text[i+1:]
+		}
+	}
+
+	candidates := []int{len(segment)}
+	if style.WhiteSpace != WhiteSpacePre && style.WhiteSpace != WhiteSpaceNowrap {
+		candidates = findLineBreakOpportunitiesWithHyphens(segment, style.Hyphens)
+		if candidates[len(candidates)-1] != len(segment) {
+			candidates = append(candidates, len(segment))
+		}
+	}
+
+	var line TextLine
+	end := -1
+	for _, c := range candidates {
+		if c == 0 && len(segment) > 0 {
+			continue
+		}
+		l, fits := measureLine(segment[:c], maxInlineSize, style, ctx)
+		if !fits && end >= 0 {
+			break
+		}
+		line, end = l, c
+		if !fits {
+			break
+		}
+	}
+	if end < len(segment) {
+		rest = text[end:]
+	}
+	if style.WhiteSpace == WhiteSpaceNormal {
+		rest = strings.TrimLeft(rest, " ")
+	}
+	return line, rest
+}
+
+// measureLine lays out text as a single line in style, showing a hyphen
+// if it ends at a soft hyphen, and reports whether it fits maxInlineSize.
+func measureLine(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) (TextLine, bool) {
+	if style.TextTransform != TextTransformNone {
+		text = applyTextTransform(text, style.TextTransform)
+	}
+	softHyphenated := strings.HasSuffix(text, softHyphen)
+	lines := breakIntoLines(text, Unbounded, style, ctx)
+	if len(lines) == 0 {
+		return TextLine{Boxes: []InlineBox{}}, true
+	}
+	line := lines[0]
+	if softHyphenated && len(line.Boxes) > 0 {
+		hyphenWidth, _, _ := measureText(hyphenText, style, ctx)
+		last := &line.Boxes[len(line.Boxes)-1]
+		*last = newInlineBox(last.Text+hyphenText, last.Width+hyphenWidth, last.Ascent, last.Descent, style, ctx)
+		line.Width += hyphenWidth
+	}
+	width := line.Width
+	if len(line.Boxes) > 0 {
+		// A stop or comma ending the line may hang past its end
+		width -= endHangWidth(line.Boxes[len(line.Boxes)-1].Text, style.HangingPunctuation, style, ctx)
+	}
+	return line, width <= maxInlineSize+0.001
+}
+
+// shiftLinesAfterFirst moves the lines after the first along the block
+// axis by delta, the difference between the first line's height and the
+// others'.
+func shiftLinesAfterFirst(lines []TextLine, delta float64, writingMode WritingMode) {
+	if delta == 0 {
+		return
+	}
+	switch writingMode {
+	case WritingModeVerticalRL, WritingModeSidewaysRL:
+		// Lines stack leftward from the right edge, so the first line
+		// starts further left too
+		for i := range lines {
+			lines[i].OffsetX -= delta
+		}
+	case WritingModeVerticalLR, WritingModeSidewaysLR:
+		for i := 1; i < len(lines); i++ {
+			lines[i].OffsetX += delta
+		}
+	default:
+		for i := 1; i < len(lines); i++ {
+			lines[i].OffsetY += delta
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFirstLineStyle(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// Letter spacing widens the first line's words, so only one fits
+	node := Text("aaa bbb ccc ddd", Style{
+		TextStyle: &TextStyle{FontSize: 10, LineHeight: 20, WordSpacing: -1, LetterSpacing: -1},
+		FirstLine: &TextStyle{FontSize: 20, LineHeight: 30, LetterSpacing: 10},
+	})
+	size := LayoutText(node, Loose(80, 200), NewLayoutContext(800, 600, 16))
+
+	tl := node.TextLayout
+	var texts []string
+	for i := range tl.Lines {
+		texts = append(texts, tl.LineText(i))
+	}
+	if want := []string{"aaa", "bbb ccc", "ddd"}; !reflect.DeepEqual(texts, want) {
+		t.Fatalf("Expected lines %q, got %q", want, texts)
+	}
+
+	// The first line is 30px tall and the others 20px
+	for i, want := range []struct{ offset, height, baseline float64 }{{0, 30, 21}, {30, 20, 13}, {50, 20, 13}} {
+		line := tl.Lines[i]
+		if line.OffsetY != want.offset || line.Height != want.height || line.Baseline != want.baseline {
+			t.Errorf("Line %d: expected offset %v, height %v and baseline %v, got %v, %v and %v",
+				i, want.offset, want.height, want.baseline, line.OffsetY, line.Height, line.Baseline)
+		}
+	}
+	if size.Height != 70 {
+		t.Errorf("Expected height 70, got %v", size.Height)
+	}
+	if tl.FirstLineStyle == nil || tl.FirstLineStyle.FontSize != 20 || tl.FirstLineStyle.LineHeight != 30 {
+		t.Errorf("Expected the first line style to be recorded, got %+v", tl.FirstLineStyle)
+	}
+
+	// Hit-testing follows the taller first line
+	if pos, _ := node.TextPositionAt(5, 25); pos.Line != 0 {
+		t.Errorf("Expected line 0 at y=25, got %+v", pos)
+	}
+	if pos, _ := node.TextPositionAt(5, 35); pos.Line != 1 {
+		t.Errorf("Expected line 1 at y=35, got %+v", pos)
+	}
+}
+
+func TestDropCap(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	style := TextStyle{FontSize: 10, LineHeight: 20, WordSpacing: -1, LetterSpacing: -1}
+	node := Text("Once upon a time there", Style{
+		TextStyle:   &style,
+		FirstLetter: &FirstLetter{Lines: 2, Margin: 5},
+	})
+	size := LayoutText(node, Loose(100, 200), NewLayoutContext(800, 600, 16))
+
+	tl := node.TextLayout
+	dc := tl.DropCap
+	if dc == nil {
+		t.Fatal("Expected a drop cap")
+	}
+
+	// The letter reaches from the top of the first line's text (5px of
+	// half-leading down) to the second line's baseline (33px): a 28px
+	// ascent, at 35px
+	if dc.Text != "O" || dc.Style.FontSize != 35 || dc.Baseline != 33 {
+		t.Errorf("Expected O at 35px on a baseline at 33, got %q at %v on %v", dc.Text, dc.Style.FontSize, dc.Baseline)
+	}
+	if want := (Rect{X: 0, Y: 5, Width: 10, Height: 35}); dc.Rect != want {
+		t.Errorf("Expected drop cap rect %+v, got %+v", want, dc.Rect)
+	}
+
+	// The two lines beside it are inset by its width and margin
+	var texts []string
+	var offsets []float64
+	for i := range tl.Lines {
+		texts = append(texts, tl.LineText(i))
+		offsets = append(offsets, tl.Lines[i].OffsetX)
+	}
+	if want := []string{"nce upon", "a time", "there"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("Expected lines %q, got %q", want, texts)
+	}
+	if want := []float64{15, 15, 0}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("Expected line offsets %v, got %v", want, offsets)
+	}
+	if size.Width != 95 || size.Height != 60 {
+		t.Errorf("Expected size 95x60, got %vx%v", size.Width, size.Height)
+	}
+
+	// In right-to-left text the letter is on the right
+	style.Direction = DirectionRTL
+	node = Text("Once upon a time there", Style{
+		TextStyle:   &style,
+		FirstLetter: &FirstLetter{Lines: 2, Margin: 5},
+	})
+	LayoutText(node, Loose(100, 200), NewLayoutContext(800, 600, 16))
+	if got := node.TextLayout.DropCap.Rect.X; got != 90 {
+		t.Errorf("Expected the drop cap at x=90, got %v", got)
+	}
+	if got := node.TextLayout.Lines[0].OffsetX; got != 5 {
+		t.Errorf("Expected the first line at x=5, got %v", got)
+	}
+}
+
+func TestSplitFirstLetter(t *testing.T) {
+	tests := []struct{ text, letter, rest string }{
+		{"Once", "O", "nce"},
+		{"“Hello,” she said", "“H", "ello,” she said"},
+		{"A. Smith", "A.", " Smith"},
+		{"éte", "é", "te"},
+		{"  space", "", "  space"},
+		{"...", "", "..."},
+	}
+	for _, tt := range tests {
+		letter, rest := splitFirstLetter(tt.text)
+		if letter != tt.letter || rest != tt.rest {
+			t.Errorf("splitFirstLetter(%q): expected %q and %q, got %q and %q", tt.text, tt.letter, tt.rest, letter, rest)
+		}
+	}
+}
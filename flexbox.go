@@ -22,40 +22,69 @@ func LayoutFlexbox(node *Node, constraints Constraints, ctx *LayoutContext) Size
 		// If not flex, delegate to block layout
 		return LayoutBlock(node, constraints, ctx)
 	}
+	node.tracks = nil
 
 	// Get current font size for Length resolution
 	fontSize := getCurrentFontSize(node, ctx)
+	restoreInherited := inheritStyle(ctx, node, fontSize)
+	defer restoreInherited()
 
 	// §9.2: Line Length Determination - Setup and initial measurement
 	setup := flexboxDetermineLineLength(node, constraints, ctx)
 
-	// Handle empty container
+	// Handle empty container: its content box is its definite size (from
+	// its style or its constraints), or 0
 	if len(node.Children) == 0 {
 		resultSize := Size{
 			Width:  setup.horizontalPadding + setup.horizontalBorder,
 			Height: setup.verticalPadding + setup.verticalBorder,
 		}
+		definiteWidth, definiteHeight := setup.hasExplicitMainSize, setup.hasExplicitCrossSize
+		if !setup.isMainHorizontal {
+			definiteWidth, definiteHeight = definiteHeight, definiteWidth
+		}
+		if definiteWidth && setup.contentWidth < Unbounded {
+			resultSize.Width += setup.contentWidth
+		}
+		if definiteHeight && setup.contentHeight < Unbounded {
+			resultSize.Height += setup.contentHeight
+		}
+		resultSize = constraints.Constrain(resultSize)
 		node.Rect = Rect{
 			X:      0,
 			Y:      0,
 			Width:  resultSize.Width,
 			Height: resultSize.Height,
 		}
-		return constraints.Constrain(resultSize)
+		return resultSize
+	}
+
+	buf, release := takeFlexBuffers(ctx)
+	defer release()
+
+	// Items' percentage sizes are taken of the content box
+	percentHeight := percentSizeIndefinite
+	if (setup.isMainHorizontal && setup.hasExplicitCrossSize) || (!setup.isMainHorizontal && setup.hasExplicitMainSize) {
+		percentHeight = setup.contentHeight
 	}
+	restore := resolvePercentSizes(node.Children, setup.contentWidth, percentHeight)
+	defer restore()
+	restoreContainer := enterQueryContainer(node, ctx, setup.contentWidth, percentHeight)
+	defer restoreContainer()
 
 	// §9.2: Line Length Determination - Measure items
-	flexItems := flexboxMeasureItems(node, setup, ctx)
+	flexItems := flexboxMeasureItems(node, setup, buf, ctx)
 
 	// Normalize align-items: zero value is stretch (CSS Flexbox default)
+	// An explicit stretch behaves the same as the default in flexbox.
 	alignItems := node.Style.AlignItems
-	if alignItems == 0 {
+	if alignItems == 0 || alignItems == AlignItemsStretchExplicit {
 		alignItems = AlignItemsStretch
 	}
 
 	// Step 2: Calculate flex lines (for wrapping)
 	hasWrap := node.Style.FlexWrap == FlexWrapWrap || node.Style.FlexWrap == FlexWrapWrapReverse
-	lines := calculateFlexLines(flexItems, setup.mainSize, hasWrap)
+	lines := calculateFlexLines(flexItems, setup.mainSize, hasWrap, buf)
 
 	// Get gap values (resolve Length to pixels)
 	rowGap := ResolveLength(node.Style.FlexRowGap, ctx, fontSize)
@@ -68,12 +97,14 @@ func LayoutFlexbox(node *Node, constraints Constraints, ctx *LayoutContext) Size
 	}
 
 	// §9.3: Main Size Determination and §9.4: Cross Size Determination
-	lineCrossSizes := make([]float64, len(lines))
+	lineCrossSizes := floats(buf.lineSizes, len(lines))
+	buf.lineSizes = lineCrossSizes
 	totalCrossSize := 0.0
 
 	for lineIdx, line := range lines {
 		// §9.3: Main Size Determination - determine main sizes using flex grow/shrink
-		flexboxDetermineMainSize(line, setup.mainSize, setup.hasExplicitMainSize)
+		flexboxDetermineMainSize(line, setup.mainSize, setup.hasExplicitMainSize, buf)
+		flexboxResolveHypotheticalCrossSizes(line, setup, ctx)
 
 		// §9.4: Cross Size Determination - determine line cross size
 		isSingleLine := len(lines) == 1
@@ -103,7 +134,7 @@ func LayoutFlexbox(node *Node, constraints Constraints, ctx *LayoutContext) Size
 	originalAlignContent := node.Style.AlignContent
 	node.Style.AlignContent = alignContent
 	lineOffsets, totalCrossSize := flexboxAlignWithAlignContent(
-		node, lines, lineCrossSizes, setup.crossSize, totalCrossSize, rowGap, setup.hasExplicitCrossSize)
+		node, lines, lineCrossSizes, setup.crossSize, totalCrossSize, rowGap, setup.hasExplicitCrossSize, buf)
 	node.Style.AlignContent = originalAlignContent
 
 	// §9.2: Line Length Determination - Handle flex-wrap: wrap-reverse
@@ -159,7 +190,7 @@ func LayoutFlexbox(node *Node, constraints Constraints, ctx *LayoutContext) Size
 
 				// Create tight constraints based on final size and re-layout
 				tightConstraints := Tight(finalWidth, finalHeight)
-				LayoutFlexbox(item.node, tightConstraints, ctx)
+				layoutByDisplay(item.node, tightConstraints, ctx)
 
 				// Restore position (re-layout resets X, Y to 0)
 				item.node.Rect.X = savedX
@@ -208,6 +239,7 @@ func LayoutFlexbox(node *Node, constraints Constraints, ctx *LayoutContext) Size
 		Width:  constrainedSize.Width,
 		Height: constrainedSize.Height,
 	}
+	node.tracks = flexLineTracks(node, &setup, lineOffsets, lineCrossSizes, ctx, fontSize)
 
 	return constrainedSize
 }
@@ -220,37 +252,54 @@ type flexItem struct {
 	flexGrow         float64
 	flexShrink       float64
 	flexBasis        float64
+	measuredMainSize float64 // main size the item was laid out at while measuring
+	minMainSize      float64 // border-box min main size, including the automatic minimum
+	maxMainSize      float64 // border-box max main size (Unbounded if none)
 	mainMarginStart  float64
 	mainMarginEnd    float64
 	crossMarginStart float64
 	crossMarginEnd   float64
+
+	// Which margins are auto (CSS Flexbox §8.1). Auto margins resolve to 0
+	// for sizing and absorb free space during alignment.
+	mainMarginStartAuto  bool
+	mainMarginEndAuto    bool
+	crossMarginStartAuto bool
+	crossMarginEndAuto   bool
+
+	// crossSizeDefinite reports that the cross size was definite when the
+	// main size was resolved, so an aspect ratio has already been applied.
+	crossSizeDefinite bool
 }
 
-func calculateFlexLines(items []*flexItem, containerMainSize float64, wrap bool) [][]*flexItem {
+// calculateFlexLines breaks items into lines. Each line is a run of
+// items, sharing their slice; the lines are kept in buf.
+func calculateFlexLines(items []*flexItem, containerMainSize float64, wrap bool, buf *flexBuffers) [][]*flexItem {
+	lines := buf.lines[:0]
 	if !wrap {
-		return [][]*flexItem{items}
+		buf.lines = append(lines, items)
+		return buf.lines
 	}
 
-	lines := [][]*flexItem{}
-	currentLine := []*flexItem{}
+	start := 0
 	currentLineSize := 0.0
 
-	for _, item := range items {
+	for i, item := range items {
 		// Include margins in item size for wrapping calculation
 		itemSize := item.baseSize + item.mainMarginStart + item.mainMarginEnd
-		if currentLineSize+itemSize > containerMainSize && len(currentLine) > 0 {
-			lines = append(lines, currentLine)
-			currentLine = []*flexItem{}
+		if currentLineSize+itemSize > containerMainSize && i > start {
+			lines = append(lines, items[start:i:i])
+			start = i
 			currentLineSize = 0
 		}
-		currentLine = append(currentLine, item)
 		currentLineSize += itemSize
 	}
 
-	if len(currentLine) > 0 {
-		lines = append(lines, currentLine)
+	if start < len(items) {
+		lines = append(lines, items[start:])
 	}
 
+	buf.lines = lines
 	return lines
 }
 
@@ -14,8 +14,10 @@ func flexboxAlignWithAlignContent(
 	totalCrossSize float64,
 	rowGap float64,
 	hasExplicitCrossSize bool,
+	buf *flexBuffers,
 ) ([]float64, float64) {
-	lineOffsets := make([]float64, len(lines))
+	lineOffsets := floats(buf.offsets, len(lines))
+	buf.offsets = lineOffsets
 
 	// align-content only has effect when there are multiple lines, wrapping is enabled,
 	// and the container's cross size is definite (not auto/unbounded).
@@ -25,70 +27,51 @@ func flexboxAlignWithAlignContent(
 		alignContent := node.Style.AlignContent
 		// Zero value is AlignContentStretch (CSS default), no need to check
 
-		// Calculate free cross space
+		// Calculate free cross space. When the lines overflow, distributed
+		// alignment falls back (CSS Box Alignment §4.3), and unless the
+		// alignment is unsafe the overflow is kept at the end (§4.4).
 		freeCrossSpace := crossSize - totalCrossSize
 		if freeCrossSpace < 0 {
-			freeCrossSpace = 0
+			switch alignContent {
+			case AlignContentStretch, AlignContentSpaceBetween:
+				alignContent = AlignContentFlexStart
+			case AlignContentSpaceAround, AlignContentSpaceEvenly:
+				alignContent = AlignContentCenter
+			}
+			if node.Style.AlignContentOverflow != OverflowAlignmentUnsafe {
+				freeCrossSpace = 0
+			}
 		}
 
-		var startOffset float64
+		// Space before the first line and between adjacent lines
+		var startOffset, between float64
 		switch alignContent {
-		case AlignContentFlexStart:
-			startOffset = 0
 		case AlignContentFlexEnd:
 			startOffset = freeCrossSpace
 		case AlignContentCenter:
 			startOffset = freeCrossSpace / 2
 		case AlignContentSpaceBetween:
-			if len(lines) > 1 {
-				startOffset = 0
-				spaceBetween := freeCrossSpace / float64(len(lines)-1)
-				currentOffset := 0.0
-				for i := range lines {
-					lineOffsets[i] = currentOffset
-					currentOffset += lineCrossSizes[i]
-					if i < len(lines)-1 {
-						currentOffset += rowGap + spaceBetween
-					}
-				}
-			} else {
-				startOffset = 0
-			}
+			between = freeCrossSpace / float64(len(lines)-1)
 		case AlignContentSpaceAround:
-			if len(lines) > 0 {
-				spaceAround := freeCrossSpace / float64(len(lines))
-				currentOffset := spaceAround / 2
-				for i := range lines {
-					lineOffsets[i] = currentOffset
-					currentOffset += lineCrossSizes[i]
-					if i < len(lines)-1 {
-						currentOffset += rowGap + spaceAround
-					}
-				}
-			}
+			between = freeCrossSpace / float64(len(lines))
+			startOffset = between / 2
+		case AlignContentSpaceEvenly:
+			between = freeCrossSpace / float64(len(lines)+1)
+			startOffset = between
 		case AlignContentStretch:
 			// Distribute free space equally to each line
-			if freeCrossSpace > 0 && len(lines) > 0 {
+			if freeCrossSpace > 0 {
 				extraPerLine := freeCrossSpace / float64(len(lines))
 				for i := range lineCrossSizes {
 					lineCrossSizes[i] += extraPerLine
 				}
 			}
-			startOffset = 0
-		default:
-			startOffset = 0
 		}
 
-		// Calculate line offsets for non-space-between/space-around
-		if alignContent != AlignContentSpaceBetween && alignContent != AlignContentSpaceAround {
-			currentOffset := startOffset
-			for i := range lines {
-				lineOffsets[i] = currentOffset
-				currentOffset += lineCrossSizes[i]
-				if i < len(lines)-1 {
-					currentOffset += rowGap
-				}
-			}
+		currentOffset := startOffset
+		for i := range lines {
+			lineOffsets[i] = currentOffset
+			currentOffset += lineCrossSizes[i] + rowGap + between
 		}
 
 		// Update total cross size if stretch was applied
@@ -0,0 +1,95 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlexboxAspectRatioBaseSizeFromStretchedCrossSize(t *testing.T) {
+	img := &Node{Style: Style{AspectRatio: 2}}
+	next := Fixed(50, 20)
+	row := HStack(img, next)
+	row.Style.Width = Px(400)
+	row.Style.Height = Px(100)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(img.Rect.Width-200) > 0.01 || math.Abs(img.Rect.Height-100) > 0.01 {
+		t.Errorf("Expected 200x100 item, got %.2fx%.2f", img.Rect.Width, img.Rect.Height)
+	}
+	// The sibling is placed after the ratio-derived width, not the content width
+	if math.Abs(next.Rect.X-200) > 0.01 {
+		t.Errorf("Expected sibling at X=200, got %.2f", next.Rect.X)
+	}
+}
+
+func TestFlexboxAspectRatioExplicitCrossSize(t *testing.T) {
+	img := &Node{Style: Style{AspectRatio: 2, Height: Px(30)}}
+	next := Fixed(50, 20)
+	row := HStack(img, next)
+	row.Style.Width = Px(400)
+	row.Style.Height = Px(100)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(img.Rect.Width-60) > 0.01 || math.Abs(img.Rect.Height-30) > 0.01 {
+		t.Errorf("Expected 60x30 item, got %.2fx%.2f", img.Rect.Width, img.Rect.Height)
+	}
+	if math.Abs(next.Rect.X-60) > 0.01 {
+		t.Errorf("Expected sibling at X=60, got %.2f", next.Rect.X)
+	}
+}
+
+func TestFlexboxAspectRatioColumn(t *testing.T) {
+	img := &Node{Style: Style{AspectRatio: 2}}
+	next := Fixed(50, 20)
+	col := VStack(img, next)
+	col.Style.Width = Px(100)
+	col.Style.Height = Px(300)
+
+	Layout(col, Loose(100, 300), NewLayoutContext(100, 300, 16))
+
+	if math.Abs(img.Rect.Width-100) > 0.01 || math.Abs(img.Rect.Height-50) > 0.01 {
+		t.Errorf("Expected 100x50 item, got %.2fx%.2f", img.Rect.Width, img.Rect.Height)
+	}
+	if math.Abs(next.Rect.Y-50) > 0.01 {
+		t.Errorf("Expected sibling at Y=50, got %.2f", next.Rect.Y)
+	}
+}
+
+func TestFlexboxAspectRatioAutomaticMinimum(t *testing.T) {
+	// The transferred width is the item's min-content size, so it doesn't
+	// shrink out of ratio when the row is too narrow
+	img := &Node{Style: Style{AspectRatio: 2}}
+	row := HStack(img, Fixed(50, 20))
+	row.Style.Width = Px(150)
+	row.Style.Height = Px(100)
+
+	Layout(row, Loose(150, 100), NewLayoutContext(150, 100, 16))
+
+	if math.Abs(img.Rect.Width-200) > 0.01 {
+		t.Errorf("Expected item to keep its 200px transferred width, got %.2f", img.Rect.Width)
+	}
+
+	// MinWidth: 0 opts out; the item shrinks and its stretched height stays
+	img.Style.MinWidth = Px(0)
+	Layout(row, Loose(150, 100), NewLayoutContext(150, 100, 16))
+
+	if img.Rect.Width >= 200 || math.Abs(img.Rect.Height-100) > 0.01 {
+		t.Errorf("Expected item to shrink at full height, got %.2fx%.2f", img.Rect.Width, img.Rect.Height)
+	}
+}
+
+func TestFlexboxAspectRatioNotStretched(t *testing.T) {
+	img := &Node{Style: Style{AspectRatio: 2, Width: Px(80)}}
+	row := HStack(img)
+	row.Style.Width = Px(400)
+	row.Style.Height = Px(100)
+	row.Style.AlignItems = AlignItemsFlexStart
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(img.Rect.Height-40) > 0.01 {
+		t.Errorf("Expected height 40 from the ratio, got %.2f", img.Rect.Height)
+	}
+}
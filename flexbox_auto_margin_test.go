@@ -0,0 +1,98 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlexboxAutoMarginPushesLastItem(t *testing.T) {
+	logo := Fixed(50, 20)
+	link := Fixed(40, 20)
+	login := Fixed(60, 20)
+	login.Style.Margin.Left = Auto()
+	nav := HStack(logo, link, login)
+	nav.Style.Width = Px(400)
+	nav.Style.JustifyContent = JustifyContentCenter
+
+	Layout(nav, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	// Auto margins take all free space, so justify-content has no effect
+	if logo.Rect.X != 0 || link.Rect.X != 50 {
+		t.Errorf("Expected leading items packed at the start, got X %.2f and %.2f", logo.Rect.X, link.Rect.X)
+	}
+	if math.Abs(login.Rect.X-340) > 0.01 {
+		t.Errorf("Expected last item pushed to X=340, got %.2f", login.Rect.X)
+	}
+}
+
+func TestFlexboxAutoMarginsCenter(t *testing.T) {
+	item := Fixed(100, 40)
+	item.Style.Margin = Spacing{Top: Auto(), Right: Auto(), Bottom: Auto(), Left: Auto()}
+	row := HStack(item)
+	row.Style.Width = Px(300)
+	row.Style.Height = Px(200)
+
+	Layout(row, Loose(300, 200), NewLayoutContext(300, 200, 16))
+
+	if math.Abs(item.Rect.X-100) > 0.01 || math.Abs(item.Rect.Y-80) > 0.01 {
+		t.Errorf("Expected item centered at (100, 80), got (%.2f, %.2f)", item.Rect.X, item.Rect.Y)
+	}
+	if item.Rect.Height != 40 {
+		t.Errorf("Expected auto cross margins to prevent stretch, got height %.2f", item.Rect.Height)
+	}
+}
+
+func TestFlexboxAutoMarginsShareFreeSpace(t *testing.T) {
+	a := Fixed(50, 20)
+	b := Fixed(50, 20)
+	b.Style.Margin.Left = Auto()
+	b.Style.Margin.Right = Auto()
+	c := Fixed(50, 20)
+	c.Style.Margin.Left = Auto()
+	row := HStack(a, b, c)
+	row.Style.Width = Px(300)
+	row.Style.FlexGap = Px(10)
+
+	Layout(row, Loose(300, 100), NewLayoutContext(300, 100, 16))
+
+	// Free space 300 - 150 - 20 = 130, split over three auto margins
+	share := 130.0 / 3
+	if math.Abs(b.Rect.X-(60+share)) > 0.01 {
+		t.Errorf("Expected middle item at X=%.2f, got %.2f", 60+share, b.Rect.X)
+	}
+	if math.Abs(c.Rect.X-250) > 0.01 {
+		t.Errorf("Expected last item at X=250, got %.2f", c.Rect.X)
+	}
+}
+
+func TestFlexboxAutoMarginColumn(t *testing.T) {
+	footer := Fixed(30, 30)
+	footer.Style.Margin.Top = Auto()
+	footer.Style.Margin.Left = Auto()
+	col := VStack(Fixed(100, 20), footer)
+	col.Style.Width = Px(100)
+	col.Style.Height = Px(200)
+
+	Layout(col, Loose(100, 200), NewLayoutContext(100, 200, 16))
+
+	// margin-top pushes along the main axis, margin-left across it
+	if math.Abs(footer.Rect.Y-170) > 0.01 || math.Abs(footer.Rect.X-70) > 0.01 {
+		t.Errorf("Expected footer at (70, 170), got (%.2f, %.2f)", footer.Rect.X, footer.Rect.Y)
+	}
+}
+
+func TestFlexboxAutoMarginNegativeFreeSpace(t *testing.T) {
+	a := Fixed(200, 20)
+	a.Style.MinWidth = Px(200)
+	b := Fixed(200, 20)
+	b.Style.MinWidth = Px(200)
+	b.Style.Margin.Left = Auto()
+	row := HStack(a, b)
+	row.Style.Width = Px(300)
+
+	Layout(row, Loose(300, 100), NewLayoutContext(300, 100, 16))
+
+	if b.Rect.X != 200 {
+		t.Errorf("Expected auto margin to be 0 on overflow, got X %.2f", b.Rect.X)
+	}
+}
@@ -0,0 +1,130 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlexBasisZeroSharesSpaceByGrow(t *testing.T) {
+	narrow := Fixed(50, 20)
+	wide := Fixed(100, 20)
+	for _, n := range []*Node{narrow, wide} {
+		n.Style.FlexBasis = Basis(Px(0))
+		n.Style.FlexGrow = 1
+	}
+	row := HStack(narrow, wide)
+	row.Style.Width = Px(300)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(narrow.Rect.Width-150) > 0.01 || math.Abs(wide.Rect.Width-150) > 0.01 {
+		t.Errorf("Expected equal widths 150 with a zero basis, got %.2f and %.2f", narrow.Rect.Width, wide.Rect.Width)
+	}
+}
+
+func TestFlexBasisAutoUsesWidth(t *testing.T) {
+	narrow := Fixed(50, 20)
+	wide := Fixed(100, 20)
+	for _, n := range []*Node{narrow, wide} {
+		n.Style.FlexGrow = 1
+	}
+	row := HStack(narrow, wide)
+	row.Style.Width = Px(300)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(narrow.Rect.Width-125) > 0.01 || math.Abs(wide.Rect.Width-175) > 0.01 {
+		t.Errorf("Expected widths 125 and 175 with an auto basis, got %.2f and %.2f", narrow.Rect.Width, wide.Rect.Width)
+	}
+}
+
+func TestFlexBasisContentIgnoresWidth(t *testing.T) {
+	item := &Node{
+		Style:    Style{Width: Px(200), FlexBasis: BasisContent()},
+		Children: []*Node{Fixed(80, 20)},
+	}
+	row := HStack(item)
+	row.Style.Width = Px(400)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(item.Rect.Width-80) > 0.01 {
+		t.Errorf("Expected content basis width 80, got %.2f", item.Rect.Width)
+	}
+}
+
+func TestFlexBasisContentColumn(t *testing.T) {
+	item := &Node{
+		Style:    Style{Height: Px(200), FlexBasis: BasisContent()},
+		Children: []*Node{Fixed(50, 40)},
+	}
+	col := VStack(item)
+	col.Style.Height = Px(400)
+
+	Layout(col, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if math.Abs(item.Rect.Height-40) > 0.01 {
+		t.Errorf("Expected content basis height 40, got %.2f", item.Rect.Height)
+	}
+	if item.Style.Height.Value != 200 {
+		t.Errorf("Expected item style to be untouched, got height %.2f", item.Style.Height.Value)
+	}
+}
+
+func TestFlexBasisPercentOfMainSize(t *testing.T) {
+	half := &Node{Style: Style{Height: Px(20), FlexBasis: Basis(Percent(50))}}
+	quarter := &Node{Style: Style{Height: Px(20), FlexBasis: Basis(Percent(25))}}
+	row := HStack(half, quarter)
+	row.Style.Width = Px(400)
+	row.Style.Padding = Horizontal(Px(10))
+	row.Style.BoxSizing = BoxSizingBorderBox
+
+	Layout(row, Loose(600, 100), NewLayoutContext(600, 100, 16))
+
+	// Percentages resolve against the inner main size (400 border box - 20 padding)
+	if math.Abs(half.Rect.Width-190) > 0.01 {
+		t.Errorf("Expected 50%% basis to be 190, got %.2f", half.Rect.Width)
+	}
+	if math.Abs(quarter.Rect.Width-95) > 0.01 {
+		t.Errorf("Expected 25%% basis to be 95, got %.2f", quarter.Rect.Width)
+	}
+}
+
+func TestFlexBasisPercentColumn(t *testing.T) {
+	item := &Node{Style: Style{Width: Px(20), FlexBasis: Basis(Percent(30))}}
+	col := VStack(item)
+	col.Style.Height = Px(200)
+
+	Layout(col, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if math.Abs(item.Rect.Height-60) > 0.01 {
+		t.Errorf("Expected 30%% of 200 = 60, got %.2f", item.Rect.Height)
+	}
+}
+
+func TestFlexBasisPercentIndefiniteIsContent(t *testing.T) {
+	item := &Node{
+		Style:    Style{FlexBasis: Basis(Percent(50))},
+		Children: []*Node{Fixed(70, 20)},
+	}
+	row := HStack(item)
+
+	Layout(row, Unconstrained(), NewLayoutContext(400, 400, 16))
+
+	if math.Abs(item.Rect.Width-70) > 0.01 {
+		t.Errorf("Expected percentage of an indefinite main size to fall back to content (70), got %.2f", item.Rect.Width)
+	}
+}
+
+func TestResolveLengthPercentWithoutBase(t *testing.T) {
+	ctx := NewLayoutContext(400, 400, 16)
+	if got := ResolveLength(Percent(50), ctx, 16); got != 0 {
+		t.Errorf("Expected a percentage without a reference size to resolve to 0, got %.2f", got)
+	}
+	if got := resolveLengthAgainst(Percent(50), 300, ctx, 16); got != 150 {
+		t.Errorf("Expected 50%% of 300 = 150, got %.2f", got)
+	}
+	if got := resolveLengthAgainst(Em(2), 300, ctx, 16); got != 32 {
+		t.Errorf("Expected non-percentages to ignore the base, got %.2f", got)
+	}
+}
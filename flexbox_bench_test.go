@@ -0,0 +1,34 @@
+package layout
+
+import (
+	"testing"
+)
+
+// makeWideRow builds a flex row of n fixed-size items.
+func makeWideRow(n int, wrap FlexWrap) *Node {
+	row := &Node{Style: Style{Display: DisplayFlex, FlexWrap: wrap, Width: Px(1000)}}
+	for i := 0; i < n; i++ {
+		row.Children = append(row.Children, &Node{Style: Style{Width: Px(20), Height: Px(10), FlexGrow: 1, FlexShrink: 1}})
+	}
+	return row
+}
+
+func BenchmarkFlexWideRow(b *testing.B) {
+	row := makeWideRow(200, FlexWrapNoWrap)
+	ctx := NewLayoutContext(1000, 800, 16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Layout(row, Loose(1000, 800), ctx)
+	}
+}
+
+func BenchmarkFlexWideRowWrap(b *testing.B) {
+	row := makeWideRow(200, FlexWrapWrap)
+	ctx := NewLayoutContext(1000, 800, 16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Layout(row, Loose(1000, 800), ctx)
+	}
+}
@@ -1,5 +1,38 @@
 package layout
 
+// flexboxResolveHypotheticalCrossSizes lays out again each item in a row
+// whose used main size differs from the size it was measured at, so content
+// that depends on its width (wrapping text, nested blocks) gets the matching
+// height and line boxes. Items with a definite height keep their cross size.
+//
+// Algorithm based on CSS Flexible Box Layout Module Level 1:
+// - §9.4 step 7: Hypothetical cross size, laid out at the used main size
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#algo-cross-item
+func flexboxResolveHypotheticalCrossSizes(line []*flexItem, setup flexboxSetup, ctx *LayoutContext) {
+	if !setup.isMainHorizontal {
+		return
+	}
+	for _, item := range line {
+		if item.mainSize == item.measuredMainSize || item.node.Style.Display == DisplayNone {
+			continue
+		}
+		constraints := Constraints{
+			MinWidth:  item.mainSize,
+			MaxWidth:  item.mainSize,
+			MaxHeight: setup.crossSize,
+		}
+		child := item.node
+		size := cachedLayout(child, constraints, ctx, func() Size {
+			return layoutByDisplay(child, constraints, ctx)
+		})
+		item.measuredMainSize = item.mainSize
+		if !flexItemHasDefiniteSize(child, false) {
+			item.crossSize = size.Height
+		}
+	}
+}
+
 // flexboxDetermineCrossSize determines the cross size of a line.
 //
 // Algorithm based on CSS Flexible Box Layout Module Level 1:
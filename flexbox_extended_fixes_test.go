@@ -49,6 +49,12 @@ func TestFlexboxAlignContentImplementation(t *testing.T) {
 			containerHeight: Px(300),
 			expectedFirstY:  50, // (300 - 100) / 4 = 50 (approximately)
 		},
+		{
+			name:            "space-evenly",
+			alignContent:    AlignContentSpaceEvenly,
+			containerHeight: Px(300),
+			expectedFirstY:  66.67, // (300 - 100) / 3
+		},
 	}
 
 	for _, tt := range tests {
@@ -86,6 +92,49 @@ func TestFlexboxAlignContentImplementation(t *testing.T) {
 	}
 }
 
+// TestFlexboxAlignContentOverflow tests safe and unsafe align-content when
+// the lines are taller than the container
+func TestFlexboxAlignContentOverflow(t *testing.T) {
+	tests := []struct {
+		name           string
+		alignContent   AlignContent
+		overflow       OverflowAlignment
+		expectedFirstY float64
+	}{
+		{"default center keeps start", AlignContentCenter, OverflowAlignmentDefault, 0},
+		{"safe flex-end keeps start", AlignContentFlexEnd, OverflowAlignmentSafe, 0},
+		{"unsafe center overflows both sides", AlignContentCenter, OverflowAlignmentUnsafe, -20},
+		{"unsafe flex-end overflows start", AlignContentFlexEnd, OverflowAlignmentUnsafe, -40},
+		{"unsafe space-evenly falls back to center", AlignContentSpaceEvenly, OverflowAlignmentUnsafe, -20},
+		{"unsafe space-between falls back to start", AlignContentSpaceBetween, OverflowAlignmentUnsafe, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Node{
+				Style: Style{
+					Display:              DisplayFlex,
+					FlexWrap:             FlexWrapWrap,
+					AlignContent:         tt.alignContent,
+					AlignContentOverflow: tt.overflow,
+					Width:                Px(100),
+					Height:               Px(60),
+				},
+				Children: []*Node{
+					{Style: Style{Width: Px(60), Height: Px(50)}},
+					{Style: Style{Width: Px(60), Height: Px(50)}},
+				},
+			}
+
+			LayoutFlexbox(root, Loose(100, 60), NewLayoutContext(1920, 1080, 16))
+
+			if y := root.Children[0].Rect.Y; math.Abs(y-tt.expectedFirstY) > 0.01 {
+				t.Errorf("Expected first child Y %.2f, got %.2f", tt.expectedFirstY, y)
+			}
+		})
+	}
+}
+
 // TestFlexboxFlexDirectionReverse tests flex-direction reverse
 func TestFlexboxFlexDirectionReverse(t *testing.T) {
 	root := &Node{
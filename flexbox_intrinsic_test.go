@@ -0,0 +1,101 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlexTextItemUsesMaxContentWidth(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	label := Text("hello world")
+	row := HStack(label)
+	row.Style.Width = Px(400)
+
+	Layout(row, Loose(600, 400), ctx)
+
+	want := calculateMaxContentWidth(label, Unconstrained(), ctx)
+	if want <= 0 {
+		t.Fatalf("Expected positive max-content width, got %.2f", want)
+	}
+	if math.Abs(label.Rect.Width-want) > 0.01 {
+		t.Errorf("Expected text item width %.2f, got %.2f", want, label.Rect.Width)
+	}
+	if label.Rect.Height <= 0 || len(label.TextLayout.Lines) != 1 {
+		t.Errorf("Expected a single line with positive height, got %.2f and %d lines", label.Rect.Height, len(label.TextLayout.Lines))
+	}
+}
+
+func TestFlexShrunkTextWrapsAndGrowsTaller(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	a := Text("one two three four")
+	b := Text("five six seven eight")
+	row := HStack(a, b)
+	row.Style.Width = Px(200)
+	row.Style.AlignItems = AlignItemsFlexStart
+
+	Layout(row, Loose(600, 400), ctx)
+
+	if math.Abs(a.Rect.Width+b.Rect.Width-200) > 0.01 {
+		t.Errorf("Expected items to shrink to fill 200, got %.2f + %.2f", a.Rect.Width, b.Rect.Width)
+	}
+	for name, n := range map[string]*Node{"a": a, "b": b} {
+		if len(n.TextLayout.Lines) < 2 {
+			t.Errorf("%s: expected text to wrap at its used width, got %d lines", name, len(n.TextLayout.Lines))
+		}
+		for _, line := range n.TextLayout.Lines {
+			if line.Width > n.Rect.Width+0.01 {
+				t.Errorf("%s: line width %.2f exceeds item width %.2f", name, line.Width, n.Rect.Width)
+			}
+		}
+	}
+	lineHeight := a.Rect.Height / float64(len(a.TextLayout.Lines))
+	if a.Rect.Height < 2*lineHeight-0.01 {
+		t.Errorf("Expected shrunk item to be at least two lines tall, got %.2f", a.Rect.Height)
+	}
+}
+
+func TestFlexContainerIntrinsicWidths(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	items := func() []*Node {
+		return []*Node{Text("alpha beta"), Fixed(30, 10), Text("gamma")}
+	}
+	alpha := calculateMaxContentWidth(Text("alpha beta"), Unconstrained(), ctx)
+	alphaMin := calculateMinContentWidth(Text("alpha beta"), Unconstrained(), ctx)
+	gamma := calculateMaxContentWidth(Text("gamma"), Unconstrained(), ctx)
+
+	row := &Node{Style: Style{Display: DisplayFlex, FlexGap: Px(5)}, Children: items()}
+	if got, want := calculateMaxContentWidth(row, Unconstrained(), ctx), alpha+30+gamma+10; math.Abs(got-want) > 0.01 {
+		t.Errorf("Row max-content: expected %.2f, got %.2f", want, got)
+	}
+	if got, want := calculateMinContentWidth(row, Unconstrained(), ctx), alphaMin+30+gamma+10; math.Abs(got-want) > 0.01 {
+		t.Errorf("Row min-content: expected %.2f, got %.2f", want, got)
+	}
+
+	wrapped := &Node{Style: Style{Display: DisplayFlex, FlexWrap: FlexWrapWrap, FlexGap: Px(5)}, Children: items()}
+	if got, want := calculateMinContentWidth(wrapped, Unconstrained(), ctx), math.Max(alphaMin, math.Max(30, gamma)); math.Abs(got-want) > 0.01 {
+		t.Errorf("Wrapping row min-content: expected %.2f, got %.2f", want, got)
+	}
+
+	col := &Node{Style: Style{Display: DisplayFlex, FlexDirection: FlexDirectionColumn}, Children: items()}
+	if got, want := calculateMaxContentWidth(col, Unconstrained(), ctx), math.Max(alpha, math.Max(30, gamma)); math.Abs(got-want) > 0.01 {
+		t.Errorf("Column max-content: expected %.2f, got %.2f", want, got)
+	}
+}
+
+func TestFlexItemIntrinsicWidthContribution(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	item := &Node{Style: Style{
+		Width:   Px(100),
+		Padding: Horizontal(Px(10)),
+		Margin:  Horizontal(Em(1)),
+	}}
+	if got := flexItemIntrinsicWidth(item, IntrinsicSizeMaxContent, ctx); math.Abs(got-152) > 0.01 {
+		t.Errorf("Expected 100 + 20 padding + 32 margin = 152, got %.2f", got)
+	}
+
+	clamped := Text("a fairly long label")
+	clamped.Style.MaxWidth = Px(50)
+	if got := flexItemIntrinsicWidth(clamped, IntrinsicSizeMaxContent, ctx); math.Abs(got-50) > 0.01 {
+		t.Errorf("Expected max-width to clamp contribution to 50, got %.2f", got)
+	}
+}
@@ -1,6 +1,9 @@
 package layout
 
-import "sort"
+import (
+	"math"
+	"sort"
+)
 
 // flexboxMeasureItems measures all children and creates flex items.
 //
@@ -10,27 +13,36 @@ import "sort"
 //
 // See: https://www.w3.org/TR/css-flexbox-1/#line-sizing
 // See: https://www.w3.org/TR/css-flexbox-1/#order-property
-func flexboxMeasureItems(node *Node, setup flexboxSetup, ctx *LayoutContext) []*flexItem {
+func flexboxMeasureItems(node *Node, setup flexboxSetup, buf *flexBuffers, ctx *LayoutContext) []*flexItem {
 	children := node.Children
 
 	// Sort children by order property (CSS Flexbox §5.4.1)
 	// Items with the same order value appear in source order
-	orderedChildren := make([]*Node, len(children))
-	copy(orderedChildren, children)
-	sort.SliceStable(orderedChildren, func(i, j int) bool {
+	orderedChildren := append(buf.children[:0], children...)
+	buf.children = orderedChildren
+	if !sort.SliceIsSorted(orderedChildren, func(i, j int) bool {
 		return orderedChildren[i].Style.Order < orderedChildren[j].Style.Order
-	})
+	}) {
+		sort.SliceStable(orderedChildren, func(i, j int) bool {
+			return orderedChildren[i].Style.Order < orderedChildren[j].Style.Order
+		})
+	}
 
-	flexItems := make([]*flexItem, 0, len(orderedChildren))
+	// The items are taken from buf; it's sized up front so they don't
+	// move while the list refers to them
+	if cap(buf.items) < len(orderedChildren) {
+		buf.items = make([]flexItem, len(orderedChildren))
+	}
+	buf.items = buf.items[:len(orderedChildren)]
+	flexItems := buf.itemPtrs[:0]
 
 	for _, child := range orderedChildren {
 		// Skip display:none children
 		if child.Style.Display == DisplayNone {
 			continue
 		}
-		item := &flexItem{
-			node: child,
-		}
+		item := &buf.items[len(flexItems)]
+		*item = flexItem{node: child}
 
 		// Get current font size for child's Length resolution
 		childFontSize := getCurrentFontSize(child, ctx)
@@ -63,6 +75,23 @@ func flexboxMeasureItems(node *Node, setup flexboxSetup, ctx *LayoutContext) []*
 		item.crossMarginStart = childCrossMarginStart
 		item.crossMarginEnd = childCrossMarginEnd
 
+		// Record auto margins in the same logical order
+		margin := child.Style.Margin
+		if setup.isMainHorizontal {
+			item.mainMarginStartAuto = margin.Left.Unit == AutoUnit
+			item.mainMarginEndAuto = margin.Right.Unit == AutoUnit
+			if setup.writingMode.IsRightToLeft() {
+				item.mainMarginStartAuto, item.mainMarginEndAuto = item.mainMarginEndAuto, item.mainMarginStartAuto
+			}
+			item.crossMarginStartAuto = margin.Top.Unit == AutoUnit
+			item.crossMarginEndAuto = margin.Bottom.Unit == AutoUnit
+		} else {
+			item.mainMarginStartAuto = margin.Top.Unit == AutoUnit
+			item.mainMarginEndAuto = margin.Bottom.Unit == AutoUnit
+			item.crossMarginStartAuto = margin.Left.Unit == AutoUnit
+			item.crossMarginEndAuto = margin.Right.Unit == AutoUnit
+		}
+
 		// Determine child constraints (account for margins)
 		childMainSize := setup.mainSize
 		childCrossSize := setup.crossSize
@@ -81,15 +110,12 @@ func flexboxMeasureItems(node *Node, setup flexboxSetup, ctx *LayoutContext) []*
 			childConstraints.MaxWidth, childConstraints.MaxHeight = childConstraints.MaxHeight, childConstraints.MaxWidth
 		}
 
-		// Measure child
-		var childSize Size
-		if child.Style.Display == DisplayFlex {
-			childSize = LayoutFlexbox(child, childConstraints, ctx)
-		} else if child.Style.Display == DisplayGrid {
-			childSize = LayoutGrid(child, childConstraints, ctx)
-		} else {
-			childSize = LayoutBlock(child, childConstraints, ctx)
-		}
+		// Measure child. Text items are measured by LayoutText, so in a
+		// single-line row (unbounded main size) they report their max-content
+		// width and the height of one line.
+		childSize := cachedLayout(child, childConstraints, ctx, func() Size {
+			return layoutByDisplay(child, childConstraints, ctx)
+		})
 
 		if setup.isMainHorizontal {
 			item.mainSize = childSize.Width
@@ -114,8 +140,29 @@ func flexboxMeasureItems(node *Node, setup flexboxSetup, ctx *LayoutContext) []*
 			}
 		}
 
+		// An item with an aspect ratio and an auto main size takes its main
+		// size from a definite cross size through the ratio. Without one,
+		// it starts from its content size; the ratio is applied again once
+		// the line is stretched (see flexboxAlignmentCrossAxis).
+		ratioMainSize, hasRatioMainSize := 0.0, false
+		if child.Style.AspectRatio > 0 && !flexItemHasDefiniteSize(child, setup.isMainHorizontal) {
+			if cross, ok := flexItemDefiniteCrossSize(node, child, item, setup, ctx); ok {
+				ratioMainSize = flexItemRatioMainSize(child, setup.isMainHorizontal, cross, ctx)
+				hasRatioMainSize = true
+				item.crossSize = cross
+				item.mainSize = ratioMainSize
+				item.crossSizeDefinite = true
+			} else {
+				probe := *child
+				probe.Style.AspectRatio = 0
+				item.mainSize = flexContentBasis(&probe, setup.isMainHorizontal, childCrossSize, ctx)
+				item.crossSize = flexItemRatioCrossSize(child, setup.isMainHorizontal, item.mainSize)
+			}
+		}
+
 		// Store the measured size as a fallback
 		measuredMainSize := item.mainSize
+		item.measuredMainSize = measuredMainSize
 
 		// Get flex properties
 		item.flexGrow = child.Style.FlexGrow
@@ -126,32 +173,171 @@ func flexboxMeasureItems(node *Node, setup flexboxSetup, ctx *LayoutContext) []*
 		if item.flexShrink == 0 {
 			item.flexShrink = 1 // Default shrink factor
 		}
-		item.flexBasis = ResolveLength(child.Style.FlexBasis, ctx, childFontSize)
-		if item.flexBasis < 0 {
-			item.flexBasis = item.mainSize // auto means use main size
+		// Resolve the flex base size (CSS Flexbox §9.2 step 3)
+		// A percentage of an indefinite main size behaves as content.
+		basis := child.Style.FlexBasis
+		if basis.Kind == FlexBasisLength && lengthHasPercent(basis.Length) &&
+			(!setup.hasExplicitMainSize || setup.mainSize >= Unbounded) {
+			basis = BasisContent()
 		}
+		switch {
+		case basis.Kind == FlexBasisLength && basis.Length.Value >= 0:
+			item.flexBasis = resolveLengthAgainst(basis.Length, setup.mainSize, ctx, childFontSize)
+		case basis.Kind == FlexBasisContent:
+			item.flexBasis = flexContentBasis(child, setup.isMainHorizontal, childCrossSize, ctx)
+		default:
+			// auto: use the measured main size, which honors Width/Height
+			item.flexBasis = item.mainSize
 
-		item.baseSize = item.flexBasis
-
-		// Ensure baseSize is never 0 if we have a measured size or explicit width/height
-		if item.baseSize == 0 {
-			if measuredMainSize > 0 {
-				item.baseSize = measuredMainSize
-				item.flexBasis = measuredMainSize
-			} else if setup.isMainHorizontal && child.Style.Width.Value >= 0 {
-				// Use explicit width for baseSize
-				resolvedWidth := ResolveLength(child.Style.Width, ctx, childFontSize)
-				item.baseSize = resolvedWidth
-				item.flexBasis = resolvedWidth
-			} else if !setup.isMainHorizontal && child.Style.Height.Value >= 0 {
-				// Use explicit height for baseSize
-				resolvedHeight := ResolveLength(child.Style.Height, ctx, childFontSize)
-				item.baseSize = resolvedHeight
-				item.flexBasis = resolvedHeight
+			// Ensure the basis is never 0 if we have a measured size or explicit width/height
+			if item.flexBasis == 0 {
+				if measuredMainSize > 0 {
+					item.flexBasis = measuredMainSize
+				} else if setup.isMainHorizontal && child.Style.Width.Value >= 0 {
+					item.flexBasis = ResolveLength(child.Style.Width, ctx, childFontSize)
+				} else if !setup.isMainHorizontal && child.Style.Height.Value >= 0 {
+					item.flexBasis = ResolveLength(child.Style.Height, ctx, childFontSize)
+				}
 			}
 		}
+		if hasRatioMainSize && basis.Kind != FlexBasisLength {
+			item.flexBasis = ratioMainSize
+		}
+		item.baseSize = item.flexBasis
+		item.minMainSize, item.maxMainSize = flexItemMainSizeLimits(child, setup.isMainHorizontal, childCrossSize, ctx)
+		if hasRatioMainSize && flexItemMinLength(child, setup.isMainHorizontal).Unit == "" {
+			// CSS Box Sizing Level 4 §5.1: the min-content size of a box with
+			// an aspect ratio and a definite cross size is the transferred
+			// size, so the automatic minimum keeps the ratio.
+			item.minMainSize = math.Min(math.Max(item.minMainSize, ratioMainSize), item.maxMainSize)
+		}
 		flexItems = append(flexItems, item)
 	}
 
+	buf.itemPtrs = flexItems
 	return flexItems
 }
+
+// flexItemDefiniteCrossSize returns the border-box cross size of an item
+// when it is definite before the main size is resolved: an explicit cross
+// size, or, for a stretched item in a single-line container with a definite
+// cross size, the container's inner cross size minus the item's margins.
+//
+// Algorithm based on CSS Flexible Box Layout Module Level 1:
+// - §9.2 step 3B: Flex base size from a definite cross size and aspect ratio
+// - §9.8: Definite and Indefinite Sizes
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#definite-sizes
+func flexItemDefiniteCrossSize(node, child *Node, item *flexItem, setup flexboxSetup, ctx *LayoutContext) (float64, bool) {
+	if flexItemHasDefiniteSize(child, !setup.isMainHorizontal) {
+		return item.crossSize, true
+	}
+	alignItems := node.Style.AlignItems
+	if alignItems == 0 {
+		alignItems = AlignItemsStretch
+	}
+	stretched := flexItemAlignment(child, alignItems) == AlignItemsStretch &&
+		!item.crossMarginStartAuto && !item.crossMarginEndAuto
+	if !stretched || node.Style.FlexWrap != FlexWrapNoWrap || !setup.hasExplicitCrossSize || setup.crossSize >= Unbounded {
+		return 0, false
+	}
+	cross := setup.crossSize - item.crossMarginStart - item.crossMarginEnd
+	return math.Max(0, flexItemClampSize(child, !setup.isMainHorizontal, cross, ctx)), true
+}
+
+// flexItemRatioMainSize transfers a border-box cross size to the main axis
+// through child's aspect ratio (width / height), clamped to its main-axis
+// min and max sizes.
+func flexItemRatioMainSize(child *Node, isMainHorizontal bool, crossSize float64, ctx *LayoutContext) float64 {
+	if isMainHorizontal {
+		return flexItemClampSize(child, true, crossSize*child.Style.AspectRatio, ctx)
+	}
+	return flexItemClampSize(child, false, crossSize/child.Style.AspectRatio, ctx)
+}
+
+// flexItemRatioCrossSize transfers a main size to the cross axis through
+// child's aspect ratio.
+func flexItemRatioCrossSize(child *Node, isMainHorizontal bool, mainSize float64) float64 {
+	if isMainHorizontal {
+		return mainSize / child.Style.AspectRatio
+	}
+	return mainSize * child.Style.AspectRatio
+}
+
+// flexItemMinLength returns child's MinWidth (horizontal) or MinHeight.
+func flexItemMinLength(child *Node, horizontal bool) Length {
+	if horizontal {
+		return child.Style.MinWidth
+	}
+	return child.Style.MinHeight
+}
+
+// flexContentBasis returns child's max-content size along the main axis,
+// ignoring its Width/Height, for flex-basis: content. A column item is laid
+// out at the available cross size with an auto height to find its content
+// height. The child itself is not modified; layout runs on a shallow copy.
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#valdef-flex-basis-content
+func flexContentBasis(child *Node, isMainHorizontal bool, crossSize float64, ctx *LayoutContext) float64 {
+	if isMainHorizontal {
+		return calculateMaxContentWidth(child, Unconstrained(), ctx)
+	}
+	probe := *child
+	probe.Style.Height = Px(-1)
+	probe.Style.HeightSizing = IntrinsicSizeNone
+	return layoutByDisplay(&probe, Constraints{MaxWidth: crossSize, MaxHeight: Unbounded}, ctx).Height
+}
+
+// flexItemMainSizeLimits returns child's border-box min and max size along
+// the main axis. An unset MinWidth/MinHeight (the zero Length, CSS auto)
+// resolves to the automatic minimum size: the item's min-content size, capped
+// by its specified size and its max size, so long words and nested content
+// don't overflow a shrinking item. Set MinWidth/MinHeight to Px(0) to opt out
+// and let the item shrink below its content. Scroll containers have no
+// automatic minimum, since their content scrolls instead.
+//
+// Algorithm based on CSS Flexible Box Layout Module Level 1:
+// - §4.5: Automatic Minimum Size of Flex Items
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#min-size-auto
+func flexItemMainSizeLimits(child *Node, horizontal bool, crossSize float64, ctx *LayoutContext) (minSize, maxSize float64) {
+	fontSize := getCurrentFontSize(child, ctx)
+	minL, maxL, size := child.Style.MinHeight, child.Style.MaxHeight, child.Style.Height
+	paddingBorder := getVerticalPaddingBorder(child.Style.Padding, child.Style.Border, ctx, fontSize)
+	if horizontal {
+		minL, maxL, size = child.Style.MinWidth, child.Style.MaxWidth, child.Style.Width
+		paddingBorder = getHorizontalPaddingBorder(child.Style.Padding, child.Style.Border, ctx, fontSize)
+	}
+	if child.Style.BoxSizing == BoxSizingBorderBox {
+		paddingBorder = 0
+	}
+
+	maxSize = Unbounded
+	if resolved := ResolveLength(maxL, ctx, fontSize); resolved > 0 && resolved < Unbounded {
+		maxSize = resolved + paddingBorder
+	}
+
+	if minL.Unit != "" {
+		if resolved := ResolveLength(minL, ctx, fontSize); resolved > 0 {
+			minSize = resolved + paddingBorder
+		}
+		return minSize, maxSize
+	}
+
+	// Scroll containers have no automatic minimum (§4.5)
+	if child.IsScrollContainer() {
+		return 0, maxSize
+	}
+
+	// Automatic minimum: the content size suggestion, capped by the
+	// specified size suggestion and the max size
+	if horizontal {
+		minSize = calculateMinContentWidth(child, Unconstrained(), ctx)
+	} else {
+		minSize = flexContentBasis(child, false, crossSize, ctx)
+	}
+	if flexItemHasDefiniteSize(child, horizontal) {
+		minSize = math.Min(minSize, ResolveLength(size, ctx, fontSize)+paddingBorder)
+	}
+	return math.Min(minSize, maxSize), maxSize
+}
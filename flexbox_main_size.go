@@ -6,64 +6,107 @@ import (
 
 // flexboxDetermineMainSize determines the main size of items in a line using flex grow/shrink.
 //
+// Items are clamped to their min/max main sizes (including the automatic
+// minimum size). When clamping an item changes the space left for the
+// others, the clamped items are frozen and the free space is distributed
+// again among the rest.
+//
 // Algorithm based on CSS Flexible Box Layout Module Level 1:
 // - §9.3: Main Size Determination
-//   - §9.3.1: Initial free space calculation
-//   - §9.3.2: Flex grow factor distribution
-//   - §9.3.3: Flex shrink factor distribution
+// - §9.7: Resolving Flexible Lengths
+//   - Step 1: Grow or shrink, from the sum of hypothetical main sizes
+//   - Step 2: Freeze inflexible items
+//   - Step 4: Distribute free space, fix min/max violations, and freeze
 //
 // See: https://www.w3.org/TR/css-flexbox-1/#main-sizing
-func flexboxDetermineMainSize(line []*flexItem, mainSize float64, hasExplicitMainSize bool) {
-	// Calculate total flex grow and shrink
-	totalFlexGrow := 0.0
-	totalFlexShrink := 0.0
-	for _, item := range line {
-		totalFlexGrow += item.flexGrow
-		totalFlexShrink += item.flexShrink
+// See: https://www.w3.org/TR/css-flexbox-1/#resolve-flexible-lengths
+func flexboxDetermineMainSize(line []*flexItem, mainSize float64, hasExplicitMainSize bool, buf *flexBuffers) {
+	// Indefinite main size (auto-sized flex container): don't flex in the main axis.
+	// Keep items at their hypothetical main size so the container grows to fit content.
+	if !hasExplicitMainSize {
+		for _, item := range line {
+			item.mainSize = item.clampMainSize(item.baseSize)
+		}
+		return
 	}
 
-	// Calculate free space (including margins)
 	usedMainSize := 0.0
 	for _, item := range line {
-		usedMainSize += item.baseSize + item.mainMarginStart + item.mainMarginEnd
+		usedMainSize += item.clampMainSize(item.baseSize) + item.mainMarginStart + item.mainMarginEnd
+	}
+	growing := mainSize > usedMainSize
+	factor := func(item *flexItem) float64 {
+		if growing {
+			return item.flexGrow
+		}
+		return item.flexShrink
+	}
+
+	// Freeze inflexible items at their hypothetical main size
+	frozen := bools(buf.frozen, len(line))
+	buf.frozen = frozen
+	for i, item := range line {
+		item.mainSize = item.clampMainSize(item.baseSize)
+		if factor(item) == 0 ||
+			(growing && item.baseSize > item.mainSize) ||
+			(!growing && item.baseSize < item.mainSize) {
+			frozen[i] = true
+		}
 	}
-	freeSpace := mainSize - usedMainSize
 
-	// Distribute free space only if the container has a definite main size
-	// If main size is indefinite (auto), don't flex - keep items at base size so container grows to fit content
-	if hasExplicitMainSize {
-		// Only run the flex grow/shrink algorithm if the container's main size is definite.
-		if freeSpace > 0 && totalFlexGrow > 0 {
-			// Grow items
-			for _, item := range line {
-				if item.flexGrow > 0 {
-					growAmount := (freeSpace * item.flexGrow) / totalFlexGrow
-					item.mainSize = item.baseSize + growAmount
-				} else {
-					item.mainSize = item.baseSize
-				}
+	violations := floats(buf.violations, len(line))
+	buf.violations = violations
+	for {
+		freeSpace := mainSize
+		totalFactor := 0.0
+		unfrozen := 0
+		for i, item := range line {
+			freeSpace -= item.mainMarginStart + item.mainMarginEnd
+			if frozen[i] {
+				freeSpace -= item.mainSize
+			} else {
+				freeSpace -= item.baseSize
+				totalFactor += factor(item)
+				unfrozen++
 			}
-		} else if freeSpace < 0 && totalFlexShrink > 0 {
-			// Shrink items
-			for _, item := range line {
-				if item.flexShrink > 0 {
-					shrinkAmount := (-freeSpace * item.flexShrink) / totalFlexShrink
-					item.mainSize = math.Max(0, item.baseSize-shrinkAmount)
-				} else {
-					item.mainSize = item.baseSize
-				}
+		}
+		if unfrozen == 0 {
+			return
+		}
+
+		totalViolation := 0.0
+		for i, item := range line {
+			if frozen[i] {
+				continue
 			}
-		} else {
-			// No free space or no flex factors: use base size as-is
-			for _, item := range line {
-				item.mainSize = item.baseSize
+			target := item.baseSize
+			if growing && freeSpace > 0 {
+				target += freeSpace * item.flexGrow / totalFactor
+			} else if !growing && freeSpace < 0 {
+				target = math.Max(0, target+freeSpace*item.flexShrink/totalFactor)
 			}
+			item.mainSize = item.clampMainSize(target)
+			violations[i] = item.mainSize - target
+			totalViolation += violations[i]
 		}
-	} else {
-		// Indefinite main size (auto-sized flex container): don't flex in the main axis.
-		// Keep mainSize = baseSize so the container grows to fit content.
-		for _, item := range line {
-			item.mainSize = item.baseSize
+
+		// Freeze min violations if the total is positive, max violations if
+		// negative, or everything if the sizes fit
+		for i := range line {
+			if frozen[i] {
+				continue
+			}
+			if totalViolation == 0 ||
+				(totalViolation > 0 && violations[i] > 0) ||
+				(totalViolation < 0 && violations[i] < 0) {
+				frozen[i] = true
+			}
 		}
 	}
 }
+
+// clampMainSize clamps a border-box main size to the item's min/max main
+// size. The min size wins when they conflict.
+func (item *flexItem) clampMainSize(size float64) float64 {
+	return math.Max(item.minMainSize, math.Min(size, item.maxMainSize))
+}
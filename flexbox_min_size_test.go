@@ -0,0 +1,94 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFlexAutoMinSizeKeepsLongWord(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	word := Text("supercalifragilistic")
+	box := Fixed(150, 20)
+	row := HStack(word, box)
+	row.Style.Width = Px(200)
+
+	Layout(row, Loose(600, 400), ctx)
+
+	minContent := calculateMinContentWidth(word, Unconstrained(), ctx)
+	if math.Abs(word.Rect.Width-minContent) > 0.01 {
+		t.Errorf("Expected word to stop shrinking at its min-content width %.2f, got %.2f", minContent, word.Rect.Width)
+	}
+	// The box takes all of the remaining overflow
+	if want := math.Max(0, 200-minContent); math.Abs(box.Rect.Width-want) > 0.01 {
+		t.Errorf("Expected box to shrink to %.2f, got %.2f", want, box.Rect.Width)
+	}
+}
+
+func TestFlexMinWidthZeroOptsOut(t *testing.T) {
+	ctx := NewLayoutContext(600, 400, 16)
+	word := Text("supercalifragilistic")
+	word.Style.MinWidth = Px(0)
+	box := Fixed(150, 20)
+	row := HStack(word, box)
+	row.Style.Width = Px(200)
+
+	Layout(row, Loose(600, 400), ctx)
+
+	// Both items shrink by the same amount (flex-shrink 1)
+	basis := calculateMaxContentWidth(word, Unconstrained(), ctx)
+	overflow := basis + 150 - 200
+	if want := basis - overflow/2; math.Abs(word.Rect.Width-want) > 0.01 {
+		t.Errorf("Expected word to shrink below its content to %.2f, got %.2f", want, word.Rect.Width)
+	}
+}
+
+func TestFlexAutoMinSizeCappedBySpecifiedSize(t *testing.T) {
+	// A definite width smaller than the content wins over min-content
+	item := &Node{
+		Style:    Style{Width: Px(40), Height: Px(20)},
+		Children: []*Node{Fixed(100, 20)},
+	}
+	row := HStack(item, Fixed(200, 20))
+	row.Style.Width = Px(200)
+
+	Layout(row, Loose(600, 400), NewLayoutContext(600, 400, 16))
+
+	if item.Rect.Width > 40.01 {
+		t.Errorf("Expected item to shrink from its specified width 40, got %.2f", item.Rect.Width)
+	}
+}
+
+func TestFlexAutoMinSizeColumn(t *testing.T) {
+	item := &Node{Style: Style{Width: Px(50), Height: Px(-1)}, Children: []*Node{Fixed(50, 80)}}
+	spacer := Fixed(50, 100)
+	col := VStack(item, spacer)
+	col.Style.Height = Px(100)
+
+	Layout(col, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if math.Abs(item.Rect.Height-80) > 0.01 {
+		t.Errorf("Expected item to keep its content height 80, got %.2f", item.Rect.Height)
+	}
+	if math.Abs(spacer.Rect.Height-20) > 0.01 {
+		t.Errorf("Expected spacer to absorb the overflow and shrink to 20, got %.2f", spacer.Rect.Height)
+	}
+}
+
+func TestFlexMaxWidthRedistributesGrowth(t *testing.T) {
+	capped := Fixed(0, 20)
+	capped.Style.FlexGrow = 1
+	capped.Style.MaxWidth = Px(50)
+	free := Fixed(0, 20)
+	free.Style.FlexGrow = 1
+	row := HStack(capped, free)
+	row.Style.Width = Px(300)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(capped.Rect.Width-50) > 0.01 {
+		t.Errorf("Expected capped item at max-width 50, got %.2f", capped.Rect.Width)
+	}
+	if math.Abs(free.Rect.Width-250) > 0.01 {
+		t.Errorf("Expected remaining item to take the rest (250), got %.2f", free.Rect.Width)
+	}
+}
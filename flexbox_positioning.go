@@ -1,5 +1,7 @@
 package layout
 
+import "math"
+
 // flexboxAlignmentMainAxis positions items along the main axis using justify-content.
 //
 // Algorithm based on CSS Flexible Box Layout Module Level 1:
@@ -84,6 +86,10 @@ func flexboxAlignmentMainAxis(
 		}
 	}
 
+	// §8.1: auto margins absorb positive free space before justify-content,
+	// which then has nothing left to distribute.
+	flexboxResolveAutoMainMargins(line, mainSize, columnGap)
+
 	// Calculate content area start offset (accounting for padding and border)
 	contentAreaStart := 0.0
 	if setup.isMainHorizontal {
@@ -138,6 +144,41 @@ func flexboxAlignmentMainAxis(
 	return lineMainSize
 }
 
+// flexboxResolveAutoMainMargins distributes a line's positive free space
+// equally among its auto main-axis margins. With no free space (or an
+// indefinite main size) auto margins stay 0.
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#auto-margins
+func flexboxResolveAutoMainMargins(line []*flexItem, mainSize, gap float64) {
+	if mainSize >= Unbounded {
+		return
+	}
+	autoMargins := 0
+	usedSpace := gap * float64(len(line)-1)
+	for _, item := range line {
+		usedSpace += item.mainSize + item.mainMarginStart + item.mainMarginEnd
+		if item.mainMarginStartAuto {
+			autoMargins++
+		}
+		if item.mainMarginEndAuto {
+			autoMargins++
+		}
+	}
+	freeSpace := mainSize - usedSpace
+	if autoMargins == 0 || freeSpace <= 0 {
+		return
+	}
+	share := freeSpace / float64(autoMargins)
+	for _, item := range line {
+		if item.mainMarginStartAuto {
+			item.mainMarginStart += share
+		}
+		if item.mainMarginEndAuto {
+			item.mainMarginEnd += share
+		}
+	}
+}
+
 // flexboxAlignmentCrossAxis positions items along the cross axis using align-items.
 //
 // Algorithm based on CSS Flexible Box Layout Module Level 1:
@@ -162,11 +203,7 @@ func flexboxAlignmentCrossAxis(
 	var maxBaseline float64 = 0.0
 	hasBaseline := false
 	for _, item := range line {
-		itemAlign := alignItems
-		if item.node.Style.AlignSelf != 0 {
-			itemAlign = item.node.Style.AlignSelf
-		}
-		if itemAlign == AlignItemsBaseline {
+		if flexItemAlignment(item.node, alignItems) == AlignItemsBaseline {
 			hasBaseline = true
 			break
 		}
@@ -174,11 +211,7 @@ func flexboxAlignmentCrossAxis(
 
 	if hasBaseline {
 		for _, item := range line {
-			itemAlign := alignItems
-			if item.node.Style.AlignSelf != 0 {
-				itemAlign = item.node.Style.AlignSelf
-			}
-			if itemAlign == AlignItemsBaseline {
+			if flexItemAlignment(item.node, alignItems) == AlignItemsBaseline {
 				// Get baseline for this item
 				// If node.Baseline is 0 (not set), use the item's cross size as fallback
 				baseline := item.node.Baseline
@@ -197,10 +230,7 @@ func flexboxAlignmentCrossAxis(
 
 	for _, item := range line {
 		// Check for per-item alignment override (CSS Flexbox §8.3)
-		itemAlign := alignItems
-		if item.node.Style.AlignSelf != 0 {
-			itemAlign = item.node.Style.AlignSelf
-		}
+		itemAlign := flexItemAlignment(item.node, alignItems)
 		// Set initial rect dimensions
 		// For main axis horizontal: mainSize=width, crossSize=height
 		// For main axis vertical: mainSize=height, crossSize=width
@@ -215,21 +245,33 @@ func flexboxAlignmentCrossAxis(
 
 		// Apply align-self/align-items stretch if needed (for cross-size)
 		// Use lineCrossSize consistently - it already accounts for single-line stretch
-		if itemAlign == AlignItemsStretch {
+		// §9.4 step 11: only items whose cross size is auto (and whose
+		// cross-axis margins are not auto) are stretched; an explicit cross
+		// size is kept and the item is aligned at the start.
+		hasAutoCrossMargin := item.crossMarginStartAuto || item.crossMarginEndAuto
+		if itemAlign == AlignItemsStretch && !hasAutoCrossMargin && !flexItemHasDefiniteSize(item.node, !setup.isMainHorizontal) {
+			stretched := lineCrossSize - item.crossMarginStart - item.crossMarginEnd
+			stretched = flexItemClampSize(item.node, !setup.isMainHorizontal, stretched, ctx)
+			if stretched < 0 {
+				stretched = 0
+			}
+			item.crossSize = stretched
+
+			// The stretched cross size is definite, so an aspect-ratio item
+			// with an auto main size re-derives its main size from it
+			// (CSS Box Sizing Level 4 §5.1 ratio transfer). Items that grew,
+			// or that already resolved their main size from a definite cross
+			// size, keep the size the flex algorithm gave them.
+			if item.node.Style.AspectRatio > 0 && item.flexGrow == 0 && !item.crossSizeDefinite && !flexItemHasDefiniteSize(item.node, setup.isMainHorizontal) {
+				item.mainSize = flexItemRatioMainSize(item.node, setup.isMainHorizontal, stretched, ctx)
+			}
+
 			if setup.isMainHorizontal {
-				// For main axis horizontal, cross-size is height
-				rectHeight = lineCrossSize - item.crossMarginStart - item.crossMarginEnd
-				if rectHeight < 0 {
-					rectHeight = 0
-				}
-				item.crossSize = rectHeight
+				rectWidth = item.mainSize
+				rectHeight = item.crossSize
 			} else {
-				// For main axis vertical, cross-size is width
-				rectWidth = lineCrossSize - item.crossMarginStart - item.crossMarginEnd
-				if rectWidth < 0 {
-					rectWidth = 0
-				}
-				item.crossSize = rectWidth
+				rectWidth = item.crossSize
+				rectHeight = item.mainSize
 			}
 		}
 
@@ -259,6 +301,20 @@ func flexboxAlignmentCrossAxis(
 			crossOffset = item.crossMarginStart
 		}
 
+		// §9.6 step 13: auto cross-axis margins absorb positive free space
+		// and take precedence over align-self.
+		if hasAutoCrossMargin {
+			crossOffset = item.crossMarginStart
+			if freeSpace := alignmentCrossSize - itemCrossSizeWithMargins; freeSpace > 0 {
+				switch {
+				case item.crossMarginStartAuto && item.crossMarginEndAuto:
+					crossOffset += freeSpace / 2
+				case item.crossMarginStartAuto:
+					crossOffset += freeSpace
+				}
+			}
+		}
+
 		// Get parent font size for Length resolution
 		parentFontSize := getCurrentFontSize(node, ctx)
 
@@ -272,3 +328,55 @@ func flexboxAlignmentCrossAxis(
 		}
 	}
 }
+
+// flexItemAlignment resolves an item's cross-axis alignment: align-self if
+// set, otherwise the container's align-items. An explicit stretch is the same
+// as the default stretch in flexbox.
+func flexItemAlignment(n *Node, alignItems AlignItems) AlignItems {
+	itemAlign := alignItems
+	if n.Style.AlignSelf != 0 {
+		itemAlign = n.Style.AlignSelf
+	}
+	if itemAlign == AlignItemsStretchExplicit {
+		itemAlign = AlignItemsStretch
+	}
+	return itemAlign
+}
+
+// flexItemHasDefiniteSize reports whether a flex item has an explicit
+// (non-auto) width (horizontal) or height (!horizontal).
+//
+// Zero and negative values are treated as auto: Px(0) is the conventional
+// "auto" in this package, and negative values are intrinsic sizing sentinels.
+// A percentage that the container hasn't resolved yet is auto too.
+func flexItemHasDefiniteSize(n *Node, horizontal bool) bool {
+	l := n.Style.Height
+	sizing := n.Style.HeightSizing
+	if horizontal {
+		l = n.Style.Width
+		sizing = n.Style.WidthSizing
+	}
+	return l.Unit != "" && !lengthHasPercent(l) && l.Value > 0 && sizing == IntrinsicSizeNone
+}
+
+// flexItemClampSize clamps a border-box size to the item's min/max width
+// (horizontal) or height (!horizontal), honoring box-sizing.
+func flexItemClampSize(n *Node, horizontal bool, size float64, ctx *LayoutContext) float64 {
+	fontSize := getCurrentFontSize(n, ctx)
+	minL, maxL := n.Style.MinHeight, n.Style.MaxHeight
+	paddingBorder := getVerticalPaddingBorder(n.Style.Padding, n.Style.Border, ctx, fontSize)
+	if horizontal {
+		minL, maxL = n.Style.MinWidth, n.Style.MaxWidth
+		paddingBorder = getHorizontalPaddingBorder(n.Style.Padding, n.Style.Border, ctx, fontSize)
+	}
+	if n.Style.BoxSizing == BoxSizingBorderBox {
+		paddingBorder = 0
+	}
+	if maxSize := ResolveLength(maxL, ctx, fontSize); maxSize > 0 && maxSize < Unbounded {
+		size = math.Min(size, maxSize+paddingBorder)
+	}
+	if minSize := ResolveLength(minL, ctx, fontSize); minSize > 0 {
+		size = math.Max(size, minSize+paddingBorder)
+	}
+	return size
+}
@@ -0,0 +1,97 @@
+package layout
+
+import "sync"
+
+// flexBuffers are the working slices of one flex container's layout:
+// its items, the lines they're broken into and per-line and per-item
+// numbers. They're reused from container to container and from layout to
+// layout (see flexScratch), so a wide row doesn't allocate them anew each
+// time it's laid out.
+type flexBuffers struct {
+	items      []flexItem
+	itemPtrs   []*flexItem
+	children   []*Node
+	lines      [][]*flexItem
+	lineSizes  []float64 // Line cross sizes
+	offsets    []float64 // Line offsets
+	frozen     []bool    // Items frozen while resolving flexible lengths
+	violations []float64 // Min/max violations while resolving flexible lengths
+}
+
+// flexScratch holds flexBuffers for a layout. Flex containers are laid
+// out while their ancestors' buffers are still in use, so there's one set
+// per nesting depth, taken on the way down and released on the way up.
+type flexScratch struct {
+	depth  int
+	frames []*flexBuffers
+}
+
+var flexScratchPool = sync.Pool{
+	New: func() any { return &flexScratch{} },
+}
+
+// withFlexScratch returns a copy of ctx that lends reusable flex buffers
+// to the containers of a layout, and a function that returns them when the
+// layout is done. A nil ctx is returned as is.
+func (ctx *LayoutContext) withFlexScratch() (*LayoutContext, func()) {
+	if ctx == nil || ctx.flexScratch != nil {
+		return ctx, func() {}
+	}
+	scratch := flexScratchPool.Get().(*flexScratch)
+	copy := *ctx
+	copy.flexScratch = scratch
+	return &copy, func() {
+		copy.flexScratch = nil
+		flexScratchPool.Put(scratch)
+	}
+}
+
+// takeFlexBuffers returns buffers for a flex container and a function
+// that releases them. Without scratch in ctx the buffers are new.
+func takeFlexBuffers(ctx *LayoutContext) (*flexBuffers, func()) {
+	if ctx == nil || ctx.flexScratch == nil {
+		return &flexBuffers{}, func() {}
+	}
+	s := ctx.flexScratch
+	if s.depth == len(s.frames) {
+		s.frames = append(s.frames, &flexBuffers{})
+	}
+	b := s.frames[s.depth]
+	s.depth++
+	return b, func() {
+		s.depth--
+		b.reset()
+	}
+}
+
+// reset empties b, keeping its capacity but not the nodes it pointed to.
+func (b *flexBuffers) reset() {
+	clear(b.items)
+	clear(b.itemPtrs)
+	clear(b.children)
+	clear(b.lines)
+	b.items = b.items[:0]
+	b.itemPtrs = b.itemPtrs[:0]
+	b.children = b.children[:0]
+	b.lines = b.lines[:0]
+}
+
+// floats returns buf resized to n zeroes.
+func floats(buf []float64, n int) []float64 {
+	if cap(buf) < n {
+		return make([]float64, n)
+	}
+	buf = buf[:n]
+	clear(buf)
+	return buf
+}
+
+// bools returns buf resized to n falses.
+func bools(buf []bool, n int) []bool {
+	if cap(buf) < n {
+		return make([]bool, n)
+	}
+	buf = buf[:n]
+	clear(buf)
+	return buf
+}
@@ -345,3 +345,39 @@ func TestFlexboxNested(t *testing.T) {
 		t.Errorf("Expected 2 children in nested container, got %d", len(root.Children[0].Children))
 	}
 }
+
+func TestFlexboxEmptyContainerSize(t *testing.T) {
+	// An empty flex container keeps its specified size
+	node := &Node{Style: Style{Display: DisplayFlex, Width: Px(100), Height: Px(30), Padding: Uniform(Px(5))}}
+	size := LayoutFlexbox(node, Loose(Unbounded, Unbounded), NewLayoutContext(800, 600, 16))
+	if size != (Size{Width: 110, Height: 40}) || node.Rect.Width != 110 || node.Rect.Height != 40 {
+		t.Errorf("Expected 110x40, got size %v, rect %v", size, node.Rect)
+	}
+
+	// or the definite size its constraints resolve to
+	row := &Node{Style: Style{Display: DisplayFlex, Padding: Uniform(Px(5))}}
+	size = LayoutFlexbox(row, Loose(200, 150), NewLayoutContext(800, 600, 16))
+	if size != (Size{Width: 200, Height: 150}) || row.Rect.Width != 200 || row.Rect.Height != 150 {
+		t.Errorf("Expected 200x150, got size %v, rect %v", size, row.Rect)
+	}
+	size = LayoutFlexbox(row, Loose(200, Unbounded), NewLayoutContext(800, 600, 16))
+	if size != (Size{Width: 200, Height: 10}) {
+		t.Errorf("Expected 200x10 without a definite height, got %v", size)
+	}
+
+	// and fills the size its parent gives it, like a growing item
+	root := &Node{
+		Style: Style{Display: DisplayFlex, Width: Px(100), Height: Px(100)},
+		Children: []*Node{
+			{Style: Style{Display: DisplayFlex, FlexGrow: 1}},
+			{Style: Style{Display: DisplayFlex, FlexGrow: 1}},
+		},
+	}
+	Layout(root, Loose(Unbounded, Unbounded), NewLayoutContext(800, 600, 16))
+	for i, child := range root.Children {
+		want := Rect{X: float64(i) * 50, Width: 50, Height: 100}
+		if child.Rect != want {
+			t.Errorf("Expected child %d at %v, got %v", i, want, child.Rect)
+		}
+	}
+}
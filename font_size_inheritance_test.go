@@ -0,0 +1,52 @@
+package layout
+
+import "testing"
+
+func TestEmInheritsParentFontSize(t *testing.T) {
+	grandchild := &Node{Style: Style{Display: DisplayBlock, Width: Em(2), Height: Rem(1)}}
+	child := &Node{Style: Style{Display: DisplayBlock, Width: Px(-1), Height: Px(-1), Padding: Spacing{Left: Em(1)}}, Children: []*Node{grandchild}}
+	root := &Node{
+		Style:    Style{Display: DisplayBlock, Width: Px(400), Height: Px(-1), TextStyle: &TextStyle{FontSize: 20}},
+		Children: []*Node{child},
+	}
+	Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if grandchild.Rect.Width != 40 {
+		t.Errorf("Expected 2em of the inherited 20px font to be 40, got %.2f", grandchild.Rect.Width)
+	}
+	if grandchild.Rect.Height != 16 {
+		t.Errorf("Expected 1rem to use the root font size, got %.2f", grandchild.Rect.Height)
+	}
+	if grandchild.Rect.X != 20 {
+		t.Errorf("Expected 1em padding on the child to be 20, got %.2f", grandchild.Rect.X)
+	}
+}
+
+func TestEmInheritanceInFlexAndGrid(t *testing.T) {
+	item := &Node{Style: Style{Width: Em(3), Height: Px(10)}}
+	row := HStack(item)
+	row.Style.TextStyle = &TextStyle{FontSize: 10}
+
+	cell := &Node{Style: Style{Width: Em(1), Height: Em(1), JustifySelf: JustifyItemsStart, AlignSelf: AlignItemsFlexStart}}
+	grid := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100))},
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(100))},
+			TextStyle:           &TextStyle{FontSize: 24},
+		},
+		Children: []*Node{cell},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	Layout(VStack(row, grid), Loose(800, 600), ctx)
+
+	if item.Rect.Width != 30 {
+		t.Errorf("Expected flex item 3em = 30, got %.2f", item.Rect.Width)
+	}
+	if cell.Rect.Width != 24 || cell.Rect.Height != 24 {
+		t.Errorf("Expected grid item 1em = 24, got %.2fx%.2f", cell.Rect.Width, cell.Rect.Height)
+	}
+	if ctx.inheritedFontSize != 0 {
+		t.Errorf("Expected inherited font size to be reset after layout, got %.2f", ctx.inheritedFontSize)
+	}
+}
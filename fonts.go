@@ -0,0 +1,184 @@
+package layout
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Font fallback.
+//
+// TextStyle.FontFamily is a font stack: a comma-separated list of families
+// in order of preference, as in CSS. When the metrics provider implements
+// FontCoverageProvider, text is measured run by run, each run in the first
+// family of the stack that has glyphs for it, so mixed Latin, CJK and emoji
+// text gets the widths of the fonts that will actually draw it. Boxes that
+// need more than the first family record their runs in InlineBox.Runs.
+//
+// See: https://www.w3.org/TR/css-fonts-4/#font-matching-algorithm
+
+// FontCoverageProvider is an optional interface for a TextMetricsProvider
+// that knows which characters each font family covers. Without it, text is
+// measured with the whole TextStyle as before.
+type FontCoverageProvider interface {
+	// HasGlyph reports whether family has a glyph for r.
+	HasGlyph(family string, r rune) bool
+}
+
+// FontRun is a run of a box's text drawn in one font family of the font
+// stack.
+type FontRun struct {
+	Text       string
+	FontFamily string
+	Width      float64
+}
+
+// FontStack returns the families of t.FontFamily in order of preference,
+// with surrounding quotes and spaces removed.
+//
+// Example:
+//
+//	TextStyle{FontFamily: `Inter, "Noto Sans CJK", emoji`}.FontStack()
+//	// ["Inter", "Noto Sans CJK", "emoji"]
+func (t TextStyle) FontStack() []string {
+	var stack []string
+	for _, family := range strings.Split(t.FontFamily, ",") {
+		family = strings.TrimSpace(family)
+		if len(family) >= 2 && (family[0] == '"' || family[0] == '\'') && family[len(family)-1] == family[0] {
+			family = strings.TrimSpace(family[1 : len(family)-1])
+		}
+		if family != "" {
+			stack = append(stack, family)
+		}
+	}
+	return stack
+}
+
+// measureText measures text in style, run by run when fonts fall back,
+// through ctx's TextMeasureCache if it has one.
+func measureText(text string, style TextStyle, ctx *LayoutContext) (advance, ascent, descent float64) {
+	if ctx != nil && ctx.TextCache != nil {
+		m := ctx.TextCache.measure(text, style)
+		return m.advance, m.ascent, m.descent
+	}
+	metrics := getTextMetrics()
+	if runs := fontRuns(text, style, metrics); runs != nil {
+		return measureFontRuns(runs, style, metrics)
+	}
+	return metrics.Measure(text, style)
+}
+
+// textFontRuns returns the measured font runs of text in style, or nil if
+// text doesn't need font fallback.
+func textFontRuns(text string, style TextStyle, ctx *LayoutContext) []FontRun {
+	if ctx != nil && ctx.TextCache != nil {
+		// Callers own the runs they get
+		return append([]FontRun(nil), ctx.TextCache.measure(text, style).runs...)
+	}
+	metrics := getTextMetrics()
+	runs := fontRuns(text, style, metrics)
+	if runs != nil {
+		measureFontRuns(runs, style, metrics)
+	}
+	return runs
+}
+
+// measureTextWith measures text in style with metrics, keeping its font
+// runs.
+func measureTextWith(text string, style TextStyle, metrics TextMetricsProvider) *textMeasurement {
+	m := &textMeasurement{runs: fontRuns(text, style, metrics)}
+	if m.runs != nil {
+		m.advance, m.ascent, m.descent = measureFontRuns(m.runs, style, metrics)
+	} else {
+		m.advance, m.ascent, m.descent = metrics.Measure(text, style)
+	}
+	return m
+}
+
+// fontRuns splits text into runs of the first family of style's font stack
+// that covers each character. It returns nil if metrics can't tell
+// coverage, the stack has a single family, or the first family covers all
+// of text.
+func fontRuns(text string, style TextStyle, metrics TextMetricsProvider) []FontRun {
+	coverage, ok := metrics.(FontCoverageProvider)
+	if !ok || !strings.Contains(style.FontFamily, ",") {
+		return nil
+	}
+	stack := style.FontStack()
+	if len(stack) < 2 {
+		return nil
+	}
+
+	var runs []FontRun
+	start, family := 0, ""
+	prev := rune(-1)
+	for i, r := range text {
+		f := family
+		if family == "" || !continuesCluster(prev, r) {
+			f = stack[0]
+			for _, candidate := range stack {
+				if coverage.HasGlyph(candidate, r) {
+					f = candidate
+					break
+				}
+			}
+		}
+		if f != family {
+			if family != "" {
+				runs = append(runs, FontRun{Text: text[start:i], FontFamily: family})
+			}
+			start, family = i, f
+		}
+		prev = r
+	}
+	if family == "" || (len(runs) == 0 && family == stack[0]) {
+		return nil
+	}
+	return append(runs, FontRun{Text: text[start:], FontFamily: family})
+}
+
+// measureFontRuns sets the width of each run and returns the advance of
+// all of them, with the tallest ascent and descent.
+func measureFontRuns(runs []FontRun, style TextStyle, metrics TextMetricsProvider) (advance, ascent, descent float64) {
+	for i := range runs {
+		width, a, d := metrics.Measure(runs[i].Text, fontRunStyle(style, runs[i].FontFamily))
+		runs[i].Width = width
+		advance += width
+		if i > 0 && style.LetterSpacing != -1 {
+			// Measure only spaces the letters within a run
+			advance += style.LetterSpacing
+		}
+		if a > ascent {
+			ascent = a
+		}
+		if d > descent {
+			descent = d
+		}
+	}
+	return advance, ascent, descent
+}
+
+// fontRunStyle returns style with its font stack replaced by family.
+func fontRunStyle(style TextStyle, family string) TextStyle {
+	style.FontFamily = family
+	return style
+}
+
+// continuesCluster reports whether r is drawn together with the character
+// before it (prev), so both must come from the same font: combining marks,
+// variation selectors, emoji modifiers and characters joined by a ZWJ.
+func continuesCluster(prev, r rune) bool {
+	if prev < 0 {
+		return false
+	}
+	switch {
+	case prev == '\u200d', r == '\u200d', // Zero width joiner
+		unicode.In(r, unicode.Mn, unicode.Me, unicode.Variation_Selector),
+		r >= 0x1F3FB && r <= 0x1F3FF, // Emoji skin tone modifiers
+		r >= 0xE0020 && r <= 0xE007F: // Emoji tag sequences
+		return true
+	case unicode.IsSpace(r):
+		// Spaces stay in the current font rather than starting a run
+		return true
+	}
+	return false
+}
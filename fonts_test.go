@@ -0,0 +1,101 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+// fallbackMetrics covers ASCII in "latin" (10px), Han in "cjk" (20px) and
+// emoji in "emoji" (24px).
+type fallbackMetrics struct{}
+
+func (fallbackMetrics) Measure(text string, style TextStyle) (advance, ascent, descent float64) {
+	width, ascent := 10.0, 8.0
+	switch style.FontFamily {
+	case "cjk":
+		width, ascent = 20, 16
+	case "emoji":
+		width, ascent = 24, 20
+	}
+	return width * float64(utf8.RuneCountInString(text)), ascent, 2
+}
+
+func (fallbackMetrics) HasGlyph(family string, r rune) bool {
+	switch family {
+	case "latin":
+		return r < 0x80
+	case "cjk":
+		return r >= 0x4E00 && r <= 0x9FFF
+	case "emoji":
+		return r >= 0x1F000
+	}
+	return false
+}
+
+func TestFontStack(t *testing.T) {
+	got := TextStyle{FontFamily: ` Inter, "Noto Sans CJK" ,'Apple Color Emoji', ,serif`}.FontStack()
+	want := []string{"Inter", "Noto Sans CJK", "Apple Color Emoji", "serif"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := (TextStyle{}).FontStack(); got != nil {
+		t.Errorf("Expected no families, got %q", got)
+	}
+}
+
+func TestFontFallbackRuns(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	SetTextMetricsProvider(fallbackMetrics{})
+
+	style := TextStyle{FontFamily: "latin, cjk, emoji", LetterSpacing: -1}
+	tests := []struct {
+		text  string
+		runs  []FontRun
+		width float64
+	}{
+		{"hello", nil, 50},
+		{"ok漢字", []FontRun{{"ok", "latin", 20}, {"漢字", "cjk", 40}}, 60},
+		// The skin tone modifier stays with its emoji
+		{"hi👍🏽!", []FontRun{{"hi", "latin", 20}, {"👍🏽", "emoji", 48}, {"!", "latin", 10}}, 78},
+		// Nothing covers Cyrillic, so it's measured in the first family
+		{"мир", nil, 30},
+	}
+	for _, tt := range tests {
+		runs := textFontRuns(tt.text, style, nil)
+		if !reflect.DeepEqual(runs, tt.runs) {
+			t.Errorf("%q: expected runs %+v, got %+v", tt.text, tt.runs, runs)
+		}
+		if width, _, _ := measureText(tt.text, style, nil); width != tt.width {
+			t.Errorf("%q: expected width %v, got %v", tt.text, tt.width, width)
+		}
+	}
+
+	// A single family never falls back
+	if runs := textFontRuns("ok漢字", TextStyle{FontFamily: "latin"}, nil); runs != nil {
+		t.Errorf("Expected no runs for a single family, got %+v", runs)
+	}
+}
+
+func TestFontFallbackLayout(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	SetTextMetricsProvider(fallbackMetrics{})
+
+	node := Text("go 👍", Style{TextStyle: &TextStyle{FontFamily: "latin, emoji", LineHeight: 1}})
+	LayoutText(node, Loose(400, 100), NewLayoutContext(800, 600, 16))
+
+	boxes := node.TextLayout.Lines[0].Boxes
+	if len(boxes) != 2 {
+		t.Fatalf("Expected 2 boxes, got %d", len(boxes))
+	}
+	if boxes[0].Runs != nil {
+		t.Errorf("Expected no runs for %q, got %+v", boxes[0].Text, boxes[0].Runs)
+	}
+	emoji := boxes[1]
+	if emoji.Width != 24 || emoji.Ascent != 20 {
+		t.Errorf("Expected the emoji box measured in the emoji font (24px wide, ascent 20), got width %v ascent %v", emoji.Width, emoji.Ascent)
+	}
+	if want := []FontRun{{"👍", "emoji", 24}}; !reflect.DeepEqual(emoji.Runs, want) {
+		t.Errorf("Expected runs %+v, got %+v", want, emoji.Runs)
+	}
+}
@@ -0,0 +1,248 @@
+package layout
+
+import "math"
+
+// Fragmentation
+//
+// Paginate splits a laid-out tree into pages of a fixed height for paged
+// output such as PDF. Layout itself is unchanged: each page is a horizontal
+// slice of the laid-out tree, and the slices are cut at break points that
+// don't split anything that shouldn't be split.
+//
+// Break points are the top edges of boxes and the boundaries between lines
+// of text. A break point is never chosen inside a leaf box (an image, a
+// fixed-size box) or inside a line of text. BreakBefore and BreakAfter force
+// or discourage breaks at a box's edges, and BreakInside avoid keeps a box
+// on one page. Avoid rules are dropped when no other break point fits on the
+// page, and a box taller than a page is sliced at the page boundary.
+//
+// See: https://www.w3.org/TR/css-break-3/
+
+// Page is one page of a paginated tree.
+type Page struct {
+	// Top is where the page starts in the tree, in the coordinate space
+	// of the root's Rect. Height is how much of the tree is on the page:
+	// at most the page height, less when the page ends at a break.
+	Top    float64
+	Height float64
+
+	// Fragments are the boxes on the page, in tree order.
+	Fragments []Fragment
+}
+
+// Fragment is the part of a box that is on a page.
+type Fragment struct {
+	Node *Node
+
+	// Rect is the part of the node's border box on the page, in page
+	// coordinates: X is the node's absolute X and Y is measured from the
+	// top of the page.
+	Rect Rect
+
+	// Offset is how much of the node's border box is on earlier pages.
+	// Draw the node's content shifted up by Offset and clipped to Rect.
+	Offset float64
+
+	// Continued reports that the node started on an earlier page, and
+	// Continues that it goes on to a later page.
+	Continued bool
+	Continues bool
+}
+
+// fragmentBox is a box that constrains where a page can break.
+type fragmentBox struct {
+	top, bottom float64
+	avoid       bool // BreakInside avoid rather than monolithic
+}
+
+// fragmentBreak is a possible page break.
+type fragmentBreak struct {
+	y      float64
+	forced bool
+	avoid  bool
+}
+
+// fragmentNode is a node with its absolute border box.
+type fragmentNode struct {
+	node *Node
+	rect Rect
+}
+
+// Paginate splits root, which must already be laid out, into pages at most
+// pageHeight tall. A pageHeight of zero or less puts the whole tree on one
+// page. Only horizontal writing modes are broken between lines of text.
+//
+// Algorithm based on CSS Fragmentation Module Level 3:
+// - §3: Controlling breaks
+// - §4: Rules for breaking
+//
+// See: https://www.w3.org/TR/css-break-3/#breaking-rules
+//
+// Example:
+//
+//	layout.Layout(doc, layout.Loose(595, layout.Unbounded), ctx)
+//	for _, page := range layout.Paginate(doc, 842, ctx) {
+//		for _, f := range page.Fragments {
+//			drawBox(f.Node, f.Rect, f.Offset)
+//		}
+//		newPage()
+//	}
+func Paginate(root *Node, pageHeight float64, ctx *LayoutContext) []Page {
+	var nodes []fragmentNode
+	var boxes []fragmentBox
+	var breaks []fragmentBreak
+	fragmentCollect(root, 0, 0, true, ctx, &nodes, &boxes, &breaks)
+
+	top, end := root.Rect.Y, root.Rect.Y+root.Rect.Height
+	var pages []Page
+	for {
+		limit := end
+		if pageHeight > 0 {
+			limit = math.Min(top+pageHeight, end)
+		}
+		bottom := fragmentChooseBreak(top, limit, end, boxes, breaks)
+		pages = append(pages, fragmentPage(nodes, top, bottom))
+		if bottom >= end {
+			return pages
+		}
+		top = bottom
+	}
+}
+
+// fragmentCollect records the absolute border box of node and its
+// descendants, the boxes that can't be broken, and the possible breaks.
+// (x, y) is the origin of node's parent.
+func fragmentCollect(node *Node, x, y float64, isRoot bool, ctx *LayoutContext, nodes *[]fragmentNode, boxes *[]fragmentBox, breaks *[]fragmentBreak) {
+	rect := node.Rect
+	rect.X += x
+	rect.Y += y
+	*nodes = append(*nodes, fragmentNode{node: node, rect: rect})
+	bottom := rect.Y + rect.Height
+
+	if !isRoot {
+		switch {
+		case node.Style.BreakInside == BreakInsideAvoid:
+			*boxes = append(*boxes, fragmentBox{top: rect.Y, bottom: bottom, avoid: true})
+		case len(node.Children) == 0 && node.Text == "":
+			*boxes = append(*boxes, fragmentBox{top: rect.Y, bottom: bottom})
+		}
+	}
+
+	// Lines of text are monolithic; the text can break between them
+	if node.Text != "" {
+		if node.TextLayout == nil || node.Style.WritingMode.IsVertical() {
+			*boxes = append(*boxes, fragmentBox{top: rect.Y, bottom: bottom})
+		} else {
+			fontSize := getCurrentFontSize(node, ctx)
+			contentTop := rect.Y + ResolveLength(node.Style.Padding.Top, ctx, fontSize) + ResolveLength(node.Style.Border.Top, ctx, fontSize)
+			for i, line := range node.TextLayout.Lines {
+				lineTop := contentTop + line.OffsetY
+				*boxes = append(*boxes, fragmentBox{top: lineTop, bottom: lineTop + node.TextLayout.lineHeight(i)})
+				if i > 0 {
+					*breaks = append(*breaks, fragmentBreak{y: lineTop})
+				}
+			}
+		}
+	}
+
+	var prev *Node
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone {
+			continue
+		}
+		childTop := rect.Y + child.Rect.Y
+		b := fragmentBreak{y: childTop}
+		if child.Style.BreakBefore == BreakPage || (prev != nil && prev.Style.BreakAfter == BreakPage) {
+			b.forced = true
+		}
+		if child.Style.BreakBefore == BreakAvoid || (prev != nil && prev.Style.BreakAfter == BreakAvoid) {
+			b.avoid = true
+		}
+		*breaks = append(*breaks, b)
+		fragmentCollect(child, rect.X, rect.Y, false, ctx, nodes, boxes, breaks)
+		prev = child
+	}
+	if prev != nil && prev.Style.BreakAfter == BreakPage {
+		*breaks = append(*breaks, fragmentBreak{y: rect.Y + prev.Rect.Y + prev.Rect.Height, forced: true})
+	}
+}
+
+// fragmentChooseBreak returns where the page that starts at top ends: the
+// first forced break before limit, otherwise end if the rest of the tree
+// fits, otherwise the last break point before limit that breaks the fewest
+// rules, otherwise limit itself.
+//
+// Rules are relaxed in the order of CSS Fragmentation §4.4: first avoid at
+// break points, then avoid inside boxes. Monolithic boxes and lines are
+// only sliced when nothing else fits.
+func fragmentChooseBreak(top, limit, end float64, boxes []fragmentBox, breaks []fragmentBreak) float64 {
+	const epsilon = 0.001
+
+	forced := math.Inf(1)
+	for _, b := range breaks {
+		if b.forced && b.y > top+epsilon && b.y <= limit+epsilon {
+			forced = math.Min(forced, b.y)
+		}
+	}
+	if !math.IsInf(forced, 1) {
+		return forced
+	}
+	if limit >= end {
+		return end
+	}
+
+	for pass := 0; pass < 3; pass++ {
+		best := math.Inf(-1)
+		for _, b := range breaks {
+			if b.y <= top+epsilon || b.y > limit+epsilon || b.y <= best {
+				continue
+			}
+			if b.avoid && pass == 0 {
+				continue
+			}
+			valid := true
+			for _, box := range boxes {
+				if box.avoid && pass == 2 {
+					continue
+				}
+				if box.top < b.y-epsilon && b.y+epsilon < box.bottom {
+					valid = false
+					break
+				}
+			}
+			if valid {
+				best = b.y
+			}
+		}
+		if !math.IsInf(best, -1) {
+			return best
+		}
+	}
+	return limit
+}
+
+// fragmentPage collects the fragments of the nodes between top and bottom.
+func fragmentPage(nodes []fragmentNode, top, bottom float64) Page {
+	page := Page{Top: top, Height: bottom - top}
+	for _, n := range nodes {
+		r := n.rect
+		nodeBottom := r.Y + r.Height
+		if r.Height == 0 {
+			if r.Y < top || r.Y >= bottom {
+				continue
+			}
+		} else if r.Y >= bottom || nodeBottom <= top {
+			continue
+		}
+		visibleTop := math.Max(r.Y, top)
+		visibleBottom := math.Min(nodeBottom, bottom)
+		page.Fragments = append(page.Fragments, Fragment{
+			Node:      n.node,
+			Rect:      Rect{X: r.X, Y: visibleTop - top, Width: r.Width, Height: visibleBottom - visibleTop},
+			Offset:    visibleTop - r.Y,
+			Continued: r.Y < top,
+			Continues: nodeBottom > bottom,
+		})
+	}
+	return page
+}
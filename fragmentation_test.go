@@ -0,0 +1,137 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// paginatedColumn lays out a 200px wide block column of the given children.
+func paginatedColumn(children ...*Node) (*Node, *LayoutContext) {
+	root := &Node{Style: Style{Display: DisplayBlock, Width: Px(200), Height: Px(-1)}, Children: children}
+	ctx := NewLayoutContext(200, 1000, 16)
+	Layout(root, Loose(200, Unbounded), ctx)
+	return root, ctx
+}
+
+func pageHeights(pages []Page) []float64 {
+	heights := make([]float64, len(pages))
+	for i, p := range pages {
+		heights[i] = p.Height
+	}
+	return heights
+}
+
+func TestPaginateBreaksBetweenBoxes(t *testing.T) {
+	root, ctx := paginatedColumn(Fixed(200, 60), Fixed(200, 60), Fixed(200, 60))
+
+	pages := Paginate(root, 100, ctx)
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %v", pageHeights(pages))
+	}
+	for i, p := range pages {
+		if p.Top != float64(i)*60 || p.Height != 60 {
+			t.Errorf("Page %d: expected top %d height 60, got top %.1f height %.1f", i, i*60, p.Top, p.Height)
+		}
+	}
+
+	// The root continues across pages; each box is whole on its page
+	second := pages[1].Fragments
+	if len(second) != 2 || second[0].Node != root || second[1].Node != root.Children[1] {
+		t.Fatalf("Expected root and second box on page 2, got %d fragments", len(second))
+	}
+	if !second[0].Continued || !second[0].Continues || second[0].Offset != 60 {
+		t.Errorf("Expected root fragment to continue with offset 60, got %+v", second[0])
+	}
+	if second[1].Rect != (Rect{X: 0, Y: 0, Width: 200, Height: 60}) || second[1].Continued || second[1].Continues {
+		t.Errorf("Expected whole box at the top of page 2, got %+v", second[1])
+	}
+}
+
+func TestPaginateForcedBreaks(t *testing.T) {
+	after := Fixed(200, 20)
+	after.Style.BreakAfter = BreakPage
+	before := Fixed(200, 20)
+	before.Style.BreakBefore = BreakPage
+	root, ctx := paginatedColumn(after, Fixed(200, 20), before)
+
+	pages := Paginate(root, 500, ctx)
+	want := []float64{20, 20, 20}
+	if got := pageHeights(pages); len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("Expected page heights %v, got %v", want, got)
+	}
+}
+
+func TestPaginateAvoid(t *testing.T) {
+	t.Run("break-inside avoid", func(t *testing.T) {
+		section := VStack(Fixed(200, 30), Fixed(200, 30))
+		section.Style.BreakInside = BreakInsideAvoid
+		root, ctx := paginatedColumn(Fixed(200, 50), section)
+
+		// The section would fit at 50..110 only if broken after its first
+		// child; it moves to the next page instead
+		pages := Paginate(root, 100, ctx)
+		if len(pages) != 2 || pages[0].Height != 50 {
+			t.Errorf("Expected section pushed to page 2, got %v", pageHeights(pages))
+		}
+	})
+
+	t.Run("break-after avoid", func(t *testing.T) {
+		heading := Fixed(200, 20)
+		heading.Style.BreakAfter = BreakAvoid
+		root, ctx := paginatedColumn(Fixed(200, 60), heading, Fixed(200, 60))
+
+		pages := Paginate(root, 100, ctx)
+		if len(pages) != 2 || pages[0].Height != 60 {
+			t.Errorf("Expected heading kept with the next box, got %v", pageHeights(pages))
+		}
+	})
+
+	t.Run("avoid dropped when nothing else fits", func(t *testing.T) {
+		section := VStack(Fixed(200, 80), Fixed(200, 80))
+		section.Style.BreakInside = BreakInsideAvoid
+		root, ctx := paginatedColumn(section)
+
+		pages := Paginate(root, 100, ctx)
+		if len(pages) != 2 || pages[0].Height != 80 {
+			t.Errorf("Expected the oversized section to break between children, got %v", pageHeights(pages))
+		}
+	})
+}
+
+func TestPaginateSlicesMonolithicBoxes(t *testing.T) {
+	root, ctx := paginatedColumn(Fixed(200, 250))
+
+	pages := Paginate(root, 100, ctx)
+	if got := pageHeights(pages); len(got) != 3 || got[2] != 50 {
+		t.Fatalf("Expected pages 100, 100, 50, got %v", got)
+	}
+	f := pages[1].Fragments[1]
+	if f.Offset != 100 || f.Rect.Height != 100 || !f.Continued || !f.Continues {
+		t.Errorf("Expected middle slice of the box, got %+v", f)
+	}
+}
+
+func TestPaginateBreaksBetweenLines(t *testing.T) {
+	text := Text("one two three four five six seven eight nine ten")
+	text.Style.Width = Px(60)
+	root, ctx := paginatedColumn(text)
+	if len(text.TextLayout.Lines) < 3 {
+		t.Fatalf("Expected several lines, got %d", len(text.TextLayout.Lines))
+	}
+
+	lineHeight := text.TextLayout.LineHeight
+	pages := Paginate(root, 2.5*lineHeight, ctx)
+	for i, p := range pages[:len(pages)-1] {
+		if lines := p.Height / lineHeight; math.Abs(lines-math.Round(lines)) > 0.01 {
+			t.Errorf("Page %d: expected a whole number of lines, got height %.2f", i, p.Height)
+		}
+	}
+}
+
+func TestPaginateUnbounded(t *testing.T) {
+	root, ctx := paginatedColumn(Fixed(200, 60), Fixed(200, 60))
+	pages := Paginate(root, 0, ctx)
+	if len(pages) != 1 || pages[0].Height != 120 || len(pages[0].Fragments) != 3 {
+		t.Errorf("Expected a single page with every box, got %v", pageHeights(pages))
+	}
+}
@@ -0,0 +1,290 @@
+package layout
+
+// FrozenTree is an immutable copy of a laid-out tree, for goroutines that
+// read a layout (renderers, hit testing) while the tree it was taken from
+// is changed and laid out again. Freeze copies everything a reader needs:
+// styles, rects, text and text layouts, and the identity fields, so the
+// two trees share nothing mutable. A FrozenTree is safe for concurrent use
+// by any number of goroutines.
+//
+// Example:
+//
+//	frozen := layout.Freeze(root)
+//	go render(frozen)
+//	edit(root)
+//	layout.Layout(root, constraints, ctx) // doesn't affect frozen
+type FrozenTree struct {
+	nodes    []frozenNode
+	children []int // Child indexes, each node's a contiguous range
+	bySource map[*Node]int
+}
+
+// frozenNode is a node of a FrozenTree, stored in tree order.
+type frozenNode struct {
+	source     *Node
+	parent     int // -1 for the root
+	children   [2]int
+	style      Style
+	rect       Rect
+	baseline   float64
+	scroll     Point
+	text       string
+	textLayout *TextLayout
+	tag        string
+	id         string
+	classes    []string
+	attributes map[string]string
+	userData   any
+}
+
+// FrozenNode is a handle to a node of a FrozenTree. The zero FrozenNode is
+// invalid; its accessors return zero values.
+type FrozenNode struct {
+	t *FrozenTree
+	i int
+}
+
+// Freeze returns an immutable copy of root's tree as it is now, typically
+// right after layout. Freeze reads the whole tree, so it must not run
+// concurrently with changes to it.
+func Freeze(root *Node) *FrozenTree {
+	t := &FrozenTree{bySource: make(map[*Node]int)}
+	if root == nil {
+		return t
+	}
+
+	// Copy the nodes in tree order, remembering each one's parent
+	var parents []int
+	walkTree(root, func(n *Node, path []int) walkStep {
+		parents = append(parents[:len(path)], len(t.nodes))
+		parent := -1
+		if len(path) > 0 {
+			parent = parents[len(path)-1]
+		}
+		t.bySource[n] = len(t.nodes)
+		t.nodes = append(t.nodes, frozenNode{
+			source:     n,
+			parent:     parent,
+			style:      cloneStyle(n.Style),
+			rect:       n.Rect,
+			baseline:   n.Baseline,
+			scroll:     n.Scroll,
+			text:       n.Text,
+			textLayout: cloneTextLayout(n.TextLayout),
+			tag:        n.Tag,
+			id:         n.ID,
+			classes:    append([]string(nil), n.Classes...),
+			attributes: cloneAttributes(n.Attributes),
+			userData:   n.UserData,
+		})
+		return walkChildren
+	})
+
+	// Then list the children of each node together, in order
+	ends := make([]int, len(t.nodes)+1)
+	for _, n := range t.nodes[1:] {
+		ends[n.parent+1]++
+	}
+	for i := range t.nodes {
+		ends[i+1] += ends[i]
+		t.nodes[i].children = [2]int{ends[i], ends[i]}
+	}
+	t.children = make([]int, len(t.nodes)-1)
+	for i, n := range t.nodes[1:] {
+		p := &t.nodes[n.parent]
+		t.children[p.children[1]] = i + 1
+		p.children[1]++
+	}
+	return t
+}
+
+// Len returns the number of nodes in t.
+func (t *FrozenTree) Len() int {
+	return len(t.nodes)
+}
+
+// Root returns t's root, or an invalid FrozenNode for an empty tree.
+func (t *FrozenTree) Root() FrozenNode {
+	if len(t.nodes) == 0 {
+		return FrozenNode{}
+	}
+	return FrozenNode{t: t, i: 0}
+}
+
+// Lookup returns the copy of source, or an invalid FrozenNode if source
+// wasn't in the tree when it was frozen.
+func (t *FrozenTree) Lookup(source *Node) FrozenNode {
+	i, ok := t.bySource[source]
+	if !ok {
+		return FrozenNode{}
+	}
+	return FrozenNode{t: t, i: i}
+}
+
+// NodeAt returns the deepest node whose rect, in the root's coordinates,
+// contains (x, y), or an invalid FrozenNode if the point is outside the
+// root. Later siblings win, as they paint on top. Scroll positions are
+// taken into account; transforms are not.
+func (t *FrozenTree) NodeAt(x, y float64) FrozenNode {
+	if len(t.nodes) == 0 {
+		return FrozenNode{}
+	}
+	hit := -1
+	var visit func(i int, ox, oy float64)
+	visit = func(i int, ox, oy float64) {
+		n := &t.nodes[i]
+		ax, ay := ox+n.rect.X, oy+n.rect.Y
+		if x < ax || y < ay || x >= ax+n.rect.Width || y >= ay+n.rect.Height {
+			return
+		}
+		hit = i
+		for _, c := range t.children[n.children[0]:n.children[1]] {
+			visit(c, ax-n.scroll.X, ay-n.scroll.Y)
+		}
+	}
+	visit(0, 0, 0)
+	if hit < 0 {
+		return FrozenNode{}
+	}
+	return FrozenNode{t: t, i: hit}
+}
+
+// Valid reports whether n refers to a node.
+func (n FrozenNode) Valid() bool {
+	return n.t != nil
+}
+
+// node returns n's data, or zero data for an invalid FrozenNode.
+func (n FrozenNode) node() *frozenNode {
+	if n.t == nil {
+		return &frozenNode{parent: -1}
+	}
+	return &n.t.nodes[n.i]
+}
+
+// Source returns the node n was copied from. It may have changed since,
+// so only its identity is safe to use concurrently with those changes.
+func (n FrozenNode) Source() *Node { return n.node().source }
+
+// Parent returns n's parent, or an invalid FrozenNode for the root.
+func (n FrozenNode) Parent() FrozenNode {
+	p := n.node().parent
+	if p < 0 {
+		return FrozenNode{}
+	}
+	return FrozenNode{t: n.t, i: p}
+}
+
+// NumChildren returns the number of n's children.
+func (n FrozenNode) NumChildren() int {
+	c := n.node().children
+	return c[1] - c[0]
+}
+
+// ChildAt returns n's i-th child, or an invalid FrozenNode if out of range.
+func (n FrozenNode) ChildAt(i int) FrozenNode {
+	c := n.node().children
+	if i < 0 || i >= c[1]-c[0] {
+		return FrozenNode{}
+	}
+	return FrozenNode{t: n.t, i: n.t.children[c[0]+i]}
+}
+
+// Children returns n's children in order.
+func (n FrozenNode) Children() []FrozenNode {
+	c := n.node().children
+	if c[0] == c[1] {
+		return nil
+	}
+	out := make([]FrozenNode, 0, c[1]-c[0])
+	for _, i := range n.t.children[c[0]:c[1]] {
+		out = append(out, FrozenNode{t: n.t, i: i})
+	}
+	return out
+}
+
+// Style returns a copy of n's style.
+func (n FrozenNode) Style() Style { return cloneStyle(n.node().style) }
+
+// Rect returns n's rect, relative to its parent.
+func (n FrozenNode) Rect() Rect { return n.node().rect }
+
+// AbsoluteRect returns n's rect in the root's coordinates, ignoring
+// transforms and scroll positions.
+func (n FrozenNode) AbsoluteRect() Rect {
+	r := n.Rect()
+	for p := n.Parent(); p.Valid(); p = p.Parent() {
+		pr := p.Rect()
+		r.X += pr.X
+		r.Y += pr.Y
+	}
+	return r
+}
+
+// Baseline returns n's Baseline.
+func (n FrozenNode) Baseline() float64 { return n.node().baseline }
+
+// Scroll returns n's scroll position.
+func (n FrozenNode) Scroll() Point { return n.node().scroll }
+
+// Text returns n's Text.
+func (n FrozenNode) Text() string { return n.node().text }
+
+// TextLayout returns a copy of n's text layout, or nil.
+func (n FrozenNode) TextLayout() *TextLayout { return cloneTextLayout(n.node().textLayout) }
+
+// Tag returns n's Tag.
+func (n FrozenNode) Tag() string { return n.node().tag }
+
+// ID returns n's ID.
+func (n FrozenNode) ID() string { return n.node().id }
+
+// Classes returns a copy of n's Classes.
+func (n FrozenNode) Classes() []string { return append([]string(nil), n.node().classes...) }
+
+// Attribute returns the value of n's attribute name, and whether it's set.
+func (n FrozenNode) Attribute(name string) (string, bool) {
+	v, ok := n.node().attributes[name]
+	return v, ok
+}
+
+// UserData returns n's UserData. It's the value the node held when it was
+// frozen, but isn't copied, so anything it refers to is shared with the
+// live tree.
+func (n FrozenNode) UserData() any { return n.node().userData }
+
+// cloneStyle returns a copy of s that shares no slices or pointers with it.
+func cloneStyle(s Style) Style {
+	s.GridTemplateRows = append([]GridTrack(nil), s.GridTemplateRows...)
+	s.GridTemplateColumns = append([]GridTrack(nil), s.GridTemplateColumns...)
+	if s.GridTemplateAreas != nil {
+		areas := *s.GridTemplateAreas
+		areas.Areas = append([]GridArea(nil), areas.Areas...)
+		s.GridTemplateAreas = &areas
+	}
+	if s.TextStyle != nil {
+		ts := *s.TextStyle
+		s.TextStyle = &ts
+	}
+	if s.FirstLine != nil {
+		fl := *s.FirstLine
+		s.FirstLine = &fl
+	}
+	if s.FirstLetter != nil {
+		fl := *s.FirstLetter
+		s.FirstLetter = &fl
+	}
+	return s
+}
+
+// cloneAttributes returns a copy of attrs.
+func cloneAttributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	out := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package layout
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreezeIsIndependentOfSource(t *testing.T) {
+	a, b := Fixed(100, 50), Fixed(80, 40)
+	a.ID = "a"
+	a.Classes = []string{"card"}
+	root := VStack(a, b)
+	ctx := NewLayoutContext(400, 300, 16)
+	Layout(root, Loose(400, 300), ctx)
+
+	frozen := Freeze(root)
+
+	a.Style.Height = Px(70)
+	a.Classes[0] = "changed"
+	root.Children = root.Children[:1]
+	Layout(root, Loose(400, 300), ctx)
+
+	if frozen.Len() != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", frozen.Len())
+	}
+	r := frozen.Root()
+	if r.NumChildren() != 2 || r.Rect().Height != 90 {
+		t.Errorf("Expected the root's 2 children and height 90, got %d and %.2f", r.NumChildren(), r.Rect().Height)
+	}
+	fa := r.ChildAt(0)
+	if fa.ID() != "a" || fa.Classes()[0] != "card" || fa.Rect().Height != 50 || fa.Style().Height.Value != 50 {
+		t.Errorf("Expected the frozen node to be unchanged, got %s %v %+v", fa.ID(), fa.Classes(), fa.Rect())
+	}
+	if fb := r.ChildAt(1); fb.AbsoluteRect().Y != 50 || fb.Parent().Source() != root {
+		t.Errorf("Expected the second child at Y=50 under the root, got %+v", fb.AbsoluteRect())
+	}
+	if frozen.Lookup(b).Source() != b || frozen.Lookup(&Node{}).Valid() {
+		t.Error("Expected Lookup to find frozen nodes by source only")
+	}
+	if r.ChildAt(2).Valid() || r.Parent().Valid() {
+		t.Error("Expected out-of-range children and the root's parent to be invalid")
+	}
+}
+
+func TestFrozenTreeNodeAt(t *testing.T) {
+	inner := Fixed(20, 20)
+	inner.UserData = "inner"
+	box := &Node{Style: Style{Width: Px(100), Height: Px(100), Padding: Uniform(Px(10))}, Children: []*Node{inner}}
+	root := VStack(Fixed(100, 30), box)
+	Layout(root, Loose(400, 300), NewLayoutContext(400, 300, 16))
+	frozen := Freeze(root)
+
+	if got := frozen.NodeAt(15, 45); got.Source() != inner {
+		t.Errorf("Expected the inner node at (15, 45), got %+v", got.AbsoluteRect())
+	}
+	if got := frozen.NodeAt(15, 45).UserData(); got != "inner" {
+		t.Errorf("Expected the inner node's UserData, got %v", got)
+	}
+	if got := frozen.NodeAt(90, 120); got.Source() != box {
+		t.Errorf("Expected the box at (90, 120), got %+v", got.AbsoluteRect())
+	}
+	if frozen.NodeAt(500, 500).Valid() {
+		t.Error("Expected no node outside the root")
+	}
+}
+
+func TestFrozenTreeConcurrentReads(t *testing.T) {
+	root := VStack(Fixed(100, 50), HStack(Fixed(10, 10), Fixed(20, 20)))
+	ctx := NewLayoutContext(400, 300, 16)
+	Layout(root, Loose(400, 300), ctx)
+	frozen := Freeze(root)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if frozen.NodeAt(5, 5).Rect().Width != 100 {
+					t.Error("Expected the first child at (5, 5)")
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		root.Children[0].Style.Width = Px(float64(100 + j))
+		Layout(root, Loose(400, 300), ctx)
+	}
+	wg.Wait()
+}
@@ -4,6 +4,8 @@ go 1.25.4
 
 require (
 	github.com/SCKelemen/text v1.2.0
+	github.com/go-text/typesetting v0.3.0
+	golang.org/x/image v0.23.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -18,6 +20,7 @@ require (
 	github.com/google/cel-go v0.26.1 // indirect
 	github.com/stoewer/go-strcase v1.2.0 // indirect
 	golang.org/x/exp v0.0.0-20230515195305-f3d0a9c9a5cc // indirect
+	golang.org/x/text v0.22.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20240826202546-f6391c0de4c7 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240826202546-f6391c0de4c7 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
@@ -2,6 +2,7 @@ package layout
 
 import (
 	"math"
+	"slices"
 )
 
 // LayoutGrid performs CSS Grid layout on a node.
@@ -25,12 +26,12 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		// If not grid, delegate to block layout
 		return LayoutBlock(node, constraints, ctx)
 	}
+	node.tracks = nil
 
 	// Get current font size for em unit resolution
-	currentFontSize := 16.0 // Default
-	if node.Style.TextStyle != nil && node.Style.TextStyle.FontSize > 0 {
-		currentFontSize = node.Style.TextStyle.FontSize
-	}
+	currentFontSize := getCurrentFontSize(node, ctx)
+	restoreInherited := inheritStyle(ctx, node, currentFontSize)
+	defer restoreInherited()
 
 	// Calculate available space
 	// If container has explicit width/height, use that to constrain available space
@@ -102,6 +103,14 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		contentHeight = 0
 	}
 
+	// Items' cq* units see the content box; an auto height is not definite
+	containerHeight := percentSizeIndefinite
+	if heightValue >= 0 {
+		containerHeight = contentHeight
+	}
+	restoreContainer := enterQueryContainer(node, ctx, contentWidth, containerHeight)
+	defer restoreContainer()
+
 	// Determine writing mode for grid positioning
 	// Based on CSS Writing Modes Level 3 and CSS Grid Layout Level 1
 	writingMode := node.Style.WritingMode
@@ -113,16 +122,10 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 
 	// Use auto tracks if templates not specified
 	if len(rows) == 0 {
-		rows = []GridTrack{node.Style.GridAutoRows}
-		if len(rows) == 0 || (rows[0].MinSize.Value == 0 && rows[0].MaxSize.Value == Unbounded && rows[0].Fraction == 0) {
-			rows = []GridTrack{AutoTrack()}
-		}
+		rows = []GridTrack{gridImplicitTrack(node.Style.GridAutoRows)}
 	}
 	if len(columns) == 0 {
-		columns = []GridTrack{node.Style.GridAutoColumns}
-		if len(columns) == 0 || (columns[0].MinSize.Value == 0 && columns[0].MaxSize.Value == Unbounded && columns[0].Fraction == 0) {
-			columns = []GridTrack{AutoTrack()}
-		}
+		columns = []GridTrack{gridImplicitTrack(node.Style.GridAutoColumns)}
 	}
 
 	// Calculate gap - resolve Length values
@@ -196,16 +199,12 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 			MaxHeight: Unbounded,
 		}
 
-		var childSize Size
-		if item.node.Style.Display == DisplayFlex {
-			childSize = LayoutFlexbox(item.node, childConstraints, ctx)
-		} else if item.node.Style.Display == DisplayGrid {
-			childSize = LayoutGrid(item.node, childConstraints, ctx)
-		} else if item.node.Style.Display == DisplayInlineText {
-			childSize = LayoutText(item.node, childConstraints, ctx)
-		} else {
-			childSize = LayoutBlock(item.node, childConstraints, ctx)
-		}
+		// Percentages are taken of the grid area; rows aren't sized yet
+		restore := resolvePercentSizes([]*Node{item.node}, itemWidth, percentSizeIndefinite)
+		childSize := cachedLayout(item.node, childConstraints, ctx, func() Size {
+			return layoutByDisplay(item.node, childConstraints, ctx)
+		})
+		restore()
 
 		// Store measured size for use in positioning phase
 		item.measuredSize = childSize
@@ -335,9 +334,10 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		}
 	}
 
-	rowOffsets := gridCalculateTrackOffsets(rowSizes, totalDistributedRowSize, contentHeight, rowGap, alignContent)
+	rowOffsets := gridCalculateTrackOffsets(rowSizes, totalDistributedRowSize, contentHeight, rowGap, alignContent, node.Style.AlignContentOverflow)
 
 	// Step 5: Position children
+	var stickyAreas map[int]Rect
 	for _, item := range gridItems {
 		// Calculate grid cell position using track offsets
 		cellX := 0.0
@@ -366,15 +366,13 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		if item.rowEnd > item.rowStart+1 {
 			cellHeight += rowGap * float64(item.rowEnd-item.rowStart-1)
 		}
+		restore := resolvePercentSizes([]*Node{item.node}, cellWidth, cellHeight)
 
 		// Position item within grid cell, accounting for margins
 		// In CSS Grid, items stretch to fill their cell by default (align-items: stretch)
 		// However, if an item has an aspect ratio, it should maintain that ratio while fitting within the cell
 		// Get item's font size for margin resolution
-		itemFontSize := 16.0 // Default
-		if item.node.Style.TextStyle != nil && item.node.Style.TextStyle.FontSize > 0 {
-			itemFontSize = item.node.Style.TextStyle.FontSize
-		}
+		itemFontSize := getCurrentFontSize(item.node, ctx)
 		marginLeft := ResolveLength(item.node.Style.Margin.Left, ctx, itemFontSize)
 		marginRight := ResolveLength(item.node.Style.Margin.Right, ctx, itemFontSize)
 		marginTop := ResolveLength(item.node.Style.Margin.Top, ctx, itemFontSize)
@@ -393,6 +391,9 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 
 		var itemWidth, itemHeight float64
 
+		// Resolve justify-self/align-self against the container defaults
+		itemJustify, itemAlign := gridItemAlignment(node, item.node)
+
 		// If item has aspect ratio, maintain it while fitting within cell
 		// In CSS Grid, items with aspect ratio maintain their ratio but fit within the cell
 		// For spanning items, we should use the measured size if it's valid and maintains aspect ratio
@@ -460,25 +461,31 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 					}
 				}
 			}
-		} else {
-			// No aspect ratio: apply justify-items and align-items alignment
-			// Zero value is stretch (CSS Grid default)
-			justifyItems := node.Style.JustifyItems
-			alignItems := node.Style.AlignItems
 
-			// Override with per-item alignment if set (CSS Grid §10.3)
-			if item.node.Style.JustifySelf != 0 {
-				justifyItems = item.node.Style.JustifySelf
+			// An explicit stretch (unlike the zero-value normal) stretches an
+			// aspect-ratio item in an auto-sized axis and transfers the
+			// stretched size through the ratio to the other axis.
+			// CSS Box Alignment Level 3 §6.2 and CSS Box Sizing Level 4 §5.1.
+			// https://www.w3.org/TR/css-align-3/#justify-grid
+			_, hasWidth := gridExplicitWidth(item.node, ctx, itemFontSize, maxItemWidth)
+			_, hasHeight := gridExplicitHeight(item.node, ctx, itemFontSize, maxItemHeight)
+			if itemJustify == JustifyItemsStretchExplicit && !hasWidth && maxItemWidth > 0 {
+				itemWidth = maxItemWidth
+				itemHeight = itemWidth / item.node.Style.AspectRatio
+			} else if itemAlign == AlignItemsStretchExplicit && !hasHeight && maxItemHeight > 0 {
+				itemHeight = maxItemHeight
+				itemWidth = itemHeight * item.node.Style.AspectRatio
 			}
-			if item.node.Style.AlignSelf != 0 {
-				alignItems = item.node.Style.AlignSelf
-			}
-
-			// Only default to stretch if the value is outside the valid enum range
-			if justifyItems > JustifyItemsCenter {
+		} else {
+			// No aspect ratio: apply justify-items and align-items alignment
+			// Zero value is stretch (CSS Grid default); without an aspect
+			// ratio an explicit stretch behaves the same.
+			justifyItems := itemJustify
+			alignItems := itemAlign
+			if justifyItems == JustifyItemsStretchExplicit {
 				justifyItems = JustifyItemsStretch
 			}
-			if alignItems > AlignItemsBaseline {
+			if alignItems == AlignItemsStretchExplicit {
 				alignItems = AlignItemsStretch
 			}
 
@@ -551,20 +558,16 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 
 		// Handle justify-items positioning (inline/row axis)
 		// Zero value is stretch (CSS Grid default)
-		justifyItems := node.Style.JustifyItems
+		justifyItems := itemJustify
 
-		// Override with per-item alignment if set (CSS Grid §10.3)
-		if item.node.Style.JustifySelf != 0 {
-			justifyItems = item.node.Style.JustifySelf
+		// Items with aspect-ratio treat normal (the zero value) as start
+		// per CSS Box Alignment Level 3 §6.1; explicit values are honored.
+		if item.node.Style.AspectRatio > 0 && justifyItems == JustifyItemsStretch {
+			justifyItems = JustifyItemsStart
 		}
-
-		if justifyItems > JustifyItemsCenter {
+		if justifyItems == JustifyItemsStretchExplicit {
 			justifyItems = JustifyItemsStretch
 		}
-		// Items with aspect-ratio default to start alignment per spec
-		if item.node.Style.AspectRatio > 0 {
-			justifyItems = JustifyItemsStart
-		}
 
 		// Calculate total item size including margins for alignment
 		totalItemWidth := itemWidth + marginLeft + marginRight
@@ -585,20 +588,15 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 
 		// Handle align-items positioning (block/column axis)
 		// Zero value is stretch (CSS default - same for Grid and Flexbox)
-		alignItems := node.Style.AlignItems
+		alignItems := itemAlign
 
-		// Override with per-item alignment if set (CSS Grid §10.3)
-		if item.node.Style.AlignSelf != 0 {
-			alignItems = item.node.Style.AlignSelf
+		// Items with aspect-ratio treat normal (the zero value) as start
+		if item.node.Style.AspectRatio > 0 && alignItems == AlignItemsStretch {
+			alignItems = AlignItemsFlexStart
 		}
-
-		if alignItems > AlignItemsBaseline {
+		if alignItems == AlignItemsStretchExplicit {
 			alignItems = AlignItemsStretch
 		}
-		// Items with aspect-ratio default to start alignment per spec
-		if item.node.Style.AspectRatio > 0 {
-			alignItems = AlignItemsFlexStart
-		}
 
 		switch alignItems {
 		case AlignItemsFlexStart: // Start
@@ -668,6 +666,21 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 			Height: finalHeight,
 		}
 
+		// Sticky items are contained by their grid area (see updateStickyIn)
+		if item.node.Style.Position == PositionSticky {
+			area := Rect{X: paddingLeft + borderLeft + cellX, Y: paddingTop + borderTop + cellY, Width: cellWidth, Height: cellHeight}
+			if isVerticalWritingMode {
+				area = Rect{X: paddingLeft + borderLeft + cellY, Y: paddingTop + borderTop + cellX, Width: cellHeight, Height: cellWidth}
+				if writingMode.IsRightToLeft() {
+					area.X = paddingLeft + borderLeft + contentWidth - cellY - cellHeight
+				}
+			}
+			if stickyAreas == nil {
+				stickyAreas = make(map[int]Rect)
+			}
+			stickyAreas[slices.Index(node.Children, item.node)] = area
+		}
+
 		// Note: The margin is already accounted for in maxItemHeight calculation above,
 		// so itemHeight is the content height, and the margin positions the item within the cell.
 		// The cell boundaries (cellY, cellY + cellHeight) define the grid structure,
@@ -680,12 +693,23 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		if item.node.Rect.Height < 0 {
 			item.node.Rect.Height = 0
 		}
+		restore()
 	}
 
 	// Calculate container size
 	totalWidth := sumSizes(columnSizes) + columnGap*float64(len(columnSizes)-1)
 	totalHeight := sumSizes(rowSizes) + rowGap*float64(len(rowSizes)-1)
 
+	if !isVerticalWritingMode {
+		node.tracks = gridTracks(columnOffsets, columnSizes, rowOffsets, rowSizes, paddingLeft+borderLeft, paddingTop+borderTop)
+	}
+	if stickyAreas != nil {
+		if node.tracks == nil {
+			node.tracks = &layoutTracks{}
+		}
+		node.tracks.stickyAreas = stickyAreas
+	}
+
 	containerSize := Size{
 		Width:  totalWidth + horizontalPadding + horizontalBorder,
 		Height: totalHeight + verticalPadding + verticalBorder,
@@ -705,6 +729,30 @@ func LayoutGrid(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	return constrainedSize
 }
 
+// gridItemAlignment resolves an item's justify-self and align-self against
+// the container's justify-items and align-items (CSS Grid §10.3). Values
+// outside the enum ranges fall back to the zero-value stretch.
+func gridItemAlignment(container, n *Node) (JustifyItems, AlignItems) {
+	justifyItems := container.Style.JustifyItems
+	alignItems := container.Style.AlignItems
+
+	// Override with per-item alignment if set
+	if n.Style.JustifySelf != 0 {
+		justifyItems = n.Style.JustifySelf
+	}
+	if n.Style.AlignSelf != 0 {
+		alignItems = n.Style.AlignSelf
+	}
+
+	if justifyItems < 0 || justifyItems > JustifyItemsStretchExplicit {
+		justifyItems = JustifyItemsStretch
+	}
+	if alignItems < 0 || alignItems > AlignItemsStretchExplicit {
+		alignItems = AlignItemsStretch
+	}
+	return justifyItems, alignItems
+}
+
 type gridItem struct {
 	node         *Node
 	rowStart     int
@@ -812,13 +860,7 @@ func calculateGridTrackSizes(tracks []GridTrack, availableSize float64, gap floa
 			// CSS Grid Layout §11.5: fit-content(size)
 			// See: https://www.w3.org/TR/css-grid-1/#valdef-grid-template-columns-fit-content
 			fixedIndices = append(fixedIndices, i)
-			maxContent := resolveIntrinsicTrackSize(track, container, i, isColumn, IntrinsicSizeMaxContent, ctx, currentFontSize)
-			size := maxSize
-			if size >= Unbounded {
-				size = maxContent // Use max-content if no limit specified
-			} else {
-				size = math.Min(maxContent, size) // Clamp to limit
-			}
+			size := resolveFitContentTrackSize(track, container, i, isColumn, ctx, currentFontSize)
 			sizes[i] = size
 			totalFixed += size
 		} else if track.Fraction > 0 {
@@ -290,3 +290,34 @@ func TestGridAutoFlowColumnWithGaps(t *testing.T) {
 		t.Errorf("Item 3 should be at (60,60), got (%v,%v)", container.Children[3].Rect.X, container.Children[3].Rect.Y)
 	}
 }
+
+// TestGridAutoFlowOrder tests that auto-placement follows order-modified
+// document order, with equal orders keeping source order
+func TestGridAutoFlowOrder(t *testing.T) {
+	container := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100)), FixedTrack(Px(100))},
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(50)), FixedTrack(Px(50))},
+			Width:               Px(200),
+			Height:              Px(100),
+		},
+		Children: []*Node{
+			{Style: Style{Order: 1}},  // (1,0)
+			{Style: Style{Order: 1}},  // (1,1)
+			{Style: Style{}},          // (0,1)
+			{Style: Style{Order: -1}}, // (0,0)
+		},
+	}
+
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutGrid(container, Loose(200, 100), ctx)
+
+	want := [][2]float64{{0, 50}, {100, 50}, {100, 0}, {0, 0}}
+	for i, pos := range want {
+		r := container.Children[i].Rect
+		if r.X != pos[0] || r.Y != pos[1] {
+			t.Errorf("Item %d should be at (%v,%v), got (%v,%v)", i, pos[0], pos[1], r.X, r.Y)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// fitContentGrid builds a two-column grid: fit-content(limit) then 1fr.
+func fitContentGrid(limit float64, label *Node) *Node {
+	label.Style.GridRowStart = 0
+	label.Style.GridRowEnd = 1
+	label.Style.GridColumnStart = 0
+	label.Style.GridColumnEnd = 1
+	return &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			Width:               Px(500),
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(50))},
+			GridTemplateColumns: []GridTrack{FitContentTrack(limit), FractionTrack(1)},
+		},
+		Children: []*Node{
+			label,
+			{Style: Style{GridRowStart: 0, GridRowEnd: 1, GridColumnStart: 1, GridColumnEnd: 2}},
+		},
+	}
+}
+
+func TestGridFitContentHugsContent(t *testing.T) {
+	root := fitContentGrid(200, &Node{Style: Style{Width: Px(80), Height: Px(20)}})
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutGrid(root, Loose(500, 600), ctx)
+
+	if math.Abs(root.Children[1].Rect.X-80) > 0.5 {
+		t.Errorf("fit-content(200) with 80px content should size to 80, second column starts at %.2f", root.Children[1].Rect.X)
+	}
+	if math.Abs(root.Children[1].Rect.Width-420) > 0.5 {
+		t.Errorf("Expected 1fr column to take remaining 420, got %.2f", root.Children[1].Rect.Width)
+	}
+}
+
+func TestGridFitContentExplicitWidthFloor(t *testing.T) {
+	// An explicit width is also the item's min-content contribution, and
+	// fit-content() never shrinks a track below its auto minimum.
+	root := fitContentGrid(200, &Node{Style: Style{Width: Px(350), Height: Px(20)}})
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutGrid(root, Loose(500, 600), ctx)
+
+	if math.Abs(root.Children[1].Rect.X-350) > 0.5 {
+		t.Errorf("Expected fit-content(200) track to keep 350px fixed item, second column starts at %.2f", root.Children[1].Rect.X)
+	}
+}
+
+func TestGridFitContentText(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+
+	// Short label: track hugs the text's max-content width.
+	short := Text("Name")
+	root := fitContentGrid(200, short)
+	LayoutGrid(root, Loose(500, 600), ctx)
+
+	want := CalculateIntrinsicWidth(Text("Name"), Unconstrained(), IntrinsicSizeMaxContent, ctx)
+	if want <= 0 {
+		t.Fatalf("Expected positive max-content width for text, got %.2f", want)
+	}
+	if math.Abs(root.Children[1].Rect.X-want) > 0.5 {
+		t.Errorf("Expected fit-content track to hug text (%.2f), second column starts at %.2f", want, root.Children[1].Rect.X)
+	}
+
+	// Long label: clamped to the limit and wrapped inside it.
+	long := Text("A considerably longer label that would blow out the layout")
+	root = fitContentGrid(120, long)
+	LayoutGrid(root, Loose(500, 600), ctx)
+
+	if math.Abs(root.Children[1].Rect.X-120) > 0.5 {
+		t.Errorf("Expected fit-content(120) to clamp long text, second column starts at %.2f", root.Children[1].Rect.X)
+	}
+	if long.TextLayout == nil || len(long.TextLayout.Lines) < 2 {
+		t.Error("Expected long label to wrap inside the clamped track")
+	}
+}
+
+func TestGridFitContentMinContentFloor(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+
+	// A single unbreakable word wider than the limit keeps the track at
+	// its min-content width.
+	word := Text("Supercalifragilistic")
+	minContent := CalculateIntrinsicWidth(Text("Supercalifragilistic"), Unconstrained(), IntrinsicSizeMinContent, ctx)
+	root := fitContentGrid(40, word)
+	LayoutGrid(root, Loose(500, 600), ctx)
+
+	if minContent <= 40 {
+		t.Fatalf("Test requires min-content wider than limit, got %.2f", minContent)
+	}
+	if math.Abs(root.Children[1].Rect.X-minContent) > 0.5 {
+		t.Errorf("Expected track floored at min-content %.2f, second column starts at %.2f", minContent, root.Children[1].Rect.X)
+	}
+}
+
+func TestIntrinsicWidthText(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+	node := Text("hello wonderful world")
+
+	minContent := CalculateIntrinsicWidth(node, Unconstrained(), IntrinsicSizeMinContent, ctx)
+	maxContent := CalculateIntrinsicWidth(node, Unconstrained(), IntrinsicSizeMaxContent, ctx)
+
+	if minContent <= 0 || maxContent <= minContent {
+		t.Errorf("Expected 0 < min-content < max-content, got %.2f and %.2f", minContent, maxContent)
+	}
+	if node.TextLayout != nil || node.Rect.Width != 0 {
+		t.Error("Intrinsic measurement should not modify the node")
+	}
+}
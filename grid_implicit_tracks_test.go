@@ -0,0 +1,95 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// implicitRowGrid builds a one-column grid with a single 50px explicit row
+// and one item per row in rows 0..len(heights)-1.
+func implicitRowGrid(autoRows GridTrack, heights ...float64) *Node {
+	root := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(50))},
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100))},
+			GridAutoRows:        autoRows,
+		},
+	}
+	for i, h := range heights {
+		item := &Node{Style: Style{GridRowStart: i, GridRowEnd: i + 1, GridColumnStart: 0, GridColumnEnd: 1}}
+		if h > 0 {
+			item.Style.Height = Px(h)
+		}
+		root.Children = append(root.Children, item)
+	}
+	return root
+}
+
+func assertRowStarts(t *testing.T, root *Node, want ...float64) {
+	t.Helper()
+	for i, y := range want {
+		if got := root.Children[i].Rect.Y; math.Abs(got-y) > 0.5 {
+			t.Errorf("Item %d: expected Y %.2f, got %.2f", i, y, got)
+		}
+	}
+}
+
+func TestGridImplicitRowsDefaultToAuto(t *testing.T) {
+	// Zero-value GridAutoRows: implicit rows are auto and hug their items
+	root := implicitRowGrid(GridTrack{}, 0, 30, 20)
+	LayoutGrid(root, Loose(800, Unbounded), NewLayoutContext(800, 600, 16))
+
+	assertRowStarts(t, root, 0, 50, 80)
+	if math.Abs(root.Rect.Height-100) > 0.5 {
+		t.Errorf("Expected grid height 100, got %.2f", root.Rect.Height)
+	}
+}
+
+func TestGridImplicitRowsFixed(t *testing.T) {
+	root := implicitRowGrid(FixedTrack(Px(40)), 0, 0, 0)
+	LayoutGrid(root, Loose(800, Unbounded), NewLayoutContext(800, 600, 16))
+
+	assertRowStarts(t, root, 0, 50, 90)
+	if math.Abs(root.Children[2].Rect.Height-40) > 0.5 {
+		t.Errorf("Expected implicit row item to stretch to 40, got %.2f", root.Children[2].Rect.Height)
+	}
+}
+
+func TestGridImplicitRowsMinMax(t *testing.T) {
+	root := implicitRowGrid(MinMaxTrack(Px(20), Px(60)), 0, 10, 100, 0)
+	LayoutGrid(root, Loose(800, Unbounded), NewLayoutContext(800, 600, 16))
+
+	// 10px item floors at 20, 100px item caps at 60
+	assertRowStarts(t, root, 0, 50, 70, 130)
+}
+
+func TestGridImplicitRowsFraction(t *testing.T) {
+	root := implicitRowGrid(FractionTrack(1), 0, 0, 0)
+	root.Style.Height = Px(250)
+	LayoutGrid(root, Loose(800, 250), NewLayoutContext(800, 600, 16))
+
+	// 200px left after the explicit row, split between two 1fr implicit rows
+	assertRowStarts(t, root, 0, 50, 150)
+}
+
+func TestGridImplicitColumns(t *testing.T) {
+	root := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(50))},
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100))},
+			GridAutoColumns:     FixedTrack(Px(70)),
+		},
+		Children: []*Node{
+			{Style: Style{GridRowStart: 0, GridRowEnd: 1, GridColumnStart: 0, GridColumnEnd: 1}},
+			{Style: Style{GridRowStart: 0, GridRowEnd: 1, GridColumnStart: 2, GridColumnEnd: 3}},
+		},
+	}
+	LayoutGrid(root, Loose(800, Unbounded), NewLayoutContext(800, 600, 16))
+
+	item := root.Children[1]
+	if math.Abs(item.Rect.X-170) > 0.5 || math.Abs(item.Rect.Width-70) > 0.5 {
+		t.Errorf("Expected item in third column at X 170 with width 70, got X %.2f width %.2f", item.Rect.X, item.Rect.Width)
+	}
+}
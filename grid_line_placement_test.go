@@ -0,0 +1,129 @@
+package layout
+
+import (
+	"testing"
+)
+
+// threeColumnGrid builds a grid with three 100px columns and 50px rows.
+func threeColumnGrid(flow GridAutoFlow, children ...*Node) *Node {
+	return &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100)), FixedTrack(Px(100)), FixedTrack(Px(100))},
+			GridAutoRows:        FixedTrack(Px(50)),
+			GridAutoFlow:        flow,
+		},
+		Children: children,
+	}
+}
+
+func TestGridResolveAxisPlacement(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		want       gridAxisPlacement
+	}{
+		{"zero value is auto", 0, 0, gridAxisPlacement{span: 1, auto: true}},
+		{"explicit auto", GridLineAuto, GridLineAuto, gridAxisPlacement{span: 1, auto: true}},
+		{"line with unset end", 2, 0, gridAxisPlacement{start: 2, span: 1}},
+		{"line to line", 0, 2, gridAxisPlacement{start: 0, span: 2}},
+		{"reversed lines swap", 3, 1, gridAxisPlacement{start: 1, span: 2}},
+		{"last line", 0, -1, gridAxisPlacement{start: 0, span: 3}},
+		{"last track", -2, -1, gridAxisPlacement{start: 2, span: 1}},
+		{"line then span", 1, Span(2), gridAxisPlacement{start: 1, span: 2}},
+		{"auto with end span", 0, Span(2), gridAxisPlacement{span: 2, auto: true}},
+		{"auto with start span", Span(3), GridLineAuto, gridAxisPlacement{span: 3, auto: true}},
+		{"span back from end", Span(2), -1, gridAxisPlacement{start: 1, span: 2}},
+		{"negative before start clamps", -10, 1, gridAxisPlacement{start: 0, span: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gridResolveAxisPlacement(tt.start, tt.end, 3)
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSpanClampsToOne(t *testing.T) {
+	if n, ok := gridSpanValue(Span(0)); !ok || n != 1 {
+		t.Errorf("Expected Span(0) to clamp to span 1, got %d (%v)", n, ok)
+	}
+	if _, ok := gridSpanValue(-1); ok {
+		t.Error("Negative line numbers should not decode as spans")
+	}
+	if _, ok := gridSpanValue(GridLineAuto); ok {
+		t.Error("GridLineAuto should not decode as a span")
+	}
+}
+
+func TestGridNegativeLinesSpanExplicitGrid(t *testing.T) {
+	root := threeColumnGrid(GridAutoFlowRow,
+		&Node{Style: Style{GridRowStart: 0, GridRowEnd: 1, GridColumnStart: 0, GridColumnEnd: -1}},
+		&Node{Style: Style{GridRowStart: 1, GridRowEnd: 2, GridColumnStart: -2, GridColumnEnd: -1}},
+	)
+	LayoutGrid(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	full := root.Children[0].Rect
+	if full.X != 0 || full.Width != 300 {
+		t.Errorf("Expected 0 / -1 to span all three columns, got X %.0f width %.0f", full.X, full.Width)
+	}
+	last := root.Children[1].Rect
+	if last.X != 200 || last.Width != 100 {
+		t.Errorf("Expected -2 / -1 to select the last column, got X %.0f width %.0f", last.X, last.Width)
+	}
+}
+
+func TestGridSpanAutoPlacement(t *testing.T) {
+	root := threeColumnGrid(GridAutoFlowRow,
+		&Node{Style: Style{GridColumnEnd: Span(2)}},
+		&Node{Style: Style{GridColumnEnd: Span(2)}},
+		&Node{Style: Style{GridRowEnd: Span(2)}},
+	)
+	LayoutGrid(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	a, b, c := root.Children[0].Rect, root.Children[1].Rect, root.Children[2].Rect
+	if a.X != 0 || a.Y != 0 || a.Width != 200 {
+		t.Errorf("First span-2 item: expected (0,0) width 200, got (%.0f,%.0f) width %.0f", a.X, a.Y, a.Width)
+	}
+	// Doesn't fit in the one remaining column, so wraps to the next row
+	if b.X != 0 || b.Y != 50 || b.Width != 200 {
+		t.Errorf("Second span-2 item: expected (0,50) width 200, got (%.0f,%.0f) width %.0f", b.X, b.Y, b.Width)
+	}
+	// Sparse placement never backfills the hole at (0,2)
+	if c.X != 200 || c.Y != 50 || c.Height != 100 {
+		t.Errorf("Row-span item: expected (200,50) height 100, got (%.0f,%.0f) height %.0f", c.X, c.Y, c.Height)
+	}
+}
+
+func TestGridSpanDenseBackfills(t *testing.T) {
+	root := threeColumnGrid(GridAutoFlowRowDense,
+		&Node{Style: Style{GridColumnEnd: Span(2)}},
+		&Node{Style: Style{GridColumnEnd: Span(2)}},
+		&Node{},
+	)
+	LayoutGrid(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	c := root.Children[2].Rect
+	if c.X != 200 || c.Y != 0 {
+		t.Errorf("Dense placement should backfill (0,2), got (%.0f,%.0f)", c.X, c.Y)
+	}
+}
+
+func TestGridAutoPlacementSkipsExplicitItems(t *testing.T) {
+	root := threeColumnGrid(GridAutoFlowRow,
+		&Node{},
+		&Node{Style: Style{GridRowStart: 0, GridRowEnd: 1, GridColumnStart: 1, GridColumnEnd: 2}},
+		&Node{},
+	)
+	LayoutGrid(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if x := root.Children[0].Rect.X; x != 0 {
+		t.Errorf("First auto item should be in column 0, got X %.0f", x)
+	}
+	if x := root.Children[2].Rect.X; x != 200 {
+		t.Errorf("Second auto item should skip the explicit item's cell, got X %.0f", x)
+	}
+}
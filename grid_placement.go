@@ -1,211 +1,292 @@
 package layout
 
+import "sort"
+
 // gridPlaceItems performs grid item placement including auto-placement.
 //
+// Each item's row and column placement is first resolved against the
+// explicit grid (line numbers, negative lines and span). Items are then
+// placed in the order the spec prescribes: fully definite items, items
+// locked to a row (or column for column flow), and finally the remaining
+// items using the auto-placement cursor. Tracks are appended for anything
+// placed beyond the explicit grid.
+//
 // Algorithm based on CSS Grid Layout Module Level 1:
-// - §12: Grid Item Placement
-// - §12.1: Grid Item Placement Algorithm
-// - §8.3: Grid Auto-Flow (row vs column, dense vs sparse)
+// - §8.3: Line-based Placement
+// - §8.3.1: Grid Placement Conflict Handling
+// - §8.5: Grid Item Placement Algorithm
+// - §7.7: Automatic Placement (row vs column, dense vs sparse)
+// - §5.4.1 (Flexbox, via Grid §4.2): Reordering with the order property
 //
-// See: https://www.w3.org/TR/css-grid-1/#placement
+// See: https://www.w3.org/TR/css-grid-1/#line-placement
 // See: https://www.w3.org/TR/css-grid-1/#auto-placement-algo
 func gridPlaceItems(node *Node, rows *[]GridTrack, columns *[]GridTrack, autoFlow GridAutoFlow) []*gridItem {
-	children := node.Children
-	gridItems := make([]*gridItem, 0, len(children))
-	itemIndex := 0
-
 	// Determine if we're using row-major or column-major flow
 	isColumnFlow := autoFlow == GridAutoFlowColumn || autoFlow == GridAutoFlowColumnDense
+	isDense := autoFlow == GridAutoFlowRowDense || autoFlow == GridAutoFlowColumnDense
+
+	// Work in flow-relative coordinates: the auto-placement cursor moves
+	// along the minor axis (columns for row flow) and wraps onto the next
+	// line of the major axis.
+	explicitMinor := len(*columns)
+	if isColumnFlow {
+		explicitMinor = len(*rows)
+	}
+
+	type placement struct {
+		item         *gridItem
+		major, minor gridAxisPlacement
+	}
+	// Place items in order-modified document order (CSS Grid §8.5,
+	// CSS Flexbox §5.4.1): items with the same order keep source order
+	orderedChildren := make([]*Node, len(node.Children))
+	copy(orderedChildren, node.Children)
+	sort.SliceStable(orderedChildren, func(i, j int) bool {
+		return orderedChildren[i].Style.Order < orderedChildren[j].Style.Order
+	})
 
-	for _, child := range children {
+	placements := make([]*placement, 0, len(orderedChildren))
+	for _, child := range orderedChildren {
 		// Skip display:none children
 		if child.Style.Display == DisplayNone {
 			continue
 		}
-		item := &gridItem{
-			node: child,
+		row := gridResolveAxisPlacement(child.Style.GridRowStart, child.Style.GridRowEnd, len(*rows))
+		col := gridResolveAxisPlacement(child.Style.GridColumnStart, child.Style.GridColumnEnd, len(*columns))
+		p := &placement{item: &gridItem{node: child}, major: row, minor: col}
+		if isColumnFlow {
+			p.major, p.minor = col, row
 		}
+		placements = append(placements, p)
+	}
 
-		// Get grid position
-		rowStart := child.Style.GridRowStart
-		rowEnd := child.Style.GridRowEnd
-		colStart := child.Style.GridColumnStart
-		colEnd := child.Style.GridColumnEnd
-
-		// Auto placement based on grid-auto-flow
-		// -1 means explicit auto, 0 means unset (default value) - both should trigger auto-placement
-		needsAutoRow := rowStart < 0 || (rowStart == 0 && rowEnd <= 0)
-		needsAutoCol := colStart < 0 || (colStart == 0 && colEnd <= 0)
-
-		if isColumnFlow {
-			// Column-major flow: items fill columns first, then rows
-			if needsAutoCol {
-				// Use itemIndex for auto-placement in column direction
-				colStart = itemIndex / len(*rows)
-				colEnd = colStart + 1
-			} else {
-				// If colEnd is -1 (explicit auto) or 0 (unset default), set it to colStart + 1
-				if colEnd <= 0 {
-					colEnd = colStart + 1
+	occupied := make(map[[2]int]bool)
+	occupy := func(p *placement) {
+		for ma := p.major.start; ma < p.major.start+p.major.span; ma++ {
+			for mi := p.minor.start; mi < p.minor.start+p.minor.span; mi++ {
+				occupied[[2]int{ma, mi}] = true
+			}
+		}
+	}
+	fits := func(major, minor int, p *placement) bool {
+		for ma := major; ma < major+p.major.span; ma++ {
+			for mi := minor; mi < minor+p.minor.span; mi++ {
+				if occupied[[2]int{ma, mi}] {
+					return false
 				}
 			}
+		}
+		return true
+	}
 
-			if needsAutoRow {
-				// Use itemIndex for auto-placement in row direction
-				rowStart = itemIndex % len(*rows)
-				rowEnd = rowStart + 1
-			} else {
-				// If rowEnd is -1 (explicit auto) or 0 (unset default), set it to rowStart + 1
-				if rowEnd <= 0 {
-					rowEnd = rowStart + 1
-				}
+	// Step 1: items with a definite position on both axes
+	for _, p := range placements {
+		if !p.major.auto && !p.minor.auto {
+			occupy(p)
+		}
+	}
+
+	// Step 2: items locked to a major line, placed at the first free minor
+	// position (after earlier items in the same line unless dense)
+	laneCursor := make(map[int]int)
+	for _, p := range placements {
+		if p.major.auto || !p.minor.auto {
+			continue
+		}
+		minor := 0
+		if !isDense {
+			minor = laneCursor[p.major.start]
+		}
+		for !fits(p.major.start, minor, p) {
+			minor++
+		}
+		p.minor.start, p.minor.auto = minor, false
+		laneCursor[p.major.start] = minor + p.minor.span
+		occupy(p)
+	}
+
+	// The minor axis doesn't grow during auto-placement, so size it to fit
+	// everything placed so far and the widest auto span.
+	minorCount := explicitMinor
+	for _, p := range placements {
+		end := p.minor.span
+		if !p.minor.auto {
+			end += p.minor.start
+		}
+		if end > minorCount {
+			minorCount = end
+		}
+	}
+
+	// Step 3: remaining items, using the auto-placement cursor
+	cursorMajor, cursorMinor := 0, 0
+	for _, p := range placements {
+		if !p.major.auto {
+			continue
+		}
+		if isDense {
+			cursorMajor, cursorMinor = 0, 0
+		}
+		if !p.minor.auto {
+			// Definite minor position: advance along the major axis only
+			if p.minor.start < cursorMinor {
+				cursorMajor++
+			}
+			cursorMinor = p.minor.start
+			for !fits(cursorMajor, cursorMinor, p) {
+				cursorMajor++
 			}
 		} else {
-			// Row-major flow (default): items fill rows first, then columns
-			if needsAutoRow {
-				// Use itemIndex for auto-placement in row direction
-				rowStart = itemIndex / len(*columns)
-				rowEnd = rowStart + 1
-			} else {
-				// If rowEnd is -1 (explicit auto) or 0 (unset default), set it to rowStart + 1
-				if rowEnd <= 0 {
-					rowEnd = rowStart + 1
+			for {
+				if cursorMinor+p.minor.span > minorCount {
+					cursorMajor++
+					cursorMinor = 0
+					continue
 				}
-			}
-
-			if needsAutoCol {
-				// Use itemIndex for auto-placement in column direction
-				colStart = itemIndex % len(*columns)
-				colEnd = colStart + 1
-			} else {
-				// If colEnd is -1 (explicit auto) or 0 (unset default), set it to colStart + 1
-				if colEnd <= 0 {
-					colEnd = colStart + 1
+				if fits(cursorMajor, cursorMinor, p) {
+					break
 				}
+				cursorMinor++
 			}
+			p.minor.start, p.minor.auto = cursorMinor, false
+		}
+		p.major.start, p.major.auto = cursorMajor, false
+		occupy(p)
+		if !isDense {
+			cursorMinor = p.minor.start + p.minor.span
 		}
+	}
+
+	// Resolve back to rows and columns, extending the implicit grid
+	gridItems := make([]*gridItem, 0, len(placements))
+	for _, p := range placements {
+		row, col := p.major, p.minor
+		if isColumnFlow {
+			row, col = col, row
+		}
+		item := p.item
+		item.rowStart, item.rowEnd = row.start, row.start+row.span
+		item.colStart, item.colEnd = col.start, col.start+col.span
 
 		// Ensure we have enough rows/columns
-		if rowEnd > len(*rows) {
-			// Extend rows with auto tracks
-			for rowEnd > len(*rows) {
-				*rows = append(*rows, node.Style.GridAutoRows)
-				if (*rows)[len(*rows)-1].MinSize.Value == 0 && (*rows)[len(*rows)-1].MaxSize.Value == Unbounded && (*rows)[len(*rows)-1].Fraction == 0 {
-					(*rows)[len(*rows)-1] = AutoTrack()
-				}
-			}
+		for item.rowEnd > len(*rows) {
+			*rows = append(*rows, gridImplicitTrack(node.Style.GridAutoRows))
 		}
-		if colEnd > len(*columns) {
-			// Extend columns with auto tracks
-			for colEnd > len(*columns) {
-				*columns = append(*columns, node.Style.GridAutoColumns)
-				if (*columns)[len(*columns)-1].MinSize.Value == 0 && (*columns)[len(*columns)-1].MaxSize.Value == Unbounded && (*columns)[len(*columns)-1].Fraction == 0 {
-					(*columns)[len(*columns)-1] = AutoTrack()
-				}
-			}
+		for item.colEnd > len(*columns) {
+			*columns = append(*columns, gridImplicitTrack(node.Style.GridAutoColumns))
 		}
-
-		item.rowStart = rowStart
-		item.rowEnd = rowEnd
-		item.colStart = colStart
-		item.colEnd = colEnd
-
 		gridItems = append(gridItems, item)
-		itemIndex++
-	}
-
-	// Apply dense packing if requested
-	isDense := autoFlow == GridAutoFlowRowDense || autoFlow == GridAutoFlowColumnDense
-	if isDense {
-		gridPlaceDense(gridItems, *rows, *columns)
 	}
 
 	return gridItems
 }
 
-// gridPlaceDense performs dense auto-placement algorithm.
+// gridAxisPlacement is an item's resolved placement on one grid axis.
+type gridAxisPlacement struct {
+	start int  // First track index; meaningless while auto
+	span  int  // Number of tracks covered, at least 1
+	auto  bool // Start still needs auto-placement
+}
+
+// gridResolveAxisPlacement resolves a start/end pair from Style against an
+// explicit grid of explicitCount tracks. The zero-value pair and
+// GridLineAuto mean auto, Span(n) gives a span, and negative line numbers
+// count back from the last explicit line.
 //
 // Algorithm based on CSS Grid Layout Module Level 1:
-// - §8.3.2: Dense Packing
+// - §8.3: Line-based Placement
+// - §8.3.1: Grid Placement Conflict Handling
 //
-// The dense packing algorithm tries to fill in holes left by larger items,
-// by placing smaller items in earlier grid cells if they fit.
-//
-// See: https://www.w3.org/TR/css-grid-1/#auto-placement-algo
-func gridPlaceDense(items []*gridItem, rows, columns []GridTrack) {
-	// Track which cells are occupied
-	occupied := make(map[[2]int]bool)
+// See: https://www.w3.org/TR/css-grid-1/#grid-placement-errors
+func gridResolveAxisPlacement(start, end, explicitCount int) gridAxisPlacement {
+	startSpan, startIsSpan := gridSpanValue(start)
+	endSpan, endIsSpan := gridSpanValue(end)
 
-	// Mark cells occupied by items with explicit positions
-	for _, item := range items {
-		// Only process items that have at least one explicit dimension
-		hasExplicitRow := item.node.Style.GridRowStart >= 0
-		hasExplicitCol := item.node.Style.GridColumnStart >= 0
+	// An end of 0 is the unset zero value, and a start of 0 with no
+	// definite end is unset too.
+	endAuto := end == 0 || end == GridLineAuto || endIsSpan
+	startAuto := start == GridLineAuto || startIsSpan || (start == 0 && endAuto)
 
-		if hasExplicitRow || hasExplicitCol {
-			for r := item.rowStart; r < item.rowEnd; r++ {
-				for c := item.colStart; c < item.colEnd; c++ {
-					occupied[[2]int{r, c}] = true
-				}
-			}
+	switch {
+	case startAuto && endAuto:
+		span := 1
+		if startIsSpan {
+			span = startSpan
+		} else if endIsSpan {
+			span = endSpan
 		}
+		return gridAxisPlacement{span: span, auto: true}
+	case startAuto:
+		// Definite end: count back from it
+		e := gridResolveLine(end, explicitCount)
+		span := 1
+		if startIsSpan {
+			span = startSpan
+		}
+		s := e - span
+		if s < 0 {
+			s = 0
+		}
+		if e <= s {
+			e = s + 1
+		}
+		return gridAxisPlacement{start: s, span: e - s}
+	case endAuto:
+		span := 1
+		if endIsSpan {
+			span = endSpan
+		}
+		return gridAxisPlacement{start: gridResolveLine(start, explicitCount), span: span}
+	default:
+		s := gridResolveLine(start, explicitCount)
+		e := gridResolveLine(end, explicitCount)
+		// Reversed lines are swapped; equal lines span one track
+		if e < s {
+			s, e = e, s
+		}
+		if e == s {
+			e = s + 1
+		}
+		return gridAxisPlacement{start: s, span: e - s}
 	}
+}
 
-	// Now place items without explicit positions using dense packing
-	for _, item := range items {
-		hasExplicitRow := item.node.Style.GridRowStart >= 0
-		hasExplicitCol := item.node.Style.GridColumnStart >= 0
-
-		// Only auto-place items without explicit positions
-		if !hasExplicitRow && !hasExplicitCol {
-			rowSpan := item.rowEnd - item.rowStart
-			colSpan := item.colEnd - item.colStart
-
-			// Try to find the first available spot
-			placed := false
-			for r := 0; r < len(rows) && !placed; r++ {
-				for c := 0; c < len(columns) && !placed; c++ {
-					// Check if this position can fit the item
-					canFit := true
-					if r+rowSpan > len(rows) || c+colSpan > len(columns) {
-						canFit = false
-					} else {
-						for dr := 0; dr < rowSpan && canFit; dr++ {
-							for dc := 0; dc < colSpan && canFit; dc++ {
-								if occupied[[2]int{r + dr, c + dc}] {
-									canFit = false
-								}
-							}
-						}
-					}
-
-					if canFit {
-						// Place the item here
-						item.rowStart = r
-						item.rowEnd = r + rowSpan
-						item.colStart = c
-						item.colEnd = c + colSpan
-
-						// Mark these cells as occupied
-						for dr := 0; dr < rowSpan; dr++ {
-							for dc := 0; dc < colSpan; dc++ {
-								occupied[[2]int{r + dr, c + dc}] = true
-							}
-						}
-						placed = true
-					}
-				}
-			}
+// gridResolveLine converts a line number to a 0-based line index. Negative
+// numbers count back from the end of the explicit grid (-1 is the last
+// line). Lines before the start of the grid clamp to 0, since implicit
+// tracks are only created after the explicit grid.
+func gridResolveLine(line, explicitCount int) int {
+	if line < 0 {
+		line = explicitCount + 1 + line
+	}
+	if line < 0 {
+		return 0
+	}
+	return line
+}
 
-			// If we couldn't place it, extend the grid and place at the end
-			if !placed {
-				// This shouldn't normally happen with proper grid sizing
-				// But handle it gracefully
-				item.rowStart = len(rows)
-				item.rowEnd = item.rowStart + rowSpan
-			}
-		}
+// gridSpanValue decodes a Span(n) value.
+func gridSpanValue(v int) (int, bool) {
+	if v > gridSpanBase && v <= gridSpanBase+gridSpanMax {
+		return v - gridSpanBase, true
+	}
+	return 0, false
+}
+
+// gridImplicitTrack returns the track used for implicit rows or columns
+// created from a grid-auto-rows or grid-auto-columns value. The zero value
+// means auto, so items placed outside the explicit grid are sized by their
+// content instead of collapsing to zero.
+//
+// Algorithm based on CSS Grid Layout Module Level 1:
+// - §7.6: Implicit Track Sizing
+//
+// See: https://www.w3.org/TR/css-grid-1/#auto-tracks
+func gridImplicitTrack(track GridTrack) GridTrack {
+	if track == (GridTrack{}) || (track.MinSize.Value == 0 && track.MaxSize.Value == Unbounded && track.Fraction == 0) {
+		return AutoTrack()
 	}
+	return track
 }
 
 // gridResolveAreas resolves named grid areas to explicit grid positions.
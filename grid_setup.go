@@ -99,26 +99,10 @@ func gridDetermineContainerSize(node *Node, constraints Constraints, ctx *Layout
 
 	// Use auto tracks if templates not specified
 	if len(setup.rows) == 0 {
-		setup.rows = []GridTrack{node.Style.GridAutoRows}
-		// Check if it's an auto track by resolving the sizes
-		if len(setup.rows) > 0 {
-			minSize := ResolveLength(setup.rows[0].MinSize, ctx, currentFontSize)
-			maxSize := ResolveLength(setup.rows[0].MaxSize, ctx, currentFontSize)
-			if minSize == 0 && maxSize >= Unbounded && setup.rows[0].Fraction == 0 {
-				setup.rows = []GridTrack{AutoTrack()}
-			}
-		}
+		setup.rows = []GridTrack{gridImplicitTrack(node.Style.GridAutoRows)}
 	}
 	if len(setup.columns) == 0 {
-		setup.columns = []GridTrack{node.Style.GridAutoColumns}
-		// Check if it's an auto track by resolving the sizes
-		if len(setup.columns) > 0 {
-			minSize := ResolveLength(setup.columns[0].MinSize, ctx, currentFontSize)
-			maxSize := ResolveLength(setup.columns[0].MaxSize, ctx, currentFontSize)
-			if minSize == 0 && maxSize >= Unbounded && setup.columns[0].Fraction == 0 {
-				setup.columns = []GridTrack{AutoTrack()}
-			}
-		}
+		setup.columns = []GridTrack{gridImplicitTrack(node.Style.GridAutoColumns)}
 	}
 
 	// Resolve gaps to pixels
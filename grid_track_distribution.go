@@ -63,9 +63,9 @@ func gridDistributeTrackSpace(
 		// This affects track positions, not sizes
 		return trackSizes, totalTrackSize
 
-	case AlignContentSpaceAround:
-		// Free space distributed around tracks (half at each end)
-		// Space before/after each track is equal
+	case AlignContentSpaceAround, AlignContentSpaceEvenly:
+		// Free space distributed around tracks (half at each end for
+		// space-around, equal at the ends for space-evenly)
 		// This affects track positions, not sizes
 		return trackSizes, totalTrackSize
 
@@ -104,12 +104,17 @@ func gridDistributeTrackSpace(
 // gridCalculateTrackOffsets calculates the starting position of each track based on alignment.
 //
 // This handles justify-content and align-content positioning of tracks within the grid container.
+// When the tracks overflow, distributed alignment falls back to start or
+// center, and unless overflow is unsafe the tracks start at the start edge.
+//
+// See: https://www.w3.org/TR/css-align-3/#overflow-values
 func gridCalculateTrackOffsets(
 	trackSizes []float64,
 	totalTrackSize float64,
 	availableSpace float64,
 	gap float64,
 	alignment AlignContent,
+	overflow OverflowAlignment,
 ) []float64 {
 	if len(trackSizes) == 0 {
 		return []float64{}
@@ -118,7 +123,15 @@ func gridCalculateTrackOffsets(
 	offsets := make([]float64, len(trackSizes))
 	freeSpace := availableSpace - totalTrackSize
 	if freeSpace < 0 {
-		freeSpace = 0
+		switch alignment {
+		case AlignContentSpaceBetween:
+			alignment = AlignContentFlexStart
+		case AlignContentSpaceAround, AlignContentSpaceEvenly:
+			alignment = AlignContentCenter
+		}
+		if overflow != OverflowAlignmentUnsafe {
+			freeSpace = 0
+		}
 	}
 
 	currentOffset := 0.0
@@ -159,6 +172,16 @@ func gridCalculateTrackOffsets(
 		}
 		return offsets
 
+	case AlignContentSpaceEvenly:
+		// Equal space before, between and after tracks
+		spaceEvenly := freeSpace / float64(len(trackSizes)+1)
+		currentOffset = spaceEvenly
+		for i := range trackSizes {
+			offsets[i] = currentOffset
+			currentOffset += trackSizes[i] + gap + spaceEvenly
+		}
+		return offsets
+
 	case AlignContentStretch:
 		// Tracks are stretched (sizes already adjusted), start from beginning
 		currentOffset = 0
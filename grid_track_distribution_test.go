@@ -164,6 +164,40 @@ func TestGridAlignContentSpaceAround(t *testing.T) {
 	}
 }
 
+// TestGridAlignContentSpaceEvenly tests align-content: space-evenly for grid rows
+// Free space should be split equally before, between and after tracks
+func TestGridAlignContentSpaceEvenly(t *testing.T) {
+	container := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(100))},
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(50)), FixedTrack(Px(50))},
+			AlignContent:        AlignContentSpaceEvenly,
+			Width:               Px(100),
+			Height:              Px(190), // Extra 90px of free space
+		},
+		Children: []*Node{
+			{Style: Style{Width: Px(50), Height: Px(50)}}, // Row 0
+			{Style: Style{Width: Px(50), Height: Px(50)}}, // Row 1
+		},
+	}
+
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutGrid(container, Loose(100, 190), ctx)
+
+	// 90px over 3 gaps: 30 before, 30 between, 30 after
+	if container.Children[0].Rect.Y != 30 {
+		t.Errorf("First item should be at Y=30, got %v", container.Children[0].Rect.Y)
+	}
+	if container.Children[1].Rect.Y != 110 {
+		t.Errorf("Second item should be at Y=110, got %v", container.Children[1].Rect.Y)
+	}
+	// Tracks keep their size
+	if container.Children[1].Rect.Height != 50 {
+		t.Errorf("Second item should keep height 50, got %v", container.Children[1].Rect.Height)
+	}
+}
+
 // TestGridAlignContentStretch tests align-content: stretch for grid rows
 // Track sizes should increase to fill available space
 func TestGridAlignContentStretch(t *testing.T) {
@@ -276,12 +310,10 @@ func TestGridAlignContentWithSpanning(t *testing.T) {
 		t.Errorf("First spanning item should be at Y=75, got %v", container.Children[0].Rect.Y)
 	}
 
-	// Second item: should be at Y=175 (75 + 50 + 50)
-	// Actually at Y=125, which suggests free space isn't being distributed as expected
-	// This might be because the spanning item's measurement affects the calculation
-	// For now, accept the actual behavior
-	if container.Children[1].Rect.Y != 125 {
-		t.Errorf("Second item should be at Y=125, got %v", container.Children[1].Rect.Y)
+	// Second item: auto-placed in row 2, after the cells the spanning item
+	// occupies → Y=175 (75 + 50 + 50)
+	if container.Children[1].Rect.Y != 175 {
+		t.Errorf("Second item should be at Y=175, got %v", container.Children[1].Rect.Y)
 	}
 }
 
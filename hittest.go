@@ -0,0 +1,252 @@
+package layout
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Text hit-testing and selection.
+//
+// Positions address the laid-out text, after white-space processing and
+// text-transform: a line of TextLayout.Lines and a rune offset into its
+// text as returned by TextLayout.LineText, which joins the line's boxes
+// with single spaces in the order they're drawn. Points and rects are
+// relative to the node's Rect origin, like the coordinates a renderer or an
+// input handler has once it subtracts the node's absolute position.
+
+// TextPosition is a caret position in laid-out text: before rune Rune of
+// line Line.
+type TextPosition struct {
+	Line int
+	Rune int
+}
+
+// Before reports whether p comes before q.
+func (p TextPosition) Before(q TextPosition) bool {
+	return p.Line < q.Line || (p.Line == q.Line && p.Rune < q.Rune)
+}
+
+// LineText returns the text of line i: its boxes joined by single spaces,
+// in visual order. Rune offsets of a TextPosition index into it.
+func (tl *TextLayout) LineText(i int) string {
+	if i < 0 || i >= len(tl.Lines) {
+		return ""
+	}
+	boxes := tl.Lines[i].Boxes
+	texts := make([]string, len(boxes))
+	for j, box := range boxes {
+		texts[j] = box.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// TextPositionAt returns the caret position nearest to the point (x, y),
+// relative to n.Rect's origin. Points above the first line or below the
+// last one hit those lines, and points beside a line hit its start or
+// end. The caret goes before the character under the point, or after it
+// if the point is past the character's middle. It returns false if n has
+// no text layout.
+//
+// Example:
+//
+//	pos, ok := node.TextPositionAt(mouseX-absX, mouseY-absY)
+func (n *Node) TextPositionAt(x, y float64) (TextPosition, bool) {
+	tl := n.TextLayout
+	if tl == nil || len(tl.Lines) == 0 {
+		return TextPosition{}, false
+	}
+	vertical := tl.Style.WritingMode.IsVertical()
+	inline, block := x-tl.ContentX, y-tl.ContentY
+	if vertical {
+		inline, block = block, inline
+	}
+
+	// The line whose block extent contains the point, or the closest one
+	line, best := 0, -1.0
+	for i := range tl.Lines {
+		start, _ := lineOffsets(&tl.Lines[i], vertical)
+		height := tl.lineHeight(i)
+		if block >= start && block < start+height {
+			line = i
+			break
+		}
+		d := block - (start + height/2)
+		if d < 0 {
+			d = -d
+		}
+		if best < 0 || d < best {
+			line, best = i, d
+		}
+	}
+
+	pos := TextPosition{Line: line}
+	closest := -1.0
+	for _, c := range tl.carets(line, nil) {
+		d := c.offset - inline
+		if d < 0 {
+			d = -d
+		}
+		if closest < 0 || d < closest {
+			pos.Rune, closest = c.rune, d
+		}
+	}
+	return pos, true
+}
+
+// TextRangeRects returns the rects covering the text from start up to
+// end, relative to n.Rect's origin: one per line, or more on a line where
+// bidi reordering makes the range discontiguous. Each rect spans the full
+// line height. The positions may be given in either order.
+//
+// Example:
+//
+//	for _, r := range node.TextRangeRects(selStart, selEnd) {
+//	    drawHighlight(absX+r.X, absY+r.Y, r.Width, r.Height)
+//	}
+func (n *Node) TextRangeRects(start, end TextPosition) []Rect {
+	tl := n.TextLayout
+	if tl == nil {
+		return nil
+	}
+	if end.Before(start) {
+		start, end = end, start
+	}
+	vertical := tl.Style.WritingMode.IsVertical()
+
+	var rects []Rect
+	for i := start.Line; i <= end.Line && i < len(tl.Lines); i++ {
+		if i < 0 {
+			continue
+		}
+		from, to := 0, -1 // To the end of the line
+		if i == start.Line {
+			from = start.Rune
+		}
+		if i == end.Line {
+			to = end.Rune
+		}
+
+		// Inline extents of the selected characters, merged where they touch
+		var spans [][2]float64
+		carets := tl.carets(i, nil)
+		for _, c := range carets {
+			if c.rune < from || (to >= 0 && c.rune >= to) || c.width == 0 {
+				continue
+			}
+			lo, hi := c.offset, c.offset+c.width
+			if c.width < 0 {
+				lo, hi = hi, lo
+			}
+			if k := len(spans) - 1; k >= 0 && lo <= spans[k][1]+0.001 && hi >= spans[k][0]-0.001 {
+				spans[k][0], spans[k][1] = min(spans[k][0], lo), max(spans[k][1], hi)
+				continue
+			}
+			spans = append(spans, [2]float64{lo, hi})
+		}
+
+		blockStart, _ := lineOffsets(&tl.Lines[i], vertical)
+		height := tl.lineHeight(i)
+		for _, s := range spans {
+			r := Rect{X: s[0], Y: blockStart, Width: s[1] - s[0], Height: height}
+			if vertical {
+				r = Rect{X: blockStart, Y: s[0], Width: height, Height: s[1] - s[0]}
+			}
+			r.X += tl.ContentX
+			r.Y += tl.ContentY
+			rects = append(rects, r)
+		}
+	}
+	return rects
+}
+
+// caret is a caret position on a line: before rune, at inline offset
+// offset, with the character there width wide (negative in right-to-left
+// boxes, whose characters run leftward).
+type caret struct {
+	rune   int
+	offset float64
+	width  float64
+}
+
+// carets returns the caret positions of line i in rune order, including
+// the one at the end of the line.
+func (tl *TextLayout) carets(i int, ctx *LayoutContext) []caret {
+	line := &tl.Lines[i]
+	style := tl.Style
+	if i == 0 && tl.FirstLineStyle != nil {
+		style = *tl.FirstLineStyle
+	}
+	_, x := lineOffsets(line, tl.Style.WritingMode.IsVertical())
+	lineStart := x
+	space := line.SpaceAdjustment
+	if line.SpaceCount > 0 {
+		space += line.SpaceWidth / float64(line.SpaceCount)
+	}
+
+	var carets []caret
+	r := 0
+	for j, box := range line.Boxes {
+		n := utf8.RuneCountInString(box.Text)
+		boxWidth := box.Width
+		if n > 1 {
+			boxWidth += float64(n-1) * line.CharacterAdjustment
+		}
+		rtl := box.Level%2 == 1
+
+		// Offsets of each character boundary from the box's start edge,
+		// with tabs advancing to the tab stops of the line, as in line
+		// layout
+		edges := make([]float64, n+1)
+		k := 0
+		for b := range box.Text {
+			if k > 0 {
+				w, _, _ := measureTabbed(box.Text[:b], x-lineStart, style, ctx)
+				edges[k] = w + float64(k)*line.CharacterAdjustment
+			}
+			k++
+		}
+		edges[n] = boxWidth
+
+		for k := 0; k < n; k++ {
+			c := caret{rune: r + k, offset: x + edges[k], width: edges[k+1] - edges[k]}
+			if rtl {
+				c.offset, c.width = x+boxWidth-edges[k], -c.width
+			}
+			carets = append(carets, c)
+		}
+		end := x + boxWidth
+		if rtl {
+			end = x
+		}
+		r += n
+		x += boxWidth
+		if j < len(line.Boxes)-1 {
+			// The space between boxes
+			carets = append(carets, caret{rune: r, offset: x, width: space})
+			r++
+			x += space
+		} else {
+			carets = append(carets, caret{rune: r, offset: end})
+		}
+	}
+	if len(line.Boxes) == 0 {
+		carets = append(carets, caret{offset: x})
+	}
+	return carets
+}
+
+// lineHeight returns the block size of line i.
+func (tl *TextLayout) lineHeight(i int) float64 {
+	if h := tl.Lines[i].Height; h > 0 {
+		return h
+	}
+	return tl.LineHeight
+}
+
+// lineOffsets returns the block and inline offsets of line's start.
+func lineOffsets(line *TextLine, vertical bool) (block, inline float64) {
+	if vertical {
+		return line.OffsetX, line.OffsetY
+	}
+	return line.OffsetY, line.OffsetX
+}
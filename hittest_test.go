@@ -0,0 +1,128 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func layoutHitTestText(text string, width float64, style TextStyle) *Node {
+	style.FontSize, style.LineHeight = 20, 1
+	style.WordSpacing, style.LetterSpacing = -1, -1
+	node := Text(text, Style{Padding: Uniform(Px(5)), TextStyle: &style})
+	LayoutText(node, Loose(width, 200), NewLayoutContext(800, 600, 16))
+	return node
+}
+
+func TestTextPositionAt(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// "Hello world" on line 0 and "foo" on line 1, inside 5px padding
+	node := layoutHitTestText("Hello world foo", 130, TextStyle{})
+	if got := node.TextLayout.LineText(0); got != "Hello world" {
+		t.Fatalf("Expected line 0 to be %q, got %q", "Hello world", got)
+	}
+
+	tests := []struct {
+		x, y float64
+		want TextPosition
+	}{
+		{7, 6, TextPosition{0, 0}},
+		{18, 10, TextPosition{0, 1}},   // Left half of "e"
+		{21, 10, TextPosition{0, 2}},   // Right half of "e"
+		{68, 10, TextPosition{0, 6}},   // Start of "world", past the space
+		{300, 10, TextPosition{0, 11}}, // Beside the line: its end
+		{-10, 10, TextPosition{0, 0}},
+		{29, 30, TextPosition{1, 2}},
+		{29, 500, TextPosition{1, 2}}, // Below the text: the last line
+		{29, -50, TextPosition{0, 2}}, // Above the text: the first line
+	}
+	for _, tt := range tests {
+		got, ok := node.TextPositionAt(tt.x, tt.y)
+		if !ok || got != tt.want {
+			t.Errorf("At (%v, %v): expected %+v, got %+v", tt.x, tt.y, tt.want, got)
+		}
+	}
+
+	if _, ok := (&Node{}).TextPositionAt(0, 0); ok {
+		t.Error("Expected no position without a text layout")
+	}
+}
+
+func TestTextPositionAtTabs(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// The tab advances from 45 to the tab stop at 85, 4 spaces apart
+	for _, ws := range []WhiteSpace{WhiteSpacePre, WhiteSpacePreWrap} {
+		node := layoutHitTestText("a  b\tc", 300, TextStyle{WhiteSpace: ws, TabSize: 4})
+		tests := []struct {
+			x    float64
+			want int
+		}{
+			{60, 4}, // Left half of the tab
+			{70, 5}, // Right half of the tab
+			{80, 5},
+			{92, 6}, // Right half of "c"
+		}
+		for _, tt := range tests {
+			got, ok := node.TextPositionAt(tt.x, 10)
+			if !ok || got != (TextPosition{0, tt.want}) {
+				t.Errorf("White-space %v at %v: expected rune %d, got %+v", ws, tt.x, tt.want, got)
+			}
+		}
+		want := []Rect{{X: 45, Y: 5, Width: 50, Height: 20}}
+		if got := node.TextRangeRects(TextPosition{0, 4}, TextPosition{0, 6}); !reflect.DeepEqual(got, want) {
+			t.Errorf("White-space %v: expected %+v, got %+v", ws, want, got)
+		}
+	}
+}
+
+func TestTextRangeRects(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	node := layoutHitTestText("Hello world foo", 130, TextStyle{})
+
+	// From "world" to "fo", given backwards
+	got := node.TextRangeRects(TextPosition{1, 2}, TextPosition{0, 6})
+	want := []Rect{
+		{X: 65, Y: 5, Width: 50, Height: 20},
+		{X: 5, Y: 25, Width: 20, Height: 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Across the space between words
+	got = node.TextRangeRects(TextPosition{0, 4}, TextPosition{0, 7})
+	want = []Rect{{X: 45, Y: 5, Width: 30, Height: 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	if got := node.TextRangeRects(TextPosition{0, 3}, TextPosition{0, 3}); got != nil {
+		t.Errorf("Expected no rects for an empty range, got %+v", got)
+	}
+}
+
+func TestTextHitTestRTL(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// A right-to-left box runs leftward: its first character is on the
+	// right. The line is aligned to the right of the 390px content box.
+	node := layoutHitTestText("שלום", 400, TextStyle{Direction: DirectionRTL})
+
+	if got, _ := node.TextPositionAt(5+388, 10); got != (TextPosition{0, 0}) {
+		t.Errorf("Expected the start at the right edge, got %+v", got)
+	}
+	if got, _ := node.TextPositionAt(5+352, 10); got != (TextPosition{0, 4}) {
+		t.Errorf("Expected the end at the left edge, got %+v", got)
+	}
+	got := node.TextRangeRects(TextPosition{0, 1}, TextPosition{0, 3})
+	want := []Rect{{X: 365, Y: 5, Width: 20, Height: 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,339 @@
+// Package htmlimport builds layout trees from HTML snippets, so a layout
+// can be written as markup and styled with CSS:
+//
+//	root, err := htmlimport.Parse(`
+//	    <style>
+//	        .row  { display: flex; gap: 8px }
+//	        .card { flex: 1; padding: 12px }
+//	    </style>
+//	    <div class="row">
+//	        <section class="card"><h2>Inbox</h2><p>3 new messages</p></section>
+//	        <section class="card" style="flex: 2">Drafts</section>
+//	    </div>`)
+//	if err != nil {
+//	    log.Print(err) // invalid CSS is dropped, the rest is kept
+//	}
+//	layout.Layout(root, layout.Loose(800, layout.Unbounded), ctx)
+//
+// Every element becomes a node with the element's tag, id, classes and
+// other attributes, and every run of text a text node (see layout.Text).
+// The rules of <style> elements and style attributes are applied with the
+// css package, so selectors, the cascade and inherited text properties
+// work as described there.
+//
+// It imports snippets, not web pages:
+//
+//   - Elements are blocks unless styled otherwise; the engine doesn't flow
+//     inline elements (span, em, a...) into lines of text, so each is a
+//     box of its own.
+//   - Whitespace-only text is dropped, and other text is trimmed.
+//   - head, title, script, template and noscript elements, and their
+//     content, aren't part of the tree; <style> elements in them still
+//     apply.
+//   - End tags close the innermost open element of their name, and stray
+//     ones are ignored. Apart from void elements (br, img, input...), end
+//     tags that HTML lets authors leave out, like those of p and li, must
+//     be written; <tag/> closes an element too.
+package htmlimport
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/layout/css"
+)
+
+// Options controls ParseWithOptions.
+type Options struct {
+	// Stylesheet, if non-nil, is applied before the snippet's <style>
+	// elements, like a user agent stylesheet: with equal specificity, the
+	// snippet's rules win.
+	Stylesheet *css.Stylesheet
+
+	// Context, if non-nil, applies the @media rules matching it (see
+	// css.Stylesheet.ApplyContext); otherwise they are skipped.
+	Context *layout.LayoutContext
+}
+
+// Parse builds a layout tree from an HTML snippet. The root is the
+// snippet's only top-level element, or, if it has several top-level
+// elements or text, an anonymous block node containing them.
+//
+// Like css.Parse, Parse returns the tree along with an error describing
+// the CSS rules and declarations it dropped.
+func Parse(src string) (*layout.Node, error) {
+	return ParseWithOptions(src, Options{})
+}
+
+// ParseWithOptions builds a layout tree from an HTML snippet like Parse,
+// with options for styling it.
+//
+// Example, with default styles for the snippet's elements:
+//
+//	ua, _ := css.Parse("h1 { font-size: 2em; margin: 0.67em 0 } ul { padding-left: 40px }")
+//	root, err := htmlimport.ParseWithOptions(src, htmlimport.Options{Stylesheet: ua})
+func ParseWithOptions(src string, opts Options) (*layout.Node, error) {
+	p := &parser{src: src}
+	doc := &layout.Node{}
+	p.stack = []*layout.Node{doc}
+	p.parse()
+
+	root := doc
+	if len(doc.Children) == 1 && doc.Children[0].Tag != "" {
+		root = doc.Children[0]
+	}
+
+	sheet := &css.Stylesheet{}
+	if opts.Stylesheet != nil {
+		sheet.Rules = append(sheet.Rules, opts.Stylesheet.Rules...)
+	}
+	for _, style := range p.styles {
+		s, err := css.Parse(style)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("<style>: %w", err))
+		}
+		sheet.Rules = append(sheet.Rules, s.Rules...)
+	}
+	sheet.ApplyContext(root, opts.Context)
+	return root, errors.Join(p.errs...)
+}
+
+// voidElements are the elements without content or end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements are the elements whose content is text up to their end
+// tag, not markup.
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// hiddenElements are the elements that aren't part of the tree.
+var hiddenElements = map[string]bool{
+	"head": true, "title": true, "script": true, "style": true, "template": true, "noscript": true,
+	"meta": true, "link": true, "base": true,
+}
+
+// parser builds a tree from HTML.
+type parser struct {
+	src    string
+	pos    int
+	stack  []*layout.Node // Open elements, the document first
+	styles []string       // Contents of the <style> elements
+	errs   []error
+}
+
+func (p *parser) parse() {
+	for p.pos < len(p.src) {
+		i := strings.IndexByte(p.src[p.pos:], '<')
+		if i < 0 {
+			p.text(p.src[p.pos:])
+			return
+		}
+		p.text(p.src[p.pos : p.pos+i])
+		p.pos += i
+		rest := p.src[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			p.skipPast("-->")
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			// Doctype or processing instruction
+			p.skipPast(">")
+		case strings.HasPrefix(rest, "</"):
+			p.pos += 2
+			name := strings.ToLower(p.name())
+			p.skipPast(">")
+			p.endTag(name)
+		case len(rest) > 1 && isLetter(rest[1]):
+			p.pos++
+			p.startTag()
+		default:
+			p.text("<")
+			p.pos++
+		}
+	}
+}
+
+// startTag parses a start tag after its "<" and opens its element.
+func (p *parser) startTag() {
+	name := strings.ToLower(p.name())
+	node := &layout.Node{Tag: name}
+	selfClosing := false
+	for p.pos < len(p.src) {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		if c := p.src[p.pos]; c == '>' {
+			p.pos++
+			break
+		} else if c == '/' {
+			p.pos++
+			if p.pos < len(p.src) && p.src[p.pos] == '>' {
+				p.pos++
+				selfClosing = true
+				break
+			}
+			continue
+		}
+		attr := strings.ToLower(p.name())
+		if attr == "" {
+			// Not a valid name character
+			p.pos++
+			continue
+		}
+		p.skipSpace()
+		value := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '=' {
+			p.pos++
+			p.skipSpace()
+			value = html.UnescapeString(p.attrValue())
+		}
+		p.setAttribute(node, attr, value)
+	}
+
+	parent := p.stack[len(p.stack)-1]
+	if !hiddenElements[name] {
+		parent.Children = append(parent.Children, node)
+	}
+	switch {
+	case rawTextElements[name]:
+		end := indexFold(p.src[p.pos:], "</"+name)
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		content := p.src[p.pos : p.pos+end]
+		p.pos += end
+		p.skipPast(">")
+		switch name {
+		case "style":
+			p.styles = append(p.styles, content)
+		case "textarea":
+			p.addText(node, html.UnescapeString(content))
+		}
+	case !voidElements[name] && !selfClosing:
+		p.stack = append(p.stack, node)
+	}
+}
+
+// setAttribute sets an attribute of node, keeping the first value of
+// repeated ones as HTML does.
+func (p *parser) setAttribute(node *layout.Node, name, value string) {
+	switch name {
+	case "id":
+		if node.ID == "" {
+			node.ID = value
+		}
+	case "class":
+		if node.Classes == nil {
+			node.Classes = strings.Fields(value)
+		}
+	default:
+		if node.Attributes == nil {
+			node.Attributes = make(map[string]string)
+		}
+		if _, ok := node.Attributes[name]; !ok {
+			node.Attributes[name] = value
+		}
+		if name == "style" {
+			if _, err := css.ParseDeclarations(value); err != nil {
+				p.errs = append(p.errs, fmt.Errorf("<%s> style attribute: %w", node.Tag, err))
+			}
+		}
+	}
+}
+
+// endTag closes the innermost open element named name, and the elements
+// open inside it.
+func (p *parser) endTag(name string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Tag == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+// text adds the text between tags to the open element.
+func (p *parser) text(s string) {
+	p.addText(p.stack[len(p.stack)-1], html.UnescapeString(s))
+}
+
+// addText adds a text node to parent, unless s is only whitespace.
+func (p *parser) addText(parent *layout.Node, s string) {
+	if s = strings.TrimSpace(s); s != "" {
+		parent.Children = append(parent.Children, layout.Text(s))
+	}
+}
+
+// name returns the tag or attribute name at the current position and
+// skips it.
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if isSpace(c) || c == '>' || c == '/' || c == '=' {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// attrValue returns the quoted or unquoted attribute value at the
+// current position and skips it.
+func (p *parser) attrValue() string {
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if q := p.src[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], q)
+		if end < 0 {
+			end = len(p.src) - p.pos - 1
+		}
+		value := p.src[p.pos+1 : p.pos+1+end]
+		p.pos = min(p.pos+end+2, len(p.src))
+		return value
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && p.src[p.pos] != '>' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// skipPast skips past the next occurrence of s, or to the end.
+func (p *parser) skipPast(s string) {
+	if i := strings.Index(p.src[p.pos:], s); i >= 0 {
+		p.pos += i + len(s)
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// indexFold is strings.Index for an ASCII substr, ignoring case.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,144 @@
+package htmlimport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/layout/css"
+)
+
+func TestParseTree(t *testing.T) {
+	root, err := Parse(`<!DOCTYPE html>
+		<ul id="list" class="menu  dark" data-x=1 hidden>
+			<!-- items -->
+			<li>One &amp; <em>two</em></li>
+			<li><img src="a.png" alt='A'>Three<br></li>
+			<li/>
+		</ul>`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if root.Tag != "ul" || root.ID != "list" || !reflect.DeepEqual(root.Classes, []string{"menu", "dark"}) {
+		t.Errorf("Expected ul#list.menu.dark, got %s#%s %v", root.Tag, root.ID, root.Classes)
+	}
+	if want := map[string]string{"data-x": "1", "hidden": ""}; !reflect.DeepEqual(root.Attributes, want) {
+		t.Errorf("Expected attributes %v, got %v", want, root.Attributes)
+	}
+	if len(root.Children) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(root.Children))
+	}
+
+	first := root.Children[0]
+	if len(first.Children) != 2 || first.Children[0].Text != "One &" || first.Children[1].Tag != "em" {
+		t.Fatalf("Expected text and em in the first item, got %+v", first.Children)
+	}
+	if text := first.Children[0]; text.Style.Display != layout.DisplayInlineText {
+		t.Errorf("Expected a text node, got display %v", text.Style.Display)
+	}
+	if em := first.Children[1]; len(em.Children) != 1 || em.Children[0].Text != "two" {
+		t.Errorf("Expected em with text two, got %+v", em.Children)
+	}
+
+	second := root.Children[1]
+	if len(second.Children) != 3 || second.Children[0].Tag != "img" || second.Children[1].Text != "Three" || second.Children[2].Tag != "br" {
+		t.Fatalf("Expected img, text and br in the second item, got %+v", second.Children)
+	}
+	if img := second.Children[0]; img.Attributes["src"] != "a.png" || img.Attributes["alt"] != "A" || len(img.Children) != 0 {
+		t.Errorf("Expected img with src and alt, got %+v", img)
+	}
+	if len(root.Children[2].Children) != 0 {
+		t.Errorf("Expected <li/> to be empty, got %+v", root.Children[2].Children)
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	root, _ := Parse(`<p>a</p><p>b</p>`)
+	if root.Tag != "" || len(root.Children) != 2 {
+		t.Errorf("Expected an anonymous root with 2 children, got %q with %d", root.Tag, len(root.Children))
+	}
+	root, _ = Parse(`Hello`)
+	if root.Tag != "" || len(root.Children) != 1 || root.Children[0].Text != "Hello" {
+		t.Errorf("Expected an anonymous root with a text node, got %+v", root)
+	}
+}
+
+func TestParseStyles(t *testing.T) {
+	root, err := Parse(`<html>
+		<head><title>Ignored</title><style>
+			.row { display: flex; gap: 8px }
+			.row > .card { width: 50px }
+			@media (min-width: 600px) { .card { width: 200px } }
+		</style><script>if (a < b) document.write("<div>")</script></head>
+		<body class="row">
+			<section class="card"></section>
+			<section class="card" style="width: 80px; height: 20px">Text</section>
+		</body>
+	</html>`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(root.Children) != 1 || root.Children[0].Tag != "body" {
+		t.Fatalf("Expected html with only body, got %+v", root.Children)
+	}
+	body := root.Children[0]
+	if body.Style.Display != layout.DisplayFlex {
+		t.Errorf("Expected body to be flex, got %v", body.Style.Display)
+	}
+	if len(body.Children) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(body.Children))
+	}
+	if w := body.Children[0].Style.Width; w != layout.Px(50) {
+		t.Errorf("Expected width 50px from the stylesheet, got %v", w)
+	}
+	if w := body.Children[1].Style.Width; w != layout.Px(80) {
+		t.Errorf("Expected width 80px from the style attribute, got %v", w)
+	}
+
+	layout.Layout(root, layout.Loose(400, layout.Unbounded), layout.NewLayoutContext(400, 300, 16))
+	if x := body.Children[1].Rect.X; x != 58 {
+		t.Errorf("Expected the second section at x 58, got %v", x)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	ua, _ := css.Parse(`section { width: 10px; height: 10px } .card { height: 30px }`)
+	root, err := ParseWithOptions(`<div>
+		<style>section { width: 20px } @media (min-width: 600px) { section { width: 600px } }</style>
+		<section class="card"></section>
+	</div>`, Options{Stylesheet: ua, Context: layout.NewLayoutContext(800, 600, 16)})
+	if err != nil {
+		t.Fatalf("ParseWithOptions failed: %v", err)
+	}
+	section := root.Children[0]
+	if section.Style.Width != layout.Px(600) || section.Style.Height != layout.Px(30) {
+		t.Errorf("Expected 600×30px, got %v×%v", section.Style.Width, section.Style.Height)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	root, err := Parse(`<div style="width: 10px; colour: red"><style>.a { width: }</style>ok</div>`)
+	if err == nil {
+		t.Fatal("Expected errors for invalid CSS")
+	}
+	if !strings.Contains(err.Error(), "<style>") || !strings.Contains(err.Error(), "<div> style attribute") {
+		t.Errorf("Expected errors for the stylesheet and the style attribute, got %v", err)
+	}
+	if root.Style.Width != layout.Px(10) || len(root.Children) != 1 {
+		t.Errorf("Expected the valid CSS and content to be kept, got %+v", root)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, src := range []string{"<", "<div", "<div class=", `<div class="x`, "</div>", "<!--", "<div><span></div>text", "a < b", "<style>x"} {
+		root, _ := Parse(src)
+		if root == nil {
+			t.Errorf("Expected a tree for %q", src)
+		}
+	}
+	root, _ := Parse("<div><span></div>after")
+	if len(root.Children) != 2 || root.Children[1].Text != "after" {
+		t.Errorf("Expected </div> to close span too, got %+v", root.Children)
+	}
+}
@@ -0,0 +1,94 @@
+package layout
+
+// InheritTextStyle returns the computed text style of a node with the
+// given TextStyle whose parent's computed text style is parent. It returns
+// nil if both are nil.
+//
+// Inheritance follows CSS, at the granularity Go zero values allow:
+//   - A node without a TextStyle inherits all of its parent's text
+//     properties except VerticalAlign, which isn't inherited.
+//   - A node with a TextStyle keeps it, except that a zero FontSize,
+//     FontFamily or FontWeight is inherited.
+//
+// Layout applies this to every node, so text nodes pick up the TextStyle of
+// their nearest styled ancestor; the result is in TextLayout.Style.
+//
+// Example:
+//
+//	card.Style.TextStyle = &layout.TextStyle{FontSize: 14, TextAlign: layout.TextAlignCenter}
+//	// Text children of card are laid out centered at 14px without a TextStyle of their own
+//
+// See: https://www.w3.org/TR/css-cascade-4/#inheriting
+func InheritTextStyle(parent, style *TextStyle) *TextStyle {
+	switch {
+	case style == nil && parent == nil:
+		return nil
+	case style == nil:
+		computed := *parent
+		computed.VerticalAlign = VerticalAlignBaseline
+		return &computed
+	}
+	computed := *style
+	if parent != nil {
+		if computed.FontSize <= 0 {
+			computed.FontSize = parent.FontSize
+		}
+		if computed.FontFamily == "" {
+			computed.FontFamily = parent.FontFamily
+		}
+		if computed.FontWeight == 0 {
+			computed.FontWeight = parent.FontWeight
+		}
+	}
+	return &computed
+}
+
+// Visible reports whether a box with visibility v is painted, given
+// whether its parent is. Renderers walking the tree down from the root pass
+// true for the root.
+//
+// Example:
+//
+//	visible := node.Style.Visibility.Visible(parentVisible)
+func (v Visibility) Visible(parentVisible bool) bool {
+	switch v {
+	case VisibilityVisible:
+		return true
+	case VisibilityHidden:
+		return false
+	}
+	return parentVisible
+}
+
+// computedTextStyle returns node's computed text style during layout, or
+// nil if neither node nor any ancestor has a TextStyle.
+func computedTextStyle(node *Node, ctx *LayoutContext) *TextStyle {
+	var parent *TextStyle
+	if ctx != nil {
+		parent = ctx.inheritedTextStyle
+	}
+	if node.Style.TextStyle == nil && (parent == nil || parent.VerticalAlign == VerticalAlignBaseline) {
+		// Nothing to change; share the parent's
+		return parent
+	}
+	computed := InheritTextStyle(parent, node.Style.TextStyle)
+	if computed.FontSize <= 0 {
+		computed.FontSize = getCurrentFontSize(node, ctx)
+	}
+	return computed
+}
+
+// inheritStyle makes node's font size (fontSize) and computed text style
+// the ones inherited by the children laid out until the returned function
+// restores the previous ones.
+func inheritStyle(ctx *LayoutContext, node *Node, fontSize float64) (restore func()) {
+	if ctx == nil {
+		return func() {}
+	}
+	previousFontSize, previousTextStyle := ctx.inheritedFontSize, ctx.inheritedTextStyle
+	ctx.inheritedTextStyle = computedTextStyle(node, ctx)
+	ctx.inheritedFontSize = fontSize
+	return func() {
+		ctx.inheritedFontSize, ctx.inheritedTextStyle = previousFontSize, previousTextStyle
+	}
+}
@@ -0,0 +1,90 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTextStyleInherits(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	inherited := Text("Hi")
+	overridden := Text("Hi", Style{TextStyle: &TextStyle{LineHeight: 1, TextAlign: TextAlignRight}})
+	root := &Node{
+		Style: Style{
+			Display:   DisplayBlock,
+			Width:     Px(200),
+			TextStyle: &TextStyle{FontSize: 20, TextAlign: TextAlignCenter, VerticalAlign: VerticalAlignTop},
+		},
+		Children: []*Node{
+			{Style: Style{Display: DisplayBlock}, Children: []*Node{inherited}},
+			overridden,
+		},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	Layout(root, Loose(800, 600), ctx)
+
+	got := inherited.TextLayout.Style
+	if got.FontSize != 20 || got.TextAlign != TextAlignCenter {
+		t.Errorf("Expected the root's 20px centered text style, got size %v align %v", got.FontSize, got.TextAlign)
+	}
+	// vertical-align isn't inherited
+	if got.VerticalAlign != VerticalAlignBaseline {
+		t.Errorf("Expected baseline vertical alignment, got %v", got.VerticalAlign)
+	}
+	if inherited.Style.TextStyle != nil {
+		t.Errorf("Expected the node's own TextStyle to stay nil, got %+v", inherited.Style.TextStyle)
+	}
+
+	// A TextStyle of its own wins, but a zero font size is still inherited
+	got = overridden.TextLayout.Style
+	if got.FontSize != 20 || got.TextAlign != TextAlignRight {
+		t.Errorf("Expected 20px right-aligned text, got size %v align %v", got.FontSize, got.TextAlign)
+	}
+	if math.Abs(overridden.Rect.Height-20) > 0.01 {
+		t.Errorf("Expected a 20px line, got height %.2f", overridden.Rect.Height)
+	}
+
+	// Re-layout picks up a change to the ancestor
+	root.Style.TextStyle.FontSize = 10
+	Layout(root, Loose(800, 600), ctx)
+	if got := inherited.TextLayout.Style.FontSize; got != 10 {
+		t.Errorf("Expected font size 10 after re-layout, got %v", got)
+	}
+}
+
+func TestInheritTextStyle(t *testing.T) {
+	if got := InheritTextStyle(nil, nil); got != nil {
+		t.Errorf("Expected nil, got %+v", got)
+	}
+	parent := &TextStyle{FontSize: 12, FontFamily: "serif", FontWeight: FontWeightBold, Direction: DirectionRTL}
+	got := InheritTextStyle(parent, &TextStyle{FontFamily: "mono"})
+	if got.FontSize != 12 || got.FontFamily != "mono" || got.FontWeight != FontWeightBold {
+		t.Errorf("Expected 12px bold mono, got %+v", got)
+	}
+	if got.Direction != DirectionLTR {
+		t.Errorf("Expected the node's own direction, got %v", got.Direction)
+	}
+	if got := InheritTextStyle(parent, nil); got == parent || got.Direction != DirectionRTL {
+		t.Errorf("Expected a copy of the parent's style, got %+v", got)
+	}
+}
+
+func TestVisibilityVisible(t *testing.T) {
+	tests := []struct {
+		v      Visibility
+		parent bool
+		want   bool
+	}{
+		{VisibilityInherit, true, true},
+		{VisibilityInherit, false, false},
+		{VisibilityVisible, false, true},
+		{VisibilityHidden, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Visible(tt.parent); got != tt.want {
+			t.Errorf("Expected Visibility(%d).Visible(%v) = %v, got %v", tt.v, tt.parent, tt.want, got)
+		}
+	}
+}
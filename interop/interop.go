@@ -0,0 +1,190 @@
+// Package interop converts layout trees to and from the JSON test
+// fixtures of other layout engines, Yoga and Taffy, so their flexbox and
+// grid test corpora can be run against this engine and layouts exchanged
+// with code in other languages.
+//
+// A fixture is a tree with its expected layout:
+//
+//	data, _ := os.ReadFile("taffy/absolute_layout_start_top_end_bottom.json")
+//	f, err := interop.FromTaffyJSON(data)
+//	if err != nil {
+//	    log.Print(err) // properties this engine lacks are dropped
+//	}
+//	if err := f.Verify(0.5); err != nil {
+//	    log.Print(err) // the nodes whose layout differs
+//	}
+//
+// Styles are converted through CSS: a fixture's properties are turned into
+// CSS declarations applied with the css package, and a tree's style is
+// read as the declarations render/html writes for it. Each format's
+// defaults, which differ from CSS's, are taken into account both ways.
+// Text and measure functions aren't converted.
+package interop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/layout/css"
+	"github.com/SCKelemen/layout/render/html"
+	"github.com/SCKelemen/layout/serialize"
+)
+
+// Fixture is a layout test case of another engine's corpus, or a layout
+// to exchange with one: a tree whose Rects are the layout, and the
+// constraints it's laid out with.
+type Fixture struct {
+	Root        *layout.Node
+	Constraints layout.Constraints
+}
+
+// Verify lays out the fixture's tree and checks that the layout matches
+// its Rects within tolerance, returning a *serialize.RoundTripError if not
+// (see serialize.VerifyRoundTrip).
+func (f *Fixture) Verify(tolerance float64) error {
+	return serialize.VerifyRoundTrip(f.Root, serialize.RoundTripOptions{Constraints: f.Constraints, Tolerance: tolerance})
+}
+
+// declaration is a CSS declaration of a fixture node's style.
+type declaration struct {
+	property, value string
+}
+
+// applyCSS applies defaults, then decls, to style, appending an error
+// for each declaration the engine doesn't support.
+func applyCSS(style *layout.Style, defaults, decls []declaration, path string, errs *[]error) {
+	for _, d := range defaults {
+		// Valid by construction
+		_ = css.SetProperty(style, d.property, d.value)
+	}
+	for _, d := range decls {
+		if err := css.SetProperty(style, d.property, d.value); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+}
+
+// styleDeclarations returns the CSS declarations of n's style (see
+// html.StyleCSS), by property.
+func styleDeclarations(n, parent *layout.Node) map[string]string {
+	inFlex := parent != nil && parent.Style.Display == layout.DisplayFlex
+	decls, _ := css.ParseDeclarations(html.StyleCSS(n, inFlex))
+	m := make(map[string]string, len(decls))
+	for _, d := range decls {
+		m[d.Property] = d.Value
+	}
+	return m
+}
+
+// childPath returns the path of a parent's i-th child, like "/0/2".
+func childPath(parent string, i int) string {
+	return parent + "/" + strconv.Itoa(i)
+}
+
+// rootPath returns "/" for the root's empty path, for messages.
+func rootPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// cssLength is a CSS length or keyword split into its number and unit:
+// "12.5px" is {12.5, "px"}, "auto" is {0, "auto"}.
+type cssLength struct {
+	value float64
+	unit  string
+}
+
+// parseCSSLength splits a length written by html.StyleCSS.
+func parseCSSLength(s string) (cssLength, bool) {
+	i := 0
+	for i < len(s) && (s[i] == '-' || s[i] == '+' || s[i] == '.' || s[i] >= '0' && s[i] <= '9' || s[i] == 'e' && i > 0) {
+		i++
+	}
+	if i == 0 {
+		if strings.ContainsAny(s, "() ") {
+			return cssLength{}, false
+		}
+		return cssLength{unit: s}, true
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return cssLength{}, false
+	}
+	unit := s[i:]
+	if unit == "" {
+		unit = "px" // Unitless zero
+	}
+	return cssLength{value: v, unit: unit}, true
+}
+
+// splitTopLevel splits s at sep outside parentheses, trimming the parts.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				if part := strings.TrimSpace(s[start:i]); part != "" {
+					parts = append(parts, part)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if part := strings.TrimSpace(s[start:]); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// cssFunction splits "name(a, b)" into its name and arguments.
+func cssFunction(s string) (name string, args []string, ok bool) {
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", nil, false
+	}
+	return s[:open], splitTopLevel(s[open+1:len(s)-1], ','), true
+}
+
+// formatNumber formats a number for CSS.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// sides are the physical sides, in the order of CSS shorthands.
+var sides = []string{"top", "right", "bottom", "left"}
+
+// kebabToCamel converts a CSS property name to camel case:
+// "flex-direction" is "flexDirection".
+func kebabToCamel(s string) string {
+	parts := strings.Split(s, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// camelToKebab converts a camel case name to a CSS property name:
+// "flexDirection" is "flex-direction".
+func camelToKebab(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('-')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,549 @@
+package interop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// taffyNode is a node of Taffy's fixture JSON: the description of an
+// element that Taffy's test generator (scripts/gentest) takes from a
+// browser, with the element's inline style and its layout.
+type taffyNode struct {
+	Style    map[string]json.RawMessage `json:"style"`
+	Layout   *taffyLayout               `json:"layout,omitempty"`
+	Children []*taffyNode               `json:"children,omitempty"`
+}
+
+// taffyLayout is a node's border box, relative to its parent's.
+type taffyLayout struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// taffyDimension is a length: {"unit": "points", "value": 10}, a
+// percentage as a fraction, {"unit": "percent", "value": 0.5}, or a
+// keyword, {"unit": "auto"}.
+type taffyDimension struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value,omitempty"`
+}
+
+// taffyTrack is a grid track sizing function: a scalar, like a dimension
+// or {"kind": "scalar", "unit": "fraction", "value": 1}, or a function
+// (minmax, fit-content or repeat) of tracks.
+type taffyTrack struct {
+	Kind        string           `json:"kind"`
+	Unit        string           `json:"unit,omitempty"`
+	Value       float64          `json:"value,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Repetitions *taffyRepetition `json:"repetitions,omitempty"`
+	Arguments   []taffyTrack     `json:"arguments,omitempty"`
+}
+
+// taffyRepetition is the count of repeat(): {"kind": "count", "value":
+// 3}, or {"kind": "auto-fill"} or {"kind": "auto-fit"}.
+type taffyRepetition struct {
+	Kind  string `json:"kind"`
+	Value int    `json:"value,omitempty"`
+}
+
+// taffyPlacement is grid-row or grid-column.
+type taffyPlacement struct {
+	Start taffyLine `json:"start"`
+	End   taffyLine `json:"end"`
+}
+
+// taffyLine is a grid line: {"kind": "line", "value": 2} (numbered from
+// 1, as in CSS), {"kind": "span", "value": 2} or {"kind": "auto"}.
+type taffyLine struct {
+	Kind  string `json:"kind"`
+	Value int    `json:"value,omitempty"`
+}
+
+// taffyDefaults are Taffy's defaults that differ from CSS's initial
+// values (or from the engine's), as declarations.
+var taffyDefaults = []declaration{
+	{"display", "flex"},
+	{"box-sizing", "border-box"},
+	{"flex-shrink", "1"},
+}
+
+// Taffy style properties by kind; enums have the values of the CSS
+// property of the same name, in camel case.
+var (
+	taffyEnums = map[string]bool{
+		"display": true, "position": true, "boxSizing": true, "direction": true, "writingMode": true,
+		"flexDirection": true, "flexWrap": true, "justifyContent": true, "alignItems": true,
+		"alignSelf": true, "alignContent": true, "justifyItems": true, "justifySelf": true,
+		"gridAutoFlow": true,
+	}
+	taffyNumbers = map[string]bool{"flexGrow": true, "flexShrink": true, "aspectRatio": true}
+	taffyTracks  = map[string]bool{
+		"gridTemplateRows": true, "gridTemplateColumns": true, "gridAutoRows": true, "gridAutoColumns": true,
+	}
+	// taffyEdges maps the edge properties to the format of their CSS
+	// properties, with the side substituted
+	taffyEdges = map[string]string{
+		"margin": "margin-%s", "padding": "padding-%s", "border": "border-%s-width", "inset": "%s",
+	}
+	// taffySizes maps the size properties to the prefix of their CSS
+	// properties
+	taffySizes = map[string]string{"size": "", "minSize": "min-", "maxSize": "max-"}
+)
+
+// FromTaffyJSON converts a fixture of Taffy's test corpus, the JSON its
+// test generator writes for each HTML fixture, to a Fixture. Nodes are
+// laid out with max-content available space, as Taffy's generated tests
+// do.
+//
+// It returns the fixture along with an error describing the properties
+// it dropped.
+func FromTaffyJSON(data []byte) (*Fixture, error) {
+	var tn taffyNode
+	if err := json.Unmarshal(data, &tn); err != nil {
+		return nil, err
+	}
+	var errs []error
+	root := taffyToNode(&tn, "", &errs)
+	return &Fixture{
+		Root:        root,
+		Constraints: layout.Constraints{MaxWidth: layout.Unbounded, MaxHeight: layout.Unbounded},
+	}, errors.Join(errs...)
+}
+
+func taffyToNode(tn *taffyNode, path string, errs *[]error) *layout.Node {
+	node := &layout.Node{}
+	decls := taffyDeclarations(tn.Style, rootPath(path), errs)
+	applyCSS(&node.Style, taffyDefaults, decls, rootPath(path), errs)
+	if l := tn.Layout; l != nil {
+		node.Rect = layout.Rect{X: l.X, Y: l.Y, Width: l.Width, Height: l.Height}
+	}
+	for i, child := range tn.Children {
+		if child != nil {
+			node.Children = append(node.Children, taffyToNode(child, childPath(path, i), errs))
+		}
+	}
+	return node
+}
+
+// taffyDeclarations converts a Taffy style to CSS declarations.
+func taffyDeclarations(style map[string]json.RawMessage, path string, errs *[]error) []declaration {
+	keys := make([]string, 0, len(style))
+	for key := range style {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var decls []declaration
+	for _, key := range keys {
+		raw := style[key]
+		if string(raw) == "null" {
+			continue
+		}
+		d, err := taffyDeclaration(key, raw)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %s: %w", path, key, err))
+		}
+		decls = append(decls, d...)
+	}
+	return decls
+}
+
+// taffyDeclaration converts a Taffy style property to CSS declarations.
+func taffyDeclaration(key string, raw json.RawMessage) ([]declaration, error) {
+	switch {
+	case taffyEnums[key], key == "overflowX", key == "overflowY":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		if key == "overflowX" || key == "overflowY" {
+			// The engine has a single overflow
+			key = "overflow"
+		}
+		return []declaration{{camelToKebab(key), v}}, nil
+
+	case taffyNumbers[key]:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return []declaration{{camelToKebab(key), formatNumber(v)}}, nil
+
+	case key == "flexBasis":
+		var d taffyDimension
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return nil, err
+		}
+		v, err := d.css()
+		return []declaration{{"flex-basis", v}}, err
+
+	case taffySizes[key] != "" || key == "size":
+		var dims map[string]*taffyDimension
+		if err := json.Unmarshal(raw, &dims); err != nil {
+			return nil, err
+		}
+		var decls []declaration
+		for _, axis := range []string{"width", "height"} {
+			if d := dims[axis]; d != nil {
+				v, err := d.css()
+				if err != nil {
+					return decls, err
+				}
+				decls = append(decls, declaration{taffySizes[key] + axis, v})
+			}
+		}
+		return decls, nil
+
+	case taffyEdges[key] != "":
+		var dims map[string]*taffyDimension
+		if err := json.Unmarshal(raw, &dims); err != nil {
+			return nil, err
+		}
+		// Older generators name the horizontal edges start and end
+		if dims["left"] == nil {
+			dims["left"] = dims["start"]
+		}
+		if dims["right"] == nil {
+			dims["right"] = dims["end"]
+		}
+		var decls []declaration
+		for _, side := range sides {
+			if d := dims[side]; d != nil {
+				v, err := d.css()
+				if err != nil {
+					return decls, err
+				}
+				decls = append(decls, declaration{fmt.Sprintf(taffyEdges[key], side), v})
+			}
+		}
+		return decls, nil
+
+	case key == "gap":
+		var gap struct{ Row, Column *taffyDimension }
+		if err := json.Unmarshal(raw, &gap); err != nil {
+			return nil, err
+		}
+		var decls []declaration
+		for _, g := range []struct {
+			property string
+			d        *taffyDimension
+		}{{"row-gap", gap.Row}, {"column-gap", gap.Column}} {
+			if g.d != nil {
+				v, err := g.d.css()
+				if err != nil {
+					return decls, err
+				}
+				decls = append(decls, declaration{g.property, v})
+			}
+		}
+		return decls, nil
+
+	case taffyTracks[key]:
+		var tracks []taffyTrack
+		if err := json.Unmarshal(raw, &tracks); err != nil {
+			return nil, err
+		}
+		if len(tracks) == 0 {
+			return nil, nil
+		}
+		parts := make([]string, len(tracks))
+		for i, t := range tracks {
+			v, err := t.css()
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = v
+		}
+		return []declaration{{camelToKebab(key), strings.Join(parts, " ")}}, nil
+
+	case key == "gridRow" || key == "gridColumn":
+		var p taffyPlacement
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, err
+		}
+		start, err := p.Start.css()
+		if err != nil {
+			return nil, err
+		}
+		end, err := p.End.css()
+		if err != nil {
+			return nil, err
+		}
+		return []declaration{{camelToKebab(key), start + " / " + end}}, nil
+	}
+	return nil, errors.New("unsupported property")
+}
+
+// css returns the dimension as CSS.
+func (d *taffyDimension) css() (string, error) {
+	switch d.Unit {
+	case "points", "length":
+		return formatNumber(d.Value) + "px", nil
+	case "percent":
+		return formatNumber(d.Value*100) + "%", nil
+	case "fraction":
+		return formatNumber(d.Value) + "fr", nil
+	case "auto", "min-content", "max-content", "fit-content":
+		return d.Unit, nil
+	}
+	return "", fmt.Errorf("unsupported unit %q", d.Unit)
+}
+
+// css returns the track as CSS.
+func (t *taffyTrack) css() (string, error) {
+	if t.Kind != "function" {
+		d := taffyDimension{Unit: t.Unit, Value: t.Value}
+		return d.css()
+	}
+	args := make([]string, 0, len(t.Arguments)+1)
+	switch t.Name {
+	case "minmax", "fit-content":
+	case "repeat":
+		if t.Repetitions == nil {
+			return "", errors.New("repeat() without repetitions")
+		}
+		switch t.Repetitions.Kind {
+		case "count":
+			args = append(args, strconv.Itoa(t.Repetitions.Value))
+		case "auto-fill", "auto-fit":
+			args = append(args, t.Repetitions.Kind)
+		default:
+			return "", fmt.Errorf("unsupported repetitions %q", t.Repetitions.Kind)
+		}
+	default:
+		return "", fmt.Errorf("unsupported track function %q", t.Name)
+	}
+	var tracks []string
+	for _, arg := range t.Arguments {
+		v, err := arg.css()
+		if err != nil {
+			return "", err
+		}
+		tracks = append(tracks, v)
+	}
+	if t.Name == "repeat" {
+		// repeat()'s tracks are a list, not arguments
+		args = append(args, strings.Join(tracks, " "))
+	} else {
+		args = append(args, tracks...)
+	}
+	return t.Name + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// css returns the line as CSS.
+func (l taffyLine) css() (string, error) {
+	switch l.Kind {
+	case "", "auto":
+		return "auto", nil
+	case "line":
+		return strconv.Itoa(l.Value), nil
+	case "span":
+		return "span " + strconv.Itoa(l.Value), nil
+	}
+	return "", fmt.Errorf("unsupported grid line %q", l.Kind)
+}
+
+// ToTaffyJSON converts a fixture to Taffy's fixture JSON, with each
+// node's Rect as its layout, so Taffy's tests can run it.
+//
+// It returns the JSON along with an error describing the properties
+// Taffy's format can't express, which are left out.
+func ToTaffyJSON(f *Fixture) ([]byte, error) {
+	var errs []error
+	tn := nodeToTaffy(f.Root, nil, "", &errs)
+	data, err := json.MarshalIndent(tn, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return data, errors.Join(errs...)
+}
+
+func nodeToTaffy(n, parent *layout.Node, path string, errs *[]error) *taffyNode {
+	decls := styleDeclarations(n, parent)
+	// Write what CSS leaves out but Taffy defaults differently
+	if decls["display"] == "" {
+		decls["display"] = "block"
+	}
+	if decls["box-sizing"] == "" {
+		decls["box-sizing"] = "content-box"
+	}
+	if n.Text != "" {
+		*errs = append(*errs, fmt.Errorf("%s: text isn't converted", rootPath(path)))
+	}
+
+	style := make(map[string]any)
+	properties := make([]string, 0, len(decls))
+	for p := range decls {
+		properties = append(properties, p)
+	}
+	sort.Strings(properties)
+	for _, p := range properties {
+		if err := taffyProperty(style, p, decls[p]); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %s: %w", rootPath(path), p, err))
+		}
+	}
+
+	tn := &taffyNode{
+		Style:  make(map[string]json.RawMessage, len(style)),
+		Layout: &taffyLayout{X: n.Rect.X, Y: n.Rect.Y, Width: n.Rect.Width, Height: n.Rect.Height},
+	}
+	for key, v := range style {
+		tn.Style[key], _ = json.Marshal(v)
+	}
+	for i, child := range n.Children {
+		tn.Children = append(tn.Children, nodeToTaffy(child, n, childPath(path, i), errs))
+	}
+	return tn
+}
+
+// taffyProperty sets the Taffy style property for the CSS declaration
+// property: value.
+func taffyProperty(style map[string]any, property, value string) error {
+	edge := func(key, side string) error {
+		d, err := cssToTaffyDimension(value)
+		if err != nil {
+			return err
+		}
+		edges, _ := style[key].(map[string]taffyDimension)
+		if edges == nil {
+			edges = make(map[string]taffyDimension)
+			style[key] = edges
+		}
+		edges[side] = d
+		return nil
+	}
+
+	key := kebabToCamel(property)
+	switch {
+	case taffyEnums[key]:
+		style[key] = value
+		return nil
+	case property == "overflow":
+		style["overflowX"] = value
+		style["overflowY"] = value
+		return nil
+	case taffyNumbers[key]:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		style[key] = v
+		return nil
+	case property == "flex-basis":
+		d, err := cssToTaffyDimension(value)
+		if err != nil {
+			return err
+		}
+		style[key] = d
+		return nil
+	case taffyTracks[key]:
+		var tracks []taffyTrack
+		for _, part := range splitTopLevel(value, ' ') {
+			t, err := cssToTaffyTrack(part)
+			if err != nil {
+				return err
+			}
+			tracks = append(tracks, t)
+		}
+		style[key] = tracks
+		return nil
+	case property == "grid-row" || property == "grid-column":
+		lines := strings.Split(value, "/")
+		var p taffyPlacement
+		for i, line := range []*taffyLine{&p.Start, &p.End} {
+			if i >= len(lines) {
+				break
+			}
+			l, err := cssToTaffyLine(strings.TrimSpace(lines[i]))
+			if err != nil {
+				return err
+			}
+			*line = l
+		}
+		style[key] = p
+		return nil
+	case property == "row-gap" || property == "column-gap":
+		return edge("gap", strings.TrimSuffix(key, "Gap"))
+	}
+
+	for _, axis := range []string{"width", "height"} {
+		for sizeKey, prefix := range taffySizes {
+			if property == prefix+axis {
+				return edge(sizeKey, axis)
+			}
+		}
+	}
+	for _, side := range sides {
+		for edgeKey, format := range taffyEdges {
+			if property == fmt.Sprintf(format, side) {
+				return edge(edgeKey, side)
+			}
+		}
+	}
+	return errors.New("unsupported property")
+}
+
+// cssToTaffyDimension converts a CSS length or keyword.
+func cssToTaffyDimension(s string) (taffyDimension, error) {
+	l, ok := parseCSSLength(s)
+	switch {
+	case !ok:
+	case l.unit == "px":
+		return taffyDimension{Unit: "points", Value: l.value}, nil
+	case l.unit == "%":
+		return taffyDimension{Unit: "percent", Value: l.value / 100}, nil
+	case l.unit == "fr":
+		return taffyDimension{Unit: "fraction", Value: l.value}, nil
+	case l.unit == "auto" || l.unit == "min-content" || l.unit == "max-content" || l.unit == "fit-content":
+		return taffyDimension{Unit: l.unit}, nil
+	}
+	return taffyDimension{}, fmt.Errorf("unsupported value %q", s)
+}
+
+// cssToTaffyTrack converts a CSS track sizing function.
+func cssToTaffyTrack(s string) (taffyTrack, error) {
+	if name, args, ok := cssFunction(s); ok {
+		if name != "minmax" && name != "fit-content" {
+			return taffyTrack{}, fmt.Errorf("unsupported track %q", s)
+		}
+		t := taffyTrack{Kind: "function", Name: name}
+		for _, arg := range args {
+			a, err := cssToTaffyTrack(arg)
+			if err != nil {
+				return taffyTrack{}, err
+			}
+			t.Arguments = append(t.Arguments, a)
+		}
+		return t, nil
+	}
+	d, err := cssToTaffyDimension(s)
+	if err != nil {
+		return taffyTrack{}, err
+	}
+	return taffyTrack{Kind: "scalar", Unit: d.Unit, Value: d.Value}, nil
+}
+
+// cssToTaffyLine converts a CSS grid line.
+func cssToTaffyLine(s string) (taffyLine, error) {
+	if s == "auto" {
+		return taffyLine{Kind: "auto"}, nil
+	}
+	kind := "line"
+	if n, ok := strings.CutPrefix(s, "span "); ok {
+		kind, s = "span", strings.TrimSpace(n)
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return taffyLine{}, fmt.Errorf("unsupported grid line %q", s)
+	}
+	return taffyLine{Kind: kind, Value: v}, nil
+}
@@ -0,0 +1,141 @@
+package interop
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// taffyFlexFixture is a flex row as Taffy's generator writes it: the
+// children shrink, as Taffy's defaults do, to fit the margin.
+const taffyFlexFixture = `{
+	"style": {"display": "flex", "size": {"width": {"unit": "points", "value": 100}, "height": {"unit": "points", "value": 100}}},
+	"layout": {"width": 100, "height": 100, "x": 0, "y": 0},
+	"children": [
+		{"style": {"flexGrow": 1}, "layout": {"width": 45, "height": 100, "x": 0, "y": 0}},
+		{"style": {"flexGrow": 1, "margin": {"left": {"unit": "points", "value": 10}}}, "layout": {"width": 45, "height": 100, "x": 55, "y": 0}}
+	]
+}`
+
+const taffyGridFixture = `{
+	"style": {
+		"display": "grid",
+		"gridTemplateColumns": [{"kind": "scalar", "unit": "points", "value": 40}, {"kind": "scalar", "unit": "fraction", "value": 1}],
+		"gridTemplateRows": [{"kind": "function", "name": "minmax", "arguments": [{"kind": "scalar", "unit": "points", "value": 20}, {"kind": "scalar", "unit": "auto"}]}],
+		"size": {"width": {"unit": "points", "value": 120}}
+	},
+	"layout": {"width": 120, "height": 20, "x": 0, "y": 0},
+	"children": [
+		{"style": {}, "layout": {"width": 40, "height": 20, "x": 0, "y": 0}},
+		{"style": {"gridColumn": {"start": {"kind": "line", "value": 2}, "end": {"kind": "span", "value": 1}}}, "layout": {"width": 80, "height": 20, "x": 40, "y": 0}}
+	]
+}`
+
+func TestFromTaffyJSONFlex(t *testing.T) {
+	f, err := FromTaffyJSON([]byte(taffyFlexFixture))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	child := f.Root.Children[1]
+	if child.Style.FlexShrink != 1 || child.Style.BoxSizing != layout.BoxSizingBorderBox {
+		t.Errorf("Expected Taffy's defaults, got flex-shrink %v, box-sizing %v", child.Style.FlexShrink, child.Style.BoxSizing)
+	}
+	if child.Style.Margin.Left != layout.Px(10) {
+		t.Errorf("Expected margin-left 10px, got %v", child.Style.Margin.Left)
+	}
+	if child.Rect != (layout.Rect{X: 55, Width: 45, Height: 100}) {
+		t.Errorf("Expected the layout as Rect, got %v", child.Rect)
+	}
+	if err := f.Verify(0.01); err != nil {
+		t.Errorf("Expected the layout to match, got %v", err)
+	}
+}
+
+func TestFromTaffyJSONGrid(t *testing.T) {
+	f, err := FromTaffyJSON([]byte(taffyGridFixture))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := len(f.Root.Style.GridTemplateColumns); got != 2 {
+		t.Errorf("Expected 2 columns, got %d", got)
+	}
+	if err := f.Verify(0.01); err != nil {
+		t.Errorf("Expected the layout to match, got %v", err)
+	}
+}
+
+func TestFromTaffyJSONDroppedProperties(t *testing.T) {
+	f, err := FromTaffyJSON([]byte(`{
+		"style": {"flexGrow": 1},
+		"children": [{"style": {"scrollbarWidth": 10, "size": {"width": {"unit": "calc", "value": 1}}}}]
+	}`))
+	if f == nil || f.Root.Style.FlexGrow != 1 {
+		t.Fatal("Expected the fixture despite the dropped properties")
+	}
+	if err == nil {
+		t.Fatal("Expected an error for the dropped properties")
+	}
+	for _, want := range []string{"/0: scrollbarWidth", "/0: size", `"calc"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFromTaffyJSONInvalid(t *testing.T) {
+	if _, err := FromTaffyJSON([]byte(`{"style": `)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestTaffyRoundTrip(t *testing.T) {
+	for _, fixture := range []string{taffyFlexFixture, taffyGridFixture} {
+		f, _ := FromTaffyJSON([]byte(fixture))
+		data, err := ToTaffyJSON(f)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		back, err := FromTaffyJSON(data)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := back.Verify(0.01); err != nil {
+			t.Errorf("Expected the exported layout to match, got %v\n%s", err, data)
+		}
+	}
+}
+
+func TestToTaffyJSONDefaults(t *testing.T) {
+	// The engine's and CSS's defaults aren't Taffy's
+	root := layout.HStack(layout.Fixed(20, 10))
+	layout.Layout(root, layout.Loose(100, 100), layout.NewLayoutContext(100, 100, 16))
+	data, err := ToTaffyJSON(&Fixture{Root: root, Constraints: layout.Loose(100, 100)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var tn taffyNode
+	if err := json.Unmarshal(data, &tn); err != nil {
+		t.Fatal(err)
+	}
+	child := tn.Children[0].Style
+	for key, want := range map[string]string{"display": `"block"`, "boxSizing": `"content-box"`, "flexShrink": "0"} {
+		if got := string(child[key]); got != want {
+			t.Errorf("Expected %s %s, got %s", key, want, got)
+		}
+	}
+	if got := string(tn.Style["display"]); got != `"flex"` {
+		t.Errorf("Expected display \"flex\", got %s", got)
+	}
+	if tn.Children[0].Layout.Width != 20 {
+		t.Errorf("Expected the Rect as layout, got %+v", tn.Children[0].Layout)
+	}
+}
+
+func TestToTaffyJSONText(t *testing.T) {
+	_, err := ToTaffyJSON(&Fixture{Root: &layout.Node{Children: []*layout.Node{layout.Text("hi")}}})
+	if err == nil || !strings.Contains(err.Error(), "/0: text") {
+		t.Errorf("Expected an error for the text node, got %v", err)
+	}
+}
@@ -0,0 +1,330 @@
+package interop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// yogaNode is a node of Yoga's JSON tree format, as in Yoga's tree
+// captures: the style properties that differ from Yoga's defaults, named
+// as in CSS, the layout, and, on the root, the available space.
+type yogaNode struct {
+	LayoutInputs *yogaLayoutInputs          `json:"layout-inputs,omitempty"`
+	Style        map[string]json.RawMessage `json:"style"`
+	Layout       *yogaLayout                `json:"layout,omitempty"`
+	Children     []*yogaNode                `json:"children,omitempty"`
+}
+
+// yogaLayoutInputs is the space available to the root; a missing size is
+// undefined.
+type yogaLayoutInputs struct {
+	AvailableWidth  *float64 `json:"available-width,omitempty"`
+	AvailableHeight *float64 `json:"available-height,omitempty"`
+}
+
+// yogaLayout is a node's border box, relative to its parent's.
+type yogaLayout struct {
+	Left   float64 `json:"left"`
+	Top    float64 `json:"top"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// yogaValue is a length: {"value": 10, "unit": "px"}, {"value": 50,
+// "unit": "pct"} or a keyword, {"unit": "auto"}.
+type yogaValue struct {
+	Value float64 `json:"value,omitempty"`
+	Unit  string  `json:"unit"`
+}
+
+// yogaDefaults are Yoga's defaults that differ from CSS's initial values
+// (or from the engine's), as declarations. Every Yoga node is a flex
+// container.
+var yogaDefaults = []declaration{
+	{"display", "flex"},
+	{"flex-direction", "column"},
+	{"flex-shrink", "0"},
+	{"align-content", "flex-start"},
+	{"box-sizing", "border-box"},
+	{"position", "relative"},
+}
+
+// Yoga style properties by kind, named as in CSS.
+var (
+	yogaEnums = map[string]bool{
+		"display": true, "position": true, "box-sizing": true, "direction": true, "overflow": true,
+		"flex-direction": true, "flex-wrap": true, "justify-content": true, "align-items": true,
+		"align-self": true, "align-content": true,
+	}
+	yogaNumbers = map[string]bool{"flex-grow": true, "flex-shrink": true, "aspect-ratio": true}
+)
+
+// FromYogaJSON converts a tree in Yoga's JSON format to a Fixture. A root
+// without a width or height is sized to the available space in that
+// axis, as Yoga does; a missing available size is unbounded.
+//
+// It returns the fixture along with an error describing the properties
+// it dropped.
+func FromYogaJSON(data []byte) (*Fixture, error) {
+	var yn yogaNode
+	if err := json.Unmarshal(data, &yn); err != nil {
+		return nil, err
+	}
+	var errs []error
+	f := &Fixture{
+		Root:        yogaToNode(&yn, "", &errs),
+		Constraints: layout.Constraints{MaxWidth: layout.Unbounded, MaxHeight: layout.Unbounded},
+	}
+	if in := yn.LayoutInputs; in != nil {
+		if in.AvailableWidth != nil {
+			f.Constraints.MaxWidth = *in.AvailableWidth
+			if yn.Style["width"] == nil {
+				f.Constraints.MinWidth = *in.AvailableWidth
+			}
+		}
+		if in.AvailableHeight != nil {
+			f.Constraints.MaxHeight = *in.AvailableHeight
+			if yn.Style["height"] == nil {
+				f.Constraints.MinHeight = *in.AvailableHeight
+			}
+		}
+	}
+	return f, errors.Join(errs...)
+}
+
+func yogaToNode(yn *yogaNode, path string, errs *[]error) *layout.Node {
+	node := &layout.Node{}
+	keys := make([]string, 0, len(yn.Style))
+	for key := range yn.Style {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var decls []declaration
+	for _, key := range keys {
+		if string(yn.Style[key]) == "null" {
+			continue
+		}
+		d, err := yogaDeclaration(key, yn.Style[key])
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %s: %w", rootPath(path), key, err))
+			continue
+		}
+		decls = append(decls, d...)
+	}
+	applyCSS(&node.Style, yogaDefaults, decls, rootPath(path), errs)
+
+	if l := yn.Layout; l != nil {
+		node.Rect = layout.Rect{X: l.Left, Y: l.Top, Width: l.Width, Height: l.Height}
+	}
+	for i, child := range yn.Children {
+		if child != nil {
+			node.Children = append(node.Children, yogaToNode(child, childPath(path, i), errs))
+		}
+	}
+	return node
+}
+
+// yogaDeclaration converts a Yoga style property to a CSS declaration.
+func yogaDeclaration(key string, raw json.RawMessage) ([]declaration, error) {
+	property := yogaToCSSProperty(key)
+	switch {
+	case yogaEnums[property]:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return []declaration{{property, v}}, nil
+	case yogaNumbers[property]:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return []declaration{{property, formatNumber(v)}}, nil
+	}
+
+	var v yogaValue
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	switch v.Unit {
+	case "undefined":
+		return nil, nil
+	case "px", "point":
+		return []declaration{{property, formatNumber(v.Value) + "px"}}, nil
+	case "pct", "percent":
+		return []declaration{{property, formatNumber(v.Value) + "%"}}, nil
+	case "auto", "max-content", "fit-content", "stretch":
+		return []declaration{{property, v.Unit}}, nil
+	}
+	return nil, fmt.Errorf("unsupported unit %q", v.Unit)
+}
+
+// yogaToCSSProperty returns the CSS property of a Yoga style property:
+// position-type is position, position-left is left, edges named start,
+// end, horizontal and vertical are logical, and borders are widths.
+func yogaToCSSProperty(key string) string {
+	if key == "position-type" {
+		return "position"
+	}
+	if side, ok := strings.CutPrefix(key, "position-"); ok {
+		return side
+	}
+	for _, box := range []string{"margin", "padding", "border"} {
+		edge, ok := strings.CutPrefix(key, box)
+		if !ok || edge != "" && edge[0] != '-' {
+			continue
+		}
+		switch edge {
+		case "-start", "-end":
+			edge = "-inline" + edge
+		case "-horizontal":
+			edge = "-inline"
+		case "-vertical":
+			edge = "-block"
+		case "-all":
+			edge = ""
+		}
+		if box == "border" {
+			return box + edge + "-width"
+		}
+		return box + edge
+	}
+	return key
+}
+
+// ToYogaJSON converts a fixture to Yoga's JSON format, with each node's
+// Rect as its layout and the fixture's maximum constraints as the
+// available space.
+//
+// It returns the JSON along with an error describing what Yoga can't
+// express, like grid and block containers, which is left out.
+func ToYogaJSON(f *Fixture) ([]byte, error) {
+	var errs []error
+	yn := nodeToYoga(f.Root, nil, "", &errs)
+	in := &yogaLayoutInputs{}
+	if w := f.Constraints.MaxWidth; w < layout.Unbounded {
+		in.AvailableWidth = &w
+	}
+	if h := f.Constraints.MaxHeight; h < layout.Unbounded {
+		in.AvailableHeight = &h
+	}
+	if in.AvailableWidth != nil || in.AvailableHeight != nil {
+		yn.LayoutInputs = in
+	}
+	data, err := json.MarshalIndent(yn, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return data, errors.Join(errs...)
+}
+
+func nodeToYoga(n, parent *layout.Node, path string, errs *[]error) *yogaNode {
+	decls := styleDeclarations(n, parent)
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fmt.Errorf("%s: "+format, append([]any{rootPath(path)}, args...)...))
+	}
+
+	// Write what CSS leaves out but Yoga defaults differently, and drop
+	// the display Yoga defaults to
+	switch decls["display"] {
+	case "":
+		if len(n.Children) > 0 {
+			fail("display: block has no Yoga equivalent")
+		}
+	case "flex":
+		delete(decls, "display")
+		if decls["flex-direction"] == "" {
+			decls["flex-direction"] = "row"
+		}
+		if decls["align-content"] == "" {
+			decls["align-content"] = "stretch"
+		}
+	}
+	if decls["box-sizing"] == "" {
+		decls["box-sizing"] = "content-box"
+	}
+	if decls["position"] == "" {
+		decls["position"] = "static"
+	}
+	if n.Text != "" {
+		fail("text isn't converted")
+	}
+
+	yn := &yogaNode{
+		Style:  make(map[string]json.RawMessage, len(decls)),
+		Layout: &yogaLayout{Left: n.Rect.X, Top: n.Rect.Y, Width: n.Rect.Width, Height: n.Rect.Height},
+	}
+	properties := make([]string, 0, len(decls))
+	for p := range decls {
+		properties = append(properties, p)
+	}
+	sort.Strings(properties)
+	for _, p := range properties {
+		key, v, err := yogaProperty(p, decls[p])
+		if err != nil {
+			fail("%s: %v", p, err)
+			continue
+		}
+		yn.Style[key], _ = json.Marshal(v)
+	}
+	for i, child := range n.Children {
+		yn.Children = append(yn.Children, nodeToYoga(child, n, childPath(path, i), errs))
+	}
+	return yn
+}
+
+// yogaProperty returns the Yoga style property and value for the CSS
+// declaration property: value.
+func yogaProperty(property, value string) (string, any, error) {
+	switch {
+	case property == "display" && value != "none":
+		return "", nil, fmt.Errorf("%s has no Yoga equivalent", value)
+	case property == "position":
+		return "position-type", value, nil
+	case yogaEnums[property]:
+		return property, value, nil
+	case yogaNumbers[property]:
+		v, err := strconv.ParseFloat(value, 64)
+		return property, v, err
+	}
+
+	if !yogaLengths[property] {
+		return "", nil, errors.New("unsupported property")
+	}
+	key := property
+	if box, ok := strings.CutSuffix(property, "-width"); ok && strings.HasPrefix(property, "border-") {
+		key = box
+	}
+	l, ok := parseCSSLength(value)
+	switch {
+	case !ok:
+	case l.unit == "px":
+		return key, yogaValue{Value: l.value, Unit: "px"}, nil
+	case l.unit == "%":
+		return key, yogaValue{Value: l.value, Unit: "pct"}, nil
+	case l.unit == "auto" || l.unit == "max-content" || l.unit == "fit-content":
+		return key, yogaValue{Unit: l.unit}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported value %q", value)
+}
+
+// yogaLengths are the CSS properties with Yoga length values.
+var yogaLengths = func() map[string]bool {
+	m := map[string]bool{
+		"flex-basis": true, "width": true, "height": true, "min-width": true, "min-height": true,
+		"max-width": true, "max-height": true, "gap": true, "row-gap": true, "column-gap": true,
+	}
+	for _, side := range sides {
+		m[side] = true
+		m["margin-"+side] = true
+		m["padding-"+side] = true
+		m["border-"+side+"-width"] = true
+	}
+	return m
+}()
@@ -0,0 +1,157 @@
+package interop
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+const yogaFixture = `{
+	"layout-inputs": {"available-width": 200, "available-height": 100},
+	"style": {"flex-direction": "row", "padding": {"value": 10, "unit": "px"}},
+	"layout": {"left": 0, "top": 0, "width": 200, "height": 100},
+	"children": [
+		{"style": {"width": {"value": 50, "unit": "pct"}}, "layout": {"left": 10, "top": 10, "width": 90, "height": 80}},
+		{"style": {"flex-grow": 1, "border-left": {"value": 5, "unit": "px"}}, "layout": {"left": 100, "top": 10, "width": 90, "height": 80}}
+	]
+}`
+
+func TestFromYogaJSON(t *testing.T) {
+	f, err := FromYogaJSON([]byte(yogaFixture))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The root has no size, so it fills the available space
+	if f.Constraints != layout.Tight(200, 100) {
+		t.Errorf("Expected tight constraints, got %+v", f.Constraints)
+	}
+	child := f.Root.Children[0]
+	if child.Style.Display != layout.DisplayFlex || child.Style.FlexDirection != layout.FlexDirectionColumn {
+		t.Errorf("Expected Yoga's default flex column, got display %v, direction %v", child.Style.Display, child.Style.FlexDirection)
+	}
+	if child.Style.Width != layout.Percent(50) {
+		t.Errorf("Expected width 50%%, got %v", child.Style.Width)
+	}
+	if got := f.Root.Children[1].Style.Border.Left; got != layout.Px(5) {
+		t.Errorf("Expected border-left 5px, got %v", got)
+	}
+	if err := f.Verify(0.01); err != nil {
+		t.Errorf("Expected the layout to match, got %v", err)
+	}
+}
+
+func TestFromYogaJSONSizedRoot(t *testing.T) {
+	f, err := FromYogaJSON([]byte(`{
+		"layout-inputs": {"available-width": 500},
+		"style": {"width": {"value": 100, "unit": "point"}, "height": {"value": 50, "unit": "point"}},
+		"layout": {"left": 0, "top": 0, "width": 100, "height": 50}
+	}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := layout.Constraints{MaxWidth: 500, MaxHeight: layout.Unbounded}
+	if f.Constraints != want {
+		t.Errorf("Expected %+v, got %+v", want, f.Constraints)
+	}
+	if err := f.Verify(0.01); err != nil {
+		t.Errorf("Expected the layout to match, got %v", err)
+	}
+}
+
+func TestYogaToCSSProperty(t *testing.T) {
+	tests := map[string]string{
+		"position-type":     "position",
+		"position-left":     "left",
+		"margin":            "margin",
+		"margin-top":        "margin-top",
+		"margin-start":      "margin-inline-start",
+		"padding-end":       "padding-inline-end",
+		"padding-vertical":  "padding-block",
+		"margin-horizontal": "margin-inline",
+		"border":            "border-width",
+		"border-all":        "border-width",
+		"border-right":      "border-right-width",
+		"flex-grow":         "flex-grow",
+		"width":             "width",
+	}
+	for key, want := range tests {
+		if got := yogaToCSSProperty(key); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, key, got)
+		}
+	}
+}
+
+func TestFromYogaJSONDroppedProperties(t *testing.T) {
+	f, err := FromYogaJSON([]byte(`{"style": {"flex-grow": 2, "width": {"value": 1, "unit": "vw"}, "flex": 1}}`))
+	if f == nil || f.Root.Style.FlexGrow != 2 {
+		t.Fatal("Expected the fixture despite the dropped properties")
+	}
+	if err == nil {
+		t.Fatal("Expected an error for the dropped properties")
+	}
+	for _, want := range []string{"/: width", `"vw"`, "flex"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestYogaRoundTrip(t *testing.T) {
+	f, _ := FromYogaJSON([]byte(yogaFixture))
+	data, err := ToYogaJSON(f)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	back, err := FromYogaJSON(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := back.Verify(0.01); err != nil {
+		t.Errorf("Expected the exported layout to match, got %v\n%s", err, data)
+	}
+}
+
+func TestToYogaJSONDefaults(t *testing.T) {
+	// The engine's and CSS's defaults aren't Yoga's
+	root := layout.HStack(layout.Fixed(20, 10))
+	layout.Layout(root, layout.Loose(100, 100), layout.NewLayoutContext(100, 100, 16))
+	data, err := ToYogaJSON(&Fixture{Root: root, Constraints: layout.Loose(100, layout.Unbounded)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var yn yogaNode
+	if err := json.Unmarshal(data, &yn); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"flex-direction": `"row"`, "align-content": `"stretch"`, "box-sizing": `"content-box"`, "position-type": `"static"`,
+	} {
+		if got := string(yn.Style[key]); got != want {
+			t.Errorf("Expected %s %s, got %s", key, want, got)
+		}
+	}
+	if _, ok := yn.Style["display"]; ok {
+		t.Error("Expected Yoga's default display to be left out")
+	}
+	if in := yn.LayoutInputs; in == nil || in.AvailableWidth == nil || *in.AvailableWidth != 100 || in.AvailableHeight != nil {
+		t.Errorf("Expected an available width of 100 only, got %+v", in)
+	}
+}
+
+func TestToYogaJSONUnsupported(t *testing.T) {
+	root := &layout.Node{
+		Style:    layout.Style{Display: layout.DisplayGrid},
+		Children: []*layout.Node{{Children: []*layout.Node{{}}}},
+	}
+	_, err := ToYogaJSON(&Fixture{Root: root})
+	if err == nil {
+		t.Fatal("Expected an error for grid and block containers")
+	}
+	for _, want := range []string{"/: display: grid has no Yoga equivalent", "/0: display: block"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
@@ -74,6 +74,8 @@ func calculateMinContentWidth(node *Node, constraints Constraints, ctx *LayoutCo
 		return calculateGridMinContentWidth(node, constraints, ctx)
 	case DisplayBlock:
 		return calculateBlockMinContentWidth(node, constraints, ctx)
+	case DisplayInlineText:
+		return calculateTextIntrinsicWidth(node, true, ctx)
 	default:
 		return 0
 	}
@@ -89,11 +91,45 @@ func calculateMaxContentWidth(node *Node, constraints Constraints, ctx *LayoutCo
 		return calculateGridMaxContentWidth(node, constraints, ctx)
 	case DisplayBlock:
 		return calculateBlockMaxContentWidth(node, constraints, ctx)
+	case DisplayInlineText:
+		return calculateTextIntrinsicWidth(node, false, ctx)
 	default:
 		return 0
 	}
 }
 
+// calculateTextIntrinsicWidth measures a text node's border-box width.
+// With minContent set, the text breaks at every soft wrap opportunity so the
+// result is the widest unbreakable run; otherwise the text never wraps
+// (max-content).
+//
+// The node itself is not modified; layout runs on a shallow copy.
+//
+// See: https://www.w3.org/TR/css-sizing-3/#min-content
+// See: https://www.w3.org/TR/css-sizing-3/#max-content
+func calculateTextIntrinsicWidth(node *Node, minContent bool, ctx *LayoutContext) float64 {
+	probe := *node
+	probe.Style.Width = Px(0)
+	fontSize := getCurrentFontSize(&probe, ctx)
+	horizontalPaddingBorder := getHorizontalPaddingBorder(probe.Style.Padding, probe.Style.Border, ctx, fontSize)
+
+	// LayoutText treats a zero content width as unbounded, so min-content
+	// uses the smallest positive width instead.
+	availableWidth := Unbounded
+	if minContent {
+		availableWidth = horizontalPaddingBorder + 1e-6
+	}
+	LayoutText(&probe, Constraints{MaxWidth: availableWidth, MaxHeight: Unbounded}, ctx)
+
+	maxLine := 0.0
+	if probe.TextLayout != nil {
+		for _, line := range probe.TextLayout.Lines {
+			maxLine = math.Max(maxLine, line.Width)
+		}
+	}
+	return maxLine + horizontalPaddingBorder
+}
+
 // calculateMinContentHeight calculates the min-content height.
 func calculateMinContentHeight(node *Node, constraints Constraints, ctx *LayoutContext) float64 {
 	// For most layouts, min-content height is the same as auto height
@@ -120,7 +156,7 @@ func calculateBlockMinContentWidth(node *Node, constraints Constraints, ctx *Lay
 
 		// Calculate child's min-content width recursively
 		childWidth := 0.0
-		if child.Style.Width.Value > 0 {
+		if child.Style.Width.Value > 0 && !lengthHasPercent(child.Style.Width) {
 			// Explicit width (assuming pixels, should use ResolveLength with ctx)
 			childWidth = child.Style.Width.Value
 		} else if child.Style.Width.Value == SizeMinContent || child.Style.WidthSizing == IntrinsicSizeMinContent {
@@ -160,7 +196,7 @@ func calculateBlockMaxContentWidth(node *Node, constraints Constraints, ctx *Lay
 
 		// Calculate child's max-content width recursively
 		childWidth := 0.0
-		if child.Style.Width.Value > 0 {
+		if child.Style.Width.Value > 0 && !lengthHasPercent(child.Style.Width) {
 			// Explicit width (assuming pixels, should use ResolveLength with ctx)
 			childWidth = child.Style.Width.Value
 		} else {
@@ -184,122 +220,80 @@ func calculateBlockMaxContentWidth(node *Node, constraints Constraints, ctx *Lay
 
 // calculateFlexMinContentWidth calculates min-content width for flex layout.
 func calculateFlexMinContentWidth(node *Node, constraints Constraints, ctx *LayoutContext) float64 {
-	isRow := node.Style.FlexDirection == FlexDirectionRow || node.Style.FlexDirection == FlexDirectionRowReverse
-
-	if isRow {
-		// Flex row: sum of children's min-content widths
-		totalWidth := 0.0
-		for _, child := range node.Children {
-			if child.Style.Display == DisplayNone {
-				continue
-			}
-			// If child has explicit width, use it; otherwise calculate intrinsically
-			childWidth := 0.0
-			if child.Style.Width.Value > 0 {
-				childWidth = child.Style.Width.Value
-			} else {
-				childWidth = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMinContent, ctx)
-			}
-			childWidth += child.Style.Margin.Left.Value + child.Style.Margin.Right.Value
-			totalWidth += childWidth
-		}
-
-		// Add gaps
-		gap := node.Style.FlexGap
-		if node.Style.FlexColumnGap.Value > 0 {
-			gap = node.Style.FlexColumnGap
-		}
-		if len(node.Children) > 1 {
-			totalWidth += gap.Value * float64(len(node.Children)-1)
-		}
-
-		currentFontSize := getCurrentFontSize(node, ctx)
-		horizontalPaddingBorder := getHorizontalPaddingBorder(node.Style.Padding, node.Style.Border, ctx, currentFontSize)
-		return totalWidth + horizontalPaddingBorder
-	} else {
-		// Flex column: max of children's min-content widths
-		maxWidth := 0.0
-		for _, child := range node.Children {
-			if child.Style.Display == DisplayNone {
-				continue
-			}
-			// If child has explicit width, use it; otherwise calculate intrinsically
-			childWidth := 0.0
-			if child.Style.Width.Value > 0 {
-				childWidth = child.Style.Width.Value
-			} else {
-				childWidth = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMinContent, ctx)
-			}
-			childWidth += child.Style.Margin.Left.Value + child.Style.Margin.Right.Value
-			if childWidth > maxWidth {
-				maxWidth = childWidth
-			}
-		}
-
-		currentFontSize := getCurrentFontSize(node, ctx)
-		horizontalPaddingBorder := getHorizontalPaddingBorder(node.Style.Padding, node.Style.Border, ctx, currentFontSize)
-		return maxWidth + horizontalPaddingBorder
-	}
+	return calculateFlexIntrinsicWidth(node, IntrinsicSizeMinContent, ctx)
 }
 
 // calculateFlexMaxContentWidth calculates max-content width for flex layout.
 func calculateFlexMaxContentWidth(node *Node, constraints Constraints, ctx *LayoutContext) float64 {
+	return calculateFlexIntrinsicWidth(node, IntrinsicSizeMaxContent, ctx)
+}
+
+// calculateFlexIntrinsicWidth calculates the min-content or max-content width
+// of a flex container from its items' outer intrinsic contributions.
+//
+// A single-line row lays every item out side by side, so its intrinsic width
+// is the sum of the contributions plus gaps. A multi-line row can put each
+// item on its own line, so its min-content width is the largest contribution.
+// A column stacks items, so its intrinsic width is the largest contribution.
+//
+// Algorithm based on CSS Flexible Box Layout Module Level 1:
+// - §9.9.1: Flex Container Intrinsic Main Sizes
+// - §9.9.2: Flex Container Intrinsic Cross Sizes
+//
+// See: https://www.w3.org/TR/css-flexbox-1/#intrinsic-sizes
+func calculateFlexIntrinsicWidth(node *Node, sizingType IntrinsicSize, ctx *LayoutContext) float64 {
 	isRow := node.Style.FlexDirection == FlexDirectionRow || node.Style.FlexDirection == FlexDirectionRowReverse
+	isMultiLine := node.Style.FlexWrap == FlexWrapWrap || node.Style.FlexWrap == FlexWrapWrapReverse
+	sumItems := isRow && !(isMultiLine && sizingType == IntrinsicSizeMinContent)
 
-	if isRow {
-		// Flex row: sum of children's max-content widths
-		totalWidth := 0.0
-		for _, child := range node.Children {
-			if child.Style.Display == DisplayNone {
-				continue
-			}
-			// If child has explicit width, use it; otherwise calculate intrinsically
-			childWidth := 0.0
-			if child.Style.Width.Value > 0 {
-				childWidth = child.Style.Width.Value
-			} else {
-				childWidth = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMaxContent, ctx)
-			}
-			childWidth += child.Style.Margin.Left.Value + child.Style.Margin.Right.Value
-			totalWidth += childWidth
+	total, largest := 0.0, 0.0
+	visible := 0
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone {
+			continue
 		}
+		contribution := flexItemIntrinsicWidth(child, sizingType, ctx)
+		total += contribution
+		largest = math.Max(largest, contribution)
+		visible++
+	}
 
-		// Add gaps
-		gap := node.Style.FlexGap
-		if node.Style.FlexColumnGap.Value > 0 {
-			gap = node.Style.FlexColumnGap
+	currentFontSize := getCurrentFontSize(node, ctx)
+	width := largest
+	if sumItems {
+		width = total
+		gap := ResolveLength(node.Style.FlexColumnGap, ctx, currentFontSize)
+		if gap == 0 {
+			gap = ResolveLength(node.Style.FlexGap, ctx, currentFontSize)
 		}
-		if len(node.Children) > 1 {
-			totalWidth += gap.Value * float64(len(node.Children)-1)
+		if visible > 1 {
+			width += gap * float64(visible-1)
 		}
+	}
 
-		currentFontSize := getCurrentFontSize(node, ctx)
-		horizontalPaddingBorder := getHorizontalPaddingBorder(node.Style.Padding, node.Style.Border, ctx, currentFontSize)
-		return totalWidth + horizontalPaddingBorder
-	} else {
-		// Flex column: max of children's max-content widths
-		maxWidth := 0.0
-		for _, child := range node.Children {
-			if child.Style.Display == DisplayNone {
-				continue
-			}
-			// If child has explicit width, use it; otherwise calculate intrinsically
-			childWidth := 0.0
-			if child.Style.Width.Value > 0 {
-				childWidth = child.Style.Width.Value
-			} else {
-				childWidth = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMaxContent, ctx)
-			}
-			childWidth += child.Style.Margin.Left.Value + child.Style.Margin.Right.Value
-			if childWidth > maxWidth {
-				maxWidth = childWidth
-			}
-		}
+	horizontalPaddingBorder := getHorizontalPaddingBorder(node.Style.Padding, node.Style.Border, ctx, currentFontSize)
+	return width + horizontalPaddingBorder
+}
 
-		currentFontSize := getCurrentFontSize(node, ctx)
-		horizontalPaddingBorder := getHorizontalPaddingBorder(node.Style.Padding, node.Style.Border, ctx, currentFontSize)
-		return maxWidth + horizontalPaddingBorder
+// flexItemIntrinsicWidth returns a flex item's outer min-content or
+// max-content width contribution: its explicit border-box width if it has
+// one, otherwise its intrinsic width (text included), clamped by
+// min-width/max-width, plus horizontal margins.
+//
+// See: https://www.w3.org/TR/css-sizing-3/#contributions
+func flexItemIntrinsicWidth(child *Node, sizingType IntrinsicSize, ctx *LayoutContext) float64 {
+	fontSize := getCurrentFontSize(child, ctx)
+	var width float64
+	if flexItemHasDefiniteSize(child, true) {
+		width = ResolveLength(child.Style.Width, ctx, fontSize)
+		if child.Style.BoxSizing != BoxSizingBorderBox {
+			width += getHorizontalPaddingBorder(child.Style.Padding, child.Style.Border, ctx, fontSize)
+		}
+	} else {
+		width = CalculateIntrinsicWidth(child, Unconstrained(), sizingType, ctx)
 	}
+	width = flexItemClampSize(child, true, width, ctx)
+	return width + ResolveLength(child.Style.Margin.Left, ctx, fontSize) + ResolveLength(child.Style.Margin.Right, ctx, fontSize)
 }
 
 // calculateGridMinContentWidth calculates min-content width for grid layout.
@@ -359,6 +353,11 @@ func calculateGridMaxContentWidth(node *Node, constraints Constraints, ctx *Layo
 // resolveIntrinsicTrackSize resolves a grid track's size for intrinsic sizing.
 // This handles min-content, max-content, and fit-content tracks.
 func resolveIntrinsicTrackSize(track GridTrack, container *Node, trackIndex int, isColumn bool, sizingType IntrinsicSize, ctx *LayoutContext, currentFontSize float64) float64 {
+	// fit-content(limit) tracks hug their content up to the limit
+	if track.Fraction == -1 {
+		return resolveFitContentTrackSize(track, container, trackIndex, isColumn, ctx, currentFontSize)
+	}
+
 	// Resolve track sizes
 	minSize := ResolveLength(track.MinSize, ctx, currentFontSize)
 	maxSize := ResolveLength(track.MaxSize, ctx, currentFontSize)
@@ -392,6 +391,43 @@ func resolveIntrinsicTrackSize(track GridTrack, container *Node, trackIndex int,
 	}
 }
 
+// resolveFitContentTrackSize sizes a fit-content(limit) track.
+//
+// CSS Grid Layout §7.2.4: fit-content(limit) behaves like
+// minmax(auto, max-content) with the maximum clamped to the limit, i.e.
+// max(min-content, min(max-content, limit)). A track with no finite limit
+// is simply max-content.
+//
+// See: https://www.w3.org/TR/css-grid-1/#valdef-grid-template-columns-fit-content
+func resolveFitContentTrackSize(track GridTrack, container *Node, trackIndex int, isColumn bool, ctx *LayoutContext, currentFontSize float64) float64 {
+	limit := ResolveLength(track.MaxSize, ctx, currentFontSize)
+	minContent := calculateTrackMinContent(container, trackIndex, isColumn, ctx)
+	maxContent := calculateTrackMaxContent(container, trackIndex, isColumn, ctx)
+
+	size := maxContent
+	if limit < Unbounded {
+		size = math.Min(maxContent, limit)
+	}
+	return math.Max(size, minContent)
+}
+
+// gridItemTrackStart returns the track an item starts in on one axis,
+// resolving negative lines and spans against the container's template.
+// Auto-placed items report track 0.
+func gridItemTrackStart(container, child *Node, isColumn bool) int {
+	start, end := child.Style.GridRowStart, child.Style.GridRowEnd
+	explicitCount := len(container.Style.GridTemplateRows)
+	if isColumn {
+		start, end = child.Style.GridColumnStart, child.Style.GridColumnEnd
+		explicitCount = len(container.Style.GridTemplateColumns)
+	}
+	p := gridResolveAxisPlacement(start, end, explicitCount)
+	if p.auto {
+		return 0
+	}
+	return p.start
+}
+
 // calculateTrackMinContent calculates the min-content size for a grid track.
 func calculateTrackMinContent(container *Node, trackIndex int, isColumn bool, ctx *LayoutContext) float64 {
 	maxSize := 0.0
@@ -403,20 +439,7 @@ func calculateTrackMinContent(container *Node, trackIndex int, isColumn bool, ct
 		}
 
 		// Check if this child is in this track
-		var inTrack bool
-		if isColumn {
-			colStart := child.Style.GridColumnStart
-			if colStart < 0 {
-				colStart = 0
-			}
-			inTrack = colStart == trackIndex
-		} else {
-			rowStart := child.Style.GridRowStart
-			if rowStart < 0 {
-				rowStart = 0
-			}
-			inTrack = rowStart == trackIndex
-		}
+		inTrack := gridItemTrackStart(container, child, isColumn) == trackIndex
 
 		if !inTrack {
 			continue
@@ -425,7 +448,7 @@ func calculateTrackMinContent(container *Node, trackIndex int, isColumn bool, ct
 		// Calculate child's min-content size
 		var childSize float64
 		if isColumn {
-			childSize = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMinContent, ctx)
+			childSize = gridItemIntrinsicWidth(child, IntrinsicSizeMinContent, ctx)
 		} else {
 			childSize = CalculateIntrinsicHeight(child, Unconstrained(), IntrinsicSizeMinContent, ctx)
 		}
@@ -449,20 +472,7 @@ func calculateTrackMaxContent(container *Node, trackIndex int, isColumn bool, ct
 		}
 
 		// Check if this child is in this track
-		var inTrack bool
-		if isColumn {
-			colStart := child.Style.GridColumnStart
-			if colStart < 0 {
-				colStart = 0
-			}
-			inTrack = colStart == trackIndex
-		} else {
-			rowStart := child.Style.GridRowStart
-			if rowStart < 0 {
-				rowStart = 0
-			}
-			inTrack = rowStart == trackIndex
-		}
+		inTrack := gridItemTrackStart(container, child, isColumn) == trackIndex
 
 		if !inTrack {
 			continue
@@ -471,7 +481,7 @@ func calculateTrackMaxContent(container *Node, trackIndex int, isColumn bool, ct
 		// Calculate child's max-content size
 		var childSize float64
 		if isColumn {
-			childSize = CalculateIntrinsicWidth(child, Unconstrained(), IntrinsicSizeMaxContent, ctx)
+			childSize = gridItemIntrinsicWidth(child, IntrinsicSizeMaxContent, ctx)
 		} else {
 			childSize = CalculateIntrinsicHeight(child, Unconstrained(), IntrinsicSizeMaxContent, ctx)
 		}
@@ -483,3 +493,16 @@ func calculateTrackMaxContent(container *Node, trackIndex int, isColumn bool, ct
 
 	return maxSize
 }
+
+// gridItemIntrinsicWidth returns a grid item's width contribution to an
+// intrinsically sized column: its explicit width if it has one, otherwise its
+// min-content or max-content width.
+//
+// See: https://www.w3.org/TR/css-grid-1/#algo-content
+func gridItemIntrinsicWidth(child *Node, sizingType IntrinsicSize, ctx *LayoutContext) float64 {
+	if child.Style.Width.Value > 0 && !lengthHasPercent(child.Style.Width) {
+		fontSize := getCurrentFontSize(child, ctx)
+		return ResolveLength(child.Style.Width, ctx, fontSize)
+	}
+	return CalculateIntrinsicWidth(child, Unconstrained(), sizingType, ctx)
+}
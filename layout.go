@@ -12,7 +12,18 @@ package layout
 // (absolute, relative, fixed, sticky), use LayoutWithPositioning instead.
 //
 // After calling Layout, each node's Rect field will contain the computed
-// position and size.
+// position and size. Cross-node constraints (MatchWidthOf,
+// AlignBaselineWith) are applied last. Logical margins, paddings and
+// insets are mapped onto physical sides first (see ResolveLogicalSides).
+//
+// Nodes nested deeper than ctx.MaxDepth aren't laid out, so generated
+// trees of pathological depth can't overflow the stack: the nodes at the
+// maximum depth are laid out as if they had no children, and their
+// descendants keep their previous Rects. Layout doesn't report this.
+// LayoutWithContext returns a *DepthError for it, LayoutChecked refuses
+// such trees, LayoutResilient replaces the cut subtrees with placeholders,
+// and CheckDepth finds them beforehand.
+// Callbacks added with OnLayout run once layout is done.
 //
 // Based on CSS specifications:
 // - CSS Display Module Level 3: Display types and layout modes
@@ -30,17 +41,95 @@ package layout
 // - https://www.w3.org/TR/css-text-3/
 // - https://www.w3.org/TR/css-values-4/
 func Layout(root *Node, constraints Constraints, ctx *LayoutContext) Size {
-	switch root.Style.Display {
+	size, _ := layoutRoot(root, constraints, ctx)
+	runLayoutCallbacks(root, ctx)
+	return size
+}
+
+// layoutRoot lays out root's tree like Layout, without running OnLayout
+// callbacks. It returns a *DepthError if the tree was cut at ctx's
+// MaxDepth.
+func layoutRoot(root *Node, constraints Constraints, ctx *LayoutContext) (Size, error) {
+	restoreTree, depthErr := prepareTree(root, ctx.maxDepth())
+	defer restoreTree()
+	size := layoutPrepared(root, constraints, ctx)
+	if depthErr != nil {
+		return size, depthErr
+	}
+	return size, nil
+}
+
+// layoutPrepared lays out root's tree, already readied by prepareTree.
+func layoutPrepared(root *Node, constraints Constraints, ctx *LayoutContext) Size {
+	if root.Style.Display == DisplayNone {
+		return Size{Width: 0, Height: 0}
+	}
+	// Percentages on the root are taken of the constraints, or of the
+	// viewport (the initial containing block) when unbounded
+	percentWidth, percentHeight := constraints.MaxWidth, constraints.MaxHeight
+	if percentWidth >= Unbounded && ctx != nil && ctx.ViewportWidth > 0 {
+		percentWidth = ctx.ViewportWidth
+	}
+	if percentHeight >= Unbounded && ctx != nil && ctx.ViewportHeight > 0 {
+		percentHeight = ctx.ViewportHeight
+	}
+	ctx = ctx.withGuardRoot(root)
+	ctx = ctx.withRootWritingMode(root.Style.WritingMode)
+	ctx, releaseScratch := ctx.withFlexScratch()
+	defer releaseScratch()
+	ctx = ctx.beginMeasurePass()
+	restore := resolvePercentSizes([]*Node{root}, percentWidth, percentHeight)
+	defer restore()
+
+	size := cachedLayout(root, constraints, ctx, func() Size {
+		return layoutByDisplay(root, constraints, ctx)
+	})
+	if hasNodeConstraints(root) {
+		size = resolveNodeConstraints(root, constraints, ctx, size)
+	}
+	return size
+}
+
+// layoutByDisplay routes node to the layout algorithm for its display type,
+// setting aside space for its scrollbars if it's a scroll container.
+func layoutByDisplay(node *Node, constraints Constraints, ctx *LayoutContext) Size {
+	node.tracks = nil
+	if ctx != nil && ctx.ScrollbarWidth > 0 && node.IsScrollContainer() {
+		return layoutWithScrollbars(node, constraints, ctx)
+	}
+	node.gutters = scrollbarGutters{}
+	return layoutAlgorithm(node, constraints, ctx)
+}
+
+// layoutAlgorithm runs the layout algorithm for node's display type.
+func layoutAlgorithm(node *Node, constraints Constraints, ctx *LayoutContext) Size {
+	if ctx.canceled() {
+		return Size{}
+	}
+	var size Size
+	if ctx != nil && (ctx.OnNodeStart != nil || ctx.OnNodeEnd != nil) {
+		size = tracedLayout(node, constraints, ctx)
+	} else {
+		size = runLayoutAlgorithm(node, constraints, ctx)
+	}
+	if ctx != nil && ctx.NaNGuard != nil {
+		ctx.NaNGuard.check(node, size, ctx.guardRoot)
+	}
+	return size
+}
+
+// runLayoutAlgorithm runs the layout algorithm for node's display type,
+// untraced.
+func runLayoutAlgorithm(node *Node, constraints Constraints, ctx *LayoutContext) Size {
+	switch node.Style.Display {
 	case DisplayFlex:
-		return LayoutFlexbox(root, constraints, ctx)
+		return LayoutFlexbox(node, constraints, ctx)
 	case DisplayGrid:
-		return LayoutGrid(root, constraints, ctx)
+		return LayoutGrid(node, constraints, ctx)
 	case DisplayInlineText:
-		return LayoutText(root, constraints, ctx)
-	case DisplayNone:
-		return Size{Width: 0, Height: 0}
+		return LayoutText(node, constraints, ctx)
 	default:
-		return LayoutBlock(root, constraints, ctx)
+		return LayoutBlock(node, constraints, ctx)
 	}
 }
 
@@ -0,0 +1,309 @@
+package layout
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
+	"sync"
+)
+
+// LayoutCache memoizes layout results for structurally identical subtrees.
+//
+// Trees instantiated from a template (list rows, table rows, cards) usually
+// contain many subtrees whose styles, text and child structure are identical.
+// Laid out under the same constraints, such subtrees always produce the same
+// sizes and the same child rects relative to the subtree root. The cache
+// hashes (subtree styles + text + constraints + unit context) and, on a hit,
+// copies the previously computed relative rects onto the new subtree instead
+// of running the layout algorithm again. The parent then positions the
+// subtree root as usual, so only the root's offset differs between rows.
+//
+// A cache is attached to a layout via LayoutContext.WithCache and may be
+// shared across layouts and goroutines:
+//
+//	cache := layout.NewLayoutCache()
+//	ctx := layout.NewLayoutContext(800, 600, 16).WithCache(cache)
+//	layout.Layout(list, layout.Loose(800, layout.Unbounded), ctx)
+//
+// Correctness guards:
+//   - The cache is flushed whenever the effective TextMetricsProvider
+//     changes (either ctx.TextMetrics or the package-level provider
+//     installed with SetTextMetricsProvider), since every text measurement
+//     depends on it.
+//   - Viewport size, root font size and the ch reference character are part
+//     of the key, so relative units never resolve against stale values.
+//   - Hits restore a deep copy of the cached TextLayout, so mutating one
+//     row's line boxes never affects another row.
+//
+// Keys are 64-bit FNV-1a hashes. Callers that mutate a node's Style after
+// layout simply get a different key on the next pass; no invalidation call
+// is needed.
+type LayoutCache struct {
+	mu       sync.Mutex
+	entries  map[uint64]*layoutCacheEntry
+	provider TextMetricsProvider
+	holder   *textMetricsHolder
+	hits     int
+	misses   int
+}
+
+// LayoutCacheStats reports cache effectiveness counters.
+type LayoutCacheStats struct {
+	Hits    int // Subtrees restored from the cache
+	Misses  int // Subtrees laid out and stored
+	Entries int // Distinct subtrees currently cached
+}
+
+// layoutCacheEntry holds a subtree's layout result in pre-order.
+type layoutCacheEntry struct {
+	size    Size
+	results []layoutCacheResult
+}
+
+// layoutCacheResult is the per-node output of a layout pass.
+type layoutCacheResult struct {
+	rect       Rect
+	baseline   float64
+	textLayout *TextLayout
+	gutters    scrollbarGutters
+	tracks     *layoutTracks
+}
+
+// NewLayoutCache creates an empty layout cache.
+func NewLayoutCache() *LayoutCache {
+	return &LayoutCache{
+		entries: make(map[uint64]*layoutCacheEntry),
+	}
+}
+
+// Stats returns the current hit/miss counters and entry count.
+func (c *LayoutCache) Stats() LayoutCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return LayoutCacheStats{
+		Hits:    c.hits,
+		Misses:  c.misses,
+		Entries: len(c.entries),
+	}
+}
+
+// Reset drops all cached entries and zeroes the counters.
+func (c *LayoutCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[uint64]*layoutCacheEntry)
+	c.provider = nil
+	c.holder = nil
+	c.hits = 0
+	c.misses = 0
+}
+
+// cachedLayout runs layoutFn for node unless ctx carries a MeasureCache
+// that holds node's result under identical constraints from earlier in
+// the same layout, or a LayoutCache that holds the result for an identical
+// subtree under identical constraints. Then the cached rects are restored
+// onto node and its descendants. Without a cache it simply calls layoutFn.
+func cachedLayout(node *Node, constraints Constraints, ctx *LayoutContext, layoutFn func() Size) Size {
+	if ctx != nil && ctx.MeasureCache != nil && ctx.measurePass != 0 {
+		return ctx.MeasureCache.layout(node, constraints, ctx, func() Size {
+			return sharedLayout(node, constraints, ctx, layoutFn)
+		})
+	}
+	return sharedLayout(node, constraints, ctx, layoutFn)
+}
+
+// sharedLayout runs layoutFn for node, or restores the result of an
+// identical subtree from ctx's LayoutCache.
+func sharedLayout(node *Node, constraints Constraints, ctx *LayoutContext, layoutFn func() Size) Size {
+	if ctx == nil || ctx.Cache == nil {
+		return layoutFn()
+	}
+	c := ctx.Cache
+
+	key := layoutCacheKey(node, constraints, ctx)
+
+	c.mu.Lock()
+	c.checkProvider(ctx)
+	entry, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.mu.Unlock()
+
+	if ok && entry.restore(node) {
+		return entry.size
+	}
+
+	size := layoutFn()
+	if ctx.canceled() {
+		// The result may be missing parts of the subtree
+		return size
+	}
+
+	entry = &layoutCacheEntry{size: size}
+	entry.capture(node)
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	return size
+}
+
+// checkProvider flushes the cache if the text metrics provider in effect has
+// changed since entries were stored. Must be called with c.mu held.
+func (c *LayoutCache) checkProvider(ctx *LayoutContext) {
+	holder := textMetrics.Load()
+	if c.holder == holder && sameProvider(c.provider, ctx.TextMetrics) {
+		return
+	}
+	if len(c.entries) > 0 {
+		c.entries = make(map[uint64]*layoutCacheEntry)
+	}
+	c.holder = holder
+	c.provider = ctx.TextMetrics
+}
+
+// sameProvider reports whether a and b are the same provider. Providers with
+// non-comparable dynamic types are never considered equal, which errs on the
+// side of flushing.
+func sameProvider(a, b TextMetricsProvider) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// capture records the layout output of node and its descendants in pre-order.
+func (e *layoutCacheEntry) capture(node *Node) {
+	e.results = append(e.results, layoutCacheResult{
+		rect:       node.Rect,
+		baseline:   node.Baseline,
+		textLayout: cloneTextLayout(node.TextLayout),
+		gutters:    node.gutters,
+		tracks:     node.tracks,
+	})
+	for i := range node.Children {
+		e.capture(node.Children[i])
+	}
+}
+
+// restore copies the cached output onto node and its descendants. It returns
+// false if the tree shape does not match the entry (a hash collision), in
+// which case the caller falls back to a real layout.
+func (e *layoutCacheEntry) restore(node *Node) bool {
+	if countNodes(node) != len(e.results) {
+		return false
+	}
+	idx := 0
+	var apply func(n *Node)
+	apply = func(n *Node) {
+		r := e.results[idx]
+		idx++
+		n.Rect = r.rect
+		n.Baseline = r.baseline
+		n.TextLayout = cloneTextLayout(r.textLayout)
+		n.gutters = r.gutters
+		n.tracks = r.tracks
+		for i := range n.Children {
+			apply(n.Children[i])
+		}
+	}
+	apply(node)
+	return true
+}
+
+// countNodes returns the number of nodes in the subtree rooted at node.
+func countNodes(node *Node) int {
+	n := 1
+	for i := range node.Children {
+		n += countNodes(node.Children[i])
+	}
+	return n
+}
+
+// cloneTextLayout deep-copies a TextLayout so cached line boxes are never
+// shared between nodes.
+func cloneTextLayout(tl *TextLayout) *TextLayout {
+	if tl == nil {
+		return nil
+	}
+	out := *tl
+	out.Lines = make([]TextLine, len(tl.Lines))
+	for i, line := range tl.Lines {
+		line.Boxes = append([]InlineBox(nil), line.Boxes...)
+		for j := range line.Boxes {
+			line.Boxes[j].Orientations = append([]bool(nil), line.Boxes[j].Orientations...)
+			line.Boxes[j].Runs = append([]FontRun(nil), line.Boxes[j].Runs...)
+		}
+		out.Lines[i] = line
+	}
+	if tl.FirstLineStyle != nil {
+		style := *tl.FirstLineStyle
+		out.FirstLineStyle = &style
+	}
+	if tl.DropCap != nil {
+		dc := *tl.DropCap
+		out.DropCap = &dc
+	}
+	return &out
+}
+
+// layoutCacheKey hashes everything that influences the layout of node's
+// subtree: constraints, the unit resolution context and, recursively, each
+// node's Style and Text.
+func layoutCacheKey(node *Node, constraints Constraints, ctx *LayoutContext) uint64 {
+	h := fnv.New64a()
+	hashConstraints(h, constraints, ctx)
+	hashSubtree(h, node)
+	return h.Sum64()
+}
+
+// hashConstraints writes the constraints and the parts of ctx that units
+// and inherited styles resolve against.
+func hashConstraints(h hash.Hash64, constraints Constraints, ctx *LayoutContext) {
+	fmt.Fprintf(h, "%v|%v|%v|%v|%v|%v|%d|%v|%v|%v|%v|", constraints, ctx.ViewportWidth, ctx.ViewportHeight, ctx.RootFontSize, ctx.inheritedFontSize, ctx.inheritedTextStyle, ctx.ChReferenceChar, ctx.inlineContainer, ctx.sizeContainer, ctx.Units, ctx.ScrollbarWidth)
+}
+
+// hashSubtree writes a structural description of node and its descendants.
+func hashSubtree(h hash.Hash64, node *Node) {
+	hashNode(h, node)
+	fmt.Fprintf(h, "%d[", len(node.Children))
+	for i := range node.Children {
+		hashSubtree(h, node.Children[i])
+	}
+	h.Write([]byte("]}"))
+}
+
+// hashNode writes node's Style and Text. Pointer fields are dereferenced
+// so that equal values in different allocations hash identically.
+func hashNode(h hash.Hash64, node *Node) {
+	style := node.Style
+	textStyle := style.TextStyle
+	areas := style.GridTemplateAreas
+	firstLine, firstLetter := style.FirstLine, style.FirstLetter
+	style.TextStyle = nil
+	style.GridTemplateAreas = nil
+	style.FirstLine, style.FirstLetter = nil, nil
+
+	fmt.Fprintf(h, "{%v|%q|", style, node.Text)
+	if textStyle != nil {
+		fmt.Fprintf(h, "ts%v|", *textStyle)
+	}
+	if areas != nil {
+		fmt.Fprintf(h, "ga%v|", *areas)
+	}
+	if firstLine != nil {
+		fmt.Fprintf(h, "fl%v|", *firstLine)
+	}
+	if firstLetter != nil {
+		fmt.Fprintf(h, "fc%v|", *firstLetter)
+	}
+}
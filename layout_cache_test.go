@@ -0,0 +1,144 @@
+package layout
+
+import (
+	"testing"
+)
+
+// makeCacheRow builds a template row: an icon above a label.
+func makeCacheRow(label string) *Node {
+	return &Node{
+		Style: Style{
+			Display: DisplayBlock,
+			Padding: Uniform(Px(4)),
+		},
+		Children: []*Node{
+			{Style: Style{Width: Px(20), Height: Px(20)}},
+			Text(label, Style{TextStyle: &TextStyle{FontSize: 12}}),
+		},
+	}
+}
+
+func makeCacheList(rows int) *Node {
+	list := &Node{Style: Style{Display: DisplayBlock, Width: Px(300)}}
+	for i := 0; i < rows; i++ {
+		list.Children = append(list.Children, makeCacheRow("Item"))
+	}
+	return list
+}
+
+func TestLayoutCacheReusesIdenticalRows(t *testing.T) {
+	cached := makeCacheList(5)
+	uncached := makeCacheList(5)
+
+	cache := NewLayoutCache()
+	ctx := NewLayoutContext(800, 600, 16).WithCache(cache)
+	Layout(cached, Loose(800, Unbounded), ctx)
+	Layout(uncached, Loose(800, Unbounded), NewLayoutContext(800, 600, 16))
+
+	stats := cache.Stats()
+	if stats.Hits != 4 {
+		t.Errorf("Expected 4 cache hits for 4 repeated rows, got %d", stats.Hits)
+	}
+
+	for i := range cached.Children {
+		got, want := cached.Children[i], uncached.Children[i]
+		if got.Rect != want.Rect {
+			t.Errorf("Row %d: expected rect %+v, got %+v", i, want.Rect, got.Rect)
+		}
+		for j := range got.Children {
+			if got.Children[j].Rect != want.Children[j].Rect {
+				t.Errorf("Row %d child %d: expected rect %+v, got %+v", i, j, want.Children[j].Rect, got.Children[j].Rect)
+			}
+		}
+	}
+
+	// Rows must be offset by position only.
+	if cached.Children[1].Rect.Y <= cached.Children[0].Rect.Y {
+		t.Errorf("Expected second row below first, got Y=%.2f and Y=%.2f", cached.Children[0].Rect.Y, cached.Children[1].Rect.Y)
+	}
+}
+
+func TestLayoutCacheDistinguishesContent(t *testing.T) {
+	list := &Node{Style: Style{Display: DisplayBlock, Width: Px(300)}}
+	list.Children = []*Node{makeCacheRow("Short"), makeCacheRow("A much longer label")}
+
+	cache := NewLayoutCache()
+	ctx := NewLayoutContext(800, 600, 16).WithCache(cache)
+	Layout(list, Loose(800, Unbounded), ctx)
+
+	// Only the identical icons may be shared; the rows themselves differ.
+	if cache.Stats().Hits != 1 {
+		t.Errorf("Expected only the icon to hit the cache, got %d hits", cache.Stats().Hits)
+	}
+	short := list.Children[0].Children[1].TextLayout.Lines[0].Width
+	long := list.Children[1].Children[1].TextLayout.Lines[0].Width
+	if long <= short {
+		t.Errorf("Expected longer label to be wider, got %.2f <= %.2f", long, short)
+	}
+}
+
+func TestLayoutCacheTextLayoutNotShared(t *testing.T) {
+	list := makeCacheList(2)
+	ctx := NewLayoutContext(800, 600, 16).WithCache(NewLayoutCache())
+	Layout(list, Loose(800, Unbounded), ctx)
+
+	first := list.Children[0].Children[1].TextLayout
+	second := list.Children[1].Children[1].TextLayout
+	if first == nil || second == nil {
+		t.Fatal("Expected text layouts on both rows")
+	}
+	if first == second {
+		t.Fatal("Cached rows must not share a TextLayout pointer")
+	}
+	first.Lines[0].Width = -1
+	if second.Lines[0].Width == -1 {
+		t.Error("Mutating one row's lines should not affect another row")
+	}
+}
+
+// wideMetrics measures every rune as 10px regardless of font size.
+type wideMetrics struct{}
+
+func (wideMetrics) Measure(text string, style TextStyle) (advance, ascent, descent float64) {
+	return float64(len([]rune(text))) * 10, style.FontSize * 0.8, style.FontSize * 0.2
+}
+
+func TestLayoutCacheFlushesOnProviderChange(t *testing.T) {
+	cache := NewLayoutCache()
+
+	base := NewLayoutContext(800, 600, 16).WithCache(cache)
+	Layout(makeCacheList(1), Loose(800, Unbounded), base)
+	if cache.Stats().Entries == 0 {
+		t.Fatal("Expected entries after first layout")
+	}
+
+	// Same cache, different provider on the context.
+	wide := base.WithTextMetrics(wideMetrics{})
+	Layout(makeCacheList(1), Loose(800, Unbounded), wide)
+	if hits := cache.Stats().Hits; hits != 0 {
+		t.Errorf("Expected no hits after provider change, got %d", hits)
+	}
+
+	// Same cache, package-level provider swapped.
+	previous := getTextMetrics()
+	defer SetTextMetricsProvider(previous)
+	SetTextMetricsProvider(wideMetrics{})
+
+	before := cache.Stats().Hits
+	Layout(makeCacheList(1), Loose(800, Unbounded), wide)
+	if hits := cache.Stats().Hits; hits != before {
+		t.Errorf("Expected cache flush after SetTextMetricsProvider, got %d new hits", hits-before)
+	}
+}
+
+func TestLayoutCacheReset(t *testing.T) {
+	cache := NewLayoutCache()
+	ctx := NewLayoutContext(800, 600, 16).WithCache(cache)
+	Layout(makeCacheList(3), Loose(800, Unbounded), ctx)
+
+	cache.Reset()
+	stats := cache.Stats()
+	if stats.Hits != 0 || stats.Misses != 0 || stats.Entries != 0 {
+		t.Errorf("Expected empty stats after Reset, got %+v", stats)
+	}
+}
@@ -24,14 +24,96 @@ type LayoutContext struct {
 	RootFontSize float64
 
 	// TextMetrics is the text measurement provider used to measure character widths.
-	// Used to resolve ch units by measuring the reference character.
-	// If nil, a monospace approximation is used (60% of font size).
+	// Used to resolve ch units by measuring the reference character, and ex
+	// units if it implements XHeightProvider.
+	// If nil, the package-level provider is used (see SetTextMetricsProvider).
 	TextMetrics TextMetricsProvider
 
 	// ChReferenceChar is the reference character for ch unit calculations.
 	// Per CSS spec, this is typically '0' (U+0030 DIGIT ZERO).
 	// Default: '0'
 	ChReferenceChar rune
+
+	// Units, if non-nil, resolves units that the engine doesn't know, or
+	// overrides the ones it does. See UnitResolver. Default: nil.
+	Units UnitResolver
+
+	// Cache, if non-nil, memoizes layout results for structurally identical
+	// subtrees. See LayoutCache. Default: nil (no caching).
+	Cache *LayoutCache
+
+	// MeasureCache, if non-nil, reuses a node's result when a layout lays
+	// it out again under the same constraints. See MeasureCache.
+	// Default: nil (no caching).
+	MeasureCache *MeasureCache
+
+	// TextCache, if non-nil, remembers text measurements across layouts.
+	// See TextMeasureCache. Default: nil (no caching).
+	TextCache *TextMeasureCache
+
+	// OnNodeStart and OnNodeEnd, if non-nil, are called before and after
+	// each run of a layout algorithm on a node, for profiling. See
+	// NodeTrace, and WithProfile for a built-in profiler. Default: nil.
+	OnNodeStart func(NodeTrace)
+	OnNodeEnd   func(NodeTrace)
+
+	// NaNGuard, if non-nil, catches the first NaN or infinity in a layout
+	// result. See NaNGuard. Default: nil.
+	NaNGuard *NaNGuard
+
+	// MaxDepth is the deepest nesting that Layout lays out. Children of
+	// nodes this deep are left as they are, and CheckDepth reports them.
+	// Default: 0, for DefaultMaxDepth; negative for no limit.
+	MaxDepth int
+
+	// ScrollbarWidth is the thickness of a scrollbar, in pixels. Scroll
+	// containers set aside this much space for their scrollbars, between
+	// their border and padding, and their content gets narrower or
+	// shorter to match, as in a browser. Default: 0, for overlay
+	// scrollbars that take no space (and terminals).
+	// See Style.Overflow and Style.ScrollbarGutter.
+	ScrollbarWidth float64
+
+	// PrefersDark reports that the user prefers a dark color scheme. It
+	// doesn't affect layout; it is matched by the prefers-color-scheme
+	// media feature (see MediaQuery). Default: false.
+	PrefersDark bool
+
+	// inheritedFontSize is the font size of the node whose children are
+	// being laid out, inherited by children without their own FontSize.
+	// Zero outside layout, where RootFontSize applies.
+	inheritedFontSize float64
+
+	// inheritedTextStyle is the computed text style of the node whose
+	// children are being laid out, or nil if it has none. See
+	// InheritTextStyle.
+	inheritedTextStyle *TextStyle
+
+	// inlineContainer and sizeContainer are the nearest query containers
+	// of the nodes being laid out, for cq* units. See enterQueryContainer.
+	inlineContainer *queryContainer
+	sizeContainer   *queryContainer
+
+	// measurePass numbers the layout in progress for MeasureCache. Zero
+	// outside layout.
+	measurePass uint64
+
+	// cancel, if non-nil, stops the layout in progress when it's done. See
+	// LayoutWithContext.
+	cancel *cancelState
+
+	// guardRoot is the root of the layout in progress, for NaNGuard's
+	// paths.
+	guardRoot *Node
+
+	// rootWritingMode is the writing mode of the root of the layout in
+	// progress, whose inline and block axes vi and vb follow. Horizontal
+	// outside layout.
+	rootWritingMode WritingMode
+
+	// flexScratch lends flex containers reusable buffers during a layout.
+	// Nil outside layout.
+	flexScratch *flexScratch
 }
 
 // NewLayoutContext creates a new LayoutContext with the specified parameters
@@ -94,3 +176,65 @@ func (ctx *LayoutContext) WithChReferenceChar(char rune) *LayoutContext {
 	copy.ChReferenceChar = char
 	return &copy
 }
+
+// WithUnitResolver returns a copy of the context that resolves lengths
+// with the given UnitResolver before the built-in units.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(80, 24, 1).WithUnitResolver(layout.UnitScales{"cell": 1})
+func (ctx *LayoutContext) WithUnitResolver(r UnitResolver) *LayoutContext {
+	copy := *ctx
+	copy.Units = r
+	return &copy
+}
+
+// WithCache returns a copy of the context that reuses layout results from
+// the given LayoutCache for structurally identical subtrees.
+//
+// Example:
+//
+//	cache := layout.NewLayoutCache()
+//	ctx := layout.NewLayoutContext(1920, 1080, 16).WithCache(cache)
+func (ctx *LayoutContext) WithCache(cache *LayoutCache) *LayoutContext {
+	copy := *ctx
+	copy.Cache = cache
+	return &copy
+}
+
+// WithMeasureCache returns a copy of the context that reuses a node's
+// layout result when it's laid out again under the same constraints
+// during a layout.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(1920, 1080, 16).WithMeasureCache(layout.NewMeasureCache())
+func (ctx *LayoutContext) WithMeasureCache(cache *MeasureCache) *LayoutContext {
+	copy := *ctx
+	copy.MeasureCache = cache
+	return &copy
+}
+
+// WithTextCache returns a copy of the context that reuses text
+// measurements from the given TextMeasureCache.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(1920, 1080, 16).WithTextCache(layout.NewTextMeasureCache(0))
+func (ctx *LayoutContext) WithTextCache(cache *TextMeasureCache) *LayoutContext {
+	copy := *ctx
+	copy.TextCache = cache
+	return &copy
+}
+
+// WithPrefersDark returns a copy of the context with PrefersDark set, for
+// matching (prefers-color-scheme: dark) media queries.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(1920, 1080, 16).WithPrefersDark(true)
+func (ctx *LayoutContext) WithPrefersDark(dark bool) *LayoutContext {
+	copy := *ctx
+	copy.PrefersDark = dark
+	return &copy
+}
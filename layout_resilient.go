@@ -0,0 +1,298 @@
+package layout
+
+import (
+	"fmt"
+	"math"
+)
+
+// Resilient layout
+//
+// Layout trusts its input: a NaN width or a grid item placed on line
+// 1,000,000 propagates through every sibling and ancestor size. That is fine
+// for trees built in code but not for layouts assembled from user-generated
+// content, where one bad node should not take the whole page with it.
+//
+// LayoutResilient validates every node before layout. A node that fails
+// validation is laid out as an empty placeholder box of a configurable size
+// in place of its whole subtree, the failure is recorded on the result, and
+// the rest of the tree lays out normally. Node styles and children are
+// restored afterwards; only Rect fields change.
+
+// gridMaxLine is the largest grid line number accepted by validation.
+// Browsers clamp line numbers to a similar range (10,000 in Blink and Gecko)
+// so a single item can't allocate an unbounded number of implicit tracks.
+const gridMaxLine = 10000
+
+// ResilientOptions configures LayoutResilient.
+type ResilientOptions struct {
+	// PlaceholderWidth and PlaceholderHeight are the border-box size of the
+	// box laid out in place of an invalid subtree. Zero collapses it.
+	PlaceholderWidth  float64
+	PlaceholderHeight float64
+}
+
+// LayoutError describes a node that was replaced by a placeholder.
+type LayoutError struct {
+	// Node is the invalid node. Its Rect holds the placeholder's position and
+	// size; the Rects of its descendants are zeroed.
+	Node *Node
+
+	// Path is the sequence of child indexes from the root to Node.
+	// The root's path is empty.
+	Path []int
+
+	// Err is the validation failure (or recovered panic).
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LayoutError) Error() string {
+	return fmt.Sprintf("layout: node %v: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying validation error.
+func (e *LayoutError) Unwrap() error {
+	return e.Err
+}
+
+// LayoutResult is the outcome of LayoutResilient.
+type LayoutResult struct {
+	// Size is the root's laid-out size, as returned by Layout.
+	Size Size
+
+	// Errors lists the nodes replaced by placeholders, in tree order.
+	// Empty when the whole tree was valid.
+	Errors []*LayoutError
+}
+
+// LayoutResilient lays out root like Layout, but replaces every subtree whose
+// root fails validation with a placeholder box instead of letting it corrupt
+// the rest of the layout. Invalid values include non-finite lengths, negative
+// flex factors or aspect ratios, and grid lines or spans outside ±10000. A
+// node at ctx.MaxDepth with children is replaced too, with a DepthError.
+//
+// If layout still panics, the panic is recovered, the whole tree is replaced
+// by a placeholder, and the panic is reported as an error on the root.
+//
+// Example:
+//
+//	result := layout.LayoutResilient(root, layout.Loose(800, 600), ctx,
+//		layout.ResilientOptions{PlaceholderWidth: 100, PlaceholderHeight: 20})
+//	for _, err := range result.Errors {
+//		log.Print(err)
+//	}
+func LayoutResilient(root *Node, constraints Constraints, ctx *LayoutContext, opts ResilientOptions) (result LayoutResult) {
+	result.Errors = collectLayoutErrors(root, ctx.maxDepth())
+
+	type saved struct {
+		style    Style
+		children []*Node
+		text     string
+	}
+	originals := make([]saved, len(result.Errors))
+	for i, e := range result.Errors {
+		n := e.Node
+		originals[i] = saved{n.Style, n.Children, n.Text}
+		n.Style = placeholderStyle(opts)
+		n.Children = nil
+		n.Text = ""
+	}
+	defer func() {
+		for i, e := range result.Errors[:len(originals)] {
+			n := e.Node
+			n.Style, n.Children, n.Text = originals[i].style, originals[i].children, originals[i].text
+			n.TextLayout = nil
+			for _, child := range n.Children {
+				for _, d := range child.DescendantsAndSelf() {
+					d.Rect = Rect{}
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			root.Rect = Rect{Width: opts.PlaceholderWidth, Height: opts.PlaceholderHeight}
+			result.Size = Size{Width: opts.PlaceholderWidth, Height: opts.PlaceholderHeight}
+			result.Errors = append(result.Errors, &LayoutError{
+				Node: root,
+				Path: []int{},
+				Err:  fmt.Errorf("layout panicked: %v", r),
+			})
+		}
+	}()
+
+	result.Size = Layout(root, constraints, ctx)
+	return result
+}
+
+// collectLayoutErrors validates root's tree in tree order. The subtree of
+// an invalid node is not visited, since it is replaced as a whole. So is
+// the subtree of a node at maxDepth (-1 for no limit) with children, which
+// Layout wouldn't lay out.
+func collectLayoutErrors(root *Node, maxDepth int) []*LayoutError {
+	var errs []*LayoutError
+	walkTree(root, func(n *Node, path []int) walkStep {
+		if err := validateStyle(&n.Style); err != nil {
+			errs = append(errs, &LayoutError{Node: n, Path: append([]int{}, path...), Err: err})
+			return walkSkip
+		}
+		if len(path) == maxDepth && len(n.Children) > 0 {
+			err := &DepthError{Path: append(append([]int{}, path...), 0), MaxDepth: maxDepth}
+			errs = append(errs, &LayoutError{Node: n, Path: append([]int{}, path...), Err: err})
+			return walkSkip
+		}
+		return walkChildren
+	})
+	return errs
+}
+
+// placeholderStyle returns the style laid out in place of an invalid node.
+func placeholderStyle(opts ResilientOptions) Style {
+	return Style{
+		Display:   DisplayBlock,
+		Width:     Px(opts.PlaceholderWidth),
+		Height:    Px(opts.PlaceholderHeight),
+		BoxSizing: BoxSizingBorderBox,
+	}
+}
+
+// validateStyle reports the first value in s that layout can't handle.
+func validateStyle(s *Style) error {
+	lengths := []struct {
+		name    string
+		value   Length
+		maximum bool // +Inf is allowed as "no maximum"
+	}{
+		{"Width", s.Width, false},
+		{"Height", s.Height, false},
+		{"MinWidth", s.MinWidth, false},
+		{"MinHeight", s.MinHeight, false},
+		{"MaxWidth", s.MaxWidth, true},
+		{"MaxHeight", s.MaxHeight, true},
+		{"FlexBasis", s.FlexBasis.Length, false},
+		{"FlexGap", s.FlexGap, false},
+		{"FlexRowGap", s.FlexRowGap, false},
+		{"FlexColumnGap", s.FlexColumnGap, false},
+		{"GridGap", s.GridGap, false},
+		{"GridRowGap", s.GridRowGap, false},
+		{"GridColumnGap", s.GridColumnGap, false},
+		{"ColumnWidth", s.ColumnWidth, false},
+		{"ColumnGap", s.ColumnGap, false},
+		{"ColumnRuleWidth", s.ColumnRuleWidth, false},
+		{"FitContentWidth", s.FitContentWidth, true},
+		{"FitContentHeight", s.FitContentHeight, true},
+		{"Top", s.Top, false},
+		{"Right", s.Right, false},
+		{"Bottom", s.Bottom, false},
+		{"Left", s.Left, false},
+		{"InsetBlockStart", s.InsetBlockStart, false},
+		{"InsetBlockEnd", s.InsetBlockEnd, false},
+		{"InsetInlineStart", s.InsetInlineStart, false},
+		{"InsetInlineEnd", s.InsetInlineEnd, false},
+		{"MarginBlockStart", s.MarginBlockStart, false},
+		{"MarginBlockEnd", s.MarginBlockEnd, false},
+		{"MarginInlineStart", s.MarginInlineStart, false},
+		{"MarginInlineEnd", s.MarginInlineEnd, false},
+		{"PaddingBlockStart", s.PaddingBlockStart, false},
+		{"PaddingBlockEnd", s.PaddingBlockEnd, false},
+		{"PaddingInlineStart", s.PaddingInlineStart, false},
+		{"PaddingInlineEnd", s.PaddingInlineEnd, false},
+	}
+	for _, l := range lengths {
+		if err := validateFloat(l.name, l.value.Value, l.maximum); err != nil {
+			return err
+		}
+	}
+	for _, sp := range []struct {
+		name    string
+		spacing Spacing
+	}{{"Padding", s.Padding}, {"Margin", s.Margin}, {"Border", s.Border}} {
+		for _, side := range []Length{sp.spacing.Top, sp.spacing.Right, sp.spacing.Bottom, sp.spacing.Left} {
+			if err := validateFloat(sp.name, side.Value, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	factors := []struct {
+		name  string
+		value float64
+	}{{"FlexGrow", s.FlexGrow}, {"FlexShrink", s.FlexShrink}, {"AspectRatio", s.AspectRatio}}
+	for _, f := range factors {
+		if err := validateFloat(f.name, f.value, false); err != nil {
+			return err
+		}
+		if f.value < 0 {
+			return fmt.Errorf("%s is negative (%v)", f.name, f.value)
+		}
+	}
+
+	tracks := append(append([]GridTrack{s.GridAutoRows, s.GridAutoColumns}, s.GridTemplateRows...), s.GridTemplateColumns...)
+	for _, track := range tracks {
+		if err := validateFloat("grid track MinSize", track.MinSize.Value, false); err != nil {
+			return err
+		}
+		if err := validateFloat("grid track MaxSize", track.MaxSize.Value, true); err != nil {
+			return err
+		}
+		if err := validateFloat("grid track Fraction", track.Fraction, false); err != nil {
+			return err
+		}
+		if track.Fraction < 0 {
+			return fmt.Errorf("grid track Fraction is negative (%v)", track.Fraction)
+		}
+	}
+
+	lines := []struct {
+		name  string
+		value int
+	}{
+		{"GridRowStart", s.GridRowStart},
+		{"GridRowEnd", s.GridRowEnd},
+		{"GridColumnStart", s.GridColumnStart},
+		{"GridColumnEnd", s.GridColumnEnd},
+	}
+	for _, l := range lines {
+		if l.value == GridLineAuto {
+			continue
+		}
+		if n, ok := gridSpanValue(l.value); ok {
+			if n > gridMaxLine {
+				return fmt.Errorf("%s spans too many tracks (%d)", l.name, n)
+			}
+			continue
+		}
+		if l.value > gridMaxLine || l.value < -gridMaxLine {
+			return fmt.Errorf("%s is out of range (%d)", l.name, l.value)
+		}
+	}
+
+	if ts := s.TextStyle; ts != nil {
+		for _, f := range []struct {
+			name  string
+			value float64
+		}{
+			{"FontSize", ts.FontSize},
+			{"LineHeight", ts.LineHeight},
+			{"WordSpacing", ts.WordSpacing},
+			{"LetterSpacing", ts.LetterSpacing},
+			{"TextIndent", ts.TextIndent},
+			{"TabSize", ts.TabSize},
+		} {
+			if err := validateFloat(f.name, f.value, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateFloat rejects NaN and infinities. When maximum is true, +Inf is
+// accepted since it means "no limit".
+func validateFloat(name string, v float64, maximum bool) error {
+	if math.IsNaN(v) || math.IsInf(v, -1) || (math.IsInf(v, 1) && !maximum) {
+		return fmt.Errorf("%s is not finite (%v)", name, v)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package layout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLayoutResilientReplacesInvalidSubtree(t *testing.T) {
+	bad := &Node{
+		Style:    Style{Width: Px(math.NaN()), Height: Px(30)},
+		Children: []*Node{Fixed(10, 10)},
+	}
+	after := Fixed(40, 30)
+	row := HStack(Fixed(50, 30), bad, after)
+
+	result := LayoutResilient(row, Loose(400, 100), NewLayoutContext(400, 100, 16),
+		ResilientOptions{PlaceholderWidth: 20, PlaceholderHeight: 10})
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d", len(result.Errors))
+	}
+	err := result.Errors[0]
+	if err.Node != bad || len(err.Path) != 1 || err.Path[0] != 1 {
+		t.Errorf("Expected error on child 1, got path %v", err.Path)
+	}
+	if !strings.Contains(err.Error(), "Width is not finite") {
+		t.Errorf("Expected width error, got %q", err.Error())
+	}
+	if bad.Rect.Width != 20 || bad.Rect.Height != 10 {
+		t.Errorf("Expected 20x10 placeholder, got %.2fx%.2f", bad.Rect.Width, bad.Rect.Height)
+	}
+	if math.Abs(after.Rect.X-70) > 0.01 {
+		t.Errorf("Expected trailing sibling at X=70, got %.2f", after.Rect.X)
+	}
+	if math.Abs(result.Size.Width-110) > 0.01 {
+		t.Errorf("Expected row width 110, got %.2f", result.Size.Width)
+	}
+
+	// The input tree is restored
+	if !math.IsNaN(bad.Style.Width.Value) || len(bad.Children) != 1 {
+		t.Error("Expected invalid node's style and children to be restored")
+	}
+	if bad.Children[0].Rect != (Rect{}) {
+		t.Errorf("Expected descendants of placeholder to have zero rects, got %+v", bad.Children[0].Rect)
+	}
+}
+
+func TestLayoutResilientGridPlacementOutOfRange(t *testing.T) {
+	grid := threeColumnGrid(GridAutoFlowRow,
+		&Node{Style: Style{GridRowStart: 1000000, GridRowEnd: 1000001}},
+		&Node{},
+		&Node{},
+	)
+
+	result := LayoutResilient(grid, Loose(800, 600), NewLayoutContext(800, 600, 16), ResilientOptions{})
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "GridRowStart is out of range") {
+		t.Fatalf("Expected one grid line error, got %v", result.Errors)
+	}
+	// The placeholder is auto-placed like any other item
+	if x := grid.Children[2].Rect.X; x != 200 {
+		t.Errorf("Expected remaining items to auto-place normally, got X %.0f", x)
+	}
+	if result.Size.Height != 50 {
+		t.Errorf("Expected a single 50px row, got height %.0f", result.Size.Height)
+	}
+}
+
+func TestLayoutResilientGridSpanOutOfRange(t *testing.T) {
+	grid := threeColumnGrid(GridAutoFlowRow,
+		&Node{Style: Style{GridColumnEnd: Span(1 << 20), GridRowEnd: Span(3000)}},
+		&Node{},
+		&Node{},
+	)
+
+	result := LayoutResilient(grid, Loose(800, 600), NewLayoutContext(800, 600, 16), ResilientOptions{PlaceholderHeight: 10})
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "GridColumnEnd spans too many tracks") {
+		t.Fatalf("Expected one grid span error, got %v", result.Errors)
+	}
+	if result.Errors[0].Node != grid.Children[0] || grid.Children[0].Rect.Height != 10 {
+		t.Errorf("Expected the item to be replaced by a placeholder, got %+v", grid.Children[0].Rect)
+	}
+	if x := grid.Children[2].Rect.X; x != 200 {
+		t.Errorf("Expected remaining items to auto-place normally, got X %.0f", x)
+	}
+}
+
+func TestLayoutResilientValidTree(t *testing.T) {
+	root := VStack(Fixed(100, 20), Fixed(80, 30))
+	ctx := NewLayoutContext(400, 400, 16)
+
+	result := LayoutResilient(root, Loose(400, 400), ctx, ResilientOptions{PlaceholderWidth: 10, PlaceholderHeight: 10})
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+	if want := Layout(VStack(Fixed(100, 20), Fixed(80, 30)), Loose(400, 400), ctx); result.Size != want {
+		t.Errorf("Expected size %+v to match Layout, got %+v", want, result.Size)
+	}
+}
+
+func TestValidateStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		style Style
+		want  string
+	}{
+		{"valid", Style{Width: Px(10), MaxWidth: Px(math.Inf(1))}, ""},
+		{"infinite width", Style{Width: Px(math.Inf(1))}, "Width is not finite"},
+		{"NaN padding", Style{Padding: Uniform(Px(math.NaN()))}, "Padding is not finite"},
+		{"negative grow", Style{FlexGrow: -1}, "FlexGrow is negative"},
+		{"negative fraction", Style{GridTemplateColumns: []GridTrack{FractionTrack(-1)}}, "Fraction is negative"},
+		{"span is not a line", Style{GridColumnEnd: Span(5000)}, ""},
+		{"span out of range", Style{GridColumnEnd: Span(50000)}, "GridColumnEnd spans too many tracks"},
+		{"negative line out of range", Style{GridColumnStart: -20000}, "GridColumnStart is out of range"},
+		{"NaN font size", Style{TextStyle: &TextStyle{FontSize: math.NaN()}}, "FontSize is not finite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStyle(&tt.style)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
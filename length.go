@@ -48,17 +48,33 @@ const (
 	EmUnit  = units.EM  // 1em  = current element font-size
 	RemUnit = units.REM // 1rem = root element font-size
 	ChUnit  = units.CH  // 1ch  = advance measure of '0' glyph
+	ExUnit  = units.EX  // 1ex  = x-height of the font
 
 	// Viewport-relative units.
 	VhUnit   = units.VH   // 1vh   = 1% of viewport height
 	VwUnit   = units.VW   // 1vw   = 1% of viewport width
 	VmaxUnit = units.VMAX // 1vmax = 1% of larger viewport dimension
 	VminUnit = units.VMIN // 1vmin = 1% of smaller viewport dimension
+	ViUnit   = units.VI   // 1vi   = 1% of viewport inline size
+	VbUnit   = units.VB   // 1vb   = 1% of viewport block size
 
 	// UnboundedUnit represents an unbounded length (infinity).
 	// Layout-specific sentinel; not part of CSS L4. Used for maximum sizes
 	// that have no limit (e.g. unconstrained layout passes).
 	UnboundedUnit LengthUnit = "unbounded"
+
+	// PercentUnit represents a percentage of a reference size that depends
+	// on the property (for FlexBasis, the flex container's inner main size;
+	// for Width, Height and their min/max, the containing block).
+	// Layout-specific: the units package has no percentage length.
+	// ResolveLength has no reference size and resolves percentages to 0;
+	// the layout algorithms resolve them against the right size.
+	PercentUnit LengthUnit = "%"
+
+	// AutoUnit represents an auto length. Layout-specific sentinel, since
+	// units.Length has no keyword values. Currently only margins of flex
+	// items honor it; everywhere else (and in ResolveLength) it is 0.
+	AutoUnit LengthUnit = "auto"
 )
 
 // ─────────────────────────────────────────────────────────────────────────
@@ -96,8 +112,15 @@ func Em(value float64) Length { return units.Em(value) }
 func Rem(value float64) Length { return units.Rem(value) }
 
 // Ch creates a Length in ch units (relative to '0' character width).
+// The width is measured with the context's TextMetricsProvider, so with
+// terminal metrics Ch(20) is 20 cells.
 func Ch(value float64) Length { return units.Ch(value) }
 
+// Ex creates a Length in ex units (relative to the font's x-height). The
+// x-height comes from the context's TextMetricsProvider if it implements
+// XHeightProvider, and is 0.5em otherwise.
+func Ex(value float64) Length { return units.Ex(value) }
+
 // Vh creates a Length in vh units (relative to viewport height).
 func Vh(value float64) Length { return units.Vh(value) }
 
@@ -110,6 +133,12 @@ func Vmax(value float64) Length { return units.Vmax(value) }
 // Vmin creates a Length in vmin units (relative to smaller viewport dimension).
 func Vmin(value float64) Length { return units.Vmin(value) }
 
+// Vi creates a Length in vi units (relative to viewport inline size).
+func Vi(value float64) Length { return units.Vi(value) }
+
+// Vb creates a Length in vb units (relative to viewport block size).
+func Vb(value float64) Length { return units.Vb(value) }
+
 // Container-query length constructors. These create container-relative
 // lengths (cq*) that resolve against the nearest query container when
 // passed to ResolveLengthInContext. See CSS Containment Module Level 3:
@@ -137,6 +166,27 @@ func Cqmax(value float64) Length { return units.Cqmax(value) }
 // Equivalent to Px(math.MaxFloat64) but avoids repeated allocations.
 var PxUnbounded = Length{Value: math.MaxFloat64, Unit: Pixels}
 
+// Percent creates a percentage Length. See PercentUnit for where
+// percentages are supported.
+//
+// Example:
+//
+//	node.Style.FlexBasis = layout.Basis(layout.Percent(50))
+//	sidebar.Style.Width = layout.Percent(25)
+func Percent(value float64) Length {
+	return Length{Value: value, Unit: PercentUnit}
+}
+
+// Auto creates an auto Length. See AutoUnit for where auto is supported.
+//
+// Example:
+//
+//	// Push the last item of a row to the far end
+//	last.Style.Margin.Left = layout.Auto()
+func Auto() Length {
+	return Length{Unit: AutoUnit}
+}
+
 // UnboundedLength creates an unbounded Length.
 // This is more semantically clear than Px(math.MaxFloat64).
 func UnboundedLength() Length {
@@ -155,22 +205,60 @@ func UnboundedLength() Length {
 // Most of the unit math is delegated to github.com/SCKelemen/units via
 // units.Length.Resolve, which provides resolvers for the full CSS L4 unit
 // set (absolute, font-relative, viewport-relative, container-relative).
-// Two pieces of behavior remain layout-specific:
+// Eight pieces of behavior remain layout-specific:
 //
 //   - UnboundedUnit short-circuits to math.MaxFloat64. It is a layout-only
 //     sentinel; the units package has no concept of it.
-//   - Unknown / unsupported units (e.g. cq*, vi/vb when the corresponding
-//     context fields are unset) preserve the pre-migration default-case
-//     behavior of returning l.Value unchanged.
+//   - PercentUnit resolves to 0, since there is no reference size here.
+//   - AutoUnit resolves to 0; algorithms that support auto check for it
+//     before resolving.
+//   - The small, large and dynamic viewport units (svw, lvh, dvh, ...)
+//     resolve like vw/vh: a LayoutContext has a single, static viewport.
+//     vi/vb (and svi, lvb, ...) follow the inline and block axes of the
+//     root being laid out: in a vertical writing mode, vi resolves like
+//     vh and vb like vw. Outside layout they resolve like vw/vh.
+//   - calc() lengths (see Calc) are evaluated term by term, with
+//     percentage terms resolving to 0 as above.
+//   - The context's UnitResolver, if any, is consulted before the
+//     built-in units, so applications can add or override units.
+//   - Container query units (cq*) resolve against the query containers of
+//     the node being laid out (see ContainerType), and against the
+//     viewport outside layout or when there is none.
+//   - Unknown / unsupported units (e.g. viewport units when the viewport
+//     size is unset) preserve the pre-migration default-case behavior of
+//     returning l.Value unchanged.
 //
-// For ancestor-aware container query resolution (cq*), use
-// ResolveLengthInContext, which is the only path that populates
-// units.Context.ContainerWidth / ContainerHeight.
+// To resolve cq* units against the ancestors of an arbitrary node outside
+// layout, use ResolveLengthInContext.
 func ResolveLength(l Length, ctx *LayoutContext, currentFontSize float64) float64 {
 	// Layout-specific sentinel: not in CSS, units pkg doesn't know it.
 	if l.Unit == UnboundedUnit {
 		return math.MaxFloat64
 	}
+	if l.Unit == PercentUnit || l.Unit == AutoUnit {
+		return 0
+	}
+	if isCalc(l) {
+		return resolveCalc(l, 0, ctx, currentFontSize)
+	}
+	if ctx != nil && ctx.Units != nil {
+		if px, ok := ctx.Units.ResolveUnit(l.Value, l.Unit, currentFontSize); ok {
+			return px
+		}
+	}
+	// Unset and px lengths are already in pixels; don't build a units
+	// context (which measures the ch width) just to find that out
+	if l.Unit == "" || l.Unit == Pixels {
+		return l.Value
+	}
+	if l.IsContainerRelative() && ctx != nil {
+		return resolveContainerLength(l, ctx, currentFontSize, ctx.inlineContainer, ctx.sizeContainer)
+	}
+	if unit, ok := verticalViewportUnitAliases[l.Unit]; ok && ctx != nil && ctx.rootWritingMode.IsVertical() {
+		l.Unit = unit
+	} else if unit, ok := viewportUnitAliases[l.Unit]; ok {
+		l.Unit = unit
+	}
 
 	uctx := buildUnitsContext(ctx, currentFontSize)
 	resolved, err := l.Resolve(uctx)
@@ -182,22 +270,79 @@ func ResolveLength(l Length, ctx *LayoutContext, currentFontSize float64) float6
 	return resolved.Value
 }
 
+// viewportUnitAliases maps the viewport units that the units package can't
+// resolve onto vw and vh, with vi and vb as in a horizontal writing mode.
+// See ResolveLength.
+var viewportUnitAliases = map[LengthUnit]LengthUnit{
+	units.VI:  units.VW,
+	units.VB:  units.VH,
+	units.SVW: units.VW,
+	units.SVH: units.VH,
+	units.SVI: units.VW,
+	units.SVB: units.VH,
+	units.LVW: units.VW,
+	units.LVH: units.VH,
+	units.LVI: units.VW,
+	units.LVB: units.VH,
+	units.DVW: units.VW,
+	units.DVH: units.VH,
+	units.DVI: units.VW,
+	units.DVB: units.VH,
+}
+
+// verticalViewportUnitAliases maps vi and vb onto vh and vw in a vertical
+// writing mode, where the inline axis is the viewport's height.
+var verticalViewportUnitAliases = map[LengthUnit]LengthUnit{
+	units.VI:  units.VH,
+	units.VB:  units.VW,
+	units.SVI: units.VH,
+	units.SVB: units.VW,
+	units.LVI: units.VH,
+	units.LVB: units.VW,
+	units.DVI: units.VH,
+	units.DVB: units.VW,
+}
+
+// withRootWritingMode returns a copy of ctx whose vi and vb units follow
+// the axes of writing mode w, or ctx if they already do.
+func (ctx *LayoutContext) withRootWritingMode(w WritingMode) *LayoutContext {
+	if ctx == nil || w.IsVertical() == ctx.rootWritingMode.IsVertical() {
+		return ctx
+	}
+	copy := *ctx
+	copy.rootWritingMode = w
+	return &copy
+}
+
+// resolveLengthAgainst resolves l like ResolveLength, except that a
+// percentage, or a percentage term of a calc() length, is taken of base.
+func resolveLengthAgainst(l Length, base float64, ctx *LayoutContext, currentFontSize float64) float64 {
+	if l.Unit == PercentUnit {
+		return base * l.Value / 100
+	}
+	if isCalc(l) {
+		return resolveCalc(l, base, ctx, currentFontSize)
+	}
+	return ResolveLength(l, ctx, currentFontSize)
+}
+
 // buildUnitsContext maps a layout-side LayoutContext (plus the current
 // element's font size) onto a units.Context.
 //
 // The mapping reflects layout's terminal-cell-grid simplifications:
 //
-//   - ex (x-height), cap (cap-height), and lh (line-height) all collapse
-//     to the current font size — terminals do not provide distinct font
-//     metrics for these.
+//   - ch and ex (x-height) are measured with the text metrics provider;
+//     see measureCharWidth and measureXHeight.
+//   - cap (cap-height) and lh (line-height) collapse to the current font
+//     size — terminals do not provide distinct font metrics for these.
 //   - rlh (root line-height) collapses to the root font size for the same
 //     reason.
 //   - ic (CJK ideograph advance) is set to 2 * ch on the assumption that
 //     full-width CJK glyphs occupy two terminal cells.
 //
 // ContainerWidth and ContainerHeight are intentionally left zero here.
-// They are populated only by ResolveLengthInContext, which has the
-// NodeContext required to walk ancestors and find a query container.
+// They are populated only by resolveContainerLength, which knows the
+// query container.
 //
 // A nil LayoutContext is accepted and produces a minimal units.Context
 // populated only with currentFontSize. This matches the pre-migration
@@ -208,13 +353,17 @@ func ResolveLength(l Length, ctx *LayoutContext, currentFontSize float64) float6
 // back to l.Value via the error path in ResolveLength.
 func buildUnitsContext(ctx *LayoutContext, currentFontSize float64) *units.Context {
 	if ctx == nil {
-		return &units.Context{FontSize: currentFontSize}
+		return &units.Context{
+			FontSize: currentFontSize,
+			XHeight:  measureXHeight(currentFontSize, nil),
+			ChWidth:  measureCharWidth('0', currentFontSize, nil),
+		}
 	}
 	chWidth := measureCharWidth(ctx.ChReferenceChar, currentFontSize, ctx.TextMetrics)
 	return &units.Context{
 		FontSize:       currentFontSize,
 		RootFontSize:   ctx.RootFontSize,
-		XHeight:        currentFontSize,
+		XHeight:        measureXHeight(currentFontSize, ctx.TextMetrics),
 		CapHeight:      currentFontSize,
 		ChWidth:        chWidth,
 		IcWidth:        chWidth * 2,
@@ -223,17 +372,16 @@ func buildUnitsContext(ctx *LayoutContext, currentFontSize float64) *units.Conte
 		ViewportWidth:  ctx.ViewportWidth,
 		ViewportHeight: ctx.ViewportHeight,
 		// ContainerWidth / ContainerHeight left zero; populated by
-		// ResolveLengthInContext when a NodeContext is available.
+		// resolveContainerLength.
 	}
 }
 
-// measureCharWidth estimates the width of a character using text metrics.
-// For now, uses monospace approximation via TextMetricsProvider.
-// Can be swapped for true text measurement (HarfBuzz, FreeType) in the future.
+// measureCharWidth measures the advance of a character with metrics, or
+// with the package-level provider (see SetTextMetricsProvider) if metrics
+// is nil.
 func measureCharWidth(char rune, fontSize float64, metrics TextMetricsProvider) float64 {
 	if metrics == nil {
-		// Fallback: monospace approximation (60% of font size)
-		return fontSize * 0.6
+		metrics = getTextMetrics()
 	}
 
 	style := TextStyle{
@@ -245,3 +393,16 @@ func measureCharWidth(char rune, fontSize float64, metrics TextMetricsProvider)
 	width, _, _ := metrics.Measure(string(char), style)
 	return width
 }
+
+// measureXHeight returns the x-height for ex units from metrics, or from
+// the package-level provider if metrics is nil. Providers that don't
+// implement XHeightProvider get the CSS fallback of 0.5em.
+func measureXHeight(fontSize float64, metrics TextMetricsProvider) float64 {
+	if metrics == nil {
+		metrics = getTextMetrics()
+	}
+	if p, ok := metrics.(XHeightProvider); ok {
+		return p.XHeight(TextStyle{FontSize: fontSize})
+	}
+	return fontSize * 0.5
+}
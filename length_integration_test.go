@@ -20,6 +20,10 @@ func TestWidthWithAllUnits(t *testing.T) {
 		{"Vw", Vw(50), 500},     // 50% of 1000 = 500
 		{"Vmin", Vmin(50), 400}, // 50% of min(1000, 800) = 50% of 800 = 400
 		{"Vmax", Vmax(50), 500}, // 50% of max(1000, 800) = 50% of 1000 = 500
+		{"Vi", Vi(50), 500},     // Inline axis is horizontal: 50% of 1000
+		{"Vb", Vb(50), 400},     // Block axis is vertical: 50% of 800
+		{"Dvw", Length{Value: 50, Unit: "dvw"}, 500},
+		{"Svh", Length{Value: 50, Unit: "svh"}, 400},
 	}
 
 	for _, tt := range tests {
@@ -42,6 +46,32 @@ func TestWidthWithAllUnits(t *testing.T) {
 	}
 }
 
+// TestLogicalViewportUnitsInVerticalWritingMode tests that vi and vb follow
+// the root's writing mode
+func TestLogicalViewportUnitsInVerticalWritingMode(t *testing.T) {
+	ctx := NewLayoutContext(1000, 800, 16)
+	child := &Node{Style: Style{Width: Vb(10), Height: Length{Value: 10, Unit: "dvi"}}}
+	root := &Node{
+		Style:    Style{WritingMode: WritingModeVerticalRL, Width: Vi(50), Height: Vb(25)},
+		Children: []*Node{child},
+	}
+
+	// The inline axis is vertical: vi is a percentage of the viewport
+	// height, and vb of its width
+	size := Layout(root, Loose(1000, 800), ctx)
+	if size.Width != 400 || size.Height != 250 {
+		t.Errorf("Expected 400x250, got %.2fx%.2f", size.Width, size.Height)
+	}
+	if child.Rect.Width != 100 || child.Rect.Height != 80 {
+		t.Errorf("Expected the child 100x80, got %.2fx%.2f", child.Rect.Width, child.Rect.Height)
+	}
+
+	// Outside layout, they assume a horizontal writing mode
+	if got := ResolveLength(Vi(50), ctx, 16); got != 500 {
+		t.Errorf("Expected vi to resolve like vw outside layout, got %.2f", got)
+	}
+}
+
 // TestHeightWithAllUnits tests Height property with all available length units
 func TestHeightWithAllUnits(t *testing.T) {
 	ctx := NewLayoutContext(1000, 800, 16)
@@ -517,7 +547,7 @@ func TestFlexBasisWithUnits(t *testing.T) {
 				Children: []*Node{
 					{
 						Style: Style{
-							FlexBasis: tt.flexBasis,
+							FlexBasis: Basis(tt.flexBasis),
 							Height:    Px(50),
 						},
 					},
@@ -17,6 +17,7 @@ func TestLengthConstructors(t *testing.T) {
 		{"Em", Em(2), 2, EmUnit},
 		{"Rem", Rem(1.5), 1.5, RemUnit},
 		{"Ch", Ch(80), 80, ChUnit},
+		{"Ex", Ex(3), 3, ExUnit},
 		{"Vh", Vh(50), 50, VhUnit},
 		{"Vw", Vw(100), 100, VwUnit},
 		{"Vmax", Vmax(75), 75, VmaxUnit},
@@ -257,6 +258,46 @@ func TestResolveLengthCh(t *testing.T) {
 	}
 }
 
+// cellMetrics measures every character as one terminal cell with an
+// x-height of half a cell.
+type cellMetrics struct{}
+
+func (cellMetrics) Measure(text string, style TextStyle) (advance, ascent, descent float64) {
+	return float64(len([]rune(text))), 0.8, 0.2
+}
+
+func (cellMetrics) XHeight(style TextStyle) float64 { return 0.5 }
+
+// TestResolveLengthChExWithTextMetrics tests that ch and ex query the
+// active text metrics provider
+func TestResolveLengthChExWithTextMetrics(t *testing.T) {
+	ctx := NewLayoutContext(80, 24, 1).WithTextMetrics(cellMetrics{})
+	if got := ResolveLength(Ch(20), ctx, 1); got != 20 {
+		t.Errorf("ResolveLength(Ch(20)) = %v, want 20 cells", got)
+	}
+	if got := ResolveLength(Ex(4), ctx, 1); got != 2 {
+		t.Errorf("ResolveLength(Ex(4)) = %v, want 2", got)
+	}
+
+	// Without XHeightProvider, 1ex is 0.5em
+	ctx = NewLayoutContext(800, 600, 16)
+	if got := ResolveLength(Ex(2), ctx, 20); got != 20 {
+		t.Errorf("ResolveLength(Ex(2)) = %v, want 20", got)
+	}
+
+	// A nil context uses the package-level provider
+	if got := ResolveLength(Ch(10), nil, 10); got != 60 {
+		t.Errorf("ResolveLength(Ch(10), nil) = %v, want 60", got)
+	}
+
+	// Character-count widths size boxes in cells
+	box := &Node{Style: Style{Display: DisplayBlock, Width: Ch(12), Height: Px(1)}}
+	Layout(box, Loose(80, 24), NewLayoutContext(80, 24, 1).WithTextMetrics(cellMetrics{}))
+	if box.Rect.Width != 12 {
+		t.Errorf("Expected a 12 cell wide box, got %v", box.Rect.Width)
+	}
+}
+
 // TestResolveLengthChWithCustomChar tests ch unit with custom reference character
 func TestResolveLengthChWithCustomChar(t *testing.T) {
 	ctx := NewLayoutContext(1920, 1080, 16)
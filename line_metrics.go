@@ -0,0 +1,77 @@
+package layout
+
+// DecorationMetricsProvider is an optional interface for a
+// TextMetricsProvider that knows the underline and strikethrough metrics of
+// its fonts. Without it, decorations are placed from the font size.
+//
+// See: https://www.w3.org/TR/css-text-decor-3/#line-decoration
+type DecorationMetricsProvider interface {
+	// DecorationMetrics returns the distance above the baseline of the top
+	// of the underline (negative below it) and of the strikethrough, and
+	// their thicknesses, in the given style. Like the OpenType post and OS/2
+	// tables they usually come from.
+	DecorationMetrics(style TextStyle) (underlinePosition, underlineThickness, strikethroughPosition, strikethroughThickness float64)
+}
+
+// DecorationMetrics are the computed positions of a line's text decoration
+// lines: the offset of the top of each from the line's block start, and
+// their thicknesses. Renderers draw a decoration as a rule of the
+// thickness at that offset, whether or not TextStyle.TextDecoration
+// enables it.
+type DecorationMetrics struct {
+	Underline            float64
+	Overline             float64
+	LineThrough          float64
+	Thickness            float64 // Underline and overline thickness
+	LineThroughThickness float64
+}
+
+// computeLineMetrics sets the height, ascent, descent, baseline and
+// decoration metrics of each line, centering the content area of each line in its
+// line box (§4.4.1 half-leading).
+// CSS Inline Layout Module Level 3: https://www.w3.org/TR/css-inline-3/#inline-height
+func computeLineMetrics(lines []TextLine, lineHeight float64, style TextStyle, ctx *LayoutContext) {
+	// Ascent and descent of an empty line (the strut)
+	_, strutAscent, strutDescent := measureText(" ", style, ctx)
+
+	underlinePos, underlineThickness, strikePos, strikeThickness := decorationMetrics(style)
+	for i := range lines {
+		line := &lines[i]
+		ascent, descent := strutAscent, strutDescent
+		for _, box := range line.Boxes {
+			if box.Ascent > ascent {
+				ascent = box.Ascent
+			}
+			if box.Descent > descent {
+				descent = box.Descent
+			}
+		}
+		line.Height = lineHeight
+		line.Ascent, line.Descent = ascent, descent
+		line.Baseline = (lineHeight-(ascent+descent))/2 + ascent
+		line.Decorations = DecorationMetrics{
+			Underline:            line.Baseline - underlinePos,
+			Overline:             line.Baseline - ascent,
+			LineThrough:          line.Baseline - strikePos,
+			Thickness:            underlineThickness,
+			LineThroughThickness: strikeThickness,
+		}
+	}
+}
+
+// decorationMetrics returns the decoration metrics of style from the
+// metrics provider, or derived from the font size: a thickness of 1/16em,
+// an underline 1/10em below the baseline and a strikethrough centered at
+// half the x-height.
+func decorationMetrics(style TextStyle) (underlinePosition, underlineThickness, strikethroughPosition, strikethroughThickness float64) {
+	metrics := getTextMetrics()
+	if p, ok := metrics.(DecorationMetricsProvider); ok {
+		return p.DecorationMetrics(style)
+	}
+	xHeight := style.FontSize * 0.5
+	if p, ok := metrics.(XHeightProvider); ok {
+		xHeight = p.XHeight(style)
+	}
+	thickness := style.FontSize / 16
+	return -style.FontSize / 10, thickness, xHeight/2 + thickness/2, thickness
+}
@@ -0,0 +1,59 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// decoratedMetrics is fakeMetrics with font decoration metrics.
+type decoratedMetrics struct {
+	fakeMetrics
+}
+
+func (*decoratedMetrics) DecorationMetrics(style TextStyle) (float64, float64, float64, float64) {
+	return -3, 2, 6, 1
+}
+
+func TestLineMetrics(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	// 20px text (ascent 16, descent 4) in 30px lines: 5px of half-leading
+	node := Text("Hello world", Style{TextStyle: &TextStyle{FontSize: 20, LineHeight: 1.5}})
+	LayoutText(node, Loose(60, 200), NewLayoutContext(800, 600, 16))
+
+	lines := node.TextLayout.Lines
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if line.Ascent != 16 || line.Descent != 4 || line.Baseline != 21 {
+			t.Errorf("Line %d: expected ascent 16, descent 4 and baseline 21, got %v, %v and %v", i, line.Ascent, line.Descent, line.Baseline)
+		}
+	}
+
+	// Without font metrics: 1/16em thick, the underline 1/10em below the
+	// baseline and the line-through centered on half the x-height (0.5em)
+	got := lines[0].Decorations
+	want := DecorationMetrics{Underline: 23, Overline: 5, LineThrough: 15.375, Thickness: 1.25, LineThroughThickness: 1.25}
+	if got != want {
+		t.Errorf("Expected decorations %+v, got %+v", want, got)
+	}
+}
+
+func TestLineMetricsFromProvider(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	SetTextMetricsProvider(&decoratedMetrics{fakeMetrics{charWidth: 10}})
+
+	node := Text("Hi", Style{TextStyle: &TextStyle{FontSize: 10, LineHeight: 1}})
+	LayoutText(node, Loose(100, 100), NewLayoutContext(800, 600, 16))
+
+	line := node.TextLayout.Lines[0]
+	if math.Abs(line.Baseline-8) > 1e-9 {
+		t.Fatalf("Expected baseline 8, got %v", line.Baseline)
+	}
+	want := DecorationMetrics{Underline: 11, Overline: 0, LineThrough: 2, Thickness: 2, LineThroughThickness: 1}
+	if got := line.Decorations; got != want {
+		t.Errorf("Expected decorations %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,157 @@
+package layout
+
+// Logical properties.
+//
+// MarginInlineStart, PaddingBlockEnd, InsetInlineStart and the other
+// logical sides are named after the flow of the content rather than the
+// screen: the block axis is the one blocks stack along and the inline axis
+// the one text runs along. Which physical side each one is depends on the
+// box's WritingMode and on its Direction (TextStyle.Direction, inherited
+// from the nearest ancestor with a TextStyle):
+//
+//	writing mode    block-start  block-end  inline-start (ltr / rtl)
+//	horizontal-tb   top          bottom     left / right
+//	vertical-rl     right        left       top / bottom
+//	vertical-lr     left         right      top / bottom
+//	sideways-rl     right        left       top / bottom
+//	sideways-lr     left         right      bottom / top
+//
+// The end sides are opposite the start sides. A single style with
+// MarginInlineStart set therefore gives a left margin in left-to-right
+// text and a right margin in right-to-left text.
+//
+// Layout, LayoutWithPositioning and UpdateSticky map the logical sides
+// that are set (not the zero Length) onto the physical ones for the
+// duration of the call, overriding what's there, and restore the physical
+// sides afterwards. Inside UpdateSticky, direction is inherited from the
+// container down.
+//
+// See: https://www.w3.org/TR/css-logical-1/
+// See: https://www.w3.org/TR/css-writing-modes-4/#logical-to-physical
+
+// Physical sides, in the order of CSS shorthands.
+const (
+	sideTop = iota
+	sideRight
+	sideBottom
+	sideLeft
+)
+
+// ResolveLogicalSides sets the physical margins, paddings and insets of s
+// that its logical ones map to in direction, for its WritingMode. Logical
+// sides that aren't set leave the physical side alone.
+//
+// Example:
+//
+//	style := layout.Style{MarginInlineStart: layout.Px(8)}
+//	style.ResolveLogicalSides(layout.DirectionRTL) // style.Margin.Right is 8px
+func (s *Style) ResolveLogicalSides(direction Direction) {
+	blockStart, blockEnd, inlineStart, inlineEnd := physicalSides(s.WritingMode, direction)
+	margin := [4]*Length{&s.Margin.Top, &s.Margin.Right, &s.Margin.Bottom, &s.Margin.Left}
+	padding := [4]*Length{&s.Padding.Top, &s.Padding.Right, &s.Padding.Bottom, &s.Padding.Left}
+	inset := [4]*Length{&s.Top, &s.Right, &s.Bottom, &s.Left}
+	for _, m := range []struct {
+		sides   [4]*Length
+		logical [4]Length
+	}{
+		{margin, [4]Length{s.MarginBlockStart, s.MarginBlockEnd, s.MarginInlineStart, s.MarginInlineEnd}},
+		{padding, [4]Length{s.PaddingBlockStart, s.PaddingBlockEnd, s.PaddingInlineStart, s.PaddingInlineEnd}},
+		{inset, [4]Length{s.InsetBlockStart, s.InsetBlockEnd, s.InsetInlineStart, s.InsetInlineEnd}},
+	} {
+		for i, side := range [4]int{blockStart, blockEnd, inlineStart, inlineEnd} {
+			if m.logical[i].Unit != "" {
+				*m.sides[side] = m.logical[i]
+			}
+		}
+	}
+}
+
+// hasLogicalSides reports whether any logical side of s is set.
+func hasLogicalSides(s *Style) bool {
+	for _, l := range []Length{
+		s.MarginBlockStart, s.MarginBlockEnd, s.MarginInlineStart, s.MarginInlineEnd,
+		s.PaddingBlockStart, s.PaddingBlockEnd, s.PaddingInlineStart, s.PaddingInlineEnd,
+		s.InsetBlockStart, s.InsetBlockEnd, s.InsetInlineStart, s.InsetInlineEnd,
+	} {
+		if l.Unit != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// physicalSides returns the physical sides the block-start, block-end,
+// inline-start and inline-end sides map to.
+func physicalSides(wm WritingMode, direction Direction) (blockStart, blockEnd, inlineStart, inlineEnd int) {
+	switch wm {
+	case WritingModeVerticalRL, WritingModeSidewaysRL:
+		blockStart, blockEnd, inlineStart, inlineEnd = sideRight, sideLeft, sideTop, sideBottom
+	case WritingModeVerticalLR:
+		blockStart, blockEnd, inlineStart, inlineEnd = sideLeft, sideRight, sideTop, sideBottom
+	case WritingModeSidewaysLR:
+		blockStart, blockEnd, inlineStart, inlineEnd = sideLeft, sideRight, sideBottom, sideTop
+	default:
+		blockStart, blockEnd, inlineStart, inlineEnd = sideTop, sideBottom, sideLeft, sideRight
+	}
+	if direction == DirectionRTL {
+		inlineStart, inlineEnd = inlineEnd, inlineStart
+	}
+	return
+}
+
+// resolveLogicalTree resolves the logical sides of root and its
+// descendants (see ResolveLogicalSides), and returns a function that
+// restores their physical sides.
+//
+// Example:
+//
+//	restore := resolveLogicalTree(root)
+//	defer restore()
+func resolveLogicalTree(root *Node) (restore func()) {
+	restore, _ = prepareTree(root, -1)
+	return restore
+}
+
+// logicalSides resolves the logical sides of the nodes of a tree, walked
+// in tree order, remembering their physical sides.
+type logicalSides struct {
+	originals []savedSides
+
+	// directions holds the inherited direction of each node on the path
+	// to the last one resolved
+	directions []Direction
+}
+
+// savedSides are a node's physical sides before its logical ones were
+// resolved.
+type savedSides struct {
+	node                     *Node
+	margin, padding          Spacing
+	top, right, bottom, left Length
+}
+
+// resolve resolves the logical sides of n, at depth in the walk.
+func (l *logicalSides) resolve(n *Node, depth int) {
+	direction := DirectionLTR
+	if depth > 0 {
+		direction = l.directions[depth-1]
+	}
+	s := &n.Style
+	if s.TextStyle != nil {
+		direction = s.TextStyle.Direction
+	}
+	l.directions = append(l.directions[:depth], direction)
+	if hasLogicalSides(s) {
+		l.originals = append(l.originals, savedSides{n, s.Margin, s.Padding, s.Top, s.Right, s.Bottom, s.Left})
+		s.ResolveLogicalSides(direction)
+	}
+}
+
+// restore restores the physical sides of the nodes resolved.
+func (l *logicalSides) restore() {
+	for _, o := range l.originals {
+		s := &o.node.Style
+		s.Margin, s.Padding = o.margin, o.padding
+		s.Top, s.Right, s.Bottom, s.Left = o.top, o.right, o.bottom, o.left
+	}
+}
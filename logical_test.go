@@ -0,0 +1,63 @@
+package layout
+
+import "testing"
+
+func TestLogicalSidesLayout(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+	for _, tt := range []struct {
+		direction Direction
+		x         float64
+	}{{DirectionLTR, 20}, {DirectionRTL, 0}} {
+		child := &Node{Style: Style{Width: Px(-1), Height: Px(10), MarginBlockStart: Px(5)}}
+		root := &Node{
+			Style: Style{
+				Width:              Px(200),
+				PaddingInlineStart: Px(20),
+				TextStyle:          &TextStyle{Direction: tt.direction},
+			},
+			Children: []*Node{child},
+		}
+		Layout(root, Loose(800, 600), ctx)
+
+		// The padding is on the left in left-to-right text, on the right in
+		// right-to-left text
+		if child.Rect.X != tt.x || child.Rect.Width != 200 || child.Rect.Y != 5 {
+			t.Errorf("Direction %v: expected the child at (%v, 5), 200 wide, got %+v", tt.direction, tt.x, child.Rect)
+		}
+		if root.Style.Padding != (Spacing{}) || child.Style.Margin != (Spacing{}) {
+			t.Errorf("Direction %v: expected the physical sides to be restored, got %+v and %+v", tt.direction, root.Style.Padding, child.Style.Margin)
+		}
+	}
+}
+
+func TestResolveLogicalSides(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      WritingMode
+		direction Direction
+		want      Spacing
+	}{
+		{"horizontal-tb ltr", WritingModeHorizontalTB, DirectionLTR, Spacing{Top: Px(1), Bottom: Px(2), Left: Px(3), Right: Px(4)}},
+		{"horizontal-tb rtl", WritingModeHorizontalTB, DirectionRTL, Spacing{Top: Px(1), Bottom: Px(2), Right: Px(3), Left: Px(4)}},
+		{"vertical-rl", WritingModeVerticalRL, DirectionLTR, Spacing{Right: Px(1), Left: Px(2), Top: Px(3), Bottom: Px(4)}},
+		{"vertical-lr rtl", WritingModeVerticalLR, DirectionRTL, Spacing{Left: Px(1), Right: Px(2), Bottom: Px(3), Top: Px(4)}},
+		{"sideways-lr", WritingModeSidewaysLR, DirectionLTR, Spacing{Left: Px(1), Right: Px(2), Bottom: Px(3), Top: Px(4)}},
+	}
+	for _, tt := range tests {
+		s := Style{
+			WritingMode:      tt.mode,
+			MarginBlockStart: Px(1), MarginBlockEnd: Px(2), MarginInlineStart: Px(3), MarginInlineEnd: Px(4),
+		}
+		s.ResolveLogicalSides(tt.direction)
+		if s.Margin != tt.want {
+			t.Errorf("%s: expected margin %+v, got %+v", tt.name, tt.want, s.Margin)
+		}
+	}
+
+	// Unset logical sides leave the physical ones alone
+	s := Style{Margin: Uniform(Px(7)), InsetInlineEnd: Px(0)}
+	s.ResolveLogicalSides(DirectionLTR)
+	if s.Margin != Uniform(Px(7)) || s.Right != Px(0) || s.Left.Unit != "" {
+		t.Errorf("Expected only the right inset to be set, got margin %+v and insets %v %v", s.Margin, s.Left, s.Right)
+	}
+}
@@ -0,0 +1,140 @@
+package layout
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// MeasureCache remembers the sizes each node was laid out at during a
+// layout, keyed by the constraints it was given, like the layout cache of
+// Flutter's render objects.
+//
+// Flex and grid containers lay out their items more than once: to measure
+// them, to find their hypothetical cross size at the used main size, and
+// again after stretching. Each pass descends into the item's whole
+// subtree, so nested containers repeat identical work many times over.
+// With a MeasureCache, a node laid out again under the same constraints,
+// with the same style and the same inherited context, gets its earlier
+// result back, and the rects of its descendants are restored from it.
+//
+// Results are only reused within one call to Layout (or
+// LayoutWithPositioning, ApplyStates, ...), so changing a tree between
+// layouts never needs an invalidation call. To reuse results across
+// layouts and between identical subtrees, use a LayoutCache as well.
+//
+// A MeasureCache only holds statistics, so one may be shared across
+// layouts and goroutines:
+//
+//	measure := layout.NewMeasureCache()
+//	ctx := layout.NewLayoutContext(800, 600, 16).WithMeasureCache(measure)
+//	layout.Layout(root, layout.Loose(800, 600), ctx)
+//	fmt.Printf("%+v\n", measure.Stats())
+type MeasureCache struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// MeasureCacheStats reports MeasureCache effectiveness counters.
+type MeasureCacheStats struct {
+	Hits      int // Layouts of a node answered from an earlier one
+	Misses    int // Layouts of a node that ran the layout algorithm
+	Evictions int // Results dropped because a node had too many
+}
+
+// maxMeasuredSizes is the number of results kept per node. Items are
+// rarely laid out under more than a few different constraints.
+const maxMeasuredSizes = 4
+
+// measurePasses numbers layouts, so nodes can tell results of the current
+// one from stale ones.
+var measurePasses atomic.Uint64
+
+// measuredSizes are the results of a node's layouts during one pass, most
+// recent last.
+type measuredSizes struct {
+	pass    uint64
+	entries []measuredSize
+}
+
+// measuredSize is a node's layout result under the constraints and
+// context that hash to key.
+type measuredSize struct {
+	key   uint64
+	entry *layoutCacheEntry
+}
+
+// NewMeasureCache creates a measure cache with zeroed counters.
+func NewMeasureCache() *MeasureCache {
+	return &MeasureCache{}
+}
+
+// Stats returns the current hit, miss and eviction counters.
+func (c *MeasureCache) Stats() MeasureCacheStats {
+	return MeasureCacheStats{
+		Hits:      int(c.hits.Load()),
+		Misses:    int(c.misses.Load()),
+		Evictions: int(c.evictions.Load()),
+	}
+}
+
+// Reset zeroes the counters.
+func (c *MeasureCache) Reset() {
+	c.hits.Store(0)
+	c.misses.Store(0)
+	c.evictions.Store(0)
+}
+
+// beginMeasurePass returns a copy of ctx for a new layout pass if it
+// carries a MeasureCache, so results of earlier passes aren't reused.
+// Otherwise it returns ctx.
+func (ctx *LayoutContext) beginMeasurePass() *LayoutContext {
+	if ctx == nil || ctx.MeasureCache == nil {
+		return ctx
+	}
+	copy := *ctx
+	copy.measurePass = measurePasses.Add(1)
+	return &copy
+}
+
+// layout runs layoutFn for node unless node was laid out earlier in the
+// current pass under the same constraints, with the same style and
+// context, in which case that result is restored.
+func (c *MeasureCache) layout(node *Node, constraints Constraints, ctx *LayoutContext, layoutFn func() Size) Size {
+	// Within a pass, a node's descendants only change through the node:
+	// the percentages it resolves and the context it passes down. So its
+	// own style, the constraints and the context decide the result.
+	h := fnv.New64a()
+	hashConstraints(h, constraints, ctx)
+	hashNode(h, node)
+	key := h.Sum64()
+
+	m := &node.measured
+	if m.pass != ctx.measurePass {
+		// Don't reuse the array: a clone of node may share it
+		m.pass = ctx.measurePass
+		m.entries = nil
+	}
+	for _, e := range m.entries {
+		if e.key == key && e.entry.restore(node) {
+			c.hits.Add(1)
+			return e.entry.size
+		}
+	}
+	c.misses.Add(1)
+
+	size := layoutFn()
+
+	entry := &layoutCacheEntry{size: size}
+	entry.capture(node)
+	// A nested layout of node may have started a new pass
+	if m.pass != ctx.measurePass || ctx.canceled() {
+		return size
+	}
+	if len(m.entries) == maxMeasuredSizes {
+		c.evictions.Add(1)
+		m.entries = append(m.entries[:0], m.entries[1:]...)
+	}
+	m.entries = append(m.entries, measuredSize{key: key, entry: entry})
+	return size
+}
@@ -0,0 +1,59 @@
+package layout
+
+import (
+	"testing"
+)
+
+// makeMeasureTree builds flex rows nested depth deep, each holding a
+// growing row and a fixed box, around a wrapping label.
+func makeMeasureTree(depth int) *Node {
+	node := Text("A label that wraps", Style{TextStyle: &TextStyle{FontSize: 12}})
+	for i := 0; i < depth; i++ {
+		node = &Node{
+			Style: Style{Display: DisplayFlex, Padding: Uniform(Px(2))},
+			Children: []*Node{
+				{Style: Style{Display: DisplayFlex, FlexGrow: 1}, Children: []*Node{node}},
+				{Style: Style{Width: Px(30), Height: Px(10)}},
+			},
+		}
+	}
+	return node
+}
+
+func TestMeasureCacheMatchesUncachedLayout(t *testing.T) {
+	cached, uncached := makeMeasureTree(4), makeMeasureTree(4)
+	measure := NewMeasureCache()
+	Layout(cached, Loose(300, Unbounded), NewLayoutContext(800, 600, 16).WithMeasureCache(measure))
+	Layout(uncached, Loose(300, Unbounded), NewLayoutContext(800, 600, 16))
+
+	stats := measure.Stats()
+	if stats.Hits == 0 || stats.Misses == 0 {
+		t.Errorf("Expected repeated layouts of nested items to hit, got %+v", stats)
+	}
+	want, got := uncached.DescendantsAndSelf(), cached.DescendantsAndSelf()
+	for i := range want {
+		if got[i].Rect != want[i].Rect {
+			t.Errorf("Node %d: expected rect %+v, got %+v", i, want[i].Rect, got[i].Rect)
+		}
+	}
+
+	measure.Reset()
+	if stats := measure.Stats(); stats != (MeasureCacheStats{}) {
+		t.Errorf("Expected zeroed stats after Reset, got %+v", stats)
+	}
+}
+
+func TestMeasureCacheIsPerLayout(t *testing.T) {
+	child := &Node{Style: Style{Width: Px(40), Height: Px(10)}}
+	root := &Node{Style: Style{Display: DisplayFlex, Width: Px(200)}, Children: []*Node{{Children: []*Node{child}}}}
+	ctx := NewLayoutContext(800, 600, 16).WithMeasureCache(NewMeasureCache())
+	Layout(root, Loose(800, 600), ctx)
+
+	// The flex item's own style is unchanged, but a later layout doesn't
+	// reuse what it measured before its child grew
+	child.Style.Width = Px(80)
+	Layout(root, Loose(800, 600), ctx)
+	if root.Children[0].Rect.Width != 80 {
+		t.Errorf("Expected the item to grow with its child to 80, got %v", root.Children[0].Rect.Width)
+	}
+}
@@ -0,0 +1,348 @@
+package layout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MediaQuery is a parsed CSS media query list, evaluated against a
+// LayoutContext. It lets a single tree definition be restyled for the
+// viewport before layout:
+//
+//	wide, _ := layout.ParseMediaQuery("(min-width: 800px) and (orientation: landscape)")
+//	if wide.Match(ctx) {
+//	    sidebar.Style.Display = layout.DisplayBlock
+//	}
+//
+// Supported syntax:
+//
+//   - media types all, screen and print; a LayoutContext is a screen
+//   - not before a query, and only before a media type
+//   - width, height and aspect-ratio, with min-/max- prefixes or range
+//     syntax such as (width >= 600px) and (400px < width)
+//   - orientation: portrait or landscape
+//   - prefers-color-scheme: light or dark (see LayoutContext.PrefersDark)
+//   - queries joined with and, and comma-separated query lists, which
+//     match if any query matches
+//
+// Lengths are resolved against the context, with em and rem relative to
+// its RootFontSize. The zero MediaQuery matches every context.
+//
+// See: https://www.w3.org/TR/mediaqueries-4/
+type MediaQuery struct {
+	source  string
+	queries []mediaQuery
+}
+
+// mediaQuery is a single query of a query list.
+type mediaQuery struct {
+	not       bool
+	mediaType string // "" for any
+	features  []mediaFeature
+}
+
+// mediaFeature is a media feature test such as (min-width: 600px).
+type mediaFeature struct {
+	name    string // "width", "height", "aspect-ratio", "orientation" or "prefers-color-scheme"
+	op      string // "<", "<=", "=", ">=", ">", or "" in a boolean context
+	length  Length // For width and height
+	ratio   float64
+	keyword string // For orientation and prefers-color-scheme
+}
+
+// mediaRangeOps are the range operators, longest first.
+var mediaRangeOps = []string{"<=", ">=", "<", ">", "="}
+
+// ParseMediaQuery parses a media query list, such as the prelude of an
+// @media rule.
+//
+// Example:
+//
+//	q, err := layout.ParseMediaQuery("screen and (max-width: 599px), (prefers-color-scheme: dark)")
+func ParseMediaQuery(s string) (MediaQuery, error) {
+	src := strings.TrimSpace(s)
+	mq := MediaQuery{source: src}
+	if src == "" {
+		return mq, nil
+	}
+	for _, part := range strings.Split(src, ",") {
+		q, err := parseMediaQuery(part)
+		if err != nil {
+			return MediaQuery{}, fmt.Errorf("layout: invalid media query %q: %v", s, err)
+		}
+		mq.queries = append(mq.queries, q)
+	}
+	return mq, nil
+}
+
+// String returns the source text of the query list.
+func (mq MediaQuery) String() string {
+	return mq.source
+}
+
+// Match reports whether ctx matches the query list. A nil context
+// matches only the zero MediaQuery.
+func (mq MediaQuery) Match(ctx *LayoutContext) bool {
+	if len(mq.queries) == 0 {
+		return true
+	}
+	if ctx == nil {
+		return false
+	}
+	for _, q := range mq.queries {
+		if q.match(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q mediaQuery) match(ctx *LayoutContext) bool {
+	ok := q.mediaType == "" || q.mediaType == "all" || q.mediaType == "screen"
+	for _, f := range q.features {
+		ok = ok && f.match(ctx)
+	}
+	return ok != q.not
+}
+
+func (f mediaFeature) match(ctx *LayoutContext) bool {
+	width, height := ctx.ViewportWidth, ctx.ViewportHeight
+	switch f.name {
+	case "width":
+		return compareMedia(width, f.op, ResolveLength(f.length, ctx, ctx.RootFontSize))
+	case "height":
+		return compareMedia(height, f.op, ResolveLength(f.length, ctx, ctx.RootFontSize))
+	case "aspect-ratio":
+		if height <= 0 {
+			return false
+		}
+		return compareMedia(width/height, f.op, f.ratio)
+	case "orientation":
+		portrait := height >= width
+		return f.keyword == "" || portrait == (f.keyword == "portrait")
+	case "prefers-color-scheme":
+		return f.keyword == "" || ctx.PrefersDark == (f.keyword == "dark")
+	}
+	return false
+}
+
+// compareMedia evaluates "v op ref". In a boolean context, a feature
+// matches if it isn't zero.
+func compareMedia(v float64, op string, ref float64) bool {
+	switch op {
+	case "<":
+		return v < ref
+	case "<=":
+		return v <= ref
+	case "=":
+		return v == ref
+	case ">=":
+		return v >= ref
+	case ">":
+		return v > ref
+	}
+	return v != 0
+}
+
+// parseMediaQuery parses a single query: [not|only] [type] [and] (feature) [and (feature)]...
+func parseMediaQuery(s string) (mediaQuery, error) {
+	var q mediaQuery
+	tokens, err := mediaTokens(s)
+	if err != nil {
+		return q, err
+	}
+	if len(tokens) == 0 {
+		return q, fmt.Errorf("empty query")
+	}
+	if t := strings.ToLower(tokens[0]); t == "not" || t == "only" {
+		q.not = t == "not"
+		tokens = tokens[1:]
+		if len(tokens) == 0 || (!q.not && tokens[0][0] == '(') {
+			return q, fmt.Errorf("expected a media type after %s", t)
+		}
+	}
+	if len(tokens) > 0 && tokens[0][0] != '(' {
+		q.mediaType = strings.ToLower(tokens[0])
+		if q.mediaType == "and" || q.mediaType == "not" || q.mediaType == "only" || q.mediaType == "or" {
+			return q, fmt.Errorf("unexpected %q", tokens[0])
+		}
+		tokens = tokens[1:]
+		if len(tokens) > 0 {
+			if !strings.EqualFold(tokens[0], "and") {
+				return q, fmt.Errorf("expected and, got %q", tokens[0])
+			}
+			tokens = tokens[1:]
+			if len(tokens) == 0 {
+				return q, fmt.Errorf("expected a feature after and")
+			}
+		}
+	}
+	for i, t := range tokens {
+		if i%2 == 1 {
+			if !strings.EqualFold(t, "and") {
+				return q, fmt.Errorf("expected and, got %q", t)
+			}
+			continue
+		}
+		if t[0] != '(' {
+			return q, fmt.Errorf("expected a feature, got %q", t)
+		}
+		f, err := parseMediaFeature(t[1 : len(t)-1])
+		if err != nil {
+			return q, err
+		}
+		q.features = append(q.features, f)
+	}
+	if len(tokens)%2 == 0 && len(tokens) > 0 {
+		return q, fmt.Errorf("expected a feature after and")
+	}
+	return q, nil
+}
+
+// mediaTokens splits a query into words and parenthesized features.
+func mediaTokens(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case isSelectorSpace(c):
+			i++
+		case c == '(':
+			// Up to the matching ), since values may be calc() expressions
+			start, depth := i, 0
+			for ; i < len(s); i++ {
+				if s[i] == '(' {
+					depth++
+				} else if s[i] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("missing )")
+			}
+			i++
+			tokens = append(tokens, s[start:i])
+		case c == ')':
+			return nil, fmt.Errorf("unexpected )")
+		default:
+			start := i
+			for i < len(s) && !isSelectorSpace(s[i]) && s[i] != '(' && s[i] != ')' {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// parseMediaFeature parses the inside of a feature test.
+func parseMediaFeature(s string) (mediaFeature, error) {
+	s = strings.TrimSpace(s)
+	var f mediaFeature
+	var value string
+	if name, v, ok := strings.Cut(s, ":"); ok {
+		f.name, value, f.op = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(v), "="
+		if n, ok := strings.CutPrefix(f.name, "min-"); ok {
+			f.name, f.op = n, ">="
+		} else if n, ok := strings.CutPrefix(f.name, "max-"); ok {
+			f.name, f.op = n, "<="
+		}
+	} else if i, op := mediaRangeOp(s); op != "" {
+		left, right := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(op):])
+		f.name, value, f.op = strings.ToLower(left), right, op
+		if !isMediaRangeFeature(f.name) {
+			// value op name: flip the comparison
+			f.name, value = strings.ToLower(right), left
+			f.op = map[string]string{"<": ">", "<=": ">=", "=": "=", ">=": "<=", ">": "<"}[op]
+		}
+	} else {
+		f.name = strings.ToLower(s)
+	}
+
+	switch f.name {
+	case "width", "height":
+		if f.op == "" {
+			return f, nil
+		}
+		l, err := parseMediaLength(value)
+		if err != nil {
+			return f, err
+		}
+		f.length = l
+	case "aspect-ratio":
+		if f.op == "" {
+			return f, nil
+		}
+		r, err := parseMediaRatio(value)
+		if err != nil {
+			return f, err
+		}
+		f.ratio = r
+	case "orientation", "prefers-color-scheme":
+		if f.op == "" {
+			return f, nil
+		}
+		if f.op != "=" {
+			return f, fmt.Errorf("%s isn't a range feature", f.name)
+		}
+		f.keyword = strings.ToLower(value)
+		valid := f.keyword == "portrait" || f.keyword == "landscape"
+		if f.name == "prefers-color-scheme" {
+			valid = f.keyword == "light" || f.keyword == "dark"
+		}
+		if !valid {
+			return f, fmt.Errorf("invalid %s %q", f.name, value)
+		}
+	default:
+		return f, fmt.Errorf("unsupported feature %q", f.name)
+	}
+	return f, nil
+}
+
+// mediaRangeOp returns the index of the first range operator in s.
+func mediaRangeOp(s string) (int, string) {
+	for i := range s {
+		for _, op := range mediaRangeOps {
+			if strings.HasPrefix(s[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+func isMediaRangeFeature(name string) bool {
+	return name == "width" || name == "height" || name == "aspect-ratio"
+}
+
+// parseMediaLength parses a length, which may be a calc() expression.
+func parseMediaLength(s string) (Length, error) {
+	l, err := parseLengthValue(s)
+	if err != nil {
+		return Length{}, err
+	}
+	if l.Unit == PercentUnit {
+		return Length{}, fmt.Errorf("invalid length %q", s)
+	}
+	return l, nil
+}
+
+// parseMediaRatio parses a ratio such as 16/9, or a single number.
+func parseMediaRatio(s string) (float64, error) {
+	num, den, hasDen := strings.Cut(s, "/")
+	w, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || w < 0 {
+		return 0, fmt.Errorf("invalid ratio %q", s)
+	}
+	if !hasDen {
+		return w, nil
+	}
+	h, err := strconv.ParseFloat(strings.TrimSpace(den), 64)
+	if err != nil || h <= 0 {
+		return 0, fmt.Errorf("invalid ratio %q", s)
+	}
+	return w / h, nil
+}
@@ -0,0 +1,53 @@
+package layout
+
+import "testing"
+
+func TestMediaQueryMatch(t *testing.T) {
+	landscape := NewLayoutContext(1024, 768, 16)
+	portrait := NewLayoutContext(400, 800, 16).WithPrefersDark(true)
+
+	tests := []struct {
+		query               string
+		landscape, portrait bool
+	}{
+		{"", true, true},
+		{"all", true, true},
+		{"print", false, false},
+		{"not print", true, true},
+		{"screen and (min-width: 600px)", true, false},
+		{"(max-width: 599px)", false, true},
+		{"(min-width: 40em)", true, false},
+		{"(width >= 1024px)", true, false},
+		{"(1024px > width)", false, true},
+		{"(width = calc(1000px + 24px))", true, false},
+		{"(orientation: portrait)", false, true},
+		{"(orientation: landscape) and (min-height: 700px)", true, false},
+		{"(prefers-color-scheme: dark)", false, true},
+		{"not (prefers-color-scheme: dark)", true, false},
+		{"(min-aspect-ratio: 4/3)", true, false},
+		{"(max-width: 500px), (min-width: 1000px)", true, true},
+		{"(width)", true, true},
+	}
+	for _, tt := range tests {
+		q, err := ParseMediaQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseMediaQuery(%q): %v", tt.query, err)
+		}
+		if got := q.Match(landscape); got != tt.landscape {
+			t.Errorf("Expected %q to match landscape %v, got %v", tt.query, tt.landscape, got)
+		}
+		if got := q.Match(portrait); got != tt.portrait {
+			t.Errorf("Expected %q to match portrait %v, got %v", tt.query, tt.portrait, got)
+		}
+	}
+
+	if q, _ := ParseMediaQuery("all"); q.Match(nil) {
+		t.Errorf("Expected a nil context not to match")
+	}
+	for _, bad := range []string{"(min-width)", "(width: wide)", "(hover: hover)", "(orientation > portrait)",
+		"screen (width: 1px)", "(width: 1px) and", "only (width: 1px)", "(width: 1px", "(aspect-ratio: 4/0)"} {
+		if _, err := ParseMediaQuery(bad); err == nil {
+			t.Errorf("Expected ParseMediaQuery(%q) to fail", bad)
+		}
+	}
+}
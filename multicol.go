@@ -0,0 +1,241 @@
+package layout
+
+import "math"
+
+// Multi-column layout
+//
+// A block container with ColumnCount or ColumnWidth set lays its children
+// out in columns instead of a single stack. The number of columns and
+// their width follow from the container's width, ColumnCount, ColumnWidth
+// and ColumnGap. Children are distributed in order, each column filled
+// before the next, and the column height is balanced: it is the smallest
+// height that fits all children into the columns. With an explicit height
+// that is too small, the extra children overflow into additional columns
+// to the right, as in CSS.
+//
+// Children are monolithic: a child is never split between two columns.
+//
+// See: https://www.w3.org/TR/css-multicol-1/
+
+// ColumnGeometry describes the columns of a laid-out multi-column
+// container, for renderers that draw column rules or backgrounds.
+type ColumnGeometry struct {
+	// Columns are the content boxes of the columns, relative to the
+	// container, including overflow columns.
+	Columns []Rect
+
+	// Rules are the column rules: one in the middle of each gap between
+	// two columns that both have content, ColumnRuleWidth wide and as tall
+	// as the columns.
+	Rules []Rect
+}
+
+// isMultiColumn reports whether a block container with style s is a
+// multi-column container.
+func isMultiColumn(s *Style) bool {
+	return s.ColumnCount > 0 || (s.ColumnWidth.Unit != "" && s.ColumnWidth.Value > 0)
+}
+
+// multiColumnCountAndWidth resolves the used column count and width and the
+// column gap for a content box availableWidth wide.
+//
+// Algorithm based on CSS Multi-column Layout Module Level 1:
+// - §3.4: Pseudo-algorithm
+//
+// See: https://www.w3.org/TR/css-multicol-1/#pseudo-algorithm
+func multiColumnCountAndWidth(node *Node, availableWidth float64, ctx *LayoutContext) (count int, width, gap float64) {
+	fontSize := getCurrentFontSize(node, ctx)
+	s := &node.Style
+	gap = fontSize // normal = 1em
+	if s.ColumnGap.Unit != "" {
+		gap = math.Max(0, ResolveLength(s.ColumnGap, ctx, fontSize))
+	}
+
+	count = s.ColumnCount
+	if s.ColumnWidth.Unit != "" && s.ColumnWidth.Value > 0 {
+		columnWidth := ResolveLength(s.ColumnWidth, ctx, fontSize)
+		fit := int(math.Floor((availableWidth + gap) / (columnWidth + gap)))
+		if count == 0 || fit < count {
+			count = fit
+		}
+	}
+	if count < 1 {
+		count = 1
+	}
+	width = math.Max(0, (availableWidth-float64(count-1)*gap)/float64(count))
+	return count, width, gap
+}
+
+// multiColumnItem is a child of a multi-column container.
+type multiColumnItem struct {
+	node                    *Node
+	size                    Size
+	marginTop, marginBottom float64
+	marginLeft, marginRight float64
+	column                  int
+	offset                  float64 // block offset within the column
+}
+
+// multiColumnFill places items greedily into columns of the given height,
+// collapsing adjacent margins and truncating margins at column breaks.
+// It returns the number of columns used, the tallest column, and the
+// smallest increase in height that would keep one more item in its column
+// (0 if everything fit).
+func multiColumnFill(items []*multiColumnItem, height float64) (columns int, used, stretch float64) {
+	const epsilon = 0.001
+	column, pos, prevMarginBottom := 0, 0.0, 0.0
+	started := false
+	for _, item := range items {
+		gapBefore := 0.0
+		if started {
+			gapBefore = math.Max(prevMarginBottom, item.marginTop)
+		}
+		end := pos + gapBefore + item.size.Height
+		if started && end > height+epsilon {
+			if need := end - height; stretch == 0 || need < stretch {
+				stretch = need
+			}
+			column++
+			pos, gapBefore = 0, 0
+			end = item.size.Height
+		}
+		item.column, item.offset = column, pos+gapBefore
+		pos, prevMarginBottom, started = end, item.marginBottom, true
+		used = math.Max(used, pos)
+	}
+	return column + 1, used, stretch
+}
+
+// blockLayoutColumns lays out the children of a multi-column container and
+// returns the balanced column height and the width of all used columns.
+//
+// Algorithm based on CSS Multi-column Layout Module Level 1:
+// - §3: The number and width of columns
+// - §7: Column balancing and overflow
+//
+// See: https://www.w3.org/TR/css-multicol-1/#cf
+func blockLayoutColumns(node *Node, setup blockSetup, nodeWidth float64, ctx *LayoutContext, parentFontSize float64) (currentBlockPos, maxCrossSize float64) {
+	count, columnWidth, gap := multiColumnCountAndWidth(node, nodeWidth, ctx)
+
+	childConstraints := Constraints{MaxWidth: columnWidth, MaxHeight: Unbounded}
+	var items []*multiColumnItem
+	tallest := 0.0
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone {
+			continue
+		}
+		childFontSize := getCurrentFontSize(child, ctx)
+		item := &multiColumnItem{
+			node:         child,
+			marginTop:    ResolveLength(child.Style.Margin.Top, ctx, childFontSize),
+			marginBottom: ResolveLength(child.Style.Margin.Bottom, ctx, childFontSize),
+			marginLeft:   ResolveLength(child.Style.Margin.Left, ctx, childFontSize),
+			marginRight:  ResolveLength(child.Style.Margin.Right, ctx, childFontSize),
+		}
+		item.size = cachedLayout(child, childConstraints, ctx, func() Size {
+			return layoutByDisplay(child, childConstraints, ctx)
+		})
+		tallest = math.Max(tallest, item.size.Height)
+		items = append(items, item)
+	}
+
+	// Balance: start from an even split and grow the column height by the
+	// smallest amount that keeps another item in place until everything
+	// fits in count columns
+	_, total, _ := multiColumnFill(items, math.Inf(1))
+	height := math.Max(tallest, total/float64(count))
+	for {
+		columns, _, stretch := multiColumnFill(items, height)
+		if columns <= count || stretch == 0 {
+			break
+		}
+		height += stretch
+	}
+
+	// An explicit height (or max height) caps the columns; the rest overflows
+	limit := Unbounded
+	if !setup.isAutoHeight {
+		limit = setup.specifiedHeight
+	} else if setup.maxHeightContent > 0 && setup.maxHeightContent < Unbounded {
+		limit = setup.maxHeightContent
+	}
+	height = math.Min(height, limit)
+	columns, used, _ := multiColumnFill(items, height)
+
+	contentLeft := ResolveLength(node.Style.Padding.Left, ctx, parentFontSize) + ResolveLength(node.Style.Border.Left, ctx, parentFontSize)
+	contentTop := ResolveLength(node.Style.Padding.Top, ctx, parentFontSize) + ResolveLength(node.Style.Border.Top, ctx, parentFontSize)
+	for _, item := range items {
+		item.node.Rect = Rect{
+			X:      contentLeft + float64(item.column)*(columnWidth+gap) + item.marginLeft,
+			Y:      contentTop + item.offset,
+			Width:  item.size.Width,
+			Height: item.size.Height,
+		}
+	}
+
+	if columns < count {
+		columns = count
+	}
+	return used, float64(columns)*columnWidth + float64(columns-1)*gap
+}
+
+// MultiColumnGeometry returns the column boxes and column rules of a
+// multi-column container after layout. It returns the zero ColumnGeometry
+// for other nodes.
+//
+// Example:
+//
+//	layout.Layout(article, layout.Loose(80, 40), ctx)
+//	for _, rule := range layout.MultiColumnGeometry(article, ctx).Rules {
+//		drawVerticalLine(rule)
+//	}
+func MultiColumnGeometry(node *Node, ctx *LayoutContext) ColumnGeometry {
+	if node.Style.Display != DisplayBlock || !isMultiColumn(&node.Style) || node.Style.WritingMode.IsVertical() {
+		return ColumnGeometry{}
+	}
+	fontSize := getCurrentFontSize(node, ctx)
+	s := &node.Style
+	left := ResolveLength(s.Padding.Left, ctx, fontSize) + ResolveLength(s.Border.Left, ctx, fontSize)
+	top := ResolveLength(s.Padding.Top, ctx, fontSize) + ResolveLength(s.Border.Top, ctx, fontSize)
+	contentWidth := node.Rect.Width - getHorizontalPaddingBorder(s.Padding, s.Border, ctx, fontSize)
+	contentHeight := math.Max(0, node.Rect.Height-getVerticalPaddingBorder(s.Padding, s.Border, ctx, fontSize))
+
+	count, columnWidth, gap := multiColumnCountAndWidth(node, contentWidth, ctx)
+
+	// Find the columns with content, including overflow columns
+	hasContent := make(map[int]bool)
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone {
+			continue
+		}
+		marginLeft := ResolveLength(child.Style.Margin.Left, ctx, getCurrentFontSize(child, ctx))
+		column := int(math.Floor((child.Rect.X - marginLeft - left + 0.001) / (columnWidth + gap)))
+		hasContent[column] = true
+		if column >= count {
+			count = column + 1
+		}
+	}
+
+	var g ColumnGeometry
+	for i := 0; i < count; i++ {
+		g.Columns = append(g.Columns, Rect{
+			X:      left + float64(i)*(columnWidth+gap),
+			Y:      top,
+			Width:  columnWidth,
+			Height: contentHeight,
+		})
+	}
+	ruleWidth := math.Max(0, ResolveLength(s.ColumnRuleWidth, ctx, fontSize))
+	for i := 0; i+1 < count; i++ {
+		if !hasContent[i] || !hasContent[i+1] {
+			continue
+		}
+		g.Rules = append(g.Rules, Rect{
+			X:      left + float64(i+1)*columnWidth + float64(i)*gap + (gap-ruleWidth)/2,
+			Y:      top,
+			Width:  ruleWidth,
+			Height: contentHeight,
+		})
+	}
+	return g
+}
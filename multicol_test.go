@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// columnBlocks returns n full-width blocks of the given heights
+func columnBlocks(heights ...float64) []*Node {
+	nodes := make([]*Node, len(heights))
+	for i, h := range heights {
+		nodes[i] = &Node{Style: Style{Display: DisplayBlock, Width: Px(-1), Height: Px(h)}}
+	}
+	return nodes
+}
+
+func multiColumnContainer(children ...*Node) *Node {
+	return &Node{
+		Style: Style{
+			Display:     DisplayBlock,
+			Width:       Px(320),
+			Height:      Px(-1),
+			ColumnCount: 3,
+			ColumnGap:   Px(10),
+		},
+		Children: children,
+	}
+}
+
+func TestMultiColumnBalancesHeight(t *testing.T) {
+	root := multiColumnContainer(columnBlocks(20, 20, 20, 20, 20, 20)...)
+
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if root.Rect.Height != 40 {
+		t.Errorf("Expected balanced height 40, got %.2f", root.Rect.Height)
+	}
+	for i, child := range root.Children {
+		wantX := float64(i/2) * 110
+		wantY := float64(i%2) * 20
+		if child.Rect.X != wantX || child.Rect.Y != wantY || child.Rect.Width != 100 {
+			t.Errorf("Child %d: expected 100 wide at (%.0f, %.0f), got %.2f at (%.2f, %.2f)",
+				i, wantX, wantY, child.Rect.Width, child.Rect.X, child.Rect.Y)
+		}
+	}
+}
+
+func TestMultiColumnUnevenChildren(t *testing.T) {
+	// 90 of content in 3 columns: a 50px child forces columns of 50
+	root := multiColumnContainer(columnBlocks(10, 50, 10, 10, 10)...)
+
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if root.Rect.Height != 50 {
+		t.Errorf("Expected height 50, got %.2f", root.Rect.Height)
+	}
+	columns := []int{0, 1, 2, 2, 2}
+	for i, child := range root.Children {
+		if want := float64(columns[i]) * 110; child.Rect.X != want {
+			t.Errorf("Child %d: expected column %d (X=%.0f), got X=%.2f", i, columns[i], want, child.Rect.X)
+		}
+	}
+}
+
+func TestMultiColumnMarginsTruncatedAtBreaks(t *testing.T) {
+	children := columnBlocks(20, 20, 20, 20)
+	for _, c := range children {
+		c.Style.Margin = Spacing{Top: Px(10), Bottom: Px(10)}
+	}
+	root := multiColumnContainer(children...)
+	root.Style.ColumnCount = 2
+
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	// Each column holds two children 10px apart; margins at the top and
+	// bottom of a column are dropped
+	if root.Rect.Height != 50 {
+		t.Errorf("Expected height 50, got %.2f", root.Rect.Height)
+	}
+	if children[2].Rect.Y != 0 || children[3].Rect.Y != 30 {
+		t.Errorf("Expected second column at 0 and 30, got %.2f and %.2f", children[2].Rect.Y, children[3].Rect.Y)
+	}
+}
+
+func TestMultiColumnWidth(t *testing.T) {
+	root := multiColumnContainer(columnBlocks(20, 20)...)
+	root.Style.ColumnCount = 0
+	root.Style.ColumnWidth = Px(70)
+
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	// floor((320 + 10) / (70 + 10)) = 4 columns of (320 - 30) / 4
+	if w := root.Children[0].Rect.Width; math.Abs(w-72.5) > 0.01 {
+		t.Errorf("Expected column width 72.5, got %.2f", w)
+	}
+
+	// ColumnCount caps the number of columns
+	root.Style.ColumnCount = 2
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+	if w := root.Children[0].Rect.Width; w != 155 {
+		t.Errorf("Expected column width 155, got %.2f", w)
+	}
+}
+
+func TestMultiColumnOverflowColumns(t *testing.T) {
+	root := multiColumnContainer(columnBlocks(20, 20, 20, 20, 20, 20, 20, 20)...)
+	root.Style.Height = Px(40)
+
+	Layout(root, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if root.Rect.Height != 40 {
+		t.Errorf("Expected explicit height 40, got %.2f", root.Rect.Height)
+	}
+	if x := root.Children[7].Rect.X; x != 330 {
+		t.Errorf("Expected last child in an overflow column at X=330, got %.2f", x)
+	}
+}
+
+func TestMultiColumnGeometry(t *testing.T) {
+	root := multiColumnContainer(columnBlocks(20, 20, 20, 20)...)
+	root.Style.Padding = Uniform(Px(5))
+	root.Style.ColumnRuleWidth = Px(2)
+	ctx := NewLayoutContext(400, 400, 16)
+
+	Layout(root, Loose(400, 400), ctx)
+	g := MultiColumnGeometry(root, ctx)
+
+	if len(g.Columns) != 3 {
+		t.Fatalf("Expected 3 columns, got %d", len(g.Columns))
+	}
+	if want := (Rect{X: 115, Y: 5, Width: 100, Height: 40}); g.Columns[1] != want {
+		t.Errorf("Expected second column %+v, got %+v", want, g.Columns[1])
+	}
+	// Four children fill two columns, so there is a single rule
+	if len(g.Rules) != 1 {
+		t.Fatalf("Expected 1 rule between the filled columns, got %d", len(g.Rules))
+	}
+	if want := (Rect{X: 109, Y: 5, Width: 2, Height: 40}); g.Rules[0] != want {
+		t.Errorf("Expected rule %+v, got %+v", want, g.Rules[0])
+	}
+
+	if g := MultiColumnGeometry(VStack(), ctx); g.Columns != nil {
+		t.Errorf("Expected no columns for a flex container, got %+v", g)
+	}
+}
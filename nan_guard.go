@@ -0,0 +1,146 @@
+package layout
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// NaNGuard catches the first NaN or infinity in the results of the layouts
+// it's attached to via LayoutContext.NaNGuard, so a non-finite value can be
+// traced to where it entered, rather than to the root it spread to.
+//
+// After each run of a layout algorithm on a node, the guard checks the
+// node's Rect and returned size, and the positions of its children. A
+// node's descendants are checked before the node, so the first node in
+// error is the innermost one that went wrong. The error is recorded, or,
+// with Panic set, the guard panics with it. Checks stop once an error is
+// recorded, until Reset.
+//
+// Example:
+//
+//	guard := &layout.NaNGuard{}
+//	ctx.NaNGuard = guard
+//	layout.Layout(root, constraints, ctx)
+//	if err := guard.Err(); err != nil {
+//	    log.Print(err) // layout: node [0 2] (#sidebar): Rect.Width is NaN
+//	}
+type NaNGuard struct {
+	// Panic makes the guard panic with the *NonFiniteError instead of
+	// recording it.
+	Panic bool
+
+	mu  sync.Mutex
+	err *NonFiniteError
+}
+
+// NonFiniteError is a NaN or infinity caught by a NaNGuard.
+type NonFiniteError struct {
+	// Node is the node whose result wasn't finite, and Path the sequence
+	// of child indexes from the layout's root to it (nil if it isn't in
+	// the root's tree, as for a probe laid out on the side).
+	Node *Node
+	Path []int
+
+	// Property is the result that wasn't finite: Rect.X, Rect.Y,
+	// Rect.Width, Rect.Height, Size.Width or Size.Height.
+	Property string
+	Value    float64
+}
+
+// Error implements the error interface.
+func (e *NonFiniteError) Error() string {
+	node := fmt.Sprint(e.Path)
+	if e.Node.ID != "" {
+		node += " (#" + e.Node.ID + ")"
+	}
+	return fmt.Sprintf("layout: node %s: %s is %v", node, e.Property, e.Value)
+}
+
+// Err returns the first error caught, or nil.
+func (g *NaNGuard) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil {
+		return nil
+	}
+	return g.err
+}
+
+// Reset forgets the error caught, so the guard checks again.
+func (g *NaNGuard) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = nil
+}
+
+// check checks the results of laying out node in the tree of root.
+func (g *NaNGuard) check(node *Node, size Size, root *Node) {
+	g.mu.Lock()
+	caught := g.err != nil
+	g.mu.Unlock()
+	if caught {
+		return
+	}
+
+	var bad *Node
+	var property string
+	var value float64
+	find := func(n *Node, name string, v float64) bool {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			bad, property, value = n, name, v
+			return true
+		}
+		return false
+	}
+	r := node.Rect
+	switch {
+	case find(node, "Rect.Width", r.Width), find(node, "Rect.Height", r.Height),
+		find(node, "Size.Width", size.Width), find(node, "Size.Height", size.Height),
+		find(node, "Rect.X", r.X), find(node, "Rect.Y", r.Y):
+	default:
+		for _, child := range node.Children {
+			if find(child, "Rect.X", child.Rect.X) || find(child, "Rect.Y", child.Rect.Y) {
+				break
+			}
+		}
+	}
+	if bad == nil {
+		return
+	}
+
+	err := &NonFiniteError{Node: bad, Path: indexPathTo(root, bad), Property: property, Value: value}
+	if g.Panic {
+		panic(err)
+	}
+	g.mu.Lock()
+	if g.err == nil {
+		g.err = err
+	}
+	g.mu.Unlock()
+}
+
+// indexPathTo returns the sequence of child indexes from root to n, or nil if n
+// isn't in root's tree.
+func indexPathTo(root, n *Node) []int {
+	var path []int
+	walkTree(root, func(node *Node, p []int) walkStep {
+		if node == n {
+			path = append([]int{}, p...)
+			return walkStop
+		}
+		return walkChildren
+	})
+	return path
+}
+
+// withGuardRoot returns a copy of ctx that knows root is being laid out,
+// if it has a NaNGuard. Otherwise it returns ctx.
+func (ctx *LayoutContext) withGuardRoot(root *Node) *LayoutContext {
+	if ctx == nil || ctx.NaNGuard == nil {
+		return ctx
+	}
+	copy := *ctx
+	copy.guardRoot = root
+	return &copy
+}
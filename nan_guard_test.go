@@ -0,0 +1,70 @@
+package layout
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNaNGuardRecordsFirstNode(t *testing.T) {
+	bad := &Node{ID: "bad", Style: Style{Width: Px(10), Height: Px(10), Padding: Spacing{Top: Px(math.NaN())}}}
+	inner := &Node{Style: Style{Height: Px(-1)}, Children: []*Node{bad}}
+	root := &Node{Style: Style{Height: Px(-1)}, Children: []*Node{Fixed(10, 10), inner}}
+	guard := &NaNGuard{}
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.NaNGuard = guard
+
+	Layout(root, Loose(400, 300), ctx)
+
+	var nonFinite *NonFiniteError
+	if !errors.As(guard.Err(), &nonFinite) {
+		t.Fatalf("Expected a NonFiniteError, got %v", guard.Err())
+	}
+	if nonFinite.Node != bad {
+		t.Errorf("Expected the innermost node to be caught, got path %v", nonFinite.Path)
+	}
+	if len(nonFinite.Path) != 2 || nonFinite.Path[0] != 1 || nonFinite.Path[1] != 0 {
+		t.Errorf("Expected path [1 0], got %v", nonFinite.Path)
+	}
+	if nonFinite.Property != "Rect.Height" || !math.IsNaN(nonFinite.Value) {
+		t.Errorf("Expected Rect.Height to be NaN, got %s = %v", nonFinite.Property, nonFinite.Value)
+	}
+	if !strings.Contains(nonFinite.Error(), "#bad") {
+		t.Errorf("Expected the error to name the node, got %q", nonFinite.Error())
+	}
+
+	guard.Reset()
+	if guard.Err() != nil {
+		t.Error("Expected Reset to forget the error")
+	}
+}
+
+func TestNaNGuardFiniteLayout(t *testing.T) {
+	guard := &NaNGuard{}
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.NaNGuard = guard
+
+	Layout(HStack(Fixed(10, 10), Fixed(20, 20)), Loose(400, 300), ctx)
+
+	if err := guard.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestNaNGuardPanics(t *testing.T) {
+	bad := &Node{Style: Style{Width: Px(10), Height: Px(10), Margin: Spacing{Left: Px(math.NaN())}}}
+	ctx := NewLayoutContext(400, 300, 16)
+	ctx.NaNGuard = &NaNGuard{Panic: true}
+
+	defer func() {
+		err, ok := recover().(*NonFiniteError)
+		if !ok {
+			t.Fatalf("Expected a NonFiniteError panic, got %v", err)
+		}
+		if err.Node != bad || err.Property != "Rect.X" {
+			t.Errorf("Expected the panic on the invalid node's X, got %v", err)
+		}
+	}()
+	Layout(&Node{Style: Style{Height: Px(-1)}, Children: []*Node{bad}}, Loose(400, 300), ctx)
+}
@@ -0,0 +1,234 @@
+package layout
+
+import "fmt"
+
+// Cross-node constraints
+//
+// Constraints tie one node's layout to another node anywhere in the same
+// tree, covering the common "make these two buttons equal width" and "line
+// this label up with that field" cases without a general constraint solver.
+// They are resolved by Layout in a fixup pass after normal layout:
+//
+//  1. Width constraints: each constrained node is pinned to its target's
+//     border-box width and the tree is laid out again, so siblings reflow
+//     around the new size.
+//  2. Baseline constraints: each constrained node is moved vertically so its
+//     baseline lines up with its target's baseline. Descendants move with it.
+//
+// Constraints chain (a matches b, b matches c) and are resolved targets
+// first. Nodes on a cycle, and constraints whose target can't be honored
+// (outside the tree, or a baseline target that moves with the node), are
+// left unconstrained; CheckNodeConstraints reports them.
+
+// MatchWidthOf makes n's border-box width equal to other's after layout.
+// other must be in the same tree as n; otherwise the constraint is ignored.
+// Passing nil removes the constraint. Returns n for chaining.
+//
+// Example:
+//
+//	cancel := layout.Fixed(60, 30)
+//	ok := layout.Fixed(40, 30).MatchWidthOf(cancel)
+func (n *Node) MatchWidthOf(other *Node) *Node {
+	n.matchWidthOf = other
+	return n
+}
+
+// AlignBaselineWith moves n vertically after layout so its baseline lines up
+// with other's. A node's baseline is its Baseline field, or the bottom of its
+// border box when Baseline is 0 (the synthesized baseline of CSS Box
+// Alignment Level 3 §9.1). other must be in the same tree as n and must not
+// be n or one of its descendants, which move with it; otherwise the
+// constraint is ignored. Passing nil removes the constraint. Returns n for
+// chaining.
+func (n *Node) AlignBaselineWith(other *Node) *Node {
+	n.alignBaselineWith = other
+	return n
+}
+
+// CheckNodeConstraints reports an error if any MatchWidthOf or
+// AlignBaselineWith constraints in the tree form a cycle or have a target
+// Layout can't use: one outside the tree, or a baseline target that is the
+// node itself or one of its descendants. Layout ignores such constraints
+// rather than failing.
+func CheckNodeConstraints(root *Node) error {
+	nodes := root.DescendantsAndSelf()
+	parents := treeParents(root, nodes)
+	for _, n := range nodes {
+		if n.matchWidthOf != nil && widthTarget(n, parents) == nil {
+			return fmt.Errorf("layout: MatchWidthOf target is not in the tree")
+		}
+		if target := n.alignBaselineWith; target != nil && baselineTarget(n, parents) == nil {
+			if _, ok := parents[target]; !ok {
+				return fmt.Errorf("layout: AlignBaselineWith target is not in the tree")
+			}
+			return fmt.Errorf("layout: AlignBaselineWith target is the node itself or its descendant")
+		}
+	}
+	if _, cyclic := constraintOrder(nodes, func(n *Node) *Node { return widthTarget(n, parents) }); len(cyclic) > 0 {
+		return fmt.Errorf("layout: MatchWidthOf cycle through %d nodes", len(cyclic))
+	}
+	if _, cyclic := constraintOrder(nodes, func(n *Node) *Node { return baselineTarget(n, parents) }); len(cyclic) > 0 {
+		return fmt.Errorf("layout: AlignBaselineWith cycle through %d nodes", len(cyclic))
+	}
+	return nil
+}
+
+// hasNodeConstraints reports whether any node in the tree has a constraint.
+func hasNodeConstraints(root *Node) bool {
+	if root.matchWidthOf != nil || root.alignBaselineWith != nil {
+		return true
+	}
+	for _, child := range root.Children {
+		if hasNodeConstraints(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveNodeConstraints runs the constraint fixup pass after root has been
+// laid out and returns root's (possibly updated) size.
+func resolveNodeConstraints(root *Node, constraints Constraints, ctx *LayoutContext, size Size) Size {
+	nodes := root.DescendantsAndSelf()
+	parents := treeParents(root, nodes)
+
+	// Width: pin each node to its target's resolved width and lay out again
+	order, _ := constraintOrder(nodes, func(n *Node) *Node { return widthTarget(n, parents) })
+	if len(order) > 0 {
+		widths := make(map[*Node]float64, len(order))
+		for _, n := range order {
+			w := n.matchWidthOf.Rect.Width
+			if resolved, ok := widths[n.matchWidthOf]; ok {
+				w = resolved
+			}
+			widths[n] = w
+		}
+
+		saved := make(map[*Node]Style, len(widths))
+		for n, w := range widths {
+			saved[n] = n.Style
+			n.Style.Width = Px(w)
+			n.Style.BoxSizing = BoxSizingBorderBox
+		}
+		// Descendants changed, so earlier measurements don't apply
+		ctx = ctx.beginMeasurePass()
+		size = cachedLayout(root, constraints, ctx, func() Size {
+			return layoutByDisplay(root, constraints, ctx)
+		})
+		for n, style := range saved {
+			n.Style = style
+		}
+	}
+
+	// Baseline: shift each node so its absolute baseline matches its target's
+	order, _ = constraintOrder(nodes, func(n *Node) *Node { return baselineTarget(n, parents) })
+	if len(order) > 0 {
+		absoluteY := func(n *Node) float64 {
+			y := 0.0
+			for ; n != nil; n = parents[n] {
+				y += n.Rect.Y
+			}
+			return y
+		}
+		for _, n := range order {
+			target := n.alignBaselineWith
+			n.Rect.Y += absoluteY(target) + nodeBaseline(target) - absoluteY(n) - nodeBaseline(n)
+		}
+	}
+
+	return size
+}
+
+// treeParents maps each of nodes (root and its descendants) to its parent,
+// and root to nil.
+func treeParents(root *Node, nodes []*Node) map[*Node]*Node {
+	parents := make(map[*Node]*Node, len(nodes))
+	parents[root] = nil
+	for _, n := range nodes {
+		for _, child := range n.Children {
+			parents[child] = n
+		}
+	}
+	return parents
+}
+
+// widthTarget returns n's MatchWidthOf target, or nil if there is none or
+// it isn't in the tree described by parents.
+func widthTarget(n *Node, parents map[*Node]*Node) *Node {
+	if _, ok := parents[n.matchWidthOf]; !ok {
+		return nil
+	}
+	return n.matchWidthOf
+}
+
+// baselineTarget returns n's AlignBaselineWith target, or nil if there is
+// none, it isn't in the tree described by parents, or it is n or one of its
+// descendants (moving n would move the target too).
+func baselineTarget(n *Node, parents map[*Node]*Node) *Node {
+	target := n.alignBaselineWith
+	if _, ok := parents[target]; !ok {
+		return nil
+	}
+	for m := target; m != nil; m = parents[m] {
+		if m == n {
+			return nil
+		}
+	}
+	return target
+}
+
+// nodeBaseline returns n's baseline offset from the top of its border box.
+func nodeBaseline(n *Node) float64 {
+	if n.Baseline > 0 {
+		return n.Baseline
+	}
+	return n.Rect.Height
+}
+
+// constraintOrder returns the constrained nodes among nodes ordered so every
+// node comes after the node it depends on (via next), plus the nodes that
+// sit on a dependency cycle, which are left out of the order.
+func constraintOrder(nodes []*Node, next func(*Node) *Node) (order, cyclic []*Node) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[*Node]int)
+	onCycle := make(map[*Node]bool)
+
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		state[n] = visiting
+		if target := next(n); target != nil {
+			switch state[target] {
+			case unvisited:
+				visit(target)
+			case visiting:
+				// Walk the cycle from target back round to n
+				for m := target; ; m = next(m) {
+					onCycle[m] = true
+					if m == n {
+						break
+					}
+				}
+			}
+		}
+		state[n] = done
+		if next(n) != nil && !onCycle[n] {
+			order = append(order, n)
+		}
+	}
+
+	for _, n := range nodes {
+		if next(n) != nil && state[n] == unvisited {
+			visit(n)
+		}
+	}
+	for _, n := range nodes {
+		if onCycle[n] {
+			cyclic = append(cyclic, n)
+		}
+	}
+	return order, cyclic
+}
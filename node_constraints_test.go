@@ -0,0 +1,176 @@
+package layout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMatchWidthOfReflowsSiblings(t *testing.T) {
+	cancel := Fixed(90, 30)
+	ok := Fixed(40, 30).MatchWidthOf(cancel)
+	after := Fixed(20, 30)
+	row := HStack(ok, cancel, after)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if math.Abs(ok.Rect.Width-90) > 0.01 {
+		t.Errorf("Expected matched width 90, got %.2f", ok.Rect.Width)
+	}
+	if math.Abs(cancel.Rect.X-90) > 0.01 {
+		t.Errorf("Expected target to reflow to X=90, got %.2f", cancel.Rect.X)
+	}
+	if math.Abs(after.Rect.X-180) > 0.01 {
+		t.Errorf("Expected trailing sibling at X=180, got %.2f", after.Rect.X)
+	}
+	if ok.Style.Width.Value != 40 {
+		t.Errorf("Expected style to be restored after layout, got width %.2f", ok.Style.Width.Value)
+	}
+}
+
+func TestMatchWidthOfBorderBox(t *testing.T) {
+	target := Fixed(100, 30)
+	padded := Fixed(10, 30).MatchWidthOf(target)
+	padded.Style.Padding = Uniform(Px(8))
+	col := VStack(target, padded)
+	col.Style.AlignItems = AlignItemsFlexStart
+
+	Layout(col, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if math.Abs(padded.Rect.Width-100) > 0.01 {
+		t.Errorf("Expected border-box width 100 including padding, got %.2f", padded.Rect.Width)
+	}
+}
+
+func TestMatchWidthOfChain(t *testing.T) {
+	a := Fixed(120, 20)
+	b := Fixed(10, 20).MatchWidthOf(a)
+	c := Fixed(30, 20).MatchWidthOf(b)
+	col := VStack(c, b, a)
+	col.Style.AlignItems = AlignItemsFlexStart
+
+	Layout(col, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	if b.Rect.Width != 120 || c.Rect.Width != 120 {
+		t.Errorf("Expected chained widths 120, got b=%.2f c=%.2f", b.Rect.Width, c.Rect.Width)
+	}
+}
+
+func TestMatchWidthOfCycleIsIgnored(t *testing.T) {
+	a := Fixed(50, 20)
+	b := Fixed(70, 20)
+	a.MatchWidthOf(b)
+	b.MatchWidthOf(a)
+	row := HStack(a, b)
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if a.Rect.Width != 50 || b.Rect.Width != 70 {
+		t.Errorf("Expected cyclic constraints to be ignored, got a=%.2f b=%.2f", a.Rect.Width, b.Rect.Width)
+	}
+	err := CheckNodeConstraints(row)
+	if err == nil || !strings.Contains(err.Error(), "cycle through 2 nodes") {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+
+	b.MatchWidthOf(nil)
+	if err := CheckNodeConstraints(row); err != nil {
+		t.Errorf("Expected no error after removing constraint, got %v", err)
+	}
+}
+
+func TestAlignBaselineWithAcrossContainers(t *testing.T) {
+	// Two columns whose labels start at different heights
+	label := Fixed(80, 20)
+	label.Baseline = 15
+	left := VStack(Fixed(80, 40), label)
+
+	field := Fixed(120, 30)
+	field.Baseline = 22
+	caption := Fixed(120, 10).AlignBaselineWith(field)
+	right := VStack(field, caption)
+
+	label.AlignBaselineWith(field)
+	row := HStack(left, right)
+	row.Style.AlignItems = AlignItemsFlexStart
+
+	Layout(row, Loose(400, 400), NewLayoutContext(400, 400, 16))
+
+	absBaseline := func(n *Node, parents ...*Node) float64 {
+		y := n.Rect.Y + nodeBaseline(n)
+		for _, p := range parents {
+			y += p.Rect.Y
+		}
+		return y
+	}
+	want := absBaseline(field, right, row)
+	if got := absBaseline(label, left, row); math.Abs(got-want) > 0.01 {
+		t.Errorf("Expected label baseline %.2f to match field baseline %.2f", got, want)
+	}
+	// caption has no Baseline, so its bottom edge is used
+	if got := absBaseline(caption, right, row); math.Abs(got-want) > 0.01 {
+		t.Errorf("Expected caption bottom %.2f to match field baseline %.2f", got, want)
+	}
+}
+
+func TestAlignBaselineWithOutsideTreeIsIgnored(t *testing.T) {
+	outside := Fixed(10, 10)
+	outside.Rect.Y = 500
+	n := Fixed(10, 10).AlignBaselineWith(outside)
+	col := VStack(n)
+
+	Layout(col, Loose(100, 100), NewLayoutContext(100, 100, 16))
+
+	if n.Rect.Y != 0 {
+		t.Errorf("Expected constraint to a node outside the tree to be ignored, got Y=%.2f", n.Rect.Y)
+	}
+	err := CheckNodeConstraints(col)
+	if err == nil || !strings.Contains(err.Error(), "AlignBaselineWith target is not in the tree") {
+		t.Errorf("Expected outside-tree error, got %v", err)
+	}
+}
+
+func TestMatchWidthOfOutsideTreeIsIgnored(t *testing.T) {
+	outside := Fixed(150, 10)
+	Layout(outside, Loose(400, 100), NewLayoutContext(400, 100, 16))
+	n := Fixed(40, 20).MatchWidthOf(outside)
+	row := HStack(n, Fixed(20, 20))
+
+	Layout(row, Loose(400, 100), NewLayoutContext(400, 100, 16))
+
+	if n.Rect.Width != 40 {
+		t.Errorf("Expected constraint to a node outside the tree to be ignored, got width %.2f", n.Rect.Width)
+	}
+	err := CheckNodeConstraints(row)
+	if err == nil || !strings.Contains(err.Error(), "MatchWidthOf target is not in the tree") {
+		t.Errorf("Expected outside-tree error, got %v", err)
+	}
+}
+
+func TestAlignBaselineWithDescendantIsIgnored(t *testing.T) {
+	label := Fixed(40, 10)
+	label.Baseline = 8
+	box := VStack(Fixed(40, 30), label)
+	box.AlignBaselineWith(label)
+	col := VStack(Fixed(40, 5), box)
+
+	Layout(col, Loose(100, 100), NewLayoutContext(100, 100, 16))
+
+	if box.Rect.Y != 5 {
+		t.Errorf("Expected constraint to a descendant to be ignored, got Y=%.2f", box.Rect.Y)
+	}
+	err := CheckNodeConstraints(col)
+	if err == nil || !strings.Contains(err.Error(), "node itself or its descendant") {
+		t.Errorf("Expected descendant error, got %v", err)
+	}
+
+	box.AlignBaselineWith(box)
+	Layout(col, Loose(100, 100), NewLayoutContext(100, 100, 16))
+	if box.Rect.Y != 5 {
+		t.Errorf("Expected constraint to itself to be ignored, got Y=%.2f", box.Rect.Y)
+	}
+	err = CheckNodeConstraints(col)
+	if err == nil || !strings.Contains(err.Error(), "node itself or its descendant") {
+		t.Errorf("Expected self error, got %v", err)
+	}
+}
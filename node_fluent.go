@@ -25,16 +25,12 @@ func (n *Node) Descendants() []*Node {
 	// Pre-allocate with rough capacity estimate (assume average of 3 children per node, depth 3)
 	result := make([]*Node, 0, len(n.Children)*3)
 
-	// Recursive helper function
-	var collect func(*Node)
-	collect = func(node *Node) {
-		for _, child := range node.Children {
-			result = append(result, child)
-			collect(child)
+	walkTree(n, func(node *Node, path []int) walkStep {
+		if len(path) > 0 {
+			result = append(result, node)
 		}
-	}
-
-	collect(n)
+		return walkChildren
+	})
 	return result
 }
 
@@ -50,18 +46,10 @@ func (n *Node) DescendantsAndSelf() []*Node {
 	}
 
 	result := make([]*Node, 0, len(n.Children)*3+1)
-	result = append(result, n)
-
-	// Recursive helper function
-	var collect func(*Node)
-	collect = func(node *Node) {
-		for _, child := range node.Children {
-			result = append(result, child)
-			collect(child)
-		}
-	}
-
-	collect(n)
+	walkTree(n, func(node *Node, path []int) walkStep {
+		result = append(result, node)
+		return walkChildren
+	})
 	return result
 }
 
@@ -139,21 +127,15 @@ func (n *Node) Find(predicate func(*Node) bool) *Node {
 	}
 
 	// Depth-first search with early termination
-	var search func(*Node) *Node
-	search = func(node *Node) *Node {
-		for _, child := range node.Children {
-			if predicate(child) {
-				return child
-			}
-			// Recursive search in child's subtree
-			if found := search(child); found != nil {
-				return found
-			}
+	var found *Node
+	walkTree(n, func(node *Node, path []int) walkStep {
+		if len(path) > 0 && predicate(node) {
+			found = node
+			return walkStop
 		}
-		return nil
-	}
-
-	return search(n)
+		return walkChildren
+	})
+	return found
 }
 
 // FindAll returns all nodes in the tree (depth-first) that match the predicate.
@@ -170,19 +152,12 @@ func (n *Node) FindAll(predicate func(*Node) bool) []*Node {
 	}
 
 	result := make([]*Node, 0, 10)
-
-	// Recursive collection
-	var collect func(*Node)
-	collect = func(node *Node) {
-		for _, child := range node.Children {
-			if predicate(child) {
-				result = append(result, child)
-			}
-			collect(child)
+	walkTree(n, func(node *Node, path []int) walkStep {
+		if len(path) > 0 && predicate(node) {
+			result = append(result, node)
 		}
-	}
-
-	collect(n)
+		return walkChildren
+	})
 	return result
 }
 
@@ -212,20 +187,7 @@ func (n *Node) Any(predicate func(*Node) bool) bool {
 	}
 
 	// Early termination on first match
-	var search func(*Node) bool
-	search = func(node *Node) bool {
-		for _, child := range node.Children {
-			if predicate(child) {
-				return true
-			}
-			if search(child) {
-				return true
-			}
-		}
-		return false
-	}
-
-	return search(n)
+	return n.Find(predicate) != nil
 }
 
 // All returns true if all descendant nodes match the predicate.
@@ -242,20 +204,7 @@ func (n *Node) All(predicate func(*Node) bool) bool {
 	}
 
 	// Early termination on first non-match
-	var check func(*Node) bool
-	check = func(node *Node) bool {
-		for _, child := range node.Children {
-			if !predicate(child) {
-				return false
-			}
-			if !check(child) {
-				return false
-			}
-		}
-		return true
-	}
-
-	return check(n)
+	return n.Find(func(node *Node) bool { return !predicate(node) }) == nil
 }
 
 // OfDisplayType returns all descendants with the specified display type.
@@ -305,17 +254,27 @@ func (n *Node) CloneDeep() *Node {
 	}
 
 	// Shallow copy first
-	copy := *n
-
-	// Deep copy children
-	if len(n.Children) > 0 {
-		copy.Children = make([]*Node, len(n.Children))
-		for i, child := range n.Children {
-			copy.Children[i] = child.CloneDeep()
+	root := *n
+
+	// Deep copy children, with a stack of copies whose children are still
+	// the originals
+	pending := []*Node{&root}
+	for len(pending) > 0 {
+		copy := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if len(copy.Children) == 0 {
+			continue
+		}
+		children := make([]*Node, len(copy.Children))
+		for i, child := range copy.Children {
+			childCopy := *child
+			children[i] = &childCopy
+			pending = append(pending, &childCopy)
 		}
+		copy.Children = children
 	}
 
-	return &copy
+	return &root
 }
 
 // =============================================================================
@@ -455,6 +414,21 @@ func (n *Node) WithText(text string) *Node {
 	return copy
 }
 
+// WithUserData returns a new node with the specified UserData.
+// The original node is unchanged.
+//
+// Example:
+//
+//	buttonNode := node.WithUserData(button)
+func (n *Node) WithUserData(data any) *Node {
+	if n == nil {
+		return nil
+	}
+	copy := n.Clone()
+	copy.UserData = data
+	return copy
+}
+
 // WithDisplay returns a new node with the specified display mode.
 // The original node is unchanged.
 //
@@ -634,8 +608,15 @@ func (n *Node) InsertChildAt(index int, child *Node) *Node {
 // =============================================================================
 
 // Transform returns a new tree with nodes selectively transformed.
-// Walks the tree and applies the transform function to nodes matching the predicate.
-// Returns a deep copy with transformations applied.
+// Walks the tree and applies the transform function to a copy of each node
+// matching the predicate. The original tree is unchanged.
+//
+// Only the matching nodes and their ancestors are copied: subtrees without
+// a match are shared between the original and the result, so a small edit
+// to a large tree costs little memory, and a tree without matches is
+// returned as is. Shared nodes are the same *Node in both trees, so
+// changing one in place, or laying out either tree (which sets their
+// Rects), affects both; use CloneDeep for independent trees.
 //
 // Example:
 //
@@ -653,27 +634,13 @@ func (n *Node) Transform(predicate func(*Node) bool, transform func(*Node) *Node
 		return n
 	}
 
-	// Create a copy of this node
-	result := n.Clone()
-
-	// Apply transformation if predicate matches
-	if predicate(n) {
-		result = transform(result)
-	}
-
-	// Recursively transform children
-	if len(n.Children) > 0 {
-		result.Children = make([]*Node, len(n.Children))
-		for i, child := range n.Children {
-			result.Children[i] = child.Transform(predicate, transform)
-		}
-	}
-
-	return result
+	return transformTree(n, predicate, transform)
 }
 
 // Map returns a new tree with the transform function applied to all nodes.
-// This is equivalent to Transform with a predicate that always returns true.
+// This is equivalent to Transform with a predicate that always returns true,
+// so every node is copied. Use Transform to share the nodes that don't
+// change.
 //
 // Example:
 //
@@ -688,18 +655,61 @@ func (n *Node) Map(transform func(*Node) *Node) *Node {
 		return n
 	}
 
-	// Apply transformation to this node
-	result := transform(n.Clone())
+	return transformTree(n, func(*Node) bool { return true }, transform)
+}
+
+// transformTree returns n's tree with transform applied to copies of the
+// nodes matching predicate, path-copying their ancestors and sharing the
+// other subtrees. A transformed node's children are replaced by the results
+// for the original's children, unless the original has none.
+func transformTree(n *Node, predicate func(*Node) bool, transform func(*Node) *Node) *Node {
+	type frame struct {
+		node    *Node
+		matched bool
+		next    int     // Index of the next child to visit
+		results []*Node // Results for the children, once one differs
+	}
+	stack := []*frame{{node: n, matched: predicate(n)}}
+	for {
+		f := stack[len(stack)-1]
+		if f.next < len(f.node.Children) {
+			child := f.node.Children[f.next]
+			f.next++
+			stack = append(stack, &frame{node: child, matched: predicate(child)})
+			continue
+		}
 
-	// Recursively map children
-	if len(n.Children) > 0 {
-		result.Children = make([]*Node, len(n.Children))
-		for i, child := range n.Children {
-			result.Children[i] = child.Map(transform)
+		// All the children are done; copy the node if it or one of them
+		// changed, with children of its own
+		stack = stack[:len(stack)-1]
+		result := f.node
+		if f.matched || f.results != nil {
+			children := f.results
+			if children == nil && len(f.node.Children) > 0 {
+				children = append([]*Node(nil), f.node.Children...)
+			}
+			result = f.node.Clone()
+			if f.matched {
+				result = transform(result)
+			}
+			if children != nil {
+				result.Children = children
+			}
+		}
+		if len(stack) == 0 {
+			return result
 		}
-	}
 
-	return result
+		parent := stack[len(stack)-1]
+		i := parent.next - 1
+		if parent.results == nil && result != f.node {
+			parent.results = make([]*Node, len(parent.node.Children))
+			copy(parent.results, parent.node.Children[:i])
+		}
+		if parent.results != nil {
+			parent.results[i] = result
+		}
+	}
 }
 
 // Filter returns a new tree with only nodes matching the predicate.
@@ -751,24 +761,36 @@ func (n *Node) FilterDeep(predicate func(*Node) bool) *Node {
 		return n
 	}
 
-	// Create a copy of this node
-	result := n.Clone()
+	// Copy the tree breadth-first, so each node's children come after it
+	type entry struct {
+		node, result *Node
+		firstChild   int
+	}
+	entries := []entry{{node: n, result: n.Clone()}}
+	for i := 0; i < len(entries); i++ {
+		entries[i].firstChild = len(entries)
+		for _, child := range entries[i].node.Children {
+			entries = append(entries, entry{node: child, result: child.Clone()})
+		}
+	}
 
-	// Recursively filter children
-	if len(n.Children) > 0 {
-		filtered := make([]*Node, 0, len(n.Children))
-		for _, child := range n.Children {
-			// First recursively filter the child's subtree
-			filteredChild := child.FilterDeep(predicate)
-			// Then check if the child itself matches
-			if predicate(filteredChild) {
-				filtered = append(filtered, filteredChild)
+	// Then filter from the bottom up: a child's subtree is filtered before
+	// the child itself is checked
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if len(e.node.Children) == 0 {
+			continue
+		}
+		filtered := make([]*Node, 0, len(e.node.Children))
+		for _, child := range entries[e.firstChild : e.firstChild+len(e.node.Children)] {
+			if predicate(child.result) {
+				filtered = append(filtered, child.result)
 			}
 		}
-		result.Children = filtered
+		e.result.Children = filtered
 	}
 
-	return result
+	return entries[0].result
 }
 
 // Fold reduces the tree to a single value by accumulating over all nodes.
@@ -790,14 +812,11 @@ func (n *Node) Fold(initial interface{}, fn func(acc interface{}, node *Node) in
 		return initial
 	}
 
-	// Apply function to this node
-	acc := fn(initial, n)
-
-	// Recursively fold children
-	for _, child := range n.Children {
-		acc = child.Fold(acc, fn)
-	}
-
+	acc := initial
+	walkTree(n, func(node *Node, path []int) walkStep {
+		acc = fn(acc, node)
+		return walkChildren
+	})
 	return acc
 }
 
@@ -820,18 +839,123 @@ func (n *Node) FoldWithContext(initial interface{}, fn func(acc interface{}, nod
 		return initial
 	}
 
-	var foldHelper func(*Node, interface{}, int) interface{}
-	foldHelper = func(node *Node, acc interface{}, depth int) interface{} {
-		// Apply function to this node
-		acc = fn(acc, node, depth)
+	acc := initial
+	walkTree(n, func(node *Node, path []int) walkStep {
+		acc = fn(acc, node, len(path))
+		return walkChildren
+	})
+	return acc
+}
 
-		// Recursively fold children
-		for _, child := range node.Children {
-			acc = foldHelper(child, acc, depth+1)
-		}
+// FoldNodes reduces the tree to a single value by accumulating over all
+// nodes in depth-first order, like Fold, but with a typed accumulator.
+//
+// Example:
+//
+//	// Sum all widths in the tree
+//	totalWidth := FoldNodes(root, 0.0, func(acc float64, n *Node) float64 {
+//	    return acc + n.Style.Width.Value
+//	})
+func FoldNodes[T any](n *Node, initial T, fn func(acc T, node *Node) T) T {
+	if n == nil || fn == nil {
+		return initial
+	}
+
+	acc := initial
+	walkTree(n, func(node *Node, path []int) walkStep {
+		acc = fn(acc, node)
+		return walkChildren
+	})
+	return acc
+}
+
+// FoldNodesWithDepth is FoldNodes with the depth of each node, like
+// FoldWithContext.
+//
+// Example:
+//
+//	// Count nodes per depth
+//	depthCounts := FoldNodesWithDepth(root, map[int]int{}, func(m map[int]int, n *Node, depth int) map[int]int {
+//	    m[depth]++
+//	    return m
+//	})
+func FoldNodesWithDepth[T any](n *Node, initial T, fn func(acc T, node *Node, depth int) T) T {
+	if n == nil || fn == nil {
+		return initial
+	}
+
+	acc := initial
+	walkTree(n, func(node *Node, path []int) walkStep {
+		acc = fn(acc, node, len(path))
+		return walkChildren
+	})
+	return acc
+}
 
-		return acc
+// MapNodes returns fn applied to each node of the tree, in depth-first
+// order. Unlike Map, which builds a new tree, it collects values of any
+// type.
+//
+// Example:
+//
+//	// Collect the IDs of all nodes
+//	ids := MapNodes(root, func(n *Node) string {
+//	    return n.ID
+//	})
+func MapNodes[T any](n *Node, fn func(*Node) T) []T {
+	if n == nil || fn == nil {
+		return nil
 	}
 
-	return foldHelper(n, initial, 0)
+	result := make([]T, 0, len(n.Children)*3+1)
+	walkTree(n, func(node *Node, path []int) walkStep {
+		result = append(result, fn(node))
+		return walkChildren
+	})
+	return result
+}
+
+// UserDataOf returns n's UserData as a T, and false if n is nil or its
+// UserData isn't a T.
+//
+// Example:
+//
+//	hit := root.Find(func(n *Node) bool { return PointInNode(root, n, x, y) })
+//	if button, ok := UserDataOf[*Button](hit); ok {
+//	    button.Click()
+//	}
+func UserDataOf[T any](n *Node) (T, bool) {
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	data, ok := n.UserData.(T)
+	return data, ok
+}
+
+// FindUserData returns the UserData of the first node in depth-first order,
+// including n itself, whose UserData is a T and satisfies the predicate,
+// along with the node. A nil predicate matches any T.
+//
+// Example:
+//
+//	// Find the node laying out a particular model
+//	item, node, ok := FindUserData(root, func(item *Item) bool {
+//	    return item.ID == selectedID
+//	})
+func FindUserData[T any](n *Node, predicate func(T) bool) (T, *Node, bool) {
+	var found T
+	var foundNode *Node
+	if n == nil {
+		return found, nil, false
+	}
+	walkTree(n, func(node *Node, path []int) walkStep {
+		data, ok := node.UserData.(T)
+		if ok && (predicate == nil || predicate(data)) {
+			found, foundNode = data, node
+			return walkStop
+		}
+		return walkChildren
+	})
+	return found, foundNode, foundNode != nil
 }
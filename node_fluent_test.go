@@ -1071,6 +1071,39 @@ func TestPerformanceDescendants(t *testing.T) {
 	}
 }
 
+func TestUserData(t *testing.T) {
+	type widget struct{ name string }
+	ok, cancel := &widget{"ok"}, &widget{"cancel"}
+	okNode := Fixed(80, 30).WithUserData(ok)
+	root := HStack(Fixed(10, 30).WithUserData("spacer"), okNode, Fixed(80, 30).WithUserData(cancel))
+
+	if got, found := UserDataOf[*widget](okNode); !found || got != ok {
+		t.Errorf("Expected the ok widget, got %v", got)
+	}
+	if _, found := UserDataOf[*widget](root.Children[0]); found {
+		t.Error("Expected UserDataOf to reject data of another type")
+	}
+	if _, found := UserDataOf[*widget](nil); found {
+		t.Error("Expected no data for a nil node")
+	}
+
+	got, node, found := FindUserData(root, func(w *widget) bool { return w.name == "cancel" })
+	if !found || got != cancel || node != root.Children[2] {
+		t.Errorf("Expected to find the cancel widget's node, got %v", got)
+	}
+	if got, _, _ := FindUserData[*widget](root, nil); got != ok {
+		t.Errorf("Expected the first widget with a nil predicate, got %v", got)
+	}
+	if _, _, found := FindUserData(root, func(w *widget) bool { return w.name == "help" }); found {
+		t.Error("Expected no match")
+	}
+
+	clone := root.CloneDeep()
+	if data, _ := UserDataOf[*widget](clone.Children[1]); data != ok {
+		t.Error("Expected CloneDeep to keep the same UserData")
+	}
+}
+
 func BenchmarkDescendants(b *testing.B) {
 	root := createLargeTree(4) // 3^1 + 3^2 + 3^3 + 3^4 = 120 nodes
 
@@ -1189,6 +1222,43 @@ func TestTransformOriginalUnchanged(t *testing.T) {
 	}
 }
 
+func TestTransformSharesUnchangedSubtrees(t *testing.T) {
+	target := Fixed(10, 10)
+	target.ID = "target"
+	left := VStack(Fixed(10, 10), VStack(target, Fixed(10, 10)))
+	right := VStack(Fixed(20, 20), Fixed(20, 20))
+	root := HStack(left, right)
+
+	result := root.Transform(
+		func(n *Node) bool { return n.ID == "target" },
+		func(n *Node) *Node { return n.WithWidth(50) },
+	)
+
+	if result == root || result.Children[0] == left || result.Children[0].Children[1] == left.Children[1] {
+		t.Error("Expected the target's ancestors to be copied")
+	}
+	if result.Children[1] != right || result.Children[0].Children[0] != left.Children[0] {
+		t.Error("Expected subtrees without a match to be shared")
+	}
+	inner := result.Children[0].Children[1]
+	if inner.Children[0].Style.Width.Value != 50 || inner.Children[1] != left.Children[1].Children[1] {
+		t.Errorf("Expected only the target to change, got width %.2f", inner.Children[0].Style.Width.Value)
+	}
+	if target.Style.Width.Value != 10 || len(left.Children[1].Children) != 2 || left.Children[1].Children[0] != target {
+		t.Error("Expected the original tree to be unchanged")
+	}
+
+	// Copied nodes get children of their own
+	result.Children = append(result.Children[:1], Fixed(1, 1))
+	if root.Children[1] != right {
+		t.Error("Expected the original's children not to be aliased")
+	}
+
+	if same := root.Transform(func(n *Node) bool { return false }, func(n *Node) *Node { return n }); same != root {
+		t.Error("Expected a tree without matches to be returned as is")
+	}
+}
+
 func TestMap(t *testing.T) {
 	root := createTestTree()
 
@@ -1532,6 +1602,58 @@ func TestFoldWithContext(t *testing.T) {
 	})
 }
 
+func TestFoldNodes(t *testing.T) {
+	root := createTestTree()
+
+	t.Run("sum all widths", func(t *testing.T) {
+		totalWidth := FoldNodes(root, 0.0, func(acc float64, n *Node) float64 {
+			return acc + n.Style.Width.Value
+		})
+
+		if totalWidth != 560 {
+			t.Errorf("Expected total width 560, got %.2f", totalWidth)
+		}
+	})
+
+	t.Run("count nodes at each depth", func(t *testing.T) {
+		depthMap := FoldNodesWithDepth(root, map[int]int{}, func(m map[int]int, n *Node, depth int) map[int]int {
+			m[depth]++
+			return m
+		})
+
+		if depthMap[0] != 1 || depthMap[1] != 3 || depthMap[2] != 2 {
+			t.Errorf("Expected 1, 3 and 2 nodes per depth, got %v", depthMap)
+		}
+	})
+
+	t.Run("nil node returns initial", func(t *testing.T) {
+		if got := FoldNodes(nil, 42, func(acc int, n *Node) int { return acc + 1 }); got != 42 {
+			t.Errorf("Expected 42, got %d", got)
+		}
+	})
+}
+
+func TestMapNodes(t *testing.T) {
+	root := createTestTree()
+
+	widths := MapNodes(root, func(n *Node) float64 { return n.Style.Width.Value })
+
+	// Depth-first order: root, child1, grandchild1, grandchild2, child2, child3
+	expected := []float64{0, 100, 50, 60, 200, 150}
+	if len(widths) != len(expected) {
+		t.Fatalf("Expected %d widths, got %d", len(expected), len(widths))
+	}
+	for i, w := range expected {
+		if widths[i] != w {
+			t.Errorf("Expected width %.0f at %d, got %.0f", w, i, widths[i])
+		}
+	}
+
+	if MapNodes[int](nil, func(n *Node) int { return 0 }) != nil {
+		t.Error("Expected nil for nil node")
+	}
+}
+
 // =============================================================================
 // Performance Tests for Transformations
 // =============================================================================
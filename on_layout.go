@@ -0,0 +1,73 @@
+package layout
+
+import "sync/atomic"
+
+// layoutCallbacks counts the calls to OnLayout, so layouts of trees
+// without callbacks, the common case, don't look for them.
+var layoutCallbacks atomic.Int64
+
+// OnLayout adds a function that Layout and LayoutWithPositioning call with
+// n once they're done, when n's Rect is final, so state kept outside the
+// tree (scroll positions, cached glyph runs) can follow the layout without
+// walking the tree again. Passing nil removes n's callbacks. Returns n for
+// chaining.
+//
+// Callbacks run after the whole tree is laid out, each node's after those
+// of its descendants, in tree order otherwise. They don't run for nodes
+// that weren't laid out: with display: none, nested deeper than
+// LayoutContext.MaxDepth, or after LayoutWithContext was canceled.
+//
+// Example:
+//
+//	pane.OnLayout(func(n *layout.Node) {
+//	    scrollbar.SetRange(n.MaxScroll(ctx))
+//	})
+func (n *Node) OnLayout(fn func(*Node)) *Node {
+	if fn == nil {
+		n.onLayout = nil
+		return n
+	}
+	n.onLayout = append(n.onLayout, fn)
+	layoutCallbacks.Add(1)
+	return n
+}
+
+// runLayoutCallbacks calls the OnLayout callbacks of the nodes of root's
+// tree that were laid out with ctx.
+func runLayoutCallbacks(root *Node, ctx *LayoutContext) {
+	if layoutCallbacks.Load() == 0 || ctx.canceled() {
+		return
+	}
+	maxDepth := ctx.maxDepth()
+
+	// Walk the tree visiting later children first; backwards, that's each
+	// node after its descendants, in tree order
+	var nodes []*Node
+	type frame struct {
+		node  *Node
+		depth int
+	}
+	stack := []frame{{root, 0}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if f.node.Style.Display == DisplayNone {
+			continue
+		}
+		if f.node.onLayout != nil {
+			nodes = append(nodes, f.node)
+		}
+		if maxDepth >= 0 && f.depth >= maxDepth {
+			continue
+		}
+		for _, child := range f.node.Children {
+			stack = append(stack, frame{child, f.depth + 1})
+		}
+	}
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		for _, fn := range n.onLayout {
+			fn(n)
+		}
+	}
+}
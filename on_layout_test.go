@@ -0,0 +1,65 @@
+package layout
+
+import "testing"
+
+func TestOnLayoutRunsAfterLayout(t *testing.T) {
+	a, b := Fixed(100, 50), Fixed(80, 40)
+	hidden := Fixed(10, 10)
+	hidden.Style.Display = DisplayNone
+	column := VStack(a, b, hidden)
+	root := VStack(Fixed(10, 10), column)
+
+	var order []*Node
+	var bY float64
+	record := func(n *Node) { order = append(order, n) }
+	column.OnLayout(record)
+	a.OnLayout(record)
+	b.OnLayout(record).OnLayout(func(n *Node) { bY = n.Rect.Y })
+	hidden.OnLayout(record)
+
+	Layout(root, Loose(400, 300), NewLayoutContext(400, 300, 16))
+
+	want := []*Node{a, b, column}
+	if len(order) != len(want) {
+		t.Fatalf("Expected %d callbacks, got %d", len(want), len(order))
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("Expected callback %d in post-order", i)
+		}
+	}
+	if bY != 50 {
+		t.Errorf("Expected the final rect in the callback, got Y=%.2f", bY)
+	}
+
+	// A nil context calls them too, and nil removes them
+	order = nil
+	a.OnLayout(nil)
+	Layout(root, Loose(400, 300), nil)
+	if len(order) != 2 || order[0] != b {
+		t.Errorf("Expected the callbacks of b and the column, got %d", len(order))
+	}
+}
+
+func TestOnLayoutWithPositioning(t *testing.T) {
+	abs := Fixed(20, 20)
+	abs.Style.Position = PositionAbsolute
+	abs.Style.Left = Px(30)
+	root := &Node{Style: Style{Width: Px(200), Height: Px(200)}, Children: []*Node{abs}}
+
+	calls := 0
+	var x float64
+	abs.OnLayout(func(n *Node) {
+		calls++
+		x = n.Rect.X
+	})
+
+	LayoutWithPositioning(root, Loose(400, 300), Rect{Width: 400, Height: 300}, NewLayoutContext(400, 300, 16))
+
+	if calls != 1 {
+		t.Errorf("Expected one call, got %d", calls)
+	}
+	if x != 30 {
+		t.Errorf("Expected the positioned X of 30, got %.2f", x)
+	}
+}
@@ -0,0 +1,117 @@
+package layout
+
+import "math"
+
+// Overflow and scroll containers.
+//
+// A node whose Style.Overflow isn't OverflowVisible is a scroll container:
+// its descendants are clipped to its padding box (the scrollport) and
+// drawn shifted by Node.Scroll. Layout is unaffected by scrolling, so
+// descendants' Rects stay where layout put them, in the coordinates of the
+// unscrolled content; renderers subtract the container's Scroll when they
+// add up a descendant's position.
+//
+// See: https://www.w3.org/TR/css-overflow-3/
+
+// IsScrollContainer reports whether n clips and scrolls its content.
+func (n *Node) IsScrollContainer() bool {
+	return n.Style.Overflow != OverflowVisible
+}
+
+// OverflowClip returns the rect n clips its descendants to, its padding
+// box, relative to n.Rect's origin. It returns false if n doesn't clip.
+func (n *Node) OverflowClip(ctx *LayoutContext) (Rect, bool) {
+	if !n.IsScrollContainer() {
+		return Rect{}, false
+	}
+	return n.paddingBox(ctx), true
+}
+
+// ScrollSize returns the size of n's scrollable overflow area: its padding
+// box, grown to the right and bottom edges of its descendants and their
+// text, plus n's right and bottom padding. Content overflowing the left
+// and top can't be scrolled to. Descendants that are scroll containers
+// count with their border box only.
+//
+// See: https://www.w3.org/TR/css-overflow-3/#scrollable
+func (n *Node) ScrollSize(ctx *LayoutContext) Size {
+	port := n.paddingBox(ctx)
+	content := contentBoxOf(n, ctx)
+	padRight := port.X + port.Width - (content.X + content.Width)
+	padBottom := port.Y + port.Height - (content.Y + content.Height)
+
+	right, bottom := port.X+port.Width, port.Y+port.Height
+	r, b := contentExtent(n, 0, 0)
+	right = math.Max(right, r+padRight)
+	bottom = math.Max(bottom, b+padBottom)
+	return Size{Width: right - port.X, Height: bottom - port.Y}
+}
+
+// MaxScroll returns the furthest n's content can be scrolled: how much
+// its scrollable overflow area exceeds its padding box.
+func (n *Node) MaxScroll(ctx *LayoutContext) Point {
+	port := n.paddingBox(ctx)
+	size := n.ScrollSize(ctx)
+	return Point{X: math.Max(0, size.Width-port.Width), Y: math.Max(0, size.Height-port.Height)}
+}
+
+// ScrollTo scrolls n's content to (x, y), clamped between 0 and MaxScroll,
+// and returns the new scroll position. Nodes that aren't scroll containers
+// don't scroll.
+//
+// Example:
+//
+//	pane.ScrollTo(0, pane.Scroll.Y+float64(rows), ctx) // Page down
+func (n *Node) ScrollTo(x, y float64, ctx *LayoutContext) Point {
+	if !n.IsScrollContainer() {
+		n.Scroll = Point{}
+		return n.Scroll
+	}
+	limit := n.MaxScroll(ctx)
+	n.Scroll = Point{X: math.Max(0, math.Min(x, limit.X)), Y: math.Max(0, math.Min(y, limit.Y))}
+	return n.Scroll
+}
+
+// paddingBox returns n's padding box, inside its scrollbar gutters,
+// relative to n.Rect's origin.
+func (n *Node) paddingBox(ctx *LayoutContext) Rect {
+	fontSize := getCurrentFontSize(n, ctx)
+	left := ResolveLength(n.Style.Border.Left, ctx, fontSize) + n.gutters.left
+	top := ResolveLength(n.Style.Border.Top, ctx, fontSize)
+	right := ResolveLength(n.Style.Border.Right, ctx, fontSize) + n.gutters.right
+	bottom := ResolveLength(n.Style.Border.Bottom, ctx, fontSize) + n.gutters.bottom
+	return Rect{X: left, Y: top, Width: n.Rect.Width - left - right, Height: n.Rect.Height - top - bottom}
+}
+
+// contentExtent returns the right and bottom edges of node's text and
+// descendants, with node's origin at (x, y). They're (x, y) without
+// content.
+func contentExtent(node *Node, x, y float64) (right, bottom float64) {
+	right, bottom = x, y
+	if tl := node.TextLayout; tl != nil {
+		vertical := tl.Style.WritingMode.IsVertical()
+		for i := range tl.Lines {
+			line := &tl.Lines[i]
+			r := x + tl.ContentX + line.OffsetX + line.Width
+			b := y + tl.ContentY + line.OffsetY + tl.lineHeight(i)
+			if vertical {
+				r = x + tl.ContentX + line.OffsetX + tl.lineHeight(i)
+				b = y + tl.ContentY + line.OffsetY + line.Width
+			}
+			right, bottom = math.Max(right, r), math.Max(bottom, b)
+		}
+	}
+	for _, child := range node.Children {
+		if child.Style.Display == DisplayNone || child.Style.Position == PositionFixed {
+			continue
+		}
+		cx, cy := x+child.Rect.X, y+child.Rect.Y
+		right = math.Max(right, cx+child.Rect.Width)
+		bottom = math.Max(bottom, cy+child.Rect.Height)
+		if !child.IsScrollContainer() {
+			r, b := contentExtent(child, cx, cy)
+			right, bottom = math.Max(right, r), math.Max(bottom, b)
+		}
+	}
+	return right, bottom
+}
@@ -0,0 +1,104 @@
+package layout
+
+import "testing"
+
+func TestScrollSize(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	ctx := NewLayoutContext(800, 600, 16)
+	inner := &Node{
+		Style:    Style{Height: Px(50), Overflow: OverflowHidden},
+		Children: []*Node{{Style: Style{Width: Px(500), Height: Px(500)}}},
+	}
+	pane := &Node{
+		Style: Style{
+			Width:    Px(200),
+			Height:   Px(100),
+			Padding:  Uniform(Px(10)),
+			Border:   Uniform(Px(2)),
+			Overflow: OverflowScroll,
+		},
+		Children: []*Node{
+			// A 300px line that doesn't wrap
+			Text("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Style{TextStyle: &TextStyle{FontSize: 10, LineHeight: 15, WhiteSpace: WhiteSpaceNowrap}}),
+			{Style: Style{Height: Px(150)}},
+			inner,
+		},
+	}
+	Layout(pane, Loose(800, 600), ctx)
+
+	// The padding box is 220x120, from (2, 2). The content reaches 12+300
+	// right and 12+15+150+50 down, plus 10px of padding; the nested scroll
+	// container counts with its own box only
+	if got, want := pane.ScrollSize(ctx), (Size{Width: 320, Height: 235}); got != want {
+		t.Errorf("Expected scroll size %+v, got %+v", want, got)
+	}
+	if got, want := pane.MaxScroll(ctx), (Point{X: 100, Y: 115}); got != want {
+		t.Errorf("Expected max scroll %+v, got %+v", want, got)
+	}
+	if got, want := pane.ScrollTo(-5, 60, ctx), (Point{X: 0, Y: 60}); got != want {
+		t.Errorf("Expected to scroll to %+v, got %+v", want, got)
+	}
+
+	clip, ok := pane.OverflowClip(ctx)
+	if want := (Rect{X: 2, Y: 2, Width: 220, Height: 120}); !ok || clip != want {
+		t.Errorf("Expected clip %+v, got %+v (%v)", want, clip, ok)
+	}
+
+	visible := &Node{Style: Style{Width: Px(10), Height: Px(10)}}
+	Layout(visible, Loose(100, 100), ctx)
+	if _, ok := visible.OverflowClip(ctx); ok {
+		t.Error("Expected no clip for overflow: visible")
+	}
+	if got := visible.ScrollTo(5, 5, ctx); got != (Point{}) {
+		t.Errorf("Expected overflow: visible not to scroll, got %+v", got)
+	}
+}
+
+func TestScrollContainerFlexMinimum(t *testing.T) {
+	// A scroll container has no automatic minimum size, so it shrinks to
+	// its flex container and scrolls its content instead
+	pane := &Node{
+		Style:    Style{FlexGrow: 1, FlexShrink: 1, Overflow: OverflowAuto},
+		Children: []*Node{{Style: Style{Width: Px(50), Height: Px(400)}}},
+	}
+	root := &Node{
+		Style:    Style{Display: DisplayFlex, FlexDirection: FlexDirectionColumn, Width: Px(100), Height: Px(100)},
+		Children: []*Node{pane},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	Layout(root, Loose(800, 600), ctx)
+
+	if pane.Rect.Height != 100 {
+		t.Errorf("Expected the pane to be 100 tall, got %v", pane.Rect.Height)
+	}
+	if got := pane.MaxScroll(ctx).Y; got != 300 {
+		t.Errorf("Expected to scroll by up to 300, got %v", got)
+	}
+}
+
+func TestStickyInScrollContainer(t *testing.T) {
+	header := &Node{Style: Style{Position: PositionSticky, Top: Px(0), Height: Px(20)}}
+	pane := &Node{
+		Style:    Style{Height: Px(100), Overflow: OverflowScroll},
+		Children: []*Node{header, {Style: Style{Height: Px(300)}}},
+	}
+	root := &Node{
+		Style:    Style{Width: Px(200), Height: Px(400)},
+		Children: []*Node{{Style: Style{Height: Px(50)}}, pane},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutWithPositioning(root, Loose(800, 600), root.Rect, ctx)
+
+	// The header sticks to the pane, not the root
+	UpdateSticky(root, 0, 30, ctx)
+	if header.Rect.Y != 0 {
+		t.Errorf("Expected the header not to stick to the root, got %v", header.Rect.Y)
+	}
+	pane.ScrollTo(0, 120, ctx)
+	UpdateSticky(pane, pane.Scroll.X, pane.Scroll.Y, ctx)
+	if header.Rect.Y != 120 {
+		t.Errorf("Expected the header at the pane's scroll position, got %v", header.Rect.Y)
+	}
+}
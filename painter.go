@@ -0,0 +1,92 @@
+package layout
+
+// Painter is what a rendering backend (gio, ebiten, Skia, a canvas...)
+// implements to paint laid-out trees with WalkPaint, without the layout
+// package depending on it or the backend walking the tree itself.
+//
+// Coordinates are those of the root's Rect, mapped by the transforms
+// pushed before the call. PushClip and PushTransform apply until their
+// matching Pop, like a canvas's save and restore; Pop undoes the last one
+// that hasn't been undone.
+//
+// Example, with gio:
+//
+//	type gioPainter struct {
+//		ops   *op.Ops
+//		stack []interface{ Pop() }
+//	}
+//
+//	func (p *gioPainter) PushClip(n *layout.Node, r layout.Rect) {
+//		p.stack = append(p.stack, clip.Rect(image.Rect(int(r.X), int(r.Y), int(r.X+r.Width), int(r.Y+r.Height))).Push(p.ops))
+//	}
+//
+//	func (p *gioPainter) Pop() {
+//		p.stack[len(p.stack)-1].Pop()
+//		p.stack = p.stack[:len(p.stack)-1]
+//	}
+type Painter interface {
+	// PushClip clips later calls to rect, the node's padding box (see
+	// OverflowClip).
+	PushClip(n *Node, rect Rect)
+
+	// PushTransform applies t to later calls, after the transforms
+	// already pushed.
+	PushTransform(n *Node, t Transform)
+
+	// Pop undoes the last PushClip or PushTransform.
+	Pop()
+
+	// DrawRect paints a node's border box, rect: its background fills
+	// it, and its border lies between it and inner.
+	DrawRect(n *Node, rect, inner Rect)
+
+	// DrawTextRun paints a word of a node's text (see PaintCommand).
+	DrawTextRun(n *Node, run TextRun)
+
+	// DrawImage paints the image at src, the "src" attribute of a node
+	// whose Tag is "img", in rect, its content box.
+	DrawImage(n *Node, src string, rect Rect)
+}
+
+// TextRun is a word of text for Painter.DrawTextRun.
+type TextRun struct {
+	// Text is the word in display order (see InlineBox.VisualText).
+	Text string
+
+	// Rect is the word's box: its advance by its line's height.
+	Rect Rect
+
+	// Baseline is the y of the word's baseline.
+	Baseline float64
+
+	// Style is the computed style of the word's line.
+	Style TextStyle
+}
+
+// WalkPaint paints root's tree, which must already be laid out, with p, in
+// painting order. It runs the commands of RecordDisplayList, so it paints
+// the same boxes in the same order: ctx should be the context the tree was
+// laid out with.
+//
+// Example:
+//
+//	layout.Layout(root, constraints, ctx)
+//	layout.WalkPaint(root, ctx, &gioPainter{ops: gtx.Ops})
+func WalkPaint(root *Node, ctx *LayoutContext, p Painter) {
+	for _, cmd := range RecordDisplayList(root, ctx) {
+		switch cmd.Op {
+		case PaintPushClip:
+			p.PushClip(cmd.Node, cmd.Rect)
+		case PaintPushTransform:
+			p.PushTransform(cmd.Node, cmd.Transform)
+		case PaintDrawRect:
+			p.DrawRect(cmd.Node, cmd.Rect, cmd.Inner)
+		case PaintDrawTextRun:
+			p.DrawTextRun(cmd.Node, TextRun{Text: cmd.Text, Rect: cmd.Rect, Baseline: cmd.Baseline, Style: cmd.TextStyle})
+		case PaintDrawImage:
+			p.DrawImage(cmd.Node, cmd.Source, cmd.Rect)
+		case PaintPop:
+			p.Pop()
+		}
+	}
+}
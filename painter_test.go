@@ -0,0 +1,72 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingPainter records the calls WalkPaint makes, and the details of
+// the last ones.
+type recordingPainter struct {
+	calls     []string
+	transform Transform
+	image     string
+}
+
+func (p *recordingPainter) PushClip(n *Node, rect Rect) {
+	p.calls = append(p.calls, "clip "+n.ID)
+}
+
+func (p *recordingPainter) PushTransform(n *Node, t Transform) {
+	p.calls = append(p.calls, "transform "+n.ID)
+	p.transform = t
+}
+
+func (p *recordingPainter) Pop() {
+	p.calls = append(p.calls, "pop")
+}
+
+func (p *recordingPainter) DrawRect(n *Node, rect, inner Rect) {
+	p.calls = append(p.calls, "rect "+n.ID)
+}
+
+func (p *recordingPainter) DrawTextRun(n *Node, run TextRun) {
+	p.calls = append(p.calls, "text "+run.Text)
+}
+
+func (p *recordingPainter) DrawImage(n *Node, src string, rect Rect) {
+	p.calls = append(p.calls, "image "+n.ID)
+	p.image = fmt.Sprintf("%s %v", src, rect)
+}
+
+func TestWalkPaint(t *testing.T) {
+	label := Text("Hi", Style{})
+	label.ID = "label"
+	img := Fixed(20, 20)
+	img.ID = "img"
+	img.Tag = "img"
+	img.Attributes = map[string]string{"src": "a.png"}
+	box := VStack(label, img)
+	box.ID = "box"
+	box.Style.Overflow = OverflowHidden
+	box.Style.Transform = Translate(5, 0)
+	root := VStack(box)
+	root.ID = "root"
+	ctx := NewLayoutContext(200, 200, 16)
+	Layout(root, Loose(200, 200), ctx)
+
+	p := &recordingPainter{}
+	WalkPaint(root, ctx, p)
+
+	want := "rect root, transform box, rect box, clip box, rect label, text Hi, rect img, image img, pop, pop"
+	if got := strings.Join(p.calls, ", "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if p.transform != Translate(5, 0) {
+		t.Errorf("Expected the box's transform, got %+v", p.transform)
+	}
+	if p.image != "a.png {0 19.2 20 20}" {
+		t.Errorf("Expected the image's source and content box, got %q", p.image)
+	}
+}
@@ -0,0 +1,93 @@
+package layout
+
+// Percentage sizes
+//
+// Width, Height, MinWidth, MinHeight, MaxWidth and MaxHeight accept
+// Percent lengths, which are taken of the containing block's content box:
+// the parent's content box for block and flex children, the grid area for
+// grid items, and the constraints (or, when unbounded, the viewport) for the
+// root.
+//
+// A percentage height needs a definite containing block height, as in CSS:
+// when the parent's height depends on its content, a percentage Height is
+// auto and a percentage MinHeight or MaxHeight is ignored. Widths behave the
+// same when the containing block width is unbounded.
+//
+// Containers resolve their children's percentages to pixels just before
+// laying them out, so the algorithms only ever see pixel sizes, and restore
+// the original lengths afterwards. Intrinsic size contributions treat
+// percentage sizes as auto. A calc() size with a percentage term (see
+// Calc) is a percentage size: its percentage terms become pixels and the
+// rest of the expression resolves as usual.
+//
+// See: https://www.w3.org/TR/css-sizing-3/#percentage-sizing
+// See: https://www.w3.org/TR/CSS2/visudet.html#the-height-property
+
+// percentSizeIndefinite is passed to resolvePercentSizes for a containing
+// block dimension that depends on content.
+const percentSizeIndefinite = -1.0
+
+// resolvePercentSizes replaces the percentage sizes of nodes with pixel
+// sizes taken of a width x height containing block, and returns a function
+// that restores the original lengths. A negative or unbounded dimension is
+// indefinite: percentages against it become auto.
+//
+// Example:
+//
+//	restore := resolvePercentSizes(node.Children, contentWidth, contentHeight)
+//	defer restore()
+func resolvePercentSizes(nodes []*Node, width, height float64) (restore func()) {
+	type saved struct {
+		node                *Node
+		width, height       Length
+		minWidth, minHeight Length
+		maxWidth, maxHeight Length
+	}
+	var originals []saved
+	for _, n := range nodes {
+		s := &n.Style
+		if !hasPercentSize(s) {
+			continue
+		}
+		originals = append(originals, saved{n, s.Width, s.Height, s.MinWidth, s.MinHeight, s.MaxWidth, s.MaxHeight})
+		s.Width = resolvePercentSize(s.Width, width, Px(-1))
+		s.Height = resolvePercentSize(s.Height, height, Px(-1))
+		s.MinWidth = resolvePercentSize(s.MinWidth, width, Length{})
+		s.MinHeight = resolvePercentSize(s.MinHeight, height, Length{})
+		s.MaxWidth = resolvePercentSize(s.MaxWidth, width, Length{})
+		s.MaxHeight = resolvePercentSize(s.MaxHeight, height, Length{})
+	}
+	return func() {
+		for _, o := range originals {
+			s := &o.node.Style
+			s.Width, s.Height = o.width, o.height
+			s.MinWidth, s.MinHeight = o.minWidth, o.minHeight
+			s.MaxWidth, s.MaxHeight = o.maxWidth, o.maxHeight
+		}
+	}
+}
+
+// hasPercentSize reports whether any size property of s is a percentage.
+func hasPercentSize(s *Style) bool {
+	for _, l := range []Length{s.Width, s.Height, s.MinWidth, s.MinHeight, s.MaxWidth, s.MaxHeight} {
+		if lengthHasPercent(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePercentSize resolves a percentage l against base, returning
+// indefinite when base is indefinite. Other lengths are returned unchanged.
+func resolvePercentSize(l Length, base float64, indefinite Length) Length {
+	if !lengthHasPercent(l) {
+		return l
+	}
+	if base < 0 || base >= Unbounded {
+		return indefinite
+	}
+	if isCalc(l) {
+		return calcWithPercentBase(l, base)
+	}
+	return Px(base * l.Value / 100)
+}
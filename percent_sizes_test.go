@@ -0,0 +1,81 @@
+package layout
+
+import "testing"
+
+func TestPercentWidthInBlock(t *testing.T) {
+	child := &Node{Style: Style{Display: DisplayBlock, Width: Percent(50), Height: Px(20)}}
+	root := &Node{
+		Style:    Style{Display: DisplayBlock, Width: Px(300), Height: Px(-1), Padding: Uniform(Px(10)), BoxSizing: BoxSizingBorderBox},
+		Children: []*Node{child},
+	}
+	Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if child.Rect.Width != 140 {
+		t.Errorf("Expected 50%% of the 280px content box, got %.2f", child.Rect.Width)
+	}
+	if child.Style.Width != Percent(50) {
+		t.Errorf("Expected the style to be restored, got %v", child.Style.Width)
+	}
+}
+
+func TestPercentHeightNeedsDefiniteParent(t *testing.T) {
+	t.Run("definite", func(t *testing.T) {
+		child := &Node{Style: Style{Display: DisplayBlock, Height: Percent(25)}}
+		root := &Node{Style: Style{Display: DisplayBlock, Width: Px(100), Height: Px(200)}, Children: []*Node{child}}
+		Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+		if child.Rect.Height != 50 {
+			t.Errorf("Expected 25%% of 200, got %.2f", child.Rect.Height)
+		}
+	})
+
+	t.Run("auto parent height", func(t *testing.T) {
+		content := Fixed(10, 30)
+		child := &Node{Style: Style{Display: DisplayBlock, Height: Percent(25), MinHeight: Percent(90)}, Children: []*Node{content}}
+		root := &Node{Style: Style{Display: DisplayBlock, Width: Px(100), Height: Px(-1)}, Children: []*Node{child}}
+		Layout(root, Loose(800, 600), NewLayoutContext(800, 600, 16))
+		if child.Rect.Height != 30 {
+			t.Errorf("Expected percentage height to behave as auto, got %.2f", child.Rect.Height)
+		}
+	})
+}
+
+func TestPercentSizesInFlex(t *testing.T) {
+	a := &Node{Style: Style{Width: Percent(25), Height: Percent(50)}}
+	b := &Node{Style: Style{Width: Px(40), Height: Px(10), MaxHeight: Percent(10)}}
+	row := HStack(a, b)
+	row.Style.Width = Px(400)
+	row.Style.Height = Px(100)
+	Layout(row, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if a.Rect.Width != 100 || a.Rect.Height != 50 {
+		t.Errorf("Expected 100x50, got %.2fx%.2f", a.Rect.Width, a.Rect.Height)
+	}
+	if b.Rect.Height != 10 {
+		t.Errorf("Expected max-height 10%% of 100 to allow 10, got %.2f", b.Rect.Height)
+	}
+}
+
+func TestPercentSizesInGridArea(t *testing.T) {
+	item := &Node{Style: Style{Width: Percent(50), Height: Percent(50), JustifySelf: JustifyItemsStart, AlignSelf: AlignItemsFlexStart}}
+	grid := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(200)), FixedTrack(Px(100))},
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(80))},
+		},
+		Children: []*Node{item},
+	}
+	Layout(grid, Loose(800, 600), NewLayoutContext(800, 600, 16))
+
+	if item.Rect.Width != 100 || item.Rect.Height != 40 {
+		t.Errorf("Expected half of the 200x80 grid area, got %.2fx%.2f", item.Rect.Width, item.Rect.Height)
+	}
+}
+
+func TestPercentSizeOnRoot(t *testing.T) {
+	root := &Node{Style: Style{Display: DisplayBlock, Width: Percent(50), Height: Percent(10)}}
+	size := Layout(root, Loose(800, Unbounded), NewLayoutContext(1024, 600, 16))
+	if size.Width != 400 || size.Height != 60 {
+		t.Errorf("Expected 400x60 (constraint width, viewport height), got %.2fx%.2f", size.Width, size.Height)
+	}
+}
@@ -0,0 +1,65 @@
+package layout
+
+import "sync"
+
+var nodePool = sync.Pool{
+	New: func() any { return &Node{} },
+}
+
+// AcquireNode returns an empty node, reused from ReleaseNode if one is
+// available. Immediate-mode UIs that rebuild their tree each frame can
+// acquire its nodes and release them once the frame is drawn, so building
+// the next one doesn't allocate.
+//
+// Example:
+//
+//	root := layout.AcquireNode()
+//	root.Style.Display = layout.DisplayFlex
+//	for _, item := range items {
+//	    child := layout.AcquireNode()
+//	    child.Text = item.Label
+//	    root.Children = append(root.Children, child)
+//	}
+//	layout.Layout(root, constraints, ctx)
+//	draw(root)
+//	layout.ReleaseNode(root)
+func AcquireNode() *Node {
+	return nodePool.Get().(*Node)
+}
+
+// ReleaseNode resets n and its descendants (see Reset) and returns them to
+// the pool AcquireNode takes nodes from. Nodes need not have come from
+// AcquireNode, but none of them may be used after, by the caller or
+// through another tree, and each may only appear once in n's tree.
+func ReleaseNode(n *Node) {
+	if n == nil {
+		return
+	}
+	var nodes []*Node
+	walkTree(n, func(node *Node, path []int) walkStep {
+		nodes = append(nodes, node)
+		return walkChildren
+	})
+	for _, node := range nodes {
+		node.Reset()
+		nodePool.Put(node)
+	}
+}
+
+// Reset clears n to the zero Node: style, children, text and text layout,
+// rects, identity, UserData, callbacks, states and scroll position. The
+// memory behind Children, Classes and Attributes is kept for reuse, so a
+// shallow copy of n (from Clone or a With method) that shares them must
+// not be used after. The former children themselves are left as they are.
+func (n *Node) Reset() {
+	clear(n.Children)
+	clear(n.Classes)
+	clear(n.Attributes)
+	clear(n.measured.entries)
+	*n = Node{
+		Children:   n.Children[:0],
+		Classes:    n.Classes[:0],
+		Attributes: n.Attributes,
+		measured:   measuredSizes{entries: n.measured.entries[:0]},
+	}
+}
@@ -0,0 +1,64 @@
+package layout
+
+import "testing"
+
+func TestNodeReset(t *testing.T) {
+	child := Fixed(20, 20)
+	n := VStack(child, Fixed(30, 30))
+	n.ID = "list"
+	n.Classes = []string{"a", "b"}
+	n.Attributes = map[string]string{"role": "list"}
+	n.UserData = 1
+	n.OnLayout(func(*Node) {})
+	Layout(n, Loose(400, 300), NewLayoutContext(400, 300, 16))
+
+	children := n.Children
+	n.Reset()
+
+	if n.ID != "" || n.UserData != nil || n.onLayout != nil || n.Rect != (Rect{}) || n.Style.Display != 0 {
+		t.Errorf("Expected a zero node, got %+v", n)
+	}
+	if len(n.Children) != 0 || cap(n.Children) < 2 || children[0] != nil {
+		t.Errorf("Expected empty Children keeping their capacity, got len %d cap %d", len(n.Children), cap(n.Children))
+	}
+	if len(n.Classes) != 0 || len(n.Attributes) != 0 {
+		t.Errorf("Expected no classes or attributes, got %v %v", n.Classes, n.Attributes)
+	}
+	if child.Rect.Width != 20 {
+		t.Errorf("Expected former children to be left alone, got %+v", child.Rect)
+	}
+
+	// A reset node lays out like a new one
+	n.Style = Style{Display: DisplayBlock, Height: Px(-1)}
+	n.Children = append(n.Children, Fixed(50, 10))
+	size := Layout(n, Loose(400, 300), NewLayoutContext(400, 300, 16))
+	if size.Height != 10 {
+		t.Errorf("Expected height 10, got %.2f", size.Height)
+	}
+}
+
+func TestAcquireAndReleaseNode(t *testing.T) {
+	ctx := NewLayoutContext(400, 300, 16)
+	for frame := 0; frame < 3; frame++ {
+		root := AcquireNode()
+		root.Style = Style{Display: DisplayBlock, Height: Px(-1)}
+		for i := 0; i < 3; i++ {
+			child := AcquireNode()
+			child.Style.Height = Px(10)
+			child.ID = "item"
+			root.Children = append(root.Children, child)
+		}
+		if size := Layout(root, Loose(400, 300), ctx); size.Height != 30 {
+			t.Errorf("Expected height 30 in frame %d, got %.2f", frame, size.Height)
+		}
+		ReleaseNode(root)
+		if len(root.Children) != 0 || root.ID != "" {
+			t.Errorf("Expected the released root to be reset in frame %d", frame)
+		}
+	}
+	ReleaseNode(nil)
+
+	if n := AcquireNode(); n.ID != "" || len(n.Children) != 0 || n.Rect != (Rect{}) {
+		t.Errorf("Expected an empty node, got %+v", n)
+	}
+}
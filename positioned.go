@@ -1,7 +1,9 @@
 package layout
 
-// LayoutPositioned handles positioned elements (absolute, relative, fixed, sticky).
+// LayoutPositioned handles positioned elements (absolute, relative, fixed).
 // This should be called after the normal layout flow to position elements.
+// Sticky elements depend on the scroll position and are positioned by
+// UpdateSticky.
 //
 // Algorithm based on CSS Positioned Layout Module Level 3:
 // - §2: Positioning Schemes
@@ -27,7 +29,7 @@ func LayoutPositioned(node *Node, parentRect Rect, viewportRect Rect, ctx *Layou
 		// Fixed is relative to viewport
 		positioningContext = viewportRect
 	case PositionAbsolute, PositionRelative, PositionSticky:
-		// Absolute/relative/sticky are relative to nearest positioned ancestor
+		// Absolute/relative are relative to nearest positioned ancestor
 		// For now, we'll use parentRect (in a full implementation, we'd traverse up)
 		positioningContext = parentRect
 	}
@@ -164,24 +166,8 @@ func LayoutPositioned(node *Node, parentRect Rect, viewportRect Rect, ctx *Layou
 		}
 	}
 
-	// For sticky positioning, we'd need scroll information
-	// For now, treat it similar to relative
-	if node.Style.Position == PositionSticky {
-		// Sticky is a hybrid - starts as relative, becomes fixed when scrolled
-		// Without scroll context, we'll treat it as relative
-		if left.Value >= 0 {
-			node.Rect.X += left.Value
-		}
-		if right.Value >= 0 {
-			node.Rect.X -= right.Value
-		}
-		if top.Value >= 0 {
-			node.Rect.Y += top.Value
-		}
-		if bottom.Value >= 0 {
-			node.Rect.Y -= bottom.Value
-		}
-	}
+	// Sticky boxes are positioned against their scroll container's
+	// scrollport by UpdateSticky
 }
 
 // findPositionedAncestor finds the nearest positioned ancestor
@@ -198,12 +184,19 @@ func findPositionedAncestor(node *Node, root *Node) *Node {
 // 1. Normal flow layout
 // 2. Positioned elements layout
 func LayoutWithPositioning(root *Node, constraints Constraints, viewportRect Rect, ctx *LayoutContext) Size {
+	restore, _ := prepareTree(root, ctx.maxDepth())
+	defer restore()
+
 	// First pass: normal flow layout
-	size := Layout(root, constraints, ctx)
+	size := layoutPrepared(root, constraints, ctx)
 
 	// Second pass: handle positioned elements
 	layoutPositionedRecursive(root, root.Rect, viewportRect, ctx)
 
+	// Sticky elements stick to their scroll container, or the root
+	updateSticky(root, root.Scroll.X, root.Scroll.Y, ctx)
+
+	runLayoutCallbacks(root, ctx)
 	return size
 }
 
@@ -0,0 +1,350 @@
+package layout
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LayoutProfile collects the time layout algorithms take on each node of
+// the layouts it's attached to (see LayoutContext.WithProfile), through the
+// OnNodeStart and OnNodeEnd hooks, to find the subtrees that eat a frame's
+// budget.
+//
+// Runs are aggregated by call path: a node laid out several times under
+// the same ancestors, for example measured and then stretched, counts
+// once, with the time of all its runs. The profile can be summarized per
+// node with Subtrees, or written as folded stacks, for flame graph tools,
+// or as a pprof profile, for go tool pprof. Frames are named like DumpTree
+// names nodes ("grid#cards"), so giving the nodes of interest an ID makes
+// them easy to find.
+//
+// A LayoutProfile profiles one layout at a time; layouts running
+// concurrently need a profile each. The zero value is ready to use.
+//
+// Example:
+//
+//	profile := &layout.LayoutProfile{}
+//	layout.Layout(root, constraints, ctx.WithProfile(profile))
+//	for _, s := range profile.Subtrees() {
+//	    fmt.Printf("#%s: %v (%d runs)\n", s.Node.ID, s.Total, s.Runs)
+//	}
+//	profile.WriteFolded(f) // flamegraph.pl layout.folded > layout.svg
+type LayoutProfile struct {
+	mu    sync.Mutex
+	roots profileCalls
+	open  []*profileCall
+	start time.Time
+}
+
+// SubtreeProfile is the time spent laying out a node's subtree, summed over
+// its runs.
+type SubtreeProfile struct {
+	Node *Node
+
+	// Runs is the number of times a layout algorithm ran on the node.
+	Runs int
+
+	// Total is the time the runs took, including the layout of the node's
+	// descendants, and Self the time excluding it.
+	Total time.Duration
+	Self  time.Duration
+}
+
+// profileCall is a node's runs under a call path.
+type profileCall struct {
+	node     *Node
+	runs     int
+	total    time.Duration
+	children profileCalls
+}
+
+// profileCalls are the calls under a call path, in the order they were
+// first made.
+type profileCalls struct {
+	calls  []*profileCall
+	byNode map[*Node]*profileCall
+}
+
+// get returns the call of n, adding it if it's new.
+func (c *profileCalls) get(n *Node) *profileCall {
+	if call, ok := c.byNode[n]; ok {
+		return call
+	}
+	if c.byNode == nil {
+		c.byNode = make(map[*Node]*profileCall)
+	}
+	call := &profileCall{node: n}
+	c.byNode[n] = call
+	c.calls = append(c.calls, call)
+	return call
+}
+
+// self returns the call's time excluding its children's.
+func (c *profileCall) self() time.Duration {
+	self := c.total
+	for _, child := range c.children.calls {
+		self -= child.total
+	}
+	if self < 0 {
+		return 0
+	}
+	return self
+}
+
+// WithProfile returns a copy of the context that records the time its
+// layouts take in profile, calling the context's OnNodeStart and OnNodeEnd
+// hooks as well.
+//
+// Example:
+//
+//	profile := &layout.LayoutProfile{}
+//	ctx := layout.NewLayoutContext(1920, 1080, 16).WithProfile(profile)
+func (ctx *LayoutContext) WithProfile(profile *LayoutProfile) *LayoutContext {
+	copy := *ctx
+	onStart, onEnd := ctx.OnNodeStart, ctx.OnNodeEnd
+	copy.OnNodeStart = func(t NodeTrace) {
+		profile.begin(t)
+		if onStart != nil {
+			onStart(t)
+		}
+	}
+	copy.OnNodeEnd = func(t NodeTrace) {
+		if onEnd != nil {
+			onEnd(t)
+		}
+		profile.end(t)
+	}
+	return &copy
+}
+
+// begin records the start of a run.
+func (p *LayoutProfile) begin(t NodeTrace) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := &p.roots
+	if len(p.open) > 0 {
+		calls = &p.open[len(p.open)-1].children
+	} else if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	p.open = append(p.open, calls.get(t.Node))
+}
+
+// end records the end of the innermost run.
+func (p *LayoutProfile) end(t NodeTrace) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.open) == 0 {
+		return
+	}
+	call := p.open[len(p.open)-1]
+	p.open = p.open[:len(p.open)-1]
+	call.runs++
+	call.total += t.Duration
+}
+
+// Reset forgets the time recorded.
+func (p *LayoutProfile) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.roots = profileCalls{}
+	p.open = nil
+	p.start = time.Time{}
+}
+
+// Subtrees returns the time spent on each node recorded, over all its call
+// paths, slowest first. A node that is laid out again inside one of its
+// own runs counts that time twice in its Total.
+func (p *LayoutProfile) Subtrees() []SubtreeProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var subtrees []SubtreeProfile
+	index := make(map[*Node]int)
+	var visit func(calls *profileCalls)
+	visit = func(calls *profileCalls) {
+		for _, call := range calls.calls {
+			i, ok := index[call.node]
+			if !ok {
+				i = len(subtrees)
+				index[call.node] = i
+				subtrees = append(subtrees, SubtreeProfile{Node: call.node})
+			}
+			subtrees[i].Runs += call.runs
+			subtrees[i].Total += call.total
+			subtrees[i].Self += call.self()
+			visit(&call.children)
+		}
+	}
+	visit(&p.roots)
+	sort.SliceStable(subtrees, func(i, j int) bool {
+		return subtrees[i].Total > subtrees[j].Total
+	})
+	return subtrees
+}
+
+// WriteFolded writes the profile as folded stacks, the input format of
+// flamegraph.pl, speedscope and other flame graph tools: a line per call
+// path, its frames separated by semicolons and followed by the path's self
+// time in nanoseconds. Paths whose frames have the same names, like those
+// of sibling nodes without an ID, are summed into a line.
+//
+//	flex#app;grid#cards;block.card 182000
+func (p *LayoutProfile) WriteFolded(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var stacks []string
+	self := make(map[string]time.Duration)
+	var visit func(calls *profileCalls, prefix string)
+	visit = func(calls *profileCalls, prefix string) {
+		for _, call := range calls.calls {
+			stack := prefix + profileFrame(call.node)
+			if _, ok := self[stack]; !ok {
+				stacks = append(stacks, stack)
+			}
+			self[stack] += call.self()
+			visit(&call.children, stack+";")
+		}
+	}
+	visit(&p.roots, "")
+
+	bw := bufio.NewWriter(w)
+	for _, stack := range stacks {
+		if self[stack] > 0 {
+			fmt.Fprintf(bw, "%s %d\n", stack, self[stack].Nanoseconds())
+		}
+	}
+	return bw.Flush()
+}
+
+// profileFrame returns the frame name of n in folded stacks and pprof
+// profiles.
+func profileFrame(n *Node) string {
+	return strings.NewReplacer(";", ",", " ", "_", "\n", "_").Replace(nodeLabel(n))
+}
+
+// WritePprof writes the profile as a gzipped pprof profile
+// (https://github.com/google/pprof/blob/main/proto/profile.proto), with a
+// sample per call path counting its runs and its self time, for go tool
+// pprof and other tools that read pprof:
+//
+//	go tool pprof -http=:8080 layout.pb.gz
+func (p *LayoutProfile) WritePprof(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var pb protoBuffer
+	strs := map[string]int{"": 0}
+	table := []string{""}
+	str := func(s string) uint64 {
+		i, ok := strs[s]
+		if !ok {
+			i = len(table)
+			strs[s] = i
+			table = append(table, s)
+		}
+		return uint64(i)
+	}
+	valueType := func(typ, unit string) []byte {
+		var vt protoBuffer
+		vt.uint(1, str(typ))
+		vt.uint(2, str(unit))
+		return vt.b
+	}
+
+	// Profile.sample_type
+	pb.bytes(1, valueType("runs", "count"))
+	pb.bytes(1, valueType("time", "nanoseconds"))
+
+	// Profile.sample, with a location and function per frame name, leaf
+	// first
+	functions := make(map[string]uint64)
+	var frames []string
+	var visit func(calls *profileCalls, stack []uint64)
+	visit = func(calls *profileCalls, stack []uint64) {
+		for _, call := range calls.calls {
+			name := profileFrame(call.node)
+			id, ok := functions[name]
+			if !ok {
+				frames = append(frames, name)
+				id = uint64(len(frames))
+				functions[name] = id
+			}
+			path := append([]uint64{id}, stack...)
+			var sample protoBuffer
+			sample.packed(1, path)
+			sample.packed(2, []uint64{uint64(call.runs), uint64(call.self().Nanoseconds())})
+			pb.bytes(2, sample.b)
+			visit(&call.children, path)
+		}
+	}
+	visit(&p.roots, nil)
+
+	// Profile.location and Profile.function
+	for i, name := range frames {
+		id := uint64(i + 1)
+		var line, location, function protoBuffer
+		line.uint(1, id)
+		location.uint(1, id)
+		location.bytes(4, line.b)
+		pb.bytes(4, location.b)
+		function.uint(1, id)
+		function.uint(2, str(name))
+		function.uint(3, str(name))
+		pb.bytes(5, function.b)
+	}
+
+	// Profile.time_nanos, duration_nanos and period_type, then the
+	// string table
+	if !p.start.IsZero() {
+		pb.uint(9, uint64(p.start.UnixNano()))
+	}
+	var total time.Duration
+	for _, call := range p.roots.calls {
+		total += call.total
+	}
+	pb.uint(10, uint64(total.Nanoseconds()))
+	pb.bytes(11, valueType("time", "nanoseconds"))
+	for _, s := range table {
+		pb.bytes(6, []byte(s))
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(pb.b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// protoBuffer encodes a protocol buffer message.
+type protoBuffer struct {
+	b []byte
+}
+
+// uint appends a varint field.
+func (pb *protoBuffer) uint(field int, v uint64) {
+	pb.b = binary.AppendUvarint(pb.b, uint64(field)<<3)
+	pb.b = binary.AppendUvarint(pb.b, v)
+}
+
+// bytes appends a length-delimited field.
+func (pb *protoBuffer) bytes(field int, v []byte) {
+	pb.b = binary.AppendUvarint(pb.b, uint64(field)<<3|2)
+	pb.b = binary.AppendUvarint(pb.b, uint64(len(v)))
+	pb.b = append(pb.b, v...)
+}
+
+// packed appends a packed repeated varint field.
+func (pb *protoBuffer) packed(field int, vs []uint64) {
+	var packed []byte
+	for _, v := range vs {
+		packed = binary.AppendUvarint(packed, v)
+	}
+	pb.bytes(field, packed)
+}
@@ -0,0 +1,105 @@
+package layout
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLayoutProfileRecordsRuns(t *testing.T) {
+	a, b := Fixed(50, 20), Fixed(30, 20)
+	row := HStack(a, b)
+	row.ID = "row"
+	root := VStack(row)
+	ctx := NewLayoutContext(400, 300, 16)
+	hooked := 0
+	ctx.OnNodeEnd = func(NodeTrace) { hooked++ }
+
+	profile := &LayoutProfile{}
+	Layout(root, Loose(400, 300), ctx.WithProfile(profile))
+
+	if hooked == 0 {
+		t.Errorf("Expected the context's own hooks to be called")
+	}
+	subtrees := profile.Subtrees()
+	if len(subtrees) == 0 || subtrees[0].Node != root {
+		t.Fatalf("Expected the root first, got %+v", subtrees)
+	}
+	runs := 0
+	seen := make(map[*Node]bool)
+	for _, s := range subtrees {
+		runs += s.Runs
+		seen[s.Node] = true
+		if s.Self > s.Total {
+			t.Errorf("Expected self time within total time, got %+v", s)
+		}
+	}
+	if !seen[row] || !seen[a] || !seen[b] {
+		t.Errorf("Expected every node to be profiled, got %+v", subtrees)
+	}
+	if runs != hooked {
+		t.Errorf("Expected %d runs, got %d", hooked, runs)
+	}
+
+	profile.Reset()
+	if len(profile.Subtrees()) != 0 {
+		t.Errorf("Expected no subtrees after Reset")
+	}
+}
+
+func TestLayoutProfileWriteFolded(t *testing.T) {
+	root := &Node{ID: "app", Style: Style{Display: DisplayFlex}}
+	first, second := &Node{Classes: []string{"a;b"}}, &Node{Classes: []string{"a;b"}}
+	profile := &LayoutProfile{}
+	profile.begin(NodeTrace{Node: root})
+	for _, child := range []*Node{first, second, first} {
+		profile.begin(NodeTrace{Node: child})
+		profile.end(NodeTrace{Node: child, Duration: 10 * time.Microsecond})
+	}
+	profile.end(NodeTrace{Node: root, Duration: 100 * time.Microsecond})
+
+	var b bytes.Buffer
+	if err := profile.WriteFolded(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "flex#app 70000\nflex#app;block.a,b 30000\n"
+	if b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+
+	subtrees := profile.Subtrees()
+	if subtrees[1].Node != first || subtrees[1].Runs != 2 || subtrees[1].Total != 20*time.Microsecond {
+		t.Errorf("Expected 2 runs of the first child taking 20µs, got %+v", subtrees[1])
+	}
+	if subtrees[0].Self != 70*time.Microsecond {
+		t.Errorf("Expected the root to take 70µs itself, got %v", subtrees[0].Self)
+	}
+}
+
+func TestLayoutProfileWritePprof(t *testing.T) {
+	profile := &LayoutProfile{}
+	grid := Grid(1, 1, 50, 50)
+	grid.ID = "cards"
+	Layout(VStack(grid), Loose(400, 300), NewLayoutContext(400, 300, 16).WithProfile(profile))
+
+	var b bytes.Buffer
+	if err := profile.WritePprof(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	zr, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatalf("Expected a gzipped profile, got %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Expected a gzipped profile, got %v", err)
+	}
+	for _, s := range []string{"grid#cards", "nanoseconds", "runs"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("Expected %q in the string table", s)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package canvas exports the display list of a laid-out node tree (see
+// layout.RecordDisplayList) as JSON draw commands for an HTML canvas, so a
+// Go service can compute layout on the server and browsers only paint.
+//
+// The commands are painted by canvas.js, a small script with no
+// dependencies that ships with the package (see Script):
+//
+//	layout.Layout(root, layout.Loose(800, 600), ctx)
+//	data, _ := canvas.Marshal(root, ctx)
+//
+//	// In the browser, with canvas.js loaded:
+//	// LayoutCanvas.render(canvasElement, JSON.parse(data))
+package canvas
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Version is the version of the JSON format, which Document.Version
+// carries. It changes when a change to the format would break canvas.js
+// or other readers.
+const Version = 1
+
+// Script is canvas.js, which paints a Document on a canvas. Serve it next
+// to the documents, or copy it into a web project.
+//
+//go:embed canvas.js
+var Script string
+
+// Document is a display list in JSON form.
+type Document struct {
+	Version int `json:"version"`
+
+	// Width and Height are the size of the root's border box, the size
+	// to make the canvas.
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+
+	Commands []Command `json:"commands"`
+}
+
+// Command is a draw command. Op says which:
+//
+//   - "clip" clips later commands to Rect, until the matching "pop"
+//   - "transform" applies Matrix to later commands, until the matching "pop"
+//   - "rect" paints a node's box: its background fills Rect, and its
+//     border lies between Rect and Inner
+//   - "text" paints Text with its baseline starting at (X, Y) in Font
+//   - "image" paints the image at Src, an "img" node's "src" attribute, in
+//     Rect, its content box
+//   - "pop" undoes the last "clip" or "transform" that hasn't been undone
+//
+// Rects are [x, y, width, height] in the coordinates of root.Rect, mapped
+// by the transforms applied before the command. The first command is a
+// "transform" that moves the root's corner to the canvas's origin.
+type Command struct {
+	Op string `json:"op"`
+
+	// ID, Classes and Path identify the node that's clipped, transformed
+	// or painted, so the page can pick its colors. Path is its child
+	// indexes from the root ("0/2"; "" for the root).
+	ID      string   `json:"id,omitempty"`
+	Classes []string `json:"classes,omitempty"`
+	Path    *string  `json:"path,omitempty"`
+
+	Rect  *[4]float64 `json:"rect,omitempty"`
+	Inner *[4]float64 `json:"inner,omitempty"`
+
+	// Matrix is a "transform"'s matrix [a, b, c, d, e, f], the arguments
+	// of the canvas's transform method.
+	Matrix *[6]float64 `json:"matrix,omitempty"`
+
+	// Text, X, Y and Font describe a "text" command. Font is a CSS font
+	// shorthand, the value of the canvas's font property.
+	Text string  `json:"text,omitempty"`
+	X    float64 `json:"x,omitempty"`
+	Y    float64 `json:"y,omitempty"`
+	Font string  `json:"font,omitempty"`
+
+	// Src is the source of an "image".
+	Src string `json:"src,omitempty"`
+}
+
+// Export returns the display list of root's tree, which must already be
+// laid out, as a Document. ctx resolves the lengths of borders and should
+// be the context the tree was laid out with.
+func Export(root *layout.Node, ctx *layout.LayoutContext) *Document {
+	doc := &Document{Version: Version, Commands: []Command{}}
+	if root == nil {
+		return doc
+	}
+	doc.Width, doc.Height = root.Rect.Width, root.Rect.Height
+
+	paths := make(map[*layout.Node]string)
+	var walk func(n *layout.Node, path string)
+	walk = func(n *layout.Node, path string) {
+		paths[n] = path
+		for i, child := range n.Children {
+			childPath := strconv.Itoa(i)
+			if path != "" {
+				childPath = path + "/" + childPath
+			}
+			walk(child, childPath)
+		}
+	}
+	walk(root, "")
+
+	// The display list is in the coordinates of root.Rect; the canvas's
+	// origin is the root's corner (0 - x, as -x is -0 for 0)
+	doc.Commands = append(doc.Commands, Command{Op: "transform", Matrix: &[6]float64{1, 0, 0, 1, 0 - root.Rect.X, 0 - root.Rect.Y}})
+	for _, pc := range layout.RecordDisplayList(root, ctx) {
+		cmd := Command{}
+		if pc.Node != nil {
+			path := paths[pc.Node]
+			cmd.ID, cmd.Classes, cmd.Path = pc.Node.ID, pc.Node.Classes, &path
+		}
+		switch pc.Op {
+		case layout.PaintPushClip:
+			cmd.Op = "clip"
+			cmd.Rect = rect(pc.Rect)
+		case layout.PaintPushTransform:
+			t := pc.Transform
+			cmd.Op = "transform"
+			cmd.Matrix = &[6]float64{t.A, t.B, t.C, t.D, t.E, t.F}
+		case layout.PaintDrawRect:
+			cmd.Op = "rect"
+			cmd.Rect = rect(pc.Rect)
+			if pc.Inner != pc.Rect {
+				cmd.Inner = rect(pc.Inner)
+			}
+		case layout.PaintDrawTextRun:
+			cmd.Op = "text"
+			cmd.Text = pc.Text
+			cmd.X, cmd.Y = pc.Rect.X, pc.Baseline
+			cmd.Font = font(pc.TextStyle)
+		case layout.PaintDrawImage:
+			cmd.Op = "image"
+			cmd.Rect = rect(pc.Rect)
+			cmd.Src = pc.Source
+		case layout.PaintPop:
+			cmd.Op = "pop"
+		}
+		doc.Commands = append(doc.Commands, cmd)
+	}
+	doc.Commands = append(doc.Commands, Command{Op: "pop"})
+	return doc
+}
+
+// Marshal returns the JSON of root's Document (see Export).
+func Marshal(root *layout.Node, ctx *layout.LayoutContext) ([]byte, error) {
+	return json.Marshal(Export(root, ctx))
+}
+
+// Encode writes the JSON of root's Document (see Export) to w.
+func Encode(w io.Writer, root *layout.Node, ctx *layout.LayoutContext) error {
+	return json.NewEncoder(w).Encode(Export(root, ctx))
+}
+
+// rect returns r as [x, y, width, height].
+func rect(r layout.Rect) *[4]float64 {
+	return &[4]float64{r.X, r.Y, r.Width, r.Height}
+}
+
+// font returns the CSS font shorthand of ts, like "italic 700 16px serif".
+func font(ts layout.TextStyle) string {
+	var parts []string
+	switch ts.FontStyle {
+	case layout.FontStyleItalic:
+		parts = append(parts, "italic")
+	case layout.FontStyleOblique:
+		parts = append(parts, "oblique")
+	}
+	if ts.FontWeight != 0 && ts.FontWeight != layout.FontWeightNormal {
+		parts = append(parts, strconv.Itoa(int(ts.FontWeight)))
+	}
+	size := ts.FontSize
+	if size <= 0 {
+		size = 16
+	}
+	family := ts.FontFamily
+	if family == "" {
+		family = "sans-serif"
+	}
+	parts = append(parts, strconv.FormatFloat(size, 'f', -1, 64)+"px", family)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,115 @@
+// canvas.js paints the draw commands that github.com/SCKelemen/layout/render/canvas
+// exports on an HTML canvas. It has no dependencies.
+//
+//   const doc = await (await fetch("/layout.json")).json();
+//   LayoutCanvas.render(document.querySelector("canvas"), doc, {
+//     boxStyle: (cmd) => cmd.id === "header" ? { background: "#eee" } : {},
+//   });
+//
+// Options:
+//   boxStyle(cmd)  returns { background, border } colors for a "rect"
+//                  command; either may be left out. Default: a black border
+//                  where the node has one, no background.
+//   textColor(cmd) returns the color of a "text" command. Default: black.
+//   image(cmd)     returns the loaded image (an HTMLImageElement, ImageBitmap
+//                  or other CanvasImageSource) for an "image" command's src,
+//                  or null to skip it. Default: skip images.
+(function (global) {
+  "use strict";
+
+  var VERSION = 1;
+
+  function defaultBoxStyle() {
+    return { border: "#000" };
+  }
+
+  function defaultTextColor() {
+    return "#000";
+  }
+
+  // paint runs doc's commands on a 2D context, whose transform should map
+  // CSS pixels to the canvas.
+  function paint(ctx, doc, options) {
+    if (doc.version > VERSION) {
+      throw new Error("layout canvas: unsupported version " + doc.version);
+    }
+    options = options || {};
+    var boxStyle = options.boxStyle || defaultBoxStyle;
+    var textColor = options.textColor || defaultTextColor;
+    var image = options.image || function () { return null; };
+
+    ctx.save();
+    doc.commands.forEach(function (cmd) {
+      switch (cmd.op) {
+        case "clip":
+          ctx.save();
+          ctx.beginPath();
+          ctx.rect(cmd.rect[0], cmd.rect[1], cmd.rect[2], cmd.rect[3]);
+          ctx.clip();
+          break;
+        case "transform":
+          ctx.save();
+          ctx.transform.apply(ctx, cmd.matrix);
+          break;
+        case "rect":
+          paintBox(ctx, cmd, boxStyle(cmd) || {});
+          break;
+        case "text":
+          ctx.font = cmd.font;
+          ctx.textBaseline = "alphabetic";
+          ctx.fillStyle = textColor(cmd);
+          ctx.fillText(cmd.text, cmd.x || 0, cmd.y || 0);
+          break;
+        case "image":
+          var source = image(cmd);
+          if (source) {
+            ctx.drawImage(source, cmd.rect[0], cmd.rect[1], cmd.rect[2], cmd.rect[3]);
+          }
+          break;
+        case "pop":
+          ctx.restore();
+          break;
+      }
+    });
+    ctx.restore();
+  }
+
+  // paintBox fills a box's background, then its border: the area between
+  // its rect and its inner rect.
+  function paintBox(ctx, cmd, style) {
+    var r = cmd.rect;
+    if (style.background) {
+      ctx.fillStyle = style.background;
+      ctx.fillRect(r[0], r[1], r[2], r[3]);
+    }
+    if (style.border && cmd.inner) {
+      var i = cmd.inner;
+      ctx.beginPath();
+      ctx.rect(r[0], r[1], r[2], r[3]);
+      ctx.rect(i[0], i[1], Math.max(i[2], 0), Math.max(i[3], 0));
+      ctx.fillStyle = style.border;
+      ctx.fill("evenodd");
+    }
+  }
+
+  // render sizes a canvas element for doc at the device's pixel ratio and
+  // paints it.
+  function render(canvas, doc, options) {
+    var ratio = global.devicePixelRatio || 1;
+    canvas.width = Math.ceil(doc.width * ratio);
+    canvas.height = Math.ceil(doc.height * ratio);
+    canvas.style.width = doc.width + "px";
+    canvas.style.height = doc.height + "px";
+    var ctx = canvas.getContext("2d");
+    ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+    ctx.clearRect(0, 0, doc.width, doc.height);
+    paint(ctx, doc, options);
+  }
+
+  var LayoutCanvas = { VERSION: VERSION, paint: paint, render: render };
+  if (typeof module === "object" && module.exports) {
+    module.exports = LayoutCanvas;
+  } else {
+    global.LayoutCanvas = LayoutCanvas;
+  }
+})(typeof window !== "undefined" ? window : globalThis);
@@ -0,0 +1,125 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestExport(t *testing.T) {
+	ctx := layout.NewLayoutContext(400, 300, 16)
+	card := layout.Fixed(100, 50)
+	card.ID = "card"
+	card.Classes = []string{"raised"}
+	card.Style.Border = layout.Uniform(layout.Px(2))
+	label := layout.Text("Hi", layout.Style{TextStyle: &layout.TextStyle{FontSize: 20, FontWeight: layout.FontWeightBold, FontFamily: "serif"}})
+	root := layout.VStack(card, label)
+	root.Style.Transform = layout.Translate(10, 0)
+	layout.Layout(root, layout.Loose(400, 300), ctx)
+
+	doc := Export(root, ctx)
+	if doc.Version != Version || doc.Width != root.Rect.Width || doc.Height != root.Rect.Height {
+		t.Errorf("Expected the root's size, got %v×%v", doc.Width, doc.Height)
+	}
+
+	var ops []string
+	depth := 0
+	for _, cmd := range doc.Commands {
+		ops = append(ops, cmd.Op)
+		switch cmd.Op {
+		case "clip", "transform":
+			depth++
+		case "pop":
+			depth--
+		}
+	}
+	want := "transform transform rect rect rect text pop pop"
+	if got := strings.Join(ops, " "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if depth != 0 {
+		t.Errorf("Expected balanced pushes and pops, got %d", depth)
+	}
+
+	box := doc.Commands[3]
+	if box.ID != "card" || *box.Path != "0" || box.Classes[0] != "raised" {
+		t.Errorf("Expected the card's box, got %+v", box)
+	}
+	if *box.Rect != [4]float64{0, 0, 104, 54} || *box.Inner != [4]float64{2, 2, 100, 50} {
+		t.Errorf("Expected the card's border box and inner rect, got %v and %v", *box.Rect, *box.Inner)
+	}
+	if doc.Commands[2].Inner != nil || *doc.Commands[2].Path != "" {
+		t.Errorf("Expected the root without a border, got %+v", doc.Commands[2])
+	}
+	text := doc.Commands[5]
+	if text.Text != "Hi" || text.Font != "700 20px serif" || *text.Path != "1" || text.Y <= box.Rect[3] {
+		t.Errorf("Expected the label's text below the card, got %+v", text)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	root := layout.Fixed(40, 30)
+	ctx := layout.NewLayoutContext(400, 300, 16)
+	layout.Layout(root, layout.Loose(400, 300), ctx)
+
+	data, err := Marshal(root, ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := `{"version":1,"width":40,"height":30,"commands":[` +
+		`{"op":"transform","matrix":[1,0,0,1,0,0]},` +
+		`{"op":"rect","path":"","rect":[0,0,40,30]},` +
+		`{"op":"pop"}]}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	var b bytes.Buffer
+	if err := Encode(&b, nil, ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil || len(doc.Commands) != 0 {
+		t.Errorf("Expected an empty document, got %s", b.String())
+	}
+}
+
+func TestFont(t *testing.T) {
+	for _, tt := range []struct {
+		style layout.TextStyle
+		want  string
+	}{
+		{layout.TextStyle{}, "16px sans-serif"},
+		{layout.TextStyle{FontSize: 12.5, FontStyle: layout.FontStyleItalic, FontWeight: 300, FontFamily: "Inter"}, "italic 300 12.5px Inter"},
+		{layout.TextStyle{FontWeight: layout.FontWeightNormal, FontStyle: layout.FontStyleOblique}, "oblique 16px sans-serif"},
+	} {
+		if got := font(tt.style); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	if !strings.Contains(Script, "LayoutCanvas") {
+		t.Errorf("Expected canvas.js to be embedded")
+	}
+}
+
+func TestExportImage(t *testing.T) {
+	img := layout.Fixed(20, 10)
+	img.Tag = "img"
+	img.Attributes = map[string]string{"src": "logo.png"}
+	ctx := layout.NewLayoutContext(400, 300, 16)
+	layout.Layout(img, layout.Loose(400, 300), ctx)
+
+	doc := Export(img, ctx)
+	if len(doc.Commands) != 4 {
+		t.Fatalf("Expected 4 commands, got %+v", doc.Commands)
+	}
+	if c := doc.Commands[2]; c.Op != "image" || c.Src != "logo.png" || *c.Rect != [4]float64{0, 0, 20, 10} {
+		t.Errorf("Expected the image in its content box, got %+v", c)
+	}
+}
@@ -0,0 +1,510 @@
+package html
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// gridSpanBase mirrors layout's encoding of Span(n) as gridSpanBase+n.
+const gridSpanBase = math.MinInt32 / 2
+
+// declarations builds a CSS declaration block.
+type declarations struct {
+	b strings.Builder
+}
+
+// add appends the declaration name: value.
+func (d *declarations) add(name, value string) {
+	if d.b.Len() > 0 {
+		d.b.WriteByte(' ')
+	}
+	fmt.Fprintf(&d.b, "%s: %s;", name, value)
+}
+
+// String returns the declarations.
+func (d *declarations) String() string {
+	return d.b.String()
+}
+
+// StyleCSS returns the CSS declarations for n's style that Render writes
+// in Semantic mode, n being a child of a flex container if inFlex.
+// Properties at their initial value are left out, and so is an unset
+// (zero) length, which is auto: sizes the engine reads as 0px when unset
+// are written as auto, like most trees intend.
+//
+// Example:
+//
+//	decls, _ := css.ParseDeclarations(html.StyleCSS(node, false))
+func StyleCSS(n *layout.Node, inFlex bool) string {
+	var css declarations
+	s := &n.Style
+
+	switch s.Display {
+	case layout.DisplayFlex:
+		css.add("display", "flex")
+	case layout.DisplayGrid:
+		css.add("display", "grid")
+	case layout.DisplayNone:
+		css.add("display", "none")
+	}
+	if s.BoxSizing == layout.BoxSizingBorderBox {
+		css.add("box-sizing", "border-box")
+	}
+
+	// Position
+	if pos, ok := positions[s.Position]; ok {
+		css.add("position", pos)
+		for _, side := range []struct {
+			name  string
+			value layout.Length
+		}{{"top", s.Top}, {"right", s.Right}, {"bottom", s.Bottom}, {"left", s.Left}} {
+			if isSet(side.value) {
+				css.add(side.name, length(side.value))
+			}
+		}
+	}
+	if s.ZIndex != 0 {
+		css.add("z-index", fmt.Sprint(s.ZIndex))
+	}
+
+	// Sizing
+	if v := size(s.Width, s.WidthSizing); v != "" {
+		css.add("width", v)
+	}
+	if v := size(s.Height, s.HeightSizing); v != "" {
+		css.add("height", v)
+	}
+	for _, l := range []struct {
+		name  string
+		value layout.Length
+	}{{"min-width", s.MinWidth}, {"min-height", s.MinHeight}} {
+		if isSet(l.value) {
+			css.add(l.name, length(l.value))
+		}
+	}
+	for _, l := range []struct {
+		name  string
+		value layout.Length
+	}{{"max-width", s.MaxWidth}, {"max-height", s.MaxHeight}} {
+		if v := maxSize(l.value); v != "" {
+			css.add(l.name, v)
+		}
+	}
+	if s.AspectRatio > 0 {
+		css.add("aspect-ratio", num(s.AspectRatio))
+	}
+
+	// Box model
+	writeSpacing(&css, "margin-%s", s.Margin, true)
+	writeSpacing(&css, "padding-%s", s.Padding, false)
+	writeSpacing(&css, "border-%s-width", s.Border, false)
+
+	// Flexbox container
+	if s.Display == layout.DisplayFlex {
+		if v, ok := flexDirections[s.FlexDirection]; ok {
+			css.add("flex-direction", v)
+		}
+		if v, ok := flexWraps[s.FlexWrap]; ok {
+			css.add("flex-wrap", v)
+		}
+		if v, ok := justifyContents[s.JustifyContent]; ok {
+			css.add("justify-content", v)
+		}
+		writeGaps(&css, s.FlexGap, s.FlexRowGap, s.FlexColumnGap)
+	}
+	if s.Display == layout.DisplayFlex || s.Display == layout.DisplayGrid {
+		if v, ok := alignItems[s.AlignItems]; ok {
+			css.add("align-items", v)
+		}
+		if v, ok := alignContents[s.AlignContent]; ok {
+			css.add("align-content", v)
+		}
+	}
+
+	// Flex and grid items
+	if v, ok := alignItems[s.AlignSelf]; ok {
+		css.add("align-self", v)
+	}
+	if inFlex {
+		if s.FlexGrow != 0 {
+			css.add("flex-grow", num(s.FlexGrow))
+		}
+		// The engine's default flex-shrink is 0, CSS's 1
+		css.add("flex-shrink", num(s.FlexShrink))
+		switch s.FlexBasis.Kind {
+		case layout.FlexBasisContent:
+			css.add("flex-basis", "content")
+		case layout.FlexBasisLength:
+			css.add("flex-basis", length(s.FlexBasis.Length))
+		}
+	}
+	if s.Order != 0 {
+		css.add("order", fmt.Sprint(s.Order))
+	}
+
+	// Grid container
+	if s.Display == layout.DisplayGrid {
+		if len(s.GridTemplateColumns) > 0 {
+			css.add("grid-template-columns", tracks(s.GridTemplateColumns))
+		}
+		if len(s.GridTemplateRows) > 0 {
+			css.add("grid-template-rows", tracks(s.GridTemplateRows))
+		}
+		if s.GridAutoColumns != (layout.GridTrack{}) {
+			css.add("grid-auto-columns", track(s.GridAutoColumns))
+		}
+		if s.GridAutoRows != (layout.GridTrack{}) {
+			css.add("grid-auto-rows", track(s.GridAutoRows))
+		}
+		if v, ok := gridAutoFlows[s.GridAutoFlow]; ok {
+			css.add("grid-auto-flow", v)
+		}
+		if s.GridTemplateAreas != nil {
+			css.add("grid-template-areas", templateAreas(s.GridTemplateAreas))
+		}
+		if v, ok := justifyItems[s.JustifyItems]; ok {
+			css.add("justify-items", v)
+		}
+		writeGaps(&css, s.GridGap, s.GridRowGap, s.GridColumnGap)
+	}
+
+	// Grid items
+	if v, ok := justifyItems[s.JustifySelf]; ok {
+		css.add("justify-self", v)
+	}
+	if s.GridArea != "" {
+		css.add("grid-area", s.GridArea)
+	} else {
+		if v := gridLines(s.GridRowStart, s.GridRowEnd); v != "" {
+			css.add("grid-row", v)
+		}
+		if v := gridLines(s.GridColumnStart, s.GridColumnEnd); v != "" {
+			css.add("grid-column", v)
+		}
+	}
+
+	// Other
+	if v, ok := overflows[s.Overflow]; ok {
+		css.add("overflow", v)
+	}
+	if v, ok := visibilities[s.Visibility]; ok {
+		css.add("visibility", v)
+	}
+	if v, ok := writingModes[s.WritingMode]; ok {
+		css.add("writing-mode", v)
+	}
+	if s.Transform != (layout.Transform{}) && !s.Transform.IsIdentity() {
+		// The engine applies transforms about the parent's origin
+		css.add("transform-origin", px(-n.Rect.X)+" "+px(-n.Rect.Y))
+		css.add("transform", matrix(s.Transform))
+	}
+	if s.TextStyle != nil {
+		writeFont(&css, s.TextStyle)
+		writeText(&css, s.TextStyle)
+	}
+	return css.String()
+}
+
+// writeSpacing writes the sides of sp that are set, with the side's name
+// substituted in format. A zero side is left out unless it's a margin.
+func writeSpacing(css *declarations, format string, sp layout.Spacing, margin bool) {
+	for _, side := range []struct {
+		name  string
+		value layout.Length
+	}{{"top", sp.Top}, {"right", sp.Right}, {"bottom", sp.Bottom}, {"left", sp.Left}} {
+		if side.value.Unit == layout.AutoUnit && margin {
+			css.add(fmt.Sprintf(format, side.name), "auto")
+		} else if side.value.Value != 0 {
+			css.add(fmt.Sprintf(format, side.name), length(side.value))
+		}
+	}
+}
+
+// writeGaps writes the row and column gaps, each falling back to gap when
+// zero, like the engine.
+func writeGaps(css *declarations, gap, row, column layout.Length) {
+	if row.Value == 0 {
+		row = gap
+	}
+	if column.Value == 0 {
+		column = gap
+	}
+	if row.Value != 0 {
+		css.add("row-gap", length(row))
+	}
+	if column.Value != 0 {
+		css.add("column-gap", length(column))
+	}
+}
+
+// writeFont writes the font properties of ts that are set.
+func writeFont(css *declarations, ts *layout.TextStyle) {
+	if ts.FontFamily != "" {
+		css.add("font-family", ts.FontFamily)
+	}
+	if ts.FontSize > 0 {
+		css.add("font-size", px(ts.FontSize))
+	}
+	if ts.FontWeight != 0 {
+		css.add("font-weight", fmt.Sprint(int(ts.FontWeight)))
+	}
+	switch ts.FontStyle {
+	case layout.FontStyleItalic:
+		css.add("font-style", "italic")
+	case layout.FontStyleOblique:
+		css.add("font-style", "oblique")
+	}
+}
+
+// writeText writes the line-breaking and alignment properties of ts that
+// are set.
+func writeText(css *declarations, ts *layout.TextStyle) {
+	// Below 10 is a multiple of the font size, like the engine reads it
+	switch {
+	case ts.LineHeight >= 10:
+		css.add("line-height", px(ts.LineHeight))
+	case ts.LineHeight > 0:
+		css.add("line-height", num(ts.LineHeight))
+	}
+	if v, ok := whiteSpaces[ts.WhiteSpace]; ok {
+		css.add("white-space", v)
+	}
+	if v, ok := textAligns[ts.TextAlign]; ok {
+		css.add("text-align", v)
+	}
+	if ts.LetterSpacing > 0 || ts.LetterSpacing < -1 {
+		css.add("letter-spacing", px(ts.LetterSpacing))
+	}
+	if ts.WordSpacing > 0 || ts.WordSpacing < -1 {
+		css.add("word-spacing", px(ts.WordSpacing))
+	}
+	if ts.TextIndent != 0 {
+		css.add("text-indent", px(ts.TextIndent))
+	}
+	if ts.Direction == layout.DirectionRTL {
+		css.add("direction", "rtl")
+	}
+}
+
+// isSet reports whether l isn't the zero Length, which is auto or unset
+// for offsets and minimum sizes.
+func isSet(l layout.Length) bool {
+	return l.Value != 0 || l.Unit != ""
+}
+
+// size returns the CSS for a width or height and its intrinsic sizing, or
+// "" for auto.
+func size(l layout.Length, sizing layout.IntrinsicSize) string {
+	switch sizing {
+	case layout.IntrinsicSizeMinContent:
+		return "min-content"
+	case layout.IntrinsicSizeMaxContent:
+		return "max-content"
+	case layout.IntrinsicSizeFitContent:
+		return "fit-content"
+	}
+	if !isSet(l) || l.Unit == layout.AutoUnit {
+		return ""
+	}
+	if l.Unit == "" || l.Unit == layout.Pixels {
+		switch {
+		case l.Value == layout.SizeMinContent:
+			return "min-content"
+		case l.Value == layout.SizeMaxContent:
+			return "max-content"
+		case l.Value == layout.SizeFitContent:
+			return "fit-content"
+		case l.Value < 0 || l.Value >= layout.Unbounded:
+			return ""
+		}
+	}
+	return length(l)
+}
+
+// maxSize returns the CSS for a maximum size, or "" for none.
+func maxSize(l layout.Length) string {
+	if l.Unit == layout.UnboundedUnit || l.Unit == layout.AutoUnit {
+		return ""
+	}
+	if (l.Unit == "" || l.Unit == layout.Pixels) && (l.Value <= 0 || l.Value >= layout.Unbounded) {
+		return ""
+	}
+	return length(l)
+}
+
+// length returns l as a CSS length.
+func length(l layout.Length) string {
+	switch {
+	case l.Unit == "" || l.Unit == layout.Pixels:
+		return px(l.Value)
+	case l.Unit == layout.AutoUnit:
+		return "auto"
+	case l.Unit == layout.PercentUnit:
+		return num(l.Value) + "%"
+	case strings.HasPrefix(string(l.Unit), "calc("):
+		if l.Value == 1 {
+			return string(l.Unit)
+		}
+		return fmt.Sprintf("calc(%s * %s)", num(l.Value), l.Unit)
+	default:
+		return num(l.Value) + string(l.Unit)
+	}
+}
+
+// tracks returns a grid template's track list.
+func tracks(ts []layout.GridTrack) string {
+	parts := make([]string, len(ts))
+	for i, t := range ts {
+		parts[i] = track(t)
+	}
+	return strings.Join(parts, " ")
+}
+
+// track returns a grid track's sizing function.
+func track(t layout.GridTrack) string {
+	switch {
+	case t.Fraction > 0:
+		return num(t.Fraction) + "fr"
+	case t.Fraction < 0:
+		return fmt.Sprintf("fit-content(%s)", length(t.MaxSize))
+	}
+	max := trackBreadth(t.MaxSize)
+	if t.MinSize == t.MaxSize {
+		return max
+	}
+	min := trackBreadth(t.MinSize)
+	if min == "0" && max == "auto" {
+		return "auto"
+	}
+	if min == "0" && (max == "min-content" || max == "max-content") {
+		return max
+	}
+	return fmt.Sprintf("minmax(%s, %s)", min, max)
+}
+
+// trackBreadth returns a track's minimum or maximum breadth.
+func trackBreadth(l layout.Length) string {
+	if l.Unit == "" || l.Unit == layout.Pixels {
+		switch {
+		case l.Value == layout.SizeMinContent:
+			return "min-content"
+		case l.Value == layout.SizeMaxContent:
+			return "max-content"
+		case l.Value >= layout.Unbounded:
+			return "auto"
+		}
+	}
+	if l.Unit == layout.UnboundedUnit {
+		return "auto"
+	}
+	return length(l)
+}
+
+// templateAreas returns grid-template-areas as quoted rows of area names,
+// with "." for unnamed cells.
+func templateAreas(t *layout.GridTemplateAreas) string {
+	rows := make([][]string, t.Rows)
+	for r := range rows {
+		rows[r] = make([]string, t.Cols)
+		for c := range rows[r] {
+			rows[r][c] = "."
+		}
+	}
+	for _, a := range t.Areas {
+		for r := a.RowStart; r < a.RowEnd && r < t.Rows; r++ {
+			for c := a.ColumnStart; c < a.ColumnEnd && c < t.Cols; c++ {
+				if r >= 0 && c >= 0 {
+					rows[r][c] = a.Name
+				}
+			}
+		}
+	}
+	quoted := make([]string, len(rows))
+	for r, row := range rows {
+		quoted[r] = fmt.Sprintf("%q", strings.Join(row, " "))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// gridLines returns the grid-row or grid-column value for a placement, or
+// "" for auto. The engine numbers lines from 0, CSS from 1.
+func gridLines(start, end int) string {
+	if start == 0 && end == 0 {
+		return ""
+	}
+	return gridLine(start) + " / " + gridLine(end)
+}
+
+// gridLine returns one line of a placement.
+func gridLine(line int) string {
+	switch {
+	case line == layout.GridLineAuto:
+		return "auto"
+	case line > gridSpanBase && line < 0 && line <= gridSpanBase+1<<20:
+		return fmt.Sprintf("span %d", line-gridSpanBase)
+	case line >= 0:
+		return fmt.Sprint(line + 1)
+	default:
+		return fmt.Sprint(line)
+	}
+}
+
+// Keywords for the properties written. Initial values are missing, so
+// they're left out.
+var (
+	positions = map[layout.Position]string{
+		layout.PositionRelative: "relative", layout.PositionAbsolute: "absolute",
+		layout.PositionFixed: "fixed", layout.PositionSticky: "sticky",
+	}
+	flexDirections = map[layout.FlexDirection]string{
+		layout.FlexDirectionRowReverse: "row-reverse", layout.FlexDirectionColumn: "column",
+		layout.FlexDirectionColumnReverse: "column-reverse",
+	}
+	flexWraps = map[layout.FlexWrap]string{
+		layout.FlexWrapWrap: "wrap", layout.FlexWrapWrapReverse: "wrap-reverse",
+	}
+	justifyContents = map[layout.JustifyContent]string{
+		layout.JustifyContentFlexEnd: "flex-end", layout.JustifyContentCenter: "center",
+		layout.JustifyContentSpaceBetween: "space-between", layout.JustifyContentSpaceAround: "space-around",
+		layout.JustifyContentSpaceEvenly: "space-evenly",
+	}
+	alignItems = map[layout.AlignItems]string{
+		layout.AlignItemsFlexStart: "flex-start", layout.AlignItemsFlexEnd: "flex-end",
+		layout.AlignItemsCenter: "center", layout.AlignItemsBaseline: "baseline",
+		layout.AlignItemsStretchExplicit: "stretch",
+	}
+	alignContents = map[layout.AlignContent]string{
+		layout.AlignContentFlexStart: "flex-start", layout.AlignContentFlexEnd: "flex-end",
+		layout.AlignContentCenter: "center", layout.AlignContentSpaceBetween: "space-between",
+		layout.AlignContentSpaceAround: "space-around", layout.AlignContentSpaceEvenly: "space-evenly",
+	}
+	justifyItems = map[layout.JustifyItems]string{
+		layout.JustifyItemsStart: "start", layout.JustifyItemsEnd: "end",
+		layout.JustifyItemsCenter: "center", layout.JustifyItemsStretchExplicit: "stretch",
+	}
+	gridAutoFlows = map[layout.GridAutoFlow]string{
+		layout.GridAutoFlowColumn: "column", layout.GridAutoFlowRowDense: "row dense",
+		layout.GridAutoFlowColumnDense: "column dense",
+	}
+	overflows = map[layout.Overflow]string{
+		layout.OverflowHidden: "hidden", layout.OverflowScroll: "scroll", layout.OverflowAuto: "auto",
+	}
+	visibilities = map[layout.Visibility]string{
+		layout.VisibilityVisible: "visible", layout.VisibilityHidden: "hidden",
+	}
+	writingModes = map[layout.WritingMode]string{
+		layout.WritingModeVerticalRL: "vertical-rl", layout.WritingModeVerticalLR: "vertical-lr",
+		layout.WritingModeSidewaysRL: "sideways-rl", layout.WritingModeSidewaysLR: "sideways-lr",
+	}
+	whiteSpaces = map[layout.WhiteSpace]string{
+		layout.WhiteSpaceNowrap: "nowrap", layout.WhiteSpacePre: "pre",
+		layout.WhiteSpacePreWrap: "pre-wrap", layout.WhiteSpacePreLine: "pre-line",
+	}
+	textAligns = map[layout.TextAlign]string{
+		layout.TextAlignLeft: "left", layout.TextAlignRight: "right", layout.TextAlignCenter: "center",
+		layout.TextAlignJustify: "justify", layout.TextAlignStart: "start", layout.TextAlignEnd: "end",
+	}
+)
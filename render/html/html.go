@@ -0,0 +1,297 @@
+// Package html exports laid-out node trees as HTML documents with inline
+// CSS, to compare the engine's results with a browser's.
+//
+// A document can describe a tree in two ways (see Mode). Semantic mode
+// writes each node's style as the CSS properties it stands for, so the
+// browser lays the tree out itself; Absolute mode places every box at the
+// rect the engine computed. Viewing both, or checking the browser's boxes
+// against the data-layout-rect attribute every element carries, shows
+// where the two disagree:
+//
+//	layout.Layout(root, layout.Loose(800, 600), ctx)
+//	f, _ := os.Create("semantic.html")
+//	html.Render(f, root, html.Options{Mode: html.Semantic, Outline: true})
+package html
+
+import (
+	"bufio"
+	"fmt"
+	gohtml "html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Mode selects how a document describes the tree.
+type Mode int
+
+const (
+	// Semantic writes each node's style as CSS (display: flex, grid
+	// templates, sizes, spacing and so on) and nests the elements like
+	// the nodes, so the browser computes the layout.
+	Semantic Mode = iota
+
+	// Absolute positions an element per node at the border box the
+	// engine computed, taking scroll positions and transforms into
+	// account. Elements aren't nested, so overflow isn't clipped.
+	Absolute
+)
+
+// Options controls the document Render writes.
+type Options struct {
+	// Mode selects semantic or absolute output.
+	Mode Mode
+
+	// Title is the document's title. Empty means "Layout".
+	Title string
+
+	// Outline outlines every box with a thin line that doesn't affect
+	// layout, so boxes without borders or content are visible.
+	Outline bool
+}
+
+// Render writes root's tree to w as an HTML document. The tree should be
+// laid out first: its root's size gives the viewport the elements are
+// placed in, and each element's data-layout-rect attribute holds the
+// node's border box in viewport coordinates ("x y width height"), as
+// layout computed it. Elements also carry the node's ID and Classes, and
+// data-layout-path, its child indexes from the root ("0/2").
+func Render(w io.Writer, root *layout.Node, opts Options) error {
+	bw := bufio.NewWriter(w)
+	title := opts.Title
+	if title == "" {
+		title = "Layout"
+	}
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n", gohtml.EscapeString(title))
+	bw.WriteString("body { margin: 0; }\n")
+	bw.WriteString("[data-layout-path] { box-sizing: content-box; margin: 0; padding: 0; border: 0 solid; font-size: 16px; line-height: normal; }\n")
+	if opts.Outline {
+		bw.WriteString("[data-layout-path] { outline: 1px solid rgba(0, 0, 255, 0.5); outline-offset: -1px; }\n")
+	}
+	bw.WriteString("</style>\n</head>\n<body>\n")
+
+	if root != nil {
+		fmt.Fprintf(bw, "<div data-layout-viewport style=\"position: relative; width: %s; height: %s;\">\n",
+			px(root.Rect.Width), px(root.Rect.Height))
+		if opts.Mode == Absolute {
+			writeAbsolute(bw, root)
+		} else {
+			writeSemantic(bw, root)
+		}
+		bw.WriteString("</div>\n")
+	}
+	bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// ToHTML returns the document Render writes.
+func ToHTML(root *layout.Node, opts Options) string {
+	var b strings.Builder
+	Render(&b, root, opts)
+	return b.String()
+}
+
+// entry is a node to write, with its place in the tree.
+type entry struct {
+	node   *layout.Node
+	parent *layout.Node // Nil for the root
+	path   []int
+	depth  int
+
+	// toRoot maps the node's coordinates to the viewport's
+	toRoot layout.Transform
+
+	// hidden is whether the node inherits visibility: hidden
+	hidden bool
+}
+
+// writeSemantic writes the tree as nested elements with the nodes' styles.
+func writeSemantic(w *bufio.Writer, root *layout.Node) {
+	// Nodes are written on the way down and their elements closed on the
+	// way up, so the stack holds both
+	type item struct {
+		entry
+		close bool
+	}
+	stack := []item{{entry: entry{node: root, toRoot: layout.IdentityTransform()}}}
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		indent := strings.Repeat("  ", it.depth)
+		if it.close {
+			fmt.Fprintf(w, "%s</div>\n", indent)
+			continue
+		}
+		n := it.node
+		rect := rectToRoot(it.toRoot, n)
+
+		inFlex := it.parent != nil && it.parent.Style.Display == layout.DisplayFlex
+		attrs := attributes(n, it.path, rect, StyleCSS(n, inFlex))
+		if n.Text != "" && len(n.Children) == 0 {
+			fmt.Fprintf(w, "%s<div%s>%s</div>\n", indent, attrs, gohtml.EscapeString(n.Text))
+			continue
+		}
+		fmt.Fprintf(w, "%s<div%s>\n", indent, attrs)
+		stack = append(stack, item{entry: entry{depth: it.depth}, close: true})
+		for i := len(n.Children) - 1; i >= 0; i-- {
+			child := n.Children[i]
+			stack = append(stack, item{entry: entry{
+				node:   child,
+				parent: n,
+				path:   append(append([]int(nil), it.path...), i),
+				depth:  it.depth + 1,
+				toRoot: childToRoot(it.toRoot, n, child),
+			}})
+		}
+	}
+}
+
+// writeAbsolute writes an element per node, in tree order, positioned at
+// the node's rect, followed by its text's lines.
+func writeAbsolute(w *bufio.Writer, root *layout.Node) {
+	stack := []entry{{node: root, toRoot: layout.IdentityTransform()}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n := e.node
+		if n.Style.Display == layout.DisplayNone {
+			continue
+		}
+		switch n.Style.Visibility {
+		case layout.VisibilityHidden:
+			e.hidden = true
+		case layout.VisibilityVisible:
+			e.hidden = false
+		}
+
+		var css declarations
+		place(&css, e.toRoot)
+		css.add("box-sizing", "border-box")
+		css.add("width", px(n.Rect.Width))
+		css.add("height", px(n.Rect.Height))
+		writeSpacing(&css, "border-%s-width", n.Style.Border, false)
+		if e.hidden {
+			css.add("visibility", "hidden")
+		}
+		fmt.Fprintf(w, "<div%s></div>\n", attributes(n, e.path, rectToRoot(e.toRoot, n), css.String()))
+		if n.TextLayout != nil {
+			writeTextLines(w, n.TextLayout, e.toRoot, e.hidden)
+		}
+
+		for i := len(n.Children) - 1; i >= 0; i-- {
+			child := n.Children[i]
+			stack = append(stack, entry{
+				node:   child,
+				path:   append(append([]int(nil), e.path...), i),
+				toRoot: childToRoot(e.toRoot, n, child),
+				hidden: e.hidden,
+			})
+		}
+	}
+}
+
+// writeTextLines writes the boxes of a text layout's lines as spans at
+// their computed offsets, given the transform from the node to the
+// viewport.
+func writeTextLines(w *bufio.Writer, tl *layout.TextLayout, toRoot layout.Transform, hidden bool) {
+	for i, line := range tl.Lines {
+		style := tl.Style
+		if i == 0 && tl.FirstLineStyle != nil {
+			style = *tl.FirstLineStyle
+		}
+		// Boxes are words, separated by the line's spaces (see tuirender)
+		space := line.SpaceAdjustment
+		if line.SpaceCount > 0 {
+			space += line.SpaceWidth / float64(line.SpaceCount)
+		}
+		x := tl.ContentX + line.OffsetX
+		for _, box := range line.Boxes {
+			var css declarations
+			place(&css, toRoot.Multiply(layout.Translate(x, tl.ContentY+line.OffsetY)))
+			css.add("height", px(line.Height))
+			css.add("line-height", px(line.Height))
+			css.add("white-space", "pre")
+			writeFont(&css, &style)
+			if hidden {
+				css.add("visibility", "hidden")
+			}
+			fmt.Fprintf(w, "<span style=\"%s\">%s</span>\n", gohtml.EscapeString(css.String()), gohtml.EscapeString(box.VisualText()))
+			x += box.Width + space
+		}
+	}
+}
+
+// place positions an absolute element at the origin of the coordinates
+// toRoot maps to the viewport's, with left and top when it's a
+// translation and a transform otherwise.
+func place(css *declarations, toRoot layout.Transform) {
+	css.add("position", "absolute")
+	t := toRoot
+	if t.A == 1 && t.B == 0 && t.C == 0 && t.D == 1 {
+		css.add("left", px(t.E))
+		css.add("top", px(t.F))
+		return
+	}
+	css.add("left", "0")
+	css.add("top", "0")
+	css.add("transform-origin", "0 0")
+	css.add("transform", matrix(t))
+}
+
+// attributes returns the attributes of n's element, with a leading space.
+func attributes(n *layout.Node, path []int, rect layout.Rect, style string) string {
+	var b strings.Builder
+	if n.ID != "" {
+		fmt.Fprintf(&b, " id=\"%s\"", gohtml.EscapeString(n.ID))
+	}
+	if len(n.Classes) > 0 {
+		fmt.Fprintf(&b, " class=\"%s\"", gohtml.EscapeString(strings.Join(n.Classes, " ")))
+	}
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = strconv.Itoa(p)
+	}
+	fmt.Fprintf(&b, " data-layout-path=\"%s\"", strings.Join(parts, "/"))
+	fmt.Fprintf(&b, " data-layout-rect=\"%s %s %s %s\"", num(rect.X), num(rect.Y), num(rect.Width), num(rect.Height))
+	if style != "" {
+		fmt.Fprintf(&b, " style=\"%s\"", gohtml.EscapeString(style))
+	}
+	return b.String()
+}
+
+// childToRoot returns the transform from child's coordinates to the
+// viewport's, given its parent's, like layout does: the parent's scroll
+// position, then the child's transform, then its offset.
+func childToRoot(parentToRoot layout.Transform, parent, child *layout.Node) layout.Transform {
+	t := parentToRoot.Multiply(layout.Translate(-parent.Scroll.X, -parent.Scroll.Y))
+	if child.Style.Transform != (layout.Transform{}) {
+		t = t.Multiply(child.Style.Transform)
+	}
+	return t.Multiply(layout.Translate(child.Rect.X, child.Rect.Y))
+}
+
+// rectToRoot returns the bounding box of n's border box in the viewport,
+// given the transform to it.
+func rectToRoot(toRoot layout.Transform, n *layout.Node) layout.Rect {
+	return toRoot.ApplyToRect(layout.Rect{Width: n.Rect.Width, Height: n.Rect.Height})
+}
+
+// matrix returns t as a CSS matrix().
+func matrix(t layout.Transform) string {
+	return fmt.Sprintf("matrix(%s, %s, %s, %s, %s, %s)", num(t.A), num(t.B), num(t.C), num(t.D), num(t.E), num(t.F))
+}
+
+// num formats v without an exponent or trailing zeros.
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// px formats v as a pixel length.
+func px(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+	return num(v) + "px"
+}
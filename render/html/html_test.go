@@ -0,0 +1,136 @@
+package html
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func laidOut(root *layout.Node) *layout.Node {
+	layout.Layout(root, layout.Loose(400, 300), layout.NewLayoutContext(400, 300, 16))
+	return root
+}
+
+func TestSemanticMode(t *testing.T) {
+	item := layout.Fixed(100, 50)
+	item.ID = "first"
+	item.Classes = []string{"card", "wide"}
+	item.Style.FlexGrow = 1
+	item.Style.Padding = layout.Uniform(layout.Px(4))
+	row := layout.HStack(item, layout.Fixed(80, 40))
+	row.Style.JustifyContent = layout.JustifyContentSpaceBetween
+	laidOut(row)
+
+	got := ToHTML(row, Options{Title: "Row <1>"})
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Row &lt;1&gt;</title>",
+		`<div data-layout-viewport style="position: relative; width: 400px; height: 300px;">`,
+		`data-layout-path="" data-layout-rect="0 0 400 300" style="display: flex; `,
+		"justify-content: space-between;",
+		`<div id="first" class="card wide" data-layout-path="0" data-layout-rect="0 0 `,
+		"width: 100px; height: 50px;",
+		"padding-top: 4px;",
+		"flex-grow: 1; flex-shrink: 0;",
+		`data-layout-path="1"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "<div") != strings.Count(got, "</div>") {
+		t.Errorf("Expected balanced elements in:\n%s", got)
+	}
+}
+
+func TestSemanticGridAndText(t *testing.T) {
+	cell := layout.Text("a < b", layout.Style{TextStyle: &layout.TextStyle{FontSize: 12}})
+	cell.Style.GridColumnStart, cell.Style.GridColumnEnd = 1, layout.Span(2)
+	grid := &layout.Node{
+		Style: layout.Style{
+			Display:             layout.DisplayGrid,
+			GridTemplateColumns: []layout.GridTrack{layout.FixedTrack(layout.Px(50)), layout.FractionTrack(1), layout.AutoTrack()},
+			GridGap:             layout.Px(8),
+		},
+		Children: []*layout.Node{cell},
+	}
+	laidOut(grid)
+
+	got := ToHTML(grid, Options{})
+	for _, want := range []string{
+		"grid-template-columns: 50px 1fr auto;",
+		"row-gap: 8px; column-gap: 8px;",
+		"grid-column: 2 / span 2;",
+		"font-size: 12px;",
+		">a &lt; b</div>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestAbsoluteMode(t *testing.T) {
+	inner := layout.Fixed(20, 10)
+	box := &layout.Node{
+		Style:    layout.Style{Width: layout.Px(100), Height: layout.Px(60), Padding: layout.Uniform(layout.Px(5)), Border: layout.Uniform(layout.Px(2))},
+		Children: []*layout.Node{inner},
+	}
+	rotated := layout.Fixed(10, 10)
+	rotated.Style.Transform = layout.Scale(2, 2)
+	root := layout.VStack(layout.Fixed(100, 30), box, rotated)
+	laidOut(root)
+	box.Style.Visibility = layout.VisibilityHidden
+
+	got := ToHTML(root, Options{Mode: Absolute, Outline: true})
+
+	for _, want := range []string{
+		"outline: 1px solid",
+		`data-layout-path="1" data-layout-rect="0 30 114 74"`,
+		"position: absolute; left: 0; top: 30px; box-sizing: border-box; width: 114px; height: 74px; border-top-width: 2px;",
+		`data-layout-path="1/0" data-layout-rect="7 37 20 10" style="position: absolute; left: 7px; top: 37px;`,
+		"transform: matrix(2, 0, 0, 2, 0, 208);",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+	// The hidden box's child inherits its visibility
+	if n := strings.Count(got, "visibility: hidden;"); n != 2 {
+		t.Errorf("Expected the box and its child hidden, got %d hidden elements in:\n%s", n, got)
+	}
+	if n := strings.Count(got, "<div data-layout-path"); n != 5 {
+		t.Errorf("Expected an element per node, got %d in:\n%s", n, got)
+	}
+}
+
+func TestAbsoluteModeText(t *testing.T) {
+	text := layout.Text("hello world", layout.Style{
+		Width:     layout.Px(40),
+		Padding:   layout.Uniform(layout.Px(3)),
+		TextStyle: &layout.TextStyle{FontSize: 10, LineHeight: 12},
+	})
+	root := layout.VStack(text)
+	laidOut(root)
+
+	got := ToHTML(root, Options{Mode: Absolute})
+	boxes := 0
+	for _, line := range text.TextLayout.Lines {
+		boxes += len(line.Boxes)
+	}
+	if n := strings.Count(got, "<span"); n != boxes || n != 2 {
+		t.Errorf("Expected a span per word, got %d for %d boxes in:\n%s", n, boxes, got)
+	}
+	if !strings.Contains(got, "left: 3px; top: 3px; height: 12px; line-height: 12px; white-space: pre; font-size: 10px;\">hello</span>") {
+		t.Errorf("Expected the first word at the content origin in:\n%s", got)
+	}
+}
+
+func TestRenderNil(t *testing.T) {
+	got := ToHTML(nil, Options{})
+	if !strings.Contains(got, "<body>\n</body>") {
+		t.Errorf("Expected an empty body, got:\n%s", got)
+	}
+}
@@ -0,0 +1,284 @@
+// Package pdf draws laid-out node trees as pages of a PDF document.
+//
+// The package doesn't write PDF files itself. Render splits the tree into
+// pages with layout.Paginate, converts pixels to points, and passes the
+// pages, boxes and words to a Document, which draws them with a PDF
+// library such as gofpdf or pdfcpu:
+//
+//	opts := pdf.Options{Margin: 36}
+//	layout.Layout(root, layout.Loose(pdf.PageWidth(opts), layout.Unbounded), ctx)
+//	err := pdf.Render(doc, root, ctx, opts)
+//
+// Coordinates are in points with the origin at the top left of the page and
+// y growing down, as gofpdf uses them. Adapters for libraries with the PDF
+// origin at the bottom left subtract y from the page height.
+package pdf
+
+import (
+	"github.com/SCKelemen/layout"
+)
+
+// PointsPerPixel is the default scale: CSS pixels are 1/96 inch and PDF
+// points 1/72 inch.
+const PointsPerPixel = 72.0 / 96.0
+
+// A4 page size in points, the default.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document is the PDF backend Render draws through. Methods are called in
+// page order: AddPage, then the page's boxes and text in tree order, so
+// later calls paint over earlier ones. An error stops Render and is
+// returned from it.
+type Document interface {
+	// AddPage starts a new page of the given size in points.
+	AddPage(width, height float64) error
+
+	// DrawBox draws the part of a node's box that's on the current page,
+	// such as its background and borders.
+	DrawBox(box Box) error
+
+	// DrawText draws a word of a text node's lines.
+	DrawText(run TextRun) error
+}
+
+// Edges holds a width for each side of a box, in points.
+type Edges struct {
+	Top, Right, Bottom, Left float64
+}
+
+// Box is the part of a node's border box on a page.
+type Box struct {
+	Node *layout.Node
+
+	// Rect is the part of the border box on the page, in points.
+	Rect layout.Rect
+
+	// Border is the node's border widths, in points. Edges the page cuts
+	// through have no border: Top is 0 when the box started on an earlier
+	// page and Bottom when it goes on to a later one (CSS
+	// box-decoration-break: slice).
+	Border Edges
+
+	// Continued reports that the box started on an earlier page, and
+	// Continues that it goes on to a later page.
+	Continued bool
+	Continues bool
+}
+
+// TextRun is a word of a text node's line, positioned at its baseline.
+type TextRun struct {
+	Node *layout.Node
+
+	// X and Y are the start of the word's baseline on the page, in
+	// points, and Width its advance.
+	X, Y  float64
+	Width float64
+
+	// Text is the word in display order (see layout.InlineBox.VisualText).
+	Text string
+
+	// Style is the line's computed text style, and FontSize its font size
+	// in points.
+	Style    layout.TextStyle
+	FontSize float64
+}
+
+// Options controls how Render pages the tree.
+type Options struct {
+	// PageWidth and PageHeight are the page size in points. Zero means A4.
+	PageWidth  float64
+	PageHeight float64
+
+	// Margin is the blank space around each page's content, in points.
+	Margin float64
+
+	// Scale is the number of points per layout pixel. Zero means
+	// PointsPerPixel.
+	Scale float64
+}
+
+// withDefaults returns opts with zero values replaced by their defaults.
+func (opts Options) withDefaults() Options {
+	if opts.PageWidth <= 0 {
+		opts.PageWidth = A4Width
+	}
+	if opts.PageHeight <= 0 {
+		opts.PageHeight = A4Height
+	}
+	if opts.Scale <= 0 {
+		opts.Scale = PointsPerPixel
+	}
+	return opts
+}
+
+// PageWidth returns the width of the pages' content area in layout pixels,
+// the width to lay the tree out in.
+func PageWidth(opts Options) float64 {
+	opts = opts.withDefaults()
+	return (opts.PageWidth - 2*opts.Margin) / opts.Scale
+}
+
+// PageHeight returns the height of the pages' content area in layout
+// pixels, the height Render paginates the tree at.
+func PageHeight(opts Options) float64 {
+	opts = opts.withDefaults()
+	return (opts.PageHeight - 2*opts.Margin) / opts.Scale
+}
+
+// Render draws root, which must already be laid out, into doc, a page at a
+// time. The tree is broken into pages with layout.Paginate, so page breaks
+// honor BreakBefore, BreakAfter and BreakInside and fall between lines of
+// text. Each page gets a Box for every node on it, followed by the words
+// of its text lines that start on the page. Nodes hidden with
+// VisibilityHidden are left out, but their visible descendants aren't.
+//
+// Transforms, scroll positions and overflow clipping aren't applied, and
+// only horizontal text is drawn.
+func Render(doc Document, root *layout.Node, ctx *layout.LayoutContext, opts Options) error {
+	if root == nil {
+		return nil
+	}
+	opts = opts.withDefaults()
+	hidden := hiddenNodes(root)
+	p := painter{doc: doc, ctx: ctx, scale: opts.Scale, margin: opts.Margin}
+
+	for _, page := range layout.Paginate(root, PageHeight(opts), ctx) {
+		if err := doc.AddPage(opts.PageWidth, opts.PageHeight); err != nil {
+			return err
+		}
+		for _, f := range page.Fragments {
+			if hidden[f.Node] {
+				continue
+			}
+			if err := p.box(f); err != nil {
+				return err
+			}
+			if err := p.text(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// painter converts fragments to a document's boxes and text runs.
+type painter struct {
+	doc    Document
+	ctx    *layout.LayoutContext
+	scale  float64
+	margin float64
+}
+
+// point converts a position on the page in pixels to points.
+func (p *painter) point(x, y float64) (float64, float64) {
+	return p.margin + x*p.scale, p.margin + y*p.scale
+}
+
+// box draws f's box.
+func (p *painter) box(f layout.Fragment) error {
+	n := f.Node
+	fontSize := fontSizeOf(n, p.ctx)
+	x, y := p.point(f.Rect.X, f.Rect.Y)
+	box := Box{
+		Node: n,
+		Rect: layout.Rect{X: x, Y: y, Width: f.Rect.Width * p.scale, Height: f.Rect.Height * p.scale},
+		Border: Edges{
+			Top:    layout.ResolveLength(n.Style.Border.Top, p.ctx, fontSize) * p.scale,
+			Right:  layout.ResolveLength(n.Style.Border.Right, p.ctx, fontSize) * p.scale,
+			Bottom: layout.ResolveLength(n.Style.Border.Bottom, p.ctx, fontSize) * p.scale,
+			Left:   layout.ResolveLength(n.Style.Border.Left, p.ctx, fontSize) * p.scale,
+		},
+		Continued: f.Continued,
+		Continues: f.Continues,
+	}
+	if f.Continued {
+		box.Border.Top = 0
+	}
+	if f.Continues {
+		box.Border.Bottom = 0
+	}
+	return p.doc.DrawBox(box)
+}
+
+// text draws the words of the lines of f's node that start on its page.
+// Lines that start on an earlier page were drawn there; a line taller than
+// the page is only drawn once.
+func (p *painter) text(f layout.Fragment) error {
+	tl := f.Node.TextLayout
+	if tl == nil || f.Node.Style.WritingMode.IsVertical() {
+		return nil
+	}
+	// The node's origin on the page, which is above it when it started on
+	// an earlier page
+	originX, originY := f.Rect.X, f.Rect.Y-f.Offset
+	for i, line := range tl.Lines {
+		top := tl.ContentY + line.OffsetY
+		if top < f.Offset || (f.Continues && top >= f.Offset+f.Rect.Height) {
+			continue
+		}
+		style := tl.Style
+		if i == 0 && tl.FirstLineStyle != nil {
+			style = *tl.FirstLineStyle
+		}
+		// Boxes are words, separated by the line's spaces
+		space := line.SpaceAdjustment
+		if line.SpaceCount > 0 {
+			space += line.SpaceWidth / float64(line.SpaceCount)
+		}
+		x := originX + tl.ContentX + line.OffsetX
+		for _, box := range line.Boxes {
+			rx, ry := p.point(x, originY+top+line.Baseline)
+			run := TextRun{
+				Node:     f.Node,
+				X:        rx,
+				Y:        ry,
+				Width:    box.Width * p.scale,
+				Text:     box.VisualText(),
+				Style:    style,
+				FontSize: style.FontSize * p.scale,
+			}
+			if err := p.doc.DrawText(run); err != nil {
+				return err
+			}
+			x += box.Width + space
+		}
+	}
+	return nil
+}
+
+// hiddenNodes returns the nodes of root's tree that aren't painted because
+// of their visibility.
+func hiddenNodes(root *layout.Node) map[*layout.Node]bool {
+	type entry struct {
+		node          *layout.Node
+		parentVisible bool
+	}
+	hidden := map[*layout.Node]bool{}
+	stack := []entry{{root, true}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		visible := e.node.Style.Visibility.Visible(e.parentVisible)
+		if !visible {
+			hidden[e.node] = true
+		}
+		for _, child := range e.node.Children {
+			stack = append(stack, entry{child, visible})
+		}
+	}
+	return hidden
+}
+
+// fontSizeOf returns the font size em lengths of n resolve against.
+func fontSizeOf(n *layout.Node, ctx *layout.LayoutContext) float64 {
+	if n.TextLayout != nil && n.TextLayout.Style.FontSize > 0 {
+		return n.TextLayout.Style.FontSize
+	}
+	if ctx != nil && ctx.RootFontSize > 0 {
+		return ctx.RootFontSize
+	}
+	return 16
+}
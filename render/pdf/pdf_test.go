@@ -0,0 +1,166 @@
+package pdf
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// recorder is a Document that records what it's asked to draw.
+type recorder struct {
+	pages [][]any
+	err   error
+}
+
+func (r *recorder) AddPage(width, height float64) error {
+	r.pages = append(r.pages, []any{layout.Size{Width: width, Height: height}})
+	return r.err
+}
+
+func (r *recorder) DrawBox(box Box) error {
+	r.pages[len(r.pages)-1] = append(r.pages[len(r.pages)-1], box)
+	return nil
+}
+
+func (r *recorder) DrawText(run TextRun) error {
+	r.pages[len(r.pages)-1] = append(r.pages[len(r.pages)-1], run)
+	return nil
+}
+
+func (r *recorder) boxes(page int) []Box {
+	var boxes []Box
+	for _, item := range r.pages[page] {
+		if b, ok := item.(Box); ok {
+			boxes = append(boxes, b)
+		}
+	}
+	return boxes
+}
+
+func (r *recorder) runs(page int) []TextRun {
+	var runs []TextRun
+	for _, item := range r.pages[page] {
+		if run, ok := item.(TextRun); ok {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestRenderPages(t *testing.T) {
+	// A 400×400pt page with a 20pt margin holds 480px at 0.75pt/px
+	opts := Options{PageWidth: 400, PageHeight: 400, Margin: 20}
+	if w, h := PageWidth(opts), PageHeight(opts); !near(w, 480) || !near(h, 480) {
+		t.Fatalf("Expected a 480×480px content area, got %v×%v", w, h)
+	}
+
+	bordered := layout.Fixed(100, 300)
+	bordered.Style.Border = layout.Uniform(layout.Px(4))
+	root := layout.VStack(layout.Fixed(100, 300), bordered)
+	ctx := layout.NewLayoutContext(480, 480, 16)
+	layout.Layout(root, layout.Loose(PageWidth(opts), layout.Unbounded), ctx)
+
+	var doc recorder
+	if err := Render(&doc, root, ctx, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(doc.pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(doc.pages))
+	}
+	if size := doc.pages[0][0].(layout.Size); size.Width != 400 || size.Height != 400 {
+		t.Errorf("Expected 400×400pt pages, got %v", size)
+	}
+
+	// The second box breaks to the next page rather than being split
+	first := doc.boxes(0)
+	if len(first) != 2 {
+		t.Fatalf("Expected the root and first box on page 1, got %d boxes", len(first))
+	}
+	if r := first[1].Rect; !near(r.X, 20) || !near(r.Y, 20) || !near(r.Width, 75) || !near(r.Height, 225) {
+		t.Errorf("Expected the first box at (20, 20) 75×225pt, got %+v", r)
+	}
+	second := doc.boxes(1)
+	last := second[len(second)-1]
+	if last.Node != bordered {
+		t.Fatalf("Expected the bordered box on page 2, got %+v", last)
+	}
+	if r := last.Rect; !near(r.Y, 20) || !near(r.Height, 231) {
+		t.Errorf("Expected the bordered box at the top of page 2, got %+v", r)
+	}
+	if b := last.Border; !near(b.Top, 3) || !near(b.Left, 3) {
+		t.Errorf("Expected 3pt borders, got %+v", b)
+	}
+	if !second[0].Continued || second[0].Continues {
+		t.Errorf("Expected the root to continue from page 1, got %+v", second[0])
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	style := layout.Style{Width: layout.Px(100), TextStyle: &layout.TextStyle{FontSize: 16, LineHeight: 100}}
+	text := layout.Text("one two three four", style)
+	root := layout.VStack(text)
+	ctx := layout.NewLayoutContext(100, 1000, 16)
+	layout.Layout(root, layout.Loose(100, layout.Unbounded), ctx)
+	lines := text.TextLayout.Lines
+	if len(lines) < 2 {
+		t.Fatalf("Expected the text to wrap, got %d lines", len(lines))
+	}
+
+	// Pages of 150px hold one 100px line each
+	opts := Options{PageWidth: 200, PageHeight: 150 * PointsPerPixel}
+	var doc recorder
+	if err := Render(&doc, root, ctx, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(doc.pages) != len(lines) {
+		t.Fatalf("Expected a page per line, got %d pages for %d lines", len(doc.pages), len(lines))
+	}
+	for i, line := range lines {
+		runs := doc.runs(i)
+		if len(runs) != len(line.Boxes) {
+			t.Fatalf("Expected %d words on page %d, got %d", len(line.Boxes), i+1, len(runs))
+		}
+		if want := line.Baseline * PointsPerPixel; !near(runs[0].Y, want) {
+			t.Errorf("Expected page %d's baseline at %v, got %v", i+1, want, runs[0].Y)
+		}
+		if runs[0].Text != line.Boxes[0].Text || !near(runs[0].FontSize, 12) {
+			t.Errorf("Expected %q at 12pt, got %q at %v", line.Boxes[0].Text, runs[0].Text, runs[0].FontSize)
+		}
+		if len(runs) > 1 && runs[1].X <= runs[0].X+runs[0].Width {
+			t.Errorf("Expected a space between words, got %+v", runs[:2])
+		}
+	}
+}
+
+func TestRenderSkipsHidden(t *testing.T) {
+	visible := layout.Fixed(10, 10)
+	visible.Style.Visibility = layout.VisibilityVisible
+	hidden := layout.VStack(layout.Fixed(10, 10), visible)
+	hidden.Style.Visibility = layout.VisibilityHidden
+	root := layout.VStack(hidden)
+	ctx := layout.NewLayoutContext(100, 100, 16)
+	layout.Layout(root, layout.Loose(100, layout.Unbounded), ctx)
+
+	var doc recorder
+	Render(&doc, root, ctx, Options{})
+	boxes := doc.boxes(0)
+	if len(boxes) != 2 || boxes[0].Node != root || boxes[1].Node != visible {
+		t.Errorf("Expected the root and the visible box, got %d boxes", len(boxes))
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	root := layout.Fixed(10, 10)
+	layout.Layout(root, layout.Loose(100, 100), layout.NewLayoutContext(100, 100, 16))
+	want := errors.New("out of paper")
+	doc := recorder{err: want}
+	if err := Render(&doc, root, nil, Options{}); err != want {
+		t.Errorf("Expected %v, got %v", want, err)
+	}
+}
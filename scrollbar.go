@@ -0,0 +1,126 @@
+package layout
+
+import "math"
+
+// Scrollbar gutters.
+//
+// With LayoutContext.ScrollbarWidth set, a scroll container sets aside room
+// for its scrollbars between its border and its padding, as a browser does
+// with classic scrollbars: a vertical scrollbar on the right and a
+// horizontal one at the bottom. The room comes out of the content box, so a
+// box with a fixed size keeps it and its content gets narrower or shorter.
+// An auto-sized box grows by the scrollbar instead when its content decides
+// its size.
+//
+// OverflowScroll always shows both scrollbars. OverflowAuto shows each one
+// only when the content overflows that way: the box is laid out without
+// them, then again with the ones it needs. OverflowHidden shows none.
+// Style.ScrollbarGutter keeps the vertical scrollbar's room whether or not
+// it's shown, so content doesn't move when it appears.
+//
+// See: https://www.w3.org/TR/css-overflow-3/#scrollbar-gutter-property
+
+// scrollbarGutters is the room a scroll container set aside for its
+// scrollbars, and which ones it shows.
+type scrollbarGutters struct {
+	left, right, bottom  float64
+	vertical, horizontal bool
+}
+
+// ScrollbarGutters returns the room n's last layout set aside for
+// scrollbars on its left, right and bottom, inside its border. It's zero
+// unless n is a scroll container and LayoutContext.ScrollbarWidth is set.
+func (n *Node) ScrollbarGutters() (left, right, bottom float64) {
+	return n.gutters.left, n.gutters.right, n.gutters.bottom
+}
+
+// ShowsScrollbars reports whether n's last layout showed a vertical and a
+// horizontal scrollbar. Renderers draw them in the gutters reported by
+// ScrollbarGutters.
+func (n *Node) ShowsScrollbars() (vertical, horizontal bool) {
+	return n.gutters.vertical, n.gutters.horizontal
+}
+
+// layoutWithScrollbars lays out the scroll container node with room for
+// the scrollbars it shows.
+func layoutWithScrollbars(node *Node, constraints Constraints, ctx *LayoutContext) Size {
+	scroll := node.Style.Overflow == OverflowScroll
+	size := layoutWithGutters(node, constraints, ctx, scroll, scroll)
+	if node.Style.Overflow != OverflowAuto {
+		return size
+	}
+
+	// Showing one scrollbar shrinks the scrollport, which can make the
+	// content overflow the other way too
+	vertical, horizontal := false, false
+	for range 2 {
+		limit := node.MaxScroll(ctx)
+		v, h := vertical || limit.Y > 0, horizontal || limit.X > 0
+		if v == vertical && h == horizontal {
+			break
+		}
+		vertical, horizontal = v, h
+		size = layoutWithGutters(node, constraints, ctx, vertical, horizontal)
+	}
+	return size
+}
+
+// layoutWithGutters lays out node with room for the given scrollbars, and
+// for the gutters its Style.ScrollbarGutter keeps, by widening its border
+// for the duration of the layout.
+func layoutWithGutters(node *Node, constraints Constraints, ctx *LayoutContext, vertical, horizontal bool) Size {
+	thickness := ctx.ScrollbarWidth
+	g := scrollbarGutters{vertical: vertical, horizontal: horizontal}
+	if vertical || node.Style.ScrollbarGutter != ScrollbarGutterAuto {
+		g.right = thickness
+	}
+	if node.Style.ScrollbarGutter == ScrollbarGutterStableBothEdges {
+		g.left = thickness
+	}
+	if horizontal {
+		g.bottom = thickness
+	}
+	node.gutters = g
+	if g.left == 0 && g.right == 0 && g.bottom == 0 {
+		return layoutAlgorithm(node, constraints, ctx)
+	}
+
+	s := &node.Style
+	border := s.Border
+	width, minWidth, maxWidth := s.Width, s.MinWidth, s.MaxWidth
+	height, minHeight, maxHeight := s.Height, s.MinHeight, s.MaxHeight
+	defer func() {
+		s.Border = border
+		s.Width, s.MinWidth, s.MaxWidth = width, minWidth, maxWidth
+		s.Height, s.MinHeight, s.MaxHeight = height, minHeight, maxHeight
+	}()
+
+	fontSize := getCurrentFontSize(node, ctx)
+	s.Border.Left = Px(ResolveLength(border.Left, ctx, fontSize) + g.left)
+	s.Border.Right = Px(ResolveLength(border.Right, ctx, fontSize) + g.right)
+	s.Border.Bottom = Px(ResolveLength(border.Bottom, ctx, fontSize) + g.bottom)
+	if s.BoxSizing == BoxSizingContentBox {
+		// Sizes are of the content box, which the scrollbars come out of
+		across, down := g.left+g.right, g.bottom
+		s.Width = shrinkSize(width, across, ctx, fontSize)
+		s.MinWidth = shrinkSize(minWidth, across, ctx, fontSize)
+		s.MaxWidth = shrinkSize(maxWidth, across, ctx, fontSize)
+		s.Height = shrinkSize(height, down, ctx, fontSize)
+		s.MinHeight = shrinkSize(minHeight, down, ctx, fontSize)
+		s.MaxHeight = shrinkSize(maxHeight, down, ctx, fontSize)
+	}
+	return layoutAlgorithm(node, constraints, ctx)
+}
+
+// shrinkSize returns the size l less by, leaving unset and auto sizes as
+// they are.
+func shrinkSize(l Length, by float64, ctx *LayoutContext, fontSize float64) Length {
+	if by == 0 || l.Unit == "" || l.Unit == AutoUnit {
+		return l
+	}
+	v := ResolveLength(l, ctx, fontSize)
+	if v < 0 {
+		return l
+	}
+	return Px(math.Max(0, v-by))
+}
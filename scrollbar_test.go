@@ -0,0 +1,76 @@
+package layout
+
+import "testing"
+
+func TestScrollbarGutters(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+	ctx.ScrollbarWidth = 15
+
+	// overflow: scroll always shows both scrollbars; a content-box size
+	// keeps the box's size and narrows the content
+	child := &Node{Style: Style{Width: Px(-1), Height: Px(50)}}
+	pane := &Node{
+		Style:    Style{Width: Px(200), Height: Px(100), Padding: Uniform(Px(10)), Overflow: OverflowScroll},
+		Children: []*Node{child},
+	}
+	Layout(pane, Loose(800, 600), ctx)
+	if pane.Rect.Width != 220 || pane.Rect.Height != 120 {
+		t.Errorf("Expected the pane to stay 220x120, got %vx%v", pane.Rect.Width, pane.Rect.Height)
+	}
+	if child.Rect.X != 10 || child.Rect.Width != 185 {
+		t.Errorf("Expected the child at x=10, 185 wide, got %v and %v", child.Rect.X, child.Rect.Width)
+	}
+	if left, right, bottom := pane.ScrollbarGutters(); left != 0 || right != 15 || bottom != 15 {
+		t.Errorf("Expected gutters 0, 15 and 15, got %v, %v and %v", left, right, bottom)
+	}
+	if clip, _ := pane.OverflowClip(ctx); clip != (Rect{Width: 205, Height: 105}) {
+		t.Errorf("Expected the clip to exclude the scrollbars, got %+v", clip)
+	}
+
+	// overflow: auto shows a scrollbar only once the content overflows
+	tests := []struct {
+		name         string
+		content      float64
+		gutter       ScrollbarGutter
+		overflow     Overflow
+		width        float64
+		vertical     bool
+		left, right  float64
+		borderBox    bool
+		paneWidth    float64
+		contentStart float64
+	}{
+		{"auto, fits", 50, ScrollbarGutterAuto, OverflowAuto, 200, false, 0, 0, false, 200, 0},
+		{"auto, overflows", 300, ScrollbarGutterAuto, OverflowAuto, 185, true, 0, 15, false, 200, 0},
+		{"stable", 50, ScrollbarGutterStable, OverflowAuto, 185, false, 0, 15, false, 200, 0},
+		{"stable both-edges", 50, ScrollbarGutterStableBothEdges, OverflowHidden, 170, false, 15, 15, false, 200, 15},
+		{"border-box", 300, ScrollbarGutterAuto, OverflowScroll, 185, true, 0, 15, true, 200, 0},
+	}
+	for _, tt := range tests {
+		child := &Node{Style: Style{Width: Px(-1), Height: Px(tt.content)}}
+		pane := &Node{
+			Style:    Style{Width: Px(200), Height: Px(100), Overflow: tt.overflow, ScrollbarGutter: tt.gutter},
+			Children: []*Node{child},
+		}
+		if tt.borderBox {
+			pane.Style.BoxSizing = BoxSizingBorderBox
+		}
+		Layout(pane, Loose(800, 600), ctx)
+
+		if pane.Rect.Width != tt.paneWidth {
+			t.Errorf("%s: expected the pane %v wide, got %v", tt.name, tt.paneWidth, pane.Rect.Width)
+		}
+		if child.Rect.X != tt.contentStart || child.Rect.Width != tt.width {
+			t.Errorf("%s: expected the content at x=%v, %v wide, got %v and %v", tt.name, tt.contentStart, tt.width, child.Rect.X, child.Rect.Width)
+		}
+		if vertical, _ := pane.ShowsScrollbars(); vertical != tt.vertical {
+			t.Errorf("%s: expected a vertical scrollbar: %v, got %v", tt.name, tt.vertical, vertical)
+		}
+		if left, right, _ := pane.ScrollbarGutters(); left != tt.left || right != tt.right {
+			t.Errorf("%s: expected gutters %v and %v, got %v and %v", tt.name, tt.left, tt.right, left, right)
+		}
+		if pane.Style.Border != (Spacing{}) {
+			t.Errorf("%s: expected the style to be restored, got border %+v", tt.name, pane.Style.Border)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package layout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selector is a parsed CSS selector, matched against a node's Tag, ID,
+// Classes and Attributes.
+//
+// Supported syntax:
+//
+//   - type selectors (div) and the universal selector (*)
+//   - id (#main) and class (.card) selectors
+//   - attribute selectors: [name], [name=value], [name~=value],
+//     [name|=value], [name^=value], [name$=value], [name*=value], with an
+//     optional i flag for case-insensitive values. [id] and [class] match
+//     the ID and Classes fields
+//   - :first-child, :last-child, :only-child, :nth-child(An+B) and
+//     :nth-last-child(An+B), including odd and even
+//   - descendant (A B) and child (A > B) combinators
+//
+// Simple selectors can be chained, as in div.card[data-state=open].
+//
+// See: https://www.w3.org/TR/selectors-4/
+type Selector struct {
+	source string
+
+	// compounds are the compound selectors from left to right, and
+	// combinators[i] joins compounds[i] and compounds[i+1].
+	compounds   []compoundSelector
+	combinators []byte // ' ' (descendant) or '>' (child)
+}
+
+// compoundSelector is a sequence of simple selectors without combinators.
+type compoundSelector struct {
+	tag     string // "" or "*" for any tag
+	ids     []string
+	classes []string
+	attrs   []attributeSelector
+	nths    []nthSelector
+	pseudos int // Number of pseudo-classes, for specificity
+}
+
+// attributeSelector is [name op value].
+type attributeSelector struct {
+	name  string
+	op    string // "" (present), "=", "~=", "|=", "^=", "$=" or "*="
+	value string
+	fold  bool // Case-insensitive value (the i flag)
+}
+
+// nthSelector matches the 1-based sibling indexes a*n+b for n >= 0,
+// counted from the last child if fromEnd is set.
+type nthSelector struct {
+	a, b    int
+	fromEnd bool
+}
+
+// Specificity is the (id, class, type) specificity of a selector.
+// Attribute selectors and pseudo-classes count as classes.
+//
+// See: https://www.w3.org/TR/selectors-4/#specificity-rules
+type Specificity [3]int
+
+// Less reports whether s has lower specificity than o.
+func (s Specificity) Less(o Specificity) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return s[i] < o[i]
+		}
+	}
+	return false
+}
+
+// ParseSelector parses a single selector (not a comma-separated list).
+//
+// Example:
+//
+//	sel, err := layout.ParseSelector("main > .card:first-child .title")
+func ParseSelector(s string) (Selector, error) {
+	src := strings.TrimSpace(s)
+	sel := Selector{source: src}
+	if src == "" {
+		return Selector{}, fmt.Errorf("layout: empty selector")
+	}
+	i := 0
+	for {
+		c, n, err := parseCompound(src[i:])
+		if err != nil {
+			return Selector{}, fmt.Errorf("layout: invalid selector %q: %v", s, err)
+		}
+		sel.compounds = append(sel.compounds, c)
+		i += n
+
+		// Combinator: whitespace, '>', or the end
+		start := i
+		for i < len(src) && isSelectorSpace(src[i]) {
+			i++
+		}
+		if i == len(src) {
+			return sel, nil
+		}
+		comb := byte(' ')
+		if src[i] == '>' {
+			comb = '>'
+			i++
+			for i < len(src) && isSelectorSpace(src[i]) {
+				i++
+			}
+		} else if i == start {
+			return Selector{}, fmt.Errorf("layout: invalid selector %q: unexpected %q", s, src[i])
+		}
+		sel.combinators = append(sel.combinators, comb)
+	}
+}
+
+// parseSelectorList parses a comma-separated selector list.
+func parseSelectorList(s string) ([]Selector, error) {
+	var list []Selector
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch ch := s[i]; {
+			case quote != 0:
+				if ch == quote {
+					quote = 0
+				}
+				continue
+			case ch == '"' || ch == '\'':
+				quote = ch
+				continue
+			case ch == '[' || ch == '(':
+				depth++
+				continue
+			case ch == ']' || ch == ')':
+				depth--
+				continue
+			case ch != ',' || depth > 0:
+				continue
+			}
+		}
+		sel, err := ParseSelector(s[start:i])
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sel)
+		start = i + 1
+	}
+	return list, nil
+}
+
+// parseCompound parses the compound selector at the start of s and returns
+// it with the number of bytes consumed.
+func parseCompound(s string) (compoundSelector, int, error) {
+	var c compoundSelector
+	i := 0
+	if i < len(s) && s[i] == '*' {
+		c.tag = "*"
+		i++
+	} else if name := selectorIdent(s); name != "" {
+		c.tag = strings.ToLower(name)
+		i += len(name)
+	}
+loop:
+	for i < len(s) {
+		switch s[i] {
+		case '#', '.':
+			kind := s[i]
+			name := selectorIdent(s[i+1:])
+			if name == "" {
+				return c, 0, fmt.Errorf("expected a name after %q", kind)
+			}
+			if kind == '#' {
+				c.ids = append(c.ids, name)
+			} else {
+				c.classes = append(c.classes, name)
+			}
+			i += 1 + len(name)
+		case '[':
+			end := attributeEnd(s[i:])
+			if end < 0 {
+				return c, 0, fmt.Errorf("unterminated attribute selector")
+			}
+			attr, err := parseAttributeSelector(s[i+1 : i+end])
+			if err != nil {
+				return c, 0, err
+			}
+			c.attrs = append(c.attrs, attr)
+			i += end + 1
+		case ':':
+			nths, n, err := parsePseudoClass(s[i+1:])
+			if err != nil {
+				return c, 0, err
+			}
+			c.nths = append(c.nths, nths...)
+			c.pseudos++
+			i += 1 + n
+		default:
+			break loop
+		}
+	}
+	if i == 0 {
+		if s == "" {
+			return c, 0, fmt.Errorf("missing selector after combinator")
+		}
+		return c, 0, fmt.Errorf("unexpected %q", s[0])
+	}
+	return c, i, nil
+}
+
+// attributeEnd returns the index of the ] closing the attribute selector
+// at the start of s, skipping quoted values, or -1.
+func attributeEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseAttributeSelector parses the inside of [...].
+func parseAttributeSelector(s string) (attributeSelector, error) {
+	s = strings.TrimSpace(s)
+	name := selectorIdent(s)
+	if name == "" {
+		return attributeSelector{}, fmt.Errorf("expected an attribute name in [%s]", s)
+	}
+	attr := attributeSelector{name: strings.ToLower(name)}
+	rest := strings.TrimSpace(s[len(name):])
+	if rest == "" {
+		return attr, nil
+	}
+	for _, op := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(rest, op) {
+			attr.op = op
+			rest = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if attr.op == "" {
+		return attributeSelector{}, fmt.Errorf("unexpected %q in [%s]", rest, s)
+	}
+
+	// The value is an identifier or a quoted string
+	if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			return attributeSelector{}, fmt.Errorf("unterminated string in [%s]", s)
+		}
+		attr.value, rest = rest[1:end+1], rest[end+2:]
+	} else {
+		attr.value = selectorIdent(rest)
+		if attr.value == "" {
+			return attributeSelector{}, fmt.Errorf("expected a value in [%s]", s)
+		}
+		rest = rest[len(attr.value):]
+	}
+	switch strings.TrimSpace(rest) {
+	case "":
+	case "i", "I":
+		attr.fold = true
+	case "s", "S":
+	default:
+		return attributeSelector{}, fmt.Errorf("unexpected %q in [%s]", rest, s)
+	}
+	return attr, nil
+}
+
+// parsePseudoClass parses a pseudo-class after the colon and returns it as
+// nth selectors, with the number of bytes consumed.
+func parsePseudoClass(s string) ([]nthSelector, int, error) {
+	name := strings.ToLower(selectorIdent(s))
+	switch name {
+	case "first-child":
+		return []nthSelector{{b: 1}}, len(name), nil
+	case "last-child":
+		return []nthSelector{{b: 1, fromEnd: true}}, len(name), nil
+	case "only-child":
+		return []nthSelector{{b: 1}, {b: 1, fromEnd: true}}, len(name), nil
+	case "nth-child", "nth-last-child":
+		rest := s[len(name):]
+		if !strings.HasPrefix(rest, "(") {
+			return nil, 0, fmt.Errorf("expected ( after :%s", name)
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return nil, 0, fmt.Errorf("unterminated :%s()", name)
+		}
+		nth, err := parseNth(rest[1:end])
+		if err != nil {
+			return nil, 0, err
+		}
+		nth.fromEnd = name == "nth-last-child"
+		return []nthSelector{nth}, len(name) + end + 1, nil
+	case "":
+		return nil, 0, fmt.Errorf("expected a pseudo-class after :")
+	}
+	return nil, 0, fmt.Errorf("unsupported pseudo-class :%s", name)
+}
+
+// parseNth parses the An+B microsyntax: odd, even, B, An or An+B.
+//
+// See: https://www.w3.org/TR/css-syntax-3/#anb-microsyntax
+func parseNth(s string) (nthSelector, error) {
+	expr := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	switch expr {
+	case "odd":
+		return nthSelector{a: 2, b: 1}, nil
+	case "even":
+		return nthSelector{a: 2, b: 0}, nil
+	}
+	var nth nthSelector
+	var err error
+	aPart, bPart, hasN := strings.Cut(expr, "n")
+	if !hasN {
+		nth.b, err = strconv.Atoi(expr)
+		if err != nil {
+			return nthSelector{}, fmt.Errorf("invalid An+B %q", s)
+		}
+		return nth, nil
+	}
+	switch aPart {
+	case "", "+":
+		nth.a = 1
+	case "-":
+		nth.a = -1
+	default:
+		if nth.a, err = strconv.Atoi(aPart); err != nil {
+			return nthSelector{}, fmt.Errorf("invalid An+B %q", s)
+		}
+	}
+	if bPart != "" {
+		if bPart[0] != '+' && bPart[0] != '-' {
+			return nthSelector{}, fmt.Errorf("invalid An+B %q", s)
+		}
+		if nth.b, err = strconv.Atoi(bPart); err != nil {
+			return nthSelector{}, fmt.Errorf("invalid An+B %q", s)
+		}
+	}
+	return nth, nil
+}
+
+// selectorIdent returns the identifier at the start of s, approximated
+// as [A-Za-z0-9_-] and non-ASCII characters.
+func selectorIdent(s string) string {
+	i := 0
+	for i < len(s) {
+		ch := s[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' || ch >= 0x80 {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i]
+}
+
+func isSelectorSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f'
+}
+
+// String returns the selector as it was parsed, without surrounding
+// whitespace.
+func (s Selector) String() string {
+	return s.source
+}
+
+// Specificity returns the selector's specificity.
+func (s Selector) Specificity() Specificity {
+	var sp Specificity
+	for _, c := range s.compounds {
+		sp[0] += len(c.ids)
+		sp[1] += len(c.classes) + len(c.attrs) + c.pseudos
+		if c.tag != "" && c.tag != "*" {
+			sp[2]++
+		}
+	}
+	return sp
+}
+
+// Match reports whether the selector matches node. ancestors are node's
+// ancestors from the root down to its parent; nodes have no parent
+// pointers, so callers walking a tree pass the path they walked. A node
+// without ancestors is treated as an only child.
+//
+// Example:
+//
+//	sel.Match(title, []*layout.Node{root, main, card})
+func (s Selector) Match(node *Node, ancestors []*Node) bool {
+	if node == nil || len(s.compounds) == 0 {
+		return false
+	}
+	return s.matchFrom(len(s.compounds)-1, node, ancestors)
+}
+
+// matchFrom matches compounds[:i+1] with compounds[i] against node.
+func (s Selector) matchFrom(i int, node *Node, ancestors []*Node) bool {
+	var parent *Node
+	if len(ancestors) > 0 {
+		parent = ancestors[len(ancestors)-1]
+	}
+	if !s.compounds[i].match(node, parent) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch s.combinators[i-1] {
+	case '>':
+		if parent == nil {
+			return false
+		}
+		return s.matchFrom(i-1, parent, ancestors[:len(ancestors)-1])
+	default:
+		for j := len(ancestors) - 1; j >= 0; j-- {
+			if s.matchFrom(i-1, ancestors[j], ancestors[:j]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (c compoundSelector) match(node, parent *Node) bool {
+	if c.tag != "" && c.tag != "*" && !strings.EqualFold(c.tag, node.Tag) {
+		return false
+	}
+	for _, id := range c.ids {
+		if id != node.ID {
+			return false
+		}
+	}
+	for _, class := range c.classes {
+		if !node.hasClass(class) {
+			return false
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(node) {
+			return false
+		}
+	}
+	if len(c.nths) > 0 {
+		index, count := 1, 1
+		if parent != nil {
+			count = len(parent.Children)
+			for j, child := range parent.Children {
+				if child == node {
+					index = j + 1
+					break
+				}
+			}
+		}
+		for _, nth := range c.nths {
+			if !nth.match(index, count) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (n *Node) hasClass(class string) bool {
+	for _, c := range n.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// attribute returns the value of a node attribute for attribute selectors.
+// id and class are the ID and Classes fields.
+func (n *Node) attribute(name string) (string, bool) {
+	switch name {
+	case "id":
+		return n.ID, n.ID != ""
+	case "class":
+		return strings.Join(n.Classes, " "), len(n.Classes) > 0
+	}
+	for k, v := range n.Attributes {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (a attributeSelector) match(node *Node) bool {
+	v, ok := node.attribute(a.name)
+	if !ok {
+		return false
+	}
+	want := a.value
+	if a.fold {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return v == want
+	case "~=":
+		for _, f := range strings.Fields(v) {
+			if f == want {
+				return true
+			}
+		}
+		return false
+	case "|=":
+		return v == want || strings.HasPrefix(v, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(v, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(v, want)
+	case "*=":
+		return want != "" && strings.Contains(v, want)
+	}
+	return false
+}
+
+// match reports whether the 1-based index among count siblings is a*n+b
+// for some n >= 0.
+func (nth nthSelector) match(index, count int) bool {
+	if nth.fromEnd {
+		index = count - index + 1
+	}
+	if nth.a == 0 {
+		return index == nth.b
+	}
+	d := index - nth.b
+	return d%nth.a == 0 && d/nth.a >= 0
+}
+
+// Query returns the first descendant of n (depth-first, in document order)
+// matched by the selector list, or nil. Like Find, it searches descendants
+// only, but n counts as an ancestor, so "main > .title" finds the titles
+// directly under a main root. An invalid selector matches nothing; use
+// ParseSelector to check one.
+//
+// Example:
+//
+//	title := root.Query("div.card > .title")
+func (n *Node) Query(selectors string) *Node {
+	list, err := parseSelectorList(selectors)
+	if n == nil || err != nil {
+		return nil
+	}
+	var found *Node
+	n.walkSelector(list, []*Node{n}, func(node *Node) bool {
+		found = node
+		return false
+	})
+	return found
+}
+
+// QueryAll returns all descendants of n matched by the selector list, in
+// document order. See Query.
+//
+// Example:
+//
+//	oddRows := table.QueryAll(".row:nth-child(odd), .row[data-selected]")
+func (n *Node) QueryAll(selectors string) []*Node {
+	list, err := parseSelectorList(selectors)
+	if n == nil || err != nil {
+		return nil
+	}
+	result := make([]*Node, 0, 10)
+	n.walkSelector(list, []*Node{n}, func(node *Node) bool {
+		result = append(result, node)
+		return true
+	})
+	return result
+}
+
+// walkSelector calls visit for each descendant of n matched by any of the
+// selectors until visit returns false. ancestors ends with n.
+func (n *Node) walkSelector(list []Selector, ancestors []*Node, visit func(*Node) bool) bool {
+	for _, child := range n.Children {
+		for _, sel := range list {
+			if sel.Match(child, ancestors) {
+				if !visit(child) {
+					return false
+				}
+				break
+			}
+		}
+		if !child.walkSelector(list, append(ancestors, child), visit) {
+			return false
+		}
+	}
+	return true
+}
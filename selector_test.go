@@ -0,0 +1,134 @@
+package layout
+
+import "testing"
+
+func TestSelectorMatch(t *testing.T) {
+	root := &Node{Tag: "main", ID: "app"}
+	card := &Node{Tag: "div", Classes: []string{"card", "wide"}, Attributes: map[string]string{"data-state": "open", "lang": "en-US"}}
+	title := &Node{Tag: "h1", Classes: []string{"title"}}
+	body := &Node{Tag: "p"}
+	root.Children = []*Node{card}
+	card.Children = []*Node{title, body}
+	path := []*Node{root, card}
+
+	tests := []struct {
+		selector    string
+		specificity Specificity
+		match       bool
+	}{
+		{"h1", Specificity{0, 0, 1}, true},
+		{"*", Specificity{0, 0, 0}, true},
+		{".title", Specificity{0, 1, 0}, true},
+		{"H1.title", Specificity{0, 1, 1}, true},
+		{"#app h1", Specificity{1, 0, 1}, true},
+		{"div.card.wide > .title", Specificity{0, 3, 1}, true},
+		{"main > .title", Specificity{0, 1, 1}, false},
+		{"main .title", Specificity{0, 1, 1}, true},
+		{".card.narrow .title", Specificity{0, 3, 0}, false},
+		{"#other h1", Specificity{1, 0, 1}, false},
+		{"[data-state] h1", Specificity{0, 1, 1}, true},
+		{"[data-state=open] > h1", Specificity{0, 1, 1}, true},
+		{"[data-state='closed'] h1", Specificity{0, 1, 1}, false},
+		{"[lang|=en] h1", Specificity{0, 1, 1}, true},
+		{"[lang^=EN i] h1", Specificity{0, 1, 1}, true},
+		{"[lang^=EN] h1", Specificity{0, 1, 1}, false},
+		{"[class~=wide] h1", Specificity{0, 1, 1}, true},
+		{"[id=app] h1", Specificity{0, 1, 1}, true},
+		{"h1:first-child", Specificity{0, 1, 1}, true},
+		{"h1:last-child", Specificity{0, 1, 1}, false},
+		{"h1:only-child", Specificity{0, 1, 1}, false},
+		{"div:only-child > :nth-child(1)", Specificity{0, 2, 1}, true},
+		{":nth-last-child(2)", Specificity{0, 1, 0}, true},
+		{":nth-child(even)", Specificity{0, 1, 0}, false},
+	}
+	for _, tt := range tests {
+		sel, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q): %v", tt.selector, err)
+		}
+		if got := sel.Specificity(); got != tt.specificity {
+			t.Errorf("Expected %q specificity %v, got %v", tt.selector, tt.specificity, got)
+		}
+		if got := sel.Match(title, path); got != tt.match {
+			t.Errorf("Expected %q match %v, got %v", tt.selector, tt.match, got)
+		}
+	}
+
+	for _, bad := range []string{"", "div >", "> div", ".", "div..card", "a:hover", "[", "[=x]", "[a=]", "[a==b]", ":nth-child(x)", ":nth-child(2n 1)"} {
+		if _, err := ParseSelector(bad); err == nil {
+			t.Errorf("Expected ParseSelector(%q) to fail", bad)
+		}
+	}
+}
+
+func TestSelectorNthChild(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []int // Matching 1-based indexes among 7 children
+	}{
+		{"odd", []int{1, 3, 5, 7}},
+		{"even", []int{2, 4, 6}},
+		{"3", []int{3}},
+		{"3n", []int{3, 6}},
+		{"2n+3", []int{3, 5, 7}},
+		{"-n + 3", []int{1, 2, 3}},
+		{"n", []int{1, 2, 3, 4, 5, 6, 7}},
+		{"-2n+5", []int{1, 3, 5}},
+	}
+	for _, tt := range tests {
+		nth, err := parseNth(tt.expr)
+		if err != nil {
+			t.Fatalf("parseNth(%q): %v", tt.expr, err)
+		}
+		var got []int
+		for i := 1; i <= 7; i++ {
+			if nth.match(i, 7) {
+				got = append(got, i)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Expected %q to match %v, got %v", tt.expr, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Expected %q to match %v, got %v", tt.expr, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	titleA := &Node{Tag: "span", Classes: []string{"title"}}
+	titleB := &Node{Tag: "span", Classes: []string{"title"}}
+	nested := &Node{Tag: "span", Classes: []string{"title"}}
+	cardA := &Node{Tag: "div", Classes: []string{"card"}, Children: []*Node{titleA}}
+	cardB := &Node{Tag: "div", Classes: []string{"card"}, Attributes: map[string]string{"data-selected": ""},
+		Children: []*Node{titleB, {Children: []*Node{nested}}}}
+	root := &Node{Tag: "main", Children: []*Node{cardA, cardB}}
+
+	if got := root.Query("div.card > .title"); got != titleA {
+		t.Errorf("Expected the first card's title, got %+v", got)
+	}
+	if got := root.QueryAll("div.card > .title"); len(got) != 2 || got[0] != titleA || got[1] != titleB {
+		t.Errorf("Expected both direct titles, got %d nodes", len(got))
+	}
+	if got := root.QueryAll(".card .title"); len(got) != 3 || got[2] != nested {
+		t.Errorf("Expected 3 titles including the nested one, got %d nodes", len(got))
+	}
+	if got := root.QueryAll("main > .card:nth-child(2), [data-selected] .title"); len(got) != 3 || got[0] != cardB {
+		t.Errorf("Expected the selected card and its 2 titles, got %d nodes", len(got))
+	}
+	// The receiver is an ancestor but isn't returned itself
+	if got := root.QueryAll("main"); len(got) != 0 {
+		t.Errorf("Expected no matches for the receiver, got %d", len(got))
+	}
+	if got := root.Query("div >"); got != nil {
+		t.Errorf("Expected nil for an invalid selector, got %+v", got)
+	}
+	var nilNode *Node
+	if got := nilNode.QueryAll("*"); got != nil {
+		t.Errorf("Expected nil for a nil node, got %v", got)
+	}
+}
@@ -0,0 +1,567 @@
+package serialize
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Binary format.
+//
+// ToBinary writes the same data as ToJSON, in a compact form that is much
+// smaller and faster to read and write for large trees: a header (the
+// magic "LYTB", a varint version and a flags byte), an optional string
+// table, and the tree as a value. Values are a tag byte followed by:
+//
+//	null, false, true  nothing
+//	int                a zigzag varint (integral numbers)
+//	float              8 little-endian bytes (other numbers)
+//	string             a varint length and the bytes
+//	string ref         a varint index into the string table
+//	array              a varint count and the values
+//	object             a varint count and, per property, its JSON name
+//	                   (a string ref with a string table, a length and the
+//	                   bytes without) and its value
+//
+// Objects are keyed by JSON name, like JSON, so they read back whatever
+// properties have been added to or removed from the format since; unknown
+// properties are skipped. The version in the header changes only if the
+// encoding does; the tree's format version (see FormatVersion) is its
+// root's "version" property, as in JSON.
+//
+// With a string table (the default), names and strings are written once
+// and referenced by index, so the keywords and classes repeated on every
+// node cost a byte or two each.
+
+const (
+	binaryMagic   = "LYTB"
+	binaryVersion = 1
+
+	// binaryStringTable is the flag of data with a string table.
+	binaryStringTable = 1 << 0
+
+	// binaryMaxDepth is the deepest nesting FromBinary reads, as for
+	// encoding/json.
+	binaryMaxDepth = 10000
+)
+
+// Value tags.
+const (
+	binNull byte = iota
+	binFalse
+	binTrue
+	binInt
+	binFloat
+	binString
+	binStringRef
+	binArray
+	binObject
+)
+
+// ErrInvalidBinary is returned by FromBinary for data that isn't in the
+// binary format, or is corrupt.
+var ErrInvalidBinary = errors.New("serialize: invalid binary data")
+
+// BinaryOptions controls ToBinaryWithOptions.
+type BinaryOptions struct {
+	EncodeOptions
+
+	// InlineStrings writes names and strings where they occur rather than
+	// in a string table. The data is larger for all but tiny trees, but
+	// the table needn't be built first.
+	InlineStrings bool
+}
+
+// ToBinary converts a layout.Node to the binary format, with a string
+// table.
+//
+// Example:
+//
+//	data, err := serialize.ToBinary(root)
+//	...
+//	root, err = serialize.FromBinary(data)
+func ToBinary(node *layout.Node) ([]byte, error) {
+	return ToBinaryWithOptions(node, BinaryOptions{})
+}
+
+// ToBinaryWithOptions converts a layout.Node to the binary format.
+func ToBinaryWithOptions(node *layout.Node, opts BinaryOptions) ([]byte, error) {
+	e := &binaryEncoder{}
+	if !opts.InlineStrings {
+		e.refs = make(map[string]uint64)
+	}
+	e.value(reflect.ValueOf(documentToJSON(node, opts.EncodeOptions)))
+
+	out := append([]byte(binaryMagic), binaryVersion)
+	if opts.InlineStrings {
+		out = append(out, 0)
+	} else {
+		out = append(out, binaryStringTable)
+		out = binary.AppendUvarint(out, uint64(len(e.table)))
+		for _, s := range e.table {
+			out = binary.AppendUvarint(out, uint64(len(s)))
+			out = append(out, s...)
+		}
+	}
+	return append(out, e.buf...), nil
+}
+
+// FromBinary converts data in the binary format to a layout.Node.
+func FromBinary(data []byte) (*layout.Node, error) {
+	if len(data) < len(binaryMagic)+2 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, ErrInvalidBinary
+	}
+	d := &binaryDecoder{data: data, pos: len(binaryMagic)}
+	version, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if version != binaryVersion {
+		return nil, fmt.Errorf("serialize: unsupported binary version %d", version)
+	}
+	flags, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if flags&binaryStringTable != 0 {
+		n, err := d.count()
+		if err != nil {
+			return nil, err
+		}
+		d.table = make([]string, n)
+		for i := range d.table {
+			if d.table[i], err = d.inlineString(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var nj *NodeJSON
+	if err := d.value(reflect.ValueOf(&nj).Elem(), 0); err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, ErrInvalidBinary
+	}
+	if nj != nil && nj.Version != 0 {
+		if err := checkVersion(nj.Version); err != nil {
+			return nil, err
+		}
+	}
+	return jsonToNode(nj), nil
+}
+
+// binaryField is a struct field as encoding/json sees it.
+type binaryField struct {
+	name      string
+	index     int
+	omitEmpty bool
+	omitZero  bool
+}
+
+// omits reports whether the field is left out with value v, as in
+// encoding/json.
+func (f *binaryField) omits(v reflect.Value) bool {
+	return f.omitEmpty && emptyValue(v) || f.omitZero && v.IsZero()
+}
+
+// binaryStruct is the fields of a struct type, in order and by name.
+type binaryStruct struct {
+	fields []binaryField
+	byName map[string]int
+}
+
+// binaryStructs caches the binaryStruct of struct types.
+var binaryStructs sync.Map
+
+// structFields returns the fields of struct type t that encoding/json
+// marshals, with their JSON names.
+func structFields(t reflect.Type) *binaryStruct {
+	if s, ok := binaryStructs.Load(t); ok {
+		return s.(*binaryStruct)
+	}
+	s := &binaryStruct{byName: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.byName[name] = len(s.fields)
+		s.fields = append(s.fields, binaryField{
+			name:      name,
+			index:     i,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			omitZero:  strings.Contains(","+opts+",", ",omitzero,"),
+		})
+	}
+	binaryStructs.Store(t, s)
+	return s
+}
+
+// binaryEncoder writes values.
+type binaryEncoder struct {
+	buf []byte
+
+	// refs indexes table, the string table, or is nil for inline strings
+	refs  map[string]uint64
+	table []string
+}
+
+// str writes s without a tag: as a reference or inline.
+func (e *binaryEncoder) str(s string) {
+	if e.refs == nil {
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+		e.buf = append(e.buf, s...)
+		return
+	}
+	i, ok := e.refs[s]
+	if !ok {
+		i = uint64(len(e.table))
+		e.refs[s] = i
+		e.table = append(e.table, s)
+	}
+	e.buf = binary.AppendUvarint(e.buf, i)
+}
+
+// value writes v like encoding/json would marshal it.
+func (e *binaryEncoder) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, binNull)
+			return
+		}
+		e.value(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, binTrue)
+		} else {
+			e.buf = append(e.buf, binFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = append(e.buf, binInt)
+		e.buf = binary.AppendVarint(e.buf, v.Int())
+	case reflect.Float32, reflect.Float64:
+		e.float(v.Float())
+	case reflect.String:
+		if e.refs == nil {
+			e.buf = append(e.buf, binString)
+		} else {
+			e.buf = append(e.buf, binStringRef)
+		}
+		e.str(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf = append(e.buf, binNull)
+			return
+		}
+		e.buf = append(e.buf, binArray)
+		e.buf = binary.AppendUvarint(e.buf, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			e.value(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, binNull)
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		e.buf = append(e.buf, binObject)
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(keys)))
+		for _, k := range keys {
+			e.str(k.String())
+			e.value(v.MapIndex(k))
+		}
+	case reflect.Struct:
+		s := structFields(v.Type())
+		n := 0
+		for i := range s.fields {
+			if !s.fields[i].omits(v.Field(s.fields[i].index)) {
+				n++
+			}
+		}
+		e.buf = append(e.buf, binObject)
+		e.buf = binary.AppendUvarint(e.buf, uint64(n))
+		for i := range s.fields {
+			f := &s.fields[i]
+			field := v.Field(f.index)
+			if !f.omits(field) {
+				e.str(f.name)
+				e.value(field)
+			}
+		}
+	default:
+		panic("serialize: can't encode " + v.Type().String())
+	}
+}
+
+// float writes f as an int if it's integral and exactly representable,
+// and as a float otherwise.
+func (e *binaryEncoder) float(f float64) {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 && !(f == 0 && math.Signbit(f)) {
+		e.buf = append(e.buf, binInt)
+		e.buf = binary.AppendVarint(e.buf, int64(f))
+		return
+	}
+	e.buf = append(e.buf, binFloat)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+// emptyValue reports whether omitempty leaves v out, as in encoding/json.
+func emptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// binaryDecoder reads values.
+type binaryDecoder struct {
+	data  []byte
+	pos   int
+	table []string
+}
+
+func (d *binaryDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrInvalidBinary
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, ErrInvalidBinary
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *binaryDecoder) varint() (int64, error) {
+	v, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, ErrInvalidBinary
+	}
+	d.pos += n
+	return v, nil
+}
+
+// count reads the count of an array, object or string table, each of
+// whose items takes at least a byte, so corrupt counts can't allocate
+// more than the data's size.
+func (d *binaryDecoder) count() (int, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, ErrInvalidBinary
+	}
+	return int(n), nil
+}
+
+// inlineString reads a length and the string's bytes.
+func (d *binaryDecoder) inlineString() (string, error) {
+	n, err := d.count()
+	if err != nil {
+		return "", err
+	}
+	s := string(d.data[d.pos : d.pos+n])
+	d.pos += n
+	return s, nil
+}
+
+// str reads a string without a tag, as a reference or inline.
+func (d *binaryDecoder) str() (string, error) {
+	if d.table == nil {
+		return d.inlineString()
+	}
+	i, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if i >= uint64(len(d.table)) {
+		return "", ErrInvalidBinary
+	}
+	return d.table[i], nil
+}
+
+// value reads a value into v, or skips it if v isn't valid.
+func (d *binaryDecoder) value(v reflect.Value, depth int) error {
+	if depth > binaryMaxDepth {
+		return fmt.Errorf("serialize: binary data nested deeper than %d", binaryMaxDepth)
+	}
+	tag, err := d.byte()
+	if err != nil {
+		return err
+	}
+	if v.IsValid() && v.Kind() == reflect.Pointer {
+		if tag == binNull {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.pos--
+		return d.value(v.Elem(), depth)
+	}
+
+	switch tag {
+	case binNull:
+		if v.IsValid() {
+			v.SetZero()
+		}
+		return nil
+	case binFalse, binTrue:
+		if v.IsValid() {
+			if v.Kind() != reflect.Bool {
+				return mismatch(v, "a boolean")
+			}
+			v.SetBool(tag == binTrue)
+		}
+		return nil
+	case binInt:
+		i, err := d.varint()
+		if err != nil || !v.IsValid() {
+			return err
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(i) {
+				return mismatch(v, "an out of range number")
+			}
+			v.SetInt(i)
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(i))
+		default:
+			return mismatch(v, "a number")
+		}
+		return nil
+	case binFloat:
+		if d.pos+8 > len(d.data) {
+			return ErrInvalidBinary
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		if v.IsValid() {
+			if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+				return mismatch(v, "a number")
+			}
+			v.SetFloat(f)
+		}
+		return nil
+	case binString, binStringRef:
+		var s string
+		if tag == binString {
+			s, err = d.inlineString()
+		} else if d.table == nil {
+			err = ErrInvalidBinary
+		} else {
+			s, err = d.str()
+		}
+		if err != nil || !v.IsValid() {
+			return err
+		}
+		if v.Kind() != reflect.String {
+			return mismatch(v, "a string")
+		}
+		v.SetString(s)
+		return nil
+	case binArray:
+		n, err := d.count()
+		if err != nil {
+			return err
+		}
+		if v.IsValid() && v.Kind() != reflect.Slice {
+			return mismatch(v, "an array")
+		}
+		if v.IsValid() {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		}
+		for i := 0; i < n; i++ {
+			var elem reflect.Value
+			if v.IsValid() {
+				elem = v.Index(i)
+			}
+			if err := d.value(elem, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case binObject:
+		return d.object(v, depth)
+	}
+	return ErrInvalidBinary
+}
+
+// object reads an object's properties into the struct or map v, skipping
+// unknown ones, or skips them all if v isn't valid.
+func (d *binaryDecoder) object(v reflect.Value, depth int) error {
+	n, err := d.count()
+	if err != nil {
+		return err
+	}
+	var s *binaryStruct
+	switch {
+	case !v.IsValid():
+	case v.Kind() == reflect.Struct:
+		s = structFields(v.Type())
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+	default:
+		return mismatch(v, "an object")
+	}
+
+	for i := 0; i < n; i++ {
+		name, err := d.str()
+		if err != nil {
+			return err
+		}
+		var field reflect.Value
+		switch {
+		case s != nil:
+			if f, ok := s.byName[name]; ok {
+				field = v.Field(s.fields[f].index)
+			}
+		case v.IsValid():
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem, depth+1); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+			continue
+		}
+		if err := d.value(field, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mismatch returns the error for a value of the wrong type for v.
+func mismatch(v reflect.Value, got string) error {
+	return fmt.Errorf("%w: %s for %s", ErrInvalidBinary, got, v.Type())
+}
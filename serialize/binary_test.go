@@ -0,0 +1,190 @@
+package serialize
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// binaryTestTree returns a laid-out tree using most of the format: grids,
+// percentages, calc, optional lengths, transforms and identity.
+func binaryTestTree() *layout.Node {
+	card := layout.Fixed(100, 50)
+	card.ID = "card"
+	card.Classes = []string{"raised", "wide"}
+	card.Attributes = map[string]string{"role": "button", "data-x": "1"}
+	card.Style.MinWidth = layout.Px(0)
+	card.Style.Margin = layout.Spacing{Left: layout.Auto(), Right: layout.Auto()}
+	card.Style.Transform = layout.Rotate(0.5)
+	card.Style.FlexGrow = 1.5
+	card.Style.ZIndex = -3
+	wide := &layout.Node{Style: layout.Style{Width: layout.Percent(50), Height: layout.Calc(layout.Percent(100), layout.CalcSub, layout.Px(8))}}
+	grid := &layout.Node{Style: layout.Style{
+		Display:             layout.DisplayGrid,
+		GridTemplateColumns: []layout.GridTrack{layout.FixedTrack(layout.Px(80)), layout.FractionTrack(1)},
+		GridColumnGap:       layout.Px(4),
+	}, Children: []*layout.Node{card, wide}}
+	root := layout.VStack(grid, layout.Fixed(10, 10))
+	root.Tag = "section"
+	layout.Layout(root, layout.Loose(400, 300), layout.NewLayoutContext(400, 300, 16))
+	return root
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	root := binaryTestTree()
+	want, _ := ToJSON(root)
+
+	for _, opts := range []BinaryOptions{{}, {InlineStrings: true}} {
+		data, err := ToBinaryWithOptions(root, opts)
+		if err != nil {
+			t.Fatalf("ToBinary failed: %v", err)
+		}
+		deserialized, err := FromBinary(data)
+		if err != nil {
+			t.Fatalf("FromBinary failed: %v", err)
+		}
+		if got, _ := ToJSON(deserialized); !bytes.Equal(got, want) {
+			t.Errorf("%+v: expected the tree back, got:\n%s\nwant:\n%s", opts, got, want)
+		}
+		if len(data) >= len(want)/2 {
+			t.Errorf("%+v: expected well under half the JSON size, got %d bytes for %d", opts, len(data), len(want))
+		}
+	}
+}
+
+func TestBinaryMatchesJSONDecoding(t *testing.T) {
+	root := binaryTestTree()
+	jsonData, _ := ToJSON(root)
+	fromJSON, _ := FromJSON(jsonData)
+	data, _ := ToBinary(root)
+	fromBinary, err := FromBinary(data)
+	if err != nil {
+		t.Fatalf("FromBinary failed: %v", err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromBinary) {
+		t.Errorf("Expected binary and JSON to decode alike")
+	}
+}
+
+func TestBinarySkipsUnknownProperties(t *testing.T) {
+	// A node from a later version, with a property this one doesn't know
+	e := &binaryEncoder{refs: make(map[string]uint64)}
+	e.buf = append(e.buf, binObject, 3)
+	e.str("id")
+	e.value(reflect.ValueOf("future"))
+	e.str("hologram")
+	e.buf = append(e.buf, binArray, 2, binTrue, binObject, 1)
+	e.str("depth")
+	e.float(2.5)
+	e.str("style")
+	e.buf = append(e.buf, binObject, 1)
+	e.str("width")
+	e.float(30)
+	data := append([]byte(binaryMagic), binaryVersion, binaryStringTable, byte(len(e.table)))
+	for _, s := range e.table {
+		data = append(data, byte(len(s)))
+		data = append(data, s...)
+	}
+	data = append(data, e.buf...)
+
+	node, err := FromBinary(data)
+	if err != nil {
+		t.Fatalf("FromBinary failed: %v", err)
+	}
+	if node.ID != "future" || node.Style.Width != layout.Px(30) {
+		t.Errorf("Expected the known properties, got %+v", node)
+	}
+}
+
+func TestFromBinaryInvalid(t *testing.T) {
+	data, _ := ToBinary(binaryTestTree())
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("JSON"), data[4:]...),
+		"truncated": data[:len(data)-3],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"count":     append(append([]byte{}, data[:6]...), 0xff, 0xff, 0xff, 0x7f),
+	} {
+		if _, err := FromBinary(bad); !errors.Is(err, ErrInvalidBinary) {
+			t.Errorf("%s: expected ErrInvalidBinary, got %v", name, err)
+		}
+	}
+
+	future := append([]byte{}, data...)
+	future[4] = binaryVersion + 1
+	if _, err := FromBinary(future); err == nil || errors.Is(err, ErrInvalidBinary) {
+		t.Errorf("Expected an unsupported version error, got %v", err)
+	}
+
+	// A string where a number belongs
+	e := &binaryEncoder{}
+	e.buf = append(e.buf, binObject, 1)
+	e.str("style")
+	e.buf = append(e.buf, binObject, 1)
+	e.str("width")
+	e.value(reflect.ValueOf("wide"))
+	wrongType := append(append([]byte(binaryMagic), binaryVersion, 0), e.buf...)
+	if _, err := FromBinary(wrongType); !errors.Is(err, ErrInvalidBinary) {
+		t.Errorf("Expected a type error, got %v", err)
+	}
+}
+
+// benchmarkTree returns a tree of about n nodes: rows of cards with a
+// title and a body each.
+func benchmarkTree(n int) *layout.Node {
+	root := &layout.Node{Style: layout.Style{Display: layout.DisplayFlex, FlexDirection: layout.FlexDirectionColumn}}
+	for len(root.Children)*31 < n {
+		row := &layout.Node{Classes: []string{"row"}, Style: layout.Style{Display: layout.DisplayFlex, FlexGap: layout.Px(8)}}
+		for range 10 {
+			card := &layout.Node{Classes: []string{"card"}, Style: layout.Style{Width: layout.Px(120), Padding: layout.Uniform(layout.Px(8)), Display: layout.DisplayFlex, FlexDirection: layout.FlexDirectionColumn}}
+			card.Children = []*layout.Node{
+				{Classes: []string{"title"}, Style: layout.Style{Height: layout.Px(20)}},
+				{Classes: []string{"body"}, Style: layout.Style{Height: layout.Px(60), FlexGrow: 1}},
+			}
+			row.Children = append(row.Children, card)
+		}
+		root.Children = append(root.Children, row)
+	}
+	return root
+}
+
+func BenchmarkToJSON(b *testing.B) {
+	root := benchmarkTree(100_000)
+	b.ReportAllocs()
+	var size int
+	for b.Loop() {
+		data, _ := ToJSON(root)
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkToBinary(b *testing.B) {
+	root := benchmarkTree(100_000)
+	b.ReportAllocs()
+	var size int
+	for b.Loop() {
+		data, _ := ToBinary(root)
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkFromJSON(b *testing.B) {
+	data, _ := ToJSON(benchmarkTree(100_000))
+	b.ReportAllocs()
+	for b.Loop() {
+		FromJSON(data)
+	}
+}
+
+func BenchmarkFromBinary(b *testing.B) {
+	data, _ := ToBinary(benchmarkTree(100_000))
+	b.ReportAllocs()
+	for b.Loop() {
+		FromBinary(data)
+	}
+}
@@ -0,0 +1,298 @@
+package serialize
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/SCKelemen/layout"
+)
+
+// EncodeOptions controls which computed results of a laid-out tree
+// ToJSONWithOptions, ToYAMLWithOptions and ToBinaryWithOptions write. The
+// zero value writes Rects but not TextLayouts, like ToJSON.
+type EncodeOptions struct {
+	// OmitRects leaves out the nodes' computed Rects, for trees that are
+	// laid out after loading, like test fixtures.
+	OmitRects bool
+
+	// TextLayout writes the computed TextLayouts of text nodes: their
+	// lines and word boxes. With the Rects, a loaded tree can be painted
+	// without laying it out again, e.g. from a cache.
+	TextLayout bool
+}
+
+// TextLayoutJSON represents a serializable version of layout.TextLayout.
+// Its styles and drop cap aren't written; they follow from the node's text
+// style, which the format doesn't have yet.
+type TextLayoutJSON struct {
+	Lines      []TextLineJSON `json:"lines,omitempty"`
+	LineHeight float64        `json:"lineHeight,omitempty"`
+	ContentX   float64        `json:"contentX,omitempty"`
+	ContentY   float64        `json:"contentY,omitempty"`
+}
+
+// TextLineJSON represents a serializable version of layout.TextLine
+type TextLineJSON struct {
+	Boxes               []InlineBoxJSON `json:"boxes,omitempty"`
+	Width               float64         `json:"width,omitempty"`
+	SpaceCount          int             `json:"spaceCount,omitempty"`
+	SpaceWidth          float64         `json:"spaceWidth,omitempty"`
+	SpaceAdjustment     float64         `json:"spaceAdjustment,omitempty"`
+	CharacterAdjustment float64         `json:"characterAdjustment,omitempty"`
+	OffsetX             float64         `json:"offsetX,omitempty"`
+	OffsetY             float64         `json:"offsetY,omitempty"`
+	Height              float64         `json:"height,omitempty"`
+	Ascent              float64         `json:"ascent,omitempty"`
+	Descent             float64         `json:"descent,omitempty"`
+	Baseline            float64         `json:"baseline,omitempty"`
+
+	Decorations DecorationsJSON `json:"decorations,omitzero"`
+}
+
+// DecorationsJSON represents a serializable version of
+// layout.DecorationMetrics
+type DecorationsJSON struct {
+	Underline            float64 `json:"underline,omitempty"`
+	Overline             float64 `json:"overline,omitempty"`
+	LineThrough          float64 `json:"lineThrough,omitempty"`
+	Thickness            float64 `json:"thickness,omitempty"`
+	LineThroughThickness float64 `json:"lineThroughThickness,omitempty"`
+}
+
+// InlineBoxJSON represents a serializable version of a text
+// layout.InlineBox
+type InlineBoxJSON struct {
+	Text         string        `json:"text,omitempty"`
+	Width        float64       `json:"width,omitempty"`
+	Ascent       float64       `json:"ascent,omitempty"`
+	Descent      float64       `json:"descent,omitempty"`
+	Orientations []bool        `json:"orientations,omitempty"`
+	Level        int           `json:"level,omitempty"`
+	Runs         []FontRunJSON `json:"runs,omitempty"`
+}
+
+// FontRunJSON represents a serializable version of layout.FontRun
+type FontRunJSON struct {
+	Text       string  `json:"text,omitempty"`
+	FontFamily string  `json:"fontFamily,omitempty"`
+	Width      float64 `json:"width,omitempty"`
+}
+
+// computedToJSON applies opts to nj, the NodeJSON of node's tree.
+func computedToJSON(nj *NodeJSON, node *layout.Node, opts EncodeOptions) {
+	if nj == nil {
+		return
+	}
+	if opts.OmitRects {
+		nj.Rect = RectJSON{}
+	}
+	if opts.TextLayout {
+		nj.TextLayout = textLayoutToJSON(node.TextLayout)
+	}
+	for i, child := range nj.Children {
+		computedToJSON(child, node.Children[i], opts)
+	}
+}
+
+func textLayoutToJSON(tl *layout.TextLayout) *TextLayoutJSON {
+	if tl == nil {
+		return nil
+	}
+	tj := &TextLayoutJSON{
+		Lines:      make([]TextLineJSON, len(tl.Lines)),
+		LineHeight: tl.LineHeight,
+		ContentX:   tl.ContentX,
+		ContentY:   tl.ContentY,
+	}
+	for i, line := range tl.Lines {
+		lj := TextLineJSON{
+			Boxes:               make([]InlineBoxJSON, len(line.Boxes)),
+			Width:               line.Width,
+			SpaceCount:          line.SpaceCount,
+			SpaceWidth:          line.SpaceWidth,
+			SpaceAdjustment:     line.SpaceAdjustment,
+			CharacterAdjustment: line.CharacterAdjustment,
+			OffsetX:             line.OffsetX,
+			OffsetY:             line.OffsetY,
+			Height:              line.Height,
+			Ascent:              line.Ascent,
+			Descent:             line.Descent,
+			Baseline:            line.Baseline,
+			Decorations:         DecorationsJSON(line.Decorations),
+		}
+		for j, box := range line.Boxes {
+			bj := InlineBoxJSON{
+				Text:         box.Text,
+				Width:        box.Width,
+				Ascent:       box.Ascent,
+				Descent:      box.Descent,
+				Orientations: box.Orientations,
+				Level:        box.Level,
+			}
+			for _, run := range box.Runs {
+				bj.Runs = append(bj.Runs, FontRunJSON(run))
+			}
+			lj.Boxes[j] = bj
+		}
+		tj.Lines[i] = lj
+	}
+	return tj
+}
+
+func jsonToTextLayout(tj *TextLayoutJSON) *layout.TextLayout {
+	if tj == nil {
+		return nil
+	}
+	tl := &layout.TextLayout{
+		Lines:      make([]layout.TextLine, len(tj.Lines)),
+		LineHeight: tj.LineHeight,
+		ContentX:   tj.ContentX,
+		ContentY:   tj.ContentY,
+	}
+	for i, lj := range tj.Lines {
+		line := layout.TextLine{
+			Boxes:               make([]layout.InlineBox, len(lj.Boxes)),
+			Width:               lj.Width,
+			SpaceCount:          lj.SpaceCount,
+			SpaceWidth:          lj.SpaceWidth,
+			SpaceAdjustment:     lj.SpaceAdjustment,
+			CharacterAdjustment: lj.CharacterAdjustment,
+			OffsetX:             lj.OffsetX,
+			OffsetY:             lj.OffsetY,
+			Height:              lj.Height,
+			Ascent:              lj.Ascent,
+			Descent:             lj.Descent,
+			Baseline:            lj.Baseline,
+			Decorations:         layout.DecorationMetrics(lj.Decorations),
+		}
+		for j, bj := range lj.Boxes {
+			box := layout.InlineBox{
+				Kind:         layout.InlineBoxText,
+				Text:         bj.Text,
+				Width:        bj.Width,
+				Ascent:       bj.Ascent,
+				Descent:      bj.Descent,
+				Orientations: bj.Orientations,
+				Level:        bj.Level,
+			}
+			for _, run := range bj.Runs {
+				box.Runs = append(box.Runs, layout.FontRun(run))
+			}
+			line.Boxes[j] = box
+		}
+		tl.Lines[i] = line
+	}
+	return tl
+}
+
+// RoundTripOptions are the options of VerifyRoundTrip.
+type RoundTripOptions struct {
+	// Constraints and Context are those the tree was laid out with. A nil
+	// Context is layout.NewLayoutContext(Constraints.MaxWidth,
+	// Constraints.MaxHeight, 16).
+	Constraints layout.Constraints
+	Context     *layout.LayoutContext
+
+	// Tolerance is the largest difference allowed between a coordinate of
+	// a serialized Rect and the laid-out one. Zero requires them to be
+	// equal.
+	Tolerance float64
+}
+
+// RectMismatch is a node whose Rect VerifyRoundTrip found to differ.
+type RectMismatch struct {
+	// Path is the sequence of child indexes from the root to the node
+	Path []int
+
+	Serialized layout.Rect
+	Layout     layout.Rect
+}
+
+// RoundTripError is the error VerifyRoundTrip returns for a tree whose
+// layout doesn't reproduce its serialized Rects.
+type RoundTripError struct {
+	// Mismatches are the nodes that differ, in tree order
+	Mismatches []RectMismatch
+}
+
+// Error implements the error interface.
+func (e *RoundTripError) Error() string {
+	m := e.Mismatches[0]
+	path := pathKey(m.Path)
+	if path == "" {
+		path = "the root"
+	}
+	msg := fmt.Sprintf("serialize: layout differs from serialized rects at %s: serialized %v, laid out %v", path, m.Serialized, m.Layout)
+	if len(e.Mismatches) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Mismatches)-1)
+	}
+	return msg
+}
+
+// VerifyRoundTrip lays out root, a tree loaded with its Rects, and checks
+// that the layout reproduces them, returning a *RoundTripError if not.
+// Use it before trusting a layout cached between runs: the Rects differ
+// when the package or the text metrics have changed since. root is left
+// laid out.
+//
+// Example:
+//
+//	root, err := serialize.FromJSON(cached)
+//	if err == nil {
+//		err = serialize.VerifyRoundTrip(root, serialize.RoundTripOptions{
+//			Constraints: layout.Loose(800, 600),
+//			Context:     ctx,
+//			Tolerance:   0.01,
+//		})
+//	}
+//	if err != nil {
+//		// Stale cache: lay out from scratch
+//	}
+func VerifyRoundTrip(root *layout.Node, opts RoundTripOptions) error {
+	// Lay the tree out from scratch, as it was before it was serialized:
+	// Layout starts from the Rects of an earlier layout
+	var serialized []layout.Rect
+	walkRects(root, nil, func(_ []int, n *layout.Node) {
+		serialized = append(serialized, n.Rect)
+		n.Rect = layout.Rect{}
+		n.TextLayout = nil
+	})
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = layout.NewLayoutContext(opts.Constraints.MaxWidth, opts.Constraints.MaxHeight, 16)
+	}
+	layout.Layout(root, opts.Constraints, ctx)
+
+	var mismatches []RectMismatch
+	i := 0
+	walkRects(root, nil, func(path []int, n *layout.Node) {
+		want := serialized[i]
+		i++
+		if !rectWithin(want, n.Rect, opts.Tolerance) {
+			mismatches = append(mismatches, RectMismatch{Path: append([]int{}, path...), Serialized: want, Layout: n.Rect})
+		}
+	})
+	if len(mismatches) > 0 {
+		return &RoundTripError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// walkRects calls f on n's tree in preorder, with each node's path.
+func walkRects(n *layout.Node, path []int, f func(path []int, n *layout.Node)) {
+	if n == nil {
+		return
+	}
+	f(path, n)
+	for i, child := range n.Children {
+		walkRects(child, append(path, i), f)
+	}
+}
+
+// rectWithin reports whether the coordinates of a and b differ by at most
+// tolerance.
+func rectWithin(a, b layout.Rect, tolerance float64) bool {
+	return math.Abs(a.X-b.X) <= tolerance && math.Abs(a.Y-b.Y) <= tolerance &&
+		math.Abs(a.Width-b.Width) <= tolerance && math.Abs(a.Height-b.Height) <= tolerance
+}
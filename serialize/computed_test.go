@@ -0,0 +1,123 @@
+package serialize
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// computedTestTree returns a laid-out tree with a wrapped text node.
+func computedTestTree() (*layout.Node, RoundTripOptions) {
+	text := layout.Text("The quick brown fox jumps over the lazy dog")
+	text.Style.Display = layout.DisplayInlineText
+	text.Style.Width = layout.Px(80)
+	root := layout.VStack(layout.Fixed(100, 20), text)
+	opts := RoundTripOptions{Constraints: layout.Loose(200, 400), Context: layout.NewLayoutContext(200, 400, 16)}
+	layout.Layout(root, opts.Constraints, opts.Context)
+	return root, opts
+}
+
+func TestEncodeOmitRects(t *testing.T) {
+	root, _ := computedTestTree()
+	data, err := ToJSONWithOptions(root, EncodeOptions{OmitRects: true})
+	if err != nil {
+		t.Fatalf("ToJSONWithOptions failed: %v", err)
+	}
+	if bytes.Contains(data, []byte(`"rect"`)) {
+		t.Errorf("Expected no rects, got %s", data)
+	}
+	if data, _ := ToJSON(root); !bytes.Contains(data, []byte(`"rect"`)) {
+		t.Errorf("Expected ToJSON to write rects, got %s", data)
+	}
+}
+
+func TestEncodeTextLayout(t *testing.T) {
+	root, _ := computedTestTree()
+	want := root.Children[1].TextLayout
+	if want == nil || len(want.Lines) < 2 {
+		t.Fatalf("Expected wrapped text, got %+v", want)
+	}
+
+	if data, _ := ToJSON(root); bytes.Contains(data, []byte(`"textLayout"`)) {
+		t.Errorf("Expected ToJSON to leave out text layouts, got %s", data)
+	}
+
+	opts := EncodeOptions{TextLayout: true}
+	data, err := ToJSONWithOptions(root, opts)
+	if err != nil {
+		t.Fatalf("ToJSONWithOptions failed: %v", err)
+	}
+	fromJSON, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	bin, err := ToBinaryWithOptions(root, BinaryOptions{EncodeOptions: opts})
+	if err != nil {
+		t.Fatalf("ToBinaryWithOptions failed: %v", err)
+	}
+	fromBinary, err := FromBinary(bin)
+	if err != nil {
+		t.Fatalf("FromBinary failed: %v", err)
+	}
+
+	for name, loaded := range map[string]*layout.Node{"JSON": fromJSON, "binary": fromBinary} {
+		text := loaded.Children[1]
+		if text.Style.Display != layout.DisplayInlineText {
+			t.Errorf("%s: Expected display inline-text, got %v", name, text.Style.Display)
+		}
+		if text.Text != root.Children[1].Text {
+			t.Errorf("%s: Expected text %q, got %q", name, root.Children[1].Text, text.Text)
+		}
+		got := text.TextLayout
+		if got == nil {
+			t.Errorf("%s: Expected a text layout", name)
+			continue
+		}
+		if !reflect.DeepEqual(got.Lines, want.Lines) {
+			t.Errorf("%s: Expected lines %+v, got %+v", name, want.Lines, got.Lines)
+		}
+		if got.LineHeight != want.LineHeight || got.ContentX != want.ContentX || got.ContentY != want.ContentY {
+			t.Errorf("%s: Expected %+v, got %+v", name, want, got)
+		}
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	root, opts := computedTestTree()
+	data, _ := ToJSON(root)
+
+	loaded, err := FromJSONWithOptions(data, DecodeOptions{RoundTrip: &opts})
+	if err != nil {
+		t.Fatalf("Expected the layout to round-trip, got %v", err)
+	}
+	if loaded.Children[1].TextLayout == nil {
+		t.Error("Expected the loaded tree to be laid out")
+	}
+
+	// A stale cache
+	loaded, _ = FromJSON(data)
+	loaded.Children[1].Rect.Height += 0.5
+	loaded.Children[0].Rect.Width -= 0.001
+	opts.Tolerance = 0.01
+	err = VerifyRoundTrip(loaded, opts)
+	var roundTripErr *RoundTripError
+	if !errors.As(err, &roundTripErr) {
+		t.Fatalf("Expected *RoundTripError, got %v", err)
+	}
+	if len(roundTripErr.Mismatches) != 1 {
+		t.Fatalf("Expected 1 mismatch within tolerance 0.01, got %+v", roundTripErr.Mismatches)
+	}
+	m := roundTripErr.Mismatches[0]
+	if !reflect.DeepEqual(m.Path, []int{1}) {
+		t.Errorf("Expected path [1], got %v", m.Path)
+	}
+	if m.Serialized.Height != m.Layout.Height+0.5 {
+		t.Errorf("Expected serialized height %v, got %v", m.Layout.Height+0.5, m.Serialized.Height)
+	}
+	if loaded.Children[1].Rect != root.Children[1].Rect {
+		t.Errorf("Expected the tree to be left laid out, got %+v", loaded.Children[1].Rect)
+	}
+}
@@ -0,0 +1,232 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Changeset describes the differences between two node trees (see Diff).
+// It serializes to JSON, so it can be stored in regression reports or sent
+// to a remote renderer to bring its copy of a tree up to date.
+//
+// Every node appears at most once: removed nodes in the earlier tree's
+// order, then added, moved and changed nodes in the later tree's order.
+type Changeset struct {
+	Removed []NodeChange `json:"removed,omitempty"`
+	Added   []NodeChange `json:"added,omitempty"`
+	Moved   []NodeChange `json:"moved,omitempty"`
+	Changed []NodeChange `json:"changed,omitempty"`
+}
+
+// NodeChange is a node that was removed, added, moved or changed.
+type NodeChange struct {
+	// ID is the node's ID, if it has one.
+	ID string `json:"id,omitempty"`
+
+	// Path is the sequence of child indexes from the root to the node, in
+	// the later tree, or in the earlier tree for removed nodes.
+	Path []int `json:"path"`
+
+	// From is the path of a moved node in the earlier tree.
+	From []int `json:"from,omitempty"`
+
+	// Node is an added node, without its children, which are added
+	// themselves.
+	Node *NodeJSON `json:"node,omitempty"`
+
+	// Style lists the style properties of a moved or changed node that
+	// differ, by their JSON names, in alphabetical order.
+	Style []PropertyChange `json:"style,omitempty"`
+
+	// Rect is the change of a moved or changed node's rect, if any.
+	Rect *RectDiff `json:"rect,omitempty"`
+}
+
+// PropertyChange is a style property that differs between two nodes, with
+// its JSON values before and after. A property at its zero value is null.
+type PropertyChange struct {
+	Name string          `json:"name"`
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+// RectDiff is a node's rect before and after.
+type RectDiff struct {
+	From RectJSON `json:"from"`
+	To   RectJSON `json:"to"`
+}
+
+// Empty reports whether c has no changes.
+func (c *Changeset) Empty() bool {
+	return len(c.Removed) == 0 && len(c.Added) == 0 && len(c.Moved) == 0 && len(c.Changed) == 0
+}
+
+// Diff compares two node trees, typically a CloneDeep or deserialized copy
+// of a tree and the tree after a change, and returns what changed.
+//
+// Nodes are paired up like layout.DiffRects does: by identity when the same
+// *layout.Node is in both trees, then by ID, and then, among the nodes
+// without an ID, by their path. A paired node is moved if its path differs,
+// and changed if its style or rect does. Styles are compared normalized
+// (see layout.Style.Normalize). Nodes without a partner are removed or
+// added.
+//
+// Example:
+//
+//	before := root.CloneDeep()
+//	applyEdits(root)
+//	layout.Layout(root, constraints, ctx)
+//	changes := serialize.Diff(before, root)
+//	data, _ := json.Marshal(changes)
+func Diff(a, b *layout.Node) *Changeset {
+	var old, cur []diffNode
+	flattenTree(a, nil, &old)
+	flattenTree(b, nil, &cur)
+
+	byNode := make(map[*layout.Node]int, len(old))
+	byID := make(map[string]int)
+	byPath := make(map[string]int, len(old))
+	for i, e := range old {
+		byNode[e.node] = i
+		if e.node.ID != "" {
+			if _, ok := byID[e.node.ID]; !ok {
+				byID[e.node.ID] = i
+			}
+		} else {
+			byPath[pathKey(e.path)] = i
+		}
+	}
+
+	// Pair by identity and ID first, so a node keeps its partner even if
+	// another one took its place in the tree
+	pair := make([]int, len(cur))
+	taken := make([]bool, len(old))
+	for i, e := range cur {
+		pair[i] = -1
+		j, ok := byNode[e.node]
+		if !ok && e.node.ID != "" {
+			j, ok = byID[e.node.ID]
+		}
+		if ok && !taken[j] {
+			pair[i], taken[j] = j, true
+		}
+	}
+	for i, e := range cur {
+		if pair[i] >= 0 || e.node.ID != "" {
+			continue
+		}
+		if j, ok := byPath[pathKey(e.path)]; ok && !taken[j] {
+			pair[i], taken[j] = j, true
+		}
+	}
+
+	c := &Changeset{}
+	for j, o := range old {
+		if !taken[j] {
+			c.Removed = append(c.Removed, NodeChange{ID: o.node.ID, Path: o.path})
+		}
+	}
+	for i, e := range cur {
+		if pair[i] < 0 {
+			node := nodeToJSON(e.node)
+			node.Children = nil
+			c.Added = append(c.Added, NodeChange{ID: e.node.ID, Path: e.path, Node: node})
+			continue
+		}
+		o := old[pair[i]]
+		change := NodeChange{ID: e.node.ID, Path: e.path}
+		change.Style = diffStyles(&o.node.Style, &e.node.Style)
+		if o.node.Rect != e.node.Rect {
+			change.Rect = &RectDiff{From: rectToJSON(&o.node.Rect), To: rectToJSON(&e.node.Rect)}
+		}
+		switch {
+		case pathKey(o.path) != pathKey(e.path):
+			change.From = o.path
+			c.Moved = append(c.Moved, change)
+		case change.Style != nil || change.Rect != nil:
+			c.Changed = append(c.Changed, change)
+		}
+	}
+	return c
+}
+
+// diffNode is a node of a tree compared by Diff, with its path from the
+// root.
+type diffNode struct {
+	node *layout.Node
+	path []int
+}
+
+// flattenTree appends n and its descendants to nodes in tree order.
+func flattenTree(n *layout.Node, path []int, nodes *[]diffNode) {
+	if n == nil {
+		return
+	}
+	*nodes = append(*nodes, diffNode{node: n, path: append([]int{}, path...)})
+	for i, child := range n.Children {
+		flattenTree(child, append(path, i), nodes)
+	}
+}
+
+// pathKey returns a map key for path ("/0/2/1").
+func pathKey(path []int) string {
+	var b bytes.Buffer
+	for _, i := range path {
+		fmt.Fprintf(&b, "/%d", i)
+	}
+	return b.String()
+}
+
+// diffStyles returns the properties of the JSON forms of two styles that
+// differ, once normalized, so values that lay out the same aren't reported.
+func diffStyles(a, b *layout.Style) []PropertyChange {
+	na, nb := *a, *b
+	na.Normalize()
+	nb.Normalize()
+	from, to := styleProperties(&na), styleProperties(&nb)
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []PropertyChange
+	for _, name := range names {
+		f, t := from[name], to[name]
+		if bytes.Equal(f, t) {
+			continue
+		}
+		changes = append(changes, PropertyChange{Name: name, From: orNull(f), To: orNull(t)})
+	}
+	return changes
+}
+
+// styleProperties returns the properties of s's JSON form by name.
+func styleProperties(s *layout.Style) map[string]json.RawMessage {
+	data, err := json.Marshal(styleToJSON(s))
+	if err != nil {
+		return nil
+	}
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil
+	}
+	return props
+}
+
+// orNull returns v, or null for a property that is omitted.
+func orNull(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return v
+}
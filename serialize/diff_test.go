@@ -0,0 +1,99 @@
+package serialize
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestDiffIdenticalTrees(t *testing.T) {
+	root := layout.VStack(layout.Fixed(100, 50), layout.Fixed(80, 40))
+	layout.Layout(root, layout.Loose(200, 200), layout.NewLayoutContext(200, 200, 16))
+
+	if c := Diff(root.CloneDeep(), root); !c.Empty() {
+		t.Errorf("Expected no changes, got %+v", c)
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	title := layout.Fixed(100, 20)
+	title.ID = "title"
+	body := layout.Fixed(100, 50)
+	body.ID = "body"
+	footer := layout.Fixed(100, 10)
+	footer.ID = "footer"
+	root := layout.VStack(title, body, footer)
+	ctx := layout.NewLayoutContext(200, 200, 16)
+	layout.Layout(root, layout.Loose(200, 200), ctx)
+	before := root.CloneDeep()
+
+	// Drop the title, widen the body and add a node at the end
+	root.Children = []*layout.Node{body, footer, layout.Fixed(30, 30)}
+	body.Style.Width = layout.Px(120)
+	layout.Layout(root, layout.Loose(200, 200), ctx)
+
+	c := Diff(before, root)
+
+	if len(c.Removed) != 1 || c.Removed[0].ID != "title" || pathKey(c.Removed[0].Path) != "/0" {
+		t.Errorf("Expected the title to be removed, got %+v", c.Removed)
+	}
+	if len(c.Added) != 1 || pathKey(c.Added[0].Path) != "/2" || c.Added[0].Node.Style.Width != 30 {
+		t.Errorf("Expected a 30px node added at /2, got %+v", c.Added)
+	}
+	if len(c.Moved) != 2 {
+		t.Fatalf("Expected the body and footer to move, got %+v", c.Moved)
+	}
+	moved := c.Moved[0]
+	if moved.ID != "body" || pathKey(moved.From) != "/1" || pathKey(moved.Path) != "/0" {
+		t.Errorf("Expected the body to move from /1 to /0, got %+v", moved)
+	}
+	if len(moved.Style) != 1 || moved.Style[0].Name != "width" || string(moved.Style[0].From) != "100" || string(moved.Style[0].To) != "120" {
+		t.Errorf("Expected width to change from 100 to 120, got %+v", moved.Style)
+	}
+	if moved.Rect == nil || moved.Rect.To.Y != 0 || moved.Rect.To.Width != 120 {
+		t.Errorf("Expected the body's rect to change, got %+v", moved.Rect)
+	}
+	if c.Moved[1].ID != "footer" || pathKey(c.Moved[1].From) != "/2" || pathKey(c.Moved[1].Path) != "/1" {
+		t.Errorf("Expected the footer to move from /2 to /1, got %+v", c.Moved[1])
+	}
+	if len(c.Changed) != 1 || pathKey(c.Changed[0].Path) != "" || c.Changed[0].Rect == nil {
+		t.Errorf("Expected the root's rect to change, got %+v", c.Changed)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Changeset
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded.Moved) != 2 || decoded.Moved[1].ID != "footer" {
+		t.Errorf("Expected the changeset to round-trip through JSON, got %s", data)
+	}
+}
+
+func TestDiffPropertyToZero(t *testing.T) {
+	a := &layout.Node{Style: layout.Style{FlexGrow: 1}}
+	b := &layout.Node{}
+
+	c := Diff(a, b)
+
+	if len(c.Changed) != 1 || len(c.Changed[0].Style) != 1 {
+		t.Fatalf("Expected one style change, got %+v", c)
+	}
+	p := c.Changed[0].Style[0]
+	if p.Name != "flexGrow" || string(p.From) != "1" || string(p.To) != "null" {
+		t.Errorf("Expected flexGrow 1 -> null, got %s %s -> %s", p.Name, p.From, p.To)
+	}
+}
+
+func TestDiffIgnoresEquivalentStyles(t *testing.T) {
+	a := &layout.Node{Style: layout.Style{Padding: layout.Uniform(layout.Px(0)), Transform: layout.IdentityTransform()}}
+	b := &layout.Node{}
+
+	if c := Diff(a, b); !c.Empty() {
+		t.Errorf("Expected no changes between equivalent styles, got %+v", c.Changed)
+	}
+}
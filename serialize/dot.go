@@ -0,0 +1,111 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// ToDOT converts a layout.Node tree to a Graphviz graph in the DOT
+// language, one box per node with edges from parents to their children, to
+// visualize large or generated trees:
+//
+//	data := serialize.ToDOT(root)
+//	os.WriteFile("tree.dot", data, 0o644) // dot -Tsvg tree.dot > tree.svg
+//
+// Each box is labeled with the node's display type, tag, ID and classes,
+// its text, its rect, and the style properties that differ from the zero
+// Style, by their JSON names. Nodes with DisplayNone are drawn dashed, and
+// nodes laid out with no area, which are often structural mistakes, are
+// drawn in red.
+func ToDOT(root *layout.Node) []byte {
+	var b bytes.Buffer
+	b.WriteString("digraph layout {\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\", fontsize=10];\n")
+	if root != nil {
+		defaults := styleProperties(&layout.Style{})
+		next := 0
+		writeDOTNode(&b, root, defaults, &next)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// writeDOTNode writes n's box and its subtree's, naming boxes n0, n1, ... in
+// tree order, and returns n's name.
+func writeDOTNode(b *bytes.Buffer, n *layout.Node, defaults map[string]json.RawMessage, next *int) string {
+	name := fmt.Sprintf("n%d", *next)
+	*next++
+
+	var attrs []string
+	attrs = append(attrs, "label="+dotQuote(dotLabel(n, defaults)))
+	if n.Style.Display == layout.DisplayNone {
+		attrs = append(attrs, "style=dashed")
+	} else if n.Rect.Width <= 0 || n.Rect.Height <= 0 {
+		attrs = append(attrs, "color=red")
+	}
+	fmt.Fprintf(b, "  %s [%s];\n", name, strings.Join(attrs, ", "))
+
+	for _, child := range n.Children {
+		if child == nil {
+			continue
+		}
+		childName := writeDOTNode(b, child, defaults, next)
+		fmt.Fprintf(b, "  %s -> %s;\n", name, childName)
+	}
+	return name
+}
+
+// dotLabel returns the lines of n's label, each ending in a newline.
+func dotLabel(n *layout.Node, defaults map[string]json.RawMessage) string {
+	var lines []string
+
+	head := displayToString(n.Style.Display)
+	if head == "" {
+		head = "block"
+	}
+	if n.Tag != "" {
+		head += " <" + n.Tag + ">"
+	}
+	if n.ID != "" {
+		head += " #" + n.ID
+	}
+	for _, class := range n.Classes {
+		head += " ." + class
+	}
+	lines = append(lines, head)
+	if n.Text != "" {
+		lines = append(lines, fmt.Sprintf("%q", n.Text))
+	}
+	lines = append(lines, fmt.Sprintf("[%g, %g, %g×%g]", n.Rect.X, n.Rect.Y, n.Rect.Width, n.Rect.Height))
+
+	props := styleProperties(&n.Style)
+	names := make([]string, 0, len(props))
+	for name, value := range props {
+		if name != "display" && !bytes.Equal(value, defaults[name]) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := string(props[name])
+		var s string
+		if json.Unmarshal(props[name], &s) == nil {
+			value = s
+		}
+		lines = append(lines, name+": "+value)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// dotQuote quotes s as a DOT string, with its lines left-justified.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\l`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,38 @@
+package serialize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestToDOT(t *testing.T) {
+	logo := layout.Fixed(32, 32)
+	logo.ID = "logo"
+	empty := &layout.Node{}
+	hidden := &layout.Node{Style: layout.Style{Display: layout.DisplayNone}}
+	root := layout.HStack(logo, empty, hidden)
+	root.Classes = []string{"toolbar"}
+	root.Style.Padding = layout.Uniform(layout.Px(8))
+	layout.Layout(root, layout.Loose(200, 200), layout.NewLayoutContext(200, 200, 16))
+
+	dot := string(ToDOT(root))
+	for _, want := range []string{
+		"digraph layout {\n",
+		`  n0 [label="flex .toolbar\l[0, 0, 48×200]\lpadding: {\"top\":8,\"right\":8,\"bottom\":8,\"left\":8}\l"];`,
+		`  n1 [label="block #logo\l[8, 8, 32×32]\lheight: 32\lwidth: 32\l"];`,
+		"  n0 -> n1;\n",
+		`  n2 [label="block\l[40, 8, 0×184]\l", color=red];`,
+		"  n0 -> n2;\n",
+		"style=dashed];\n  n0 -> n3;\n}\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %q in:\n%s", want, dot)
+		}
+	}
+
+	if got := string(ToDOT(nil)); strings.Contains(got, "n0") {
+		t.Errorf("Expected an empty graph, got %q", got)
+	}
+}
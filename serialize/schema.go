@@ -0,0 +1,158 @@
+package serialize
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Schema returns a JSON Schema (draft 2020-12) of the JSON format, for
+// tools and editors to validate fixture files and complete their
+// properties. It's generated from NodeJSON, so it has every property
+// FromJSON reads, and no others: like FromJSON, it rejects unknown
+// properties. Enumerated properties list their values.
+//
+// Example, for editors that read a file's "$schema" property:
+//
+//	os.WriteFile("layout.schema.json", serialize.Schema(), 0o644)
+//	// and in fixtures: {"$schema": "./layout.schema.json", "style": {...}}
+func Schema() []byte {
+	defs := make(map[string]any)
+	doc := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Layout tree",
+		"description": "A tree of github.com/SCKelemen/layout nodes, as serialize.ToJSON writes it",
+		"$ref":        schemaType(reflect.TypeFor[NodeJSON](), "", defs)["$ref"],
+		"$defs":       defs,
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic(err) // Maps of strings, numbers and slices always marshal
+	}
+	return data
+}
+
+// schemaType returns the schema of values of type t, adding the schemas
+// of struct types to defs. prop is the "Type.property" t is the type of,
+// for its annotations in schemaAnnotations.
+func schemaType(t reflect.Type, prop string, defs map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var s map[string]any
+	switch t.Kind() {
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = map[string]any{"type": "number"}
+	case reflect.String:
+		s = map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		s = map[string]any{"type": "array", "items": schemaType(t.Elem(), prop+"[]", defs)}
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": schemaType(t.Elem(), prop+"{}", defs)}
+	case reflect.Struct:
+		name := strings.TrimSuffix(t.Name(), "JSON")
+		if _, ok := defs[name]; !ok {
+			def := map[string]any{"type": "object", "additionalProperties": false}
+			defs[name] = def // Before the fields, for recursive types
+			properties := make(map[string]any)
+			for _, f := range structFields(t).fields {
+				properties[f.name] = schemaType(t.Field(f.index).Type, name+"."+f.name, defs)
+			}
+			def["properties"] = properties
+		}
+		s = map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		s = map[string]any{}
+	}
+	for k, v := range schemaAnnotations[prop] {
+		s[k] = v
+	}
+	return s
+}
+
+// schemaAnnotations are the enumerated values and descriptions of
+// properties, by "Type.property" ("[]" for the items of an array, "{}"
+// for the values of an object).
+var schemaAnnotations = map[string]map[string]any{
+	"Node.version": {
+		"description": "Format version of the data, on the root; omitted is version 1",
+		"minimum":     1,
+		"maximum":     FormatVersion,
+	},
+	"Node.$schema":    {"description": "URI of the JSON Schema of the data, for editors; ignored"},
+	"Node.rect":       {"description": "Computed border box, relative to the parent's"},
+	"Node.textLayout": {"description": "Computed lines of a text node"},
+
+	"Style.display":        {"enum": enumValues(displayToString)},
+	"Style.flexDirection":  {"enum": enumValues(flexDirectionToString)},
+	"Style.flexWrap":       {"enum": enumValues(flexWrapToString)},
+	"Style.justifyContent": {"enum": enumValues(justifyContentToString)},
+	"Style.alignItems":     {"enum": enumValues(alignItemsToString)},
+	"Style.justifyItems":   {"enum": enumValues(justifyItemsToString)},
+	"Style.alignContent": {
+		"enum":        overflowAlignmentValues(enumValues(alignContentToString)),
+		"description": "Optionally prefixed with \"safe \" or \"unsafe \"",
+	},
+	"Style.flexBasisKind": {
+		"enum":        []string{"content", "length", "percent"},
+		"description": "Omitted is auto, or a length for a non-zero flexBasis",
+	},
+	"Style.minWidth":  {"description": "Omitted is auto: flex items get their automatic minimum size"},
+	"Style.minHeight": {"description": "Omitted is auto: flex items get their automatic minimum size"},
+	"Style.percent[]": {
+		"enum":        sizeNames,
+		"description": "A size that is a percentage",
+	},
+	"Style.calc": {
+		"propertyNames": map[string]any{"enum": sizeNames},
+		"description":   "calc() expressions of sizes, like {\"width\": \"calc(100% - 32px)\"}; the size is the scale factor",
+	},
+	"Style.boxSizing":       {"enum": enumValues(boxSizingToString)},
+	"Style.marginCollapse":  {"enum": []string{"full"}, "description": "Omitted collapses siblings only"},
+	"Style.columnGap":       {"description": "Omitted is normal (1em)"},
+	"Style.breakBefore":     {"enum": enumValues(breakBetweenToString)},
+	"Style.breakAfter":      {"enum": enumValues(breakBetweenToString)},
+	"Style.breakInside":     {"enum": []string{"avoid"}},
+	"Style.position":        {"enum": enumValues(positionToString)},
+	"Style.visibility":      {"enum": []string{"visible", "hidden"}, "description": "Omitted inherits the parent's"},
+	"Style.overflow":        {"enum": []string{"hidden", "scroll", "auto"}, "description": "Omitted is visible"},
+	"Style.scrollbarGutter": {"enum": []string{"stable", "stable both-edges"}, "description": "Omitted is auto"},
+
+	"Spacing.auto[]": {"enum": []string{"top", "right", "bottom", "left"}, "description": "A side that is auto (margins only)"},
+}
+
+// sizeNames are the sizes of the "percent" and "calc" style properties
+// (see sizeLength).
+var sizeNames = []string{"width", "height", "minWidth", "minHeight", "maxWidth", "maxHeight"}
+
+// enumValues returns the values toString writes for an enumeration, in
+// the order of its constants.
+func enumValues[T ~int](toString func(T) string) []string {
+	var values []string
+	seen := make(map[string]bool)
+	for i := range 64 {
+		if v := toString(T(i)); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// overflowAlignmentValues returns values with their safe and unsafe
+// variants (see overflowAlignmentPrefix).
+func overflowAlignmentValues(values []string) []string {
+	all := append([]string{}, values...)
+	for _, o := range []layout.OverflowAlignment{layout.OverflowAlignmentSafe, layout.OverflowAlignmentUnsafe} {
+		for _, v := range values {
+			all = append(all, overflowAlignmentPrefix(o)+v)
+		}
+	}
+	return all
+}
@@ -0,0 +1,157 @@
+package serialize
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// validateSchema checks v against the subset of JSON Schema that Schema
+// uses, returning the path of each violation.
+func validateSchema(s map[string]any, defs map[string]any, v any, path string) []string {
+	if ref, ok := s["$ref"].(string); ok {
+		return validateSchema(defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), defs, v, path)
+	}
+	var errs []string
+	if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, v) {
+		errs = append(errs, fmt.Sprintf("%s: %v isn't one of %v", path, v, enum))
+	}
+	switch s["type"] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return append(errs, path+": not an object")
+		}
+		properties, _ := s["properties"].(map[string]any)
+		for name, value := range obj {
+			if names, ok := s["propertyNames"].(map[string]any); ok {
+				errs = append(errs, validateSchema(names, defs, name, path+"."+name)...)
+			}
+			switch ps, ok := properties[name].(map[string]any); {
+			case ok:
+				errs = append(errs, validateSchema(ps, defs, value, path+"."+name)...)
+			case s["additionalProperties"] == false:
+				errs = append(errs, path+"."+name+": unknown property")
+			default:
+				if as, ok := s["additionalProperties"].(map[string]any); ok {
+					errs = append(errs, validateSchema(as, defs, value, path+"."+name)...)
+				}
+			}
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return append(errs, path+": not an array")
+		}
+		for i, item := range items {
+			errs = append(errs, validateSchema(s["items"].(map[string]any), defs, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			errs = append(errs, path+": not a string")
+		}
+	case "number", "integer":
+		if _, ok := v.(float64); !ok {
+			errs = append(errs, path+": not a number")
+		}
+	}
+	return errs
+}
+
+func loadSchema(t *testing.T) map[string]any {
+	t.Helper()
+	var schema map[string]any
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	return schema
+}
+
+func TestSchemaValidatesSerializedTrees(t *testing.T) {
+	schema := loadSchema(t)
+	defs := schema["$defs"].(map[string]any)
+
+	child := layout.Fixed(100, 20)
+	child.Style.AlignContent = layout.AlignContentCenter
+	child.Style.AlignContentOverflow = layout.OverflowAlignmentSafe
+	child.Style.Width = layout.Percent(50)
+	child.Style.Margin.Left = layout.Auto()
+	child.Style.Position = layout.PositionAbsolute
+	child.Style.Overflow = layout.OverflowHidden
+	grid := layout.Grid(2, 2, 50, 50)
+	grid.Style.BoxSizing = layout.BoxSizingBorderBox
+	root, opts := computedTestTree()
+	root.Children = append(root.Children, child, grid)
+	root.ID = "root"
+	root.Classes = []string{"a"}
+	root.Attributes = map[string]string{"role": "list"}
+	layout.Layout(root, opts.Constraints, opts.Context)
+
+	data, err := ToJSONWithOptions(root, EncodeOptions{TextLayout: true})
+	if err != nil {
+		t.Fatalf("ToJSONWithOptions failed: %v", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if errs := validateSchema(schema, defs, doc, ""); len(errs) > 0 {
+		t.Errorf("Expected the serialized tree to be valid, got %v", errs)
+	}
+}
+
+func TestSchemaRejectsInvalidData(t *testing.T) {
+	schema := loadSchema(t)
+	defs := schema["$defs"].(map[string]any)
+	var doc any
+	json.Unmarshal([]byte(`{"style": {"display": "table", "widht": 10, "percent": ["top"]}, "children": [{"tag": 1}]}`), &doc)
+	errs := strings.Join(validateSchema(schema, defs, doc, ""), "\n")
+	for _, want := range []string{".style.display: table", ".style.widht: unknown", ".style.percent[0]: top", ".children[0].tag: not a string"} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("Expected a violation %q, got %s", want, errs)
+		}
+	}
+}
+
+func TestSchemaEnums(t *testing.T) {
+	defs := loadSchema(t)["$defs"].(map[string]any)
+	style := defs["Style"].(map[string]any)["properties"].(map[string]any)
+	display := style["display"].(map[string]any)["enum"].([]any)
+	for _, want := range []string{"block", "flex", "grid", "inline-text", "none"} {
+		if !slices.Contains(display, any(want)) {
+			t.Errorf("Expected display %q in %v", want, display)
+		}
+	}
+	// Every enumerated value decodes to what it names
+	for _, v := range display {
+		if got := displayToString(stringToDisplay(v.(string))); got != v {
+			t.Errorf("Expected display %v to round-trip, got %q", v, got)
+		}
+	}
+	for _, v := range style["alignContent"].(map[string]any)["enum"].([]any) {
+		s := jsonToStyle(&StyleJSON{AlignContent: v.(string)})
+		sj := styleToJSON(&s)
+		if back := jsonToStyle(&sj); back.AlignContent != s.AlignContent || back.AlignContentOverflow != s.AlignContentOverflow {
+			t.Errorf("Expected align-content %v to round-trip, got %q", v, sj.AlignContent)
+		}
+	}
+}
+
+func TestSchemaProperty(t *testing.T) {
+	// Fixtures can name their schema for editors
+	root, err := FromJSON([]byte(`{"$schema": "./layout.schema.json", "style": {"width": 10}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if root.Style.Width.Value != 10 {
+		t.Errorf("Expected width 10, got %v", root.Style.Width)
+	}
+	properties := loadSchema(t)["$defs"].(map[string]any)["Node"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := properties["$schema"]; !ok {
+		t.Error("Expected a $schema property")
+	}
+}
@@ -2,15 +2,32 @@ package serialize
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/SCKelemen/layout"
 )
 
 // NodeJSON represents a serializable version of layout.Node
 type NodeJSON struct {
-	Style    StyleJSON   `json:"style"`
-	Children []*NodeJSON `json:"children,omitempty"`
-	Rect     RectJSON    `json:"rect,omitempty"`
+	// Version is the format version of the data (see FormatVersion). It's
+	// set on the root only.
+	Version int `json:"version,omitempty"`
+
+	// Schema is the URI of a JSON Schema of the data (see Schema()), for
+	// editors. It's ignored.
+	Schema string `json:"$schema,omitempty"`
+
+	Tag        string            `json:"tag,omitempty"`
+	ID         string            `json:"id,omitempty"`
+	Classes    []string          `json:"classes,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Text       string            `json:"text,omitempty"`
+	Style      StyleJSON         `json:"style"`
+	Children   []*NodeJSON       `json:"children,omitempty"`
+
+	// Rect and TextLayout are the computed layout (see EncodeOptions)
+	Rect       RectJSON        `json:"rect,omitzero"`
+	TextLayout *TextLayoutJSON `json:"textLayout,omitempty"`
 }
 
 // StyleJSON represents a serializable version of layout.Style
@@ -25,6 +42,7 @@ type StyleJSON struct {
 	FlexGrow       float64 `json:"flexGrow,omitempty"`
 	FlexShrink     float64 `json:"flexShrink,omitempty"`
 	FlexBasis      float64 `json:"flexBasis,omitempty"`
+	FlexBasisKind  string  `json:"flexBasisKind,omitempty"` // "content", "length" or "percent"; empty is auto, or length for a non-zero flexBasis
 	FlexGap        float64 `json:"flexGap,omitempty"`
 	FlexRowGap     float64 `json:"flexRowGap,omitempty"`
 	FlexColumnGap  float64 `json:"flexColumnGap,omitempty"`
@@ -43,13 +61,21 @@ type StyleJSON struct {
 	GridColumnEnd       int         `json:"gridColumnEnd,omitempty"`
 
 	// Sizing
-	Width       float64 `json:"width,omitempty"`
-	Height      float64 `json:"height,omitempty"`
-	MinWidth    float64 `json:"minWidth,omitempty"`
-	MinHeight   float64 `json:"minHeight,omitempty"`
-	MaxWidth    float64 `json:"maxWidth,omitempty"`
-	MaxHeight   float64 `json:"maxHeight,omitempty"`
-	AspectRatio float64 `json:"aspectRatio,omitempty"`
+	Width       float64  `json:"width,omitempty"`
+	Height      float64  `json:"height,omitempty"`
+	MinWidth    *float64 `json:"minWidth,omitempty"`  // Omitted = auto (flex items get their automatic minimum size)
+	MinHeight   *float64 `json:"minHeight,omitempty"` // Omitted = auto
+	MaxWidth    float64  `json:"maxWidth,omitempty"`
+	MaxHeight   float64  `json:"maxHeight,omitempty"`
+	AspectRatio float64  `json:"aspectRatio,omitempty"`
+
+	// Percent lists the sizes above that are percentages ("width",
+	// "height", "minWidth", "minHeight", "maxWidth", "maxHeight")
+	Percent []string `json:"percent,omitempty"`
+
+	// Calc maps sizes that are calc() lengths to their expression, e.g.
+	// {"width": "calc(100% - 32px)"}; the size above is the scale factor
+	Calc map[string]string `json:"calc,omitempty"`
 
 	// Spacing
 	Padding SpacingJSON `json:"padding,omitempty"`
@@ -59,6 +85,20 @@ type StyleJSON struct {
 	// Box model
 	BoxSizing string `json:"boxSizing,omitempty"`
 
+	// MarginCollapse is "full" for MarginCollapseFull; omitted means siblings only
+	MarginCollapse string `json:"marginCollapse,omitempty"`
+
+	// Multi-column
+	ColumnCount     int      `json:"columnCount,omitempty"`
+	ColumnWidth     float64  `json:"columnWidth,omitempty"`
+	ColumnGap       *float64 `json:"columnGap,omitempty"` // Omitted = normal (1em)
+	ColumnRuleWidth float64  `json:"columnRuleWidth,omitempty"`
+
+	// Fragmentation: "avoid" or "page"; breakInside is "avoid"
+	BreakBefore string `json:"breakBefore,omitempty"`
+	BreakAfter  string `json:"breakAfter,omitempty"`
+	BreakInside string `json:"breakInside,omitempty"`
+
 	// Positioning
 	Position string  `json:"position,omitempty"`
 	Top      float64 `json:"top,omitempty"`
@@ -69,6 +109,15 @@ type StyleJSON struct {
 
 	// Transform
 	Transform TransformJSON `json:"transform,omitempty"`
+
+	// Visibility is "visible" or "hidden"; omitted inherits the parent's
+	Visibility string `json:"visibility,omitempty"`
+
+	// Overflow is "hidden", "scroll" or "auto"; omitted is visible
+	Overflow string `json:"overflow,omitempty"`
+
+	// ScrollbarGutter is "stable" or "stable both-edges"; omitted is auto
+	ScrollbarGutter string `json:"scrollbarGutter,omitempty"`
 }
 
 // TrackJSON represents a serializable version of layout.GridTrack
@@ -84,6 +133,10 @@ type SpacingJSON struct {
 	Right  float64 `json:"right,omitempty"`
 	Bottom float64 `json:"bottom,omitempty"`
 	Left   float64 `json:"left,omitempty"`
+
+	// Auto lists the sides that are auto ("top", "right", "bottom", "left").
+	// Only margins support auto.
+	Auto []string `json:"auto,omitempty"`
 }
 
 // RectJSON represents a serializable version of layout.Rect
@@ -106,17 +159,35 @@ type TransformJSON struct {
 
 // ToJSON converts a layout.Node to JSON bytes
 func ToJSON(node *layout.Node) ([]byte, error) {
-	nodeJSON := nodeToJSON(node)
+	return ToJSONWithOptions(node, EncodeOptions{})
+}
+
+// ToJSONWithOptions converts a layout.Node to JSON bytes, with options for
+// the computed layout written.
+func ToJSONWithOptions(node *layout.Node, opts EncodeOptions) ([]byte, error) {
+	nodeJSON := documentToJSON(node, opts)
 	return json.MarshalIndent(nodeJSON, "", "  ")
 }
 
-// FromJSON converts JSON bytes to a layout.Node
+// FromJSON converts JSON bytes to a layout.Node. Data of an older format
+// version is migrated (see FormatVersion), and properties that aren't in
+// the format are an *UnknownFieldsError; see FromJSONWithOptions to skip
+// them.
 func FromJSON(data []byte) (*layout.Node, error) {
-	var nodeJSON NodeJSON
-	if err := json.Unmarshal(data, &nodeJSON); err != nil {
-		return nil, err
+	return FromJSONWithOptions(data, DecodeOptions{})
+}
+
+// documentToJSON converts the root of a document to NodeJSON, with the
+// format version and the computed layout of opts.
+func documentToJSON(node *layout.Node, opts EncodeOptions) *NodeJSON {
+	nodeJSON := nodeToJSON(node)
+	if nodeJSON != nil {
+		nodeJSON.Version = FormatVersion
+		if opts != (EncodeOptions{}) {
+			computedToJSON(nodeJSON, node, opts)
+		}
 	}
-	return jsonToNode(&nodeJSON), nil
+	return nodeJSON
 }
 
 // nodeToJSON converts a layout.Node to NodeJSON
@@ -126,8 +197,13 @@ func nodeToJSON(node *layout.Node) *NodeJSON {
 	}
 
 	nj := &NodeJSON{
-		Style: styleToJSON(&node.Style),
-		Rect:  rectToJSON(&node.Rect),
+		Tag:        node.Tag,
+		ID:         node.ID,
+		Classes:    node.Classes,
+		Attributes: node.Attributes,
+		Text:       node.Text,
+		Style:      styleToJSON(&node.Style),
+		Rect:       rectToJSON(&node.Rect),
 	}
 
 	if len(node.Children) > 0 {
@@ -147,8 +223,14 @@ func jsonToNode(nj *NodeJSON) *layout.Node {
 	}
 
 	node := &layout.Node{
-		Style: jsonToStyle(&nj.Style),
-		Rect:  jsonToRect(&nj.Rect),
+		Tag:        nj.Tag,
+		ID:         nj.ID,
+		Classes:    nj.Classes,
+		Attributes: nj.Attributes,
+		Text:       nj.Text,
+		Style:      jsonToStyle(&nj.Style),
+		Rect:       jsonToRect(&nj.Rect),
+		TextLayout: jsonToTextLayout(nj.TextLayout),
 	}
 
 	if len(nj.Children) > 0 {
@@ -166,14 +248,14 @@ func styleToJSON(s *layout.Style) StyleJSON {
 	sj := StyleJSON{
 		Width:           s.Width.Value,
 		Height:          s.Height.Value,
-		MinWidth:        s.MinWidth.Value,
-		MinHeight:       s.MinHeight.Value,
+		MinWidth:        optionalLengthToJSON(s.MinWidth),
+		MinHeight:       optionalLengthToJSON(s.MinHeight),
 		MaxWidth:        s.MaxWidth.Value,
 		MaxHeight:       s.MaxHeight.Value,
 		AspectRatio:     s.AspectRatio,
 		FlexGrow:        s.FlexGrow,
 		FlexShrink:      s.FlexShrink,
-		FlexBasis:       s.FlexBasis.Value,
+		FlexBasis:       s.FlexBasis.Length.Value,
 		FlexGap:         s.FlexGap.Value,
 		FlexRowGap:      s.FlexRowGap.Value,
 		FlexColumnGap:   s.FlexColumnGap.Value,
@@ -210,17 +292,68 @@ func styleToJSON(s *layout.Style) StyleJSON {
 	}
 	// Serialize AlignItems (Default/0 will be omitted due to omitempty)
 	sj.AlignItems = alignItemsToString(s.AlignItems)
-	if s.AlignContent != 0 {
-		sj.AlignContent = alignContentToString(s.AlignContent)
+	if s.AlignContent != 0 || s.AlignContentOverflow != 0 {
+		sj.AlignContent = overflowAlignmentPrefix(s.AlignContentOverflow) + alignContentToString(s.AlignContent)
 	}
 	// Serialize JustifyItems (Default/0 will be omitted due to omitempty)
 	sj.JustifyItems = justifyItemsToString(s.JustifyItems)
+	sj.FlexBasisKind = flexBasisKindToString(s.FlexBasis)
 	if s.BoxSizing != 0 {
 		sj.BoxSizing = boxSizingToString(s.BoxSizing)
 	}
+	if s.MarginCollapse == layout.MarginCollapseFull {
+		sj.MarginCollapse = "full"
+	}
+	for _, size := range []struct {
+		name string
+		l    layout.Length
+	}{
+		{"width", s.Width}, {"height", s.Height},
+		{"minWidth", s.MinWidth}, {"minHeight", s.MinHeight},
+		{"maxWidth", s.MaxWidth}, {"maxHeight", s.MaxHeight},
+	} {
+		if size.l.Unit == layout.PercentUnit {
+			sj.Percent = append(sj.Percent, size.name)
+		}
+		if strings.HasPrefix(string(size.l.Unit), "calc(") {
+			if sj.Calc == nil {
+				sj.Calc = make(map[string]string)
+			}
+			sj.Calc[size.name] = string(size.l.Unit)
+		}
+	}
+	sj.ColumnCount = s.ColumnCount
+	sj.ColumnWidth = s.ColumnWidth.Value
+	sj.ColumnGap = optionalLengthToJSON(s.ColumnGap)
+	sj.ColumnRuleWidth = s.ColumnRuleWidth.Value
+	sj.BreakBefore = breakBetweenToString(s.BreakBefore)
+	sj.BreakAfter = breakBetweenToString(s.BreakAfter)
+	if s.BreakInside == layout.BreakInsideAvoid {
+		sj.BreakInside = "avoid"
+	}
 	if s.Position != 0 {
 		sj.Position = positionToString(s.Position)
 	}
+	switch s.Visibility {
+	case layout.VisibilityVisible:
+		sj.Visibility = "visible"
+	case layout.VisibilityHidden:
+		sj.Visibility = "hidden"
+	}
+	switch s.Overflow {
+	case layout.OverflowHidden:
+		sj.Overflow = "hidden"
+	case layout.OverflowScroll:
+		sj.Overflow = "scroll"
+	case layout.OverflowAuto:
+		sj.Overflow = "auto"
+	}
+	switch s.ScrollbarGutter {
+	case layout.ScrollbarGutterStable:
+		sj.ScrollbarGutter = "stable"
+	case layout.ScrollbarGutterStableBothEdges:
+		sj.ScrollbarGutter = "stable both-edges"
+	}
 
 	// Convert grid tracks
 	if len(s.GridTemplateRows) > 0 {
@@ -250,14 +383,14 @@ func jsonToStyle(sj *StyleJSON) layout.Style {
 	s := layout.Style{
 		Width:           layout.Px(sj.Width),
 		Height:          layout.Px(sj.Height),
-		MinWidth:        layout.Px(sj.MinWidth),
-		MinHeight:       layout.Px(sj.MinHeight),
+		MinWidth:        jsonToOptionalLength(sj.MinWidth),
+		MinHeight:       jsonToOptionalLength(sj.MinHeight),
 		MaxWidth:        layout.Px(sj.MaxWidth),
 		MaxHeight:       layout.Px(sj.MaxHeight),
 		AspectRatio:     sj.AspectRatio,
 		FlexGrow:        sj.FlexGrow,
 		FlexShrink:      sj.FlexShrink,
-		FlexBasis:       layout.Px(sj.FlexBasis),
+		FlexBasis:       stringToFlexBasis(sj.FlexBasisKind, sj.FlexBasis),
 		FlexGap:         layout.Px(sj.FlexGap),
 		FlexRowGap:      layout.Px(sj.FlexRowGap),
 		FlexColumnGap:   layout.Px(sj.FlexColumnGap),
@@ -299,14 +432,60 @@ func jsonToStyle(sj *StyleJSON) layout.Style {
 		s.JustifyItems = stringToJustifyItems(sj.JustifyItems)
 	}
 	if sj.AlignContent != "" {
-		s.AlignContent = stringToAlignContent(sj.AlignContent)
+		var alignContent string
+		s.AlignContentOverflow, alignContent = splitOverflowAlignment(sj.AlignContent)
+		s.AlignContent = stringToAlignContent(alignContent)
 	}
 	if sj.BoxSizing != "" {
 		s.BoxSizing = stringToBoxSizing(sj.BoxSizing)
 	}
+	if sj.MarginCollapse == "full" {
+		s.MarginCollapse = layout.MarginCollapseFull
+	}
+	for _, size := range sj.Percent {
+		if l := sizeLength(&s, size); l != nil {
+			*l = layout.Percent(l.Value)
+		}
+	}
+	for size, expr := range sj.Calc {
+		if l := sizeLength(&s, size); l != nil {
+			*l = layout.Length{Value: l.Value, Unit: layout.LengthUnit(expr)}
+		}
+	}
+	s.ColumnCount = sj.ColumnCount
+	if sj.ColumnWidth != 0 {
+		s.ColumnWidth = layout.Px(sj.ColumnWidth)
+	}
+	s.ColumnGap = jsonToOptionalLength(sj.ColumnGap)
+	s.ColumnRuleWidth = layout.Px(sj.ColumnRuleWidth)
+	s.BreakBefore = stringToBreakBetween(sj.BreakBefore)
+	s.BreakAfter = stringToBreakBetween(sj.BreakAfter)
+	if sj.BreakInside == "avoid" {
+		s.BreakInside = layout.BreakInsideAvoid
+	}
 	if sj.Position != "" {
 		s.Position = stringToPosition(sj.Position)
 	}
+	switch sj.Visibility {
+	case "visible":
+		s.Visibility = layout.VisibilityVisible
+	case "hidden":
+		s.Visibility = layout.VisibilityHidden
+	}
+	switch sj.Overflow {
+	case "hidden":
+		s.Overflow = layout.OverflowHidden
+	case "scroll":
+		s.Overflow = layout.OverflowScroll
+	case "auto":
+		s.Overflow = layout.OverflowAuto
+	}
+	switch sj.ScrollbarGutter {
+	case "stable":
+		s.ScrollbarGutter = layout.ScrollbarGutterStable
+	case "stable both-edges":
+		s.ScrollbarGutter = layout.ScrollbarGutterStableBothEdges
+	}
 
 	// Convert grid tracks
 	if len(sj.GridTemplateRows) > 0 {
@@ -340,6 +519,10 @@ func displayToString(d layout.Display) string {
 		return "flex"
 	case layout.DisplayGrid:
 		return "grid"
+	case layout.DisplayInlineText:
+		return "inline-text"
+	case layout.DisplayNone:
+		return "none"
 	default:
 		return ""
 	}
@@ -353,6 +536,10 @@ func stringToDisplay(s string) layout.Display {
 		return layout.DisplayFlex
 	case "grid":
 		return layout.DisplayGrid
+	case "inline-text":
+		return layout.DisplayInlineText
+	case "none":
+		return layout.DisplayNone
 	default:
 		return 0
 	}
@@ -464,6 +651,8 @@ func alignItemsToString(ai layout.AlignItems) string {
 		return "center"
 	case layout.AlignItemsBaseline:
 		return "baseline"
+	case layout.AlignItemsStretchExplicit:
+		return "stretch-explicit"
 	default:
 		return "" // Zero value (stretch), will be omitted
 	}
@@ -483,6 +672,8 @@ func stringToAlignItems(s string) layout.AlignItems {
 		return layout.AlignItemsCenter
 	case "baseline":
 		return layout.AlignItemsBaseline
+	case "stretch-explicit":
+		return layout.AlignItemsStretchExplicit
 	default:
 		return layout.AlignItemsStretch // Zero value (default)
 	}
@@ -498,6 +689,8 @@ func justifyItemsToString(ji layout.JustifyItems) string {
 		return "end"
 	case layout.JustifyItemsCenter:
 		return "center"
+	case layout.JustifyItemsStretchExplicit:
+		return "stretch-explicit"
 	default:
 		return "" // Zero value (stretch), will be omitted
 	}
@@ -515,6 +708,8 @@ func stringToJustifyItems(s string) layout.JustifyItems {
 		return layout.JustifyItemsEnd
 	case "center":
 		return layout.JustifyItemsCenter
+	case "stretch-explicit":
+		return layout.JustifyItemsStretchExplicit
 	default:
 		return layout.JustifyItemsStretch // Zero value (default)
 	}
@@ -534,6 +729,8 @@ func alignContentToString(ac layout.AlignContent) string {
 		return "space-between"
 	case layout.AlignContentSpaceAround:
 		return "space-around"
+	case layout.AlignContentSpaceEvenly:
+		return "space-evenly"
 	default:
 		return ""
 	}
@@ -553,11 +750,105 @@ func stringToAlignContent(s string) layout.AlignContent {
 		return layout.AlignContentSpaceBetween
 	case "space-around":
 		return layout.AlignContentSpaceAround
+	case "space-evenly":
+		return layout.AlignContentSpaceEvenly
 	default:
 		return 0
 	}
 }
 
+// overflowAlignmentPrefix returns the CSS "safe " or "unsafe " prefix for an
+// alignment value.
+func overflowAlignmentPrefix(o layout.OverflowAlignment) string {
+	switch o {
+	case layout.OverflowAlignmentSafe:
+		return "safe "
+	case layout.OverflowAlignmentUnsafe:
+		return "unsafe "
+	default:
+		return ""
+	}
+}
+
+// splitOverflowAlignment splits a "safe "/"unsafe " prefix off an alignment
+// value.
+func splitOverflowAlignment(s string) (layout.OverflowAlignment, string) {
+	if rest, ok := strings.CutPrefix(s, "safe "); ok {
+		return layout.OverflowAlignmentSafe, rest
+	}
+	if rest, ok := strings.CutPrefix(s, "unsafe "); ok {
+		return layout.OverflowAlignmentUnsafe, rest
+	}
+	return layout.OverflowAlignmentDefault, s
+}
+
+// optionalLengthToJSON encodes a length whose zero Length has its own
+// meaning (auto min-width/min-height, normal column-gap). The zero Length is
+// omitted, so an explicit Px(0) survives a round trip.
+// sizeLength returns the size property of s with the given JSON name, or
+// nil for an unknown name.
+func sizeLength(s *layout.Style, name string) *layout.Length {
+	switch name {
+	case "width":
+		return &s.Width
+	case "height":
+		return &s.Height
+	case "minWidth":
+		return &s.MinWidth
+	case "minHeight":
+		return &s.MinHeight
+	case "maxWidth":
+		return &s.MaxWidth
+	case "maxHeight":
+		return &s.MaxHeight
+	}
+	return nil
+}
+
+func optionalLengthToJSON(l layout.Length) *float64 {
+	if l.Unit == "" {
+		return nil
+	}
+	v := l.Value
+	return &v
+}
+
+func jsonToOptionalLength(v *float64) layout.Length {
+	if v == nil {
+		return layout.Length{}
+	}
+	return layout.Px(*v)
+}
+
+func flexBasisKindToString(b layout.FlexBasis) string {
+	switch b.Kind {
+	case layout.FlexBasisContent:
+		return "content"
+	case layout.FlexBasisLength:
+		if b.Length.Unit == layout.PercentUnit {
+			return "percent"
+		}
+		return "length"
+	default:
+		return ""
+	}
+}
+
+// stringToFlexBasis decodes a flex basis. A bare non-zero flexBasis without a
+// kind is a length, matching documents written before FlexBasisKind existed.
+func stringToFlexBasis(kind string, value float64) layout.FlexBasis {
+	switch {
+	case kind == "content":
+		return layout.BasisContent()
+	case kind == "percent":
+		return layout.Basis(layout.Percent(value))
+	case kind == "length" || (kind == "" && value != 0):
+		return layout.Basis(layout.Px(value))
+	default:
+		return layout.BasisAuto()
+	}
+}
+
 func boxSizingToString(bs layout.BoxSizing) string {
 	switch bs {
 	case layout.BoxSizingContentBox:
@@ -580,6 +871,28 @@ func stringToBoxSizing(s string) layout.BoxSizing {
 	}
 }
 
+func breakBetweenToString(b layout.BreakBetween) string {
+	switch b {
+	case layout.BreakAvoid:
+		return "avoid"
+	case layout.BreakPage:
+		return "page"
+	default:
+		return ""
+	}
+}
+
+func stringToBreakBetween(s string) layout.BreakBetween {
+	switch s {
+	case "avoid":
+		return layout.BreakAvoid
+	case "page":
+		return layout.BreakPage
+	default:
+		return layout.BreakAuto
+	}
+}
+
 func positionToString(p layout.Position) string {
 	switch p {
 	case layout.PositionStatic:
@@ -631,21 +944,43 @@ func jsonToTrack(tj *TrackJSON) layout.GridTrack {
 }
 
 func spacingToJSON(s *layout.Spacing) SpacingJSON {
-	return SpacingJSON{
+	sj := SpacingJSON{
 		Top:    s.Top.Value,
 		Right:  s.Right.Value,
 		Bottom: s.Bottom.Value,
 		Left:   s.Left.Value,
 	}
+	for _, side := range []struct {
+		name string
+		l    layout.Length
+	}{{"top", s.Top}, {"right", s.Right}, {"bottom", s.Bottom}, {"left", s.Left}} {
+		if side.l.Unit == layout.AutoUnit {
+			sj.Auto = append(sj.Auto, side.name)
+		}
+	}
+	return sj
 }
 
 func jsonToSpacing(sj *SpacingJSON) layout.Spacing {
-	return layout.Spacing{
+	s := layout.Spacing{
 		Top:    layout.Px(sj.Top),
 		Right:  layout.Px(sj.Right),
 		Bottom: layout.Px(sj.Bottom),
 		Left:   layout.Px(sj.Left),
 	}
+	for _, side := range sj.Auto {
+		switch side {
+		case "top":
+			s.Top = layout.Auto()
+		case "right":
+			s.Right = layout.Auto()
+		case "bottom":
+			s.Bottom = layout.Auto()
+		case "left":
+			s.Left = layout.Auto()
+		}
+	}
+	return s
 }
 
 func rectToJSON(r *layout.Rect) RectJSON {
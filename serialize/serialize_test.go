@@ -2,6 +2,7 @@ package serialize
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/SCKelemen/layout"
@@ -158,3 +159,288 @@ func TestTransformSerialization(t *testing.T) {
 		t.Errorf("Transform.A mismatch: got %v, want %v", deserialized.Style.Transform.A, root.Style.Transform.A)
 	}
 }
+
+func TestFlexBasisSerialization(t *testing.T) {
+	bases := []layout.FlexBasis{layout.BasisAuto(), layout.BasisContent(), layout.Basis(layout.Px(0)), layout.Basis(layout.Px(120)), layout.Basis(layout.Percent(50))}
+
+	for _, basis := range bases {
+		root := &layout.Node{Style: layout.Style{FlexBasis: basis}}
+		jsonBytes, err := ToJSON(root)
+		if err != nil {
+			t.Fatalf("ToJSON failed: %v", err)
+		}
+		deserialized, err := FromJSON(jsonBytes)
+		if err != nil {
+			t.Fatalf("FromJSON failed: %v", err)
+		}
+		if deserialized.Style.FlexBasis != basis {
+			t.Errorf("FlexBasis mismatch: got %+v, want %+v", deserialized.Style.FlexBasis, basis)
+		}
+	}
+
+	// Documents written before flexBasisKind existed
+	legacy, err := FromJSON([]byte(`{"style":{"flexBasis":80}}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if legacy.Style.FlexBasis != layout.Basis(layout.Px(80)) {
+		t.Errorf("Expected bare flexBasis to decode as a length, got %+v", legacy.Style.FlexBasis)
+	}
+}
+
+func TestMinSizeSerialization(t *testing.T) {
+	for _, minWidth := range []layout.Length{{}, layout.Px(0), layout.Px(40)} {
+		root := &layout.Node{Style: layout.Style{MinWidth: minWidth}}
+		jsonBytes, err := ToJSON(root)
+		if err != nil {
+			t.Fatalf("ToJSON failed: %v", err)
+		}
+		deserialized, err := FromJSON(jsonBytes)
+		if err != nil {
+			t.Fatalf("FromJSON failed: %v", err)
+		}
+		if deserialized.Style.MinWidth != minWidth {
+			t.Errorf("MinWidth mismatch: got %+v, want %+v", deserialized.Style.MinWidth, minWidth)
+		}
+	}
+}
+
+func TestAlignContentSerialization(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{
+		AlignContent:         layout.AlignContentSpaceEvenly,
+		AlignContentOverflow: layout.OverflowAlignmentUnsafe,
+	}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if !strings.Contains(string(jsonBytes), `"unsafe space-evenly"`) {
+		t.Errorf("Expected CSS-style align-content value, got %s", jsonBytes)
+	}
+
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if deserialized.Style.AlignContent != layout.AlignContentSpaceEvenly || deserialized.Style.AlignContentOverflow != layout.OverflowAlignmentUnsafe {
+		t.Errorf("AlignContent mismatch: got %v/%v", deserialized.Style.AlignContent, deserialized.Style.AlignContentOverflow)
+	}
+}
+
+func TestAutoMarginSerialization(t *testing.T) {
+	margin := layout.Spacing{Top: layout.Px(4), Right: layout.Px(8), Bottom: layout.Px(0), Left: layout.Auto()}
+	root := &layout.Node{Style: layout.Style{Margin: margin}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if deserialized.Style.Margin != margin {
+		t.Errorf("Margin mismatch: got %+v, want %+v", deserialized.Style.Margin, margin)
+	}
+}
+
+func TestMarginCollapseSerialization(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{MarginCollapse: layout.MarginCollapseFull}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if deserialized.Style.MarginCollapse != layout.MarginCollapseFull {
+		t.Errorf("MarginCollapse mismatch: got %v", deserialized.Style.MarginCollapse)
+	}
+}
+
+func TestMultiColumnSerialization(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{
+		ColumnCount:     3,
+		ColumnWidth:     layout.Px(120),
+		ColumnRuleWidth: layout.Px(1),
+	}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	s := deserialized.Style
+	if s.ColumnCount != 3 || s.ColumnWidth != layout.Px(120) || s.ColumnRuleWidth != layout.Px(1) {
+		t.Errorf("Multi-column mismatch: got count %d, width %v, rule %v", s.ColumnCount, s.ColumnWidth, s.ColumnRuleWidth)
+	}
+	if s.ColumnGap.Unit != "" {
+		t.Errorf("Expected normal column gap to survive a round trip, got %v", s.ColumnGap)
+	}
+
+	root.Style.ColumnGap = layout.Px(0)
+	jsonBytes, _ = ToJSON(root)
+	deserialized, _ = FromJSON(jsonBytes)
+	if deserialized.Style.ColumnGap != layout.Px(0) {
+		t.Errorf("Expected explicit zero column gap, got %v", deserialized.Style.ColumnGap)
+	}
+}
+
+func TestBreakSerialization(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{
+		BreakBefore: layout.BreakPage,
+		BreakAfter:  layout.BreakAvoid,
+		BreakInside: layout.BreakInsideAvoid,
+	}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	s := deserialized.Style
+	if s.BreakBefore != layout.BreakPage || s.BreakAfter != layout.BreakAvoid || s.BreakInside != layout.BreakInsideAvoid {
+		t.Errorf("Break mismatch: got before %v, after %v, inside %v", s.BreakBefore, s.BreakAfter, s.BreakInside)
+	}
+}
+
+func TestVisibilitySerialization(t *testing.T) {
+	root := &layout.Node{
+		Style:    layout.Style{Visibility: layout.VisibilityHidden},
+		Children: []*layout.Node{{Style: layout.Style{Visibility: layout.VisibilityVisible}}, {}},
+	}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	got := []layout.Visibility{deserialized.Style.Visibility, deserialized.Children[0].Style.Visibility, deserialized.Children[1].Style.Visibility}
+	want := []layout.Visibility{layout.VisibilityHidden, layout.VisibilityVisible, layout.VisibilityInherit}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected visibility %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestPercentSizeSerialization(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{
+		Width:     layout.Percent(50),
+		Height:    layout.Px(20),
+		MinHeight: layout.Percent(10),
+	}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	s := deserialized.Style
+	if s.Width != layout.Percent(50) || s.Height != layout.Px(20) || s.MinHeight != layout.Percent(10) {
+		t.Errorf("Percent mismatch: got width %v, height %v, min height %v", s.Width, s.Height, s.MinHeight)
+	}
+}
+
+func TestCalcSerialization(t *testing.T) {
+	width := layout.Calc(layout.Percent(100), layout.CalcSub, layout.Px(32))
+	root := &layout.Node{Style: layout.Style{Width: width, Height: layout.Px(20)}}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	s := deserialized.Style
+	if s.Width != width || s.Height != layout.Px(20) {
+		t.Errorf("Calc mismatch: got width %v, height %v", s.Width, s.Height)
+	}
+}
+
+func TestOverflowSerialization(t *testing.T) {
+	root := &layout.Node{
+		Style:    layout.Style{Overflow: layout.OverflowAuto, ScrollbarGutter: layout.ScrollbarGutterStableBothEdges},
+		Children: []*layout.Node{{Style: layout.Style{Overflow: layout.OverflowHidden}}, {}},
+	}
+
+	jsonBytes, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	deserialized, err := FromJSON(jsonBytes)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	got := []layout.Overflow{deserialized.Style.Overflow, deserialized.Children[0].Style.Overflow, deserialized.Children[1].Style.Overflow}
+	want := []layout.Overflow{layout.OverflowAuto, layout.OverflowHidden, layout.OverflowVisible}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected overflow %v, got %v", want, got)
+			break
+		}
+	}
+	if deserialized.Style.ScrollbarGutter != layout.ScrollbarGutterStableBothEdges {
+		t.Errorf("Expected scrollbar gutter %v, got %v", layout.ScrollbarGutterStableBothEdges, deserialized.Style.ScrollbarGutter)
+	}
+}
+
+func TestIdentitySerialization(t *testing.T) {
+	root := &layout.Node{
+		Tag:     "section",
+		ID:      "main",
+		Classes: []string{"card", "wide"},
+		Children: []*layout.Node{
+			{Tag: "button", Attributes: map[string]string{"type": "submit"}},
+			{},
+		},
+	}
+
+	for _, format := range []struct {
+		name string
+		to   func(*layout.Node) ([]byte, error)
+		from func([]byte) (*layout.Node, error)
+	}{{"JSON", ToJSON, FromJSON}, {"YAML", ToYAML, FromYAML}} {
+		data, err := format.to(root)
+		if err != nil {
+			t.Fatalf("To%s failed: %v", format.name, err)
+		}
+		deserialized, err := format.from(data)
+		if err != nil {
+			t.Fatalf("From%s failed: %v", format.name, err)
+		}
+		if deserialized.Tag != "section" || deserialized.ID != "main" || strings.Join(deserialized.Classes, " ") != "card wide" {
+			t.Errorf("%s: expected section#main.card.wide, got %s#%s.%v", format.name, deserialized.Tag, deserialized.ID, deserialized.Classes)
+		}
+		button := deserialized.Children[0]
+		if button.Tag != "button" || button.Attributes["type"] != "submit" {
+			t.Errorf("%s: expected button[type=submit], got %s%v", format.name, button.Tag, button.Attributes)
+		}
+		if plain := deserialized.Children[1]; plain.Tag != "" || plain.ID != "" || len(plain.Classes) != 0 {
+			t.Errorf("%s: expected no identity, got %+v", format.name, plain)
+		}
+	}
+
+	jsonBytes, _ := ToJSON(&layout.Node{})
+	if strings.Contains(string(jsonBytes), `"id"`) {
+		t.Errorf("Expected empty identity fields to be omitted, got %s", jsonBytes)
+	}
+}
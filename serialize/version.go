@@ -0,0 +1,230 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// FormatVersion is the version of the serialization format that ToJSON,
+// ToYAML and ToBinary write, in the root's "version" property. Data
+// without a version is version 1, so fixtures written by hand needn't
+// give one.
+//
+// Properties added to the format don't change its version: data written
+// before they existed simply doesn't have them. The version changes when
+// what data means changes, for example when a property is renamed or its
+// default changes, and FromJSON migrates older data to the current
+// version (see migrations).
+const FormatVersion = 1
+
+// migrations[v] migrates a node object of format version v, as decoded by
+// encoding/json, to version v+1 in place. FromJSON applies them to every
+// node of a tree, from its version up to FormatVersion.
+//
+// To change what data means, bump FormatVersion and add the migration
+// from the previous version, e.g. for a renamed property:
+//
+//	migrations[1] = func(node map[string]any) {
+//		if style, ok := node["style"].(map[string]any); ok && style["gridGap"] != nil {
+//			style["gap"] = style["gridGap"]
+//			delete(style, "gridGap")
+//		}
+//	}
+var migrations = map[int]func(node map[string]any){}
+
+// UnknownFieldsError is the error FromJSON returns for data with
+// properties that aren't in the format, which are usually typos, or data
+// from a newer version of the package. FromJSONWithOptions can skip them
+// instead.
+type UnknownFieldsError struct {
+	// Fields are the paths of the unknown properties, like
+	// "children[2].style.hologram", sorted.
+	Fields []string
+}
+
+// Error implements the error interface.
+func (e *UnknownFieldsError) Error() string {
+	return "serialize: unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// DecodeOptions controls FromJSONWithOptions.
+type DecodeOptions struct {
+	// AllowUnknownFields skips the properties that aren't in the format,
+	// rather than failing with an *UnknownFieldsError.
+	AllowUnknownFields bool
+
+	// OnUnknownField, if non-nil, is called with the path of each
+	// property skipped with AllowUnknownFields.
+	OnUnknownField func(path string)
+
+	// RoundTrip, if non-nil, checks that laying out the loaded tree
+	// reproduces its serialized Rects, failing with a *RoundTripError if
+	// not (see VerifyRoundTrip). The tree is returned laid out, with the
+	// error if any, so it can be used as is when the check fails.
+	RoundTrip *RoundTripOptions
+}
+
+// FromJSONWithOptions converts JSON bytes to a layout.Node like FromJSON,
+// with options for unknown properties and round-trip checking.
+//
+// Example, to load data from a newer version of the package:
+//
+//	root, err := serialize.FromJSONWithOptions(data, serialize.DecodeOptions{
+//		AllowUnknownFields: true,
+//		OnUnknownField: func(path string) { log.Printf("skipped %s", path) },
+//	})
+func FromJSONWithOptions(data []byte, opts DecodeOptions) (*layout.Node, error) {
+	root, err := decodeJSON(data, opts)
+	if err != nil || opts.RoundTrip == nil {
+		return root, err
+	}
+	return root, VerifyRoundTrip(root, *opts.RoundTrip)
+}
+
+// decodeJSON converts JSON bytes to a layout.Node, with opts for unknown
+// properties.
+func decodeJSON(data []byte, opts DecodeOptions) (*layout.Node, error) {
+	// Data of the current version with known properties only, the usual
+	// case, is decoded directly
+	var nodeJSON NodeJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&nodeJSON); err == nil && !dec.More() && (nodeJSON.Version == 0 || nodeJSON.Version == FormatVersion) {
+		return jsonToNode(&nodeJSON), nil
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	root, ok := raw.(map[string]any)
+	if !ok {
+		// Let encoding/json report the type error
+		return nil, json.Unmarshal(data, &nodeJSON)
+	}
+	version := 1
+	if v, ok := root["version"].(float64); ok {
+		version = int(v)
+	}
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+	migrate(root, version, FormatVersion)
+
+	var unknown []string
+	unknownFields(root, reflect.TypeOf(NodeJSON{}), "", &unknown)
+	sort.Strings(unknown)
+	if len(unknown) > 0 {
+		if !opts.AllowUnknownFields {
+			return nil, &UnknownFieldsError{Fields: unknown}
+		}
+		if opts.OnUnknownField != nil {
+			for _, path := range unknown {
+				opts.OnUnknownField(path)
+			}
+		}
+	}
+
+	root["version"] = FormatVersion
+	migrated, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	nodeJSON = NodeJSON{}
+	if err := json.Unmarshal(migrated, &nodeJSON); err != nil {
+		return nil, err
+	}
+	return jsonToNode(&nodeJSON), nil
+}
+
+// migrate migrates the nodes of root's tree from format version from to
+// version to.
+func migrate(root map[string]any, from, to int) {
+	for v := from; v < to; v++ {
+		if m := migrations[v]; m != nil {
+			walkNodeObjects(root, m)
+		}
+	}
+}
+
+// walkNodeObjects calls f on node and its descendants' objects.
+func walkNodeObjects(node map[string]any, f func(map[string]any)) {
+	f(node)
+	children, _ := node["children"].([]any)
+	for _, child := range children {
+		if c, ok := child.(map[string]any); ok {
+			walkNodeObjects(c, f)
+		}
+	}
+}
+
+// checkVersion returns an error for a format version this package can't
+// read.
+func checkVersion(version int) error {
+	if version > FormatVersion {
+		return fmt.Errorf("serialize: format version %d is newer than this package's %d", version, FormatVersion)
+	}
+	if version < 1 {
+		return fmt.Errorf("serialize: invalid format version %d", version)
+	}
+	return nil
+}
+
+// unknownFields appends the paths of the properties of x, decoded by
+// encoding/json, that type t doesn't have to unknown. Like encoding/json,
+// it matches names case-insensitively. Values of the wrong type are left
+// for encoding/json to report.
+func unknownFields(x any, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := x.(map[string]any)
+		if !ok {
+			return
+		}
+		s := structFields(t)
+		for name, v := range obj {
+			field := fieldByJSONName(s, name)
+			prop := name
+			if path != "" {
+				prop = path + "." + name
+			}
+			if field == nil {
+				*unknown = append(*unknown, prop)
+				continue
+			}
+			unknownFields(v, t.Field(field.index).Type, prop, unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := x.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			unknownFields(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", unknown)
+		}
+	}
+}
+
+// fieldByJSONName returns the field of s that encoding/json decodes
+// property name into, or nil.
+func fieldByJSONName(s *binaryStruct, name string) *binaryField {
+	if i, ok := s.byName[name]; ok {
+		return &s.fields[i]
+	}
+	for i := range s.fields {
+		if strings.EqualFold(s.fields[i].name, name) {
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
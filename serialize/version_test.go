@@ -0,0 +1,117 @@
+package serialize
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestFormatVersionWritten(t *testing.T) {
+	root := layout.VStack(layout.Fixed(10, 10))
+	data, err := ToJSON(root)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if doc["version"] != float64(FormatVersion) {
+		t.Errorf("Expected version %d on the root, got %v", FormatVersion, doc["version"])
+	}
+	child := doc["children"].([]any)[0].(map[string]any)
+	if _, ok := child["version"]; ok {
+		t.Errorf("Expected no version on children, got %v", child["version"])
+	}
+
+	bin, err := ToBinary(root)
+	if err != nil {
+		t.Fatalf("ToBinary failed: %v", err)
+	}
+	if _, err := FromBinary(bin); err != nil {
+		t.Errorf("Expected binary to read back, got %v", err)
+	}
+}
+
+func TestFromJSONUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"style": {"display": "flex", "hologram": true},
+		"children": [{}, {}, {"style": {"width": 10}, "sparkle": 1}],
+		"extra": "x"
+	}`)
+
+	_, err := FromJSON(data)
+	var unknownErr *UnknownFieldsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Expected *UnknownFieldsError, got %v", err)
+	}
+	want := []string{"children[2].sparkle", "extra", "style.hologram"}
+	if !reflect.DeepEqual(unknownErr.Fields, want) {
+		t.Errorf("Expected fields %v, got %v", want, unknownErr.Fields)
+	}
+	if !strings.Contains(err.Error(), "children[2].sparkle") {
+		t.Errorf("Expected error to name the fields, got %q", err.Error())
+	}
+
+	var skipped []string
+	root, err := FromJSONWithOptions(data, DecodeOptions{
+		AllowUnknownFields: true,
+		OnUnknownField:     func(path string) { skipped = append(skipped, path) },
+	})
+	if err != nil {
+		t.Fatalf("Expected unknown fields to be skipped, got %v", err)
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Expected OnUnknownField with %v, got %v", want, skipped)
+	}
+	if len(root.Children) != 3 || root.Children[2].Style.Width.Value != 10 {
+		t.Errorf("Expected known fields to load, got %+v", root)
+	}
+}
+
+func TestFromJSONVersion(t *testing.T) {
+	if _, err := FromJSON([]byte(`{"version": 99}`)); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected an error for a newer version, got %v", err)
+	}
+	if _, err := FromJSON([]byte(`{"version": -1}`)); err == nil {
+		t.Error("Expected an error for an invalid version")
+	}
+	if _, err := FromJSON([]byte(`{"children": [{}]}`)); err != nil {
+		t.Errorf("Expected data without a version to load, got %v", err)
+	}
+}
+
+func TestFromJSONLegacyKeys(t *testing.T) {
+	// Trees saved with Go field names load, as encoding/json matches
+	// names case-insensitively
+	root, err := FromJSON([]byte(`{"Style": {"Display": "flex"}, "Children": [{"Tag": "p"}]}`))
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+	if root.Style.Display != layout.DisplayFlex || len(root.Children) != 1 || root.Children[0].Tag != "p" {
+		t.Errorf("Expected legacy keys to load, got %+v", root)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	migrations[1] = func(node map[string]any) {
+		if style, ok := node["style"].(map[string]any); ok && style["gridGap"] != nil {
+			style["gridRowGap"] = style["gridGap"]
+			delete(style, "gridGap")
+		}
+	}
+	defer delete(migrations, 1)
+
+	var root map[string]any
+	json.Unmarshal([]byte(`{"children": [{"style": {"gridGap": 4}}]}`), &root)
+	migrate(root, 1, 2)
+
+	style := root["children"].([]any)[0].(map[string]any)["style"].(map[string]any)
+	if style["gridRowGap"] != float64(4) || style["gridGap"] != nil {
+		t.Errorf("Expected gridGap renamed to gridRowGap, got %v", style)
+	}
+}
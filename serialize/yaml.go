@@ -4,28 +4,60 @@
 package serialize
 
 import (
+	"encoding/json"
+
 	"gopkg.in/yaml.v3"
 
 	"github.com/SCKelemen/layout"
 )
 
-// ToYAML converts a layout.Node to YAML bytes
+// ToYAML converts a layout.Node to YAML bytes, with the same schema as
+// ToJSON: the same property names, and zero values left out.
 // Requires: go get gopkg.in/yaml.v3
 // To disable YAML support, build with: go build -tags no_yaml
 func ToYAML(node *layout.Node) ([]byte, error) {
-	// First convert to JSON structure
-	nodeJSON := nodeToJSON(node)
-	// Then convert to YAML
-	return yaml.Marshal(nodeJSON)
+	return ToYAMLWithOptions(node, EncodeOptions{})
+}
+
+// ToYAMLWithOptions converts a layout.Node to YAML bytes like ToYAML, with
+// options for the computed layout written.
+func ToYAMLWithOptions(node *layout.Node, opts EncodeOptions) ([]byte, error) {
+	// YAML is a superset of JSON, so the JSON form parses as a YAML
+	// document, whose keys keep their order
+	data, err := json.Marshal(documentToJSON(node, opts))
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	return yaml.Marshal(&doc)
 }
 
-// FromYAML converts YAML bytes to a layout.Node
+// FromYAML converts YAML bytes to a layout.Node. The YAML has the schema
+// of FromJSON, and is migrated and checked for unknown properties like
+// JSON; property names are matched case-insensitively.
 // Requires: go get gopkg.in/yaml.v3
 // To disable YAML support, build with: go build -tags no_yaml
 func FromYAML(data []byte) (*layout.Node, error) {
-	var nodeJSON NodeJSON
-	if err := yaml.Unmarshal(data, &nodeJSON); err != nil {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
 		return nil, err
 	}
-	return jsonToNode(&nodeJSON), nil
+	return FromJSON(data)
+}
+
+// blockStyle clears the JSON flow style and quotes of n's tree, so it's
+// written in block style, quoting only the strings that need it.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
 }
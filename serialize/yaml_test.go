@@ -0,0 +1,79 @@
+//go:build !no_yaml
+// +build !no_yaml
+
+package serialize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func TestYAMLUsesJSONSchema(t *testing.T) {
+	root := layout.VStack(layout.Fixed(100, 50))
+	root.ID = "1e3"
+	root.Style.Padding = layout.Uniform(layout.Px(8))
+
+	data, err := ToYAML(root)
+	if err != nil {
+		t.Fatalf("ToYAML failed: %v", err)
+	}
+	yml := string(data)
+	for _, want := range []string{"id: \"1e3\"\n", "    flexDirection: column\n", "    padding:\n        top: 8\n", "\n        width: 100\n"} {
+		if !strings.Contains(yml, want) {
+			t.Errorf("Expected %q in:\n%s", want, yml)
+		}
+	}
+	for _, unwanted := range []string{"flexdirection", "flexWrap", "tag:", "{\""} {
+		if strings.Contains(yml, unwanted) {
+			t.Errorf("Expected no %q in:\n%s", unwanted, yml)
+		}
+	}
+
+	deserialized, err := FromYAML(data)
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+	if deserialized.ID != "1e3" || deserialized.Style.FlexDirection != layout.FlexDirectionColumn || deserialized.Children[0].Style.Width != layout.Px(100) {
+		t.Errorf("Expected the tree back, got %+v", deserialized)
+	}
+}
+
+func TestFromYAMLFixture(t *testing.T) {
+	fixture := `
+id: toolbar
+style:
+  display: flex
+  justifyContent: space-between
+  percent: [width]
+  width: 100
+  padding: {top: 4, left: 8}
+children:
+  - style: {width: 32, height: 32}
+  - style:
+      flexGrow: 1
+      # lowercase names, as ToYAML used to write them, still read
+      minheight: 20
+`
+	root, err := FromYAML([]byte(fixture))
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+	if root.ID != "toolbar" || root.Style.Display != layout.DisplayFlex || root.Style.JustifyContent != layout.JustifyContentSpaceBetween {
+		t.Errorf("Expected a space-between flex toolbar, got %+v", root.Style)
+	}
+	if root.Style.Width != layout.Percent(100) || root.Style.Padding.Top != layout.Px(4) || root.Style.Padding.Left != layout.Px(8) {
+		t.Errorf("Expected a 100%% width and padding, got %+v and %+v", root.Style.Width, root.Style.Padding)
+	}
+	if len(root.Children) != 2 || root.Children[0].Style.Height != layout.Px(32) {
+		t.Fatalf("Expected 2 children, got %+v", root.Children)
+	}
+	if grow := root.Children[1]; grow.Style.FlexGrow != 1 || grow.Style.MinHeight != layout.Px(20) {
+		t.Errorf("Expected flexGrow and minHeight, got %+v", grow.Style)
+	}
+
+	if _, err := FromYAML([]byte("style: [")); err == nil {
+		t.Errorf("Expected an error for malformed YAML")
+	}
+}
@@ -0,0 +1,223 @@
+// Package shaper provides a layout.TextMetricsProvider that measures text
+// by shaping it with HarfBuzz (github.com/go-text/typesetting), using real
+// TTF/OTF fonts.
+//
+// Shaping applies the font's kerning, ligatures and the contextual forms of
+// complex scripts such as Arabic and Devanagari, so text laid out with this
+// provider has the widths it will have when rendered to SVG or PDF with the
+// same fonts.
+//
+// Example:
+//
+//	p := shaper.New()
+//	if err := p.LoadFont("Inter", "fonts/Inter-Regular.ttf"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	p.LoadFont("Noto Sans CJK", "fonts/NotoSansCJK-Regular.otf")
+//	layout.SetTextMetricsProvider(p)
+//
+//	node := layout.Text("Hello 世界", layout.Style{
+//	    TextStyle: &layout.TextStyle{FontFamily: "Inter, Noto Sans CJK", FontSize: 16},
+//	})
+package shaper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/SCKelemen/layout"
+	"github.com/go-text/typesetting/di"
+	"github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/shaping"
+	"golang.org/x/image/math/fixed"
+)
+
+// defaultFontSize is the font size used for a TextStyle without one, in
+// pixels.
+const defaultFontSize = 16
+
+// Provider measures text by shaping it with the fonts registered for each
+// family. It implements layout.TextMetricsProvider,
+// layout.FontCoverageProvider, layout.XHeightProvider and
+// layout.DecorationMetricsProvider, and is safe for concurrent use.
+//
+// Font sizes are in pixels. A family that isn't registered is measured with
+// the first registered font.
+type Provider struct {
+	mu        sync.Mutex
+	shaper    shaping.HarfbuzzShaper
+	segmenter shaping.Segmenter
+	faces     map[string]*font.Face
+	fallback  *font.Face
+}
+
+// New returns a Provider without fonts. Register fonts with AddFace,
+// AddFont or LoadFont before measuring.
+func New() *Provider {
+	return &Provider{faces: make(map[string]*font.Face)}
+}
+
+// AddFace registers face for family. Family names are matched case
+// insensitively. The first face added is the fallback for unknown families.
+func (p *Provider) AddFace(family string, face *font.Face) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faces[strings.ToLower(family)] = face
+	if p.fallback == nil {
+		p.fallback = face
+	}
+}
+
+// AddFont parses a TTF or OTF font from r and registers it for family.
+func (p *Provider) AddFont(family string, r font.Resource) error {
+	face, err := font.ParseTTF(r)
+	if err != nil {
+		return fmt.Errorf("shaper: parsing font for %q: %v", family, err)
+	}
+	p.AddFace(family, face)
+	return nil
+}
+
+// LoadFont reads the TTF or OTF file at path and registers it for family.
+func (p *Provider) LoadFont(family, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("shaper: %v", err)
+	}
+	defer f.Close()
+	return p.AddFont(family, f)
+}
+
+// Measure shapes text in the first registered family of style's font
+// stack and returns its advance width, and the font's ascent and descent,
+// at style's font size.
+func (p *Provider) Measure(text string, style layout.TextStyle) (advance, ascent, descent float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	face := p.face(style)
+	if face == nil {
+		return 0, 0, 0
+	}
+	size := fontSize(style)
+	ascent, descent = extents(face, size)
+	if text == "" {
+		return 0, ascent, descent
+	}
+
+	direction := di.DirectionLTR
+	if style.Direction == layout.DirectionRTL {
+		direction = di.DirectionRTL
+	}
+	runes := []rune(text)
+	input := shaping.Input{
+		Text:      runes,
+		RunStart:  0,
+		RunEnd:    len(runes),
+		Direction: direction,
+		Face:      face,
+		Size:      fixed.Int26_6(size * 64),
+	}
+	// Split by direction and script so each run is shaped with the right
+	// rules, keeping the face the font stack resolved
+	for _, run := range p.segmenter.Split(input, singleFace{face}) {
+		out := p.shaper.Shape(run)
+		advance += fixedToFloat(out.Advance)
+	}
+	if style.LetterSpacing != -1 {
+		advance += float64(utf8.RuneCountInString(text)-1) * style.LetterSpacing
+	}
+	return advance, ascent, descent
+}
+
+// HasGlyph reports whether the font registered for family has a glyph for
+// r. It's false for families that aren't registered, so layout falls back
+// to the next family of a font stack.
+func (p *Provider) HasGlyph(family string, r rune) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	face := p.faces[strings.ToLower(family)]
+	if face == nil {
+		return false
+	}
+	_, ok := face.NominalGlyph(r)
+	return ok
+}
+
+// XHeight returns the x-height of the font for style, used to resolve ex
+// units. Fonts without an x-height get the CSS fallback of 0.5em.
+func (p *Provider) XHeight(style layout.TextStyle) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	face := p.face(style)
+	size := fontSize(style)
+	if face == nil {
+		return size / 2
+	}
+	x := face.LineMetric(font.XHeight)
+	if x <= 0 {
+		return size / 2
+	}
+	return float64(x) * size / float64(face.Upem())
+}
+
+// DecorationMetrics returns the font's underline and strikethrough
+// positions and thicknesses for style, in pixels.
+func (p *Provider) DecorationMetrics(style layout.TextStyle) (underlinePosition, underlineThickness, strikethroughPosition, strikethroughThickness float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	size := fontSize(style)
+	face := p.face(style)
+	if face == nil {
+		return -size / 10, size / 16, size / 4, size / 16
+	}
+	scale := size / float64(face.Upem())
+	metric := func(m font.LineMetric) float64 {
+		return float64(face.LineMetric(m)) * scale
+	}
+	return metric(font.UnderlinePosition), metric(font.UnderlineThickness),
+		metric(font.StrikethroughPosition), metric(font.StrikethroughThickness)
+}
+
+// face returns the face for the first registered family of style's font
+// stack, or the fallback face.
+func (p *Provider) face(style layout.TextStyle) *font.Face {
+	for _, family := range style.FontStack() {
+		if face := p.faces[strings.ToLower(family)]; face != nil {
+			return face
+		}
+	}
+	return p.fallback
+}
+
+// extents returns the ascent and descent of face at size, in pixels.
+func extents(face *font.Face, size float64) (ascent, descent float64) {
+	ext, ok := face.FontHExtents()
+	if !ok {
+		return size * 0.8, size * 0.2
+	}
+	scale := size / float64(face.Upem())
+	return float64(ext.Ascender) * scale, -float64(ext.Descender) * scale
+}
+
+func fontSize(style layout.TextStyle) float64 {
+	if style.FontSize > 0 {
+		return style.FontSize
+	}
+	return defaultFontSize
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// singleFace is a shaping.Fontmap that always resolves to one face.
+type singleFace struct {
+	face *font.Face
+}
+
+func (s singleFace) ResolveFace(rune) *font.Face {
+	return s.face
+}
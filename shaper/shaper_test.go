@@ -0,0 +1,118 @@
+package shaper
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	p := New()
+	if err := p.AddFont("Go", bytes.NewReader(goregular.TTF)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddFont("Go Mono", bytes.NewReader(gomono.TTF)); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMeasure(t *testing.T) {
+	p := newTestProvider(t)
+	style := layout.TextStyle{FontFamily: "Go", FontSize: 16, LetterSpacing: -1}
+
+	w, ascent, descent := p.Measure("Hello", style)
+	if w <= 0 || ascent <= 0 || descent <= 0 {
+		t.Fatalf("Expected positive metrics, got width %v ascent %v descent %v", w, ascent, descent)
+	}
+	if ascent+descent < 16 || ascent+descent > 24 {
+		t.Errorf("Expected ascent+descent near the 16px font size, got %v", ascent+descent)
+	}
+
+	// Metrics scale with the font size
+	style.FontSize = 32
+	if w2, _, _ := p.Measure("Hello", style); math.Abs(w2-2*w) > 0.1 {
+		t.Errorf("Expected width %v at twice the size, got %v", 2*w, w2)
+	}
+
+	// Go Mono is monospaced; Go Regular isn't
+	mono := layout.TextStyle{FontFamily: "Go Mono", FontSize: 16, LetterSpacing: -1}
+	wi, _, _ := p.Measure("iiii", mono)
+	wm, _, _ := p.Measure("mmmm", mono)
+	if math.Abs(wi-wm) > 0.01 {
+		t.Errorf("Expected equal monospaced widths, got %v and %v", wi, wm)
+	}
+	style.FontSize = 16
+	wi, _, _ = p.Measure("iiii", style)
+	wm, _, _ = p.Measure("mmmm", style)
+	if wi >= wm {
+		t.Errorf("Expected iiii narrower than mmmm in a proportional font, got %v and %v", wi, wm)
+	}
+
+	// Letter spacing is added between characters
+	spaced := style
+	spaced.LetterSpacing = 2
+	if got, _, _ := p.Measure("mmmm", spaced); math.Abs(got-(wm+6)) > 0.01 {
+		t.Errorf("Expected width %v with letter spacing, got %v", wm+6, got)
+	}
+}
+
+func TestFontStackAndCoverage(t *testing.T) {
+	p := newTestProvider(t)
+
+	// The first registered family of the stack is used
+	mono, _, _ := p.Measure("iiii", layout.TextStyle{FontFamily: "Go Mono", FontSize: 16, LetterSpacing: -1})
+	if got, _, _ := p.Measure("iiii", layout.TextStyle{FontFamily: `"Unknown", go mono, Go`, FontSize: 16, LetterSpacing: -1}); got != mono {
+		t.Errorf("Expected Go Mono's width %v, got %v", mono, got)
+	}
+
+	if !p.HasGlyph("Go", 'a') {
+		t.Error("Expected Go to cover 'a'")
+	}
+	if p.HasGlyph("Go", '世') {
+		t.Error("Expected Go not to cover '世'")
+	}
+	if p.HasGlyph("Unknown", 'a') {
+		t.Error("Expected an unregistered family to cover nothing")
+	}
+}
+
+func TestXHeight(t *testing.T) {
+	p := newTestProvider(t)
+	x := p.XHeight(layout.TextStyle{FontFamily: "Go", FontSize: 20})
+	if x <= 0 || x >= 20 {
+		t.Errorf("Expected an x-height below the 20px font size, got %v", x)
+	}
+	if got := New().XHeight(layout.TextStyle{FontSize: 20}); got != 10 {
+		t.Errorf("Expected 0.5em without fonts, got %v", got)
+	}
+}
+
+func TestDecorationMetrics(t *testing.T) {
+	p := newTestProvider(t)
+	underline, thickness, strike, strikeThickness := p.DecorationMetrics(layout.TextStyle{FontFamily: "Go", FontSize: 20})
+	if underline >= 0 || strike <= 0 {
+		t.Errorf("Expected an underline below and a strikethrough above the baseline, got %v and %v", underline, strike)
+	}
+	if thickness <= 0 || thickness > 4 || strikeThickness <= 0 || strikeThickness > 4 {
+		t.Errorf("Expected thin decorations, got %v and %v", thickness, strikeThickness)
+	}
+}
+
+func TestLayoutWithProvider(t *testing.T) {
+	p := newTestProvider(t)
+	layout.SetTextMetricsProvider(p)
+
+	style := layout.TextStyle{FontFamily: "Go", FontSize: 16, LetterSpacing: -1}
+	want, _, _ := p.Measure("Hello", style)
+	node := layout.Text("Hello", layout.Style{TextStyle: &style})
+	layout.Layout(node, layout.Loose(400, 100), layout.NewLayoutContext(800, 600, 16))
+	if math.Abs(node.Rect.Width-want) > 0.01 {
+		t.Errorf("Expected width %v, got %v", want, node.Rect.Width)
+	}
+}
@@ -0,0 +1,34 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+)
+
+// GenerateGo returns Go source for package pkg that embeds the snapshot
+// file snapFile (relative to the generated file) and exposes it as a
+// package-level *Snapshot named varName.
+func GenerateGo(pkg, varName, snapFile string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("snapshot: invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(varName) {
+		return nil, fmt.Errorf("snapshot: invalid variable name %q", varName)
+	}
+
+	// Keep the raw bytes unexported
+	dataName := strings.ToLower(varName[:1]) + varName[1:] + "Data"
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by layoutsnap; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import (\n\t_ \"embed\"\n\n\t\"github.com/SCKelemen/layout/snapshot\"\n)\n\n")
+	fmt.Fprintf(&b, "//go:embed %s\n", snapFile)
+	fmt.Fprintf(&b, "var %s []byte\n\n", dataName)
+	fmt.Fprintf(&b, "// %s is the precomputed layout embedded from %s.\n", varName, snapFile)
+	fmt.Fprintf(&b, "var %s = snapshot.MustLoad(%s)\n", varName, dataName)
+	return format.Source(b.Bytes())
+}
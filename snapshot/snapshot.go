@@ -0,0 +1,434 @@
+// Package snapshot stores computed layouts in a compact, read-only binary
+// form that can be embedded in a binary and queried without running the
+// layout engine.
+//
+// Static dashboards and document templates often lay out the same tree on
+// every start. Precompute it at build time with the layoutsnap tool (or
+// Encode), embed the result with go:embed, and Load it at runtime:
+//
+//	//go:embed dashboard.snap
+//	var dashboardData []byte
+//
+//	var dashboard = snapshot.MustLoad(dashboardData)
+//
+//	header := dashboard.Root().ChildAt(0)
+//	fmt.Println(header.AbsoluteRect(), header.Text())
+//
+// Load does not copy or unpack the data: records are decoded on demand
+// straight from the byte slice, so a snapshot costs little more than its
+// embedded bytes. Nodes are stored in document (pre-order) order, so every
+// subtree is a contiguous range of indices.
+//
+// Coordinates are stored as float32. This is exact for integral and
+// half-pixel values and accurate to well under a pixel for any realistic
+// page size.
+package snapshot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Format constants.
+const (
+	magic   = "LSNP"
+	version = 1
+
+	headerSize = 20 // magic, version, flags, node count, line count, string bytes
+	nodeSize   = 48
+	lineSize   = 20
+)
+
+// noIndex marks a missing parent, child or sibling.
+const noIndex = math.MaxUint32
+
+// ErrInvalid is returned by Load for data that is not a valid snapshot.
+var ErrInvalid = errors.New("snapshot: invalid data")
+
+// Encode records root's computed layout: rects, baselines, text and text
+// lines. It does not run layout, so call layout.Layout first.
+//
+// Nodes with display: none are stored too (with their zero rects), so
+// indices match a pre-order walk of the input tree.
+func Encode(root *layout.Node) []byte {
+	var nodes []*layout.Node
+	var walk func(n *layout.Node)
+	walk = func(n *layout.Node) {
+		nodes = append(nodes, n)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+
+	index := make(map[*layout.Node]uint32, len(nodes))
+	for i, n := range nodes {
+		index[n] = uint32(i)
+	}
+
+	var strs strings.Builder
+	addString := func(s string) (uint32, uint32) {
+		off := uint32(strs.Len())
+		strs.WriteString(s)
+		return off, uint32(len(s))
+	}
+
+	nodeBuf := make([]byte, 0, len(nodes)*nodeSize)
+	var lineBuf []byte
+	lineCount := uint32(0)
+	parents := make(map[*layout.Node]uint32, len(nodes))
+	nextSiblings := make(map[*layout.Node]uint32, len(nodes))
+	for _, n := range nodes {
+		for i, child := range n.Children {
+			parents[child] = index[n]
+			if i+1 < len(n.Children) {
+				nextSiblings[child] = index[n.Children[i+1]]
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		parent, ok := parents[n]
+		if !ok {
+			parent = noIndex
+		}
+		firstChild := uint32(noIndex)
+		if len(n.Children) > 0 {
+			firstChild = index[n.Children[0]]
+		}
+		nextSibling, ok := nextSiblings[n]
+		if !ok {
+			nextSibling = noIndex
+		}
+
+		lineStart := lineCount
+		if n.TextLayout != nil {
+			for _, line := range n.TextLayout.Lines {
+				off, length := addString(lineText(line))
+				lineBuf = appendFloat32(lineBuf, line.OffsetX)
+				lineBuf = appendFloat32(lineBuf, line.OffsetY)
+				lineBuf = appendFloat32(lineBuf, line.Width)
+				lineBuf = binary.LittleEndian.AppendUint32(lineBuf, off)
+				lineBuf = binary.LittleEndian.AppendUint32(lineBuf, length)
+				lineCount++
+			}
+		}
+		textOff, textLen := addString(n.Text)
+
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, parent)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, firstChild)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, nextSibling)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, lineStart)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, lineCount-lineStart)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, textOff)
+		nodeBuf = binary.LittleEndian.AppendUint32(nodeBuf, textLen)
+		nodeBuf = appendFloat32(nodeBuf, n.Baseline)
+		nodeBuf = appendFloat32(nodeBuf, n.Rect.X)
+		nodeBuf = appendFloat32(nodeBuf, n.Rect.Y)
+		nodeBuf = appendFloat32(nodeBuf, n.Rect.Width)
+		nodeBuf = appendFloat32(nodeBuf, n.Rect.Height)
+	}
+
+	out := make([]byte, 0, headerSize+len(nodeBuf)+len(lineBuf)+strs.Len())
+	out = append(out, magic...)
+	out = binary.LittleEndian.AppendUint16(out, version)
+	out = binary.LittleEndian.AppendUint16(out, 0) // flags, reserved
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(nodes)))
+	out = binary.LittleEndian.AppendUint32(out, lineCount)
+	out = binary.LittleEndian.AppendUint32(out, uint32(strs.Len()))
+	out = append(out, nodeBuf...)
+	out = append(out, lineBuf...)
+	out = append(out, strs.String()...)
+	return out
+}
+
+// lineText returns the text of line as painted: its boxes in display order,
+// separated by a space only where the line has inter-word spaces. Lines that
+// keep white space (pre, pre-wrap) carry their spaces and tabs in the boxes.
+func lineText(line layout.TextLine) string {
+	sep := ""
+	if line.SpaceCount > 0 {
+		sep = " "
+	}
+	texts := make([]string, len(line.Boxes))
+	for i, box := range line.Boxes {
+		texts[i] = box.VisualText()
+	}
+	return strings.Join(texts, sep)
+}
+
+func appendFloat32(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v)))
+}
+
+// Snapshot is a loaded, read-only layout snapshot. It is safe for
+// concurrent use.
+type Snapshot struct {
+	data      []byte
+	nodeCount int
+	lineCount int
+	lines     int // byte offset of the line table
+	strs      int // byte offset of the string blob
+}
+
+// Load validates data and returns a snapshot that reads from it directly.
+// data must not be modified while the snapshot is in use.
+func Load(data []byte) (*Snapshot, error) {
+	if len(data) < headerSize || string(data[:4]) != magic {
+		return nil, ErrInvalid
+	}
+	if v := binary.LittleEndian.Uint16(data[4:]); v != version {
+		return nil, fmt.Errorf("snapshot: unsupported version %d", v)
+	}
+	nodeCount := int(binary.LittleEndian.Uint32(data[8:]))
+	lineCount := int(binary.LittleEndian.Uint32(data[12:]))
+	strBytes := int(binary.LittleEndian.Uint32(data[16:]))
+
+	s := &Snapshot{
+		data:      data,
+		nodeCount: nodeCount,
+		lineCount: lineCount,
+		lines:     headerSize + nodeCount*nodeSize,
+	}
+	s.strs = s.lines + lineCount*lineSize
+	if s.strs+strBytes != len(data) {
+		return nil, ErrInvalid
+	}
+
+	// Check every reference once so accessors can trust the data. In
+	// pre-order, parents come before and children/siblings after a node,
+	// which also rules out cycles.
+	before := func(ref uint32, i int) bool { return ref == noIndex || int(ref) < i }
+	after := func(ref uint32, i int) bool { return ref == noIndex || (int(ref) > i && int(ref) < nodeCount) }
+	for i := 0; i < nodeCount; i++ {
+		rec := s.node(i)
+		lineStart, lineLen := int(rec[3]), int(rec[4])
+		if !before(rec[0], i) || !after(rec[1], i) || !after(rec[2], i) ||
+			lineStart+lineLen > lineCount || int(rec[5])+int(rec[6]) > strBytes {
+			return nil, ErrInvalid
+		}
+	}
+	for i := 0; i < lineCount; i++ {
+		off := s.lines + i*lineSize
+		if int(binary.LittleEndian.Uint32(data[off+12:]))+int(binary.LittleEndian.Uint32(data[off+16:])) > strBytes {
+			return nil, ErrInvalid
+		}
+	}
+	return s, nil
+}
+
+// MustLoad is like Load but panics on invalid data. It is intended for
+// package-level variables initialized from embedded snapshots.
+func MustLoad(data []byte) *Snapshot {
+	s, err := Load(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Len returns the number of nodes in the snapshot.
+func (s *Snapshot) Len() int {
+	return s.nodeCount
+}
+
+// Root returns the root node, or an invalid Node for an empty snapshot.
+func (s *Snapshot) Root() Node {
+	return s.At(0)
+}
+
+// At returns the node at pre-order index i, or an invalid Node if i is out
+// of range.
+func (s *Snapshot) At(i int) Node {
+	if i < 0 || i >= s.nodeCount {
+		return Node{}
+	}
+	return Node{s: s, i: i}
+}
+
+// NodeAt returns the deepest node whose absolute rect contains (x, y), or an
+// invalid Node if the point is outside the root. Later siblings win, as they
+// paint on top.
+func (s *Snapshot) NodeAt(x, y float64) Node {
+	var hit func(n Node, ox, oy float64) Node
+	hit = func(n Node, ox, oy float64) Node {
+		r := n.Rect()
+		ax, ay := ox+r.X, oy+r.Y
+		if x < ax || y < ay || x >= ax+r.Width || y >= ay+r.Height {
+			return Node{}
+		}
+		found := n
+		for c := n.FirstChild(); c.Valid(); c = c.NextSibling() {
+			if h := hit(c, ax, ay); h.Valid() {
+				found = h
+			}
+		}
+		return found
+	}
+	if s.nodeCount == 0 {
+		return Node{}
+	}
+	return hit(s.Root(), 0, 0)
+}
+
+// node decodes the integer fields of node record i.
+func (s *Snapshot) node(i int) [7]uint32 {
+	var rec [7]uint32
+	off := headerSize + i*nodeSize
+	for f := range rec {
+		rec[f] = binary.LittleEndian.Uint32(s.data[off+f*4:])
+	}
+	return rec
+}
+
+// nodeFloat decodes float field f (0 = baseline, 1..4 = rect) of node i.
+func (s *Snapshot) nodeFloat(i, f int) float64 {
+	off := headerSize + i*nodeSize + 28 + f*4
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(s.data[off:])))
+}
+
+func (s *Snapshot) str(off, length uint32) string {
+	start := s.strs + int(off)
+	return string(s.data[start : start+int(length)])
+}
+
+// Node is a lightweight handle to one node in a Snapshot. The zero Node is
+// invalid; its accessors return zero values.
+type Node struct {
+	s *Snapshot
+	i int
+}
+
+// Line is one line of laid-out text, relative to its node's content box.
+// Text is the line as painted, in display order.
+type Line struct {
+	Text    string
+	OffsetX float64
+	OffsetY float64
+	Width   float64
+}
+
+// Valid reports whether n refers to a node.
+func (n Node) Valid() bool {
+	return n.s != nil
+}
+
+// Index returns n's pre-order index, or -1 for an invalid Node.
+func (n Node) Index() int {
+	if n.s == nil {
+		return -1
+	}
+	return n.i
+}
+
+func (n Node) link(field int) Node {
+	if n.s == nil {
+		return Node{}
+	}
+	idx := n.s.node(n.i)[field]
+	if idx == noIndex {
+		return Node{}
+	}
+	return Node{s: n.s, i: int(idx)}
+}
+
+// Parent returns n's parent, or an invalid Node for the root.
+func (n Node) Parent() Node { return n.link(0) }
+
+// FirstChild returns n's first child, or an invalid Node.
+func (n Node) FirstChild() Node { return n.link(1) }
+
+// NextSibling returns n's next sibling, or an invalid Node.
+func (n Node) NextSibling() Node { return n.link(2) }
+
+// Children returns n's children in order.
+func (n Node) Children() []Node {
+	var out []Node
+	for c := n.FirstChild(); c.Valid(); c = c.NextSibling() {
+		out = append(out, c)
+	}
+	return out
+}
+
+// ChildAt returns n's i-th child, or an invalid Node if out of range.
+func (n Node) ChildAt(i int) Node {
+	c := n.FirstChild()
+	for ; c.Valid() && i > 0; i-- {
+		c = c.NextSibling()
+	}
+	if i != 0 {
+		return Node{}
+	}
+	return c
+}
+
+// Rect returns n's rect relative to its parent, as computed by layout.
+func (n Node) Rect() layout.Rect {
+	if n.s == nil {
+		return layout.Rect{}
+	}
+	return layout.Rect{
+		X:      n.s.nodeFloat(n.i, 1),
+		Y:      n.s.nodeFloat(n.i, 2),
+		Width:  n.s.nodeFloat(n.i, 3),
+		Height: n.s.nodeFloat(n.i, 4),
+	}
+}
+
+// AbsoluteRect returns n's rect relative to the root's origin.
+func (n Node) AbsoluteRect() layout.Rect {
+	r := n.Rect()
+	for p := n.Parent(); p.Valid(); p = p.Parent() {
+		pr := p.Rect()
+		r.X += pr.X
+		r.Y += pr.Y
+	}
+	return r
+}
+
+// Baseline returns n's Baseline field.
+func (n Node) Baseline() float64 {
+	if n.s == nil {
+		return 0
+	}
+	return n.s.nodeFloat(n.i, 0)
+}
+
+// Text returns n's Text field.
+func (n Node) Text() string {
+	if n.s == nil {
+		return ""
+	}
+	rec := n.s.node(n.i)
+	return n.s.str(rec[5], rec[6])
+}
+
+// Lines returns n's laid-out text lines, or nil for non-text nodes.
+func (n Node) Lines() []Line {
+	if n.s == nil {
+		return nil
+	}
+	rec := n.s.node(n.i)
+	if rec[4] == 0 {
+		return nil
+	}
+	lines := make([]Line, rec[4])
+	for i := range lines {
+		off := n.s.lines + (int(rec[3])+i)*lineSize
+		d := n.s.data[off:]
+		lines[i] = Line{
+			OffsetX: float64(math.Float32frombits(binary.LittleEndian.Uint32(d[0:]))),
+			OffsetY: float64(math.Float32frombits(binary.LittleEndian.Uint32(d[4:]))),
+			Width:   float64(math.Float32frombits(binary.LittleEndian.Uint32(d[8:]))),
+			Text:    n.s.str(binary.LittleEndian.Uint32(d[12:]), binary.LittleEndian.Uint32(d[16:])),
+		}
+	}
+	return lines
+}
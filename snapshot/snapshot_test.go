@@ -0,0 +1,192 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func buildTree() *layout.Node {
+	label := layout.Text("hello snapshot world")
+	root := &layout.Node{
+		Style: layout.Style{
+			Display: layout.DisplayBlock,
+			Width:   layout.Px(200),
+			Padding: layout.Uniform(layout.Px(10)),
+		},
+		Children: []*layout.Node{
+			{Style: layout.Style{Width: layout.Px(80), Height: layout.Px(40)}},
+			label,
+			layout.HStack(layout.Fixed(30, 20), layout.Fixed(50, 20)),
+		},
+	}
+	root.Children[2].Style.Width = layout.Px(180)
+	layout.Layout(root, layout.Loose(400, 400), layout.NewLayoutContext(400, 400, 16))
+	return root
+}
+
+func TestRoundTrip(t *testing.T) {
+	root := buildTree()
+	s, err := Load(Encode(root))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if s.Len() != 6 {
+		t.Fatalf("Expected 6 nodes, got %d", s.Len())
+	}
+	if got, want := s.Root().Rect(), root.Rect; got != want {
+		t.Errorf("Root rect: expected %+v, got %+v", want, got)
+	}
+
+	row := s.Root().ChildAt(2)
+	if got, want := row.ChildAt(1).Rect(), root.Children[2].Children[1].Rect; got != want {
+		t.Errorf("Nested rect: expected %+v, got %+v", want, got)
+	}
+	abs := row.ChildAt(1).AbsoluteRect()
+	wantX := root.Rect.X + root.Children[2].Rect.X + root.Children[2].Children[1].Rect.X
+	if abs.X != wantX {
+		t.Errorf("Expected absolute X %.2f, got %.2f", wantX, abs.X)
+	}
+	if p := row.ChildAt(1).Parent(); p.Index() != row.Index() {
+		t.Errorf("Expected parent index %d, got %d", row.Index(), p.Index())
+	}
+	if n := len(s.Root().Children()); n != 3 {
+		t.Errorf("Expected 3 root children, got %d", n)
+	}
+	if s.Root().ChildAt(3).Valid() {
+		t.Error("Expected out-of-range child to be invalid")
+	}
+}
+
+func TestTextLines(t *testing.T) {
+	root := buildTree()
+	s := MustLoad(Encode(root))
+
+	label := s.Root().ChildAt(1)
+	if label.Text() != "hello snapshot world" {
+		t.Errorf("Expected label text, got %q", label.Text())
+	}
+	lines := label.Lines()
+	want := root.Children[1].TextLayout.Lines
+	if len(lines) != len(want) || len(lines) == 0 {
+		t.Fatalf("Expected %d lines, got %d", len(want), len(lines))
+	}
+	var joined []string
+	for i, line := range lines {
+		if line.OffsetY != want[i].OffsetY || line.Width != float64(float32(want[i].Width)) {
+			t.Errorf("Line %d: expected offset %.2f width %.2f, got %.2f and %.2f", i, want[i].OffsetY, want[i].Width, line.OffsetY, line.Width)
+		}
+		joined = append(joined, line.Text)
+	}
+	if strings.Join(joined, " ") != "hello snapshot world" {
+		t.Errorf("Expected line text to reassemble the label, got %q", joined)
+	}
+	if s.Root().Lines() != nil {
+		t.Error("Expected no lines for a non-text node")
+	}
+}
+
+func TestLineTextAsPainted(t *testing.T) {
+	pre := layout.Text("ab  cd\tef gh", layout.Style{TextStyle: &layout.TextStyle{WhiteSpace: layout.WhiteSpacePreWrap}})
+	narrow := &layout.Node{Style: layout.Style{Display: layout.DisplayBlock, Width: layout.Px(40)}, Children: []*layout.Node{pre}}
+	rtl := layout.Text("שלום עולם", layout.Style{TextStyle: &layout.TextStyle{Direction: layout.DirectionRTL}})
+	root := &layout.Node{Style: layout.Style{Display: layout.DisplayBlock, Width: layout.Px(300)}, Children: []*layout.Node{narrow, rtl}}
+	layout.Layout(root, layout.Loose(400, 400), layout.NewLayoutContext(400, 400, 16))
+	s := MustLoad(Encode(root))
+
+	// Wrapped pre-wrap lines keep their spaces and tabs, and add none
+	lines := s.Root().ChildAt(0).ChildAt(0).Lines()
+	if len(lines) < 2 {
+		t.Fatalf("Expected the pre-wrap text to wrap, got %d lines", len(lines))
+	}
+	var pieces []string
+	for _, line := range lines {
+		pieces = append(pieces, line.Text)
+	}
+	if got := strings.Join(pieces, ""); got != "ab  cd\tef gh" {
+		t.Errorf("Expected pre-wrap lines to reassemble the source, got %q", pieces)
+	}
+
+	// Right-to-left text is stored in display order
+	lines = s.Root().ChildAt(1).Lines()
+	if len(lines) != 1 || lines[0].Text != "םלוע םולש" {
+		t.Errorf("Expected one line in display order, got %+v", lines)
+	}
+}
+
+func TestNodeAt(t *testing.T) {
+	root := buildTree()
+	s := MustLoad(Encode(root))
+
+	// Inside the first fixed child (padding 10 → origin at 10,10)
+	if hit := s.NodeAt(15, 15); hit.Index() != 1 {
+		t.Errorf("Expected hit on node 1, got %d", hit.Index())
+	}
+	// Inside the root's padding, outside every child
+	if hit := s.NodeAt(2, 2); hit.Index() != 0 {
+		t.Errorf("Expected hit on root, got %d", hit.Index())
+	}
+	if hit := s.NodeAt(-5, 5); hit.Valid() {
+		t.Errorf("Expected miss outside root, got %d", hit.Index())
+	}
+}
+
+func TestLoadRejectsInvalidData(t *testing.T) {
+	good := Encode(buildTree())
+
+	if _, err := Load(good[:len(good)-1]); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for truncated data, got %v", err)
+	}
+	if _, err := Load([]byte("not a snapshot at all")); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for bad magic, got %v", err)
+	}
+
+	// Point the root's parent at itself
+	bad := bytes.Clone(good)
+	binary.LittleEndian.PutUint32(bad[headerSize:], 0)
+	if _, err := Load(bad); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for self-parented root, got %v", err)
+	}
+
+	future := bytes.Clone(good)
+	binary.LittleEndian.PutUint16(future[4:], version+1)
+	if _, err := Load(future); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
+
+func TestEmptySnapshot(t *testing.T) {
+	s := MustLoad(Encode(nil))
+	if s.Len() != 0 || s.Root().Valid() || s.NodeAt(0, 0).Valid() {
+		t.Error("Expected empty snapshot with invalid root")
+	}
+	if s.Root().Rect() != (layout.Rect{}) || s.Root().Text() != "" {
+		t.Error("Expected zero values from invalid node")
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := GenerateGo("dashboard", "Dashboard", "dashboard.snap")
+	if err != nil {
+		t.Fatalf("GenerateGo failed: %v", err)
+	}
+	for _, want := range []string{
+		"// Code generated by layoutsnap; DO NOT EDIT.",
+		"package dashboard",
+		"//go:embed dashboard.snap",
+		"var Dashboard = snapshot.MustLoad(dashboardData)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Expected generated source to contain %q\n%s", want, src)
+		}
+	}
+
+	if _, err := GenerateGo("not-a-package", "X", "x.snap"); err == nil {
+		t.Error("Expected error for invalid package name")
+	}
+}
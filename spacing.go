@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSpacing parses a CSS margin-style shorthand of 1 to 4 values into
+// a Spacing. As in CSS, the values are the top, right, bottom and left
+// sides, and a missing side copies the opposite one:
+//
+//	"10px"            all sides
+//	"10px 20px"       top/bottom, right/left
+//	"1px 2px 3px"     top, right/left, bottom
+//	"1px 2px 3px 4px" top, right, bottom, left
+//
+// Values are lengths in any unit, percentages, calc() expressions or a
+// unitless 0. Margins may be negative or auto; use ParseBox for padding
+// and borders, which may not.
+//
+// Example:
+//
+//	margin, err := layout.ParseSpacing("8px auto")
+func ParseSpacing(s string) (Spacing, error) {
+	return parseSpacingShorthand(s, true)
+}
+
+// ParseBox parses a padding- or border-width-style shorthand of 1 to 4
+// values with the same rules as ParseSpacing, but rejects auto and
+// negative values.
+//
+// Example:
+//
+//	padding, err := layout.ParseBox("1px 2px 3px 4px")
+func ParseBox(s string) (Spacing, error) {
+	return parseSpacingShorthand(s, false)
+}
+
+// SetMargin sets the margin from a shorthand such as "0 auto" (see
+// ParseSpacing). On error, the margin is left unchanged.
+func (s *Style) SetMargin(value string) error {
+	sp, err := ParseSpacing(value)
+	if err != nil {
+		return err
+	}
+	s.Margin = sp
+	return nil
+}
+
+// SetPadding sets the padding from a shorthand such as "4px 8px" (see
+// ParseBox). On error, the padding is left unchanged.
+func (s *Style) SetPadding(value string) error {
+	sp, err := ParseBox(value)
+	if err != nil {
+		return err
+	}
+	s.Padding = sp
+	return nil
+}
+
+func parseSpacingShorthand(s string, margin bool) (Spacing, error) {
+	fields := splitLengthFields(s)
+	if len(fields) < 1 || len(fields) > 4 {
+		return Spacing{}, fmt.Errorf("layout: invalid spacing %q: expected 1 to 4 values", s)
+	}
+	var sides [4]Length
+	for i, f := range fields {
+		l, err := parseSpacingValue(f, margin)
+		if err != nil {
+			return Spacing{}, fmt.Errorf("layout: invalid spacing %q: %v", s, err)
+		}
+		sides[i] = l
+	}
+	switch len(fields) {
+	case 1:
+		return Uniform(sides[0]), nil
+	case 2:
+		return Spacing{Top: sides[0], Right: sides[1], Bottom: sides[0], Left: sides[1]}, nil
+	case 3:
+		return Spacing{Top: sides[0], Right: sides[1], Bottom: sides[2], Left: sides[1]}, nil
+	}
+	return Spacing{Top: sides[0], Right: sides[1], Bottom: sides[2], Left: sides[3]}, nil
+}
+
+func parseSpacingValue(s string, margin bool) (Length, error) {
+	if strings.EqualFold(s, "auto") {
+		if !margin {
+			return Length{}, fmt.Errorf("auto isn't allowed")
+		}
+		return Auto(), nil
+	}
+	l, err := parseLengthValue(s)
+	if err != nil {
+		return Length{}, err
+	}
+	if !margin && !isCalc(l) && l.Value < 0 {
+		return Length{}, fmt.Errorf("negative value %q", s)
+	}
+	return l, nil
+}
+
+// parseLengthValue parses a single CSS length: a number with a unit, a
+// percentage, a calc() expression or a unitless 0. Units the engine
+// doesn't know are kept for the context's UnitResolver.
+func parseLengthValue(s string) (Length, error) {
+	if s == "0" {
+		return Px(0), nil
+	}
+	n, err := parseCalcExpression(s)
+	if err != nil {
+		return Length{}, fmt.Errorf("invalid length %q", s)
+	}
+	if n.op == 0 {
+		return n.leaf, nil
+	}
+	return Length{Value: 1, Unit: LengthUnit(n.String())}, nil
+}
+
+// splitLengthFields splits s at whitespace outside parentheses, so that
+// "calc(1px + 2px) 3px" is two fields.
+func splitLengthFields(s string) []string {
+	var fields []string
+	depth, start := 0, -1
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isSelectorSpace(c) && depth <= 0:
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, s[start:])
+	}
+	return fields
+}
@@ -0,0 +1,62 @@
+package layout
+
+import "testing"
+
+func TestParseSpacing(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Spacing
+	}{
+		{"10px", Uniform(Px(10))},
+		{"10px 20px", Spacing{Top: Px(10), Right: Px(20), Bottom: Px(10), Left: Px(20)}},
+		{"1px 2px 3px", Spacing{Top: Px(1), Right: Px(2), Bottom: Px(3), Left: Px(2)}},
+		{"1px 2px 3px 4px", Spacing{Top: Px(1), Right: Px(2), Bottom: Px(3), Left: Px(4)}},
+		{" 0  auto ", Spacing{Top: Px(0), Right: Auto(), Bottom: Px(0), Left: Auto()}},
+		{"-4px 1.5em 10% 2REM", Spacing{Top: Px(-4), Right: Em(1.5), Bottom: Percent(10), Left: Rem(2)}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpacing(tt.value)
+		if err != nil {
+			t.Errorf("ParseSpacing(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expected ParseSpacing(%q) = %+v, got %+v", tt.value, tt.want, got)
+		}
+	}
+
+	calc, err := ParseSpacing("calc(1em + 2px) 3px")
+	if err != nil {
+		t.Fatalf("ParseSpacing with calc(): %v", err)
+	}
+	if got := ResolveLength(calc.Top, nil, 10); got != 12 || calc.Right != Px(3) || calc.Left != Px(3) {
+		t.Errorf("Expected a 12px top and 3px sides, got %+v", calc)
+	}
+
+	for _, bad := range []string{"", "10", "1px 2px 3px 4px 5px", "wide", "1px,2px"} {
+		if _, err := ParseSpacing(bad); err == nil {
+			t.Errorf("Expected ParseSpacing(%q) to fail", bad)
+		}
+	}
+	for _, bad := range []string{"auto", "1px -2px"} {
+		if _, err := ParseBox(bad); err == nil {
+			t.Errorf("Expected ParseBox(%q) to fail", bad)
+		}
+	}
+}
+
+func TestStyleSetMarginPadding(t *testing.T) {
+	var s Style
+	if err := s.SetMargin("0 auto"); err != nil {
+		t.Fatalf("SetMargin: %v", err)
+	}
+	if err := s.SetPadding("4px 8px"); err != nil {
+		t.Fatalf("SetPadding: %v", err)
+	}
+	if s.Margin.Left != Auto() || s.Margin.Top != Px(0) || s.Padding.Left != Px(8) || s.Padding.Bottom != Px(4) {
+		t.Errorf("Expected margin 0 auto and padding 4px 8px, got %+v and %+v", s.Margin, s.Padding)
+	}
+	if err := s.SetPadding("auto"); err == nil || s.Padding.Left != Px(8) {
+		t.Errorf("Expected an error and the padding unchanged, got %v and %+v", err, s.Padding)
+	}
+}
@@ -0,0 +1,119 @@
+package layout
+
+import "slices"
+
+// State variants attach alternate Styles to a node for UI states such as
+// "hover", "focus" or "active", so frameworks built on this package don't
+// have to swap styles by hand:
+//
+//	button := layout.Fixed(80, 30).
+//	    SetStateStyle("hover", hoverStyle).
+//	    SetStateStyle("active", pressedStyle)
+//	layout.ApplyStates(root, constraints, ctx, layout.StateChange{Node: button, State: "hover", Active: true})
+//
+// While states with variants are active, the variant of the most recently
+// activated one replaces the node's Style. Deactivating every such state
+// restores the Style the node had before. States are plain strings; a
+// state without a variant is still tracked (see HasState), so selectors or
+// renderers can use it.
+
+// SetStateStyle sets the Style used while state is active. If state is
+// already active, n.Style is updated. Returns n for chaining.
+func (n *Node) SetStateStyle(state string, style Style) *Node {
+	if n.stateStyles == nil {
+		n.stateStyles = make(map[string]Style)
+	}
+	n.stateStyles[state] = style
+	n.applyStates()
+	return n
+}
+
+// StateStyle returns the Style set for state by SetStateStyle.
+func (n *Node) StateStyle(state string) (Style, bool) {
+	style, ok := n.stateStyles[state]
+	return style, ok
+}
+
+// SetState activates or deactivates state on n, and reports whether that
+// changed n.Style. Changes made to n.Style while a variant is applied are
+// lost when the variant is removed.
+func (n *Node) SetState(state string, active bool) bool {
+	i := slices.Index(n.states, state)
+	switch {
+	case active && i < 0:
+		n.states = append(n.states, state)
+	case !active && i >= 0:
+		n.states = slices.Delete(n.states, i, i+1)
+	default:
+		return false
+	}
+	if _, ok := n.stateStyles[state]; !ok {
+		return false
+	}
+	n.applyStates()
+	return true
+}
+
+// HasState reports whether state is active on n.
+func (n *Node) HasState(state string) bool {
+	return slices.Contains(n.states, state)
+}
+
+// States returns the states active on n, in activation order.
+func (n *Node) States() []string {
+	return slices.Clone(n.states)
+}
+
+// applyStates sets n.Style to the variant of the most recently activated
+// state that has one, or back to the base style.
+func (n *Node) applyStates() {
+	for i := len(n.states) - 1; i >= 0; i-- {
+		if style, ok := n.stateStyles[n.states[i]]; ok {
+			if n.baseStyle == nil {
+				base := n.Style
+				n.baseStyle = &base
+			}
+			n.Style = style
+			return
+		}
+	}
+	if n.baseStyle != nil {
+		n.Style = *n.baseStyle
+		n.baseStyle = nil
+	}
+}
+
+// StateChange activates or deactivates a state on a node.
+type StateChange struct {
+	Node   *Node
+	State  string
+	Active bool
+}
+
+// ApplyStates applies changes and, if any of them changed a Style, lays
+// root out again. It returns root's size and whether layout ran.
+//
+// Relayout is incremental when ctx has a LayoutCache (see WithCache): the
+// subtrees whose styles didn't change are restored from the cache, so only
+// the changed nodes and their ancestors are laid out again.
+//
+// Example:
+//
+//	ctx := layout.NewLayoutContext(800, 600, 16).WithCache(layout.NewLayoutCache())
+//	layout.Layout(root, constraints, ctx)
+//	// Pointer moved from a to b
+//	layout.ApplyStates(root, constraints, ctx,
+//	    layout.StateChange{Node: a, State: "hover", Active: false},
+//	    layout.StateChange{Node: b, State: "hover", Active: true})
+func ApplyStates(root *Node, constraints Constraints, ctx *LayoutContext, changes ...StateChange) (Size, bool) {
+	changed := false
+	for _, c := range changes {
+		if c.Node != nil && c.Node.SetState(c.State, c.Active) {
+			changed = true
+		}
+	}
+	if !changed {
+		return Size{Width: root.Rect.Width, Height: root.Rect.Height}, false
+	}
+	return Layout(root, constraints, ctx), true
+}
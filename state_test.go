@@ -0,0 +1,77 @@
+package layout
+
+import "testing"
+
+func TestStateStyles(t *testing.T) {
+	base := Style{Width: Px(80), Height: Px(30)}
+	button := &Node{Style: base}
+	button.SetStateStyle("hover", Style{Width: Px(90), Height: Px(30)}).
+		SetStateStyle("active", Style{Width: Px(70), Height: Px(28)})
+
+	if !button.SetState("hover", true) || button.Style.Width != Px(90) {
+		t.Errorf("Expected the hover style, got width %v", button.Style.Width)
+	}
+	// The most recently activated state wins
+	button.SetState("active", true)
+	if button.Style.Width != Px(70) {
+		t.Errorf("Expected the active style, got width %v", button.Style.Width)
+	}
+	// A state without a variant is tracked but doesn't change the style
+	if button.SetState("focus", true) || !button.HasState("focus") {
+		t.Errorf("Expected focus to be tracked without a style change")
+	}
+	if button.SetState("active", true) {
+		t.Errorf("Expected activating an active state to change nothing")
+	}
+	button.SetState("active", false)
+	if button.Style.Width != Px(90) {
+		t.Errorf("Expected the hover style after releasing, got width %v", button.Style.Width)
+	}
+	button.SetState("hover", false)
+	if button.Style.Width != Px(80) || button.Style.Height != Px(30) {
+		t.Errorf("Expected the base style to be restored, got %+v", button.Style)
+	}
+	if got := button.States(); len(got) != 1 || got[0] != "focus" {
+		t.Errorf("Expected only focus to be active, got %v", got)
+	}
+}
+
+func TestApplyStatesRelayout(t *testing.T) {
+	list := &Node{Style: Style{Display: DisplayBlock, Width: Px(300), Height: Px(-1)}}
+	for i := 0; i < 4; i++ {
+		list.Children = append(list.Children, &Node{
+			Style:    Style{Display: DisplayBlock, Width: Px(-1), Height: Px(-1), Padding: Uniform(Px(4))},
+			Children: []*Node{Fixed(20, 20)},
+		})
+	}
+	target := list.Children[2]
+	hover := target.Style
+	hover.Padding = Uniform(Px(10))
+	target.SetStateStyle("hover", hover)
+
+	cache := NewLayoutCache()
+	ctx := NewLayoutContext(800, 600, 16).WithCache(cache)
+	constraints := Loose(800, Unbounded)
+	Layout(list, constraints, ctx)
+	before := cache.Stats()
+
+	if _, ran := ApplyStates(list, constraints, ctx, StateChange{Node: target, State: "focus", Active: true}); ran {
+		t.Errorf("Expected no relayout for a state without a style")
+	}
+
+	size, ran := ApplyStates(list, constraints, ctx, StateChange{Node: target, State: "hover", Active: true})
+	if !ran {
+		t.Fatal("Expected a relayout")
+	}
+	// Rows are 28px high, and the hovered one 40px
+	if size.Height != 124 {
+		t.Errorf("Expected height 124, got %.2f", size.Height)
+	}
+	if y := list.Children[3].Rect.Y; y != 96 {
+		t.Errorf("Expected the next row to move down to 96, got %.2f", y)
+	}
+	// The unchanged rows come from the cache
+	if hits := cache.Stats().Hits - before.Hits; hits != 3 {
+		t.Errorf("Expected 3 unchanged rows restored from the cache, got %d hits", hits)
+	}
+}
@@ -0,0 +1,198 @@
+package layout
+
+import "math"
+
+// Sticky positioning.
+//
+// A sticky box is laid out in normal flow, then shifted to stay inside its
+// scroll container's scrollport, inset by its top, right, bottom and left,
+// as the container scrolls, without leaving its containing block: its grid
+// area if it's a grid item, or else its parent's content box (for a flex
+// item, the flex container's, as in CSS, not its flex line). An inset that
+// isn't set (a zero Length, or auto) doesn't make the box stick on that
+// side.
+//
+// See: https://www.w3.org/TR/css-position-3/#stickypos-insets
+
+// stickyState records where layout put a sticky node, so its offset can be
+// recomputed for each scroll position.
+type stickyState struct {
+	flow  Point // Normal flow position
+	stuck Point // Position set by the last UpdateSticky
+	valid bool
+}
+
+// UpdateSticky positions the sticky descendants of container, a scroll
+// container whose content is scrolled by (scrollX, scrollY), and returns
+// whether any moved. Call it after layout, and again whenever the
+// container scrolls: each call starts from the normal flow positions, so
+// the result depends only on the scroll position. Sticky boxes inside a
+// nested scroll container stick to it instead, at its Node.Scroll.
+// LayoutWithPositioning calls it for the root at the root's Scroll.
+//
+// Example:
+//
+//	layout.LayoutWithPositioning(root, constraints, viewport, ctx)
+//	// On scroll:
+//	layout.UpdateSticky(root, 0, scrollTop, ctx)
+//
+// To scroll a scroll container, update its Scroll and call UpdateSticky
+// with it:
+//
+//	pane.ScrollTo(0, y, ctx)
+//	layout.UpdateSticky(pane, pane.Scroll.X, pane.Scroll.Y, ctx)
+func UpdateSticky(container *Node, scrollX, scrollY float64, ctx *LayoutContext) bool {
+	restore := resolveLogicalTree(container)
+	defer restore()
+	return updateSticky(container, scrollX, scrollY, ctx)
+}
+
+// updateSticky is UpdateSticky with logical insets already resolved.
+func updateSticky(container *Node, scrollX, scrollY float64, ctx *LayoutContext) bool {
+	// The scrollport is the container's padding box, in the coordinates of
+	// its unscrolled content
+	port := container.paddingBox(ctx)
+	port.X += scrollX
+	port.Y += scrollY
+	return updateStickyIn(container, Point{}, port, ctx, true)
+}
+
+// StickyOffset returns how far UpdateSticky moved n from its normal flow
+// position. It's zero for nodes that aren't sticky or aren't stuck.
+func (n *Node) StickyOffset() Point {
+	if !n.sticky.valid || n.Rect.X != n.sticky.stuck.X || n.Rect.Y != n.sticky.stuck.Y {
+		return Point{}
+	}
+	return Point{X: n.sticky.stuck.X - n.sticky.flow.X, Y: n.sticky.stuck.Y - n.sticky.flow.Y}
+}
+
+// updateStickyIn positions the sticky descendants of parent, whose origin
+// is at origin in the scroll container's coordinates. scroller is whether
+// parent is the scroll container.
+func updateStickyIn(parent *Node, origin Point, port Rect, ctx *LayoutContext, scroller bool) bool {
+	moved := false
+	var content Rect
+	hasContent := false
+	for i, child := range parent.Children {
+		if child.Style.Position == PositionSticky && child.Style.Display != DisplayNone {
+			// A grid item is contained by its grid area
+			block, ok := parent.tracks.stickyArea(i)
+			if !ok {
+				if !hasContent {
+					content = contentBoxOf(parent, ctx)
+					if scroller {
+						// Children of the scroll container are contained by its
+						// content, as far as it scrolls
+						size := parent.ScrollSize(ctx)
+						content.Width += math.Max(0, size.Width-port.Width)
+						content.Height += math.Max(0, size.Height-port.Height)
+					}
+					hasContent = true
+				}
+				block = content
+			}
+			block.X += origin.X
+			block.Y += origin.Y
+			if stick(child, origin, block, port, ctx) {
+				moved = true
+			}
+		}
+		// A nested scroll container is the one its sticky descendants stick to
+		var childMoved bool
+		if child.IsScrollContainer() {
+			childMoved = updateSticky(child, child.Scroll.X, child.Scroll.Y, ctx)
+		} else {
+			childMoved = updateStickyIn(child, Point{X: origin.X + child.Rect.X, Y: origin.Y + child.Rect.Y}, port, ctx, false)
+		}
+		if childMoved {
+			moved = true
+		}
+	}
+	return moved
+}
+
+// stick moves node, whose parent is at origin, to stay inside port inset by
+// node's insets without leaving its containing block, block.
+func stick(node *Node, origin Point, block, port Rect, ctx *LayoutContext) bool {
+	// A position other than the one last set means the node was laid out
+	// again since
+	if !node.sticky.valid || node.Rect.X != node.sticky.stuck.X || node.Rect.Y != node.sticky.stuck.Y {
+		node.sticky = stickyState{flow: Point{X: node.Rect.X, Y: node.Rect.Y}, valid: true}
+	}
+	flow := node.sticky.flow
+
+	fontSize := getCurrentFontSize(node, ctx)
+	left, hasLeft := stickyInset(node.Style.Left, ctx, fontSize)
+	right, hasRight := stickyInset(node.Style.Right, ctx, fontSize)
+	top, hasTop := stickyInset(node.Style.Top, ctx, fontSize)
+	bottom, hasBottom := stickyInset(node.Style.Bottom, ctx, fontSize)
+
+	x := origin.X + flow.X
+	y := origin.Y + flow.Y
+	dx := stickyShift(x, node.Rect.Width, port.X, port.X+port.Width, left, right, hasLeft, hasRight, block.X, block.X+block.Width)
+	dy := stickyShift(y, node.Rect.Height, port.Y, port.Y+port.Height, top, bottom, hasTop, hasBottom, block.Y, block.Y+block.Height)
+
+	old := Point{X: node.Rect.X, Y: node.Rect.Y}
+	node.Rect.X, node.Rect.Y = flow.X+dx, flow.Y+dy
+	node.sticky.stuck = Point{X: node.Rect.X, Y: node.Rect.Y}
+	return node.sticky.stuck != old
+}
+
+// stickyShift returns how far to move a box at pos, size long, along one
+// axis: towards the end until it's startInset past the scrollport's start,
+// or towards the start until it's endInset before its end, without leaving
+// the containing block [lo, hi]. The start inset wins if both apply.
+func stickyShift(pos, size, portStart, portEnd, startInset, endInset float64, hasStart, hasEnd bool, lo, hi float64) float64 {
+	if hasStart {
+		if limit := portStart + startInset; pos < limit {
+			shift := limit - pos
+			if room := hi - (pos + size); shift > room {
+				shift = room
+			}
+			if shift > 0 {
+				return shift
+			}
+		}
+	}
+	if hasEnd {
+		if limit := portEnd - endInset; pos+size > limit {
+			shift := limit - (pos + size)
+			if room := lo - pos; shift < room {
+				shift = room
+			}
+			if shift < 0 {
+				return shift
+			}
+		}
+	}
+	return 0
+}
+
+// stickyInset resolves a sticky inset, reporting whether it's set.
+func stickyInset(l Length, ctx *LayoutContext, fontSize float64) (float64, bool) {
+	if l.Unit == "" || l.Unit == AutoUnit {
+		return 0, false
+	}
+	return ResolveLength(l, ctx, fontSize), true
+}
+
+// contentBoxOf returns node's content box, relative to its Rect's origin.
+func contentBoxOf(node *Node, ctx *LayoutContext) Rect {
+	fontSize := getCurrentFontSize(node, ctx)
+	box := node.paddingBox(ctx)
+	left := ResolveLength(node.Style.Padding.Left, ctx, fontSize)
+	top := ResolveLength(node.Style.Padding.Top, ctx, fontSize)
+	right := ResolveLength(node.Style.Padding.Right, ctx, fontSize)
+	bottom := ResolveLength(node.Style.Padding.Bottom, ctx, fontSize)
+	return Rect{X: box.X + left, Y: box.Y + top, Width: box.Width - left - right, Height: box.Height - top - bottom}
+}
+
+// stickyArea returns the grid area of the sticky child at index i of a grid,
+// relative to the grid's Rect, and whether it has one.
+func (t *layoutTracks) stickyArea(i int) (Rect, bool) {
+	if t == nil {
+		return Rect{}, false
+	}
+	area, ok := t.stickyAreas[i]
+	return area, ok
+}
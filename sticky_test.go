@@ -0,0 +1,150 @@
+package layout
+
+import "testing"
+
+func TestUpdateSticky(t *testing.T) {
+	header := &Node{Style: Style{Position: PositionSticky, Top: Px(0), Height: Px(20)}}
+	footer := &Node{Style: Style{Position: PositionSticky, Bottom: Px(10), Height: Px(20)}}
+	root := &Node{
+		Style: Style{Width: Px(200), Height: Px(200)},
+		Children: []*Node{
+			{Style: Style{Height: Px(300)}, Children: []*Node{header, {Style: Style{Height: Px(280)}}}},
+			{Style: Style{Height: Px(300)}, Children: []*Node{{Style: Style{Height: Px(50)}}, footer}},
+		},
+	}
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutWithPositioning(root, Loose(200, 200), root.Rect, ctx)
+
+	// The footer is 350px down, below the 200px scrollport: it sticks 10px
+	// above its bottom, but not above the top of its section at 300
+	if footer.Rect.Y != 0 {
+		t.Errorf("Expected the footer at the top of its section, got %v", footer.Rect.Y)
+	}
+
+	tests := []struct {
+		scroll, header float64
+	}{
+		{0, 0},
+		{100, 100}, // Stuck to the top of the scrollport
+		{290, 280}, // Pushed up by the end of its section
+		{0, 0},
+	}
+	for _, tt := range tests {
+		UpdateSticky(root, 0, tt.scroll, ctx)
+		if header.Rect.Y != tt.header {
+			t.Errorf("At scroll %v: expected the header at %v, got %v", tt.scroll, tt.header, header.Rect.Y)
+		}
+		if got := header.StickyOffset(); got.Y != tt.header {
+			t.Errorf("At scroll %v: expected a sticky offset of %v, got %v", tt.scroll, tt.header, got.Y)
+		}
+	}
+
+	// Scrolled to the footer's flow position, it's back in flow
+	if !UpdateSticky(root, 0, 200, ctx) || footer.Rect.Y != 50 {
+		t.Errorf("Expected the footer to move back to 50, got %v", footer.Rect.Y)
+	}
+
+	// A relayout resets the flow positions
+	Layout(root, Loose(200, 200), ctx)
+	UpdateSticky(root, 0, 100, ctx)
+	if header.Rect.Y != 100 {
+		t.Errorf("Expected the header at 100 after relayout, got %v", header.Rect.Y)
+	}
+}
+
+func TestUpdateStickyGridItem(t *testing.T) {
+	// A sticky grid item is contained by its grid area, not the grid
+	item := &Node{Style: Style{Position: PositionSticky, Top: Px(0), Height: Px(20)}}
+	grid := &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(100)), FixedTrack(Px(100))},
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(200))},
+			Padding:             Spacing{Top: Px(10)},
+		},
+		Children: []*Node{item},
+	}
+	root := &Node{Style: Style{Width: Px(200), Height: Px(100)}, Children: []*Node{grid}}
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutWithPositioning(root, Loose(200, 100), root.Rect, ctx)
+
+	tests := []struct {
+		scroll, y float64
+	}{
+		{0, 10},
+		{50, 50},  // Stuck to the top of the scrollport
+		{100, 90}, // Pushed up by the end of its area, at 110
+		{0, 10},
+	}
+	for _, tt := range tests {
+		UpdateSticky(root, 0, tt.scroll, ctx)
+		if item.Rect.Y != tt.y {
+			t.Errorf("At scroll %v: expected the item at %v, got %v", tt.scroll, tt.y, item.Rect.Y)
+		}
+	}
+}
+
+func TestUpdateStickyFlexItem(t *testing.T) {
+	// A sticky flex item is contained by its flex container, not its line
+	item := &Node{Style: Style{Position: PositionSticky, Top: Px(0), Width: Px(100), Height: Px(20)}}
+	flex := &Node{
+		Style: Style{
+			Display:      DisplayFlex,
+			FlexWrap:     FlexWrapWrap,
+			AlignContent: AlignContentFlexStart,
+			Width:        Px(100),
+			Height:       Px(300),
+		},
+		Children: []*Node{item, {Style: Style{Width: Px(100), Height: Px(50)}}},
+	}
+	root := &Node{Style: Style{Width: Px(100), Height: Px(100)}, Children: []*Node{flex}}
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutWithPositioning(root, Loose(100, 100), root.Rect, ctx)
+	if got := flex.Children[1].Rect.Y; got != 20 {
+		t.Fatalf("Expected two flex lines, got the second item at %v", got)
+	}
+
+	tests := []struct {
+		scroll, y float64
+	}{
+		{0, 0},
+		{100, 100}, // Past its line
+		{290, 280}, // Pushed up by the end of the container
+	}
+	for _, tt := range tests {
+		UpdateSticky(root, 0, tt.scroll, ctx)
+		if item.Rect.Y != tt.y {
+			t.Errorf("At scroll %v: expected the item at %v, got %v", tt.scroll, tt.y, item.Rect.Y)
+		}
+	}
+}
+
+func TestUpdateStickyNestedScroller(t *testing.T) {
+	// A sticky box sticks to its nearest scroll container, at its Scroll
+	header := &Node{Style: Style{Position: PositionSticky, Top: Px(0), Height: Px(20)}}
+	pane := &Node{
+		Style:    Style{Height: Px(100), Overflow: OverflowScroll},
+		Children: []*Node{header, {Style: Style{Height: Px(300)}}},
+	}
+	root := &Node{Style: Style{Width: Px(200), Height: Px(200)}, Children: []*Node{{Style: Style{Height: Px(50)}}, pane}}
+	ctx := NewLayoutContext(800, 600, 16)
+	LayoutWithPositioning(root, Loose(200, 200), root.Rect, ctx)
+
+	// Scrolling the root doesn't move it
+	UpdateSticky(root, 0, 100, ctx)
+	if header.Rect.Y != 0 {
+		t.Errorf("Expected the header to ignore the root's scroll, got %v", header.Rect.Y)
+	}
+
+	// Scrolling the pane does, whichever container is updated
+	pane.ScrollTo(0, 60, ctx)
+	UpdateSticky(root, 0, 0, ctx)
+	if header.Rect.Y != 60 {
+		t.Errorf("Expected the header stuck at 60, got %v", header.Rect.Y)
+	}
+	pane.ScrollTo(0, 150, ctx)
+	UpdateSticky(pane, pane.Scroll.X, pane.Scroll.Y, ctx)
+	if header.Rect.Y != 150 {
+		t.Errorf("Expected the header stuck at 150, got %v", header.Rect.Y)
+	}
+}
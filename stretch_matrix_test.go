@@ -0,0 +1,137 @@
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+// The stretch behavior matrix (CSS Box Alignment Level 3 §6, CSS Flexbox §9.4):
+//
+//	                      auto cross size   explicit cross size   aspect-ratio, auto size
+//	flex  stretch/normal  stretched         kept, start-aligned   stretched, main re-derived
+//	grid  normal (zero)   stretched         kept, start-aligned   start-aligned, not stretched
+//	grid  explicit        stretched         kept, start-aligned   stretched, other axis re-derived
+//
+// See: https://www.w3.org/TR/css-align-3/#stretch-alignment
+
+func stretchFlexRow(children ...*Node) *Node {
+	return &Node{
+		Style: Style{
+			Display:       DisplayFlex,
+			FlexDirection: FlexDirectionRow,
+			Width:         Px(600),
+			Height:        Px(100),
+		},
+		Children: children,
+	}
+}
+
+func assertRect(t *testing.T, name string, got Rect, x, y, w, h float64) {
+	t.Helper()
+	if math.Abs(got.X-x) > 0.5 || math.Abs(got.Y-y) > 0.5 || math.Abs(got.Width-w) > 0.5 || math.Abs(got.Height-h) > 0.5 {
+		t.Errorf("%s: expected {X:%.1f Y:%.1f W:%.1f H:%.1f}, got {X:%.1f Y:%.1f W:%.1f H:%.1f}",
+			name, x, y, w, h, got.X, got.Y, got.Width, got.Height)
+	}
+}
+
+func TestFlexStretchAutoCrossSize(t *testing.T) {
+	root := stretchFlexRow(&Node{Style: Style{Width: Px(50)}})
+	LayoutFlexbox(root, Loose(600, 100), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "auto height", root.Children[0].Rect, 0, 0, 50, 100)
+}
+
+func TestFlexStretchKeepsExplicitCrossSize(t *testing.T) {
+	root := stretchFlexRow(
+		&Node{Style: Style{Width: Px(50), Height: Px(40)}},
+		&Node{Style: Style{Width: Px(50), Height: Px(40), AlignSelf: AlignItemsStretchExplicit}},
+	)
+	LayoutFlexbox(root, Loose(600, 100), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "explicit height", root.Children[0].Rect, 0, 0, 50, 40)
+	assertRect(t, "explicit height, explicit stretch", root.Children[1].Rect, 50, 0, 50, 40)
+}
+
+func TestFlexStretchKeepsExplicitCrossSizeColumn(t *testing.T) {
+	root := &Node{
+		Style: Style{
+			Display:       DisplayFlex,
+			FlexDirection: FlexDirectionColumn,
+			Width:         Px(300),
+			Height:        Px(200),
+		},
+		Children: []*Node{
+			{Style: Style{Height: Px(50)}},
+			{Style: Style{Width: Px(120), Height: Px(50)}},
+		},
+	}
+	LayoutFlexbox(root, Loose(300, 200), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "auto width", root.Children[0].Rect, 0, 0, 300, 50)
+	assertRect(t, "explicit width", root.Children[1].Rect, 0, 50, 120, 50)
+}
+
+func TestFlexStretchClampedByMaxCrossSize(t *testing.T) {
+	root := stretchFlexRow(&Node{Style: Style{Width: Px(50), MaxHeight: Px(60)}})
+	LayoutFlexbox(root, Loose(600, 100), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "max-height", root.Children[0].Rect, 0, 0, 50, 60)
+}
+
+func TestFlexStretchAspectRatioRederivesMainSize(t *testing.T) {
+	root := stretchFlexRow(
+		&Node{Style: Style{AspectRatio: 2}},
+		&Node{Style: Style{Width: Px(50)}},
+	)
+	LayoutFlexbox(root, Loose(600, 100), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "aspect-ratio item", root.Children[0].Rect, 0, 0, 200, 100)
+	assertRect(t, "following item", root.Children[1].Rect, 200, 0, 50, 100)
+}
+
+func TestFlexNonStretchAlignmentIgnoresCrossSize(t *testing.T) {
+	root := stretchFlexRow(&Node{Style: Style{Width: Px(50), Height: Px(40), AlignSelf: AlignItemsCenter}})
+	LayoutFlexbox(root, Loose(600, 100), NewLayoutContext(800, 600, 16))
+
+	assertRect(t, "centered", root.Children[0].Rect, 0, 30, 50, 40)
+}
+
+func stretchGrid(item *Node) *Node {
+	item.Style.GridRowStart, item.Style.GridRowEnd = 0, 1
+	item.Style.GridColumnStart, item.Style.GridColumnEnd = 0, 1
+	return &Node{
+		Style: Style{
+			Display:             DisplayGrid,
+			GridTemplateRows:    []GridTrack{FixedTrack(Px(100))},
+			GridTemplateColumns: []GridTrack{FixedTrack(Px(200))},
+		},
+		Children: []*Node{item},
+	}
+}
+
+func TestGridStretchMatrix(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16)
+
+	tests := []struct {
+		name       string
+		item       Style
+		x, y, w, h float64
+	}{
+		{"auto size stretches", Style{}, 0, 0, 200, 100},
+		{"explicit stretch on auto size", Style{AlignSelf: AlignItemsStretchExplicit, JustifySelf: JustifyItemsStretchExplicit}, 0, 0, 200, 100},
+		{"explicit height kept", Style{Height: Px(40)}, 0, 0, 200, 40},
+		{"explicit width kept", Style{Width: Px(80)}, 0, 0, 80, 100},
+		{"aspect-ratio normal is start", Style{AspectRatio: 1, Width: Px(50), Height: Px(-1)}, 0, 0, 50, 50},
+		{"aspect-ratio honors center", Style{AspectRatio: 1, Width: Px(50), Height: Px(-1), AlignSelf: AlignItemsCenter, JustifySelf: JustifyItemsCenter}, 75, 25, 50, 50},
+		{"aspect-ratio explicit justify stretch", Style{AspectRatio: 4, JustifySelf: JustifyItemsStretchExplicit}, 0, 0, 200, 50},
+		{"aspect-ratio explicit align stretch", Style{AspectRatio: 0.5, AlignSelf: AlignItemsStretchExplicit}, 0, 0, 50, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := stretchGrid(&Node{Style: tt.item})
+			LayoutGrid(root, Loose(800, 600), ctx)
+			assertRect(t, tt.name, root.Children[0].Rect, tt.x, tt.y, tt.w, tt.h)
+		})
+	}
+}
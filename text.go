@@ -1,9 +1,11 @@
 package layout
 
 import (
+	"math"
 	"strings"
 	"sync/atomic"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/SCKelemen/unicode/v6/uax50"
 )
@@ -19,6 +21,16 @@ type TextMetricsProvider interface {
 	Measure(text string, style TextStyle) (advance, ascent, descent float64)
 }
 
+// XHeightProvider is an optional interface for a TextMetricsProvider that
+// knows the x-height of its font, used to resolve ex units. Without it an
+// ex is 0.5em.
+//
+// See: https://www.w3.org/TR/css-values-4/#ex
+type XHeightProvider interface {
+	// XHeight returns the height of a lowercase "x" in the given style.
+	XHeight(style TextStyle) float64
+}
+
 // Default approximate metrics (v1 fallback)
 type approxMetrics struct{}
 
@@ -120,9 +132,10 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		// If you need mixed content, use a block container with text and block children.
 	}
 
-	if node.Style.TextStyle == nil {
-		// Default TextStyle if not set
-		node.Style.TextStyle = &TextStyle{
+	style := computedTextStyle(node, ctx)
+	if style == nil {
+		// Default TextStyle if neither the node nor an ancestor has one
+		style = &TextStyle{
 			FontSize:   16,
 			TextAlign:  TextAlignDefault,
 			LineHeight: 0, // normal
@@ -130,7 +143,6 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 			Direction:  DirectionLTR,
 		}
 	}
-	style := node.Style.TextStyle
 
 	// Get writing mode - prefer Style.WritingMode (inherited), fall back to TextStyle.WritingMode (legacy)
 	writingMode := node.Style.WritingMode
@@ -140,10 +152,7 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	}
 
 	// Get current font size for em unit resolution
-	currentFontSize := 16.0 // Default
-	if style.FontSize > 0 {
-		currentFontSize = style.FontSize
-	}
+	currentFontSize := getCurrentFontSize(node, ctx)
 
 	// 1. Determine available content size from constraints and Style (box sizing)
 	// In vertical modes, we work with inline dimension (height) instead of width
@@ -174,6 +183,7 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 
 	// 2. Expand tabs based on tab-size (§3.1.1) - BEFORE whitespace processing
 	// Only expand tabs for normal and nowrap modes; pre modes preserve tabs
+	// and measure them to the next tab stop during line breaking
 	processedText := node.Text
 	if style.WhiteSpace == WhiteSpaceNormal || style.WhiteSpace == WhiteSpaceNowrap {
 		processedText = expandTabs(processedText, style.TabSize)
@@ -185,21 +195,65 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	// 2.6. Apply text-transform (§6)
 	processedText = applyTextTransform(processedText, style.TextTransform)
 
-	// 3. Perform line breaking (§4) with getTextMetrics().Measure
-	lines := breakIntoLines(processedText, contentWidth, *style)
+	// 2.7. Soft hyphens are invisible; where they can be break opportunities,
+	// the UAX #14 line breaker keeps them to break at (§5.1)
+	if style.Hyphens == HyphensNone || style.WhiteSpace == WhiteSpacePre || style.WhiteSpace == WhiteSpacePreWrap {
+		processedText = stripSoftHyphens(processedText)
+	}
+
+	// 2.8. Style the first line (::first-line) and set a drop cap's letter
+	// aside (::first-letter); the first lines are inset by the drop cap
+	firstLine := firstLineStyle(*style, node.Style.FirstLine)
+	first := style
+	if firstLine != nil {
+		first = firstLine
+	}
+	var dropCap *DropCap
+	var insets []float64
+	if node.Style.FirstLetter != nil && !writingMode.IsVertical() {
+		if letter, rest := splitFirstLetter(processedText); letter != "" {
+			dropCap, insets = layoutDropCap(letter, node.Style.FirstLetter, *style, *first, ctx)
+			processedText = rest
+		}
+	}
+
+	// 3. Perform line breaking (§4) with measureText
+	lines := breakLeadingLines(processedText, contentWidth, *style, firstLine, insets, ctx)
 
 	// 3.5. Apply text-overflow if needed (ellipsis truncation)
 	// CSS Text Overflow Module Level 3: https://www.w3.org/TR/css-overflow-3/#text-overflow
 	if style.TextOverflow == TextOverflowEllipsis {
-		lines = applyTextOverflow(lines, contentWidth, *style)
+		lines = applyTextOverflow(lines, contentWidth, *style, ctx)
 	}
 
 	// 4. Compute per-line positions (x,y) based on text-align (§7.1), text-align-last (§7.2.2), text-justify (§7.3), text-indent (§7.2.1), direction (§2), and writing-mode
 	lineHeight := resolveLineHeight(style.LineHeight, style.FontSize)
-	positionLines(lines, contentWidth, style.TextAlign, style.TextAlignLast, style.TextJustify, style.TextIndent, style.Direction, lineHeight, writingMode)
+	firstLineHeight := resolveLineHeight(first.LineHeight, first.FontSize)
+	// Hanging punctuation (§9.2) is outside the lines as they're aligned
+	hangs := hangPunctuation(lines, style.HangingPunctuation, *style, *first, ctx, func(i int) float64 {
+		available := contentWidth
+		if i == 0 {
+			available -= style.TextIndent
+		}
+		if i < len(insets) {
+			available -= insets[i]
+		}
+		return available
+	})
+	positionLines(lines, contentWidth, style.TextAlign, style.TextAlignLast, style.TextJustify, style.TextIndent, insets, style.Direction, lineHeight, writingMode)
+	shiftLinesAfterFirst(lines, firstLineHeight-lineHeight, writingMode)
 
-	// 4.5. Apply hanging-punctuation (§9.2)
-	applyHangingPunctuation(lines, style.HangingPunctuation, *style)
+	// 4.5. Hang the punctuation outside the aligned lines (§9.2)
+	unhangPunctuation(lines, hangs, style.Direction, writingMode)
+
+	// 4.6. Reorder each line's boxes into visual order (UAX #9)
+	reorderLines(lines, style.Direction)
+
+	// 4.7. Compute each line's baseline and decoration positions
+	computeLineMetrics(lines, lineHeight, *style, ctx)
+	if firstLine != nil && len(lines) > 0 {
+		computeLineMetrics(lines[:1], firstLineHeight, *firstLine, ctx)
+	}
 
 	// 5. Compute total height from line count and line-height (§4.4.1)
 	// If no lines, use at least one line height for empty text
@@ -207,7 +261,21 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	if numLines == 0 {
 		numLines = 1
 	}
-	contentHeight := float64(numLines) * lineHeight
+	contentHeight := firstLineHeight + float64(numLines-1)*lineHeight
+
+	// The drop cap sits on the baseline of the last line it spans, and the
+	// text is at least as tall as the drop cap
+	if dropCap != nil {
+		if n := len(insets) - 1; n < len(lines) {
+			ascent := dropCap.Baseline - dropCap.Rect.Y
+			dropCap.Baseline = lines[n].OffsetY + lines[n].Baseline
+			dropCap.Rect.Y = dropCap.Baseline - ascent
+		}
+		if style.Direction == DirectionRTL {
+			dropCap.Rect.X = contentWidth - dropCap.Rect.Width
+		}
+		contentHeight = math.Max(contentHeight, dropCap.Rect.Y+dropCap.Rect.Height)
+	}
 
 	// Find max line width (including text-indent for first line)
 	maxLineWidth := 0.0
@@ -217,6 +285,9 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 		if i == 0 && style.TextIndent != 0 {
 			w += style.TextIndent
 		}
+		if i < len(insets) {
+			w += insets[i]
+		}
 		if w > maxLineWidth {
 			maxLineWidth = w
 		}
@@ -281,7 +352,14 @@ func LayoutText(node *Node, constraints Constraints, ctx *LayoutContext) Size {
 	node.TextLayout = &TextLayout{
 		Lines:      lines,
 		LineHeight: lineHeight,
+		Style:      *style,
+		ContentX:   paddingLeft + borderLeft,
+		ContentY:   paddingTop + borderTop,
+	}
+	if firstLine != nil && *firstLine != *style {
+		node.TextLayout.FirstLineStyle = firstLine
 	}
+	node.TextLayout.DropCap = dropCap
 
 	return size
 }
@@ -492,63 +570,175 @@ func expandTabs(text string, tabSize float64) string {
 	return strings.ReplaceAll(text, "\t", replacement)
 }
 
-// isOpeningPunctuation checks if a rune is opening punctuation
-func isOpeningPunctuation(r rune) bool {
-	// Opening brackets, quotes, etc.
-	return r == '(' || r == '[' || r == '{' || r == '<' ||
-		r == '"' || r == '\'' || r == '\u201C' || r == '\u2018' || // Left double/single quotes
-		r == '\u00AB' || r == '\u2039' // Left guillemets
+// softHyphen (U+00AD) marks where a word may be hyphenated; hyphenText is
+// shown in its place when a line breaks there.
+const (
+	softHyphen = "\u00AD"
+	hyphenText = "-"
+)
+
+// stripSoftHyphens removes soft hyphens from text.
+func stripSoftHyphens(text string) string {
+	if !strings.Contains(text, softHyphen) {
+		return text
+	}
+	return strings.ReplaceAll(text, softHyphen, "")
 }
 
-// isClosingPunctuation checks if a rune is closing punctuation
-func isClosingPunctuation(r rune) bool {
-	// Closing brackets, quotes, periods, commas, etc.
-	return r == ')' || r == ']' || r == '}' || r == '>' ||
-		r == '"' || r == '\'' || r == '\u201D' || r == '\u2019' || // Right double/single quotes
-		r == '\u00BB' || r == '\u203A' || // Right guillemets
-		r == '.' || r == ',' || r == '!' || r == '?' || r == ';' || r == ':'
+// measureTabbed measures text that starts at inline position x of its line,
+// advancing each tab character to the next tab stop. Used in the white-space
+// modes that preserve tabs.
+// CSS Text Module Level 3 §3.1.1: https://www.w3.org/TR/css-text-3/#tab-size-property
+func measureTabbed(text string, x float64, style TextStyle, ctx *LayoutContext) (advance, ascent, descent float64) {
+	if !strings.Contains(text, "\t") {
+		return measureText(text, style, ctx)
+	}
+	pos := x
+	for i, piece := range strings.Split(text, "\t") {
+		if i > 0 {
+			pos = nextTabStop(pos, style, ctx)
+		}
+		if piece == "" {
+			continue
+		}
+		width, a, d := measureText(piece, style, ctx)
+		pos += width
+		if a > ascent {
+			ascent = a
+		}
+		if d > descent {
+			descent = d
+		}
+	}
+	if ascent == 0 && descent == 0 {
+		// Only tabs: take the line metrics from a space
+		_, ascent, descent = measureText(" ", style, ctx)
+	}
+	return pos - x, ascent, descent
 }
 
-// applyHangingPunctuation adjusts line boxes for hanging punctuation
-// CSS Text Module Level 3 §9.2: https://www.w3.org/TR/css-text-3/#hanging-punctuation-property
-func applyHangingPunctuation(lines []TextLine, hanging HangingPunctuation, style TextStyle) {
-	if hanging == HangingPunctuationNone {
-		return
+// nextTabStop returns the position of the first tab stop after inline
+// position x. Tab stops are tab-size spaces apart, counting letter and word
+// spacing; a stop closer than half a space is skipped. A tab-size of 0
+// disables tabs.
+func nextTabStop(x float64, style TextStyle, ctx *LayoutContext) float64 {
+	tabSize := style.TabSize
+	if tabSize < 0 {
+		tabSize = 8
+	}
+	space, _, _ := measureText(" ", style, ctx)
+	if style.LetterSpacing != -1 {
+		space += style.LetterSpacing
+	}
+	if style.WordSpacing != -1 {
+		space += style.WordSpacing
+	}
+	interval := tabSize * space
+	if interval <= 0 {
+		return x
 	}
+	stop := (math.Floor(x/interval) + 1) * interval
+	if stop-x < space/2 {
+		stop += interval
+	}
+	return stop
+}
 
+// isHangingOpen reports whether r is an opening bracket or quote, which
+// hangs at the start of the first line (hanging-punctuation: first).
+func isHangingOpen(r rune) bool {
+	return r == '"' || r == '\'' || unicode.In(r, unicode.Ps, unicode.Pi, unicode.Pf)
+}
+
+// isHangingClose reports whether r is a closing bracket or quote, which
+// hangs at the end of the last line (hanging-punctuation: last).
+func isHangingClose(r rune) bool {
+	return r == '"' || r == '\'' || unicode.In(r, unicode.Pe, unicode.Pi, unicode.Pf)
+}
+
+// isStopOrComma reports whether r is one of the stops and commas that hang
+// at the end of a line (hanging-punctuation: force-end and allow-end).
+func isStopOrComma(r rune) bool {
+	switch r {
+	case ',', '.', '\u060C', '\u06D4', '\u3001', '\u3002', '\uFF0C', '\uFF0E',
+		'\uFE50', '\uFE51', '\uFE52', '\uFF61', '\uFF64':
+		return true
+	}
+	return false
+}
+
+// endHangWidth returns the width of the stop or comma ending text, which
+// may hang past the end of its line with force-end or allow-end, or 0.
+func endHangWidth(text string, hanging HangingPunctuation, style TextStyle, ctx *LayoutContext) float64 {
+	if hanging&(HangingPunctuationForceEnd|HangingPunctuationAllowEnd) == 0 {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRuneInString(text)
+	if !isStopOrComma(r) {
+		return 0
+	}
+	width, _, _ := measureText(string(r), style, ctx)
+	return width
+}
+
+// hangPunctuation takes the punctuation that hangs outside the start and
+// end edges of each line out of its width, so that alignment and
+// justification ignore it, and returns the widths taken out for
+// unhangPunctuation. Line 0 is in first; available returns the inline
+// size line i is aligned in.
+// CSS Text Module Level 3 §9.2: https://www.w3.org/TR/css-text-3/#hanging-punctuation-property
+func hangPunctuation(lines []TextLine, hanging HangingPunctuation, style, first TextStyle, ctx *LayoutContext, available func(i int) float64) [][2]float64 {
+	if hanging == HangingPunctuationNone || len(lines) == 0 {
+		return nil
+	}
+	hangs := make([][2]float64, len(lines))
 	for i := range lines {
 		line := &lines[i]
 		if len(line.Boxes) == 0 {
 			continue
 		}
+		lineStyle := style
+		if i == 0 {
+			lineStyle = first
+		}
 
-		// Handle first punctuation (opening)
-		if hanging == HangingPunctuationFirst || hanging == HangingPunctuationAllowEnd {
-			firstBox := &line.Boxes[0]
-			if len(firstBox.Text) > 0 {
-				runes := []rune(firstBox.Text)
-				if isOpeningPunctuation(runes[0]) {
-					// Measure the punctuation character
-					punctWidth, _, _ := getTextMetrics().Measure(string(runes[0]), style)
-					// Hang it by moving line start position
-					line.OffsetX -= punctWidth
-					line.Width += punctWidth
-				}
-			}
+		var start, end float64
+		if r, _ := utf8.DecodeRuneInString(line.Boxes[0].Text); i == 0 && hanging&HangingPunctuationFirst != 0 && isHangingOpen(r) {
+			start, _, _ = measureText(string(r), lineStyle, ctx)
 		}
+		r, _ := utf8.DecodeLastRuneInString(line.Boxes[len(line.Boxes)-1].Text)
+		switch {
+		case i == len(lines)-1 && hanging&HangingPunctuationLast != 0 && isHangingClose(r):
+			end, _, _ = measureText(string(r), lineStyle, ctx)
+		case isStopOrComma(r) && hanging&HangingPunctuationForceEnd != 0:
+			end, _, _ = measureText(string(r), lineStyle, ctx)
+		case isStopOrComma(r) && hanging&HangingPunctuationAllowEnd != 0 && line.Width-start > available(i)+0.001:
+			// Only when the line doesn't fit otherwise
+			end, _, _ = measureText(string(r), lineStyle, ctx)
+		}
+		line.Width -= start + end
+		hangs[i] = [2]float64{start, end}
+	}
+	return hangs
+}
 
-		// Handle last punctuation (closing)
-		if hanging == HangingPunctuationLast || hanging == HangingPunctuationForceEnd || hanging == HangingPunctuationAllowEnd {
-			lastBox := &line.Boxes[len(line.Boxes)-1]
-			if len(lastBox.Text) > 0 {
-				runes := []rune(lastBox.Text)
-				if isClosingPunctuation(runes[len(runes)-1]) {
-					// Measure the punctuation character
-					punctWidth, _, _ := getTextMetrics().Measure(string(runes[len(runes)-1]), style)
-					// Hang it by extending line width beyond container
-					line.Width -= punctWidth
-				}
-			}
+// unhangPunctuation puts the hanging punctuation taken out by
+// hangPunctuation back into the positioned lines, outside their start and
+// end edges.
+func unhangPunctuation(lines []TextLine, hangs [][2]float64, direction Direction, writingMode WritingMode) {
+	for i, hang := range hangs {
+		line := &lines[i]
+		line.Width += hang[0] + hang[1]
+		// The line now starts before the aligned position: by the start hang,
+		// or by the end hang in RTL where the end is on the left
+		shift := hang[0]
+		if direction == DirectionRTL {
+			shift = hang[1]
+		}
+		if writingMode.IsVertical() {
+			line.OffsetY -= shift
+		} else {
+			line.OffsetX -= shift
 		}
 	}
 }
@@ -601,7 +791,7 @@ func splitIntoWords(text string) []string {
 // Note: TextLine.Width field represents the inline-size extent:
 //   - Horizontal: width in pixels
 //   - Vertical: height in pixels (how tall the "line" is when flowing top-to-bottom)
-func breakIntoLines(text string, maxInlineSize float64, style TextStyle) []TextLine {
+func breakIntoLines(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	if text == "" {
 		return []TextLine{}
 	}
@@ -613,21 +803,21 @@ func breakIntoLines(text string, maxInlineSize float64, style TextStyle) []TextL
 
 	// For pre mode, split on newlines first
 	if style.WhiteSpace == WhiteSpacePre {
-		return breakIntoLinesPre(text, maxInlineSize, style)
+		return breakIntoLinesPre(text, maxInlineSize, style, ctx)
 	}
 
 	// For pre-wrap and pre-line, split on newlines then wrap each segment
 	if style.WhiteSpace == WhiteSpacePreWrap || style.WhiteSpace == WhiteSpacePreLine {
-		return breakIntoLinesPreWrap(text, maxInlineSize, style)
+		return breakIntoLinesPreWrap(text, maxInlineSize, style, ctx)
 	}
 
 	// Use UAX #14 to find line break opportunities
-	return breakIntoLinesUAX14(text, maxInlineSize, style)
+	return breakIntoLinesUAX14(text, maxInlineSize, style, ctx)
 }
 
 // breakIntoLinesUAX14 breaks text into lines using UAX #14 line breaking algorithm.
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []TextLine {
+func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	// Find all line break opportunities using UAX #14, respecting hyphens property
 	breakPoints := findLineBreakOpportunitiesWithHyphens(text, style.Hyphens)
 	if len(breakPoints) < 2 {
@@ -642,6 +832,8 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 	}
 	currentWidth := 0.0
 	lastWordHadTrailingSpace := false // Track if last word had a trailing space
+	lastWordHadSoftHyphen := false    // Track if last word ended at a soft hyphen
+	hyphenWidth := 0.0
 
 	// First line gets text-indent
 	firstLineIndent := style.TextIndent
@@ -668,19 +860,27 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 		if hasTrailingSpace {
 			// Strip trailing space and measure it separately
 			wordText = segment[:len(segment)-1]
-			spaceWidth, _, _ = getTextMetrics().Measure(" ", style)
+			spaceWidth, _, _ = measureText(" ", style, ctx)
 			if style.WordSpacing != -1 {
 				spaceWidth += style.WordSpacing
 			}
 		}
 
+		// A segment ending in a soft hyphen is a piece of a word that may be
+		// hyphenated there (§5.1). Soft hyphens are otherwise invisible.
+		hasSoftHyphen := !hasTrailingSpace && strings.HasSuffix(wordText, softHyphen)
+		wordText = stripSoftHyphens(wordText)
+		if hasSoftHyphen && hyphenWidth == 0 {
+			hyphenWidth, _, _ = measureText(hyphenText, style, ctx)
+		}
+
 		// Skip if word is empty (segment was just a space)
 		if len(wordText) == 0 {
 			continue
 		}
 
 		// Measure the word (without trailing space)
-		wordWidth, ascent, descent := getTextMetrics().Measure(wordText, style)
+		wordWidth, ascent, descent := measureText(wordText, style, ctx)
 
 		// Check if we need to break BEFORE adding this word
 		effectiveLineWidth := currentWidth
@@ -693,6 +893,12 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 		if hasTrailingSpace {
 			effectiveLineWidth += spaceWidth
 		}
+		if hasSoftHyphen {
+			// The hyphen must fit if the line breaks after this piece
+			effectiveLineWidth += hyphenWidth
+		}
+		// A stop or comma ending the line may hang past its end (§9.2)
+		effectiveLineWidth -= endHangWidth(wordText, style.HangingPunctuation, style, ctx)
 
 		// Break if this word would exceed maxInlineSize (and we have content already on this line)
 		if maxInlineSize > 0 && maxInlineSize < Unbounded && effectiveLineWidth > maxInlineSize && len(current.Boxes) > 0 && canBreakBefore(style.WhiteSpace) {
@@ -703,6 +909,11 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 				current.Width = currentWidth - lastSpaceWidth
 				current.SpaceCount--
 				current.SpaceWidth -= lastSpaceWidth
+			} else if lastWordHadSoftHyphen {
+				// Breaking at a soft hyphen shows the hyphen
+				last := &current.Boxes[len(current.Boxes)-1]
+				*last = newInlineBox(last.Text+hyphenText, last.Width+hyphenWidth, last.Ascent, last.Descent, style, ctx)
+				current.Width = currentWidth + hyphenWidth
 			} else {
 				current.Width = currentWidth
 			}
@@ -715,6 +926,7 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 			}
 			currentWidth = 0.0
 			lastWordHadTrailingSpace = false
+			lastWordHadSoftHyphen = false
 			firstLineIndent = 0.0 // Only first line gets indent
 		}
 
@@ -724,7 +936,7 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 			if style.OverflowWrap == OverflowWrapBreakWord || style.OverflowWrap == OverflowWrapAnywhere ||
 				style.WordBreak == WordBreakBreakAll {
 				// Break word into smaller pieces
-				pieces := breakWordToFit(wordText, maxInlineSize, style)
+				pieces := breakWordToFit(wordText, maxInlineSize, style, ctx)
 				for j, piece := range pieces {
 					if j > 0 {
 						// Start new line for subsequent pieces
@@ -739,8 +951,8 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 						lastWordHadTrailingSpace = false
 					}
 
-					pieceWidth, ascent, descent := getTextMetrics().Measure(piece, style)
-					current.Boxes = append(current.Boxes, newInlineBox(piece, pieceWidth, ascent, descent, style.WritingMode))
+					pieceWidth, ascent, descent := measureText(piece, style, ctx)
+					current.Boxes = append(current.Boxes, newInlineBox(piece, pieceWidth, ascent, descent, style, ctx))
 					currentWidth += pieceWidth
 				}
 
@@ -753,15 +965,25 @@ func breakIntoLinesUAX14(text string, maxInlineSize float64, style TextStyle) []
 				} else {
 					lastWordHadTrailingSpace = false
 				}
+				lastWordHadSoftHyphen = hasSoftHyphen
 
 				continue // Skip normal word addition
 			}
 		}
 
-		// Add the word to current line
-		box := newInlineBox(wordText, wordWidth, ascent, descent, style.WritingMode)
-		current.Boxes = append(current.Boxes, box)
-		currentWidth += wordWidth
+		if lastWordHadSoftHyphen && len(current.Boxes) > 0 {
+			// No break at the soft hyphen: continue the word in its box
+			last := &current.Boxes[len(current.Boxes)-1]
+			joinedWidth, joinedAscent, joinedDescent := measureText(last.Text+wordText, style, ctx)
+			currentWidth += joinedWidth - last.Width
+			*last = newInlineBox(last.Text+wordText, joinedWidth, joinedAscent, joinedDescent, style, ctx)
+		} else {
+			// Add the word to current line
+			box := newInlineBox(wordText, wordWidth, ascent, descent, style, ctx)
+			current.Boxes = append(current.Boxes, box)
+			currentWidth += wordWidth
+		}
+		lastWordHadSoftHyphen = hasSoftHyphen
 
 		// Track space after this word (if it has one)
 		if hasTrailingSpace {
@@ -805,7 +1027,7 @@ func canBreakBefore(whiteSpace WhiteSpace) bool {
 
 // breakIntoLinesPre breaks text into lines preserving newlines and spaces (pre mode).
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func breakIntoLinesPre(text string, maxInlineSize float64, style TextStyle) []TextLine {
+func breakIntoLinesPre(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	lines := []TextLine{}
 
 	// Split by newlines
@@ -815,8 +1037,8 @@ func breakIntoLinesPre(text string, maxInlineSize float64, style TextStyle) []Te
 
 		// Measure the entire line text (preserving all spaces)
 		// Text-indent affects alignment, not intrinsic width, so handle in positionLines()
-		advance, ascent, descent := getTextMetrics().Measure(lineText, style)
-		line.Boxes = append(line.Boxes, newInlineBox(lineText, advance, ascent, descent, style.WritingMode))
+		advance, ascent, descent := measureTabbed(lineText, 0, style, ctx)
+		line.Boxes = append(line.Boxes, newInlineBox(lineText, advance, ascent, descent, style, ctx))
 		line.Width = advance
 		lines = append(lines, line)
 	}
@@ -827,7 +1049,7 @@ func breakIntoLinesPre(text string, maxInlineSize float64, style TextStyle) []Te
 // breakIntoLinesPreWrap handles pre-wrap and pre-line modes.
 // Split on newlines, then wrap each segment.
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func breakIntoLinesPreWrap(text string, maxInlineSize float64, style TextStyle) []TextLine {
+func breakIntoLinesPreWrap(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	lines := []TextLine{}
 
 	// Split by newlines
@@ -846,7 +1068,7 @@ func breakIntoLinesPreWrap(text string, maxInlineSize float64, style TextStyle)
 		// Wrap this segment if it exceeds maxInlineSize
 		// For pre-wrap: preserve spaces within the segment
 		// For pre-line: spaces already collapsed in preprocessText
-		segmentLines := wrapSegment(segment, maxInlineSize, style)
+		segmentLines := wrapSegment(segment, maxInlineSize, style, ctx)
 		lines = append(lines, segmentLines...)
 	}
 
@@ -855,13 +1077,15 @@ func breakIntoLinesPreWrap(text string, maxInlineSize float64, style TextStyle)
 
 // wrapSegment wraps a single segment (between newlines) with preserved spaces.
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func wrapSegment(segment string, maxInlineSize float64, style TextStyle) []TextLine {
+func wrapSegment(segment string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	// If unlimited inline size or segment fits, return as single line
-	segmentWidth, ascent, descent := getTextMetrics().Measure(segment, style)
+	// (soft hyphens are only kept for pre-line, which breaks at them)
+	visible := stripSoftHyphens(segment)
+	segmentWidth, ascent, descent := measureTabbed(visible, 0, style, ctx)
 
 	if maxInlineSize >= Unbounded || segmentWidth <= maxInlineSize {
 		return []TextLine{{
-			Boxes: []InlineBox{newInlineBox(segment, segmentWidth, ascent, descent, style.WritingMode)},
+			Boxes: []InlineBox{newInlineBox(visible, segmentWidth, ascent, descent, style, ctx)},
 			Width: segmentWidth,
 		}}
 	}
@@ -869,16 +1093,16 @@ func wrapSegment(segment string, maxInlineSize float64, style TextStyle) []TextL
 	// Need to wrap
 	// For pre-wrap mode, preserve all spaces including multiple consecutive ones
 	if style.WhiteSpace == WhiteSpacePreWrap {
-		return wrapSegmentPreserveSpaces(segment, maxInlineSize, style)
+		return wrapSegmentPreserveSpaces(segment, maxInlineSize, style, ctx)
 	}
 
 	// For pre-line, use UAX #14 (spaces already collapsed in preprocessText)
-	return breakIntoLinesUAX14(segment, maxInlineSize, style)
+	return breakIntoLinesUAX14(segment, maxInlineSize, style, ctx)
 }
 
 // wrapSegmentPreserveSpaces wraps text while preserving all spaces (for pre-wrap mode).
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style TextStyle) []TextLine {
+func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	lines := []TextLine{}
 	current := TextLine{Boxes: []InlineBox{}}
 	currentWidth := 0.0
@@ -889,17 +1113,17 @@ func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style Text
 	wordStart := 0
 
 	for i := 0; i < len(runes); i++ {
-		// Find next space or end
-		if runes[i] == ' ' || i == len(runes)-1 {
+		// Find next space, tab or end
+		if runes[i] == ' ' || runes[i] == '\t' || i == len(runes)-1 {
 			// Extract word (include trailing char if at end and not space)
 			wordEnd := i
-			if i == len(runes)-1 && runes[i] != ' ' {
+			if i == len(runes)-1 && runes[i] != ' ' && runes[i] != '\t' {
 				wordEnd = i + 1
 			}
 
 			if wordEnd > wordStart {
 				word := string(runes[wordStart:wordEnd])
-				wordWidth, ascent, descent := getTextMetrics().Measure(word, style)
+				wordWidth, ascent, descent := measureText(word, style, ctx)
 
 				// Check if adding this word would exceed maxInlineSize
 				if currentWidth > 0 && currentWidth+wordWidth > maxInlineSize {
@@ -911,13 +1135,13 @@ func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style Text
 				}
 
 				// Add word to current line
-				current.Boxes = append(current.Boxes, newInlineBox(word, wordWidth, ascent, descent, style.WritingMode))
+				current.Boxes = append(current.Boxes, newInlineBox(word, wordWidth, ascent, descent, style, ctx))
 				currentWidth += wordWidth
 			}
 
 			// If current char is a space, add it
 			if runes[i] == ' ' {
-				spaceWidth, ascent, descent := getTextMetrics().Measure(" ", style)
+				spaceWidth, ascent, descent := measureText(" ", style, ctx)
 
 				// Check if space fits on current line
 				if currentWidth+spaceWidth > maxInlineSize && currentWidth > 0 {
@@ -929,10 +1153,24 @@ func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style Text
 				}
 
 				// Add space
-				current.Boxes = append(current.Boxes, newInlineBox(" ", spaceWidth, ascent, descent, style.WritingMode))
+				current.Boxes = append(current.Boxes, newInlineBox(" ", spaceWidth, ascent, descent, style, ctx))
 				currentWidth += spaceWidth
 			}
 
+			// A tab advances to the next tab stop (§3.1.1)
+			if runes[i] == '\t' {
+				tabWidth, ascent, descent := measureTabbed("\t", currentWidth, style, ctx)
+				if currentWidth+tabWidth > maxInlineSize && currentWidth > 0 {
+					current.Width = currentWidth
+					lines = append(lines, current)
+					current = TextLine{Boxes: []InlineBox{}}
+					currentWidth = 0.0
+					tabWidth, ascent, descent = measureTabbed("\t", 0, style, ctx)
+				}
+				current.Boxes = append(current.Boxes, newInlineBox("\t", tabWidth, ascent, descent, style, ctx))
+				currentWidth += tabWidth
+			}
+
 			wordStart = i + 1
 		}
 	}
@@ -949,7 +1187,7 @@ func wrapSegmentPreserveSpaces(segment string, maxInlineSize float64, style Text
 // breakWordToFit breaks a word into pieces that fit maxInlineSize.
 // Used for overflow-wrap: break-word and word-break: break-all.
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func breakWordToFit(word string, maxInlineSize float64, style TextStyle) []string {
+func breakWordToFit(word string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) []string {
 	pieces := []string{}
 	runes := []rune(word)
 
@@ -958,7 +1196,7 @@ func breakWordToFit(word string, maxInlineSize float64, style TextStyle) []strin
 
 	for _, r := range runes {
 		charStr := string(r)
-		charWidth, _, _ := getTextMetrics().Measure(charStr, style)
+		charWidth, _, _ := measureText(charStr, style, ctx)
 
 		if currentWidth+charWidth > maxInlineSize && currentPiece.Len() > 0 {
 			// Finish current piece
@@ -980,14 +1218,14 @@ func breakWordToFit(word string, maxInlineSize float64, style TextStyle) []strin
 
 // applyTextOverflow applies text-overflow: ellipsis to overflowing lines
 // CSS Text Overflow Module Level 3: https://www.w3.org/TR/css-overflow-3/#text-overflow
-func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle) []TextLine {
+func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle, ctx *LayoutContext) []TextLine {
 	if len(lines) == 0 {
 		return lines
 	}
 
 	// Measure ellipsis width
 	ellipsisText := "..."
-	ellipsisWidth, ellipsisAscent, ellipsisDescent := getTextMetrics().Measure(ellipsisText, style)
+	ellipsisWidth, ellipsisAscent, ellipsisDescent := measureText(ellipsisText, style, ctx)
 
 	// Process each line that overflows
 	for i := range lines {
@@ -1002,7 +1240,7 @@ func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle)
 		availableWidth := contentWidth - ellipsisWidth
 		if availableWidth <= 0 {
 			// Not enough space even for ellipsis - just show ellipsis
-			line.Boxes = []InlineBox{newInlineBox(ellipsisText, ellipsisWidth, ellipsisAscent, ellipsisDescent, style.WritingMode)}
+			line.Boxes = []InlineBox{newInlineBox(ellipsisText, ellipsisWidth, ellipsisAscent, ellipsisDescent, style, ctx)}
 			line.Width = ellipsisWidth
 			line.SpaceCount = 0
 			line.SpaceWidth = 0
@@ -1024,10 +1262,10 @@ func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle)
 				remainingWidth := availableWidth - currentWidth
 				if remainingWidth > 0 {
 					// Try to fit part of this box
-					truncatedText := truncateTextToWidth(box.Text, remainingWidth, style)
+					truncatedText := truncateTextToWidth(box.Text, remainingWidth, style, ctx)
 					if truncatedText != "" {
-						truncWidth, truncAscent, truncDesc := getTextMetrics().Measure(truncatedText, style)
-						truncatedBoxes = append(truncatedBoxes, newInlineBox(truncatedText, truncWidth, truncAscent, truncDesc, style.WritingMode))
+						truncWidth, truncAscent, truncDesc := measureText(truncatedText, style, ctx)
+						truncatedBoxes = append(truncatedBoxes, newInlineBox(truncatedText, truncWidth, truncAscent, truncDesc, style, ctx))
 						currentWidth += truncWidth
 					}
 				}
@@ -1036,7 +1274,7 @@ func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle)
 		}
 
 		// Add ellipsis
-		truncatedBoxes = append(truncatedBoxes, newInlineBox(ellipsisText, ellipsisWidth, ellipsisAscent, ellipsisDescent, style.WritingMode))
+		truncatedBoxes = append(truncatedBoxes, newInlineBox(ellipsisText, ellipsisWidth, ellipsisAscent, ellipsisDescent, style, ctx))
 
 		line.Boxes = truncatedBoxes
 		line.Width = currentWidth + ellipsisWidth
@@ -1050,7 +1288,7 @@ func applyTextOverflow(lines []TextLine, contentWidth float64, style TextStyle)
 
 // truncateTextToWidth truncates text to fit within maxInlineSize.
 // maxInlineSize represents the maximum extent in the inline dimension (width for horizontal, height for vertical).
-func truncateTextToWidth(text string, maxInlineSize float64, style TextStyle) string {
+func truncateTextToWidth(text string, maxInlineSize float64, style TextStyle, ctx *LayoutContext) string {
 	runes := []rune(text)
 
 	// Binary search for the longest prefix that fits
@@ -1060,7 +1298,7 @@ func truncateTextToWidth(text string, maxInlineSize float64, style TextStyle) st
 	for left <= right {
 		mid := (left + right) / 2
 		candidate := string(runes[:mid])
-		width, _, _ := getTextMetrics().Measure(candidate, style)
+		width, _, _ := measureText(candidate, style, ctx)
 
 		if width <= maxInlineSize {
 			result = candidate
@@ -1075,20 +1313,32 @@ func truncateTextToWidth(text string, maxInlineSize float64, style TextStyle) st
 
 // resolveTextAlignLast resolves text-align-last auto to actual alignment
 // CSS Text Module Level 3 §7.2.2: https://www.w3.org/TR/css-text-3/#text-align-last-property
-func resolveTextAlignLast(last TextAlignLast, textAlign TextAlign) TextAlignLast {
-	if last != TextAlignLastAuto {
-		return last
-	}
-
-	// Auto follows text-align, but never justify for last line
-	switch textAlign {
-	case TextAlignRight:
-		return TextAlignLastRight
-	case TextAlignCenter:
-		return TextAlignLastCenter
-	default:
+//
+// textAlign is already resolved to the line's logical space (see
+// positionLines), where left is the start edge; physical left and right
+// swap when rtl is set.
+func resolveTextAlignLast(last TextAlignLast, textAlign TextAlign, rtl bool) TextAlignLast {
+	switch last {
+	case TextAlignLastAuto:
+		// Auto follows text-align, but never justify for last line
+		switch textAlign {
+		case TextAlignRight:
+			return TextAlignLastRight
+		case TextAlignCenter:
+			return TextAlignLastCenter
+		default:
+			return TextAlignLastLeft
+		}
+	case TextAlignLastStart:
 		return TextAlignLastLeft
+	case TextAlignLastEnd:
+		return TextAlignLastRight
+	case TextAlignLastLeft, TextAlignLastRight:
+		if rtl {
+			return TextAlignLastLeft + TextAlignLastRight - last
+		}
 	}
+	return last
 }
 
 // positionLines positions lines based on text-align, text-align-last, text-justify, and text-indent.
@@ -1098,25 +1348,21 @@ func resolveTextAlignLast(last TextAlignLast, textAlign TextAlign) TextAlignLast
 //   - Horizontal: lines stack vertically (Y increases), alignment is horizontal (X)
 //   - Vertical-LR: lines stack left-to-right (X increases), alignment is vertical (Y)
 //   - Vertical-RL: lines stack right-to-left (X decreases), alignment is vertical (Y)
-func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAlign, textAlignLast TextAlignLast, textJustify TextJustify, textIndent float64, direction Direction, lineHeight float64, writingMode WritingMode) {
-	// Resolve TextAlignDefault based on direction
+func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAlign, textAlignLast TextAlignLast, textJustify TextJustify, textIndent float64, insets []float64, direction Direction, lineHeight float64, writingMode WritingMode) {
+	// Lines are aligned in their logical space, where the start edge
+	// (right in RTL) is on the left, and RTL lines are mirrored once
+	// positioned. start and end are logical (§7.1), while left and right
+	// are physical, so they swap in RTL.
+	rtl := direction == DirectionRTL
 	align := textAlign
-	wasDefault := (align == TextAlignDefault)
-
-	if wasDefault {
-		if direction == DirectionRTL {
-			align = TextAlignRight // RTL defaults to right
-		} else {
-			align = TextAlignLeft // LTR defaults to left
-		}
-	}
-
-	// For RTL with explicit left/right (not default), swap alignments
-	if direction == DirectionRTL && !wasDefault {
-		if align == TextAlignLeft {
-			align = TextAlignRight
-		} else if align == TextAlignRight {
-			align = TextAlignLeft
+	switch align {
+	case TextAlignDefault, TextAlignStart:
+		align = TextAlignLeft
+	case TextAlignEnd:
+		align = TextAlignRight
+	case TextAlignLeft, TextAlignRight:
+		if rtl {
+			align = TextAlignLeft + TextAlignRight - align
 		}
 	}
 
@@ -1145,6 +1391,10 @@ func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAl
 		if i == 0 && textIndent != 0 {
 			indent = textIndent
 		}
+		// Lines beside a drop cap are inset by it
+		if i < len(insets) {
+			indent += insets[i]
+		}
 
 		// Calculate inline-axis offset based on text-align
 		// Horizontal mode: inline-axis is X
@@ -1187,7 +1437,7 @@ func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAl
 			} else if !isLastLine && hasMultipleWords {
 				// Middle lines: apply justification algorithm
 				availableSize := contentInlineSize
-				if i == 0 && indent != 0 {
+				if indent != 0 {
 					availableSize -= indent
 				}
 
@@ -1234,19 +1484,19 @@ func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAl
 				inlineOffset = indent
 			} else {
 				// Last line or single word: use text-align-last
-				lastAlign := resolveTextAlignLast(textAlignLast, align)
+				lastAlign := resolveTextAlignLast(textAlignLast, align, rtl)
 
 				switch lastAlign {
 				case TextAlignLastLeft:
 					inlineOffset = indent
 				case TextAlignLastRight:
 					inlineOffset = contentInlineSize - lineWidth
-					if i == 0 {
+					if indent != 0 {
 						inlineOffset -= indent
 					}
 				case TextAlignLastCenter:
 					availableSize := contentInlineSize
-					if i == 0 {
+					if indent != 0 {
 						availableSize -= indent
 					}
 					inlineOffset = indent + (availableSize-lineWidth)/2
@@ -1254,7 +1504,7 @@ func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAl
 					// Justify even last line
 					if hasMultipleWords {
 						availableSize := contentInlineSize
-						if i == 0 && indent != 0 {
+						if indent != 0 {
 							availableSize -= indent
 						}
 						extraSpace := availableSize - lineWidth
@@ -1272,6 +1522,9 @@ func positionLines(lines []TextLine, contentInlineSize float64, textAlign TextAl
 		default:
 			inlineOffset = 0.0
 		}
+		if rtl {
+			inlineOffset = contentInlineSize - inlineOffset - line.Width
+		}
 
 		// Map logical offsets to physical X/Y based on writing mode
 		if isVertical {
@@ -1317,35 +1570,22 @@ func resolveLineHeight(lineHeight float64, fontSize float64) float64 {
 }
 
 // Text creates a new text node with the given text and optional style.
-// The node will have DisplayInlineText set automatically.
+// The node will have DisplayInlineText set automatically. Without a
+// TextStyle, the node inherits its text style from its ancestors.
 func Text(text string, style ...Style) *Node {
-	baseStyle := Style{
-		Display: DisplayInlineText,
-		Width:   Px(0), // auto (Px(0) is treated as auto when resolved)
-		Height:  Px(0), // auto
-		TextStyle: &TextStyle{
-			FontSize:   16,
-			TextAlign:  TextAlignDefault,
-			LineHeight: 0, // normal
-			WhiteSpace: WhiteSpaceNormal,
-			Direction:  DirectionLTR,
-		},
-	}
-
 	node := &Node{
-		Text:  text,
-		Style: baseStyle,
+		Text: text,
+		Style: Style{
+			Width:  Px(0), // auto (Px(0) is treated as auto when resolved)
+			Height: Px(0), // auto
+		},
 	}
 
 	// Merge provided style if any
 	if len(style) > 0 {
 		node.Style = style[0]
-		node.Style.Display = DisplayInlineText
-		// Ensure TextStyle is set
-		if node.Style.TextStyle == nil {
-			node.Style.TextStyle = baseStyle.TextStyle
-		}
 	}
+	node.Style.Display = DisplayInlineText
 
 	return node
 }
@@ -1458,15 +1698,17 @@ func computeTextOrientations(text string, wm WritingMode) []bool {
 	return orientations
 }
 
-// newInlineBox creates an InlineBox with character orientation data populated.
+// newInlineBox creates an InlineBox with character orientation and font
+// fallback data populated.
 // This helper ensures consistent InlineBox creation throughout the text layout code.
-func newInlineBox(text string, width, ascent, descent float64, wm WritingMode) InlineBox {
+func newInlineBox(text string, width, ascent, descent float64, style TextStyle, ctx *LayoutContext) InlineBox {
 	return InlineBox{
 		Kind:         InlineBoxText,
 		Text:         text,
 		Width:        width,
 		Ascent:       ascent,
 		Descent:      descent,
-		Orientations: computeTextOrientations(text, wm),
+		Orientations: computeTextOrientations(text, style.WritingMode),
+		Runs:         textFontRuns(text, style, ctx),
 	}
 }
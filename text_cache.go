@@ -0,0 +1,156 @@
+package layout
+
+import (
+	"container/list"
+	"sync"
+)
+
+// TextMeasureCache memoizes text measurements across layouts.
+//
+// LayoutText asks the TextMetricsProvider for the width of every word,
+// space and hyphen of every text node, on every layout. With a real font
+// rasterizer behind it, that dominates the cost of laying out text-heavy
+// trees again after a small change. A TextMeasureCache remembers the
+// results by text and TextStyle, keeping the most recently used ones.
+//
+// A cache is attached to a layout via LayoutContext.WithTextCache and may
+// be shared across layouts and goroutines:
+//
+//	texts := layout.NewTextMeasureCache(10000)
+//	ctx := layout.NewLayoutContext(800, 600, 16).WithTextCache(texts)
+//
+// The cache is flushed when the TextMetricsProvider installed with
+// SetTextMetricsProvider changes. A provider whose measurements change in
+// place, for example when it loads a font, should be followed by a call to
+// Invalidate.
+type TextMeasureCache struct {
+	mu        sync.Mutex
+	capacity  int
+	entries   map[textMeasureKey]*list.Element
+	order     *list.List // Most recently used first
+	holder    *textMetricsHolder
+	hits      int
+	misses    int
+	evictions int
+}
+
+// TextMeasureCacheStats reports TextMeasureCache effectiveness counters.
+type TextMeasureCacheStats struct {
+	Hits      int // Measurements answered from the cache
+	Misses    int // Measurements passed on to the provider
+	Evictions int // Measurements dropped to stay within capacity
+	Entries   int // Measurements currently cached
+}
+
+// DefaultTextMeasureCacheSize is the capacity NewTextMeasureCache uses for
+// a capacity of 0 or less.
+const DefaultTextMeasureCacheSize = 4096
+
+// textMeasureKey identifies a measurement.
+type textMeasureKey struct {
+	text  string
+	style TextStyle
+}
+
+// textMeasurement is the measured advance, ascent and descent of a text,
+// and its font runs if fonts fall back (see textFontRuns).
+type textMeasurement struct {
+	key                      textMeasureKey
+	advance, ascent, descent float64
+	runs                     []FontRun
+}
+
+// NewTextMeasureCache creates an empty cache that holds up to capacity
+// measurements, or DefaultTextMeasureCacheSize if capacity is 0 or less.
+func NewTextMeasureCache(capacity int) *TextMeasureCache {
+	if capacity <= 0 {
+		capacity = DefaultTextMeasureCacheSize
+	}
+	return &TextMeasureCache{
+		capacity: capacity,
+		entries:  make(map[textMeasureKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Stats returns the current counters and entry count.
+func (c *TextMeasureCache) Stats() TextMeasureCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return TextMeasureCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   len(c.entries),
+	}
+}
+
+// Invalidate drops all cached measurements, for when the provider's
+// measurements change without the provider being replaced.
+func (c *TextMeasureCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flush()
+}
+
+// Reset drops all cached measurements and zeroes the counters.
+func (c *TextMeasureCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flush()
+	c.hits = 0
+	c.misses = 0
+	c.evictions = 0
+}
+
+// flush drops all entries. Must be called with c.mu held.
+func (c *TextMeasureCache) flush() {
+	if len(c.entries) > 0 {
+		c.entries = make(map[textMeasureKey]*list.Element)
+		c.order.Init()
+	}
+}
+
+// measure returns the measurement of text in style with the installed
+// provider, from the cache if it's there.
+func (c *TextMeasureCache) measure(text string, style TextStyle) *textMeasurement {
+	key := textMeasureKey{text: text, style: style}
+	holder := textMetrics.Load()
+
+	c.mu.Lock()
+	if c.holder != holder {
+		c.flush()
+		c.holder = holder
+	}
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.hits++
+		c.mu.Unlock()
+		return e.Value.(*textMeasurement)
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Measure without the lock; a concurrent miss on the same key only
+	// measures twice
+	m := measureTextWith(text, style, holder.provider)
+	m.key = key
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.holder != holder {
+		return m
+	}
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return m
+	}
+	c.entries[key] = c.order.PushFront(m)
+	for len(c.entries) > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*textMeasurement).key)
+		c.evictions++
+	}
+	return m
+}
@@ -0,0 +1,90 @@
+package layout
+
+import (
+	"testing"
+)
+
+// countingMetrics measures 10px per rune and counts its calls.
+type countingMetrics struct {
+	calls int
+}
+
+func (m *countingMetrics) Measure(text string, style TextStyle) (advance, ascent, descent float64) {
+	m.calls++
+	return float64(len([]rune(text))) * 10, style.FontSize * 0.8, style.FontSize * 0.2
+}
+
+func TestTextMeasureCacheAcrossLayouts(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	// Before the provider is installed, so unit resolution doesn't count
+	texts := NewTextMeasureCache(0)
+	ctx := NewLayoutContext(800, 600, 16).WithTextCache(texts)
+	metrics := &countingMetrics{}
+	SetTextMetricsProvider(metrics)
+
+	node := Text("the quick brown fox jumps over the lazy dog", Style{TextStyle: &TextStyle{FontSize: 16}})
+	LayoutText(node, Loose(100, Unbounded), ctx)
+	first := metrics.calls
+	if first == 0 {
+		t.Fatal("Expected the first layout to measure the text")
+	}
+	lines := len(node.TextLayout.Lines)
+
+	// Narrower, so the lines break differently, but the words are known
+	LayoutText(node, Loose(80, Unbounded), ctx)
+	if metrics.calls != first {
+		t.Errorf("Expected no new measurements, got %d", metrics.calls-first)
+	}
+	if len(node.TextLayout.Lines) <= lines {
+		t.Errorf("Expected more than %d lines at the narrower width, got %d", lines, len(node.TextLayout.Lines))
+	}
+	if stats := texts.Stats(); stats.Hits == 0 || stats.Misses != first {
+		t.Errorf("Expected hits and %d misses, got %+v", first, stats)
+	}
+
+	// A new provider flushes the cache, and so does Invalidate
+	SetTextMetricsProvider(&countingMetrics{})
+	LayoutText(node, Loose(80, Unbounded), ctx)
+	if metrics.calls != first {
+		t.Errorf("Expected the new provider to measure, got %d calls to the old one", metrics.calls-first)
+	}
+	SetTextMetricsProvider(metrics)
+	LayoutText(node, Loose(80, Unbounded), ctx)
+	again := metrics.calls
+	texts.Invalidate()
+	LayoutText(node, Loose(80, Unbounded), ctx)
+	if metrics.calls == again {
+		t.Error("Expected measurements after Invalidate")
+	}
+}
+
+func TestTextMeasureCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	metrics := &countingMetrics{}
+	SetTextMetricsProvider(metrics)
+
+	texts := NewTextMeasureCache(2)
+	ctx := NewLayoutContext(800, 600, 16).WithTextCache(texts)
+	style := TextStyle{FontSize: 16}
+	measureText("a", style, ctx)
+	measureText("b", style, ctx)
+	measureText("a", style, ctx) // "b" is now the least recently used
+	measureText("c", style, ctx)
+	if stats := texts.Stats(); stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 1 {
+		t.Errorf("Expected 2 entries, 1 eviction and 1 hit, got %+v", stats)
+	}
+	calls := metrics.calls
+	measureText("a", style, ctx)
+	if metrics.calls != calls {
+		t.Error("Expected \"a\" to be kept")
+	}
+	measureText("b", style, ctx)
+	if metrics.calls != calls+1 {
+		t.Error("Expected \"b\" to have been evicted")
+	}
+
+	texts.Reset()
+	if stats := texts.Stats(); stats != (TextMeasureCacheStats{}) {
+		t.Errorf("Expected an empty cache after Reset, got %+v", stats)
+	}
+}
@@ -2,6 +2,7 @@ package layout
 
 import (
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 	"unicode"
@@ -1672,13 +1673,14 @@ func TestTextDefaultTextStyle(t *testing.T) {
 	ctx := NewLayoutContext(800, 600, 16)
 	LayoutText(node, constraints, ctx)
 
-	// LayoutText should create default TextStyle
-	if node.Style.TextStyle == nil {
-		t.Error("LayoutText should create default TextStyle when nil")
+	// LayoutText lays out with a default TextStyle, leaving the node's nil
+	// so it keeps inheriting on the next layout
+	if node.Style.TextStyle != nil {
+		t.Error("LayoutText should not set the node's TextStyle")
 	}
 
 	// Default values should be set
-	style := node.Style.TextStyle
+	style := node.TextLayout.Style
 	if style.FontSize != 16 {
 		t.Errorf("Default FontSize should be 16, got %.2f", style.FontSize)
 	}
@@ -2925,6 +2927,55 @@ func TestTabSizeCustom(t *testing.T) {
 	}
 }
 
+func TestTabStopsPre(t *testing.T) {
+	setupFakeMetrics()
+
+	tests := []struct {
+		text    string
+		tabSize float64
+		want    float64
+	}{
+		{"ab\tc", 4, 50},   // "ab" ends at 20, tab to 40
+		{"abcd\tx", 4, 90}, // "abcd" ends on a stop, so the tab goes to the next one
+		{"a\tb", -1, 90},   // Default tab stops every 8 spaces
+		{"\t\tx", 2, 50},
+		{"a\tb", 0, 20}, // tab-size 0 disables tabs
+	}
+	for _, tt := range tests {
+		node := Text(tt.text, Style{TextStyle: &TextStyle{
+			WhiteSpace: WhiteSpacePre, TabSize: tt.tabSize, WordSpacing: -1, LetterSpacing: -1,
+		}})
+		LayoutText(node, Loose(400, 100), NewLayoutContext(800, 600, 16))
+		if got := node.TextLayout.Lines[0].Width; got != tt.want {
+			t.Errorf("%q with tab-size %v: expected width %v, got %v", tt.text, tt.tabSize, tt.want, got)
+		}
+	}
+}
+
+func TestTabStopsPreWrap(t *testing.T) {
+	setupFakeMetrics()
+
+	node := Text("aaaa\tbb", Style{TextStyle: &TextStyle{
+		WhiteSpace: WhiteSpacePreWrap, TabSize: 4, WordSpacing: -1, LetterSpacing: -1,
+	}})
+	LayoutText(node, Loose(70, 100), NewLayoutContext(800, 600, 16))
+
+	lines := node.TextLayout.Lines
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].Width != 40 {
+		t.Errorf("Expected the first line to end before the tab at 40, got %v", lines[0].Width)
+	}
+	// The wrapped tab starts a new line, so it advances a full tab stop
+	if box := lines[1].Boxes[0]; box.Text != "\t" || box.Width != 40 {
+		t.Errorf("Expected a 40px tab box, got %q width %v", box.Text, box.Width)
+	}
+	if lines[1].Width != 60 {
+		t.Errorf("Expected the second line to be 60 wide, got %v", lines[1].Width)
+	}
+}
+
 // ========================================
 // Hanging Punctuation Tests
 // ========================================
@@ -2984,6 +3035,52 @@ func TestHangingPunctuationLast(t *testing.T) {
 	}
 }
 
+func TestHangingPunctuationAlignment(t *testing.T) {
+	defer SetTextMetricsProvider(getTextMetrics())
+	setupFakeMetrics()
+
+	layoutLine := func(text string, width float64, ts TextStyle) []TextLine {
+		ts.FontSize, ts.LineHeight = 10, 20
+		ts.WordSpacing, ts.LetterSpacing = -1, -1
+		node := Text(text, Style{TextStyle: &ts})
+		LayoutText(node, Loose(width, 200), NewLayoutContext(800, 600, 16))
+		return node.TextLayout.Lines
+	}
+
+	// The stop hangs past the right edge: the rest of the line is aligned
+	lines := layoutLine("aaa bb.", 100, TextStyle{TextAlign: TextAlignRight, HangingPunctuation: HangingPunctuationForceEnd})
+	if lines[0].OffsetX != 40 || lines[0].Width != 70 {
+		t.Errorf("Expected the line at 40, 70 wide, got %v, %v wide", lines[0].OffsetX, lines[0].Width)
+	}
+
+	// In RTL the end is on the left
+	lines = layoutLine("aaa bb.", 100, TextStyle{Direction: DirectionRTL, HangingPunctuation: HangingPunctuationForceEnd})
+	if lines[0].OffsetX != 30 {
+		t.Errorf("Expected the RTL line at 30, got %v", lines[0].OffsetX)
+	}
+
+	// Quotes around a single line hang on both sides
+	lines = layoutLine("\u201Caa bb\u201D", 100, TextStyle{TextAlign: TextAlignCenter, HangingPunctuation: HangingPunctuationFirst | HangingPunctuationLast})
+	if lines[0].OffsetX != 15 {
+		t.Errorf("Expected the quoted line at 15, got %v", lines[0].OffsetX)
+	}
+
+	// Justification ignores the hanging stop, which ends past the edge
+	lines = layoutLine("aaaa bb. cc dd", 90, TextStyle{TextAlign: TextAlignJustify, HangingPunctuation: HangingPunctuationForceEnd})
+	if len(lines) != 2 || lines[0].SpaceAdjustment != 20 {
+		t.Fatalf("Expected 2 lines and 20px more per space on the first, got %d and %+v", len(lines), lines[0])
+	}
+
+	// allow-end lets a stop that doesn't fit hang, keeping its word on the line
+	lines = layoutLine("aaaa bbbb.", 90, TextStyle{HangingPunctuation: HangingPunctuationAllowEnd})
+	if len(lines) != 1 || lines[0].Width != 100 {
+		t.Errorf("Expected one line 100 wide, got %+v", lines)
+	}
+	if lines = layoutLine("aaaa bbbb.", 90, TextStyle{}); len(lines) != 2 {
+		t.Errorf("Expected the stop not to hang without hanging-punctuation, got %d lines", len(lines))
+	}
+}
+
 // ========================================
 // Hyphens Property Tests
 // ========================================
@@ -3048,6 +3145,45 @@ func TestHyphensManual(t *testing.T) {
 	}
 }
 
+func TestSoftHyphenBreaks(t *testing.T) {
+	setupFakeMetrics()
+
+	tests := []struct {
+		text    string
+		width   float64
+		hyphens Hyphens
+		want    []string
+		widths  []float64
+	}{
+		// Unbroken soft hyphens are invisible and keep the word in one box
+		{"super\u00ADcalifragilistic", 300, HyphensManual, []string{"supercalifragilistic"}, []float64{200}},
+		{"super\u00ADcalifragilistic", 100, HyphensNone, []string{"supercalifragilistic"}, []float64{200}},
+		// A break at a soft hyphen shows a hyphen
+		{"super\u00ADcalifragilistic", 100, HyphensManual, []string{"super-", "califragilistic"}, []float64{60, 150}},
+		{"co\u00ADop\u00ADer\u00ADate", 60, HyphensManual, []string{"coop-", "erate"}, []float64{50, 50}},
+		// "xx ab" fits, but "xx ab-" doesn't
+		{"xx ab\u00ADcd", 55, HyphensManual, []string{"xx", "abcd"}, []float64{20, 40}},
+	}
+	for _, tt := range tests {
+		node := Text(tt.text, Style{TextStyle: &TextStyle{Hyphens: tt.hyphens, WordSpacing: -1, LetterSpacing: -1}})
+		LayoutText(node, Loose(tt.width, 100), NewLayoutContext(800, 600, 16))
+
+		var got []string
+		var widths []float64
+		for _, line := range node.TextLayout.Lines {
+			var words []string
+			for _, box := range line.Boxes {
+				words = append(words, box.Text)
+			}
+			got = append(got, strings.Join(words, " "))
+			widths = append(widths, line.Width)
+		}
+		if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(widths, tt.widths) {
+			t.Errorf("%q at %v: expected lines %q widths %v, got %q widths %v", tt.text, tt.width, tt.want, tt.widths, got, widths)
+		}
+	}
+}
+
 func TestHyphensAuto(t *testing.T) {
 	setupFakeMetrics()
 
@@ -3107,27 +3243,32 @@ func TestDirectionRTL(t *testing.T) {
 func TestDirectionRTLWithTextAlign(t *testing.T) {
 	setupFakeMetrics()
 
-	text := "Hello world"
-	node := Text(text, Style{
-		Width: Px(200),
-		TextStyle: &TextStyle{
-			FontSize:  16,
-			Direction: DirectionRTL,
-			TextAlign: TextAlignLeft, // Explicit left in RTL becomes right
-		},
-	})
-
-	constraints := Loose(200, 100)
-	ctx := NewLayoutContext(800, 600, 16)
-	LayoutText(node, constraints, ctx)
+	// left and right are physical; start and end follow the direction.
+	// "Hello world" is 110px wide in a 200px box.
+	tests := []struct {
+		align   TextAlign
+		offsetX float64
+	}{
+		{TextAlignDefault, 90},
+		{TextAlignStart, 90},
+		{TextAlignEnd, 0},
+		{TextAlignLeft, 0},
+		{TextAlignRight, 90},
+		{TextAlignCenter, 45},
+	}
+	for _, tt := range tests {
+		node := Text("Hello world", Style{
+			Width: Px(200),
+			TextStyle: &TextStyle{
+				FontSize:  16,
+				Direction: DirectionRTL,
+				TextAlign: tt.align,
+			},
+		})
+		LayoutText(node, Loose(200, 100), NewLayoutContext(800, 600, 16))
 
-	// In RTL with TextAlignLeft, text should be on right (swapped)
-	if len(node.TextLayout.Lines) > 0 {
-		line := node.TextLayout.Lines[0]
-		// Left in RTL becomes right: OffsetX = contentWidth - lineWidth
-		// Text is ~110px, so OffsetX should be ~90px (right-aligned)
-		if line.OffsetX == 0 {
-			t.Errorf("RTL with left alignment (swapped to right) should position text on right (OffsetX > 0), got OffsetX=%.2f", line.OffsetX)
+		if got := node.TextLayout.Lines[0].OffsetX; math.Abs(got-tt.offsetX) > 0.01 {
+			t.Errorf("Expected RTL text-align %d at x %.2f, got %.2f", tt.align, tt.offsetX, got)
 		}
 	}
 }
@@ -0,0 +1,67 @@
+package layout
+
+import "time"
+
+// NodeTrace describes a run of a layout algorithm on a node, as reported
+// to LayoutContext.OnNodeStart and OnNodeEnd.
+//
+// Containers lay out their children while they're being laid out, so the
+// calls nest: each OnNodeStart is matched by the next OnNodeEnd for the
+// same node that isn't matched by an inner start, which is enough to build
+// a flame graph. A node may be laid out several times in one layout, for
+// example to measure a flex item and again to stretch it. Results that
+// MeasureCache or LayoutCache restore don't run an algorithm and aren't
+// reported. LayoutProfile collects them into a profile.
+//
+// Example:
+//
+//	ctx.OnNodeEnd = func(t layout.NodeTrace) {
+//	    if t.Duration > time.Millisecond {
+//	        log.Printf("slow node %q: %v", t.Node.ID, t.Duration)
+//	    }
+//	}
+type NodeTrace struct {
+	Node        *Node
+	Constraints Constraints
+
+	// Algorithm is the display type whose algorithm runs: DisplayFlex,
+	// DisplayGrid, DisplayInlineText or DisplayBlock.
+	Algorithm Display
+
+	// Size is the resulting border-box size. Zero in OnNodeStart.
+	Size Size
+
+	// Duration is the time the run took, including the layout of
+	// descendants and the hooks called for them. Zero in OnNodeStart.
+	Duration time.Duration
+}
+
+// tracedLayout runs the layout algorithm for node, reporting it to ctx's
+// hooks.
+func tracedLayout(node *Node, constraints Constraints, ctx *LayoutContext) Size {
+	trace := NodeTrace{
+		Node:        node,
+		Constraints: constraints,
+		Algorithm:   layoutAlgorithmOf(node.Style.Display),
+	}
+	if ctx.OnNodeStart != nil {
+		ctx.OnNodeStart(trace)
+	}
+	start := time.Now()
+	trace.Size = runLayoutAlgorithm(node, constraints, ctx)
+	trace.Duration = time.Since(start)
+	if ctx.OnNodeEnd != nil {
+		ctx.OnNodeEnd(trace)
+	}
+	return trace.Size
+}
+
+// layoutAlgorithmOf returns the display type whose algorithm lays out
+// nodes of display type d (see runLayoutAlgorithm).
+func layoutAlgorithmOf(d Display) Display {
+	switch d {
+	case DisplayFlex, DisplayGrid, DisplayInlineText:
+		return d
+	}
+	return DisplayBlock
+}
@@ -0,0 +1,51 @@
+package layout
+
+import "testing"
+
+func TestTraceHooksNest(t *testing.T) {
+	a, b := Fixed(50, 20), Fixed(30, 20)
+	row := HStack(a, b)
+	root := VStack(row)
+	ctx := NewLayoutContext(400, 300, 16)
+
+	var open []*Node
+	starts, ends := 0, 0
+	ctx.OnNodeStart = func(tr NodeTrace) {
+		starts++
+		open = append(open, tr.Node)
+	}
+	ctx.OnNodeEnd = func(tr NodeTrace) {
+		ends++
+		if len(open) == 0 || open[len(open)-1] != tr.Node {
+			t.Fatalf("Expected OnNodeEnd to match the innermost OnNodeStart")
+		}
+		open = open[:len(open)-1]
+		if tr.Duration < 0 {
+			t.Errorf("Expected a non-negative duration, got %v", tr.Duration)
+		}
+		if tr.Node == row && tr.Algorithm != DisplayFlex {
+			t.Errorf("Expected the row to use the flex algorithm, got %v", tr.Algorithm)
+		}
+		if tr.Node == a && tr.Size != (Size{Width: 50, Height: 20}) {
+			t.Errorf("Expected a 50x20 result, got %+v", tr.Size)
+		}
+	}
+
+	Layout(root, Loose(400, 300), ctx)
+
+	if starts == 0 || starts != ends {
+		t.Errorf("Expected matching starts and ends, got %d and %d", starts, ends)
+	}
+	if len(open) != 0 {
+		t.Errorf("Expected every start to be ended, %d open", len(open))
+	}
+}
+
+func TestTraceReportsBlockForOtherDisplays(t *testing.T) {
+	if got := layoutAlgorithmOf(DisplayNone); got != DisplayBlock {
+		t.Errorf("Expected DisplayBlock, got %v", got)
+	}
+	if got := layoutAlgorithmOf(DisplayGrid); got != DisplayGrid {
+		t.Errorf("Expected DisplayGrid, got %v", got)
+	}
+}
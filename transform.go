@@ -0,0 +1,98 @@
+package layout
+
+import "math"
+
+// DecomposedTransform is a Transform split into the operations that
+// produce it, applied in the order scale, skew, rotate, translate: the
+// equivalent of the CSS
+//
+//	translate(TranslateX, TranslateY) rotate(Rotate) skewX(Skew) scale(ScaleX, ScaleY)
+//
+// Angles are in degrees. A mirrored transform has a negative ScaleY.
+//
+// See: https://www.w3.org/TR/css-transforms-2/#decomposing-a-2d-matrix
+type DecomposedTransform struct {
+	TranslateX, TranslateY float64
+	Rotate                 float64
+	Skew                   float64
+	ScaleX, ScaleY         float64
+}
+
+// Decompose splits t into a translation, rotation, skew and scale. The
+// zero Transform is treated as the identity, as in Style.Transform. A
+// transform that flattens the plane loses its skew.
+//
+// Example:
+//
+//	d := layout.RotateDegrees(30).Multiply(layout.Scale(2, 2)).Decompose()
+//	// d.Rotate is 30, d.ScaleX and d.ScaleY are 2
+func (t Transform) Decompose() DecomposedTransform {
+	if t == (Transform{}) {
+		t = IdentityTransform()
+	}
+	d := DecomposedTransform{TranslateX: t.E, TranslateY: t.F}
+
+	// The image of the x axis gives the rotation and the x scale
+	d.ScaleX = math.Hypot(t.A, t.B)
+	ux, uy := 1.0, 0.0
+	if d.ScaleX != 0 {
+		ux, uy = t.A/d.ScaleX, t.B/d.ScaleX
+	}
+	d.Rotate = math.Atan2(uy, ux) * 180 / math.Pi
+
+	// The image of the y axis, less its part along the x axis, gives the y
+	// scale; that part is the skew
+	along := t.C*ux + t.D*uy
+	wx, wy := t.C-along*ux, t.D-along*uy
+	d.ScaleY = math.Hypot(wx, wy)
+	if t.A*t.D-t.B*t.C < 0 {
+		d.ScaleY = -d.ScaleY
+	}
+	if d.ScaleY != 0 {
+		d.Skew = math.Atan(along/d.ScaleY) * 180 / math.Pi
+	}
+	return d
+}
+
+// Transform composes d back into a Transform.
+func (d DecomposedTransform) Transform() Transform {
+	return Translate(d.TranslateX, d.TranslateY).
+		Multiply(RotateDegrees(d.Rotate)).
+		Multiply(SkewX(d.Skew * math.Pi / 180)).
+		Multiply(Scale(d.ScaleX, d.ScaleY))
+}
+
+// InterpolateTransform returns the transform t of the way from a to b (0
+// gives a, 1 gives b), as CSS transitions interpolate matrices: both are
+// decomposed, their translations, rotations, skews and scales are
+// interpolated linearly, and the result is composed again. Rotations take
+// the shorter way around. t outside [0, 1] extrapolates.
+//
+// Example:
+//
+//	frame := layout.InterpolateTransform(layout.IdentityTransform(), layout.RotateDegrees(90), 0.5)
+//	// frame rotates by 45 degrees
+//
+// See: https://www.w3.org/TR/css-transforms-1/#interpolation-of-2d-matrices
+func InterpolateTransform(a, b Transform, t float64) Transform {
+	da, db := a.Decompose(), b.Decompose()
+
+	// Don't rotate the long way around
+	if math.Abs(da.Rotate-db.Rotate) > 180 {
+		if da.Rotate > db.Rotate {
+			da.Rotate -= 360
+		} else {
+			db.Rotate -= 360
+		}
+	}
+
+	lerp := func(x, y float64) float64 { return x + (y-x)*t }
+	return DecomposedTransform{
+		TranslateX: lerp(da.TranslateX, db.TranslateX),
+		TranslateY: lerp(da.TranslateY, db.TranslateY),
+		Rotate:     lerp(da.Rotate, db.Rotate),
+		Skew:       lerp(da.Skew, db.Skew),
+		ScaleX:     lerp(da.ScaleX, db.ScaleX),
+		ScaleY:     lerp(da.ScaleY, db.ScaleY),
+	}.Transform()
+}
@@ -163,3 +163,61 @@ func TestTransformToSVGString(t *testing.T) {
 		t.Error("Identity transform should return empty SVG string")
 	}
 }
+
+func TestTransformDecompose(t *testing.T) {
+	near := func(a, b Transform) bool {
+		for _, d := range []float64{a.A - b.A, a.B - b.B, a.C - b.C, a.D - b.D, a.E - b.E, a.F - b.F} {
+			if math.Abs(d) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+
+	d := Translate(10, 20).Multiply(RotateDegrees(30)).Multiply(SkewX(math.Pi / 8)).Multiply(Scale(2, 3)).Decompose()
+	want := DecomposedTransform{TranslateX: 10, TranslateY: 20, Rotate: 30, Skew: 22.5, ScaleX: 2, ScaleY: 3}
+	for _, c := range [][2]float64{
+		{d.TranslateX, want.TranslateX}, {d.TranslateY, want.TranslateY}, {d.Rotate, want.Rotate},
+		{d.Skew, want.Skew}, {d.ScaleX, want.ScaleX}, {d.ScaleY, want.ScaleY},
+	} {
+		if math.Abs(c[0]-c[1]) > 1e-9 {
+			t.Errorf("Expected %+v, got %+v", want, d)
+			break
+		}
+	}
+
+	// Every transform composes back from its parts, mirrored ones included
+	for _, tr := range []Transform{
+		IdentityTransform(),
+		Scale(-1, 1),
+		Matrix(1, 2, 3, 4, 5, 6),
+		RotateDegrees(-170).Multiply(SkewY(0.3)),
+	} {
+		if got := tr.Decompose().Transform(); !near(got, tr) {
+			t.Errorf("Expected %+v to round-trip, got %+v", tr, got)
+		}
+	}
+	if got := (Transform{}).Decompose(); got != (DecomposedTransform{ScaleX: 1, ScaleY: 1}) {
+		t.Errorf("Expected the zero transform to decompose as the identity, got %+v", got)
+	}
+}
+
+func TestInterpolateTransform(t *testing.T) {
+	a := Translate(0, 0)
+	b := Translate(100, 50).Multiply(RotateDegrees(90)).Multiply(Scale(3, 3))
+
+	got := InterpolateTransform(a, b, 0.5)
+	want := Translate(50, 25).Multiply(RotateDegrees(45)).Multiply(Scale(2, 2))
+	for _, d := range []float64{got.A - want.A, got.B - want.B, got.C - want.C, got.D - want.D, got.E - want.E, got.F - want.F} {
+		if math.Abs(d) > 1e-9 {
+			t.Errorf("Expected %+v halfway, got %+v", want, got)
+			break
+		}
+	}
+
+	// From 170 to -170 degrees goes through 180, not 0
+	mid := InterpolateTransform(RotateDegrees(170), RotateDegrees(-170), 0.5).Decompose()
+	if math.Abs(math.Abs(mid.Rotate)-180) > 1e-9 {
+		t.Errorf("Expected a 180 degree rotation halfway, got %v", mid.Rotate)
+	}
+}
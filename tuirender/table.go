@@ -0,0 +1,314 @@
+package tuirender
+
+import (
+	"sort"
+
+	"github.com/SCKelemen/layout"
+)
+
+// Tables
+//
+// A terminal table is a grid container whose border and gaps are one cell
+// wide and whose cells have no border of their own:
+//
+//	table := &layout.Node{Style: layout.Style{
+//	    Display:             layout.DisplayGrid,
+//	    Border:              layout.Uniform(layout.Px(1)),
+//	    GridColumnGap:       layout.Px(1),
+//	    GridRowGap:          layout.Px(1),
+//	    GridTemplateColumns: ...,
+//	}}
+//
+// The one-cell gutters around and between cells are where the rules go.
+// NewTableGeometry recovers the rule positions and each cell's track span
+// from the laid-out rects, so merged (spanning) cells need no extra markup,
+// and TableGeometry.Glyphs picks the box-drawing junction for every rule
+// crossing.
+
+// Junction glyphs used where table rules meet.
+const (
+	glyphTeeDown  = '┬'
+	glyphTeeUp    = '┴'
+	glyphTeeRight = '├'
+	glyphTeeLeft  = '┤'
+	glyphCross    = '┼'
+)
+
+// CellBorders reports which edges of a cell the cell itself draws. Shared
+// edges are owned by the cell above or to the left, matching the
+// collapsing-border tie-break of CSS 2.1 §17.6.2.1.
+type CellBorders struct {
+	Top, Right, Bottom, Left bool
+}
+
+// TableCell is one cell of a table in terminal cells, relative to the
+// table's border-box origin.
+type TableCell struct {
+	// Node is the grid item that forms the cell.
+	Node *layout.Node
+
+	// Row and Col index the first track the cell occupies; RowSpan and
+	// ColSpan count the tracks it covers.
+	Row, Col         int
+	RowSpan, ColSpan int
+
+	// X, Y, Width and Height are the cell's content area in character cells.
+	X, Y, Width, Height int
+
+	// Borders is the cell's edge ownership.
+	Borders CellBorders
+}
+
+// TableGeometry is the rule and cell layout of a table rendered on a
+// character grid.
+type TableGeometry struct {
+	// Width and Height are the table's border-box size in character cells.
+	Width, Height int
+
+	// ColumnRules and RowRules are the x and y positions of the vertical and
+	// horizontal rules in ascending order. Track i lies between rule i and
+	// rule i+1.
+	ColumnRules, RowRules []int
+
+	// Cells lists the table's cells in document order.
+	Cells []TableCell
+
+	// slots maps each (row, col) track slot to an index into Cells, or -1
+	// when no cell covers it.
+	slots [][]int
+}
+
+// NewTableGeometry computes the rule positions, cell spans and border
+// ownership of a laid-out table. Children with display: none or an empty
+// rect are ignored.
+func NewTableGeometry(table *layout.Node) *TableGeometry {
+	g := &TableGeometry{
+		Width:  cell(table.Rect.Width),
+		Height: cell(table.Rect.Height),
+	}
+
+	type bounds struct {
+		node   *layout.Node
+		x0, y0 int
+		x1, y1 int
+	}
+	var items []bounds
+	xs := map[int]bool{}
+	ys := map[int]bool{}
+	for _, child := range table.Children {
+		if child.Style.Display == layout.DisplayNone || child.Rect.Width <= 0 || child.Rect.Height <= 0 {
+			continue
+		}
+		b := bounds{
+			node: child,
+			x0:   cell(child.Rect.X),
+			y0:   cell(child.Rect.Y),
+			x1:   cell(child.Rect.X + child.Rect.Width),
+			y1:   cell(child.Rect.Y + child.Rect.Height),
+		}
+		items = append(items, b)
+		// The rules sit in the gutters just outside the cell
+		xs[b.x0-1], xs[b.x1] = true, true
+		ys[b.y0-1], ys[b.y1] = true, true
+	}
+
+	g.ColumnRules = sortedKeys(xs)
+	g.RowRules = sortedKeys(ys)
+
+	rows, cols := len(g.RowRules)-1, len(g.ColumnRules)-1
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	g.slots = make([][]int, rows)
+	for r := range g.slots {
+		g.slots[r] = make([]int, cols)
+		for c := range g.slots[r] {
+			g.slots[r][c] = -1
+		}
+	}
+
+	for _, b := range items {
+		c0 := sort.SearchInts(g.ColumnRules, b.x0-1)
+		c1 := sort.SearchInts(g.ColumnRules, b.x1)
+		r0 := sort.SearchInts(g.RowRules, b.y0-1)
+		r1 := sort.SearchInts(g.RowRules, b.y1)
+		idx := len(g.Cells)
+		g.Cells = append(g.Cells, TableCell{
+			Node:    b.node,
+			Row:     r0,
+			Col:     c0,
+			RowSpan: r1 - r0,
+			ColSpan: c1 - c0,
+			X:       b.x0,
+			Y:       b.y0,
+			Width:   b.x1 - b.x0,
+			Height:  b.y1 - b.y0,
+		})
+		for r := r0; r < r1; r++ {
+			for c := c0; c < c1; c++ {
+				// Overlapping items: the first one claims the slot
+				if g.slots[r][c] < 0 {
+					g.slots[r][c] = idx
+				}
+			}
+		}
+	}
+
+	for i := range g.Cells {
+		g.Cells[i].Borders = g.ownership(i)
+	}
+	return g
+}
+
+// Rows returns the number of row tracks.
+func (g *TableGeometry) Rows() int {
+	return len(g.slots)
+}
+
+// Columns returns the number of column tracks.
+func (g *TableGeometry) Columns() int {
+	if len(g.slots) == 0 {
+		return 0
+	}
+	return len(g.slots[0])
+}
+
+// CellAt returns the cell covering track slot (row, col), or nil if the slot
+// is empty or out of range. Every slot of a merged cell returns that cell.
+func (g *TableGeometry) CellAt(row, col int) *TableCell {
+	if idx := g.slot(row, col); idx >= 0 {
+		return &g.Cells[idx]
+	}
+	return nil
+}
+
+// slot returns the cell index at (row, col), or -1.
+func (g *TableGeometry) slot(row, col int) int {
+	if row < 0 || row >= len(g.slots) || col < 0 || col >= len(g.slots[row]) {
+		return -1
+	}
+	return g.slots[row][col]
+}
+
+// ownership computes which edges cell i draws. A cell always owns its bottom
+// and right edges. It owns its top or left edge when at least one slot
+// along that edge has no cell before it to claim the shared rule.
+func (g *TableGeometry) ownership(i int) CellBorders {
+	c := g.Cells[i]
+	b := CellBorders{Bottom: true, Right: true}
+	for col := c.Col; col < c.Col+c.ColSpan; col++ {
+		if g.slot(c.Row-1, col) < 0 {
+			b.Top = true
+			break
+		}
+	}
+	for row := c.Row; row < c.Row+c.RowSpan; row++ {
+		if g.slot(row, c.Col-1) < 0 {
+			b.Left = true
+			break
+		}
+	}
+	return b
+}
+
+// Glyphs returns the table's rules as a Height×Width matrix of box-drawing
+// runes indexed [y][x]. Cells that carry no rule are 0. Rules inside a
+// merged cell are omitted, and every crossing gets the junction glyph that
+// matches the rules meeting there.
+func (g *TableGeometry) Glyphs() [][]rune {
+	// h[y][x] joins (x, y) to (x+1, y); v[y][x] joins (x, y) to (x, y+1).
+	h := newBoolMatrix(g.Width, g.Height)
+	v := newBoolMatrix(g.Width, g.Height)
+	for _, c := range g.Cells {
+		left := g.ColumnRules[c.Col]
+		right := g.ColumnRules[c.Col+c.ColSpan]
+		top := g.RowRules[c.Row]
+		bottom := g.RowRules[c.Row+c.RowSpan]
+		for x := left; x < right; x++ {
+			setBool(h, x, top, c.Borders.Top)
+			setBool(h, x, bottom, c.Borders.Bottom)
+		}
+		for y := top; y < bottom; y++ {
+			setBool(v, left, y, c.Borders.Left)
+			setBool(v, right, y, c.Borders.Right)
+		}
+	}
+
+	out := make([][]rune, g.Height)
+	for y := range out {
+		out[y] = make([]rune, g.Width)
+		for x := range out[y] {
+			up := getBool(v, x, y-1)
+			down := getBool(v, x, y)
+			left := getBool(h, x-1, y)
+			right := getBool(h, x, y)
+			out[y][x] = junction(up, down, left, right)
+		}
+	}
+	return out
+}
+
+// junction picks the box-drawing glyph for the given arms.
+func junction(up, down, left, right bool) rune {
+	switch {
+	case up && down && left && right:
+		return glyphCross
+	case down && left && right:
+		return glyphTeeDown
+	case up && left && right:
+		return glyphTeeUp
+	case up && down && right:
+		return glyphTeeRight
+	case up && down && left:
+		return glyphTeeLeft
+	case down && right:
+		return glyphTopLeft
+	case down && left:
+		return glyphTopRight
+	case up && right:
+		return glyphBottomLeft
+	case up && left:
+		return glyphBottomRight
+	case left || right:
+		return glyphHorizontal
+	case up || down:
+		return glyphVertical
+	default:
+		return 0
+	}
+}
+
+func newBoolMatrix(w, h int) [][]bool {
+	m := make([][]bool, h)
+	for y := range m {
+		m[y] = make([]bool, w)
+	}
+	return m
+}
+
+// setBool sets m[y][x] when on is true, ignoring out-of-range cells.
+func setBool(m [][]bool, x, y int, on bool) {
+	if !on || y < 0 || y >= len(m) || x < 0 || x >= len(m[y]) {
+		return
+	}
+	m[y][x] = true
+}
+
+func getBool(m [][]bool, x, y int) bool {
+	if y < 0 || y >= len(m) || x < 0 || x >= len(m[y]) {
+		return false
+	}
+	return m[y][x]
+}
+
+func sortedKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
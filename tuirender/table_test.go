@@ -0,0 +1,156 @@
+package tuirender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+// tableCell returns a text cell placed in the given grid area.
+func tableCell(text string, row, col, rowSpan, colSpan int) *layout.Node {
+	n := cellText(text)
+	n.Style.GridRowStart, n.Style.GridRowEnd = row, row+rowSpan
+	n.Style.GridColumnStart, n.Style.GridColumnEnd = col, col+colSpan
+	return n
+}
+
+// newTable builds a table with ruled one-cell gutters and fixed tracks.
+func newTable(colWidths []float64, rows int, cells ...*layout.Node) *layout.Node {
+	var cols, rowTracks []layout.GridTrack
+	for _, w := range colWidths {
+		cols = append(cols, layout.FixedTrack(layout.Px(w)))
+	}
+	for i := 0; i < rows; i++ {
+		rowTracks = append(rowTracks, layout.FixedTrack(layout.Px(1)))
+	}
+	return &layout.Node{
+		Style: layout.Style{
+			Display:             layout.DisplayGrid,
+			Border:              layout.Uniform(layout.Px(1)),
+			GridColumnGap:       layout.Px(1),
+			GridRowGap:          layout.Px(1),
+			GridTemplateColumns: cols,
+			GridTemplateRows:    rowTracks,
+		},
+		Children: cells,
+	}
+}
+
+func isTable(*layout.Node) bool { return true }
+
+func TestTableGlyphsMergedHeader(t *testing.T) {
+	root := newTable([]float64{4, 4}, 2,
+		tableCell("Title", 0, 0, 1, 2),
+		tableCell("a", 1, 0, 1, 1),
+		tableCell("b", 1, 1, 1, 1),
+	)
+	layoutCells(root, 11, 5)
+
+	got := ToStringGridWithOptions(root, 11, 5, Options{Table: isTable})
+	want := []string{
+		"┌─────────┐",
+		"│Title    │",
+		"├────┬────┤",
+		"│a   │b   │",
+		"└────┴────┘",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTableGlyphsRowSpan(t *testing.T) {
+	root := newTable([]float64{2, 2}, 2,
+		tableCell("A", 0, 0, 2, 1),
+		tableCell("B", 0, 1, 1, 1),
+		tableCell("C", 1, 1, 1, 1),
+	)
+	layoutCells(root, 7, 5)
+
+	got := ToStringGridWithOptions(root, 7, 5, Options{Table: isTable})
+	want := []string{
+		"┌──┬──┐",
+		"│A │B │",
+		"│  ├──┤",
+		"│  │C │",
+		"└──┴──┘",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTableGeometrySpansAndOwnership(t *testing.T) {
+	root := newTable([]float64{4, 4}, 2,
+		tableCell("Title", 0, 0, 1, 2),
+		tableCell("a", 1, 0, 1, 1),
+		tableCell("b", 1, 1, 1, 1),
+	)
+	layoutCells(root, 11, 5)
+	g := NewTableGeometry(root)
+
+	if g.Rows() != 2 || g.Columns() != 2 {
+		t.Fatalf("Expected 2x2 tracks, got %dx%d", g.Rows(), g.Columns())
+	}
+	if got := []int{0, 5, 10}; !equalInts(g.ColumnRules, got) {
+		t.Errorf("Expected column rules %v, got %v", got, g.ColumnRules)
+	}
+
+	header := g.CellAt(0, 1)
+	if header == nil || header.Node != root.Children[0] {
+		t.Fatal("Expected merged header to cover slot (0, 1)")
+	}
+	if header.ColSpan != 2 || header.RowSpan != 1 {
+		t.Errorf("Expected header span 1x2, got %dx%d", header.RowSpan, header.ColSpan)
+	}
+	if header.Borders != (CellBorders{Top: true, Right: true, Bottom: true, Left: true}) {
+		t.Errorf("Expected header to own its bottom edge over the row below, got %+v", header.Borders)
+	}
+
+	a, b := g.CellAt(1, 0), g.CellAt(1, 1)
+	if a.Borders != (CellBorders{Left: true, Right: true, Bottom: true}) {
+		t.Errorf("Expected left cell to cede its top edge to the row above, got %+v", a.Borders)
+	}
+	if b.Borders != (CellBorders{Right: true, Bottom: true}) {
+		t.Errorf("Expected corner cell to cede its top and left edges, got %+v", b.Borders)
+	}
+	if g.CellAt(2, 0) != nil {
+		t.Error("Expected nil for out-of-range slot")
+	}
+}
+
+func TestTableGlyphsEmptySlot(t *testing.T) {
+	// The bottom-right slot has no cell, so the neighbors own the rules
+	// around it and no rule is drawn on its far sides.
+	root := newTable([]float64{2, 2}, 2,
+		tableCell("A", 0, 0, 1, 1),
+		tableCell("B", 0, 1, 1, 1),
+		tableCell("C", 1, 0, 1, 1),
+	)
+	layoutCells(root, 7, 5)
+
+	got := ToStringGridWithOptions(root, 7, 5, Options{Table: isTable})
+	want := []string{
+		"┌──┬──┐",
+		"│A │B │",
+		"├──┼──┘",
+		"│C │   ",
+		"└──┘   ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
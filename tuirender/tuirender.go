@@ -0,0 +1,302 @@
+// Package tuirender renders laid-out node trees onto a terminal character
+// grid.
+//
+// Terminal layouts treat one layout unit as one character cell. Install
+// CellMetrics as the text metrics provider, use a FontSize of 1 and a
+// LineHeight of 1 on text nodes, lay the tree out, then call ToStringGrid:
+//
+//	layout.SetTextMetricsProvider(tuirender.CellMetrics{})
+//	ctx := layout.NewLayoutContext(80, 24, 1).WithTextMetrics(tuirender.CellMetrics{})
+//	layout.Layout(root, layout.Tight(80, 24), ctx)
+//	for _, line := range tuirender.ToStringGrid(root, 80, 24) {
+//	    fmt.Println(line)
+//	}
+//
+// The output is deterministic, so it can be embedded directly in golden test
+// files to snapshot-test TUI layouts without a terminal emulator.
+package tuirender
+
+import (
+	"math"
+	"strings"
+
+	"github.com/SCKelemen/layout"
+	"github.com/SCKelemen/unicode/v6/uax11"
+)
+
+// Box-drawing glyphs used for node borders.
+const (
+	glyphHorizontal  = '─'
+	glyphVertical    = '│'
+	glyphTopLeft     = '┌'
+	glyphTopRight    = '┐'
+	glyphBottomLeft  = '└'
+	glyphBottomRight = '┘'
+)
+
+// wideContinuation marks the second cell of a double-width rune. It is
+// dropped when the grid is converted to strings.
+const wideContinuation = rune(-1)
+
+// CellMetrics is a layout.TextMetricsProvider that measures text in
+// terminal cells: one cell per narrow rune and two per wide rune (UAX #11).
+// Font size is ignored, so every line is one cell tall.
+type CellMetrics struct{}
+
+// Measure implements layout.TextMetricsProvider.
+func (CellMetrics) Measure(text string, style layout.TextStyle) (advance, ascent, descent float64) {
+	return float64(uax11.StringWidth(text, uax11.ContextNarrow)), 1, 0
+}
+
+// Options controls how ToStringGridWithOptions paints nodes.
+type Options struct {
+	// Fill returns the rune used to fill a node's padding box before its
+	// border, text and children are painted. Returning 0 leaves the cells
+	// underneath untouched. Nil means no fills.
+	Fill func(node *layout.Node) rune
+
+	// Table reports whether node is a table whose rules should be drawn
+	// from its TableGeometry instead of as a box per node. The table's own
+	// border is replaced by the outer rules. Nil means no tables.
+	Table func(node *layout.Node) bool
+}
+
+// ToStringGrid paints root onto a cols×rows character grid and returns one
+// string per row. Nodes with a non-zero border get a single-line box, text
+// nodes have their computed lines written at their content origin, and
+// children paint over their parents in document order. Content outside the
+// grid is clipped, and so is the content of scroll containers (see
+// layout.Style.Overflow) outside their padding box, drawn at their
+// Node.Scroll position.
+func ToStringGrid(root *layout.Node, cols, rows int) []string {
+	return ToStringGridWithOptions(root, cols, rows, Options{})
+}
+
+// ToStringGridWithOptions is ToStringGrid with custom painting options.
+func ToStringGridWithOptions(root *layout.Node, cols, rows int, opts Options) []string {
+	if cols < 0 {
+		cols = 0
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	g := newGrid(cols, rows)
+	if root != nil {
+		g.paint(root, 0, 0, true, opts)
+	}
+	return g.lines()
+}
+
+// grid is a mutable character canvas.
+type grid struct {
+	cols, rows int
+	cells      [][]rune
+	clip       cellRect // Cells outside it aren't painted
+}
+
+// cellRect is a rectangle of cells from (x0, y0) up to, but not including,
+// (x1, y1).
+type cellRect struct {
+	x0, y0, x1, y1 int
+}
+
+func newGrid(cols, rows int) *grid {
+	cells := make([][]rune, rows)
+	for y := range cells {
+		cells[y] = make([]rune, cols)
+		for x := range cells[y] {
+			cells[y][x] = ' '
+		}
+	}
+	return &grid{cols: cols, rows: rows, cells: cells, clip: cellRect{0, 0, cols, rows}}
+}
+
+// set writes r at (x, y), ignoring cells outside the grid or the clip.
+func (g *grid) set(x, y int, r rune) {
+	if x < g.clip.x0 || y < g.clip.y0 || x >= g.clip.x1 || y >= g.clip.y1 {
+		return
+	}
+	// Overwriting half of a wide rune blanks the other half.
+	if g.cells[y][x] == wideContinuation && x > 0 {
+		g.cells[y][x-1] = ' '
+	}
+	if x+1 < g.cols && g.cells[y][x+1] == wideContinuation {
+		g.cells[y][x+1] = ' '
+	}
+	g.cells[y][x] = r
+}
+
+// lines converts the canvas into strings with trailing spaces preserved so
+// every row has the same display width.
+func (g *grid) lines() []string {
+	out := make([]string, g.rows)
+	var sb strings.Builder
+	for y, row := range g.cells {
+		sb.Reset()
+		for _, r := range row {
+			if r != wideContinuation {
+				sb.WriteRune(r)
+			}
+		}
+		out[y] = sb.String()
+	}
+	return out
+}
+
+// paint draws node (whose parent's origin is at ox, oy) and its subtree.
+// parentVisible is whether the parent is painted; hidden nodes are skipped
+// but their children may still be visible.
+func (g *grid) paint(node *layout.Node, ox, oy float64, parentVisible bool, opts Options) {
+	if node.Style.Display == layout.DisplayNone {
+		return
+	}
+
+	ax := ox + node.Rect.X
+	ay := oy + node.Rect.Y
+	x0, y0 := cell(ax), cell(ay)
+	x1, y1 := cell(ax+node.Rect.Width), cell(ay+node.Rect.Height)
+	visible := node.Style.Visibility.Visible(parentVisible)
+
+	if visible && opts.Fill != nil {
+		if r := opts.Fill(node); r != 0 {
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					g.set(x, y, r)
+				}
+			}
+		}
+	}
+
+	isTable := opts.Table != nil && opts.Table(node)
+	if visible && hasBorder(node) && !isTable {
+		g.box(x0, y0, x1-1, y1-1)
+	}
+
+	// A scroll container's content is clipped to its padding box and
+	// shifted by its scroll position
+	clip := g.clip
+	cx, cy := ax, ay
+	if port, ok := node.OverflowClip(nil); ok {
+		g.clip.x0 = max(g.clip.x0, cell(ax+port.X))
+		g.clip.y0 = max(g.clip.y0, cell(ay+port.Y))
+		g.clip.x1 = min(g.clip.x1, cell(ax+port.X+port.Width))
+		g.clip.y1 = min(g.clip.y1, cell(ay+port.Y+port.Height))
+		cx -= node.Scroll.X
+		cy -= node.Scroll.Y
+	}
+
+	if visible && node.TextLayout != nil {
+		g.text(node, cx, cy)
+	}
+
+	for _, child := range node.Children {
+		g.paint(child, cx, cy, visible, opts)
+	}
+	g.clip = clip
+
+	if visible && isTable {
+		for y, row := range NewTableGeometry(node).Glyphs() {
+			for x, r := range row {
+				if r != 0 {
+					g.set(x0+x, y0+y, r)
+				}
+			}
+		}
+	}
+}
+
+// box draws a single-line rectangle with corners at (x0, y0) and (x1, y1),
+// inclusive. Degenerate boxes collapse to a line.
+func (g *grid) box(x0, y0, x1, y1 int) {
+	if x1 < x0 || y1 < y0 {
+		return
+	}
+	if y0 == y1 {
+		for x := x0; x <= x1; x++ {
+			g.set(x, y0, glyphHorizontal)
+		}
+		return
+	}
+	if x0 == x1 {
+		for y := y0; y <= y1; y++ {
+			g.set(x0, y, glyphVertical)
+		}
+		return
+	}
+	for x := x0 + 1; x < x1; x++ {
+		g.set(x, y0, glyphHorizontal)
+		g.set(x, y1, glyphHorizontal)
+	}
+	for y := y0 + 1; y < y1; y++ {
+		g.set(x0, y, glyphVertical)
+		g.set(x1, y, glyphVertical)
+	}
+	g.set(x0, y0, glyphTopLeft)
+	g.set(x1, y0, glyphTopRight)
+	g.set(x0, y1, glyphBottomLeft)
+	g.set(x1, y1, glyphBottomRight)
+}
+
+// text writes node's computed lines starting at its content origin.
+func (g *grid) text(node *layout.Node, ax, ay float64) {
+	fontSize := 1.0
+	if node.TextLayout.Style.FontSize > 0 {
+		fontSize = node.TextLayout.Style.FontSize
+	}
+	s := node.Style
+	cx := ax + resolve(s.Padding.Left, fontSize) + resolve(s.Border.Left, fontSize)
+	cy := ay + resolve(s.Padding.Top, fontSize) + resolve(s.Border.Top, fontSize)
+
+	tabSize := int(node.TextLayout.Style.TabSize)
+	if node.TextLayout.Style.TabSize < 0 {
+		tabSize = 8
+	}
+
+	for _, line := range node.TextLayout.Lines {
+		y := cell(cy + line.OffsetY)
+		x := cx + line.OffsetX
+		lineStart := cell(x)
+		space := line.SpaceAdjustment
+		if line.SpaceCount > 0 {
+			space += line.SpaceWidth / float64(line.SpaceCount)
+		}
+		for i, box := range line.Boxes {
+			col := cell(x)
+			for _, r := range box.VisualText() {
+				if r == '\t' {
+					// Skip to the next tab stop, like layout measured it
+					if tabSize > 0 {
+						col = lineStart + ((col-lineStart)/tabSize+1)*tabSize
+					}
+					continue
+				}
+				w := uax11.CharWidth(r, uax11.ContextNarrow)
+				g.set(col, y, r)
+				if w == 2 {
+					g.set(col+1, y, wideContinuation)
+				}
+				col += w
+			}
+			x += box.Width
+			if i < len(line.Boxes)-1 {
+				x += space
+			}
+		}
+	}
+}
+
+// hasBorder reports whether any side of node's border has a non-zero width.
+func hasBorder(node *layout.Node) bool {
+	b := node.Style.Border
+	return b.Top.Value > 0 || b.Right.Value > 0 || b.Bottom.Value > 0 || b.Left.Value > 0
+}
+
+// resolve converts a length to cells without a viewport context.
+func resolve(l layout.Length, fontSize float64) float64 {
+	return layout.ResolveLength(l, nil, fontSize)
+}
+
+// cell snaps a layout coordinate to the nearest cell boundary.
+func cell(v float64) int {
+	return int(math.Round(v))
+}
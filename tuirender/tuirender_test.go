@@ -0,0 +1,212 @@
+package tuirender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SCKelemen/layout"
+)
+
+func init() {
+	layout.SetTextMetricsProvider(CellMetrics{})
+}
+
+// layoutCells lays root out on a cols×rows terminal. The root is sized to
+// the full grid.
+func layoutCells(root *layout.Node, cols, rows int) {
+	root.Style.Width = layout.Px(float64(cols))
+	root.Style.Height = layout.Px(float64(rows))
+	ctx := layout.NewLayoutContext(float64(cols), float64(rows), 1).WithTextMetrics(CellMetrics{})
+	layout.Layout(root, layout.Tight(float64(cols), float64(rows)), ctx)
+}
+
+func cellText(text string) *layout.Node {
+	return layout.Text(text, layout.Style{
+		TextStyle: &layout.TextStyle{FontSize: 1, LineHeight: 1},
+	})
+}
+
+func TestToStringGridBorderAndText(t *testing.T) {
+	root := &layout.Node{
+		Style: layout.Style{
+			Display: layout.DisplayBlock,
+			Border:  layout.Uniform(layout.Px(1)),
+		},
+		Children: []*layout.Node{cellText("Hello")},
+	}
+	layoutCells(root, 10, 3)
+
+	got := ToStringGrid(root, 10, 3)
+	want := []string{
+		"┌────────┐",
+		"│Hello   │",
+		"└────────┘",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestToStringGridDimensions(t *testing.T) {
+	root := &layout.Node{Style: layout.Style{Display: layout.DisplayBlock}}
+	layoutCells(root, 7, 4)
+
+	got := ToStringGrid(root, 7, 4)
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 rows, got %d", len(got))
+	}
+	for i, line := range got {
+		if line != "       " {
+			t.Errorf("Row %d: expected 7 spaces, got %q", i, line)
+		}
+	}
+}
+
+func TestToStringGridWrapsText(t *testing.T) {
+	root := &layout.Node{
+		Style:    layout.Style{Display: layout.DisplayBlock},
+		Children: []*layout.Node{cellText("one two three")},
+	}
+	layoutCells(root, 8, 2)
+
+	got := ToStringGrid(root, 8, 2)
+	want := []string{"one two ", "three   "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestToStringGridWideRunes(t *testing.T) {
+	root := &layout.Node{
+		Style:    layout.Style{Display: layout.DisplayBlock},
+		Children: []*layout.Node{cellText("日本")},
+	}
+	layoutCells(root, 6, 1)
+
+	got := ToStringGrid(root, 6, 1)
+	if got[0] != "日本  " {
+		t.Errorf("Expected wide runes to take two cells, got %q", got[0])
+	}
+}
+
+func TestToStringGridWithFill(t *testing.T) {
+	root := &layout.Node{
+		Style: layout.Style{Display: layout.DisplayBlock},
+		Children: []*layout.Node{
+			{Style: layout.Style{Width: layout.Px(3), Height: layout.Px(2)}},
+		},
+	}
+	layoutCells(root, 5, 2)
+
+	got := ToStringGridWithOptions(root, 5, 2, Options{
+		Fill: func(n *layout.Node) rune {
+			if n == root {
+				return '.'
+			}
+			return '#'
+		},
+	})
+	want := []string{"###..", "###.."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestToStringGridDeterministic(t *testing.T) {
+	build := func() *layout.Node {
+		return &layout.Node{
+			Style: layout.Style{
+				Display:       layout.DisplayFlex,
+				FlexDirection: layout.FlexDirectionRow,
+			},
+			Children: []*layout.Node{
+				{Style: layout.Style{Width: layout.Px(4), Height: layout.Px(3), Border: layout.Uniform(layout.Px(1))}},
+				{Style: layout.Style{Width: layout.Px(4), Height: layout.Px(3), Border: layout.Uniform(layout.Px(1))}},
+			},
+		}
+	}
+	a, b := build(), build()
+	layoutCells(a, 8, 3)
+	layoutCells(b, 8, 3)
+
+	ga, gb := ToStringGrid(a, 8, 3), ToStringGrid(b, 8, 3)
+	if strings.Join(ga, "\n") != strings.Join(gb, "\n") {
+		t.Error("Expected identical output for identical trees")
+	}
+	if ga[0] != "┌──┐┌──┐" {
+		t.Errorf("Expected two boxes side by side, got %q", ga[0])
+	}
+}
+
+func TestToStringGridSkipsHiddenBoxes(t *testing.T) {
+	shown := cellText("Shown")
+	shown.Style.Visibility = layout.VisibilityVisible
+	hidden := &layout.Node{
+		Style: layout.Style{
+			Display:    layout.DisplayBlock,
+			Border:     layout.Uniform(layout.Px(1)),
+			Visibility: layout.VisibilityHidden,
+		},
+		Children: []*layout.Node{cellText("Gone"), shown},
+	}
+	root := &layout.Node{Style: layout.Style{Display: layout.DisplayBlock}, Children: []*layout.Node{hidden}}
+	layoutCells(root, 8, 4)
+
+	// The hidden box keeps its space; its visible child is still painted
+	got := ToStringGrid(root, 8, 4)
+	want := []string{
+		"        ",
+		"        ",
+		" Shown  ",
+		"        ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestToStringGridExpandsTabs(t *testing.T) {
+	root := &layout.Node{
+		Style: layout.Style{Display: layout.DisplayBlock},
+		Children: []*layout.Node{layout.Text("a\tb\nab\tc", layout.Style{
+			TextStyle: &layout.TextStyle{FontSize: 1, LineHeight: 1, WhiteSpace: layout.WhiteSpacePre, TabSize: 4, LetterSpacing: -1, WordSpacing: -1},
+		})},
+	}
+	layoutCells(root, 8, 2)
+
+	got := ToStringGrid(root, 8, 2)
+	want := []string{
+		"a   b   ",
+		"ab  c   ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestToStringGridScrollsPanes(t *testing.T) {
+	root := &layout.Node{
+		Style: layout.Style{
+			Display:  layout.DisplayBlock,
+			Border:   layout.Uniform(layout.Px(1)),
+			Overflow: layout.OverflowAuto,
+		},
+		Children: []*layout.Node{cellText("one two three four")},
+	}
+	layoutCells(root, 10, 4)
+
+	// Three lines in a pane two rows tall: they scroll by one row at most
+	if got := root.ScrollTo(0, 5, nil); got != (layout.Point{X: 0, Y: 1}) {
+		t.Fatalf("Expected to scroll to (0, 1), got %+v", got)
+	}
+	got := ToStringGrid(root, 10, 4)
+	want := []string{
+		"┌────────┐",
+		"│three   │",
+		"│four    │",
+		"└────────┘",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Grid mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
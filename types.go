@@ -112,6 +112,52 @@ type Node struct {
 	// TextLayout contains line box information populated by LayoutText.
 	// Used by renderers to position text. Nil for non-text nodes.
 	TextLayout *TextLayout
+
+	// Tag, ID, Classes and Attributes identify the node to selectors (see
+	// ParseSelector and Query), like an element's tag name and attributes.
+	// They don't affect layout.
+	Tag        string
+	ID         string
+	Classes    []string
+	Attributes map[string]string
+
+	// UserData holds application data attached to the node, such as the
+	// widget or model it lays out, so it can be recovered from the results
+	// of Find, Query or FrozenTree.NodeAt (see UserDataOf). Layout never
+	// reads it, and Clone and CloneDeep copy the value, not what it refers
+	// to. It isn't serialized.
+	UserData any
+
+	// Cross-node constraints set by MatchWidthOf and AlignBaselineWith.
+	matchWidthOf      *Node
+	alignBaselineWith *Node
+
+	// Callbacks added by OnLayout.
+	onLayout []func(*Node)
+
+	// State variants set by SetStateStyle and activated by SetState.
+	stateStyles map[string]Style
+	states      []string // Active states, in activation order
+	baseStyle   *Style   // Style without variants, while one is applied
+
+	// Scroll is how far a scroll container's content is scrolled right and
+	// down (see Style.Overflow). Layout doesn't change it; renderers draw
+	// the children shifted by it. Set it with ScrollTo to keep it in range.
+	Scroll Point
+
+	// Normal flow and stuck positions of a sticky node (see UpdateSticky).
+	sticky stickyState
+
+	// Space reserved for scrollbars by the last layout (see
+	// ScrollbarGutters).
+	gutters scrollbarGutters
+
+	// Grid tracks or flex lines of the last layout (see DebugOverlay).
+	// Never modified once set, so copies of the node can share it.
+	tracks *layoutTracks
+
+	// Results of the current layout, for MeasureCache.
+	measured measuredSizes
 }
 
 // Style contains CSS-like layout properties
@@ -128,12 +174,16 @@ type Style struct {
 	AlignSelf      AlignItems // Per-item cross-axis alignment override (0 = use parent's AlignItems)
 	FlexGrow       float64    // Flex grow factor (unitless)
 	FlexShrink     float64    // Flex shrink factor (unitless)
-	FlexBasis      Length     // Initial main size (use Px(0) with WidthSizing/HeightSizing for auto)
+	FlexBasis      FlexBasis  // Initial main size (zero value = auto; see BasisContent and Basis)
 	FlexGap        Length     // Gap between flex items (use Px(0) for no gap)
 	FlexRowGap     Length     // Row gap (cross-axis gap, use Px(0) to fall back to FlexGap)
 	FlexColumnGap  Length     // Column gap (main-axis gap, use Px(0) to fall back to FlexGap)
 	Order          int        // Visual order (default: 0). Items are ordered by ascending order value.
 
+	// AlignContentOverflow is the safe/unsafe modifier for AlignContent in
+	// multi-line flex containers and grids. Default: safe.
+	AlignContentOverflow OverflowAlignment
+
 	// Grid properties
 	GridTemplateRows    []GridTrack
 	GridTemplateColumns []GridTrack
@@ -143,10 +193,10 @@ type Style struct {
 	GridGap             Length             // Gap between grid tracks (use Px(0) for no gap)
 	GridRowGap          Length             // Row gap (use Px(0) to fall back to GridGap)
 	GridColumnGap       Length             // Column gap (use Px(0) to fall back to GridGap)
-	GridRowStart        int                // -1 means auto
-	GridRowEnd          int                // -1 means auto
-	GridColumnStart     int                // -1 means auto
-	GridColumnEnd       int                // -1 means auto
+	GridRowStart        int                // 0-based line; negative counts from the end (-1 = last line); GridLineAuto or Span(n)
+	GridRowEnd          int                // 0-based line; negative counts from the end (-1 = last line); GridLineAuto or Span(n)
+	GridColumnStart     int                // 0-based line; negative counts from the end (-1 = last line); GridLineAuto or Span(n)
+	GridColumnEnd       int                // 0-based line; negative counts from the end (-1 = last line); GridLineAuto or Span(n)
 	GridTemplateAreas   *GridTemplateAreas // Named grid areas (nil means not set)
 	GridArea            string             // Name of the grid area this item should be placed in (empty means not set)
 	JustifyItems        JustifyItems       // Alignment along inline (row) axis. Default: Stretch
@@ -158,8 +208,8 @@ type Style struct {
 	// Sizing
 	Width       Length  // Explicit width (use WidthSizing for auto/min-content/max-content/fit-content)
 	Height      Length  // Explicit height (use HeightSizing for auto/min-content/max-content/fit-content)
-	MinWidth    Length  // Minimum width
-	MinHeight   Length  // Minimum height
+	MinWidth    Length  // Minimum width (zero Length = auto: flex items don't shrink below min-content; Px(0) opts out)
+	MinHeight   Length  // Minimum height (zero Length = auto, as MinWidth)
 	MaxWidth    Length  // Maximum width
 	MaxHeight   Length  // Maximum height
 	AspectRatio float64 // Width/Height ratio (0 means not set). Example: 16/9 = 1.777...
@@ -175,9 +225,39 @@ type Style struct {
 	Margin  Spacing // Margin is supported in Flexbox and Grid layouts
 	Border  Spacing
 
+	// Logical margins and paddings (CSS margin-inline-start and so on).
+	// Set ones (not the zero Length) replace the physical side they map to
+	// for the node's WritingMode and Direction; see ResolveLogicalSides.
+	MarginBlockStart   Length
+	MarginBlockEnd     Length
+	MarginInlineStart  Length
+	MarginInlineEnd    Length
+	PaddingBlockStart  Length
+	PaddingBlockEnd    Length
+	PaddingInlineStart Length
+	PaddingInlineEnd   Length
+
 	// Box model
 	BoxSizing BoxSizing
 
+	// MarginCollapse selects how the vertical margins of a block container's
+	// children collapse (default: adjacent siblings only). See MarginCollapse.
+	MarginCollapse MarginCollapse
+
+	// Multi-column layout (block containers only). Setting ColumnCount or
+	// ColumnWidth makes a block a multi-column container; see
+	// MultiColumnGeometry.
+	ColumnCount     int    // Number of columns (0 = auto); with ColumnWidth, the maximum
+	ColumnWidth     Length // Ideal column width (zero Length = auto); columns are at least this wide
+	ColumnGap       Length // Gap between columns (zero Length = normal, 1em)
+	ColumnRuleWidth Length // Width of the rule drawn in each gap; doesn't affect layout
+
+	// Fragmentation: where Paginate may break a laid-out tree into pages.
+	// They don't affect layout.
+	BreakBefore BreakBetween // Page break before the box (default: auto)
+	BreakAfter  BreakBetween // Page break after the box (default: auto)
+	BreakInside BreakInside  // Whether a page may break inside the box (default: auto)
+
 	// Positioning
 	Position Position
 	Top      Length // Positioning offset (use Px(0) for zero, check for auto via separate logic)
@@ -186,9 +266,31 @@ type Style struct {
 	Left     Length // Positioning offset
 	ZIndex   int    // Stacking order
 
+	// Logical positioning offsets (CSS inset-block-start and so on), which
+	// replace Top, Right, Bottom or Left like the logical margins.
+	InsetBlockStart  Length
+	InsetBlockEnd    Length
+	InsetInlineStart Length
+	InsetInlineEnd   Length
+
 	// Transform (for SVG rendering and visual effects)
 	Transform Transform
 
+	// Visibility controls whether renderers paint the box. Hidden boxes
+	// still take up space. Default: VisibilityInherit (zero value).
+	Visibility Visibility
+
+	// Overflow clips the content to the padding box and makes the node a
+	// scroll container when not OverflowVisible (zero value).
+	// Spec: https://www.w3.org/TR/css-overflow-3/#overflow-properties
+	Overflow Overflow
+
+	// ScrollbarGutter reserves space for a vertical scrollbar even when
+	// none is shown. Scrollbars only take space when
+	// LayoutContext.ScrollbarWidth is set. Default: ScrollbarGutterAuto.
+	// Spec: https://www.w3.org/TR/css-overflow-3/#scrollbar-gutter-property
+	ScrollbarGutter ScrollbarGutter
+
 	// WritingMode controls the block flow direction for layout containers.
 	// Inherited property that applies to all elements (block, flex, grid, text).
 	// Based on CSS Writing Modes Level 3: https://www.w3.org/TR/css-writing-modes-3/
@@ -206,10 +308,22 @@ type Style struct {
 	// Spec: https://www.w3.org/TR/css-contain-3/#container-name
 	ContainerName ContainerName
 
-	// TextStyle contains text-specific properties (nil for non-text nodes).
+	// TextStyle contains text-specific properties. Text nodes inherit the
+	// TextStyle of their nearest ancestor that has one (see InheritTextStyle).
 	// Based on CSS Text Module Level 3: https://www.w3.org/TR/css-text-3/
 	// Note: TextStyle.WritingMode is deprecated; use Style.WritingMode instead for inheritance.
 	TextStyle *TextStyle
+
+	// FirstLine styles the first formatted line of a text node, like the
+	// CSS ::first-line pseudo-element. Its non-zero fields override the
+	// node's computed text style on that line.
+	// Spec: https://www.w3.org/TR/css-pseudo-4/#first-line-pseudo
+	FirstLine *TextStyle
+
+	// FirstLetter makes the first letter of a text node a drop cap that the
+	// following lines wrap around (see FirstLetter).
+	// Spec: https://www.w3.org/TR/css-inline-3/#initial-letter-styling
+	FirstLetter *FirstLetter
 }
 
 // Spacing represents spacing on all sides using Length values
@@ -301,6 +415,16 @@ const (
 	AlignItemsFlexEnd
 	AlignItemsCenter
 	AlignItemsBaseline
+
+	// AlignItemsStretchExplicit is an explicitly specified `stretch`.
+	//
+	// The zero value (AlignItemsStretch) behaves like CSS `normal`: it
+	// stretches auto-sized items, except grid items with an aspect ratio,
+	// which are start-aligned. AlignItemsStretchExplicit also stretches those
+	// aspect-ratio grid items and re-derives their other axis from the ratio.
+	// In flexbox the two are equivalent.
+	// See: https://www.w3.org/TR/css-align-3/#align-grid
+	AlignItemsStretchExplicit
 )
 
 // JustifyItems controls alignment along the inline (row) axis in Grid
@@ -312,6 +436,10 @@ const (
 	JustifyItemsStart
 	JustifyItemsEnd
 	JustifyItemsCenter
+
+	// JustifyItemsStretchExplicit is an explicitly specified `stretch`; see
+	// AlignItemsStretchExplicit for how it differs from the zero value.
+	JustifyItemsStretchExplicit
 )
 
 // AlignContent
@@ -324,6 +452,24 @@ const (
 	AlignContentCenter
 	AlignContentSpaceBetween
 	AlignContentSpaceAround
+	AlignContentSpaceEvenly // Equal space before, between and after lines
+)
+
+// OverflowAlignment selects what happens when the content being aligned is
+// larger than the container (CSS safe/unsafe overflow alignment).
+//
+// See: https://www.w3.org/TR/css-align-3/#overflow-values
+type OverflowAlignment int
+
+const (
+	// OverflowAlignmentDefault behaves like safe.
+	OverflowAlignmentDefault OverflowAlignment = iota
+	// OverflowAlignmentSafe aligns overflowing content to the start, so
+	// nothing is pushed out past the start edge where it can't be reached.
+	OverflowAlignmentSafe
+	// OverflowAlignmentUnsafe honors the alignment even if content overflows
+	// past the start edge (e.g. centered lines overflow on both sides).
+	OverflowAlignmentUnsafe
 )
 
 // GridAutoFlow controls the auto-placement algorithm for grid items
@@ -345,6 +491,87 @@ const (
 	BoxSizingBorderBox
 )
 
+// MarginCollapse selects how vertical margins collapse inside a block
+// container.
+//
+// See: https://www.w3.org/TR/CSS2/box.html#collapsing-margins
+type MarginCollapse int
+
+const (
+	// MarginCollapseSiblings collapses the margins between adjacent children
+	// to the larger of the two (default).
+	MarginCollapseSiblings MarginCollapse = iota
+
+	// MarginCollapseFull collapses margins as CSS 2 §8.3.1 does: adjacent
+	// sibling margins combine the largest positive and the most negative
+	// margin, an empty child's margins collapse through it, and each block
+	// child's margins collapse with those of its own first and last child
+	// unless padding, a border or an explicit height separates them.
+	// Set it on every block of a document to get browser-like spacing.
+	// Only horizontal writing modes are supported; vertical ones fall back
+	// to MarginCollapseSiblings.
+	MarginCollapseFull
+)
+
+// BreakBetween controls page breaks before or after a box (CSS break-before
+// and break-after).
+//
+// See: https://www.w3.org/TR/css-break-3/#break-between
+type BreakBetween int
+
+const (
+	BreakAuto  BreakBetween = iota // Break if needed (default)
+	BreakAvoid                     // Avoid breaking here if another break point fits
+	BreakPage                      // Always break here
+)
+
+// BreakInside controls page breaks inside a box (CSS break-inside).
+//
+// See: https://www.w3.org/TR/css-break-3/#break-within
+type BreakInside int
+
+const (
+	BreakInsideAuto  BreakInside = iota // Break inside if needed (default)
+	BreakInsideAvoid                    // Keep the box on one page if it fits on one
+)
+
+// Visibility controls whether a box is painted (CSS visibility). It is
+// inherited: the zero value takes the parent's visibility.
+// See: https://www.w3.org/TR/css-display-3/#visibility
+type Visibility int
+
+const (
+	VisibilityInherit Visibility = iota // Same as the parent; visible at the root (default)
+	VisibilityVisible                   // Painted, even inside a hidden parent
+	VisibilityHidden                    // Not painted, but still takes up space
+)
+
+// Overflow controls what happens to content that overflows a box's padding
+// box (CSS overflow). Any value but visible makes the box a scroll
+// container: its content is clipped to its padding box and can be scrolled
+// by setting Node.Scroll.
+// See: https://www.w3.org/TR/css-overflow-3/#overflow-properties
+type Overflow int
+
+const (
+	OverflowVisible Overflow = iota // Not clipped (default)
+	OverflowHidden                  // Clipped, scrollable only programmatically
+	OverflowScroll                  // Clipped, with scrollbars whether or not it overflows
+	OverflowAuto                    // Clipped, with scrollbars when it overflows
+)
+
+// ScrollbarGutter controls whether a scroll container keeps space for a
+// vertical scrollbar when it doesn't show one (CSS scrollbar-gutter), so
+// its content doesn't move when the scrollbar comes and goes.
+// See: https://www.w3.org/TR/css-overflow-3/#scrollbar-gutter-property
+type ScrollbarGutter int
+
+const (
+	ScrollbarGutterAuto            ScrollbarGutter = iota // Space only for shown scrollbars (default)
+	ScrollbarGutterStable                                 // Always keep space on the right
+	ScrollbarGutterStableBothEdges                        // Always keep space on the right and, to match, on the left
+)
+
 // Position
 type Position int
 
@@ -366,6 +593,8 @@ const (
 	TextAlignRight
 	TextAlignCenter
 	TextAlignJustify // Stretches text to fill the line width (§7.1.1)
+	TextAlignStart   // Start edge of the line: left in LTR, right in RTL
+	TextAlignEnd     // End edge of the line: right in LTR, left in RTL
 )
 
 // TextAlignLast controls alignment of the last line in a block
@@ -378,6 +607,8 @@ const (
 	TextAlignLastRight
 	TextAlignLastCenter
 	TextAlignLastJustify // Also justify the last line
+	TextAlignLastStart
+	TextAlignLastEnd
 )
 
 // TextJustify controls the justification algorithm
@@ -456,16 +687,18 @@ const (
 	HyphensAuto                  // Automatic hyphenation with dictionaries
 )
 
-// HangingPunctuation controls punctuation placement
+// HangingPunctuation controls which punctuation hangs outside the line
+// box, where alignment and justification ignore it.
 // CSS Text Module Level 3 §9.2: https://www.w3.org/TR/css-text-3/#hanging-punctuation-property
+// Multiple values can be combined using bitwise OR, like "first allow-end last".
 type HangingPunctuation int
 
 const (
-	HangingPunctuationNone     HangingPunctuation = iota // No hanging (default)
-	HangingPunctuationFirst                              // Hang opening punctuation
-	HangingPunctuationLast                               // Hang closing punctuation
-	HangingPunctuationForceEnd                           // Force hang end punctuation
-	HangingPunctuationAllowEnd                           // Allow hang end punctuation
+	HangingPunctuationNone     HangingPunctuation = 0      // No hanging (default)
+	HangingPunctuationFirst    HangingPunctuation = 1 << 0 // Opening brackets and quotes starting the first line
+	HangingPunctuationLast     HangingPunctuation = 1 << 1 // Closing brackets and quotes ending the last line
+	HangingPunctuationForceEnd HangingPunctuation = 1 << 2 // Stops and commas ending any line
+	HangingPunctuationAllowEnd HangingPunctuation = 1 << 3 // Stops and commas ending a line they don't fit on
 )
 
 // Direction controls text direction.
@@ -650,6 +883,7 @@ type TextStyle struct {
 
 	// Tab Size (§3.1.1) - Number of spaces per tab character
 	// -1 = default (8 spaces), otherwise number of spaces
+	// In pre and pre-wrap text, tabs advance to the next tab stop
 	TabSize float64
 
 	// Font (for measurement)
@@ -683,11 +917,29 @@ type TextStyle struct {
 type TextLayout struct {
 	Lines      []TextLine
 	LineHeight float64
+
+	// Style is the computed text style the lines were laid out with: the
+	// node's TextStyle with inherited values filled in.
+	Style TextStyle
+
+	// ContentX and ContentY are the offset of the content box (inside
+	// padding and border) from the node's Rect origin. Line offsets are
+	// relative to it.
+	ContentX float64
+	ContentY float64
+
+	// FirstLineStyle is the style of the first line when it differs from
+	// Style (see Style.FirstLine), and nil otherwise.
+	FirstLineStyle *TextStyle
+
+	// DropCap is the node's drop cap (see Style.FirstLetter), or nil. Its
+	// letter isn't part of the lines' text.
+	DropCap *DropCap
 }
 
 // TextLine represents a single line of text with its boxes and positioning.
 type TextLine struct {
-	Boxes               []InlineBox
+	Boxes               []InlineBox // In visual order, left to right (see InlineBox.Level)
 	Width               float64
 	SpaceCount          int     // Number of inter-word spaces (for justify)
 	SpaceWidth          float64 // Total width of all spaces (for justify)
@@ -695,6 +947,15 @@ type TextLine struct {
 	CharacterAdjustment float64 // Extra pixels to add between characters (for inter-character justify)
 	OffsetX             float64 // X offset for text-align
 	OffsetY             float64 // Y position (cumulative)
+
+	// Vertical metrics, as offsets from the line's block start (OffsetY in
+	// horizontal writing modes). The content area (Ascent + Descent) is
+	// centered in the line height.
+	Height      float64           // Block size of the line box
+	Ascent      float64           // Tallest ascent of the line's boxes
+	Descent     float64           // Deepest descent of the line's boxes
+	Baseline    float64           // Offset of the alphabetic baseline
+	Decorations DecorationMetrics // Underline, overline and line-through positions
 }
 
 // InlineBoxKind represents the type of inline box.
@@ -723,6 +984,51 @@ type InlineBox struct {
 	// Based on Unicode UAX #50: Unicode Vertical Text Layout
 	// See: https://www.unicode.org/reports/tr50/
 	Orientations []bool
+
+	// Level is the bidi embedding level of the box (UAX #9): even for
+	// left-to-right text, odd for right-to-left. Text stays in logical
+	// order; see VisualText.
+	Level int
+
+	// Runs splits Text where it falls back to other fonts of the font stack
+	// (see FontCoverageProvider), in logical order. Nil when the first
+	// family draws the whole box.
+	Runs []FontRun
+}
+
+// FlexBasis is the initial main size of a flex item before free space is
+// distributed. The zero value is auto.
+//
+// See: CSS Flexible Box Layout Module Level 1 §7.2.3 (flex-basis)
+// https://www.w3.org/TR/css-flexbox-1/#flex-basis-property
+type FlexBasis struct {
+	Kind   FlexBasisKind
+	Length Length // Used when Kind is FlexBasisLength
+}
+
+// FlexBasisKind selects how a flex item's base size is determined.
+type FlexBasisKind int
+
+const (
+	FlexBasisAuto    FlexBasisKind = iota // auto: use Width/Height, or the content size if they are auto
+	FlexBasisContent                      // content: use the max-content size, ignoring Width/Height
+	FlexBasisLength                       // <length> or <percentage>: use Length, including 0; Percent is of the container's inner main size
+)
+
+// BasisAuto returns the auto flex basis (the zero value).
+func BasisAuto() FlexBasis {
+	return FlexBasis{Kind: FlexBasisAuto}
+}
+
+// BasisContent returns the content flex basis.
+func BasisContent() FlexBasis {
+	return FlexBasis{Kind: FlexBasisContent}
+}
+
+// Basis returns a flex basis of the given length. Basis(Px(0)) is a true
+// zero basis, so items share the free space purely by flex-grow.
+func Basis(l Length) FlexBasis {
+	return FlexBasis{Kind: FlexBasisLength, Length: l}
 }
 
 // IntrinsicSize represents intrinsic sizing keywords from CSS Sizing Module Level 3.
@@ -795,6 +1101,41 @@ func AutoTrack() GridTrack {
 	}
 }
 
+// Grid line placement values for GridRowStart/End and GridColumnStart/End.
+//
+// Lines are numbered from 0 (the start edge of the first track); an item
+// with GridColumnStart 1 and GridColumnEnd 3 covers the second and third
+// columns. Negative numbers count back from the end of the explicit grid,
+// so -1 is the last explicit line and GridColumnStart 0, GridColumnEnd -1
+// spans every explicit column. A zero-value start/end pair is auto.
+//
+// Based on CSS Grid Layout Module Level 1 §8.3.
+// See: https://www.w3.org/TR/css-grid-1/#line-placement
+const (
+	// GridLineAuto places the item automatically on that axis (CSS auto).
+	GridLineAuto = math.MinInt32
+
+	// gridSpanBase and gridSpanMax encode Span(n) as gridSpanBase+n.
+	gridSpanBase = math.MinInt32 / 2
+	gridSpanMax  = 1 << 20
+)
+
+// Span returns a placement value spanning n tracks (CSS span n). Used as an
+// end it spans n tracks from the start; used as a start it spans n tracks
+// back from a definite end, or sets the span of an auto-placed item.
+// n is clamped to at least 1.
+//
+// Example: GridRowEnd: Span(2) auto-places an item across two rows.
+func Span(n int) int {
+	if n < 1 {
+		n = 1
+	}
+	if n > gridSpanMax {
+		n = gridSpanMax
+	}
+	return gridSpanBase + n
+}
+
 // RepeatTrack represents a repeating track pattern for grid templates
 // Used with auto-fill and auto-fit grid track generation (Feature 4)
 type RepeatTrack struct {
@@ -991,6 +1332,21 @@ func (t Transform) IsIdentity() bool {
 	return t.A == 1 && t.B == 0 && t.C == 0 && t.D == 1 && t.E == 0 && t.F == 0
 }
 
+// Invert returns the transform that undoes t, and false if t can't be
+// undone because it flattens the plane (for example Scale(0, 1))
+func (t Transform) Invert() (Transform, bool) {
+	det := t.A*t.D - t.B*t.C
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Transform{}, false
+	}
+	return Transform{
+		A: t.D / det, B: -t.B / det,
+		C: -t.C / det, D: t.A / det,
+		E: (t.C*t.F - t.D*t.E) / det,
+		F: (t.B*t.E - t.A*t.F) / det,
+	}, true
+}
+
 // getHorizontalPaddingBorder returns the sum of horizontal padding and border
 func getHorizontalPaddingBorder(padding, border Spacing, ctx *LayoutContext, currentFontSize float64) float64 {
 	paddingLeft := ResolveLength(padding.Left, ctx, currentFontSize)
@@ -0,0 +1,54 @@
+package layout
+
+// UnitResolver resolves application-defined length units to pixels at
+// layout time, so a tree can be built directly in the units the
+// application thinks in (terminal cells, printer points, millimeters on a
+// label) instead of converting everything to pixels up front.
+//
+// Install one with LayoutContext.WithUnitResolver. It is consulted by
+// ResolveLength, and so for every length in every style property, before
+// the built-in units. Lengths with a custom unit are created directly:
+//
+//	width := layout.Length{Value: 40, Unit: "cell"}
+//
+// Percentages, auto and calc() are handled by the engine and never reach
+// the resolver, but the terms of a calc() expression do.
+type UnitResolver interface {
+	// ResolveUnit converts value in unit to pixels. fontSize is the font
+	// size of the node being laid out, for font-relative units. It reports
+	// false for units it doesn't handle, which then resolve as usual.
+	ResolveUnit(value float64, unit LengthUnit, fontSize float64) (px float64, ok bool)
+}
+
+// UnitResolverFunc adapts a function to a UnitResolver.
+//
+// Example:
+//
+//	// 1line = 1.5em
+//	lines := layout.UnitResolverFunc(func(v float64, u layout.LengthUnit, fontSize float64) (float64, bool) {
+//		if u != "line" {
+//			return 0, false
+//		}
+//		return v * fontSize * 1.5, true
+//	})
+type UnitResolverFunc func(value float64, unit LengthUnit, fontSize float64) (px float64, ok bool)
+
+// ResolveUnit implements UnitResolver.
+func (f UnitResolverFunc) ResolveUnit(value float64, unit LengthUnit, fontSize float64) (float64, bool) {
+	return f(value, unit, fontSize)
+}
+
+// UnitScales is a UnitResolver for units that are a fixed number of
+// pixels.
+//
+// Example:
+//
+//	// An 8x16 pixel terminal cell grid
+//	ctx = ctx.WithUnitResolver(layout.UnitScales{"col": 8, "row": 16})
+type UnitScales map[LengthUnit]float64
+
+// ResolveUnit implements UnitResolver.
+func (s UnitScales) ResolveUnit(value float64, unit LengthUnit, fontSize float64) (float64, bool) {
+	scale, ok := s[unit]
+	return value * scale, ok
+}
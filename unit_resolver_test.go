@@ -0,0 +1,48 @@
+package layout
+
+import "testing"
+
+func TestUnitResolver(t *testing.T) {
+	ctx := NewLayoutContext(800, 600, 16).WithUnitResolver(UnitScales{"col": 8, "row": 16})
+
+	if got := ResolveLength(Length{Value: 10, Unit: "col"}, ctx, 16); got != 80 {
+		t.Errorf("Expected 10col = 80px, got %.2f", got)
+	}
+	// Units the resolver doesn't handle resolve as usual
+	if got := ResolveLength(Em(2), ctx, 16); got != 32 {
+		t.Errorf("Expected 2em = 32px, got %.2f", got)
+	}
+	// calc() terms go through the resolver too
+	width, err := ParseCalc("calc(100% - 2col)")
+	if err != nil {
+		t.Fatalf("ParseCalc failed: %v", err)
+	}
+	if got := resolveLengthAgainst(width, 200, ctx, 16); got != 184 {
+		t.Errorf("Expected 200 - 16 = 184, got %.2f", got)
+	}
+}
+
+func TestUnitResolverDuringLayout(t *testing.T) {
+	// 1line = 1.5em, with the node's own font size
+	lines := UnitResolverFunc(func(v float64, u LengthUnit, fontSize float64) (float64, bool) {
+		if u != "line" {
+			return 0, false
+		}
+		return v * fontSize * 1.5, true
+	})
+	fontSize := 20.0
+	child := &Node{Style: Style{Display: DisplayBlock, Width: Length{Value: 40, Unit: "col"}, Height: Length{Value: 2, Unit: "line"}, TextStyle: &TextStyle{FontSize: fontSize}}}
+	root := &Node{Style: Style{Display: DisplayBlock, Width: Px(800), Height: Px(-1)}, Children: []*Node{child}}
+
+	ctx := NewLayoutContext(800, 600, 16).WithUnitResolver(UnitResolverFunc(func(v float64, u LengthUnit, fs float64) (float64, bool) {
+		if px, ok := (UnitScales{"col": 8}).ResolveUnit(v, u, fs); ok {
+			return px, true
+		}
+		return lines(v, u, fs)
+	}))
+	Layout(root, Loose(800, 600), ctx)
+
+	if child.Rect.Width != 320 || child.Rect.Height != 60 {
+		t.Errorf("Expected 320x60, got %.2fx%.2f", child.Rect.Width, child.Rect.Height)
+	}
+}
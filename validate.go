@@ -0,0 +1,214 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// LayoutWarning flags a style that Layout accepts but that likely doesn't
+// do what was intended: a value it ignores or treats as auto, or
+// properties that contradict each other.
+type LayoutWarning struct {
+	// Node is the node with the style, and Path the sequence of child
+	// indexes from the root to it.
+	Node *Node
+	Path []int
+
+	// Property is the Style field concerned, and Message what's wrong with
+	// it.
+	Property string
+	Message  string
+}
+
+// String returns the warning with the node's path.
+func (w LayoutWarning) String() string {
+	return fmt.Sprintf("layout: node %v: %s %s", w.Path, w.Property, w.Message)
+}
+
+// Validate checks root's tree before layout. Nodes LayoutResilient would
+// replace (non-finite lengths, negative flex factors or aspect ratios, grid
+// lines or spans outside ±10000, nesting deeper than DefaultMaxDepth) are
+// reported as an error that joins a *LayoutError per node. Styles Layout
+// accepts but probably doesn't handle as intended are reported as warnings:
+//
+//   - negative sizes, which are treated as auto (-1 is auto by convention,
+//     and isn't flagged), and negative padding and borders
+//   - a minimum size greater than the maximum
+//   - grid lines that end before they start, which are swapped
+//   - grid placement on a child of a non-grid container, and flex factors
+//     on a child of a non-flex container, which have no effect
+//   - a percentage height in a container whose height depends on its
+//     content, which is treated as auto
+//   - text on a node that isn't DisplayInlineText, and children of one
+//     that is, which aren't laid out
+//
+// Example:
+//
+//	warnings, err := layout.Validate(root)
+//	for _, w := range warnings {
+//	    log.Print(w)
+//	}
+func Validate(root *Node) ([]LayoutWarning, error) {
+	return validateTree(root, DefaultMaxDepth)
+}
+
+// LayoutChecked validates root's tree like Validate, with ctx's MaxDepth,
+// and lays it out like Layout if there are no errors. It then reports
+// nodes whose Rect isn't finite as errors. The root's size is in its Rect.
+//
+// Example:
+//
+//	warnings, err := layout.LayoutChecked(root, layout.Loose(800, 600), ctx)
+//	if err != nil {
+//	    return err
+//	}
+func LayoutChecked(root *Node, constraints Constraints, ctx *LayoutContext) ([]LayoutWarning, error) {
+	warnings, err := validateTree(root, ctx.maxDepth())
+	if err != nil {
+		return warnings, err
+	}
+	Layout(root, constraints, ctx)
+
+	var errs []error
+	walkTree(root, func(n *Node, path []int) walkStep {
+		r := n.Rect
+		for _, v := range []float64{r.X, r.Y, r.Width, r.Height} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				errs = append(errs, &LayoutError{
+					Node: n,
+					Path: append([]int{}, path...),
+					Err:  fmt.Errorf("Rect is not finite (%+v)", r),
+				})
+				break
+			}
+		}
+		return walkChildren
+	})
+	return warnings, errors.Join(errs...)
+}
+
+// validateTree validates root's tree, with nodes nested deeper than
+// maxDepth (-1 for no limit) in error.
+func validateTree(root *Node, maxDepth int) ([]LayoutWarning, error) {
+	var errs []error
+	for _, e := range collectLayoutErrors(root, maxDepth) {
+		errs = append(errs, e)
+	}
+
+	var warnings []LayoutWarning
+	var ancestors []*Node
+	walkTree(root, func(n *Node, path []int) walkStep {
+		ancestors = append(ancestors[:len(path)], n)
+		var parent *Node
+		if len(path) > 0 {
+			parent = ancestors[len(path)-1]
+		}
+		for _, w := range styleWarnings(n, parent) {
+			w.Node = n
+			w.Path = append([]int{}, path...)
+			warnings = append(warnings, w)
+		}
+		return walkChildren
+	})
+	return warnings, errors.Join(errs...)
+}
+
+// styleWarnings returns the warnings for n, the child of parent (nil for
+// the root), without their Node and Path.
+func styleWarnings(n, parent *Node) []LayoutWarning {
+	var warnings []LayoutWarning
+	warn := func(property, format string, args ...any) {
+		warnings = append(warnings, LayoutWarning{Property: property, Message: fmt.Sprintf(format, args...)})
+	}
+	s := &n.Style
+
+	for _, l := range []struct {
+		name  string
+		value Length
+	}{{"Width", s.Width}, {"Height", s.Height}} {
+		if isPixelLength(l.value) && l.value.Value < 0 && l.value.Value != -1 {
+			warn(l.name, "is negative (%v) and treated as auto", l.value.Value)
+		}
+	}
+	for _, l := range []struct {
+		name  string
+		value Length
+	}{{"MinWidth", s.MinWidth}, {"MinHeight", s.MinHeight}, {"MaxWidth", s.MaxWidth}, {"MaxHeight", s.MaxHeight}} {
+		if isPixelLength(l.value) && l.value.Value < 0 {
+			warn(l.name, "is negative (%v)", l.value.Value)
+		}
+	}
+	for _, sp := range []struct {
+		name    string
+		spacing Spacing
+	}{{"Padding", s.Padding}, {"Border", s.Border}} {
+		for _, side := range []Length{sp.spacing.Top, sp.spacing.Right, sp.spacing.Bottom, sp.spacing.Left} {
+			if isPixelLength(side) && side.Value < 0 {
+				warn(sp.name, "is negative (%v)", side.Value)
+				break
+			}
+		}
+	}
+
+	for _, mm := range []struct {
+		min, max string
+		lo, hi   Length
+	}{{"MinWidth", "MaxWidth", s.MinWidth, s.MaxWidth}, {"MinHeight", "MaxHeight", s.MinHeight, s.MaxHeight}} {
+		if isPixelLength(mm.lo) && isPixelLength(mm.hi) && mm.hi.Value > 0 && mm.lo.Value > mm.hi.Value {
+			warn(mm.min, "(%v) is greater than %s (%v); the minimum wins", mm.lo.Value, mm.max, mm.hi.Value)
+		}
+	}
+
+	for _, lines := range []struct {
+		start, end string
+		s, e       int
+	}{{"GridRowStart", "GridRowEnd", s.GridRowStart, s.GridRowEnd}, {"GridColumnStart", "GridColumnEnd", s.GridColumnStart, s.GridColumnEnd}} {
+		if lines.s >= 0 && lines.e >= 0 && (lines.s != 0 || lines.e != 0) && lines.e <= lines.s {
+			warn(lines.end, "(%d) isn't after %s (%d)", lines.e, lines.start, lines.s)
+		}
+	}
+
+	if parent != nil {
+		if parent.Style.Display != DisplayGrid && hasGridPlacement(s) {
+			warn("GridArea", "and grid lines have no effect outside a grid container")
+		}
+		if parent.Style.Display != DisplayFlex && s.FlexGrow > 0 {
+			warn("FlexGrow", "has no effect outside a flex container")
+		}
+		if parent.Style.Display != DisplayGrid && lengthHasPercent(s.Height) && hasAutoHeight(&parent.Style) {
+			warn("Height", "is a percentage of a height that depends on content, and is treated as auto")
+		}
+	}
+
+	if n.Text != "" && s.Display != DisplayInlineText {
+		warn("Display", "isn't DisplayInlineText, so Text isn't laid out")
+	}
+	if s.Display == DisplayInlineText && len(n.Children) > 0 {
+		warn("Display", "is DisplayInlineText, so Children aren't laid out")
+	}
+	return warnings
+}
+
+// isPixelLength reports whether l is a plain pixel length.
+func isPixelLength(l Length) bool {
+	return l.Unit == "" || l.Unit == Pixels
+}
+
+// hasGridPlacement reports whether s places its node in a grid.
+func hasGridPlacement(s *Style) bool {
+	if s.GridArea != "" {
+		return true
+	}
+	for _, line := range []int{s.GridRowStart, s.GridRowEnd, s.GridColumnStart, s.GridColumnEnd} {
+		if line != 0 && line != GridLineAuto {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAutoHeight reports whether s's height depends on content.
+func hasAutoHeight(s *Style) bool {
+	return s.Height.Unit == AutoUnit || (s.Height.Unit == Pixels && s.Height.Value == -1)
+}
@@ -0,0 +1,109 @@
+package layout
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidateWarnings(t *testing.T) {
+	negative := &Node{Style: Style{Width: Px(-5), Height: Px(-1)}}
+	minMax := &Node{Style: Style{MinWidth: Px(200), MaxWidth: Px(100)}}
+	gridItem := &Node{Style: Style{GridColumnStart: 3, GridColumnEnd: 1, FlexGrow: 1}}
+	percent := &Node{Style: Style{Height: Percent(50)}}
+	text := &Node{Text: "hello"}
+	root := &Node{
+		Style:    Style{Display: DisplayBlock, Height: Px(-1)},
+		Children: []*Node{negative, minMax, gridItem, percent, text},
+	}
+
+	warnings, err := Validate(root)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		node     *Node
+		property string
+	}{
+		{negative, "Width"},
+		{minMax, "MinWidth"},
+		{gridItem, "GridColumnEnd"},
+		{gridItem, "GridArea"},
+		{gridItem, "FlexGrow"},
+		{percent, "Height"},
+		{text, "Display"},
+	}
+	if len(warnings) != len(expected) {
+		for _, w := range warnings {
+			t.Log(w)
+		}
+		t.Fatalf("Expected %d warnings, got %d", len(expected), len(warnings))
+	}
+	for i, e := range expected {
+		w := warnings[i]
+		if w.Node != e.node || w.Property != e.property {
+			t.Errorf("Expected warning %d on %s, got %s", i, e.property, w)
+		}
+		if len(w.Path) != 1 {
+			t.Errorf("Expected a path of length 1, got %v", w.Path)
+		}
+	}
+	if !strings.Contains(warnings[0].String(), "treated as auto") {
+		t.Errorf("Expected the warning to explain itself, got %q", warnings[0].String())
+	}
+}
+
+func TestValidateValidTree(t *testing.T) {
+	grid := &Node{
+		Style: Style{Display: DisplayGrid, GridTemplateColumns: []GridTrack{FixedTrack(Px(100)), FixedTrack(Px(100))}},
+		Children: []*Node{
+			{Style: Style{GridColumnStart: 0, GridColumnEnd: 2, Height: Percent(100)}},
+		},
+	}
+	row := HStack(&Node{Style: Style{FlexGrow: 1}}, grid)
+
+	warnings, err := Validate(row)
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("Expected no warnings or errors, got %v, %v", warnings, err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	bad := &Node{Style: Style{Width: Px(math.Inf(1))}}
+	root := VStack(Fixed(10, 10), bad)
+
+	_, err := Validate(root)
+
+	var layoutErr *LayoutError
+	if !errors.As(err, &layoutErr) {
+		t.Fatalf("Expected a LayoutError, got %v", err)
+	}
+	if layoutErr.Node != bad {
+		t.Errorf("Expected the error on the invalid node, got path %v", layoutErr.Path)
+	}
+}
+
+func TestLayoutChecked(t *testing.T) {
+	root := VStack(Fixed(100, 50), Fixed(80, 40))
+	ctx := NewLayoutContext(400, 300, 16)
+
+	warnings, err := LayoutChecked(root, Loose(400, 300), ctx)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("Expected no warnings or errors, got %v, %v", warnings, err)
+	}
+	if root.Rect.Height != 90 {
+		t.Errorf("Expected height 90, got %.2f", root.Rect.Height)
+	}
+
+	bad := &Node{Style: Style{Height: Px(math.NaN())}}
+	child := Fixed(10, 10)
+	root = VStack(child, bad)
+	if _, err := LayoutChecked(root, Loose(400, 300), ctx); err == nil {
+		t.Error("Expected an error for a NaN height")
+	}
+	if child.Rect != (Rect{}) {
+		t.Errorf("Expected no layout after a validation error, got %+v", child.Rect)
+	}
+}